@@ -470,17 +470,7 @@ func (c *AppClient) RobotParts(orgStr, locStr, robotStr string) ([]*apppb.RobotP
 }
 
 func (c *AppClient) printRobotPartLogsInternal(logs []*apppb.LogEntry, indent string) {
-	for _, log := range logs {
-		fmt.Fprintf(
-			c.c.App.Writer,
-			"%s%s\t%s\t%s\t%s\n",
-			indent,
-			log.Time.AsTime().Format("2006-01-02T15:04:05.000Z0700"),
-			log.Level,
-			log.LoggerName,
-			log.Message,
-		)
-	}
+	printLogs(c.c.App.Writer, logs, indent)
 }
 
 // PrintRobotPartLogs prints logs for the given robot part.
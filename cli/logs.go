@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	apppb "go.viam.com/api/app/v1"
+	"go.viam.com/utils"
+	"go.viam.com/utils/rpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"go.viam.com/rdk/grpc"
+	logstorepb "go.viam.com/rdk/logstore/proto/v1"
+	rutils "go.viam.com/rdk/utils"
+)
+
+// RobotLogsOptions are the options for reading the logs kept by a robot.
+type RobotLogsOptions struct {
+	// Secret and APIKey are the credentials to authenticate to the robot with, if it requires any.
+	Secret string
+	APIKey string
+	// Insecure allows connecting to the robot without TLS.
+	Insecure bool
+	Debug    bool
+
+	// Since, if set, selects logs of no longer than that ago.
+	Since      time.Duration
+	Level      string
+	LoggerName string
+	Resource   string
+	// Limit is how many of the most recent logs to print. When tailing, those are printed before new ones.
+	Limit int
+	Tail  bool
+}
+
+// PrintRobotLogs dials the robot at the address directly and prints the logs it keeps, following them as they are
+// written if tailing.
+func PrintRobotLogs(ctx context.Context, w io.Writer, address string, opts RobotLogsOptions, logger golog.Logger) error {
+	var dialOpts []rpc.DialOption
+	switch {
+	case opts.APIKey != "" && opts.Secret != "":
+		return errors.New("only one of an api key or a secret may be given")
+	case opts.APIKey != "":
+		dialOpts = append(dialOpts, rpc.WithCredentials(rpc.Credentials{
+			Type:    rpc.CredentialsTypeAPIKey,
+			Payload: opts.APIKey,
+		}))
+	case opts.Secret != "":
+		dialOpts = append(dialOpts, rpc.WithEntityCredentials(address, rpc.Credentials{
+			Type:    rutils.CredentialsTypeRobotLocationSecret,
+			Payload: opts.Secret,
+		}))
+	}
+	if opts.Insecure {
+		dialOpts = append(dialOpts, rpc.WithInsecure(), rpc.WithAllowInsecureWithCredentialsDowngrade())
+	}
+	if opts.Debug {
+		dialOpts = append(dialOpts, rpc.WithDialDebug())
+	}
+
+	conn, err := grpc.Dial(ctx, address, logger, dialOpts...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		utils.UncheckedError(conn.Close())
+	}()
+	client := logstorepb.NewLogStoreServiceClient(conn)
+
+	filter := &logstorepb.LogFilter{
+		Level:      opts.Level,
+		LoggerName: opts.LoggerName,
+		Resource:   opts.Resource,
+	}
+	if opts.Since > 0 {
+		filter.Start = timestamppb.New(time.Now().Add(-opts.Since))
+	}
+
+	if !opts.Tail {
+		resp, err := client.GetLogs(ctx, &logstorepb.GetLogsRequest{Filter: filter, Limit: int32(opts.Limit)})
+		if err != nil {
+			return err
+		}
+		if len(resp.Logs) == 0 {
+			fmt.Fprintln(w, "no recent logs")
+			return nil
+		}
+		printLogs(w, resp.Logs, "")
+		return nil
+	}
+
+	tailClient, err := client.TailLogs(ctx, &logstorepb.TailLogsRequest{Filter: filter, Recent: int32(opts.Limit)})
+	if err != nil {
+		return err
+	}
+	for {
+		resp, err := tailClient.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		printLogs(w, resp.Logs, "")
+	}
+}
+
+func printLogs(w io.Writer, logs []*apppb.LogEntry, indent string) {
+	for _, log := range logs {
+		fmt.Fprintf(
+			w,
+			"%s%s\t%s\t%s\t%s\n",
+			indent,
+			log.Time.AsTime().Format("2006-01-02T15:04:05.000Z0700"),
+			log.Level,
+			log.LoggerName,
+			log.Message,
+		)
+	}
+}
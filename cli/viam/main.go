@@ -586,6 +586,69 @@ func main() {
 							return nil
 						},
 					},
+					{
+						Name:  "local-logs",
+						Usage: "display logs kept by a robot, connecting to it directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "address",
+								Usage:    "address of the robot",
+								Required: true,
+							},
+							&cli.StringFlag{
+								Name:  "secret",
+								Usage: "location secret of the robot",
+							},
+							&cli.StringFlag{
+								Name:  "api-key",
+								Usage: "api key for the robot",
+							},
+							&cli.BoolFlag{
+								Name:  "insecure",
+								Usage: "connect without TLS",
+							},
+							&cli.DurationFlag{
+								Name:  "since",
+								Usage: "show only logs from this long ago onward",
+							},
+							&cli.StringFlag{
+								Name:  "level",
+								Usage: "show only logs of this level and above",
+							},
+							&cli.StringFlag{
+								Name:  "logger",
+								Usage: "show only logs of this logger and its children",
+							},
+							&cli.StringFlag{
+								Name:  "resource",
+								Usage: "show only logs of this resource",
+							},
+							&cli.IntFlag{
+								Name:  "limit",
+								Usage: "show at most this many of the most recent logs",
+								Value: 100,
+							},
+							&cli.BoolFlag{
+								Name:    "tail",
+								Aliases: []string{"f"},
+								Usage:   "follow logs",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return rdkcli.PrintRobotLogs(c.Context, c.App.Writer, c.String("address"), rdkcli.RobotLogsOptions{
+								Secret:     c.String("secret"),
+								APIKey:     c.String("api-key"),
+								Insecure:   c.Bool("insecure"),
+								Debug:      c.Bool("debug"),
+								Since:      c.Duration("since"),
+								Level:      c.String("level"),
+								LoggerName: c.String("logger"),
+								Resource:   c.String("resource"),
+								Limit:      c.Int("limit"),
+								Tail:       c.Bool("tail"),
+							}, logger)
+						},
+					},
 					{
 						Name:  "part",
 						Usage: "work with robot part",
@@ -0,0 +1,414 @@
+// Package ackermann implements a base steered like a car, with a drive motor and a steering servo.
+package ackermann
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// ModelName is the name of the ackermann model of a base component.
+var ModelName = resource.NewDefaultModel("ackermann")
+
+const defaultSteeringCenterDeg = 90
+
+// Config is how you configure an ackermann base. The rear wheels are either driven together through a differential
+// by the drive motors, or each by its own left and right motors, which are then driven at the speeds of the inner
+// and outer wheels of turns. The steering servo sets the angle of a virtual front wheel halfway between the real
+// ones, whose angles are left to the steering linkage.
+type Config struct {
+	WidthMM              int      `json:"width_mm"`
+	WheelbaseMM          int      `json:"wheelbase_mm"`
+	WheelCircumferenceMM int      `json:"wheel_circumference_mm"`
+	Drive                []string `json:"drive,omitempty"`
+	Left                 []string `json:"left,omitempty"`
+	Right                []string `json:"right,omitempty"`
+
+	SteeringServo       string  `json:"steering_servo"`
+	MaxSteeringAngleDeg float64 `json:"max_steering_angle_deg"`
+	// SteeringCenterDeg is the angle of the servo when the base goes straight, 90 if unset.
+	SteeringCenterDeg float64 `json:"steering_center_deg,omitempty"`
+	// SteeringRatio is how many degrees the servo turns for the wheels to turn one degree to the left, 1 if unset.
+	// It is negative when the servo angle decreases to turn left.
+	SteeringRatio float64 `json:"steering_ratio,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	var deps []string
+
+	if cfg.WidthMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "width_mm")
+	}
+	if cfg.WheelbaseMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheelbase_mm")
+	}
+	if cfg.WheelCircumferenceMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheel_circumference_mm")
+	}
+
+	if len(cfg.Drive) == 0 && len(cfg.Left) == 0 && len(cfg.Right) == 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "drive")
+	}
+	if len(cfg.Left) != len(cfg.Right) {
+		return nil, utils.NewConfigValidationError(path,
+			fmt.Errorf("left and right need to have the same number of motors, not %d vs %d",
+				len(cfg.Left), len(cfg.Right)))
+	}
+
+	if cfg.SteeringServo == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "steering_servo")
+	}
+	if cfg.MaxSteeringAngleDeg <= 0 || cfg.MaxSteeringAngleDeg >= 90 {
+		return nil, utils.NewConfigValidationError(path,
+			fmt.Errorf("max_steering_angle_deg must be between 0 and 90, got %v", cfg.MaxSteeringAngleDeg))
+	}
+	center, ratio := cfg.steering()
+	for _, angle := range []float64{center - ratio*cfg.MaxSteeringAngleDeg, center + ratio*cfg.MaxSteeringAngleDeg} {
+		if angle < 0 || angle > 180 {
+			return nil, utils.NewConfigValidationError(path,
+				fmt.Errorf("steering the servo %v degrees from %v is out of its range", cfg.MaxSteeringAngleDeg, center))
+		}
+	}
+
+	deps = append(deps, cfg.Drive...)
+	deps = append(deps, cfg.Left...)
+	deps = append(deps, cfg.Right...)
+	deps = append(deps, cfg.SteeringServo)
+	return deps, nil
+}
+
+func (cfg *Config) steering() (float64, float64) {
+	center, ratio := cfg.SteeringCenterDeg, cfg.SteeringRatio
+	if center == 0 {
+		center = defaultSteeringCenterDeg
+	}
+	if ratio == 0 {
+		ratio = 1
+	}
+	return center, ratio
+}
+
+func init() {
+	resource.RegisterComponent(base.Subtype, ModelName, resource.Registration[base.Base, *Config]{
+		Constructor: func(
+			ctx context.Context, deps resource.Dependencies, conf resource.Config, logger golog.Logger,
+		) (base.Base, error) {
+			return CreateAckermannBase(ctx, deps, conf, logger)
+		},
+	})
+}
+
+// ackermannBase follows the bicycle model: its linear velocity is that of the center of its rear axle, and it
+// turns about a point on the line of the rear axle.
+type ackermannBase struct {
+	resource.Named
+	resource.AlwaysRebuild
+	widthMm              float64
+	wheelbaseMm          float64
+	wheelCircumferenceMm float64
+
+	// maxSteeringAngle is in radians.
+	maxSteeringAngle  float64
+	steeringCenterDeg float64
+	steeringRatio     float64
+
+	drive     []motor.Motor
+	left      []motor.Motor
+	right     []motor.Motor
+	allMotors []motor.Motor
+	steering  servo.Servo
+
+	opMgr  operation.SingleOperationManager
+	logger golog.Logger
+
+	name  string
+	frame *referenceframe.LinkConfig
+}
+
+// CreateAckermannBase returns a new ackermann base defined by the given config.
+func CreateAckermannBase(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger golog.Logger,
+) (base.LocalBase, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+
+	ab := &ackermannBase{
+		Named:                conf.ResourceName().AsNamed(),
+		widthMm:              float64(newConf.WidthMM),
+		wheelbaseMm:          float64(newConf.WheelbaseMM),
+		wheelCircumferenceMm: float64(newConf.WheelCircumferenceMM),
+		maxSteeringAngle:     rdkutils.DegToRad(newConf.MaxSteeringAngleDeg),
+		logger:               logger,
+		name:                 conf.Name,
+		frame:                conf.Frame,
+	}
+	ab.steeringCenterDeg, ab.steeringRatio = newConf.steering()
+
+	motorsFromDeps := func(names []string, kind string) ([]motor.Motor, error) {
+		motors := make([]motor.Motor, 0, len(names))
+		for _, name := range names {
+			m, err := motor.FromDependencies(deps, name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "no %s motor named (%s)", kind, name)
+			}
+			motors = append(motors, m)
+		}
+		return motors, nil
+	}
+	if ab.drive, err = motorsFromDeps(newConf.Drive, "drive"); err != nil {
+		return nil, err
+	}
+	if ab.left, err = motorsFromDeps(newConf.Left, "left"); err != nil {
+		return nil, err
+	}
+	if ab.right, err = motorsFromDeps(newConf.Right, "right"); err != nil {
+		return nil, err
+	}
+	ab.allMotors = append(ab.allMotors, ab.drive...)
+	ab.allMotors = append(ab.allMotors, ab.left...)
+	ab.allMotors = append(ab.allMotors, ab.right...)
+
+	if ab.steering, err = servo.FromDependencies(deps, newConf.SteeringServo); err != nil {
+		return nil, errors.Wrapf(err, "no steering servo named (%s)", newConf.SteeringServo)
+	}
+	return ab, nil
+}
+
+// minTurningRadius returns the radius, in mm, of the tightest turn the center of the rear axle can follow.
+func (ab *ackermannBase) minTurningRadius() float64 {
+	return ab.wheelbaseMm / math.Tan(ab.maxSteeringAngle)
+}
+
+// steer turns the steering wheels to the given angle, in radians, positive to the left.
+func (ab *ackermannBase) steer(ctx context.Context, angle float64) error {
+	servoAngle := ab.steeringCenterDeg + ab.steeringRatio*rdkutils.RadToDeg(angle)
+	servoAngle = math.Max(0, math.Min(math.Round(servoAngle), 180))
+	return ab.steering.Move(ctx, uint32(servoAngle), nil)
+}
+
+// wheelFactors returns how far the left and right rear wheels travel when the center of the rear axle travels one
+// mm along a turn of the given curvature, in 1/mm, positive to the left.
+func (ab *ackermannBase) wheelFactors(curvature float64) (float64, float64) {
+	return 1 - curvature*ab.widthMm/2, 1 + curvature*ab.widthMm/2
+}
+
+// runAll runs the drive motors in parallel, for the given travel of the center of the rear axle and at its speed,
+// along a turn of the given curvature. A travel of zero runs the motors until they are stopped.
+func (ab *ackermannBase) runAll(ctx context.Context, travelMm, mmPerSec, curvature float64, extra map[string]interface{}) error {
+	leftFactor, rightFactor := ab.wheelFactors(curvature)
+	fs := []rdkutils.SimpleFunc{}
+	goFor := func(motors []motor.Motor, factor float64) {
+		rpm := 60 * mmPerSec * math.Abs(factor) / ab.wheelCircumferenceMm
+		revolutions := travelMm * factor / ab.wheelCircumferenceMm
+		if travelMm == 0 {
+			rpm *= sign(factor)
+		}
+		for _, m := range motors {
+			m := m
+			if rpm == 0 || (travelMm != 0 && math.Abs(revolutions) < 1e-9) {
+				fs = append(fs, func(ctx context.Context) error { return m.Stop(ctx, extra) })
+				continue
+			}
+			fs = append(fs, func(ctx context.Context) error { return m.GoFor(ctx, rpm, revolutions, extra) })
+		}
+	}
+	goFor(ab.drive, 1)
+	goFor(ab.left, leftFactor)
+	goFor(ab.right, rightFactor)
+
+	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
+		return multierr.Combine(err, ab.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// MoveStraight commands the base to drive forward or backwards at a linear speed and for a specific distance.
+func (ab *ackermannBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	ctx, done := ab.opMgr.New(ctx)
+	defer done()
+	ab.logger.Debugf("received a MoveStraight with distanceMM:%d, mmPerSec:%.2f", distanceMm, mmPerSec)
+
+	// Stop the motors if the speed or distance are 0
+	if math.Abs(mmPerSec) < 0.0001 || distanceMm == 0 {
+		err := ab.Stop(ctx, nil)
+		if err != nil {
+			return errors.Errorf("error when trying to move straight at a speed and/or distance of 0: %v", err)
+		}
+		return err
+	}
+
+	return ab.straight(ctx, float64(distanceMm), mmPerSec)
+}
+
+func (ab *ackermannBase) straight(ctx context.Context, distanceMm, mmPerSec float64) error {
+	if err := ab.steer(ctx, 0); err != nil {
+		return err
+	}
+	return ab.runAll(ctx, distanceMm, mmPerSec, 0, nil)
+}
+
+// Spin commands the base to turn by the given angle. Since an ackermann base cannot turn in place, it drives along
+// its tightest turn, forward if the angle and the speed have the same sign and backward otherwise.
+func (ab *ackermannBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	ctx, done := ab.opMgr.New(ctx)
+	defer done()
+	ab.logger.Debugf("received a Spin with angleDeg:%.2f, degsPerSec:%.2f", angleDeg, degsPerSec)
+
+	// Stop the motors if the speed or the angle are 0
+	if math.Abs(degsPerSec) < 0.0001 || angleDeg == 0 {
+		err := ab.Stop(ctx, nil)
+		if err != nil {
+			return errors.Errorf("error when trying to spin at a speed of 0: %v", err)
+		}
+		return err
+	}
+
+	radius := ab.minTurningRadius()
+	return ab.turn(ctx, rdkutils.DegToRad(angleDeg), radius*rdkutils.DegToRad(degsPerSec))
+}
+
+// turn drives the base forward along its tightest turn until its heading changes by the given angle, in radians,
+// positive to the left, with the center of its rear axle moving at the given speed.
+func (ab *ackermannBase) turn(ctx context.Context, angle, mmPerSec float64) error {
+	steering := ab.maxSteeringAngle * sign(angle)
+	if err := ab.steer(ctx, steering); err != nil {
+		return err
+	}
+	radius := ab.minTurningRadius()
+	return ab.runAll(ctx, radius*math.Abs(angle), mmPerSec, math.Tan(steering)/ab.wheelbaseMm, nil)
+}
+
+// SetVelocity commands the base to move at the input linear and angular velocities. Angular velocities tighter
+// than the base can turn at the given linear velocity are limited to its tightest turn. Without a linear velocity,
+// the base only steers its wheels towards the direction of the angular velocity.
+func (ab *ackermannBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	ab.opMgr.CancelRunning(ctx)
+
+	ab.logger.Debugf(
+		"received a SetVelocity with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f (mmPerSec), angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	steering := ab.steeringMath(linear.Y, rdkutils.DegToRad(angular.Z))
+	if err := ab.steer(ctx, steering); err != nil {
+		return multierr.Combine(err, ab.Stop(ctx, nil))
+	}
+	if linear.Y == 0 {
+		return ab.Stop(ctx, nil)
+	}
+	return ab.runAll(ctx, 0, linear.Y, math.Tan(steering)/ab.wheelbaseMm, extra)
+}
+
+// steeringMath returns the steering angle for the base to turn at the angular velocity, in rad/s, while moving at
+// the linear velocity, in mm/s, limited to the maximum steering angle.
+func (ab *ackermannBase) steeringMath(mmPerSec, radsPerSec float64) float64 {
+	var steering float64
+	if mmPerSec == 0 {
+		steering = ab.maxSteeringAngle * sign(radsPerSec)
+	} else {
+		steering = math.Atan(ab.wheelbaseMm * radsPerSec / mmPerSec)
+	}
+	if math.Abs(steering) > ab.maxSteeringAngle {
+		ab.logger.Debugf("cannot turn at %.2f deg/s while moving at %.2f mm/s, turning as tightly as possible",
+			rdkutils.RadToDeg(radsPerSec), mmPerSec)
+		steering = ab.maxSteeringAngle * sign(steering)
+	}
+	return steering
+}
+
+// SetPower commands the drive motors to run at the linear power, and steers the wheels by the angular power as a
+// fraction of the maximum steering angle, positive to the left.
+func (ab *ackermannBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	ab.opMgr.CancelRunning(ctx)
+
+	ab.logger.Debugf(
+		"received a SetPower with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f, angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	steering := ab.maxSteeringAngle * math.Max(-1, math.Min(angular.Z, 1))
+	err := ab.steer(ctx, steering)
+
+	power := math.Max(-1, math.Min(linear.Y, 1))
+	leftFactor, rightFactor := ab.wheelFactors(math.Tan(steering) / ab.wheelbaseMm)
+	// keep the outer wheel at the requested power
+	scale := math.Max(1, math.Max(math.Abs(leftFactor), math.Abs(rightFactor)))
+
+	// Send motor commands
+	for _, m := range ab.drive {
+		err = multierr.Combine(err, m.SetPower(ctx, power, extra))
+	}
+	for _, m := range ab.left {
+		err = multierr.Combine(err, m.SetPower(ctx, power*leftFactor/scale, extra))
+	}
+	for _, m := range ab.right {
+		err = multierr.Combine(err, m.SetPower(ctx, power*rightFactor/scale, extra))
+	}
+
+	if err != nil {
+		return multierr.Combine(err, ab.Stop(ctx, nil))
+	}
+
+	return nil
+}
+
+// Stop commands the base to stop moving. The steering is left where it is.
+func (ab *ackermannBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	var err error
+	for _, m := range ab.allMotors {
+		err = multierr.Combine(err, m.Stop(ctx, extra))
+	}
+	return err
+}
+
+func (ab *ackermannBase) IsMoving(ctx context.Context) (bool, error) {
+	for _, m := range ab.allMotors {
+		isMoving, _, err := m.IsPowered(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		if isMoving {
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// Close is called from the client to close the instance of the ackermannBase.
+func (ab *ackermannBase) Close(ctx context.Context) error {
+	return ab.Stop(ctx, nil)
+}
+
+// Width returns the width of the base as configured by the user.
+func (ab *ackermannBase) Width(ctx context.Context) (int, error) {
+	return int(ab.widthMm), nil
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,261 @@
+package ackermann
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+type motorCommand struct {
+	rpm, revolutions, power float64
+	stopped                 bool
+}
+
+// car records the commands sent to the motors and the steering servo of a base, and integrates the motion of the
+// center of its rear axle for the commands of its drive motor.
+type car struct {
+	mu       sync.Mutex
+	commands map[string]motorCommand
+	servo    uint32
+
+	x, y, heading float64
+}
+
+func (c *car) command(name string) motorCommand {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.commands[name]
+}
+
+func (c *car) servoAngle() uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.servo
+}
+
+func (c *car) pose() (float64, float64, float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.x, c.y, c.heading
+}
+
+func (c *car) dependencies(cfg *Config) resource.Dependencies {
+	c.commands = map[string]motorCommand{}
+	center, ratio := cfg.steering()
+	deps := resource.Dependencies{}
+	names := append(append(append([]string{}, cfg.Drive...), cfg.Left...), cfg.Right...)
+	for _, name := range names {
+		name := name
+		m := inject.NewMotor(name)
+		m.GoForFunc = func(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.commands[name] = motorCommand{rpm: rpm, revolutions: revolutions}
+			if name != "drive" || revolutions == 0 {
+				return nil
+			}
+			distance := math.Copysign(revolutions, rpm*revolutions) * float64(cfg.WheelCircumferenceMM)
+			steering := rdkutils.DegToRad((float64(c.servo) - center) / ratio)
+			if steering == 0 {
+				c.x -= distance * math.Sin(c.heading)
+				c.y += distance * math.Cos(c.heading)
+				return nil
+			}
+			radius := float64(cfg.WheelbaseMM) / math.Tan(steering)
+			centerX, centerY := c.x-radius*math.Cos(c.heading), c.y-radius*math.Sin(c.heading)
+			c.heading += distance / radius
+			c.x, c.y = centerX+radius*math.Cos(c.heading), centerY+radius*math.Sin(c.heading)
+			return nil
+		}
+		m.SetPowerFunc = func(ctx context.Context, power float64, extra map[string]interface{}) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.commands[name] = motorCommand{power: power}
+			return nil
+		}
+		m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.commands[name] = motorCommand{stopped: true}
+			return nil
+		}
+		m.IsPoweredFunc = func(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+			return !c.command(name).stopped, 0, nil
+		}
+		deps[motor.Named(name)] = m
+	}
+
+	s := inject.NewServo(cfg.SteeringServo)
+	s.MoveFunc = func(ctx context.Context, angleDeg uint32, extra map[string]interface{}) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.servo = angleDeg
+		return nil
+	}
+	deps[servo.Named(cfg.SteeringServo)] = s
+	return deps
+}
+
+// newTestConfig returns the config of a base whose servo turns two degrees the other way for each degree of steering.
+func newTestConfig() *Config {
+	return &Config{
+		WidthMM:              200,
+		WheelbaseMM:          300,
+		WheelCircumferenceMM: 100,
+		Drive:                []string{"drive"},
+		SteeringServo:        "steering",
+		MaxSteeringAngleDeg:  30,
+		SteeringRatio:        -2,
+	}
+}
+
+func newTestBase(t *testing.T, cfg *Config) (*ackermannBase, *car) {
+	t.Helper()
+	c := &car{}
+	b, err := CreateAckermannBase(context.Background(), c.dependencies(cfg), resource.Config{
+		Name:                "test",
+		API:                 base.Subtype,
+		Model:               ModelName,
+		ConvertedAttributes: cfg,
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	ab, ok := b.(*ackermannBase)
+	test.That(t, ok, test.ShouldBeTrue)
+	return ab, c
+}
+
+func TestConfig(t *testing.T) {
+	cfg := newTestConfig()
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"drive", "steering"})
+
+	cfg.Left = []string{"left"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "same number of motors")
+
+	cfg = newTestConfig()
+	cfg.SteeringServo = ""
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "steering_servo")
+
+	cfg = newTestConfig()
+	cfg.MaxSteeringAngleDeg = 90
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "max_steering_angle_deg")
+
+	cfg = newTestConfig()
+	cfg.SteeringRatio = 4
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "out of its range")
+}
+
+func TestAckermannBase(t *testing.T) {
+	ctx := context.Background()
+	ab, c := newTestBase(t, newTestConfig())
+	minRadius := 300 / math.Tan(math.Pi/6)
+
+	width, err := ab.Width(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, width, test.ShouldEqual, 200)
+
+	t.Run("set velocity", func(t *testing.T) {
+		// a turn with a radius of 600mm needs the tangent of the steering angle to be one half
+		turn := rdkutils.RadToDeg(100.0 / 600)
+		test.That(t, ab.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{Z: turn}, nil), test.ShouldBeNil)
+		test.That(t, c.servoAngle(), test.ShouldEqual, uint32(math.Round(90-2*rdkutils.RadToDeg(math.Atan(0.5)))))
+		test.That(t, c.command("drive").rpm, test.ShouldAlmostEqual, 60)
+		test.That(t, c.command("drive").revolutions, test.ShouldEqual, 0)
+
+		// backing up with the same angular velocity steers the other way
+		test.That(t, ab.SetVelocity(ctx, r3.Vector{Y: -100}, r3.Vector{Z: turn}, nil), test.ShouldBeNil)
+		test.That(t, c.servoAngle(), test.ShouldEqual, uint32(math.Round(90+2*rdkutils.RadToDeg(math.Atan(0.5)))))
+		test.That(t, c.command("drive").rpm, test.ShouldAlmostEqual, -60)
+
+		// turns too tight are limited to the tightest one
+		test.That(t, ab.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{Z: -90}, nil), test.ShouldBeNil)
+		test.That(t, c.servoAngle(), test.ShouldEqual, 150)
+
+		// without a linear velocity, the base only steers
+		test.That(t, ab.SetVelocity(ctx, r3.Vector{}, r3.Vector{Z: 10}, nil), test.ShouldBeNil)
+		test.That(t, c.servoAngle(), test.ShouldEqual, 30)
+		test.That(t, c.command("drive").stopped, test.ShouldBeTrue)
+		moving, err := ab.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+	})
+
+	t.Run("move straight", func(t *testing.T) {
+		test.That(t, ab.MoveStraight(ctx, 1000, 200, nil), test.ShouldBeNil)
+		test.That(t, c.servoAngle(), test.ShouldEqual, 90)
+		test.That(t, c.command("drive"), test.ShouldResemble, motorCommand{rpm: 120, revolutions: 10})
+	})
+
+	t.Run("spin", func(t *testing.T) {
+		x0, y0, heading0 := c.pose()
+		test.That(t, ab.Spin(ctx, -90, 45, nil), test.ShouldBeNil)
+		test.That(t, c.servoAngle(), test.ShouldEqual, 150)
+		cmd := c.command("drive")
+		test.That(t, cmd.revolutions, test.ShouldAlmostEqual, minRadius*math.Pi/2/100)
+		test.That(t, cmd.rpm, test.ShouldAlmostEqual, minRadius*math.Pi/4/100*60)
+
+		// a quarter turn to the right ends up forward and to the right by the radius of the turn
+		x, y, heading := c.pose()
+		test.That(t, heading-heading0, test.ShouldAlmostEqual, -math.Pi/2)
+		test.That(t, x-x0, test.ShouldAlmostEqual, minRadius)
+		test.That(t, y-y0, test.ShouldAlmostEqual, minRadius)
+	})
+
+	t.Run("set power", func(t *testing.T) {
+		test.That(t, ab.SetPower(ctx, r3.Vector{Y: 0.5}, r3.Vector{Z: 0.5}, nil), test.ShouldBeNil)
+		test.That(t, c.servoAngle(), test.ShouldEqual, 60)
+		test.That(t, c.command("drive").power, test.ShouldEqual, 0.5)
+	})
+
+	test.That(t, ab.Close(ctx), test.ShouldBeNil)
+	test.That(t, c.command("drive").stopped, test.ShouldBeTrue)
+}
+
+func TestAckermannBaseWithWheelMotors(t *testing.T) {
+	ctx := context.Background()
+	cfg := newTestConfig()
+	cfg.Drive = nil
+	cfg.Left = []string{"left"}
+	cfg.Right = []string{"right"}
+	ab, c := newTestBase(t, cfg)
+	minRadius := 300 / math.Tan(math.Pi/6)
+
+	// the inner wheel turns slower than the outer one, as they are 200mm apart
+	test.That(t, ab.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{Z: rdkutils.RadToDeg(100.0 / 600)}, nil), test.ShouldBeNil)
+	test.That(t, c.command("left").rpm, test.ShouldAlmostEqual, 50)
+	test.That(t, c.command("right").rpm, test.ShouldAlmostEqual, 70)
+
+	test.That(t, ab.Spin(ctx, 180, 90, nil), test.ShouldBeNil)
+	test.That(t, c.command("left").revolutions, test.ShouldAlmostEqual, (minRadius-100)*math.Pi/100)
+	test.That(t, c.command("right").revolutions, test.ShouldAlmostEqual, (minRadius+100)*math.Pi/100)
+	test.That(t, c.command("left").rpm, test.ShouldAlmostEqual, (minRadius-100)*math.Pi/2/100*60)
+
+	test.That(t, ab.SetPower(ctx, r3.Vector{Y: 1}, r3.Vector{Z: -1}, nil), test.ShouldBeNil)
+	test.That(t, c.command("left").power, test.ShouldEqual, 1)
+	test.That(t, c.command("right").power, test.ShouldBeLessThan, 1)
+
+	test.That(t, ab.Stop(ctx, nil), test.ShouldBeNil)
+	test.That(t, c.command("left").stopped, test.ShouldBeTrue)
+	test.That(t, c.command("right").stopped, test.ShouldBeTrue)
+}
@@ -0,0 +1,153 @@
+package ackermann
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/base/kinematicbase"
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// defaultLinearMmPerSec is the speed at which a kinematic ackermann base moves between inputs.
+const defaultLinearMmPerSec = 200
+
+type kinematicAckermannBase struct {
+	*ackermannBase
+	slam   slam.Service
+	model  referenceframe.Model
+	dubins *motionplan.Dubins
+}
+
+// WrapWithKinematics takes an ackermannBase component and adds a slam service to it
+// It also adds kinematic model so that it can be controlled.
+// Since the base has to turn to change direction, its model has a heading after its two translational degrees of
+// freedom, and it moves between inputs along the shortest Dubins path for its tightest turn. Its inputs are where
+// the SLAM service places it on the plane of the map.
+func (ab *ackermannBase) WrapWithKinematics(ctx context.Context, slamSvc slam.Service) (base.KinematicBase, error) {
+	// gets the extents of the SLAM map
+	data, err := slam.GetPointCloudMapFull(ctx, slamSvc)
+	if err != nil {
+		return nil, err
+	}
+	dims, err := pointcloud.GetPCDMetaData(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	geometry, err := base.CollisionGeometry(ab.frame)
+	if err != nil {
+		return nil, err
+	}
+	model, err := Model(ab.name, geometry, []referenceframe.Limit{{Min: dims.MinX, Max: dims.MaxX}, {Min: dims.MinZ, Max: dims.MaxZ}})
+	if err != nil {
+		return nil, err
+	}
+	dubins, err := motionplan.NewDubins(ab.minTurningRadius(), 1)
+	if err != nil {
+		return nil, err
+	}
+	return &kinematicAckermannBase{
+		ackermannBase: ab,
+		slam:          slamSvc,
+		model:         model,
+		dubins:        dubins,
+	}, nil
+}
+
+func (kab *kinematicAckermannBase) ModelFrame() referenceframe.Model {
+	return kab.model
+}
+
+func (kab *kinematicAckermannBase) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	x, y, heading, err := kinematicbase.PlanarPose(ctx, kab.slam)
+	if err != nil {
+		return nil, err
+	}
+	return []referenceframe.Input{{Value: x}, {Value: y}, {Value: heading}}, nil
+}
+
+// GoToInputs drives the base to the goal position and heading along the shortest Dubins path: two of its tightest
+// turns joined by either a straight line or a third turn.
+func (kab *kinematicAckermannBase) GoToInputs(ctx context.Context, goal []referenceframe.Input) error {
+	if _, err := kab.model.Transform(goal); err != nil {
+		return err
+	}
+	ctx, done := kab.opMgr.New(ctx)
+	defer done()
+
+	current, err := kab.CurrentInputs(ctx)
+	if err != nil {
+		return err
+	}
+
+	// dubins states are headings from the first axis, while the base heads along the second one at a heading of 0.
+	start := []float64{current[0].Value, current[1].Value, current[2].Value + math.Pi/2}
+	end := []float64{goal[0].Value, goal[1].Value, goal[2].Value + math.Pi/2}
+	path := kab.dubins.AllPaths(start, end, true)[0]
+	if math.IsInf(path.TotalLen, 1) {
+		return errors.New("no path found to the goal inputs")
+	}
+
+	first, last, middle := path.DubinsPath[0], path.DubinsPath[1], path.DubinsPath[2]
+	segments := []func(ctx context.Context) error{
+		func(ctx context.Context) error { return kab.turnSegment(ctx, first) },
+		func(ctx context.Context) error { return kab.straightSegment(ctx, middle) },
+		func(ctx context.Context) error { return kab.turnSegment(ctx, last) },
+	}
+	if !path.Straight {
+		// the middle of a turn-turn-turn path is a turn the other way from the first one
+		segments[1] = func(ctx context.Context) error { return kab.turnSegment(ctx, -sign(first)*middle) }
+	}
+	for _, segment := range segments {
+		if err := segment(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// turnSegment turns the base by the given angle along its tightest turn.
+func (kab *kinematicAckermannBase) turnSegment(ctx context.Context, angle float64) error {
+	if angle == 0 {
+		return nil
+	}
+	return kab.turn(ctx, angle, defaultLinearMmPerSec)
+}
+
+// straightSegment moves the base forward by the given distance.
+func (kab *kinematicAckermannBase) straightSegment(ctx context.Context, distanceMm float64) error {
+	if distanceMm == 0 {
+		return nil
+	}
+	return kab.straight(ctx, distanceMm, defaultLinearMmPerSec)
+}
+
+// Model builds the kinematic model associated with the kinematicAckermannBase: it translates on the ground plane,
+// then turns about the axis normal to it by its heading, in radians, and the collision geometry turns with it.
+// Note that this model is not intended to be registered in the frame system.
+func Model(name string, collisionGeometry spatialmath.Geometry, limits []referenceframe.Limit) (referenceframe.Model, error) {
+	frame2D, err := referenceframe.NewMobile2DFrame(name+"_position", limits, nil)
+	if err != nil {
+		return nil, err
+	}
+	heading, err := referenceframe.NewRotationalFrame(name+"_heading", spatialmath.R4AA{RZ: 1}, referenceframe.Limit{
+		Min: -2 * math.Pi, Max: 2 * math.Pi,
+	})
+	if err != nil {
+		return nil, err
+	}
+	geometry, err := referenceframe.NewStaticFrameWithGeometry(collisionGeometry.Label(), spatialmath.NewZeroPose(), collisionGeometry)
+	if err != nil {
+		return nil, err
+	}
+	model := referenceframe.NewSimpleModel(name)
+	model.OrdTransforms = []referenceframe.Frame{frame2D, heading, geometry}
+	return model, nil
+}
@@ -0,0 +1,120 @@
+package ackermann
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// newSLAM returns a slam service whose map spans from -5000 to 5000 along every axis, and which places the car at its
+// pose on the XZ plane of the map.
+func newSLAM(t *testing.T, c *car) slam.Service {
+	t.Helper()
+	cloud := pointcloud.New()
+	test.That(t, cloud.Set(r3.Vector{X: -5000, Y: -5000, Z: -5000}, nil), test.ShouldBeNil)
+	test.That(t, cloud.Set(r3.Vector{X: 5000, Y: 5000, Z: 5000}, nil), test.ShouldBeNil)
+	var buf bytes.Buffer
+	test.That(t, pointcloud.ToPCD(cloud, &buf, pointcloud.PCDBinary), test.ShouldBeNil)
+
+	slamSvc := inject.NewSLAMService("slam")
+	slamSvc.GetPointCloudMapFunc = func(ctx context.Context) (func() ([]byte, error), error) {
+		sent := false
+		return func() ([]byte, error) {
+			if sent {
+				return nil, io.EOF
+			}
+			sent = true
+			return buf.Bytes(), nil
+		}, nil
+	}
+	slamSvc.GetPositionFunc = func(ctx context.Context) (spatialmath.Pose, string, error) {
+		x, y, heading := c.pose()
+		// the car faces along the Z axis of the map at a heading of 0, and turns about its Y axis
+		return spatialmath.NewPose(r3.Vector{X: x, Z: y}, &spatialmath.R4AA{Theta: -heading, RY: 1}), "", nil
+	}
+	return slamSvc
+}
+
+// normalizeAngle returns the angle in radians between -pi and pi.
+func normalizeAngle(angle float64) float64 {
+	return math.Atan2(math.Sin(angle), math.Cos(angle))
+}
+
+func TestWrapWithKinematics(t *testing.T) {
+	ctx := context.Background()
+	ab, c := newTestBase(t, newTestConfig())
+
+	_, err := ab.WrapWithKinematics(ctx, newSLAM(t, c))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	ab.frame = &referenceframe.LinkConfig{
+		Geometry: &spatialmath.GeometryConfig{Type: spatialmath.SphereType, R: 200, Label: "base"},
+	}
+	kb, err := ab.WrapWithKinematics(ctx, newSLAM(t, c))
+	test.That(t, err, test.ShouldBeNil)
+
+	model := kb.ModelFrame()
+	limits := model.DoF()
+	test.That(t, limits, test.ShouldHaveLength, 3)
+	test.That(t, limits[0], test.ShouldResemble, referenceframe.Limit{Min: -5000, Max: 5000})
+
+	// a heading of a quarter turn points the base's forward axis towards -X
+	pose, err := model.Transform([]referenceframe.Input{{100}, {200}, {math.Pi / 2}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(pose.Point(), r3.Vector{X: 100, Y: 200}, 1e-9), test.ShouldBeTrue)
+	forward := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(r3.Vector{Y: 1})).Point().Sub(pose.Point())
+	test.That(t, spatialmath.R3VectorAlmostEqual(forward, r3.Vector{X: -1}, 1e-9), test.ShouldBeTrue)
+
+	// the inputs come from the SLAM service
+	inputs, err := kb.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, referenceframe.InputsToFloats(inputs), test.ShouldResemble, []float64{0, 0, 0})
+
+	for _, goal := range [][]float64{
+		{0, 1000, 0},
+		{1000, 1000, -math.Pi / 2},
+		{-500, 200, math.Pi},
+		// a goal close behind the base needs three turns
+		{0, 800, -3},
+		{100, 700, 0.5},
+	} {
+		test.That(t, kb.GoToInputs(ctx, referenceframe.FloatsToInputs(goal)), test.ShouldBeNil)
+
+		x, y, heading := c.pose()
+		test.That(t, x, test.ShouldAlmostEqual, goal[0], 1e-3)
+		test.That(t, y, test.ShouldAlmostEqual, goal[1], 1e-3)
+		test.That(t, normalizeAngle(heading-goal[2]), test.ShouldAlmostEqual, 0, 1e-6)
+
+		inputs, err := kb.CurrentInputs(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, inputs[0].Value, test.ShouldAlmostEqual, goal[0], 1e-3)
+		test.That(t, inputs[1].Value, test.ShouldAlmostEqual, goal[1], 1e-3)
+		test.That(t, normalizeAngle(inputs[2].Value-goal[2]), test.ShouldAlmostEqual, 0, 1e-6)
+	}
+
+	test.That(t, kb.GoToInputs(ctx, referenceframe.FloatsToInputs([]float64{6000, 0, 0})), test.ShouldNotBeNil)
+}
+
+func TestModelGeometry(t *testing.T) {
+	// a geometry ahead of the center of the base turns with it
+	box, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{Y: 100}), r3.Vector{X: 10, Y: 10, Z: 10}, "base")
+	test.That(t, err, test.ShouldBeNil)
+	model, err := Model("car", box, []referenceframe.Limit{{Min: -5000, Max: 5000}, {Min: -5000, Max: 5000}})
+	test.That(t, err, test.ShouldBeNil)
+	geometries, err := model.Geometries([]referenceframe.Input{{100}, {200}, {math.Pi / 2}})
+	test.That(t, err, test.ShouldBeNil)
+	geometry := geometries.GeometryByName("car:base")
+	test.That(t, geometry, test.ShouldNotBeNil)
+	test.That(t, spatialmath.R3VectorAlmostEqual(geometry.Pose().Point(), r3.Vector{X: 0, Y: 200}, 1e-9), test.ShouldBeTrue)
+}
@@ -0,0 +1,303 @@
+// Package holonomic implements bases that can move in any direction on the ground plane without turning first,
+// like mecanum and omni wheeled bases.
+package holonomic
+
+import (
+	"context"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/operation"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// minWheelTravelMm is the distance under which a wheel is considered not to move at all during a motion. Motors
+// are stopped rather than told to go for zero revolutions, which would make them run forever.
+const minWheelTravelMm = 1e-6
+
+// A wheel of a holonomic base. The base frame has +Y forward, +X to the right, and +Z up, so positive angular
+// velocities turn the base counterclockwise (to the left) when seen from above.
+type wheel struct {
+	motor motor.Motor
+	// position of the center of the wheel's contact patch, in mm.
+	position r3.Vector
+	// drive is the unit direction the contact patch pushes the base in when the motor turns forward.
+	drive r3.Vector
+	// roller is the unit direction in which the wheel cannot slip. For omni wheels it is the drive direction, for
+	// mecanum wheels it is the direction of the axles of the rollers touching the ground.
+	roller r3.Vector
+}
+
+// rimSpeed returns the speed the rim of the wheel must move at for the base to move at the given linear velocity
+// and turn at the given angular velocity, in radians. The units of the result are those of the linear velocity,
+// so this also converts distances to wheel travel.
+func (w *wheel) rimSpeed(linear r3.Vector, angular float64) float64 {
+	contact := r3.Vector{X: linear.X - angular*w.position.Y, Y: linear.Y + angular*w.position.X}
+	return contact.Dot(w.roller) / w.drive.Dot(w.roller)
+}
+
+// checkWheels makes sure the wheels can move the base in every direction and turn it.
+func checkWheels(wheels []*wheel) error {
+	// the base is fully controllable if the jacobian from base velocities to rim speeds has rank 3,
+	// that is if the determinant of its gram matrix is not 0.
+	var gram [3][3]float64
+	for _, w := range wheels {
+		row := [3]float64{w.rimSpeed(r3.Vector{X: 1}, 0), w.rimSpeed(r3.Vector{Y: 1}, 0), w.rimSpeed(r3.Vector{}, 1)}
+		for i := range row {
+			for j := range row {
+				gram[i][j] += row[i] * row[j]
+			}
+		}
+	}
+	det := gram[0][0]*(gram[1][1]*gram[2][2]-gram[1][2]*gram[2][1]) -
+		gram[0][1]*(gram[1][0]*gram[2][2]-gram[1][2]*gram[2][0]) +
+		gram[0][2]*(gram[1][0]*gram[2][1]-gram[1][1]*gram[2][0])
+	if math.Abs(det) < 1e-9 {
+		return errors.New("the wheels cannot move the base in every direction, check their positions and angles")
+	}
+	return nil
+}
+
+type holonomicBase struct {
+	resource.Named
+	resource.AlwaysRebuild
+
+	widthMm              int
+	wheelCircumferenceMm float64
+	wheels               []*wheel
+
+	opMgr  operation.SingleOperationManager
+	logger golog.Logger
+
+	name  string
+	frame *referenceframe.LinkConfig
+}
+
+func newHolonomicBase(
+	deps resource.Dependencies,
+	conf resource.Config,
+	widthMm int,
+	wheelCircumferenceMm float64,
+	wheels []*wheel,
+	motorNames []string,
+	logger golog.Logger,
+) (*holonomicBase, error) {
+	for i, w := range wheels {
+		m, err := motor.FromDependencies(deps, motorNames[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "no motor named (%s)", motorNames[i])
+		}
+		w.motor = m
+	}
+	return &holonomicBase{
+		Named:                conf.ResourceName().AsNamed(),
+		widthMm:              widthMm,
+		wheelCircumferenceMm: wheelCircumferenceMm,
+		wheels:               wheels,
+		logger:               logger,
+		name:                 conf.Name,
+		frame:                conf.Frame,
+	}, nil
+}
+
+// MoveStraight commands the base to drive forward or backwards at a linear speed and for a specific distance.
+func (hb *holonomicBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSec float64, extra map[string]interface{}) error {
+	ctx, done := hb.opMgr.New(ctx)
+	defer done()
+	hb.logger.Debugf("received a MoveStraight with distanceMM:%d, mmPerSec:%.2f", distanceMm, mmPerSec)
+
+	// Stop the motors if the speed or distance are 0
+	if math.Abs(mmPerSec) < 0.0001 || distanceMm == 0 {
+		err := hb.Stop(ctx, nil)
+		if err != nil {
+			return errors.Errorf("error when trying to move straight at a speed and/or distance of 0: %v", err)
+		}
+		return err
+	}
+
+	return hb.translate(ctx, r3.Vector{Y: float64(distanceMm)}, mmPerSec)
+}
+
+// translate moves the base by the given displacement in mm, in its own frame, without turning it.
+func (hb *holonomicBase) translate(ctx context.Context, displacement r3.Vector, mmPerSec float64) error {
+	distance := displacement.Norm()
+	if distance == 0 {
+		return nil
+	}
+	return hb.runAll(ctx, func(w *wheel) (float64, float64) {
+		travel := w.rimSpeed(displacement, 0)
+		// the wheel covers its travel in the time the base covers its distance
+		return travel, math.Abs(travel) / distance * mmPerSec
+	})
+}
+
+// Spin commands the base to turn about its center at an angular speed and for a specific angle.
+func (hb *holonomicBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, extra map[string]interface{}) error {
+	ctx, done := hb.opMgr.New(ctx)
+	defer done()
+	hb.logger.Debugf("received a Spin with angleDeg:%.2f, degsPerSec:%.2f", angleDeg, degsPerSec)
+
+	// Stop the motors if the speed or the angle are 0
+	if math.Abs(degsPerSec) < 0.0001 || angleDeg == 0 {
+		err := hb.Stop(ctx, nil)
+		if err != nil {
+			return errors.Errorf("error when trying to spin at a speed of 0: %v", err)
+		}
+		return err
+	}
+
+	return hb.runAll(ctx, func(w *wheel) (float64, float64) {
+		travel := w.rimSpeed(r3.Vector{}, rdkutils.DegToRad(angleDeg))
+		return travel, math.Abs(travel) / math.Abs(angleDeg) * degsPerSec
+	})
+}
+
+// runAll runs every wheel for the travel and at the rim speed returned by motion, both in mm. The direction of
+// each wheel is the product of the signs of its travel and speed, as with motor.GoFor.
+func (hb *holonomicBase) runAll(ctx context.Context, motion func(w *wheel) (travelMm, mmPerSec float64)) error {
+	fs := []rdkutils.SimpleFunc{}
+	for _, w := range hb.wheels {
+		w := w
+		travel, speed := motion(w)
+		if math.Abs(travel) < minWheelTravelMm {
+			fs = append(fs, func(ctx context.Context) error { return w.motor.Stop(ctx, nil) })
+			continue
+		}
+		rpm := 60 * speed / hb.wheelCircumferenceMm
+		revolutions := travel / hb.wheelCircumferenceMm
+		fs = append(fs, func(ctx context.Context) error { return w.motor.GoFor(ctx, rpm, revolutions, nil) })
+	}
+
+	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
+		return multierr.Combine(err, hb.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// SetVelocity commands the base to move at the input linear and angular velocities. Unlike a differential base,
+// linear.X moves the base sideways, to its right.
+func (hb *holonomicBase) SetVelocity(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	hb.opMgr.CancelRunning(ctx)
+
+	hb.logger.Debugf(
+		"received a SetVelocity with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f (mmPerSec), angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	fs := []rdkutils.SimpleFunc{}
+	for _, rpm := range hb.velocityMath(linear, angular.Z) {
+		rpm := rpm
+		fs = append(fs, func(ctx context.Context) error {
+			if rpm.rpm == 0 {
+				return rpm.motor.Stop(ctx, nil)
+			}
+			return rpm.motor.GoFor(ctx, rpm.rpm, 0, nil)
+		})
+	}
+	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
+		return multierr.Combine(err, hb.Stop(ctx, nil))
+	}
+	return nil
+}
+
+type motorRPM struct {
+	motor motor.Motor
+	rpm   float64
+}
+
+// velocityMath returns the rpm of each wheel's motor for the base to move at the linear velocity, in mm/s, while
+// turning at the angular velocity, in deg/s.
+func (hb *holonomicBase) velocityMath(linear r3.Vector, degsPerSec float64) []motorRPM {
+	rpms := make([]motorRPM, 0, len(hb.wheels))
+	for _, w := range hb.wheels {
+		speed := w.rimSpeed(linear, rdkutils.DegToRad(degsPerSec))
+		rpms = append(rpms, motorRPM{motor: w.motor, rpm: 60 * speed / hb.wheelCircumferenceMm})
+	}
+	return rpms
+}
+
+// SetPower commands the base motors to run at powers corresponding to input linear and angular powers. Powers are
+// scaled down together when a wheel would need more than full power, so that the base keeps its direction.
+func (hb *holonomicBase) SetPower(ctx context.Context, linear, angular r3.Vector, extra map[string]interface{}) error {
+	hb.opMgr.CancelRunning(ctx)
+
+	hb.logger.Debugf(
+		"received a SetPower with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f, angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
+		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
+
+	powers := hb.powerMath(linear, angular.Z)
+
+	// Send motor commands
+	var err error
+	for i, w := range hb.wheels {
+		err = multierr.Combine(err, w.motor.SetPower(ctx, powers[i], extra))
+	}
+
+	if err != nil {
+		return multierr.Combine(err, hb.Stop(ctx, nil))
+	}
+
+	return nil
+}
+
+// powerMath returns the power of each wheel's motor for the given linear and angular powers. A full angular power
+// spins the base as fast as a full linear power moves its furthest wheel.
+func (hb *holonomicBase) powerMath(linear r3.Vector, angular float64) []float64 {
+	var radius float64
+	for _, w := range hb.wheels {
+		radius = math.Max(radius, math.Hypot(w.position.X, w.position.Y))
+	}
+	if radius == 0 {
+		radius = 1
+	}
+
+	powers := make([]float64, 0, len(hb.wheels))
+	maxPower := 1.0
+	for _, w := range hb.wheels {
+		p := w.rimSpeed(r3.Vector{X: linear.X, Y: linear.Y}, angular/radius)
+		maxPower = math.Max(maxPower, math.Abs(p))
+		powers = append(powers, p)
+	}
+	for i := range powers {
+		powers[i] /= maxPower
+	}
+	return powers
+}
+
+// Stop commands the base to stop moving.
+func (hb *holonomicBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	var err error
+	for _, w := range hb.wheels {
+		err = multierr.Combine(err, w.motor.Stop(ctx, extra))
+	}
+	return err
+}
+
+func (hb *holonomicBase) IsMoving(ctx context.Context) (bool, error) {
+	for _, w := range hb.wheels {
+		isMoving, _, err := w.motor.IsPowered(ctx, nil)
+		if err != nil {
+			return false, err
+		}
+		if isMoving {
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// Close is called from the client to close the instance of the holonomicBase.
+func (hb *holonomicBase) Close(ctx context.Context) error {
+	return hb.Stop(ctx, nil)
+}
+
+// Width returns the width of the base as configured by the user.
+func (hb *holonomicBase) Width(ctx context.Context) (int, error) {
+	return hb.widthMm, nil
+}
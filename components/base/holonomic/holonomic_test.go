@@ -0,0 +1,295 @@
+package holonomic
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+type motorCommand struct {
+	rpm, revolutions, power float64
+	stopped                 bool
+}
+
+// commandRecorder records the last command sent to each motor.
+type commandRecorder struct {
+	mu       sync.Mutex
+	commands map[string]motorCommand
+}
+
+func (cr *commandRecorder) command(name string) motorCommand {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return cr.commands[name]
+}
+
+func (cr *commandRecorder) set(name string, cmd motorCommand) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.commands[name] = cmd
+}
+
+func motorDependencies(names ...string) (resource.Dependencies, *commandRecorder) {
+	recorder := &commandRecorder{commands: map[string]motorCommand{}}
+	deps := resource.Dependencies{}
+	for _, name := range names {
+		name := name
+		m := inject.NewMotor(name)
+		m.GoForFunc = func(ctx context.Context, rpm, revolutions float64, extra map[string]interface{}) error {
+			recorder.set(name, motorCommand{rpm: rpm, revolutions: revolutions})
+			return nil
+		}
+		m.SetPowerFunc = func(ctx context.Context, power float64, extra map[string]interface{}) error {
+			recorder.set(name, motorCommand{power: power})
+			return nil
+		}
+		m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			recorder.set(name, motorCommand{stopped: true})
+			return nil
+		}
+		m.IsPoweredFunc = func(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+			cmd := recorder.command(name)
+			return !cmd.stopped, 0, nil
+		}
+		deps[motor.Named(name)] = m
+	}
+	return deps, recorder
+}
+
+// travel returns how far the motor turns the rim of its wheel, in mm, for the recorded command.
+func travel(cmd motorCommand, circumference float64) float64 {
+	if cmd.stopped {
+		return 0
+	}
+	return math.Copysign(cmd.revolutions, cmd.rpm*cmd.revolutions) * circumference
+}
+
+func newMecanumConfig() *MecanumConfig {
+	return &MecanumConfig{
+		WidthMM:              300,
+		WheelbaseMM:          200,
+		WheelCircumferenceMM: 100,
+		FrontLeft:            "fl",
+		FrontRight:           "fr",
+		BackLeft:             "bl",
+		BackRight:            "br",
+	}
+}
+
+func TestMecanumConfig(t *testing.T) {
+	cfg := newMecanumConfig()
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"fl", "fr", "bl", "br"})
+
+	cfg.WheelbaseMM = 0
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError, utils.NewConfigValidationFieldRequiredError("path", "wheelbase_mm"))
+
+	cfg = newMecanumConfig()
+	cfg.BackLeft = ""
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeError, utils.NewConfigValidationFieldRequiredError("path", "back_left"))
+
+	test.That(t, checkWheels(newMecanumConfig().wheels()), test.ShouldBeNil)
+}
+
+func TestOmniConfig(t *testing.T) {
+	kiwi := &OmniConfig{
+		WheelCircumferenceMM: 100,
+		Wheels: []OmniWheelConfig{
+			{Motor: "a", YMM: 100, AngleDeg: 90},
+			{Motor: "b", XMM: 100 * math.Sin(math.Pi/3*2), YMM: 100 * math.Cos(math.Pi/3*2), AngleDeg: 330},
+			{Motor: "c", XMM: -100 * math.Sin(math.Pi/3*2), YMM: 100 * math.Cos(math.Pi/3*2), AngleDeg: 210},
+		},
+	}
+	deps, err := kiwi.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"a", "b", "c"})
+	test.That(t, kiwi.width(), test.ShouldEqual, 173)
+
+	kiwi.Wheels[1].Motor = ""
+	_, err = kiwi.Validate("path")
+	test.That(t, err, test.ShouldBeError, utils.NewConfigValidationFieldRequiredError("path.wheels.1", "motor"))
+
+	// all the wheels pointing the same way cannot move the base sideways
+	parallel := &OmniConfig{
+		WheelCircumferenceMM: 100,
+		Wheels: []OmniWheelConfig{
+			{Motor: "a", XMM: -100},
+			{Motor: "b", XMM: 100},
+			{Motor: "c", YMM: 100},
+		},
+	}
+	_, err = parallel.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "every direction")
+
+	_, err = (&OmniConfig{WheelCircumferenceMM: 100, Wheels: parallel.Wheels[:2]}).Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 wheels")
+}
+
+func newMecanumBase(t *testing.T) (*holonomicBase, *commandRecorder) {
+	t.Helper()
+	deps, recorder := motorDependencies("fl", "fr", "bl", "br")
+	conf := resource.Config{
+		Name:                "test",
+		API:                 base.Subtype,
+		Model:               MecanumModelName,
+		ConvertedAttributes: newMecanumConfig(),
+	}
+	b, err := CreateMecanumBase(context.Background(), deps, conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	hb, ok := b.(*holonomicBase)
+	test.That(t, ok, test.ShouldBeTrue)
+	return hb, recorder
+}
+
+func TestMecanumBase(t *testing.T) {
+	ctx := context.Background()
+	hb, recorder := newMecanumBase(t)
+
+	width, err := hb.Width(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, width, test.ShouldEqual, 300)
+
+	rpms := func() []float64 {
+		var rpms []float64
+		for _, name := range []string{"fl", "fr", "bl", "br"} {
+			cmd := recorder.command(name)
+			test.That(t, cmd.revolutions, test.ShouldEqual, 0)
+			rpms = append(rpms, cmd.rpm)
+		}
+		return rpms
+	}
+
+	t.Run("set velocity", func(t *testing.T) {
+		// forward: every wheel turns at 100 mm/s
+		test.That(t, hb.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+		test.That(t, rpms(), test.ShouldResemble, []float64{60, 60, 60, 60})
+
+		// right: front left and back right forward, the others backward
+		test.That(t, hb.SetVelocity(ctx, r3.Vector{X: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+		test.That(t, rpms(), test.ShouldResemble, []float64{60, -60, -60, 60})
+
+		// counterclockwise: left wheels backward, right wheels forward, by the distance of the wheels along each
+		// axis from the center
+		test.That(t, hb.SetVelocity(ctx, r3.Vector{}, r3.Vector{Z: 90}, nil), test.ShouldBeNil)
+		speed := math.Pi / 2 * (150 + 100) / 100 * 60
+		for i, rpm := range rpms() {
+			test.That(t, rpm, test.ShouldAlmostEqual, []float64{-speed, speed, -speed, speed}[i])
+		}
+
+		test.That(t, hb.Stop(ctx, nil), test.ShouldBeNil)
+		moving, err := hb.IsMoving(ctx)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, moving, test.ShouldBeFalse)
+	})
+
+	t.Run("diagonal velocity stops the idle wheels", func(t *testing.T) {
+		test.That(t, hb.SetVelocity(ctx, r3.Vector{X: -100, Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+		test.That(t, recorder.command("fl").stopped, test.ShouldBeTrue)
+		test.That(t, recorder.command("br").stopped, test.ShouldBeTrue)
+		test.That(t, recorder.command("fr").rpm, test.ShouldEqual, 120)
+		test.That(t, recorder.command("bl").rpm, test.ShouldEqual, 120)
+	})
+
+	t.Run("move straight", func(t *testing.T) {
+		test.That(t, hb.MoveStraight(ctx, -500, 100, nil), test.ShouldBeNil)
+		for _, name := range []string{"fl", "fr", "bl", "br"} {
+			cmd := recorder.command(name)
+			test.That(t, cmd.rpm, test.ShouldEqual, 60)
+			test.That(t, cmd.revolutions, test.ShouldEqual, -5)
+		}
+	})
+
+	t.Run("spin", func(t *testing.T) {
+		test.That(t, hb.Spin(ctx, -90, 45, nil), test.ShouldBeNil)
+		wheelTravel := math.Pi / 2 * (150 + 100)
+		for i, name := range []string{"fl", "fr", "bl", "br"} {
+			cmd := recorder.command(name)
+			test.That(t, travel(cmd, 100), test.ShouldAlmostEqual, []float64{1, -1, 1, -1}[i]*wheelTravel)
+			// the spin takes two seconds
+			test.That(t, math.Abs(cmd.rpm), test.ShouldAlmostEqual, wheelTravel/2/100*60)
+		}
+	})
+
+	t.Run("set power keeps the direction", func(t *testing.T) {
+		test.That(t, hb.SetPower(ctx, r3.Vector{X: 1, Y: 1}, r3.Vector{}, nil), test.ShouldBeNil)
+		powers := []float64{}
+		for _, name := range []string{"fl", "fr", "bl", "br"} {
+			powers = append(powers, recorder.command(name).power)
+		}
+		test.That(t, powers, test.ShouldResemble, []float64{1, 0, 0, 1})
+
+		test.That(t, hb.SetPower(ctx, r3.Vector{Y: 0.5}, r3.Vector{Z: 0.5}, nil), test.ShouldBeNil)
+		for _, name := range []string{"fl", "fr", "bl", "br"} {
+			test.That(t, math.Abs(recorder.command(name).power), test.ShouldBeLessThanOrEqualTo, 1)
+		}
+		test.That(t, recorder.command("fr").power, test.ShouldEqual, 1)
+		test.That(t, recorder.command("fl").power, test.ShouldBeLessThan, 0)
+	})
+
+	test.That(t, hb.Close(ctx), test.ShouldBeNil)
+}
+
+func TestOmniBase(t *testing.T) {
+	ctx := context.Background()
+	// an X drive, with the wheels at the corners pointing 45 degrees from forward
+	cfg := &OmniConfig{
+		WheelCircumferenceMM: 100,
+		Wheels: []OmniWheelConfig{
+			{Motor: "fl", XMM: -100, YMM: 100, AngleDeg: -45},
+			{Motor: "fr", XMM: 100, YMM: 100, AngleDeg: 45},
+			{Motor: "bl", XMM: -100, YMM: -100, AngleDeg: 45},
+			{Motor: "br", XMM: 100, YMM: -100, AngleDeg: -45},
+		},
+	}
+	_, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+
+	deps, recorder := motorDependencies("fl", "fr", "bl", "br")
+	b, err := CreateOmniBase(ctx, deps, resource.Config{
+		Name:                "test",
+		API:                 base.Subtype,
+		Model:               OmniModelName,
+		ConvertedAttributes: cfg,
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	hb := b.(*holonomicBase)
+
+	width, err := hb.Width(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, width, test.ShouldEqual, 200)
+
+	// every wheel's rim moves along the velocity of its contact point projected on its direction
+	test.That(t, hb.SetVelocity(ctx, r3.Vector{X: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+	for i, name := range []string{"fl", "fr", "bl", "br"} {
+		test.That(t, recorder.command(name).rpm, test.ShouldAlmostEqual, []float64{1, -1, -1, 1}[i]*60*math.Sqrt2/2)
+	}
+
+	// spinning moves every wheel at the same speed along its direction
+	test.That(t, hb.Spin(ctx, 90, 90, nil), test.ShouldBeNil)
+	for i, name := range []string{"fl", "fr", "bl", "br"} {
+		test.That(t, travel(recorder.command(name), 100), test.ShouldAlmostEqual, []float64{-1, 1, -1, 1}[i]*math.Pi/2*100*math.Sqrt2)
+	}
+
+	// moving sideways as far as forward moves half the wheels
+	test.That(t, hb.translate(ctx, r3.Vector{X: 100, Y: 100}, 100), test.ShouldBeNil)
+	for i, name := range []string{"fl", "fr", "bl", "br"} {
+		test.That(t, travel(recorder.command(name), 100), test.ShouldAlmostEqual, []float64{100 * math.Sqrt2, 0, 0, 100 * math.Sqrt2}[i])
+	}
+	test.That(t, recorder.command("fr").stopped, test.ShouldBeTrue)
+}
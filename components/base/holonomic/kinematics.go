@@ -0,0 +1,87 @@
+package holonomic
+
+import (
+	"bytes"
+	"context"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/base/kinematicbase"
+	"go.viam.com/rdk/components/base/wheeled"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/slam"
+)
+
+// defaultLinearMmPerSec is the speed at which a kinematic holonomic base moves between inputs.
+const defaultLinearMmPerSec = 200
+
+type kinematicHolonomicBase struct {
+	*holonomicBase
+	slam  slam.Service
+	model referenceframe.Model
+}
+
+// WrapWithKinematics takes a holonomicBase component and adds a slam service to it
+// It also adds kinematic model so that it can be controlled.
+// Since the base does not need to turn to move in any direction, its model only has the two translational degrees
+// of freedom of the ground plane, like that of a wheeled base. Its inputs are where the SLAM service places it on
+// the plane of the map.
+func (hb *holonomicBase) WrapWithKinematics(ctx context.Context, slamSvc slam.Service) (base.KinematicBase, error) {
+	// gets the extents of the SLAM map
+	data, err := slam.GetPointCloudMapFull(ctx, slamSvc)
+	if err != nil {
+		return nil, err
+	}
+	dims, err := pointcloud.GetPCDMetaData(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	geometry, err := base.CollisionGeometry(hb.frame)
+	if err != nil {
+		return nil, err
+	}
+	limits := []referenceframe.Limit{{Min: dims.MinX, Max: dims.MaxX}, {Min: dims.MinZ, Max: dims.MaxZ}}
+	model, err := wheeled.Model(hb.name, geometry, limits)
+	if err != nil {
+		return nil, err
+	}
+	return &kinematicHolonomicBase{
+		holonomicBase: hb,
+		slam:          slamSvc,
+		model:         model,
+	}, nil
+}
+
+func (khb *kinematicHolonomicBase) ModelFrame() referenceframe.Model {
+	return khb.model
+}
+
+func (khb *kinematicHolonomicBase) CurrentInputs(ctx context.Context) ([]referenceframe.Input, error) {
+	x, y, _, err := kinematicbase.PlanarPose(ctx, khb.slam)
+	if err != nil {
+		return nil, err
+	}
+	return []referenceframe.Input{{Value: x}, {Value: y}}, nil
+}
+
+// GoToInputs moves the base in a straight line to the goal inputs, without turning it.
+func (khb *kinematicHolonomicBase) GoToInputs(ctx context.Context, goal []referenceframe.Input) error {
+	if _, err := khb.model.Transform(goal); err != nil {
+		return err
+	}
+	ctx, done := khb.opMgr.New(ctx)
+	defer done()
+
+	x, y, heading, err := kinematicbase.PlanarPose(ctx, khb.slam)
+	if err != nil {
+		return err
+	}
+	// the displacement on the plane of the map, in the frame of the base, which faces along its Y axis
+	dx, dy := goal[0].Value-x, goal[1].Value-y
+	sin, cos := math.Sincos(heading)
+	displacement := r3.Vector{X: dx*cos + dy*sin, Y: -dx*sin + dy*cos}
+	return khb.translate(ctx, displacement, defaultLinearMmPerSec)
+}
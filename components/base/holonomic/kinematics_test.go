@@ -0,0 +1,96 @@
+package holonomic
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+// newSLAM returns a slam service whose map spans from -1000 to 1000 along every axis, and which places the base at
+// the pose.
+func newSLAM(t *testing.T, pose *spatialmath.Pose) slam.Service {
+	t.Helper()
+	cloud := pointcloud.New()
+	test.That(t, cloud.Set(r3.Vector{X: -1000, Y: -1000, Z: -1000}, nil), test.ShouldBeNil)
+	test.That(t, cloud.Set(r3.Vector{X: 1000, Y: 1000, Z: 1000}, nil), test.ShouldBeNil)
+	var buf bytes.Buffer
+	test.That(t, pointcloud.ToPCD(cloud, &buf, pointcloud.PCDBinary), test.ShouldBeNil)
+
+	slamSvc := inject.NewSLAMService("slam")
+	slamSvc.GetPointCloudMapFunc = func(ctx context.Context) (func() ([]byte, error), error) {
+		sent := false
+		return func() ([]byte, error) {
+			if sent {
+				return nil, io.EOF
+			}
+			sent = true
+			return buf.Bytes(), nil
+		}, nil
+	}
+	slamSvc.GetPositionFunc = func(ctx context.Context) (spatialmath.Pose, string, error) {
+		return *pose, "", nil
+	}
+	return slamSvc
+}
+
+func TestWrapWithKinematics(t *testing.T) {
+	ctx := context.Background()
+	hb, recorder := newMecanumBase(t)
+	pose := spatialmath.NewZeroPose()
+
+	_, err := hb.WrapWithKinematics(ctx, newSLAM(t, &pose))
+	test.That(t, err, test.ShouldNotBeNil)
+
+	hb.frame = &referenceframe.LinkConfig{
+		Geometry: &spatialmath.GeometryConfig{Type: spatialmath.SphereType, R: 200, Label: "base"},
+	}
+	kb, err := hb.WrapWithKinematics(ctx, newSLAM(t, &pose))
+	test.That(t, err, test.ShouldBeNil)
+
+	limits := kb.ModelFrame().DoF()
+	test.That(t, limits, test.ShouldHaveLength, 2)
+	test.That(t, limits[0], test.ShouldResemble, referenceframe.Limit{Min: -1000, Max: 1000})
+
+	inputs, err := kb.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, inputs, test.ShouldResemble, []referenceframe.Input{{0}, {0}})
+
+	// the base moves forward and to the right without turning
+	goal := []referenceframe.Input{{300}, {400}}
+	test.That(t, kb.GoToInputs(ctx, goal), test.ShouldBeNil)
+	for i, name := range []string{"fl", "fr", "bl", "br"} {
+		test.That(t, travel(recorder.command(name), 100), test.ShouldAlmostEqual, []float64{700, 100, 100, 700}[i])
+	}
+
+	// the inputs are where the SLAM service places the base on the XZ plane of the map
+	pose = spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: 400})
+	inputs, err = kb.CurrentInputs(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, inputs, test.ShouldResemble, goal)
+
+	// then back to where it started
+	test.That(t, kb.GoToInputs(ctx, []referenceframe.Input{{0}, {0}}), test.ShouldBeNil)
+	for i, name := range []string{"fl", "fr", "bl", "br"} {
+		test.That(t, travel(recorder.command(name), 100), test.ShouldAlmostEqual, []float64{-700, -100, -100, -700}[i])
+	}
+
+	// a base turned a quarter turn to the left faces -X on the map, so the same goal is behind it and to its right
+	pose = spatialmath.NewPose(r3.Vector{}, &spatialmath.R4AA{Theta: -math.Pi / 2, RY: 1})
+	test.That(t, kb.GoToInputs(ctx, goal), test.ShouldBeNil)
+	for i, name := range []string{"fl", "fr", "bl", "br"} {
+		test.That(t, travel(recorder.command(name), 100), test.ShouldAlmostEqual, []float64{100, -700, -700, 100}[i])
+	}
+
+	test.That(t, kb.GoToInputs(ctx, []referenceframe.Input{{2000}, {0}}), test.ShouldNotBeNil)
+}
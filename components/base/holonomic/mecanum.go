@@ -0,0 +1,95 @@
+package holonomic
+
+import (
+	"context"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/resource"
+)
+
+// MecanumModelName is the name of the mecanum model of a base component.
+var MecanumModelName = resource.NewDefaultModel("mecanum")
+
+// MecanumConfig is how you configure a mecanum base. The rollers of the wheels must form an X when the base is
+// seen from above, which is how almost all mecanum platforms are built.
+type MecanumConfig struct {
+	WidthMM              int    `json:"width_mm"`
+	WheelbaseMM          int    `json:"wheelbase_mm"`
+	WheelCircumferenceMM int    `json:"wheel_circumference_mm"`
+	FrontLeft            string `json:"front_left"`
+	FrontRight           string `json:"front_right"`
+	BackLeft             string `json:"back_left"`
+	BackRight            string `json:"back_right"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *MecanumConfig) Validate(path string) ([]string, error) {
+	if cfg.WidthMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "width_mm")
+	}
+	if cfg.WheelbaseMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheelbase_mm")
+	}
+	if cfg.WheelCircumferenceMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheel_circumference_mm")
+	}
+
+	deps := cfg.motorNames()
+	for i, field := range []string{"front_left", "front_right", "back_left", "back_right"} {
+		if deps[i] == "" {
+			return nil, utils.NewConfigValidationFieldRequiredError(path, field)
+		}
+	}
+	return deps, nil
+}
+
+func (cfg *MecanumConfig) motorNames() []string {
+	return []string{cfg.FrontLeft, cfg.FrontRight, cfg.BackLeft, cfg.BackRight}
+}
+
+// wheels returns the wheels of the base, in the order of motorNames. The rollers touching the ground of the front
+// left and back right wheels point forward and to the right, so that the base moves forward and to the right when
+// they turn forward. Those of the other two point forward and to the left.
+func (cfg *MecanumConfig) wheels() []*wheel {
+	x := float64(cfg.WidthMM) / 2
+	y := float64(cfg.WheelbaseMM) / 2
+	forward := r3.Vector{Y: 1}
+	right := r3.Vector{X: math.Sqrt2 / 2, Y: math.Sqrt2 / 2}
+	left := r3.Vector{X: -math.Sqrt2 / 2, Y: math.Sqrt2 / 2}
+	return []*wheel{
+		{position: r3.Vector{X: -x, Y: y}, drive: forward, roller: right},
+		{position: r3.Vector{X: x, Y: y}, drive: forward, roller: left},
+		{position: r3.Vector{X: -x, Y: -y}, drive: forward, roller: left},
+		{position: r3.Vector{X: x, Y: -y}, drive: forward, roller: right},
+	}
+}
+
+func init() {
+	resource.RegisterComponent(base.Subtype, MecanumModelName, resource.Registration[base.Base, *MecanumConfig]{
+		Constructor: func(
+			ctx context.Context, deps resource.Dependencies, conf resource.Config, logger golog.Logger,
+		) (base.Base, error) {
+			return CreateMecanumBase(ctx, deps, conf, logger)
+		},
+	})
+}
+
+// CreateMecanumBase returns a new mecanum base defined by the given config.
+func CreateMecanumBase(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger golog.Logger,
+) (base.LocalBase, error) {
+	newConf, err := resource.NativeConfig[*MecanumConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+	return newHolonomicBase(deps, conf, newConf.WidthMM, float64(newConf.WheelCircumferenceMM),
+		newConf.wheels(), newConf.motorNames(), logger)
+}
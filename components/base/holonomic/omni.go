@@ -0,0 +1,113 @@
+package holonomic
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/resource"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// OmniModelName is the name of the omni model of a base component.
+var OmniModelName = resource.NewDefaultModel("omni")
+
+// OmniConfig is how you configure an omni wheeled base, like a three wheeled kiwi drive or a four wheeled X drive.
+type OmniConfig struct {
+	WidthMM              int               `json:"width_mm,omitempty"`
+	WheelCircumferenceMM int               `json:"wheel_circumference_mm"`
+	Wheels               []OmniWheelConfig `json:"wheels"`
+}
+
+// OmniWheelConfig describes where a wheel of an omni base is and which way it drives. Positions are relative to the
+// center of the base, with +Y forward and +X to the right. The angle is the direction the wheel pushes the base in
+// when its motor turns forward, counterclockwise from forward when seen from above.
+type OmniWheelConfig struct {
+	Motor    string  `json:"motor"`
+	XMM      float64 `json:"x_mm"`
+	YMM      float64 `json:"y_mm"`
+	AngleDeg float64 `json:"angle_deg"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *OmniConfig) Validate(path string) ([]string, error) {
+	if cfg.WidthMM < 0 {
+		return nil, utils.NewConfigValidationError(path, fmt.Errorf("width_mm cannot be negative, got %d", cfg.WidthMM))
+	}
+	if cfg.WheelCircumferenceMM <= 0 {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "wheel_circumference_mm")
+	}
+	if len(cfg.Wheels) < 3 {
+		return nil, utils.NewConfigValidationError(path,
+			fmt.Errorf("an omni base needs at least 3 wheels, got %d", len(cfg.Wheels)))
+	}
+
+	deps := make([]string, 0, len(cfg.Wheels))
+	for i, w := range cfg.Wheels {
+		if w.Motor == "" {
+			return nil, utils.NewConfigValidationFieldRequiredError(fmt.Sprintf("%s.wheels.%d", path, i), "motor")
+		}
+		deps = append(deps, w.Motor)
+	}
+	if err := checkWheels(cfg.wheels()); err != nil {
+		return nil, utils.NewConfigValidationError(path, err)
+	}
+	return deps, nil
+}
+
+func (cfg *OmniConfig) wheels() []*wheel {
+	wheels := make([]*wheel, 0, len(cfg.Wheels))
+	for _, w := range cfg.Wheels {
+		angle := rdkutils.DegToRad(w.AngleDeg)
+		drive := r3.Vector{X: -math.Sin(angle), Y: math.Cos(angle)}
+		wheels = append(wheels, &wheel{position: r3.Vector{X: w.XMM, Y: w.YMM}, drive: drive, roller: drive})
+	}
+	return wheels
+}
+
+// width returns the configured width, or the distance between the leftmost and rightmost wheels.
+func (cfg *OmniConfig) width() int {
+	if cfg.WidthMM != 0 {
+		return cfg.WidthMM
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, w := range cfg.Wheels {
+		minX = math.Min(minX, w.XMM)
+		maxX = math.Max(maxX, w.XMM)
+	}
+	return int(math.Round(maxX - minX))
+}
+
+func init() {
+	resource.RegisterComponent(base.Subtype, OmniModelName, resource.Registration[base.Base, *OmniConfig]{
+		Constructor: func(
+			ctx context.Context, deps resource.Dependencies, conf resource.Config, logger golog.Logger,
+		) (base.Base, error) {
+			return CreateOmniBase(ctx, deps, conf, logger)
+		},
+	})
+}
+
+// CreateOmniBase returns a new omni base defined by the given config.
+func CreateOmniBase(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger golog.Logger,
+) (base.LocalBase, error) {
+	newConf, err := resource.NativeConfig[*OmniConfig](conf)
+	if err != nil {
+		return nil, err
+	}
+	motorNames := make([]string, 0, len(newConf.Wheels))
+	for _, w := range newConf.Wheels {
+		motorNames = append(motorNames, w.Motor)
+	}
+	return newHolonomicBase(deps, conf, newConf.width(), float64(newConf.WheelCircumferenceMM),
+		newConf.wheels(), motorNames, logger)
+}
@@ -0,0 +1,24 @@
+// Package kinematicbase contains what the kinematic wrappers of bases share.
+package kinematicbase
+
+import (
+	"context"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/services/slam"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// PlanarPose returns where a kinematic base is on the ground plane of the map of its SLAM service: its position
+// along the first and second axes of the plane, which are the X and Z axes of the map, and its heading in radians,
+// which is 0 when the base faces along the second axis and a quarter turn when it faces towards the negative first.
+func PlanarPose(ctx context.Context, slamSvc slam.Service) (float64, float64, float64, error) {
+	pose, _, err := slamSvc.GetPosition(ctx)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	forward := spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(r3.Vector{Z: 1})).Point().Sub(pose.Point())
+	return pose.Point().X, pose.Point().Z, math.Atan2(-forward.X, forward.Z), nil
+}
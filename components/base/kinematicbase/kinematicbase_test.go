@@ -0,0 +1,48 @@
+package kinematicbase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestPlanarPose(t *testing.T) {
+	ctx := context.Background()
+	slamSvc := inject.NewSLAMService("slam")
+	var pose spatialmath.Pose
+	slamSvc.GetPositionFunc = func(ctx context.Context) (spatialmath.Pose, string, error) {
+		return pose, "", nil
+	}
+
+	// the map's Y axis is up, so turning about it changes the heading, and the height is ignored
+	for _, tc := range []struct {
+		theta   float64
+		heading float64
+	}{
+		{0, 0},
+		{math.Pi / 2, -math.Pi / 2},
+		{-math.Pi / 2, math.Pi / 2},
+		{math.Pi, math.Pi},
+	} {
+		pose = spatialmath.NewPose(r3.Vector{X: 10, Y: 5, Z: -20}, &spatialmath.R4AA{Theta: tc.theta, RY: 1})
+		x, y, heading, err := PlanarPose(ctx, slamSvc)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, x, test.ShouldAlmostEqual, 10)
+		test.That(t, y, test.ShouldAlmostEqual, -20)
+		test.That(t, math.Remainder(heading-tc.heading, 2*math.Pi), test.ShouldAlmostEqual, 0)
+	}
+
+	errFailed := errors.New("no position")
+	slamSvc.GetPositionFunc = func(ctx context.Context) (spatialmath.Pose, string, error) {
+		return nil, "", errFailed
+	}
+	_, _, _, err := PlanarPose(ctx, slamSvc)
+	test.That(t, err, test.ShouldBeError, errFailed)
+}
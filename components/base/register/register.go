@@ -3,8 +3,10 @@ package register
 
 import (
 	// register bases.
+	_ "go.viam.com/rdk/components/base/ackermann"
 	_ "go.viam.com/rdk/components/base/agilex"
 	_ "go.viam.com/rdk/components/base/boat"
 	_ "go.viam.com/rdk/components/base/fake"
+	_ "go.viam.com/rdk/components/base/holonomic"
 	_ "go.viam.com/rdk/components/base/wheeled"
 )
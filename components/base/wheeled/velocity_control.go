@@ -0,0 +1,568 @@
+package wheeled
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/control"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+const (
+	defaultVelocityControlFrequencyHz = 20.0
+	defaultHeadingGain                = 2.0
+
+	// The loops may drive the motors at most this much faster or slower than commanded.
+	maxLinearCorrectionMmPerSec    = 500.0
+	maxAngularCorrectionDegsPerSec = 180.0
+
+	// A closed loop Spin stops this close to its target angle, and slows down on its approach to it, turning at
+	// no more than spinSlowdownPerSec deg/s for every degree left to turn, but no slower than minSpinDegsPerSec.
+	spinToleranceDeg   = 1.0
+	spinSlowdownPerSec = 2.0
+	minSpinDegsPerSec  = 5.0
+
+	// maxHeadingCorrectionDegsPerSec bounds how fast a closed loop MoveStraight turns to get back on its heading.
+	maxHeadingCorrectionDegsPerSec = 45.0
+)
+
+var defaultPIDGains = PIDConfig{P: 0.5, I: 1}
+
+// VelocityControlConfig tunes the closed loop control of a base with a movement sensor.
+type VelocityControlConfig struct {
+	// FrequencyHz is how often the loops run. It defaults to 20Hz.
+	FrequencyHz float64 `json:"frequency_hz,omitempty"`
+	// Linear and Angular are the gains of the PID blocks that correct the linear (mm/s) and angular (deg/s)
+	// velocities the motors are driven at. Gains that are all zero are replaced with defaults, since a PID block
+	// without gains tunes itself, which isn't safe to do on a moving base.
+	Linear  PIDConfig `json:"linear"`
+	Angular PIDConfig `json:"angular"`
+	// HeadingGain is the angular velocity, in deg/s, a base moving straight turns at for every degree it is off its
+	// heading. It defaults to 2.
+	HeadingGain float64 `json:"heading_gain,omitempty"`
+}
+
+// PIDConfig holds the gains of a PID block.
+type PIDConfig struct {
+	P float64 `json:"kP"`
+	I float64 `json:"kI"`
+	D float64 `json:"kD"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *VelocityControlConfig) Validate(path string) error {
+	if conf.FrequencyHz < 0 || conf.FrequencyHz > 200 {
+		return utils.NewConfigValidationError(path, errors.Errorf("frequency_hz must be between 0 and 200, got %v", conf.FrequencyHz))
+	}
+	if conf.HeadingGain < 0 {
+		return utils.NewConfigValidationError(path, errors.Errorf("heading_gain cannot be negative, got %v", conf.HeadingGain))
+	}
+	return nil
+}
+
+// velocityControl regulates the velocity of a wheeled base with control loops fed by its movement sensor. The
+// loops don't replace the velocity math of the base: they correct the velocities it is given, so that slipping or
+// uneven motors are driven faster or slower until the base moves as commanded.
+type velocityControl struct {
+	wb     *wheeledBase
+	sensor movementsensor.MovementSensor
+	conf   VelocityControlConfig
+	logger golog.Logger
+
+	linearSupported      bool
+	angularSupported     bool
+	orientationSupported bool
+	motorsReportPosition bool
+
+	// commandMu serializes commanding the motors, so that a correction computed before a new velocity is set
+	// can't be sent after it.
+	commandMu sync.Mutex
+
+	mu      sync.Mutex
+	linear  velocityAxis
+	angular velocityAxis
+	loops   []*control.Loop
+	// run numbers the runs of the loops, so that a loop that is stopping can't correct the next run.
+	run int
+	// generation is incremented by every new command, so that a cancelled operation can tell whether it was
+	// replaced by another one.
+	generation int
+}
+
+// velocityAxis is the state of the linear or angular velocity of the base.
+type velocityAxis struct {
+	target     float64
+	correction float64
+	measured   float64
+	loop       *control.Loop
+}
+
+func newVelocityControl(
+	ctx context.Context,
+	wb *wheeledBase,
+	sensor movementsensor.MovementSensor,
+	conf VelocityControlConfig,
+	logger golog.Logger,
+) (*velocityControl, error) {
+	props, err := sensor.Properties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !props.LinearVelocitySupported && !props.AngularVelocitySupported {
+		return nil, errors.Errorf("movement sensor %q reports neither linear nor angular velocity", sensor.Name().ShortName())
+	}
+	if conf.FrequencyHz == 0 {
+		conf.FrequencyHz = defaultVelocityControlFrequencyHz
+	}
+	if conf.HeadingGain == 0 {
+		conf.HeadingGain = defaultHeadingGain
+	}
+	if conf.Linear == (PIDConfig{}) {
+		conf.Linear = defaultPIDGains
+	}
+	if conf.Angular == (PIDConfig{}) {
+		conf.Angular = defaultPIDGains
+	}
+
+	vc := &velocityControl{
+		wb:                   wb,
+		sensor:               sensor,
+		conf:                 conf,
+		logger:               logger,
+		linearSupported:      props.LinearVelocitySupported,
+		angularSupported:     props.AngularVelocitySupported,
+		orientationSupported: props.OrientationSupported,
+		motorsReportPosition: true,
+	}
+	for _, m := range wb.allMotors {
+		motorProps, err := m.Properties(ctx, nil)
+		if err != nil || !motorProps[motor.PositionReporting] {
+			vc.motorsReportPosition = false
+		}
+	}
+	return vc, nil
+}
+
+func (vc *velocityControl) period() time.Duration {
+	return time.Duration(float64(time.Second) / vc.conf.FrequencyHz)
+}
+
+// loopConfig returns the config of a loop that corrects the velocity of one axis: the correction is the output of
+// a PID block fed by the difference between the set point and the measured velocity.
+func (vc *velocityControl) loopConfig(name string, gains PIDConfig, maxCorrection float64) control.Config {
+	return control.Config{
+		Frequency: vc.conf.FrequencyHz,
+		Blocks: []control.BlockConfig{
+			{
+				Name:      "set_point",
+				Type:      "constant",
+				Attribute: rdkutils.AttributeMap{"constant_val": 0.0},
+				DependsOn: []string{},
+			},
+			{
+				Name:      "velocity",
+				Type:      "endpoint",
+				Attribute: rdkutils.AttributeMap{"motor_name": name},
+				DependsOn: []string{"correction"},
+			},
+			{
+				Name:      "error",
+				Type:      "sum",
+				Attribute: rdkutils.AttributeMap{"sum_string": "+-"},
+				DependsOn: []string{"set_point", "velocity"},
+			},
+			{
+				Name: "correction",
+				Type: "PID",
+				Attribute: rdkutils.AttributeMap{
+					"kP":             gains.P,
+					"kI":             gains.I,
+					"kD":             gains.D,
+					"int_sat_lim_lo": -maxCorrection,
+					"int_sat_lim_up": maxCorrection,
+					"limit_lo":       -maxCorrection,
+					"limit_up":       maxCorrection,
+				},
+				DependsOn: []string{"error"},
+			},
+		},
+	}
+}
+
+// startLocked starts the loops of the axes the movement sensor measures, unless they are running.
+func (vc *velocityControl) startLocked() error {
+	if vc.loops != nil {
+		return nil
+	}
+	vc.run++
+	vc.linear.correction, vc.angular.correction = 0, 0
+	start := func(axis *velocityAxis, isAngular bool, gains PIDConfig, maxCorrection float64) error {
+		name := vc.wb.Name().ShortName() + "-linear"
+		if isAngular {
+			name = vc.wb.Name().ShortName() + "-angular"
+		}
+		loop, err := control.NewLoop(vc.logger, vc.loopConfig(name, gains, maxCorrection),
+			&loopEndpoint{vc: vc, run: vc.run, angular: isAngular})
+		if err != nil {
+			return err
+		}
+		if err := loop.Start(); err != nil {
+			return err
+		}
+		axis.loop = loop
+		vc.loops = append(vc.loops, loop)
+		return nil
+	}
+	var err error
+	if vc.linearSupported {
+		err = start(&vc.linear, false, vc.conf.Linear, maxLinearCorrectionMmPerSec)
+	}
+	if err == nil && vc.angularSupported {
+		err = start(&vc.angular, true, vc.conf.Angular, maxAngularCorrectionDegsPerSec)
+	}
+	if err != nil {
+		loops := vc.loops
+		vc.loops = nil
+		vc.linear.loop, vc.angular.loop = nil, nil
+		for _, loop := range loops {
+			loop.Stop()
+		}
+	}
+	return err
+}
+
+// stop stops the loops.
+func (vc *velocityControl) stop() {
+	vc.mu.Lock()
+	vc.generation++
+	loops := vc.loops
+	vc.loops = nil
+	vc.linear.loop, vc.angular.loop = nil, nil
+	vc.mu.Unlock()
+
+	// The loops must be stopped without holding the lock, which their endpoints take.
+	for _, loop := range loops {
+		loop.Stop()
+	}
+}
+
+// commandLocked returns the linear and angular velocities the motors should be driven at.
+func (vc *velocityControl) commandLocked() (float64, float64) {
+	return vc.linear.target + vc.linear.correction, vc.angular.target + vc.angular.correction
+}
+
+// drive drives the motors at the corrected velocities.
+func (vc *velocityControl) drive(ctx context.Context) error {
+	vc.commandMu.Lock()
+	defer vc.commandMu.Unlock()
+	vc.mu.Lock()
+	linear, angular := vc.commandLocked()
+	vc.mu.Unlock()
+	l, r := vc.wb.velocityMath(linear, angular)
+	return vc.wb.goForAll(ctx, l, 0, r, 0)
+}
+
+// setTargets sets the velocities the loops regulate the base at, in mm/s and deg/s, and returns the generation
+// of the command.
+func (vc *velocityControl) setTargets(ctx context.Context, linear, angular float64) (int, error) {
+	vc.mu.Lock()
+	if err := ctx.Err(); err != nil {
+		vc.mu.Unlock()
+		return 0, err
+	}
+	vc.generation++
+	generation := vc.generation
+	vc.linear.target, vc.angular.target = linear, angular
+	err := vc.startLocked()
+	for _, axis := range []*velocityAxis{&vc.linear, &vc.angular} {
+		if err != nil || axis.loop == nil {
+			continue
+		}
+		err = axis.loop.SetConfigAt(ctx, "set_point", control.BlockConfig{
+			Name:      "set_point",
+			Type:      "constant",
+			Attribute: rdkutils.AttributeMap{"constant_val": axis.target},
+			DependsOn: []string{},
+		})
+	}
+	vc.mu.Unlock()
+	if err != nil {
+		return 0, multierr.Combine(err, vc.wb.Stop(ctx, nil))
+	}
+
+	// The motors are driven at the new velocity right away, rather than at the next run of the loops.
+	if err := vc.drive(ctx); err != nil {
+		return 0, multierr.Combine(err, vc.wb.Stop(ctx, nil))
+	}
+	return generation, nil
+}
+
+// setVelocity regulates the base at the linear and angular velocities, in mm/s and deg/s.
+func (vc *velocityControl) setVelocity(ctx context.Context, linear, angular float64) error {
+	_, err := vc.setTargets(ctx, linear, angular)
+	return err
+}
+
+// finish stops the base at the end of an operation. If the operation was cancelled because another one replaced
+// it, the base is left to the new operation.
+func (vc *velocityControl) finish(ctx context.Context, generation int) error {
+	if ctx.Err() == nil {
+		return vc.wb.Stop(ctx, nil)
+	}
+	vc.mu.Lock()
+	replaced := vc.generation != generation
+	vc.mu.Unlock()
+	if replaced {
+		return ctx.Err()
+	}
+	return multierr.Combine(ctx.Err(), vc.wb.Stop(context.Background(), nil))
+}
+
+// spin turns the base by the angle, measured by its movement sensor.
+func (vc *velocityControl) spin(ctx context.Context, angleDeg, degsPerSec float64) error {
+	if !vc.angularSupported && !vc.orientationSupported {
+		rpm, revolutions := vc.wb.spinMath(angleDeg, degsPerSec)
+		return vc.wb.runAll(ctx, -rpm, revolutions, rpm, revolutions)
+	}
+	heading, err := vc.newHeadingTracker(ctx)
+	if err != nil {
+		return err
+	}
+	direction := sign(angleDeg) * sign(degsPerSec)
+	target := math.Abs(angleDeg)
+	speed := math.Abs(degsPerSec)
+
+	var generation int
+	for {
+		turned, err := heading.update(ctx)
+		if err != nil {
+			return multierr.Combine(err, vc.wb.Stop(ctx, nil))
+		}
+		remaining := target - direction*turned
+		if remaining <= spinToleranceDeg {
+			return vc.wb.Stop(ctx, nil)
+		}
+		spinSpeed := math.Min(speed, math.Max(remaining*spinSlowdownPerSec, minSpinDegsPerSec))
+		if generation, err = vc.setTargets(ctx, 0, direction*spinSpeed); err != nil {
+			return vc.finish(ctx, generation)
+		}
+		if !utils.SelectContextOrWait(ctx, vc.period()) {
+			return vc.finish(ctx, generation)
+		}
+	}
+}
+
+// moveStraight drives the base by the distance, holding the heading it started at.
+func (vc *velocityControl) moveStraight(ctx context.Context, distanceMm int, mmPerSec float64) error {
+	heading, err := vc.newHeadingTracker(ctx)
+	if err != nil {
+		return err
+	}
+	odometer, err := vc.newOdometer(ctx)
+	if err != nil {
+		return err
+	}
+	direction := sign(float64(distanceMm)) * sign(mmPerSec)
+	target := math.Abs(float64(distanceMm))
+	speed := math.Abs(mmPerSec)
+
+	var generation int
+	for {
+		traveled, err := odometer.update(ctx, direction*speed)
+		if err != nil {
+			return multierr.Combine(err, vc.wb.Stop(ctx, nil))
+		}
+		if direction*traveled >= target {
+			return vc.wb.Stop(ctx, nil)
+		}
+		turned, err := heading.update(ctx)
+		if err != nil {
+			return multierr.Combine(err, vc.wb.Stop(ctx, nil))
+		}
+		correction := math.Max(-maxHeadingCorrectionDegsPerSec,
+			math.Min(-turned*vc.conf.HeadingGain, maxHeadingCorrectionDegsPerSec))
+		if generation, err = vc.setTargets(ctx, direction*speed, correction); err != nil {
+			return vc.finish(ctx, generation)
+		}
+		if !utils.SelectContextOrWait(ctx, vc.period()) {
+			return vc.finish(ctx, generation)
+		}
+	}
+}
+
+// measure returns the velocity of the base along the axis, in mm/s or deg/s. If the movement sensor fails, the
+// last measurement is returned, since a control loop can't handle a missing input.
+func (vc *velocityControl) measure(ctx context.Context, angular bool) float64 {
+	var value float64
+	var err error
+	if angular {
+		av, avErr := vc.sensor.AngularVelocity(ctx, nil)
+		value, err = av.Z, avErr
+	} else {
+		lv, lvErr := vc.sensor.LinearVelocity(ctx, nil)
+		value, err = lv.Y, lvErr
+	}
+
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	axis := &vc.linear
+	if angular {
+		axis = &vc.angular
+	}
+	if err != nil {
+		vc.logger.Debugw("error measuring the velocity of the base", "error", err)
+		return axis.measured
+	}
+	axis.measured = value
+	return value
+}
+
+// setCorrection sets the correction of the axis from a run of its loop, and drives the motors with it.
+func (vc *velocityControl) setCorrection(ctx context.Context, run int, angular bool, correction float64) error {
+	vc.mu.Lock()
+	if run != vc.run || vc.loops == nil {
+		vc.mu.Unlock()
+		return nil
+	}
+	if angular {
+		vc.angular.correction = correction
+	} else {
+		vc.linear.correction = correction
+	}
+	vc.mu.Unlock()
+	if err := vc.drive(ctx); err != nil {
+		vc.logger.Errorw("error correcting the velocity of the base", "error", err)
+		return err
+	}
+	return nil
+}
+
+// loopEndpoint connects a control loop to one axis of the base: its position is the measured velocity of the
+// axis, and its power is the correction of the velocity the motors are driven at.
+type loopEndpoint struct {
+	vc      *velocityControl
+	run     int
+	angular bool
+}
+
+// SetPower sets the correction of the velocity of the axis.
+func (e *loopEndpoint) SetPower(ctx context.Context, power float64, extra map[string]interface{}) error {
+	return e.vc.setCorrection(ctx, e.run, e.angular, power)
+}
+
+// Position returns the measured velocity of the axis.
+func (e *loopEndpoint) Position(ctx context.Context, extra map[string]interface{}) (float64, error) {
+	return e.vc.measure(ctx, e.angular), nil
+}
+
+// A headingTracker measures how far the base has turned, counterclockwise in degrees, from the orientation of
+// the movement sensor if it has one, or else by integrating its angular velocity.
+type headingTracker struct {
+	vc       *velocityControl
+	lastYaw  float64
+	lastTime time.Time
+	turned   float64
+}
+
+func (vc *velocityControl) newHeadingTracker(ctx context.Context) (*headingTracker, error) {
+	h := &headingTracker{vc: vc, lastTime: time.Now()}
+	if vc.orientationSupported {
+		o, err := vc.sensor.Orientation(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		h.lastYaw = rdkutils.RadToDeg(o.EulerAngles().Yaw)
+	}
+	return h, nil
+}
+
+func (h *headingTracker) update(ctx context.Context) (float64, error) {
+	switch {
+	case h.vc.orientationSupported:
+		o, err := h.vc.sensor.Orientation(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		yaw := rdkutils.RadToDeg(o.EulerAngles().Yaw)
+		delta := math.Mod(yaw-h.lastYaw+540, 360) - 180
+		h.lastYaw = yaw
+		h.turned += delta
+	case h.vc.angularSupported:
+		av, err := h.vc.sensor.AngularVelocity(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		now := time.Now()
+		h.turned += av.Z * now.Sub(h.lastTime).Seconds()
+		h.lastTime = now
+	}
+	return h.turned, nil
+}
+
+// An odometer measures how far the base has moved forward, in mm, from the positions of its motors if they
+// report them, or else by integrating the linear velocity of the movement sensor. As a last resort, it integrates
+// the commanded velocity.
+type odometer struct {
+	vc        *velocityControl
+	start     []float64
+	lastTime  time.Time
+	traveled  float64
+	positions bool
+}
+
+func (vc *velocityControl) newOdometer(ctx context.Context) (*odometer, error) {
+	o := &odometer{vc: vc, lastTime: time.Now(), positions: vc.motorsReportPosition}
+	if o.positions {
+		for _, m := range vc.wb.allMotors {
+			pos, err := m.Position(ctx, nil)
+			if err != nil {
+				return nil, err
+			}
+			o.start = append(o.start, pos)
+		}
+	}
+	return o, nil
+}
+
+func (o *odometer) update(ctx context.Context, commanded float64) (float64, error) {
+	now := time.Now()
+	dt := now.Sub(o.lastTime).Seconds()
+	o.lastTime = now
+	switch {
+	case o.positions:
+		var revolutions float64
+		for i, m := range o.vc.wb.allMotors {
+			pos, err := m.Position(ctx, nil)
+			if err != nil {
+				return 0, err
+			}
+			revolutions += pos - o.start[i]
+		}
+		o.traveled = revolutions / float64(len(o.start)) * float64(o.vc.wb.wheelCircumferenceMm)
+	case o.vc.linearSupported:
+		lv, err := o.vc.sensor.LinearVelocity(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		o.traveled += lv.Y * dt
+	default:
+		o.traveled += commanded * dt
+	}
+	return o.traveled, nil
+}
+
+func sign(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,258 @@
+package wheeled
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/base"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	rdkutils "go.viam.com/rdk/utils"
+)
+
+// simulatedBase simulates a differential base whose wheels slip, so that it moves slower than its motors are
+// commanded to drive it, and turns when it should go straight.
+type simulatedBase struct {
+	mu          sync.Mutex
+	width       float64
+	circ        float64
+	efficiency  map[string]float64
+	rpm         map[string]float64
+	last        time.Time
+	headingDeg  float64
+	distanceMm  float64
+	orientation bool
+}
+
+func newSimulatedBase(leftEfficiency, rightEfficiency float64, orientation bool) *simulatedBase {
+	return &simulatedBase{
+		width:       100,
+		circ:        1000,
+		efficiency:  map[string]float64{"left": leftEfficiency, "right": rightEfficiency},
+		rpm:         map[string]float64{},
+		last:        time.Now(),
+		orientation: orientation,
+	}
+}
+
+// velocitiesLocked returns the linear (mm/s) and angular (deg/s) velocities of the base.
+func (s *simulatedBase) velocitiesLocked() (float64, float64) {
+	vL := s.rpm["left"] / 60 * s.circ * s.efficiency["left"]
+	vR := s.rpm["right"] / 60 * s.circ * s.efficiency["right"]
+	return (vL + vR) / 2, rdkutils.RadToDeg((vR - vL) / s.width)
+}
+
+func (s *simulatedBase) advanceLocked() {
+	now := time.Now()
+	dt := now.Sub(s.last).Seconds()
+	s.last = now
+	linear, angular := s.velocitiesLocked()
+	s.distanceMm += linear * dt
+	s.headingDeg += angular * dt
+}
+
+func (s *simulatedBase) setRPM(name string, rpm float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceLocked()
+	s.rpm[name] = rpm
+}
+
+func (s *simulatedBase) state() (linear, angular, distance, heading float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.advanceLocked()
+	linear, angular = s.velocitiesLocked()
+	return linear, angular, s.distanceMm, s.headingDeg
+}
+
+func (s *simulatedBase) dependencies() resource.Dependencies {
+	deps := resource.Dependencies{}
+	for _, name := range []string{"left", "right"} {
+		name := name
+		m := inject.NewMotor(name)
+		m.GoForFunc = func(ctx context.Context, rpm, rotations float64, extra map[string]interface{}) error {
+			s.setRPM(name, rpm)
+			return nil
+		}
+		m.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+			s.setRPM(name, 0)
+			return nil
+		}
+		m.IsPoweredFunc = func(ctx context.Context, extra map[string]interface{}) (bool, float64, error) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			return s.rpm[name] != 0, 0, nil
+		}
+		m.PropertiesFunc = func(ctx context.Context, extra map[string]interface{}) (map[motor.Feature]bool, error) {
+			return map[motor.Feature]bool{motor.PositionReporting: false}, nil
+		}
+		deps[motor.Named(name)] = m
+	}
+
+	// the sensor is called concurrently by the control loops, so it is not injected with functions, which would
+	// record their arguments unsynchronized.
+	ms := inject.NewMovementSensor("imu")
+	ms.MovementSensor = &simulatedSensor{sim: s}
+	deps[movementsensor.Named("imu")] = ms
+	return deps
+}
+
+// simulatedSensor measures the velocities and heading of a simulatedBase.
+type simulatedSensor struct {
+	movementsensor.MovementSensor
+	sim *simulatedBase
+}
+
+func (ss *simulatedSensor) Properties(ctx context.Context, extra map[string]interface{}) (*movementsensor.Properties, error) {
+	return &movementsensor.Properties{
+		LinearVelocitySupported:  true,
+		AngularVelocitySupported: true,
+		OrientationSupported:     ss.sim.orientation,
+	}, nil
+}
+
+func (ss *simulatedSensor) LinearVelocity(ctx context.Context, extra map[string]interface{}) (r3.Vector, error) {
+	linear, _, _, _ := ss.sim.state()
+	return r3.Vector{Y: linear}, nil
+}
+
+func (ss *simulatedSensor) AngularVelocity(ctx context.Context, extra map[string]interface{}) (spatialmath.AngularVelocity, error) {
+	_, angular, _, _ := ss.sim.state()
+	return spatialmath.AngularVelocity{Z: angular}, nil
+}
+
+func (ss *simulatedSensor) Orientation(ctx context.Context, extra map[string]interface{}) (spatialmath.Orientation, error) {
+	_, _, _, heading := ss.sim.state()
+	return &spatialmath.EulerAngles{Yaw: rdkutils.DegToRad(heading)}, nil
+}
+
+func newClosedLoopBase(t *testing.T, sim *simulatedBase) *wheeledBase {
+	t.Helper()
+	conf := resource.Config{
+		Name:  "test",
+		API:   base.Subtype,
+		Model: resource.Model{Name: "wheeled_base"},
+		ConvertedAttributes: &Config{
+			WidthMM:              int(sim.width),
+			WheelCircumferenceMM: int(sim.circ),
+			Left:                 []string{"left"},
+			Right:                []string{"right"},
+			MovementSensor:       "imu",
+			VelocityControl: &VelocityControlConfig{
+				FrequencyHz: 50,
+				Linear:      PIDConfig{P: 0.5, I: 5},
+				Angular:     PIDConfig{P: 0.5, I: 5},
+			},
+		},
+	}
+	deps, err := conf.Validate("path", resource.ResourceTypeComponent)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldContain, "imu")
+
+	b, err := CreateWheeledBase(context.Background(), sim.dependencies(), conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	wb, ok := b.(*wheeledBase)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, wb.velocity, test.ShouldNotBeNil)
+	return wb
+}
+
+func TestVelocityControlConfig(t *testing.T) {
+	conf := &Config{
+		WidthMM:              100,
+		WheelCircumferenceMM: 1000,
+		Left:                 []string{"left"},
+		Right:                []string{"right"},
+		VelocityControl:      &VelocityControlConfig{},
+	}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "movement_sensor")
+
+	conf.MovementSensor = "imu"
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"left", "right", "imu"})
+
+	conf.VelocityControl.FrequencyHz = 500
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestClosedLoopSetVelocity(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimulatedBase(0.7, 0.8, false)
+	wb := newClosedLoopBase(t, sim)
+	defer func() {
+		test.That(t, wb.Close(ctx), test.ShouldBeNil)
+	}()
+
+	test.That(t, wb.SetVelocity(ctx, r3.Vector{Y: 100}, r3.Vector{}, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		linear, angular, _, _ := sim.state()
+		test.That(tb, linear, test.ShouldAlmostEqual, 100, 3)
+		test.That(tb, angular, test.ShouldAlmostEqual, 0, 2)
+	})
+
+	test.That(t, wb.SetVelocity(ctx, r3.Vector{}, r3.Vector{Z: 30}, nil), test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		linear, angular, _, _ := sim.state()
+		test.That(tb, linear, test.ShouldAlmostEqual, 0, 3)
+		test.That(tb, angular, test.ShouldAlmostEqual, 30, 1)
+	})
+
+	test.That(t, wb.Stop(ctx, nil), test.ShouldBeNil)
+	linear, angular, _, _ := sim.state()
+	test.That(t, linear, test.ShouldEqual, 0)
+	test.That(t, angular, test.ShouldEqual, 0)
+}
+
+func TestClosedLoopSpin(t *testing.T) {
+	ctx := context.Background()
+	for _, orientation := range []bool{true, false} {
+		sim := newSimulatedBase(0.5, 0.6, orientation)
+		wb := newClosedLoopBase(t, sim)
+
+		test.That(t, wb.Spin(ctx, -90, 90, nil), test.ShouldBeNil)
+		_, angular, _, heading := sim.state()
+		test.That(t, angular, test.ShouldEqual, 0)
+		test.That(t, heading, test.ShouldAlmostEqual, -90, 3)
+		test.That(t, wb.Close(ctx), test.ShouldBeNil)
+	}
+}
+
+func TestClosedLoopMoveStraight(t *testing.T) {
+	ctx := context.Background()
+	sim := newSimulatedBase(1, 0.8, true)
+	wb := newClosedLoopBase(t, sim)
+	defer func() {
+		test.That(t, wb.Close(ctx), test.ShouldBeNil)
+	}()
+
+	test.That(t, wb.MoveStraight(ctx, 300, 300, nil), test.ShouldBeNil)
+	linear, _, distance, heading := sim.state()
+	test.That(t, linear, test.ShouldEqual, 0)
+	test.That(t, distance, test.ShouldAlmostEqual, 300, 20)
+	test.That(t, math.Abs(heading), test.ShouldBeLessThan, 5)
+
+	// a cancelled move stops the base
+	cancelCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	test.That(t, wb.MoveStraight(cancelCtx, 10000, 300, nil), test.ShouldBeError, context.DeadlineExceeded)
+	linear, _, _, _ = sim.state()
+	test.That(t, linear, test.ShouldEqual, 0)
+}
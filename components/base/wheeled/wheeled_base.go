@@ -15,6 +15,7 @@ import (
 
 	"go.viam.com/rdk/components/base"
 	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/movementsensor"
 	"go.viam.com/rdk/operation"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
@@ -31,6 +32,11 @@ type Config struct {
 	SpinSlipFactor       float64  `json:"spin_slip_factor,omitempty"`
 	Left                 []string `json:"left"`
 	Right                []string `json:"right"`
+
+	// MovementSensor, if set, closes the loop on the velocity of the base, and on its heading during
+	// MoveStraight and Spin, instead of trusting the motors to turn the wheels as commanded.
+	MovementSensor  string                 `json:"movement_sensor,omitempty"`
+	VelocityControl *VelocityControlConfig `json:"velocity_control,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -61,6 +67,19 @@ func (cfg *Config) Validate(path string) ([]string, error) {
 	deps = append(deps, cfg.Left...)
 	deps = append(deps, cfg.Right...)
 
+	if cfg.MovementSensor != "" {
+		deps = append(deps, cfg.MovementSensor)
+	}
+	if cfg.VelocityControl != nil {
+		if cfg.MovementSensor == "" {
+			return nil, utils.NewConfigValidationError(path,
+				errors.New("velocity_control requires a movement_sensor"))
+		}
+		if err := cfg.VelocityControl.Validate(path + ".velocity_control"); err != nil {
+			return nil, err
+		}
+	}
+
 	return deps, nil
 }
 
@@ -87,6 +106,9 @@ type wheeledBase struct {
 	right     []motor.Motor
 	allMotors []motor.Motor
 
+	// velocity is nil unless the base has a movement sensor to close the loop with.
+	velocity *velocityControl
+
 	opMgr  operation.SingleOperationManager
 	logger golog.Logger
 
@@ -109,6 +131,10 @@ func (wb *wheeledBase) Spin(ctx context.Context, angleDeg, degsPerSec float64, e
 		return err
 	}
 
+	if wb.velocity != nil {
+		return wb.velocity.spin(ctx, angleDeg, degsPerSec)
+	}
+
 	// Spin math
 	rpm, revolutions := wb.spinMath(angleDeg, degsPerSec)
 
@@ -130,6 +156,10 @@ func (wb *wheeledBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSe
 		return err
 	}
 
+	if wb.velocity != nil {
+		return wb.velocity.moveStraight(ctx, distanceMm, mmPerSec)
+	}
+
 	// Straight math
 	rpm, rotations := wb.straightDistanceToMotorInputs(distanceMm, mmPerSec)
 
@@ -138,20 +168,28 @@ func (wb *wheeledBase) MoveStraight(ctx context.Context, distanceMm int, mmPerSe
 
 // runsAll the base motors in parallel with the required speeds and rotations.
 func (wb *wheeledBase) runAll(ctx context.Context, leftRPM, leftRotations, rightRPM, rightRotations float64) error {
+	if err := wb.goForAll(ctx, leftRPM, leftRotations, rightRPM, rightRotations); err != nil {
+		return multierr.Combine(err, wb.Stop(ctx, nil))
+	}
+	return nil
+}
+
+// goForAll runs the base motors in parallel with the required speeds and rotations, without stopping them on errors.
+func (wb *wheeledBase) goForAll(ctx context.Context, leftRPM, leftRotations, rightRPM, rightRotations float64) error {
 	fs := []rdkutils.SimpleFunc{}
 
 	for _, m := range wb.left {
+		m := m
 		fs = append(fs, func(ctx context.Context) error { return m.GoFor(ctx, leftRPM, leftRotations, nil) })
 	}
 
 	for _, m := range wb.right {
+		m := m
 		fs = append(fs, func(ctx context.Context) error { return m.GoFor(ctx, rightRPM, rightRotations, nil) })
 	}
 
-	if _, err := rdkutils.RunInParallel(ctx, fs); err != nil {
-		return multierr.Combine(err, wb.Stop(ctx, nil))
-	}
-	return nil
+	_, err := rdkutils.RunInParallel(ctx, fs)
+	return err
 }
 
 // differentialDrive takes forward and left direction inputs from a first person
@@ -201,6 +239,13 @@ func (wb *wheeledBase) SetVelocity(ctx context.Context, linear, angular r3.Vecto
 		"received a SetVelocity with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f (mmPerSec), angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
 		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
 
+	if wb.velocity != nil {
+		if linear.Y == 0 && angular.Z == 0 {
+			return wb.Stop(ctx, nil)
+		}
+		return wb.velocity.setVelocity(ctx, linear.Y, angular.Z)
+	}
+
 	l, r := wb.velocityMath(linear.Y, angular.Z)
 	return wb.runAll(ctx, l, 0, r, 0)
 }
@@ -213,6 +258,10 @@ func (wb *wheeledBase) SetPower(ctx context.Context, linear, angular r3.Vector,
 		"received a SetPower with linear.X: %.2f, linear.Y: %.2f linear.Z: %.2f, angular.X: %.2f, angular.Y: %.2f, angular.Z: %.2f",
 		linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z)
 
+	if wb.velocity != nil {
+		wb.velocity.stop()
+	}
+
 	lPower, rPower := wb.differentialDrive(linear.Y, angular.Z)
 
 	// Send motor commands
@@ -310,6 +359,9 @@ func (wb *wheeledBase) WaitForMotorsToStop(ctx context.Context) error {
 
 // Stop commands the base to stop moving.
 func (wb *wheeledBase) Stop(ctx context.Context, extra map[string]interface{}) error {
+	if wb.velocity != nil {
+		wb.velocity.stop()
+	}
 	var err error
 	for _, m := range wb.allMotors {
 		err = multierr.Combine(err, m.Stop(ctx, extra))
@@ -392,5 +444,19 @@ func CreateWheeledBase(
 
 	wb.allMotors = append(wb.allMotors, wb.left...)
 	wb.allMotors = append(wb.allMotors, wb.right...)
+
+	if newConf.MovementSensor != "" {
+		ms, err := movementsensor.FromDependencies(deps, newConf.MovementSensor)
+		if err != nil {
+			return nil, errors.Wrapf(err, "no movement sensor named (%s)", newConf.MovementSensor)
+		}
+		velocityConf := VelocityControlConfig{}
+		if newConf.VelocityControl != nil {
+			velocityConf = *newConf.VelocityControl
+		}
+		if wb.velocity, err = newVelocityControl(ctx, wb, ms, velocityConf, logger); err != nil {
+			return nil, err
+		}
+	}
 	return wb, nil
 }
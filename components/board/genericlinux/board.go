@@ -37,9 +37,66 @@ type Config struct {
 	SPIs              []board.SPIConfig              `json:"spis,omitempty"`
 	Analogs           []board.AnalogConfig           `json:"analogs,omitempty"`
 	DigitalInterrupts []board.DigitalInterruptConfig `json:"digital_interrupts,omitempty"`
+	GPIOPins          []GPIOPinConfig                `json:"gpio_pins,omitempty"`
 	Attributes        utils.AttributeMap             `json:"attributes,omitempty"`
 }
 
+// The bias and drive settings of a GPIO pin.
+const (
+	biasAsIs        = "as_is"
+	biasPullUp      = "pull_up"
+	biasPullDown    = "pull_down"
+	biasDisabled    = "disabled"
+	drivePushPull   = "push_pull"
+	driveOpenDrain  = "open_drain"
+	driveOpenSource = "open_source"
+)
+
+// A GPIOPinConfig describes the electrical settings of a GPIO pin, which are applied when the pin
+// is used as a GPIO pin or as a digital interrupt. These settings are only applied by boards using
+// the GPIO character device, and need its v2 uAPI, from Linux 5.10 onwards.
+type GPIOPinConfig struct {
+	Pin string `json:"pin"`
+	// Bias is one of "as_is" (the default), "pull_up", "pull_down" or "disabled".
+	Bias string `json:"bias,omitempty"`
+	// Drive is one of "push_pull" (the default), "open_drain" or "open_source", and is only used
+	// when the pin is an output.
+	Drive string `json:"drive,omitempty"`
+	// DebounceMicroseconds is how long an input must be stable before the kernel reports a change
+	// in its value, and is only used when the pin is an input.
+	DebounceMicroseconds int `json:"debounce_us,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (conf *GPIOPinConfig) Validate(path string) error {
+	if conf.Pin == "" {
+		return goutils.NewConfigValidationFieldRequiredError(path, "pin")
+	}
+	switch conf.Bias {
+	case "", biasAsIs, biasPullUp, biasPullDown, biasDisabled:
+	default:
+		return goutils.NewConfigValidationError(path, errors.Errorf("unknown bias %q", conf.Bias))
+	}
+	switch conf.Drive {
+	case "", drivePushPull, driveOpenDrain, driveOpenSource:
+	default:
+		return goutils.NewConfigValidationError(path, errors.Errorf("unknown drive %q", conf.Drive))
+	}
+	if conf.DebounceMicroseconds < 0 {
+		return goutils.NewConfigValidationError(path, errors.New("debounce_us cannot be negative"))
+	}
+	return nil
+}
+
+// lineSettings returns the settings to request the pin's line with.
+func (conf GPIOPinConfig) lineSettings() lineSettings {
+	return lineSettings{
+		bias:     conf.Bias,
+		drive:    conf.Drive,
+		debounce: time.Duration(conf.DebounceMicroseconds) * time.Microsecond,
+	}
+}
+
 // Validate ensures all parts of the config are valid.
 func (conf *Config) Validate(path string) ([]string, error) {
 	for idx, c := range conf.SPIs {
@@ -62,6 +119,11 @@ func (conf *Config) Validate(path string) ([]string, error) {
 			return nil, err
 		}
 	}
+	for idx, c := range conf.GPIOPins {
+		if err := c.Validate(fmt.Sprintf("%s.%s.%d", path, "gpio_pins", idx)); err != nil {
+			return nil, err
+		}
+	}
 	return nil, nil
 }
 
@@ -130,6 +192,9 @@ func (b *sysfsBoard) Reconfigure(
 		if len(newConf.DigitalInterrupts) != 0 {
 			return errors.New("digital interrupts on Periph GPIO pins are not yet supported")
 		}
+		if len(newConf.GPIOPins) != 0 {
+			return errors.New("GPIO pin settings on Periph GPIO pins are not yet supported")
+		}
 	}
 
 	stillExists := map[string]struct{}{}
@@ -218,10 +283,23 @@ func (b *sysfsBoard) Reconfigure(
 		// We currently have two implementations of GPIO pins on these boards: one using
 		// libraries from periph.io and one using an ioctl approach. If we're using the
 		// latter, we need to initialize it here.
+		// The lines of the old pins and interrupts need to be released before they can be
+		// requested again with their new settings.
+		for _, pin := range b.gpios {
+			if err := pin.release(); err != nil {
+				b.logger.Errorw("error releasing GPIO pin while reconfiguring", "error", err)
+			}
+		}
+		for _, interrupt := range b.interrupts {
+			if err := interrupt.Close(); err != nil {
+				b.logger.Errorw("error closing digital interrupt while reconfiguring", "error", err)
+			}
+		}
 		gpios, interrupts, err := b.gpioInitialize( // Defined in gpio.go
 			b.cancelCtx,
 			b.gpioMappings,
 			newConf.DigitalInterrupts,
+			newConf.GPIOPins,
 			b.logger)
 		if err != nil {
 			return err
@@ -393,7 +471,8 @@ func (b *sysfsBoard) DigitalInterruptByName(name string) (board.DigitalInterrupt
 		Pin:  name,
 		Type: defaultInterruptType,
 	}
-	interrupt, err := b.createDigitalInterrupt(b.cancelCtx, defaultInterruptConfig, b.gpioMappings)
+	interrupt, err := b.createDigitalInterrupt(
+		b.cancelCtx, defaultInterruptConfig, b.gpioMappings, gpio.settings)
 	if err != nil {
 		b.logger.Errorw("failed to create digital interrupt pin on the fly", "error", err)
 		return nil, false
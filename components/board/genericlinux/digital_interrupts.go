@@ -1,12 +1,16 @@
 //go:build linux
 
 // Package genericlinux is for Linux boards, and this particular file is for digital interrupt pins
-// using the ioctl interface, indirectly by way of mkch's gpio package.
+// using the ioctl interface, either directly with the v2 uAPI (see gpio_cdev.go) or indirectly by
+// way of mkch's gpio package.
 package genericlinux
 
 import (
 	"context"
+	"io"
+	"os"
 	"strconv"
+	"time"
 
 	"github.com/mkch/gpio"
 	"github.com/pkg/errors"
@@ -19,15 +23,17 @@ import (
 type digitalInterrupt struct {
 	parentBoard *sysfsBoard
 	interrupt   board.DigitalInterrupt
-	line        *gpio.LineWithEvent
-	cancelCtx   context.Context
-	cancelFunc  func()
+	// Either a *gpioCdevLine or, on kernels without the v2 uAPI, a *gpio.LineWithEvent.
+	line       io.Closer
+	cancelCtx  context.Context
+	cancelFunc func()
 }
 
 func (b *sysfsBoard) createDigitalInterrupt(
 	ctx context.Context,
 	config board.DigitalInterruptConfig,
 	gpioMappings map[int]GPIOBoardMapping,
+	settings lineSettings,
 ) (*digitalInterrupt, error) {
 	pinInt, err := strconv.Atoi(config.Pin)
 	if err != nil {
@@ -38,14 +44,7 @@ func (b *sysfsBoard) createDigitalInterrupt(
 		return nil, errors.Errorf("unknown interrupt pin %s", config.Pin)
 	}
 
-	chip, err := gpio.OpenChip(mapping.GPIOChipDev)
-	if err != nil {
-		return nil, err
-	}
-	defer utils.UncheckedErrorFunc(chip.Close)
-
-	line, err := chip.OpenLineWithEvents(
-		uint32(mapping.GPIO), gpio.Input, gpio.BothEdges, "viam-interrupt")
+	line, err := openEventLine(mapping.GPIOChipDev, uint32(mapping.GPIO), settings)
 	if err != nil {
 		return nil, err
 	}
@@ -67,16 +66,84 @@ func (b *sysfsBoard) createDigitalInterrupt(
 	return &result, nil
 }
 
+// openEventLine opens the line as an input reporting both edges, with the v2 uAPI if the kernel
+// supports it, and falls back to the first version otherwise, as long as the settings don't need
+// the v2 uAPI.
+func openEventLine(devicePath string, offset uint32, settings lineSettings) (io.Closer, error) {
+	settings.isInput = true
+	settings.edges = true
+	line, err := requestCdevLine(devicePath, offset, "viam-interrupt", settings)
+	if err == nil {
+		return line, nil
+	}
+	if !errors.Is(err, errGPIOV2Unsupported) {
+		return nil, err
+	}
+	if settings.usesV2Features() {
+		return nil, errors.Wrap(err, "bias and debounce settings of digital interrupts need the v2 uAPI")
+	}
+
+	chip, err := gpio.OpenChip(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(chip.Close)
+
+	return chip.OpenLineWithEvents(offset, gpio.Input, gpio.BothEdges, "viam-interrupt")
+}
+
 func (di *digitalInterrupt) startMonitor() {
+	switch line := di.line.(type) {
+	case *gpioCdevLine:
+		di.startCdevMonitor(line)
+	case *gpio.LineWithEvent:
+		di.parentBoard.activeBackgroundWorkers.Add(1)
+		utils.ManagedGo(func() {
+			for {
+				select {
+				case <-di.cancelCtx.Done():
+					return
+				case event := <-line.Events():
+					utils.UncheckedError(di.interrupt.Tick(
+						di.cancelCtx, event.RisingEdge, uint64(event.Time.UnixNano())))
+				}
+			}
+		}, di.parentBoard.activeBackgroundWorkers.Done)
+	}
+}
+
+// startCdevMonitor ticks the interrupt for each edge event of a line opened with the v2 uAPI. The
+// ticks carry the timestamps the kernel gave the events, in nanoseconds since the Unix epoch like
+// those of the first version of the uAPI, so the time between them is accurate even if we don't
+// get to read the events right away.
+func (di *digitalInterrupt) startCdevMonitor(line *gpioCdevLine) {
+	// Reading events blocks until there are some, so the read is interrupted when we're cancelled.
+	di.parentBoard.activeBackgroundWorkers.Add(1)
+	utils.ManagedGo(func() {
+		<-di.cancelCtx.Done()
+		utils.UncheckedError(line.SetReadDeadline(time.Unix(1, 0)))
+	}, di.parentBoard.activeBackgroundWorkers.Done)
+
 	di.parentBoard.activeBackgroundWorkers.Add(1)
 	utils.ManagedGo(func() {
+		events := make([]gpioV2LineEvent, gpioV2LineEventBufferLen)
 		for {
-			select {
-			case <-di.cancelCtx.Done():
-				return
-			case event := <-di.line.Events():
-				utils.UncheckedError(di.interrupt.Tick(
-					di.cancelCtx, event.RisingEdge, uint64(event.Time.UnixNano())))
+			n, err := line.ReadEvents(events)
+			if err != nil {
+				if di.cancelCtx.Err() != nil || errors.Is(err, os.ErrClosed) {
+					return
+				}
+				di.parentBoard.logger.Errorw("error reading digital interrupt events", "error", err)
+				if !utils.SelectContextOrWait(di.cancelCtx, time.Second) {
+					return
+				}
+				continue
+			}
+			for _, event := range events[:n] {
+				if err := di.interrupt.Tick(
+					di.cancelCtx, event.id == gpioV2LineEventRisingEdge, event.timestampNs); err != nil {
+					return
+				}
 			}
 		}
 	}, di.parentBoard.activeBackgroundWorkers.Done)
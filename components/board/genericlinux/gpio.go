@@ -1,17 +1,20 @@
 //go:build linux
 
 // Package genericlinux is for Linux boards, and this particular file is for GPIO pins using the
-// ioctl interface, indirectly by way of mkch's gpio package.
+// ioctl interface, either directly with the v2 uAPI (see gpio_cdev.go) or indirectly by way of
+// mkch's gpio package.
 package genericlinux
 
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/mkch/gpio"
+	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 	"go.viam.com/utils"
 
@@ -21,12 +24,13 @@ import (
 type gpioPin struct {
 	parentBoard *sysfsBoard
 
-	// These values should both be considered immutable.
+	// These values should be considered immutable.
 	devicePath string
 	offset     uint32
+	settings   lineSettings
 
 	// These values are mutable. Lock the mutex when interacting with them.
-	line            *gpio.Line
+	line            gpioLine
 	isInput         bool
 	swPwmRunning    bool
 	hwPwm           *pwmDevice // Defined in hw_pwm.go, will be nil for pins that don't support it.
@@ -62,27 +66,52 @@ func (pin *gpioPin) openGpioFd(isInput bool) error {
 		}
 	}
 
-	chip, err := gpio.OpenChip(pin.devicePath)
+	settings := pin.settings
+	settings.isInput = pin.isInput
+	line, err := openGpioLine(pin.devicePath, pin.offset, settings)
 	if err != nil {
 		return err
 	}
+	pin.line = line
+	return nil
+}
+
+// A gpioLine is a GPIO line opened either with the GPIO character device v2 uAPI, or with mkch's
+// gpio package on kernels that only support the first version of it.
+type gpioLine interface {
+	Value() (byte, error)
+	SetValue(value byte) error
+	Close() error
+}
+
+// openGpioLine opens the line with the v2 uAPI if the kernel supports it, and falls back to the
+// first version otherwise, as long as the settings don't need the v2 uAPI.
+func openGpioLine(devicePath string, offset uint32, settings lineSettings) (gpioLine, error) {
+	line, err := requestCdevLine(devicePath, offset, "viam-gpio", settings)
+	if err == nil {
+		return line, nil
+	}
+	if !errors.Is(err, errGPIOV2Unsupported) {
+		return nil, err
+	}
+	if settings.usesV2Features() {
+		return nil, errors.Wrap(err, "bias, drive and debounce settings of GPIO pins need the v2 uAPI")
+	}
+
+	chip, err := gpio.OpenChip(devicePath)
+	if err != nil {
+		return nil, err
+	}
 	defer utils.UncheckedErrorFunc(chip.Close)
 
 	direction := gpio.Output
-	if pin.isInput {
+	if settings.isInput {
 		direction = gpio.Input
 	}
 
 	// The 0 just means the default output value for this pin is off. We'll set it to the intended
 	// value in Set(), below, if this is an output pin.
-	// NOTE: we could pass in extra flags to configure the pin to be open-source or open-drain, but
-	// we haven't done that yet, and we instead go with whatever the default on the board is.
-	line, err := chip.OpenLine(pin.offset, 0, direction, "viam-gpio")
-	if err != nil {
-		return err
-	}
-	pin.line = line
-	return nil
+	return chip.OpenLine(offset, 0, direction, "viam-gpio")
 }
 
 func (pin *gpioPin) closeGpioFd() error {
@@ -287,12 +316,34 @@ func (pin *gpioPin) Close() error {
 	return pin.closeGpioFd()
 }
 
+// release stops any software PWM loop on the pin and closes its line, leaving the pin in its
+// current state.
+func (pin *gpioPin) release() error {
+	pin.mu.Lock()
+	defer pin.mu.Unlock()
+
+	pin.swPwmRunning = false
+	return pin.closeGpioFd()
+}
+
 func (b *sysfsBoard) gpioInitialize(cancelCtx context.Context, gpioMappings map[int]GPIOBoardMapping,
-	interruptConfigs []board.DigitalInterruptConfig, logger golog.Logger,
+	interruptConfigs []board.DigitalInterruptConfig, pinConfigs []GPIOPinConfig, logger golog.Logger,
 ) (map[string]*gpioPin, map[string]*digitalInterrupt, error) {
+	pinSettings := make(map[string]lineSettings, len(pinConfigs))
+	for _, config := range pinConfigs {
+		pinNumber, err := strconv.Atoi(config.Pin)
+		if err != nil {
+			return nil, nil, errors.Errorf("pin numbers must be numerical, not '%s'", config.Pin)
+		}
+		if _, ok := gpioMappings[pinNumber]; !ok {
+			return nil, nil, errors.Errorf("unknown GPIO pin %s", config.Pin)
+		}
+		pinSettings[config.Pin] = config.lineSettings()
+	}
+
 	interrupts := make(map[string]*digitalInterrupt, len(interruptConfigs))
 	for _, config := range interruptConfigs {
-		interrupt, err := b.createDigitalInterrupt(cancelCtx, config, gpioMappings)
+		interrupt, err := b.createDigitalInterrupt(cancelCtx, config, gpioMappings, pinSettings[config.Pin])
 		if err != nil {
 			// Close all pins we've started
 			for _, runningInterrupt := range interrupts {
@@ -315,6 +366,7 @@ func (b *sysfsBoard) gpioInitialize(cancelCtx context.Context, gpioMappings map[
 			parentBoard: b,
 			devicePath:  mapping.GPIOChipDev,
 			offset:      uint32(mapping.GPIO),
+			settings:    pinSettings[fmt.Sprintf("%d", pinNumber)],
 			cancelCtx:   cancelCtx,
 			logger:      logger,
 		}
@@ -0,0 +1,316 @@
+//go:build linux
+
+// Package genericlinux is for Linux boards, and this particular file is for requesting GPIO lines
+// directly from version 2 of the GPIO character device uAPI (Linux 5.10 and newer). Unlike the
+// first version, which we use by way of mkch's gpio package, it supports bias, drive and debounce
+// settings, and timestamps edge events in the kernel rather than when we read them.
+package genericlinux
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// The ioctl request numbers and flags of the GPIO v2 uAPI, from include/uapi/linux/gpio.h.
+const (
+	gpioV2GetLineInfoIoctl   uintptr = 0xC100B405
+	gpioV2GetLineIoctl       uintptr = 0xC250B407
+	gpioV2LineGetValuesIoctl uintptr = 0xC010B40E
+	gpioV2LineSetValuesIoctl uintptr = 0xC010B40F
+)
+
+const (
+	gpioV2LineFlagInput        = 1 << 2
+	gpioV2LineFlagOutput       = 1 << 3
+	gpioV2LineFlagEdgeRising   = 1 << 4
+	gpioV2LineFlagEdgeFalling  = 1 << 5
+	gpioV2LineFlagOpenDrain    = 1 << 6
+	gpioV2LineFlagOpenSource   = 1 << 7
+	gpioV2LineFlagBiasPullUp   = 1 << 8
+	gpioV2LineFlagBiasPullDown = 1 << 9
+	gpioV2LineFlagBiasDisabled = 1 << 10
+	// Linux 5.11 and newer can timestamp edge events with the realtime clock rather than the
+	// monotonic one.
+	gpioV2LineFlagEventClockRealtime = 1 << 11
+
+	gpioV2LineAttrIDDebounce   = 3
+	gpioV2LineEventRisingEdge  = 1
+	gpioV2LineEventFallingEdge = 2
+
+	gpioMaxNameSize          = 32
+	gpioV2LinesMax           = 64
+	gpioV2LineNumAttrsMax    = 10
+	gpioV2LineEventBufferLen = 64
+)
+
+// These structs mirror the layout of the structs of the GPIO v2 uAPI. Every field is naturally
+// aligned, so the layout is the same on 32 and 64 bit platforms.
+type gpioV2LineAttribute struct {
+	id      uint32
+	padding uint32
+	// a union of the flags, the output values, and the debounce period of the line
+	value [8]byte
+}
+
+type gpioV2LineConfigAttribute struct {
+	attr gpioV2LineAttribute
+	mask uint64
+}
+
+type gpioV2LineConfig struct {
+	flags    uint64
+	numAttrs uint32
+	padding  [5]uint32
+	attrs    [gpioV2LineNumAttrsMax]gpioV2LineConfigAttribute
+}
+
+type gpioV2LineRequest struct {
+	offsets         [gpioV2LinesMax]uint32
+	consumer        [gpioMaxNameSize]byte
+	config          gpioV2LineConfig
+	numLines        uint32
+	eventBufferSize uint32
+	padding         [5]uint32
+	fd              int32
+}
+
+type gpioV2LineValues struct {
+	bits uint64
+	mask uint64
+}
+
+type gpioV2LineEvent struct {
+	timestampNs uint64
+	id          uint32
+	offset      uint32
+	seqno       uint32
+	lineSeqno   uint32
+	padding     [6]uint32
+}
+
+type gpioV2LineInfo struct {
+	name     [gpioMaxNameSize]byte
+	consumer [gpioMaxNameSize]byte
+	offset   uint32
+	numAttrs uint32
+	flags    uint64
+	attrs    [gpioV2LineNumAttrsMax]gpioV2LineAttribute
+	padding  [4]uint32
+}
+
+// errGPIOV2Unsupported is returned when the kernel doesn't support the GPIO v2 uAPI.
+var errGPIOV2Unsupported = errors.New("the GPIO character device v2 uAPI is not supported by this kernel")
+
+// lineSettings are the settings a GPIO line is requested with.
+type lineSettings struct {
+	isInput  bool
+	edges    bool // Only used for inputs: whether to report edge events on both edges.
+	bias     string
+	drive    string
+	debounce time.Duration
+}
+
+// usesV2Features returns whether the settings can only be applied with the GPIO v2 uAPI.
+func (s lineSettings) usesV2Features() bool {
+	return (s.bias != "" && s.bias != biasAsIs) || (s.drive != "" && s.drive != drivePushPull) || s.debounce > 0
+}
+
+func (s lineSettings) flags() uint64 {
+	var flags uint64
+	if s.isInput {
+		flags |= gpioV2LineFlagInput
+		if s.edges {
+			flags |= gpioV2LineFlagEdgeRising | gpioV2LineFlagEdgeFalling | gpioV2LineFlagEventClockRealtime
+		}
+	} else {
+		flags |= gpioV2LineFlagOutput
+		switch s.drive {
+		case driveOpenDrain:
+			flags |= gpioV2LineFlagOpenDrain
+		case driveOpenSource:
+			flags |= gpioV2LineFlagOpenSource
+		}
+	}
+	switch s.bias {
+	case biasPullUp:
+		flags |= gpioV2LineFlagBiasPullUp
+	case biasPullDown:
+		flags |= gpioV2LineFlagBiasPullDown
+	case biasDisabled:
+		flags |= gpioV2LineFlagBiasDisabled
+	}
+	return flags
+}
+
+// newGPIOV2LineRequest builds the request for a single line with the given settings.
+func newGPIOV2LineRequest(offset uint32, consumer string, settings lineSettings) *gpioV2LineRequest {
+	var request gpioV2LineRequest
+	request.offsets[0] = offset
+	request.numLines = 1
+	copy(request.consumer[:gpioMaxNameSize-1], consumer)
+	request.config.flags = settings.flags()
+	if settings.isInput && settings.edges {
+		request.eventBufferSize = gpioV2LineEventBufferLen
+	}
+	if settings.isInput && settings.debounce > 0 {
+		attr := &request.config.attrs[0]
+		attr.attr.id = gpioV2LineAttrIDDebounce
+		*(*uint32)(unsafe.Pointer(&attr.attr.value[0])) = uint32(settings.debounce.Microseconds())
+		attr.mask = 1 // the bit of the first (and only) line in the request
+		request.config.numAttrs = 1
+	}
+	return &request
+}
+
+// gpioIoctl performs the ioctl on the file descriptor, retrying if it is interrupted.
+func gpioIoctl(fd uintptr, request uintptr, arg unsafe.Pointer) error {
+	for {
+		_, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, request, uintptr(arg))
+		switch errno {
+		case 0:
+			return nil
+		case unix.EINTR:
+			continue
+		default:
+			return errno
+		}
+	}
+}
+
+// A gpioCdevLine is a single GPIO line requested with the GPIO v2 uAPI.
+type gpioCdevLine struct {
+	f *os.File
+	// monotonicEvents is whether the kernel timestamps the edge events of the line with the
+	// monotonic clock, because it doesn't support the realtime one.
+	monotonicEvents bool
+}
+
+// requestCdevLine requests the line at the offset of the GPIO chip at devicePath. If the kernel
+// doesn't support the v2 uAPI, it returns an error wrapping errGPIOV2Unsupported.
+func requestCdevLine(devicePath string, offset uint32, consumer string, settings lineSettings) (*gpioCdevLine, error) {
+	chipFd, err := unix.Open(devicePath, unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: devicePath, Err: err}
+	}
+	defer func() {
+		//nolint:errcheck,gosec
+		unix.Close(chipFd)
+	}()
+
+	// Kernels without the v2 uAPI reject its ioctls. Getting the info of the line first lets us
+	// tell that apart from the kernel rejecting the settings we request.
+	info := gpioV2LineInfo{offset: offset}
+	if err := gpioIoctl(uintptr(chipFd), gpioV2GetLineInfoIoctl, unsafe.Pointer(&info)); err != nil {
+		if errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EINVAL) {
+			err = errGPIOV2Unsupported
+		}
+		return nil, errors.Wrapf(err, "cannot get info of line %d of %s", offset, devicePath)
+	}
+
+	request := newGPIOV2LineRequest(offset, consumer, settings)
+	err = gpioIoctl(uintptr(chipFd), gpioV2GetLineIoctl, unsafe.Pointer(request))
+	monotonicEvents := false
+	if errors.Is(err, unix.EINVAL) && request.config.flags&gpioV2LineFlagEventClockRealtime != 0 {
+		// Linux 5.10 rejects the realtime clock flag, and timestamps events with the monotonic clock.
+		request = newGPIOV2LineRequest(offset, consumer, settings)
+		request.config.flags &^= gpioV2LineFlagEventClockRealtime
+		err = gpioIoctl(uintptr(chipFd), gpioV2GetLineIoctl, unsafe.Pointer(request))
+		monotonicEvents = true
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot request line %d of %s", offset, devicePath)
+	}
+	// The line's file descriptor is made non-blocking so that reads of its edge events go through
+	// Go's poller, and can be interrupted with read deadlines.
+	if err := unix.SetNonblock(int(request.fd), true); err != nil {
+		//nolint:errcheck,gosec
+		unix.Close(int(request.fd))
+		return nil, err
+	}
+	return &gpioCdevLine{
+		f:               os.NewFile(uintptr(request.fd), fmt.Sprintf("%s:%d", devicePath, offset)),
+		monotonicEvents: monotonicEvents,
+	}, nil
+}
+
+// control runs the function with the file descriptor of the line.
+func (l *gpioCdevLine) control(fn func(fd uintptr) error) error {
+	conn, err := l.f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) { fnErr = fn(fd) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+// Value returns the value of the line, 1 if it is active and 0 otherwise.
+func (l *gpioCdevLine) Value() (byte, error) {
+	values := gpioV2LineValues{mask: 1}
+	if err := l.control(func(fd uintptr) error {
+		return gpioIoctl(fd, gpioV2LineGetValuesIoctl, unsafe.Pointer(&values))
+	}); err != nil {
+		return 0, err
+	}
+	return byte(values.bits & 1), nil
+}
+
+// SetValue sets the value of an output line.
+func (l *gpioCdevLine) SetValue(value byte) error {
+	values := gpioV2LineValues{mask: 1}
+	if value != 0 {
+		values.bits = 1
+	}
+	return l.control(func(fd uintptr) error {
+		return gpioIoctl(fd, gpioV2LineSetValuesIoctl, unsafe.Pointer(&values))
+	})
+}
+
+// ReadEvents blocks until there are edge events on the line, and fills events with as many of them
+// as are available, returning how many it read. The events are timestamped in nanoseconds since the
+// Unix epoch, like the events of the first version of the uAPI.
+func (l *gpioCdevLine) ReadEvents(events []gpioV2LineEvent) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	eventSize := int(unsafe.Sizeof(events[0]))
+	buf := unsafe.Slice((*byte)(unsafe.Pointer(&events[0])), len(events)*eventSize)
+	n, err := l.f.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+	if n%eventSize != 0 {
+		return 0, errors.Errorf("read a partial GPIO line event of %d bytes", n%eventSize)
+	}
+	n /= eventSize
+	if l.monotonicEvents {
+		var monotonicNow unix.Timespec
+		if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &monotonicNow); err != nil {
+			return 0, err
+		}
+		// how far the Unix epoch is behind the start of the monotonic clock
+		offset := time.Now().UnixNano() - monotonicNow.Nano()
+		for i := range events[:n] {
+			events[i].timestampNs = uint64(int64(events[i].timestampNs) + offset)
+		}
+	}
+	return n, nil
+}
+
+// SetReadDeadline sets the deadline for ReadEvents. A deadline in the past interrupts a read that
+// is blocked waiting for events.
+func (l *gpioCdevLine) SetReadDeadline(t time.Time) error {
+	return l.f.SetReadDeadline(t)
+}
+
+// Close releases the line.
+func (l *gpioCdevLine) Close() error {
+	return l.f.Close()
+}
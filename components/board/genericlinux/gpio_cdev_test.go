@@ -0,0 +1,233 @@
+//go:build linux
+
+package genericlinux
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/board"
+)
+
+// ioctlReadWrite computes the number of an ioctl that reads and writes a struct of the given size,
+// like the _IOWR macro.
+func ioctlReadWrite(nr, size uintptr) uintptr {
+	return 3<<30 | size<<16 | 0xB4<<8 | nr
+}
+
+func TestGPIOV2Layout(t *testing.T) {
+	test.That(t, unsafe.Sizeof(gpioV2LineAttribute{}), test.ShouldEqual, 16)
+	test.That(t, unsafe.Sizeof(gpioV2LineConfigAttribute{}), test.ShouldEqual, 24)
+	test.That(t, unsafe.Sizeof(gpioV2LineConfig{}), test.ShouldEqual, 272)
+	test.That(t, unsafe.Sizeof(gpioV2LineRequest{}), test.ShouldEqual, 592)
+	test.That(t, unsafe.Offsetof(gpioV2LineRequest{}.fd), test.ShouldEqual, 588)
+	test.That(t, unsafe.Sizeof(gpioV2LineValues{}), test.ShouldEqual, 16)
+	test.That(t, unsafe.Sizeof(gpioV2LineEvent{}), test.ShouldEqual, 48)
+	test.That(t, unsafe.Sizeof(gpioV2LineInfo{}), test.ShouldEqual, 256)
+
+	test.That(t, gpioV2GetLineInfoIoctl, test.ShouldEqual, ioctlReadWrite(0x05, unsafe.Sizeof(gpioV2LineInfo{})))
+	test.That(t, gpioV2GetLineIoctl, test.ShouldEqual, ioctlReadWrite(0x07, unsafe.Sizeof(gpioV2LineRequest{})))
+	test.That(t, gpioV2LineGetValuesIoctl, test.ShouldEqual, ioctlReadWrite(0x0E, unsafe.Sizeof(gpioV2LineValues{})))
+	test.That(t, gpioV2LineSetValuesIoctl, test.ShouldEqual, ioctlReadWrite(0x0F, unsafe.Sizeof(gpioV2LineValues{})))
+}
+
+func TestGPIOV2LineRequest(t *testing.T) {
+	output := newGPIOV2LineRequest(7, "viam-gpio", lineSettings{bias: biasPullUp, drive: driveOpenDrain})
+	test.That(t, output.offsets[0], test.ShouldEqual, 7)
+	test.That(t, output.numLines, test.ShouldEqual, 1)
+	test.That(t, string(output.consumer[:9]), test.ShouldEqual, "viam-gpio")
+	test.That(t, output.config.flags, test.ShouldEqual,
+		gpioV2LineFlagOutput|gpioV2LineFlagOpenDrain|gpioV2LineFlagBiasPullUp)
+	test.That(t, output.config.numAttrs, test.ShouldEqual, 0)
+	test.That(t, output.eventBufferSize, test.ShouldEqual, 0)
+
+	// the drive of inputs is ignored, and their debounce period is set
+	input := newGPIOV2LineRequest(3, "viam-interrupt", lineSettings{
+		isInput: true, edges: true, bias: biasPullDown, drive: driveOpenSource, debounce: 5 * time.Millisecond,
+	})
+	test.That(t, input.config.flags, test.ShouldEqual,
+		gpioV2LineFlagInput|gpioV2LineFlagEdgeRising|gpioV2LineFlagEdgeFalling|gpioV2LineFlagEventClockRealtime|
+			gpioV2LineFlagBiasPullDown)
+	test.That(t, input.eventBufferSize, test.ShouldEqual, gpioV2LineEventBufferLen)
+	test.That(t, input.config.numAttrs, test.ShouldEqual, 1)
+	test.That(t, input.config.attrs[0].attr.id, test.ShouldEqual, gpioV2LineAttrIDDebounce)
+	test.That(t, input.config.attrs[0].mask, test.ShouldEqual, 1)
+	test.That(t, *(*uint32)(unsafe.Pointer(&input.config.attrs[0].attr.value[0])), test.ShouldEqual, 5000)
+
+	test.That(t, lineSettings{}.usesV2Features(), test.ShouldBeFalse)
+	test.That(t, lineSettings{bias: biasAsIs, drive: drivePushPull}.usesV2Features(), test.ShouldBeFalse)
+	test.That(t, lineSettings{bias: biasDisabled}.usesV2Features(), test.ShouldBeTrue)
+	test.That(t, lineSettings{debounce: time.Millisecond}.usesV2Features(), test.ShouldBeTrue)
+}
+
+func TestGPIOPinConfigValidate(t *testing.T) {
+	conf := GPIOPinConfig{}
+	err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "pin")
+
+	conf = GPIOPinConfig{Pin: "3", Bias: biasPullUp, Drive: driveOpenDrain, DebounceMicroseconds: 100}
+	test.That(t, conf.Validate("path"), test.ShouldBeNil)
+	test.That(t, conf.lineSettings(), test.ShouldResemble,
+		lineSettings{bias: biasPullUp, drive: driveOpenDrain, debounce: 100 * time.Microsecond})
+
+	for _, bad := range []GPIOPinConfig{
+		{Pin: "3", Bias: "up"},
+		{Pin: "3", Drive: "open_collector"},
+		{Pin: "3", DebounceMicroseconds: -1},
+	} {
+		test.That(t, bad.Validate("path"), test.ShouldNotBeNil)
+	}
+}
+
+// A gpioSim is a simulated GPIO chip created with the kernel's gpio-sim module.
+type gpioSim struct {
+	configDir string
+	linesDir  string
+	chipPath  string
+}
+
+// newGPIOSim creates a simulated GPIO chip, or skips the test if the gpio-sim module isn't
+// available (it needs configfs mounted, the module loaded, and root).
+func newGPIOSim(t *testing.T, numLines int) *gpioSim {
+	t.Helper()
+	const simRoot = "/sys/kernel/config/gpio-sim"
+	if _, err := os.Stat(simRoot); err != nil {
+		t.Skipf("gpio-sim is not available: %v", err)
+	}
+	configDir := filepath.Join(simRoot, fmt.Sprintf("viam-test-%d", os.Getpid()))
+	writeFile := func(path, value string) {
+		t.Helper()
+		test.That(t, os.WriteFile(path, []byte(value), 0o600), test.ShouldBeNil)
+	}
+	readFile := func(path string) string {
+		t.Helper()
+		//nolint:gosec
+		data, err := os.ReadFile(path)
+		test.That(t, err, test.ShouldBeNil)
+		return strings.TrimSpace(string(data))
+	}
+
+	if err := os.Mkdir(configDir, 0o700); err != nil {
+		t.Skipf("cannot create a gpio-sim chip: %v", err)
+	}
+	test.That(t, os.Mkdir(filepath.Join(configDir, "bank0"), 0o700), test.ShouldBeNil)
+	writeFile(filepath.Join(configDir, "bank0", "num_lines"), fmt.Sprintf("%d", numLines))
+	writeFile(filepath.Join(configDir, "live"), "1")
+	t.Cleanup(func() {
+		writeFile(filepath.Join(configDir, "live"), "0")
+		test.That(t, os.Remove(filepath.Join(configDir, "bank0")), test.ShouldBeNil)
+		test.That(t, os.Remove(configDir), test.ShouldBeNil)
+	})
+
+	chipName := readFile(filepath.Join(configDir, "bank0", "chip_name"))
+	devName := readFile(filepath.Join(configDir, "dev_name"))
+	return &gpioSim{
+		configDir: configDir,
+		linesDir:  filepath.Join("/sys/devices/platform", devName, chipName),
+		chipPath:  filepath.Join("/dev", chipName),
+	}
+}
+
+// pull drives the simulated input of the line high or low.
+func (sim *gpioSim) pull(t *testing.T, offset int, high bool) {
+	t.Helper()
+	value := "pull-down"
+	if high {
+		value = "pull-up"
+	}
+	path := filepath.Join(sim.linesDir, fmt.Sprintf("sim_gpio%d", offset), "pull")
+	test.That(t, os.WriteFile(path, []byte(value), 0o600), test.ShouldBeNil)
+}
+
+// value returns the value of the line as seen by the simulated chip.
+func (sim *gpioSim) value(t *testing.T, offset int) string {
+	t.Helper()
+	//nolint:gosec
+	data, err := os.ReadFile(filepath.Join(sim.linesDir, fmt.Sprintf("sim_gpio%d", offset), "value"))
+	test.That(t, err, test.ShouldBeNil)
+	return strings.TrimSpace(string(data))
+}
+
+func TestGPIOSimLines(t *testing.T) {
+	sim := newGPIOSim(t, 4)
+
+	output, err := openGpioLine(sim.chipPath, 0, lineSettings{drive: driveOpenSource})
+	test.That(t, err, test.ShouldBeNil)
+	_, isV2 := output.(*gpioCdevLine)
+	test.That(t, isV2, test.ShouldBeTrue)
+	test.That(t, output.SetValue(1), test.ShouldBeNil)
+	test.That(t, sim.value(t, 0), test.ShouldEqual, "1")
+	test.That(t, output.SetValue(0), test.ShouldBeNil)
+	test.That(t, sim.value(t, 0), test.ShouldEqual, "0")
+
+	// the line is busy until it's closed
+	_, err = openGpioLine(sim.chipPath, 0, lineSettings{})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, output.Close(), test.ShouldBeNil)
+
+	input, err := openGpioLine(sim.chipPath, 1, lineSettings{isInput: true, bias: biasPullDown})
+	test.That(t, err, test.ShouldBeNil)
+	sim.pull(t, 1, true)
+	value, err := input.Value()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 1)
+	sim.pull(t, 1, false)
+	value, err = input.Value()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 0)
+	test.That(t, input.Close(), test.ShouldBeNil)
+}
+
+func TestGPIOSimDigitalInterrupt(t *testing.T) {
+	sim := newGPIOSim(t, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &sysfsBoard{
+		Named:  board.Named("foo").AsNamed(),
+		logger: golog.NewTestLogger(t),
+	}
+	mappings := map[int]GPIOBoardMapping{3: {GPIOChipDev: sim.chipPath, GPIO: 2}}
+	interrupt, err := b.createDigitalInterrupt(ctx, board.DigitalInterruptConfig{Name: "i", Pin: "3"}, mappings,
+		lineSettings{debounce: time.Microsecond})
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		cancel()
+		b.activeBackgroundWorkers.Wait()
+		test.That(t, interrupt.Close(), test.ShouldBeNil)
+	}()
+
+	ticks := make(chan board.Tick, 2)
+	interrupt.interrupt.AddCallback(ticks)
+	nextTick := func() board.Tick {
+		select {
+		case tick := <-ticks:
+			return tick
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a tick")
+			return board.Tick{}
+		}
+	}
+
+	sim.pull(t, 2, true)
+	rising := nextTick()
+	test.That(t, rising.High, test.ShouldBeTrue)
+	sim.pull(t, 2, false)
+	falling := nextTick()
+	test.That(t, falling.High, test.ShouldBeFalse)
+	// the timestamps come from the kernel, on the same clock as those of the first version of the uAPI
+	test.That(t, falling.TimestampNanosec, test.ShouldBeGreaterThan, rising.TimestampNanosec)
+	test.That(t, time.Since(time.Unix(0, int64(rising.TimestampNanosec))), test.ShouldBeBetween, 0, 10*time.Second)
+
+	value, err := interrupt.interrupt.Value(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, value, test.ShouldEqual, 1)
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"sync/atomic"
 
 	"github.com/edaniels/golog"
 	"github.com/edaniels/gostream"
@@ -80,11 +81,13 @@ func (cfg *joinConfig) Validate(path string) ([]string, error) {
 // joinColorDepth takes a color and depth image source and aligns them together.
 type joinColorDepth struct {
 	color, depth         gostream.VideoStream
+	colorSrc, depthSrc   camera.VideoSource
 	colorName, depthName string
 	projector            transform.Projector
 	imageType            camera.ImageType
 	debug                bool
 	logger               golog.Logger
+	frameID              atomic.Uint64
 }
 
 // newJoinColorDepth creates a gostream.VideoSource that aligned color and depth channels.
@@ -96,8 +99,10 @@ func newJoinColorDepth(ctx context.Context, color, depth camera.VideoSource, con
 	imgType := camera.ImageType(conf.ImageType)
 	videoSrc := &joinColorDepth{
 		color:     gostream.NewEmbeddedVideoStream(color),
+		colorSrc:  color,
 		colorName: conf.Color,
 		depth:     gostream.NewEmbeddedVideoStream(depth),
+		depthSrc:  depth,
 		depthName: conf.Depth,
 		projector: conf.CameraParameters,
 		imageType: imgType,
@@ -128,20 +133,36 @@ func (jcd *joinColorDepth) Read(ctx context.Context) (image.Image, func(), error
 	}
 }
 
+// Images returns the color image of the color camera and the depth map of the depth camera, captured together.
+// Each keeps the time its camera captured it.
+func (jcd *joinColorDepth) Images(ctx context.Context) ([]camera.NamedImage, error) {
+	ctx, span := trace.StartSpan(ctx, "align::joinColorDepth::Images")
+	defer span.End()
+	col, dm, err := camera.SimultaneousColorDepthImages(ctx, jcd.colorSrc, jcd.depthSrc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get images from source cameras %q and %q for join_color_depth camera",
+			jcd.colorName, jcd.depthName)
+	}
+	if jcd.debug {
+		jcd.logger.Debugf("join_color_depth: depth image captured %v after color image", dm.CapturedAt.Sub(col.CapturedAt))
+	}
+	frameID := jcd.frameID.Add(1)
+	col.SourceName, col.FrameID = string(camera.ColorStream), frameID
+	dm.SourceName, dm.FrameID = string(camera.DepthStream), frameID
+	return []camera.NamedImage{col, dm}, nil
+}
+
 func (jcd *joinColorDepth) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
 	ctx, span := trace.StartSpan(ctx, "align::joinColorDepth::NextPointCloud")
 	defer span.End()
 	if jcd.projector == nil {
 		return nil, transform.NewNoIntrinsicsError("no intrinsic_parameters in camera attributes")
 	}
-	col, dm := camera.SimultaneousColorDepthNext(ctx, jcd.color, jcd.depth)
-	if col == nil {
-		return nil, errors.Errorf("could not get color image from source camera %q for join_color_depth camera", jcd.colorName)
-	}
-	if dm == nil {
-		return nil, errors.Errorf("could not get depth image from source camera %q for join_color_depth camera", jcd.depthName)
+	imgs, err := jcd.Images(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return jcd.projector.RGBDToPointCloud(rimage.ConvertImage(col), dm)
+	return jcd.projector.RGBDToPointCloud(rimage.ConvertImage(imgs[0].Image), imgs[1].Image.(*rimage.DepthMap))
 }
 
 func (jcd *joinColorDepth) Close(ctx context.Context) error {
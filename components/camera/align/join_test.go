@@ -61,3 +61,52 @@ func TestJoin(t *testing.T) {
 	_, err = newJoinColorDepth(context.Background(), colorVideoSrc, depthVideoSrc, joinConf, logger)
 	test.That(t, errors.Is(err, transform.ErrNoIntrinsics), test.ShouldBeTrue)
 }
+
+func TestJoinImages(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	img := rimage.NewImage(64, 48)
+	img.SetXY(10, 10, rimage.Red)
+	dm := rimage.NewEmptyDepthMap(64, 48)
+	dm.Set(10, 10, 500)
+	colorVideoSrc, err := camera.NewVideoSourceFromReader(
+		context.Background(), &videosource.StaticSource{ColorImg: img}, nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	depthVideoSrc, err := camera.NewVideoSourceFromReader(
+		context.Background(), &videosource.StaticSource{DepthImg: dm}, nil, camera.DepthStream)
+	test.That(t, err, test.ShouldBeNil)
+	joinConf := &joinConfig{
+		Color: "color",
+		Depth: "depth",
+		CameraParameters: &transform.PinholeCameraIntrinsics{
+			Width: 64, Height: 48, Fx: 50, Fy: 50, Ppx: 32, Ppy: 24,
+		},
+	}
+	is, err := newJoinColorDepth(context.Background(), colorVideoSrc, depthVideoSrc, joinConf, logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, is.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	_, ok := is.(camera.ImagesSource)
+	test.That(t, ok, test.ShouldBeTrue)
+	imgs, err := camera.Images(context.Background(), is)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 2)
+	test.That(t, imgs[0].SourceName, test.ShouldEqual, "color")
+	test.That(t, imgs[1].SourceName, test.ShouldEqual, "depth")
+	test.That(t, imgs[1].Image.(*rimage.DepthMap).GetDepth(10, 10), test.ShouldEqual, rimage.Depth(500))
+	test.That(t, imgs[0].FrameID, test.ShouldEqual, imgs[1].FrameID)
+	test.That(t, imgs[0].CapturedAt.IsZero(), test.ShouldBeFalse)
+	test.That(t, imgs[1].CapturedAt.IsZero(), test.ShouldBeFalse)
+
+	next, err := camera.Images(context.Background(), is)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, next[0].FrameID, test.ShouldEqual, imgs[0].FrameID+1)
+
+	pc, err := is.NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	x, y, z := joinConf.CameraParameters.PixelToPoint(10, 10, 500)
+	d, ok := pc.At(x, y, z)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.HasColor(), test.ShouldBeTrue)
+}
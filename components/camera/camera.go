@@ -5,6 +5,7 @@ import (
 	"context"
 	"image"
 	"sync"
+	"time"
 
 	"github.com/edaniels/gostream"
 	"github.com/pion/mediadevices/pkg/prop"
@@ -14,6 +15,7 @@ import (
 	pb "go.viam.com/api/component/camera/v1"
 	viamutils "go.viam.com/utils"
 
+	streampb "go.viam.com/rdk/components/camera/proto/v1"
 	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/resource"
@@ -29,6 +31,11 @@ func init() {
 		RPCServiceHandler:           pb.RegisterCameraServiceHandlerFromEndpoint,
 		RPCServiceDesc:              &pb.CameraService_ServiceDesc,
 		RPCClient:                   NewClientFromConn,
+		ExtraRPCServices: []resource.ExtraRPCService[Camera]{{
+			ServerConstructor: NewPointCloudStreamServiceServer,
+			Handler:           streampb.RegisterPointCloudStreamServiceHandlerFromEndpoint,
+			Desc:              &streampb.PointCloudStreamService_ServiceDesc,
+		}},
 	})
 
 	data.RegisterCollector(data.MethodMetadata{
@@ -81,7 +88,7 @@ type VideoSource interface {
 	Stream(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error)
 
 	// NextPointCloud returns the next immediately available point cloud, not necessarily one
-	// a part of a sequence. In the future, there could be streaming of point clouds.
+	// a part of a sequence. Use StreamPointClouds for a sequence of point clouds.
 	NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error)
 	// Properties returns properties that are intrinsic to the particular
 	// implementation of a camera
@@ -89,6 +96,62 @@ type VideoSource interface {
 	Close(ctx context.Context) error
 }
 
+// A NamedImage is an image captured by one of the sensors of a camera.
+type NamedImage struct {
+	Image image.Image
+	// SourceName names the sensor that captured the image, e.g. "color", "depth" or "ir".
+	SourceName string
+	// CapturedAt is when the image was captured.
+	CapturedAt time.Time
+	// FrameID numbers the sets of images returned by a camera; images returned together share the same FrameID.
+	FrameID uint64
+}
+
+// An ImagesSource is a camera that can capture images from all of its sensors at once, e.g. its color and depth
+// sensors. Video sources made from readers that implement it implement it too. Use Images to get the images of any
+// camera.
+type ImagesSource interface {
+	// Images returns an image from each of the sensors of the camera, captured as close together in time as the
+	// camera allows.
+	Images(ctx context.Context) ([]NamedImage, error)
+}
+
+// Images returns an image from each of the sensors of the source. Sources that are ImagesSources capture their own;
+// otherwise, the next image of the source is its only one, named after whether it is a depth map, with a FrameID of
+// zero.
+func Images(ctx context.Context, src gostream.VideoSource) ([]NamedImage, error) {
+	if s, ok := src.(ImagesSource); ok {
+		return s.Images(ctx)
+	}
+	img, release, err := ReadImage(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	capturedAt := time.Now()
+	// the image is only valid until released, so keep a copy of it
+	if release != nil {
+		defer release()
+	}
+	sourceName := ColorStream
+	if dm, ok := img.(*rimage.DepthMap); ok {
+		img = dm.Clone()
+		sourceName = DepthStream
+	} else {
+		img = rimage.CloneImage(img)
+	}
+	return []NamedImage{{Image: img, SourceName: string(sourceName), CapturedAt: capturedAt}}, nil
+}
+
+// FindNamedImage returns the image of the named sensor from a set of images.
+func FindNamedImage(images []NamedImage, sourceName string) (NamedImage, bool) {
+	for _, img := range images {
+		if img.SourceName == sourceName {
+			return img, true
+		}
+	}
+	return NamedImage{}, false
+}
+
 // ReadImage reads an image from the given source that is immediately available.
 func ReadImage(ctx context.Context, src gostream.VideoSource) (image.Image, func(), error) {
 	return gostream.ReadImage(ctx, src)
@@ -108,10 +171,14 @@ type PointCloudSource interface {
 // If needed, implement the Camera another way. For example, a webcam
 // implements a Camera manually so that it can atomically reconfigure itself.
 func FromVideoSource(name resource.Name, src VideoSource) Camera {
-	return &sourceBasedCamera{
+	cam := &sourceBasedCamera{
 		Named:       name.AsNamed(),
 		VideoSource: src,
 	}
+	if _, ok := src.(ImagesSource); ok {
+		return &imagesSourceBasedCamera{cam}
+	}
+	return cam
 }
 
 type sourceBasedCamera struct {
@@ -120,6 +187,16 @@ type sourceBasedCamera struct {
 	VideoSource
 }
 
+// imagesSourceBasedCamera is a sourceBasedCamera whose video source is an ImagesSource.
+type imagesSourceBasedCamera struct {
+	*sourceBasedCamera
+}
+
+func (c *imagesSourceBasedCamera) Images(ctx context.Context) ([]NamedImage, error) {
+	//nolint:forcetypeassert
+	return c.VideoSource.(ImagesSource).Images(ctx)
+}
+
 // NewVideoSourceFromReader creates a VideoSource either with or without a projector. The stream type
 // argument is for detecting whether or not the resulting camera supports return
 // of pointcloud data in the absence of an implemented NextPointCloud function.
@@ -151,13 +228,13 @@ func NewVideoSourceFromReader(
 			actualSystem = &cameraModel
 		}
 	}
-	return &videoSource{
+	return newVideoSource(&videoSource{
 		system:       actualSystem,
 		videoSource:  vs,
 		videoStream:  gostream.NewEmbeddedVideoStream(vs),
 		actualSource: reader,
 		imageType:    imageType,
-	}, nil
+	}), nil
 }
 
 // NewPinholeModelWithBrownConradyDistortion creates a transform.PinholeCameraModel from
@@ -212,13 +289,21 @@ func WrapVideoSourceWithProjector(
 			actualSystem = &cameraModel
 		}
 	}
-	return &videoSource{
+	return newVideoSource(&videoSource{
 		system:       actualSystem,
 		videoSource:  source,
 		videoStream:  gostream.NewEmbeddedVideoStream(source),
 		actualSource: source,
 		imageType:    imageType,
-	}, nil
+	}), nil
+}
+
+// newVideoSource returns vs, as an ImagesSource if its underlying source is one.
+func newVideoSource(vs *videoSource) VideoSource {
+	if _, ok := vs.actualSource.(ImagesSource); ok {
+		return &imagesVideoSource{vs}
+	}
+	return vs
 }
 
 // videoSource implements a Camera with a gostream.VideoSource.
@@ -230,6 +315,18 @@ type videoSource struct {
 	imageType    ImageType
 }
 
+// imagesVideoSource is a videoSource whose underlying source is an ImagesSource.
+type imagesVideoSource struct {
+	*videoSource
+}
+
+func (vs *imagesVideoSource) Images(ctx context.Context) ([]NamedImage, error) {
+	ctx, span := trace.StartSpan(ctx, "camera::videoSource::Images")
+	defer span.End()
+	//nolint:forcetypeassert
+	return vs.actualSource.(ImagesSource).Images(ctx)
+}
+
 func (vs *videoSource) Stream(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
 	return vs.videoSource.Stream(ctx, errHandlers...)
 }
@@ -311,6 +408,47 @@ func NamesFromRobot(r robot.Robot) []string {
 	return robot.NamesBySubtype(r, Subtype)
 }
 
+// SimultaneousColorDepthImages captures the images of a color and a depth camera as simultaneously as possible. It
+// returns the color image of the color camera and the depth map of the depth camera, each with when it was captured.
+func SimultaneousColorDepthImages(ctx context.Context, color, depth VideoSource) (NamedImage, NamedImage, error) {
+	var wg sync.WaitGroup
+	var col, dm NamedImage
+	var colErr, dmErr error
+	wg.Add(2)
+	viamutils.PanicCapturingGo(func() {
+		defer wg.Done()
+		col, colErr = nextNamedImage(ctx, color, ColorStream)
+	})
+	viamutils.PanicCapturingGo(func() {
+		defer wg.Done()
+		dm, dmErr = nextNamedImage(ctx, depth, DepthStream)
+		if dmErr != nil {
+			return
+		}
+		dm.Image, dmErr = rimage.ConvertImageToDepthMap(ctx, dm.Image)
+	})
+	wg.Wait()
+	if err := multierr.Combine(colErr, dmErr); err != nil {
+		return NamedImage{}, NamedImage{}, err
+	}
+	return col, dm, nil
+}
+
+// nextNamedImage returns the image of the named sensor of src, or its only image if it has just one.
+func nextNamedImage(ctx context.Context, src VideoSource, imageType ImageType) (NamedImage, error) {
+	imgs, err := Images(ctx, src)
+	if err != nil {
+		return NamedImage{}, err
+	}
+	if img, ok := FindNamedImage(imgs, string(imageType)); ok {
+		return img, nil
+	}
+	if len(imgs) == 1 {
+		return imgs[0], nil
+	}
+	return NamedImage{}, errors.Errorf("camera has no %s image", imageType)
+}
+
 // SimultaneousColorDepthNext will call Next on both the color and depth camera as simultaneously as possible.
 func SimultaneousColorDepthNext(ctx context.Context, color, depth gostream.VideoStream) (image.Image, *rimage.DepthMap) {
 	var wg sync.WaitGroup
@@ -248,3 +248,73 @@ func TestCameraWithProjector(t *testing.T) {
 
 	test.That(t, cam2.Close(context.Background()), test.ShouldBeNil)
 }
+
+type imageSource struct {
+	img image.Image
+}
+
+func (s *imageSource) Read(ctx context.Context) (image.Image, func(), error) {
+	return s.img, func() {}, nil
+}
+
+func (s *imageSource) Close(ctx context.Context) error {
+	return nil
+}
+
+type imagesSource struct {
+	imageSource
+}
+
+func (s *imagesSource) Images(ctx context.Context) ([]camera.NamedImage, error) {
+	return []camera.NamedImage{{Image: s.img, SourceName: "ir", FrameID: 7}}, nil
+}
+
+func TestImages(t *testing.T) {
+	dm := rimage.NewEmptyDepthMap(4, 3)
+	dm.Set(1, 1, 10)
+
+	// sources without images of their own return the next image of their stream
+	src, err := camera.NewVideoSourceFromReader(context.Background(), &imageSource{dm}, nil, camera.DepthStream)
+	test.That(t, err, test.ShouldBeNil)
+	_, ok := src.(camera.ImagesSource)
+	test.That(t, ok, test.ShouldBeFalse)
+	cam := camera.FromVideoSource(camera.Named("depth"), src)
+	_, ok = cam.(camera.ImagesSource)
+	test.That(t, ok, test.ShouldBeFalse)
+	imgs, err := camera.Images(context.Background(), cam)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 1)
+	test.That(t, imgs[0].SourceName, test.ShouldEqual, "depth")
+	test.That(t, imgs[0].FrameID, test.ShouldEqual, 0)
+	test.That(t, imgs[0].CapturedAt.IsZero(), test.ShouldBeFalse)
+	img, ok := imgs[0].Image.(*rimage.DepthMap)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, img.GetDepth(1, 1), test.ShouldEqual, rimage.Depth(10))
+	test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+
+	src, err = camera.NewVideoSourceFromReader(
+		context.Background(), &imageSource{rimage.NewImage(4, 3)}, nil, camera.UnspecifiedStream)
+	test.That(t, err, test.ShouldBeNil)
+	imgs, err = camera.Images(context.Background(), src)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs[0].SourceName, test.ShouldEqual, "color")
+	test.That(t, src.Close(context.Background()), test.ShouldBeNil)
+
+	// sources with images of their own return them
+	src, err = camera.NewVideoSourceFromReader(context.Background(), &imagesSource{imageSource{dm}}, nil, camera.DepthStream)
+	test.That(t, err, test.ShouldBeNil)
+	cam = camera.FromVideoSource(camera.Named("ir"), src)
+	imagesCam, ok := cam.(camera.ImagesSource)
+	test.That(t, ok, test.ShouldBeTrue)
+	imgs, err = imagesCam.Images(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 1)
+	test.That(t, imgs[0].SourceName, test.ShouldEqual, "ir")
+	test.That(t, imgs[0].FrameID, test.ShouldEqual, 7)
+	named, ok := camera.FindNamedImage(imgs, "ir")
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, named.Image, test.ShouldEqual, dm)
+	_, ok = camera.FindNamedImage(imgs, "color")
+	test.That(t, ok, test.ShouldBeFalse)
+	test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+}
@@ -1,10 +1,9 @@
 package camera
 
 import (
-	"bytes"
 	"context"
-	"fmt"
 	"image"
+	"strings"
 	"sync"
 
 	"github.com/edaniels/golog"
@@ -13,7 +12,10 @@ import (
 	pb "go.viam.com/api/component/camera/v1"
 	goutils "go.viam.com/utils"
 	"go.viam.com/utils/rpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	streampb "go.viam.com/rdk/components/camera/proto/v1"
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
@@ -22,7 +24,9 @@ import (
 	"go.viam.com/rdk/utils"
 )
 
-// client implements CameraServiceClient.
+// client implements CameraServiceClient. It is not an ImagesSource: the camera service has no call that captures the
+// images of all of a camera's sensors at once, so camera.Images falls back to reading one image, and stamps it with
+// when it arrived, which is all the client could do itself.
 type client struct {
 	resource.Named
 	resource.TriviallyReconfigurable
@@ -31,6 +35,7 @@ type client struct {
 	name                    string
 	conn                    rpc.ClientConn
 	client                  pb.CameraServiceClient
+	streamClient            streampb.PointCloudStreamServiceClient
 	logger                  golog.Logger
 	activeBackgroundWorkers sync.WaitGroup
 	cancelCtx               context.Context
@@ -42,13 +47,14 @@ func NewClientFromConn(ctx context.Context, conn rpc.ClientConn, name resource.N
 	cancelCtx, cancel := context.WithCancel(context.Background())
 	c := pb.NewCameraServiceClient(conn)
 	return &client{
-		Named:     name.AsNamed(),
-		name:      name.ShortNameForClient(),
-		conn:      conn,
-		client:    c,
-		logger:    logger,
-		cancelCtx: cancelCtx,
-		cancel:    cancel,
+		Named:        name.AsNamed(),
+		name:         name.ShortNameForClient(),
+		conn:         conn,
+		client:       c,
+		streamClient: streampb.NewPointCloudStreamServiceClient(conn),
+		logger:       logger,
+		cancelCtx:    cancelCtx,
+		cancel:       cancel,
 	}, nil
 }
 
@@ -129,28 +135,136 @@ func (c *client) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, err
 	ctx, span := trace.StartSpan(ctx, "camera::client::NextPointCloud")
 	defer span.End()
 
+	mimeType := pointCloudMIMEType(ctx)
 	ctx, getPcdSpan := trace.StartSpan(ctx, "camera::client::NextPointCloud::GetPointCloud")
 	resp, err := c.client.GetPointCloud(ctx, &pb.GetPointCloudRequest{
 		Name:     c.name,
-		MimeType: utils.MimeTypePCD,
+		MimeType: mimeType,
 	})
 	getPcdSpan.End()
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.MimeType != utils.MimeTypePCD {
-		return nil, fmt.Errorf("unknown pc mime type %s", resp.MimeType)
-	}
-
 	return func() (pointcloud.PointCloud, error) {
-		_, span := trace.StartSpan(ctx, "camera::client::NextPointCloud::ReadPCD")
+		_, span := trace.StartSpan(ctx, "camera::client::NextPointCloud::Decode")
 		defer span.End()
 
-		return pointcloud.ReadPCD(bytes.NewReader(resp.PointCloud))
+		return pointcloud.Decode(resp.PointCloud, resp.MimeType)
 	}()
 }
 
+// pointCloudMIMEType returns the MIME type to request point clouds in. Point clouds are sent in the compact
+// encoding unless a point cloud MIME type is hinted, like a quantized one from pointcloud.CompactMIMEType.
+// Servers that do not know it send PCD instead.
+func pointCloudMIMEType(ctx context.Context) string {
+	mimeType := gostream.MIMETypeHint(ctx, "")
+	if !strings.HasPrefix(mimeType, "pointcloud/") {
+		mimeType = utils.MimeTypeCompactPointCloud
+	}
+	return mimeType
+}
+
+// StreamPointClouds streams the point clouds of the camera from the server, which limits them to the max rate.
+// Servers that cannot stream point clouds are polled instead, no faster than the max rate.
+func (c *client) StreamPointClouds(ctx context.Context, opts PointCloudStreamOptions) (PointCloudStream, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.cancelCtx.Err(); err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := context.WithCancel(c.cancelCtx)
+	stream, err := c.streamClient.StreamPointClouds(streamCtx, &streampb.StreamPointCloudsRequest{
+		Name:      c.name,
+		MimeType:  pointCloudMIMEType(ctx),
+		MaxRateHz: opts.MaxRate,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// responses are received in the background so that Next can return when its context is done
+	responses := make(chan pointCloudStreamResponse)
+	c.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer c.activeBackgroundWorkers.Done()
+		defer close(responses)
+		for {
+			resp, err := stream.Recv()
+			select {
+			case <-streamCtx.Done():
+				return
+			case responses <- pointCloudStreamResponse{resp, err}:
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+	return &clientPointCloudStream{client: c, responses: responses, cancel: cancel, maxRate: opts.MaxRate}, nil
+}
+
+type pointCloudStreamResponse struct {
+	resp *streampb.StreamPointCloudsResponse
+	err  error
+}
+
+type clientPointCloudStream struct {
+	client    *client
+	responses <-chan pointCloudStreamResponse
+	cancel    func()
+	maxRate   float64
+	received  bool
+	// polled is used instead of the responses when the server cannot stream point clouds.
+	polled PointCloudStream
+}
+
+func (s *clientPointCloudStream) Next(ctx context.Context) (StreamedPointCloud, error) {
+	if s.polled != nil {
+		return s.polled.Next(ctx)
+	}
+
+	var next pointCloudStreamResponse
+	select {
+	case <-ctx.Done():
+		return StreamedPointCloud{}, ctx.Err()
+	case r, ok := <-s.responses:
+		if !ok {
+			return StreamedPointCloud{}, errPointCloudStreamClosed
+		}
+		next = r
+	}
+	if next.err != nil {
+		if !s.received && status.Code(next.err) == codes.Unimplemented {
+			s.client.logger.Debugw("server cannot stream point clouds, polling instead", "camera", s.client.name)
+			s.cancel()
+			s.polled = rateLimitPointCloudStream(&polledPointCloudStream{src: s.client}, s.maxRate)
+			return s.polled.Next(ctx)
+		}
+		return StreamedPointCloud{}, next.err
+	}
+	s.received = true
+
+	_, span := trace.StartSpan(ctx, "camera::client::StreamPointClouds::Decode")
+	defer span.End()
+	pc, err := pointcloud.Decode(next.resp.PointCloud, next.resp.MimeType)
+	if err != nil {
+		return StreamedPointCloud{}, err
+	}
+	return StreamedPointCloud{
+		PointCloud: pc,
+		CapturedAt: next.resp.CapturedAt.AsTime(),
+		Dropped:    next.resp.Dropped,
+	}, nil
+}
+
+func (s *clientPointCloudStream) Close(ctx context.Context) error {
+	s.cancel()
+	return nil
+}
+
 func (c *client) Projector(ctx context.Context) (transform.Projector, error) {
 	var proj transform.Projector
 	props, err := c.Properties(ctx)
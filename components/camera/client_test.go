@@ -9,6 +9,7 @@ import (
 	"net"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/edaniels/gostream"
@@ -162,11 +163,26 @@ func TestClient(t *testing.T) {
 		test.That(t, imageReleased, test.ShouldBeTrue)
 		imageReleasedMu.Unlock()
 
+		imgs, err := camera.Images(ctx, camera1Client)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, imgs, test.ShouldHaveLength, 1)
+		test.That(t, imgs[0].SourceName, test.ShouldEqual, "color")
+		test.That(t, imgs[0].FrameID, test.ShouldEqual, 0)
+		test.That(t, imgs[0].Image.Bounds(), test.ShouldResemble, img.Bounds())
+
 		pcB, err := camera1Client.NextPointCloud(context.Background())
 		test.That(t, err, test.ShouldBeNil)
 		_, got := pcB.At(5, 5, 5)
 		test.That(t, got, test.ShouldBeTrue)
 
+		// point cloud MIME types can be hinted, others are ignored
+		for _, mimeType := range []string{rutils.MimeTypePCD, pointcloud.CompactMIMEType(2), rutils.MimeTypeJPEG} {
+			pcB, err = camera1Client.NextPointCloud(gostream.WithMIMETypeHint(context.Background(), mimeType))
+			test.That(t, err, test.ShouldBeNil)
+			_, got = pcB.At(5, 5, 5)
+			test.That(t, got, test.ShouldBeTrue)
+		}
+
 		projB, err := camera1Client.Projector(context.Background())
 		test.That(t, err, test.ShouldBeNil)
 		test.That(t, projB, test.ShouldNotBeNil)
@@ -390,3 +406,93 @@ func TestClientLazyImage(t *testing.T) {
 
 	test.That(t, conn.Close(), test.ShouldBeNil)
 }
+
+func TestClientStreamPointClouds(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	listener1, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	rpcServer, err := rpc.NewServer(logger, rpc.WithUnauthenticated())
+	test.That(t, err, test.ShouldBeNil)
+	// a server from before point clouds could be streamed
+	listener2, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	oldServer, err := rpc.NewServer(logger, rpc.WithUnauthenticated())
+	test.That(t, err, test.ShouldBeNil)
+
+	pcA := pointcloud.New()
+	test.That(t, pcA.Set(pointcloud.NewVector(5, 5, 5), nil), test.ShouldBeNil)
+	injectCamera := &inject.Camera{}
+	injectCamera.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+		return pcA, nil
+	}
+	injectCamera2 := &inject.Camera{}
+	injectCamera2.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+		return nil, errors.New("can't generate next point cloud")
+	}
+	resources := map[resource.Name]camera.Camera{
+		camera.Named(testCameraName): injectCamera,
+		camera.Named(failCameraName): injectCamera2,
+	}
+	cameraSvc, err := resource.NewSubtypeCollection(camera.Subtype, resources)
+	test.That(t, err, test.ShouldBeNil)
+	resourceSubtype, ok, err := resource.LookupSubtypeRegistration[camera.Camera](camera.Subtype)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, resourceSubtype.RegisterRPCService(context.Background(), rpcServer, cameraSvc), test.ShouldBeNil)
+	test.That(t, oldServer.RegisterServiceServer(
+		context.Background(),
+		resourceSubtype.RPCServiceDesc,
+		camera.NewRPCServiceServer(cameraSvc),
+	), test.ShouldBeNil)
+
+	go rpcServer.Serve(listener1)
+	defer rpcServer.Stop()
+	go oldServer.Serve(listener2)
+	defer oldServer.Stop()
+
+	for _, listener := range []net.Listener{listener1, listener2} {
+		conn, err := viamgrpc.Dial(context.Background(), listener.Addr().String(), logger)
+		test.That(t, err, test.ShouldBeNil)
+		client, err := camera.NewClientFromConn(context.Background(), conn, camera.Named(testCameraName), logger)
+		test.That(t, err, test.ShouldBeNil)
+
+		ctx := gostream.WithMIMETypeHint(context.Background(), rutils.MimeTypePCD)
+		stream, err := camera.StreamPointClouds(ctx, client, camera.PointCloudStreamOptions{MaxRate: 100})
+		test.That(t, err, test.ShouldBeNil)
+		for i := 0; i < 3; i++ {
+			next, err := stream.Next(context.Background())
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, next.PointCloud.Size(), test.ShouldEqual, 1)
+			_, got := next.PointCloud.At(5, 5, 5)
+			test.That(t, got, test.ShouldBeTrue)
+			test.That(t, next.CapturedAt, test.ShouldNotBeZeroValue)
+		}
+		test.That(t, stream.Close(context.Background()), test.ShouldBeNil)
+
+		// the client keeps to the max rate by itself, even when it polls a server that cannot stream
+		streamer, ok := client.(camera.PointCloudStreamer)
+		test.That(t, ok, test.ShouldBeTrue)
+		stream, err = streamer.StreamPointClouds(ctx, camera.PointCloudStreamOptions{MaxRate: 20})
+		test.That(t, err, test.ShouldBeNil)
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := stream.Next(context.Background())
+			test.That(t, err, test.ShouldBeNil)
+		}
+		test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 100*time.Millisecond)
+		test.That(t, stream.Close(context.Background()), test.ShouldBeNil)
+
+		client2, err := camera.NewClientFromConn(context.Background(), conn, camera.Named(failCameraName), logger)
+		test.That(t, err, test.ShouldBeNil)
+		stream, err = camera.StreamPointClouds(context.Background(), client2, camera.PointCloudStreamOptions{})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = stream.Next(context.Background())
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, err.Error(), test.ShouldContainSubstring, "can't generate next point cloud")
+		test.That(t, stream.Close(context.Background()), test.ShouldBeNil)
+
+		test.That(t, client.Close(context.Background()), test.ShouldBeNil)
+		test.That(t, client2.Close(context.Background()), test.ShouldBeNil)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"image"
 	"image/color"
 	"math"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
@@ -165,12 +168,17 @@ type Camera struct {
 	Model           *transform.PinholeCameraModel
 	Width           int
 	Height          int
+	mu              sync.Mutex
 	cacheImage      *image.RGBA
+	cacheDepth      *rimage.DepthMap
 	cachePointCloud pointcloud.PointCloud
+	frameID         atomic.Uint64
 }
 
 // Read always returns the same image of a yellow to blue gradient.
 func (c *Camera) Read(ctx context.Context) (image.Image, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.cacheImage != nil {
 		return c.cacheImage, func() {}, nil
 	}
@@ -193,8 +201,44 @@ func (c *Camera) Read(ctx context.Context) (image.Image, func(), error) {
 	return rimage.ConvertImage(img), func() {}, nil
 }
 
+// Images always returns the same color image as Read along with a depth map of the same gradient, with the depth
+// determined by the intensity of blue.
+func (c *Camera) Images(ctx context.Context) ([]camera.NamedImage, error) {
+	img, _, err := c.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cacheDepth == nil {
+		width := float64(c.Width)
+		height := float64(c.Height)
+		dm := rimage.NewEmptyDepthMap(c.Width, c.Height)
+
+		totalDist := math.Sqrt(math.Pow(0-width, 2) + math.Pow(0-height, 2))
+
+		var x, y float64
+		for x = 0; x < width; x++ {
+			for y = 0; y < height; y++ {
+				dist := math.Sqrt(math.Pow(0-x, 2) + math.Pow(0-y, 2))
+				dist /= totalDist
+				dm.Set(int(x), int(y), rimage.Depth(255*dist))
+			}
+		}
+		c.cacheDepth = dm
+	}
+	capturedAt := time.Now()
+	frameID := c.frameID.Add(1)
+	return []camera.NamedImage{
+		{Image: img, SourceName: string(camera.ColorStream), CapturedAt: capturedAt, FrameID: frameID},
+		{Image: c.cacheDepth, SourceName: string(camera.DepthStream), CapturedAt: capturedAt, FrameID: frameID},
+	}, nil
+}
+
 // NextPointCloud always returns a pointcloud of a yellow to blue gradient, with the depth determined by the intensity of blue.
 func (c *Camera) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if c.cachePointCloud != nil {
 		return c.cachePointCloud, nil
 	}
@@ -7,6 +7,7 @@ import (
 	"go.viam.com/test"
 
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/transform"
 )
 
@@ -96,3 +97,31 @@ func cameraTest(
 	err = cam.Close(context.Background())
 	test.That(t, err, test.ShouldBeNil)
 }
+
+func TestFakeCameraImages(t *testing.T) {
+	model, width, height := fakeModel(320, 0)
+	camOri := &Camera{Named: camera.Named("test_images").AsNamed(), Model: model, Width: width, Height: height}
+	src, err := camera.NewVideoSourceFromReader(context.Background(), camOri, model, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, ok := src.(camera.ImagesSource)
+	test.That(t, ok, test.ShouldBeTrue)
+	imgs, err := camera.Images(context.Background(), src)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imgs, test.ShouldHaveLength, 2)
+	col, ok := camera.FindNamedImage(imgs, string(camera.ColorStream))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, col.Image.Bounds().Dx(), test.ShouldEqual, 320)
+	dm, ok := camera.FindNamedImage(imgs, string(camera.DepthStream))
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, dm.Image, test.ShouldHaveSameTypeAs, &rimage.DepthMap{})
+	test.That(t, dm.Image.Bounds().Dy(), test.ShouldEqual, 180)
+	test.That(t, dm.CapturedAt, test.ShouldEqual, col.CapturedAt)
+	test.That(t, dm.FrameID, test.ShouldEqual, col.FrameID)
+
+	// each set of images is a new frame
+	next, err := camera.Images(context.Background(), src)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, next[0].FrameID, test.ShouldEqual, col.FrameID+1)
+	test.That(t, next[0].CapturedAt.Before(col.CapturedAt), test.ShouldBeFalse)
+}
@@ -0,0 +1,252 @@
+package camera
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/pointcloud"
+)
+
+// A StreamedPointCloud is a point cloud returned by a PointCloudStream.
+type StreamedPointCloud struct {
+	PointCloud pointcloud.PointCloud
+	// CapturedAt is when the point cloud was captured.
+	CapturedAt time.Time
+	// Dropped is how many point clouds of the camera were dropped before this one, because they were produced
+	// while the consumer of the stream was not ready for another one.
+	Dropped uint64
+}
+
+// A PointCloudStream returns the point clouds of a camera as it produces them.
+type PointCloudStream interface {
+	// Next returns the next point cloud of the stream, waiting for the camera to produce it if needed. Of the point
+	// clouds produced since the last call, only the newest is returned, so that slow consumers never fall behind.
+	// Next must not be called concurrently.
+	Next(ctx context.Context) (StreamedPointCloud, error)
+	Close(ctx context.Context) error
+}
+
+// PointCloudStreamOptions are the options of a PointCloudStream.
+type PointCloudStreamOptions struct {
+	// MaxRate is the most point clouds per second that the stream returns; there is no limit if it is zero.
+	MaxRate float64
+}
+
+// A PointCloudStreamer is a camera that can stream its point clouds, because it knows when it produces them,
+// like a lidar that finishes a rotation, or because they come from another robot. Readers given to
+// NewVideoSourceFromReader that implement it are used for streaming. Use StreamPointClouds to stream from any camera.
+type PointCloudStreamer interface {
+	StreamPointClouds(ctx context.Context, opts PointCloudStreamOptions) (PointCloudStream, error)
+}
+
+// StreamPointClouds returns a stream of the point clouds of the source, so that consumers like SLAM, segmentation
+// and obstacle detection can process each one as it is produced rather than polling for them. Sources that are
+// PointCloudStreamers stream their own; otherwise, NextPointCloud is called whenever the consumer is ready for
+// another point cloud, which for depth cameras means their next frame.
+func StreamPointClouds(ctx context.Context, src PointCloudSource, opts PointCloudStreamOptions) (PointCloudStream, error) {
+	if opts.MaxRate < 0 {
+		return nil, errors.Errorf("max rate of point cloud stream must not be negative, got %v", opts.MaxRate)
+	}
+	stream, err := streamPointClouds(ctx, src, opts)
+	if err != nil {
+		return nil, err
+	}
+	return rateLimitPointCloudStream(stream, opts.MaxRate), nil
+}
+
+// streamPointClouds is StreamPointClouds without a rate limit, for cameras that wrap other sources to stream them.
+func streamPointClouds(ctx context.Context, src PointCloudSource, opts PointCloudStreamOptions) (PointCloudStream, error) {
+	if streamer, ok := src.(PointCloudStreamer); ok {
+		return streamer.StreamPointClouds(ctx, opts)
+	}
+	return &polledPointCloudStream{src: src}, nil
+}
+
+// A polledPointCloudStream calls NextPointCloud of its source for each point cloud.
+type polledPointCloudStream struct {
+	src PointCloudSource
+}
+
+func (s *polledPointCloudStream) Next(ctx context.Context) (StreamedPointCloud, error) {
+	pc, err := s.src.NextPointCloud(ctx)
+	if err != nil {
+		return StreamedPointCloud{}, err
+	}
+	return StreamedPointCloud{PointCloud: pc, CapturedAt: time.Now()}, nil
+}
+
+func (s *polledPointCloudStream) Close(ctx context.Context) error {
+	return nil
+}
+
+// rateLimitPointCloudStream returns the stream limited to the max rate, or the stream itself if it is zero.
+func rateLimitPointCloudStream(stream PointCloudStream, maxRate float64) PointCloudStream {
+	if maxRate == 0 {
+		return stream
+	}
+	return &rateLimitedPointCloudStream{
+		PointCloudStream: stream,
+		interval:         time.Duration(float64(time.Second) / maxRate),
+	}
+}
+
+// A rateLimitedPointCloudStream waits between point clouds of its stream so that it returns at most one per interval.
+type rateLimitedPointCloudStream struct {
+	PointCloudStream
+	interval time.Duration
+	last     time.Time
+}
+
+func (s *rateLimitedPointCloudStream) Next(ctx context.Context) (StreamedPointCloud, error) {
+	if wait := time.Until(s.last.Add(s.interval)); wait > 0 {
+		if !goutils.SelectContextOrWait(ctx, wait) {
+			return StreamedPointCloud{}, ctx.Err()
+		}
+	}
+	s.last = time.Now()
+	return s.PointCloudStream.Next(ctx)
+}
+
+var errPointCloudStreamClosed = errors.New("point cloud stream closed")
+
+// A PointCloudPublisher streams the point clouds of a camera that produces them on its own to every open stream
+// of them. Cameras Publish each point cloud as they produce it and implement PointCloudStreamer with its
+// StreamPointClouds.
+type PointCloudPublisher struct {
+	mu      sync.Mutex
+	streams map[*publishedPointCloudStream]struct{}
+	closed  bool
+}
+
+// NewPointCloudPublisher returns a publisher with no open streams.
+func NewPointCloudPublisher() *PointCloudPublisher {
+	return &PointCloudPublisher{streams: map[*publishedPointCloudStream]struct{}{}}
+}
+
+// HasStreams returns whether any streams are open, so that cameras can skip producing point clouds that no one
+// would get.
+func (p *PointCloudPublisher) HasStreams() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.streams) != 0
+}
+
+// Publish sends the point cloud to every open stream. Streams whose consumer has not gotten the last point cloud
+// published drop it for this one.
+func (p *PointCloudPublisher) Publish(pc pointcloud.PointCloud, capturedAt time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for s := range p.streams {
+		s.publish(StreamedPointCloud{PointCloud: pc, CapturedAt: capturedAt})
+	}
+}
+
+// StreamPointClouds opens a stream of the point clouds published from now on. The options are left to
+// StreamPointClouds of the camera package.
+func (p *PointCloudPublisher) StreamPointClouds(ctx context.Context, opts PointCloudStreamOptions) (PointCloudStream, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil, errPointCloudStreamClosed
+	}
+	s := &publishedPointCloudStream{publisher: p, ready: make(chan struct{}, 1)}
+	p.streams[s] = struct{}{}
+	return s, nil
+}
+
+// Close closes every open stream, whose Next then returns an error, and stops new ones from opening.
+func (p *PointCloudPublisher) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for s := range p.streams {
+		s.close()
+		delete(p.streams, s)
+	}
+}
+
+// A publishedPointCloudStream holds the newest point cloud published that its consumer has not gotten yet.
+type publishedPointCloudStream struct {
+	publisher *PointCloudPublisher
+	ready     chan struct{}
+
+	mu      sync.Mutex
+	latest  *StreamedPointCloud
+	dropped uint64
+	closed  bool
+}
+
+func (s *publishedPointCloudStream) publish(pc StreamedPointCloud) {
+	s.mu.Lock()
+	if s.latest != nil {
+		s.dropped++
+	}
+	s.latest = &pc
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *publishedPointCloudStream) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *publishedPointCloudStream) notify() {
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (s *publishedPointCloudStream) Next(ctx context.Context) (StreamedPointCloud, error) {
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return StreamedPointCloud{}, errPointCloudStreamClosed
+		}
+		if s.latest != nil {
+			pc := *s.latest
+			pc.Dropped = s.dropped
+			s.latest = nil
+			s.dropped = 0
+			s.mu.Unlock()
+			return pc, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return StreamedPointCloud{}, ctx.Err()
+		case <-s.ready:
+		}
+	}
+}
+
+func (s *publishedPointCloudStream) Close(ctx context.Context) error {
+	s.publisher.mu.Lock()
+	delete(s.publisher.streams, s)
+	s.publisher.mu.Unlock()
+	s.close()
+	return nil
+}
+
+// StreamPointClouds streams the point clouds of the underlying source if it is a PointCloudStreamer, or else
+// calls NextPointCloud for each one.
+func (vs *videoSource) StreamPointClouds(ctx context.Context, opts PointCloudStreamOptions) (PointCloudStream, error) {
+	if streamer, ok := vs.actualSource.(PointCloudStreamer); ok {
+		return streamer.StreamPointClouds(ctx, opts)
+	}
+	return &polledPointCloudStream{src: vs}, nil
+}
+
+// StreamPointClouds streams the point clouds of the video source.
+func (c *sourceBasedCamera) StreamPointClouds(ctx context.Context, opts PointCloudStreamOptions) (PointCloudStream, error) {
+	return streamPointClouds(ctx, c.VideoSource, opts)
+}
@@ -0,0 +1,151 @@
+package camera_test
+
+import (
+	"context"
+	"errors"
+	"image"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestPointCloudPublisher(t *testing.T) {
+	publisher := camera.NewPointCloudPublisher()
+	test.That(t, publisher.HasStreams(), test.ShouldBeFalse)
+
+	// point clouds published before a stream opens are not sent to it
+	publisher.Publish(pointcloud.New(), time.Now())
+
+	stream1, err := publisher.StreamPointClouds(context.Background(), camera.PointCloudStreamOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	stream2, err := publisher.StreamPointClouds(context.Background(), camera.PointCloudStreamOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, publisher.HasStreams(), test.ShouldBeTrue)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	_, err = stream1.Next(ctx)
+	cancel()
+	test.That(t, err, test.ShouldBeError, context.DeadlineExceeded)
+
+	pcs := make([]pointcloud.PointCloud, 3)
+	capturedAt := time.Now()
+	for i := range pcs {
+		pcs[i] = pointcloud.New()
+		test.That(t, pcs[i].Set(pointcloud.NewVector(float64(i), 0, 0), nil), test.ShouldBeNil)
+		publisher.Publish(pcs[i], capturedAt.Add(time.Duration(i)*time.Second))
+	}
+
+	// the newest point cloud wins and the others are counted as dropped
+	for _, stream := range []camera.PointCloudStream{stream1, stream2} {
+		next, err := stream.Next(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next.PointCloud, test.ShouldEqual, pcs[2])
+		test.That(t, next.CapturedAt, test.ShouldEqual, capturedAt.Add(2*time.Second))
+		test.That(t, next.Dropped, test.ShouldEqual, 2)
+	}
+
+	// a consumer waiting for the next point cloud gets it as soon as it is published
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		time.Sleep(10 * time.Millisecond)
+		publisher.Publish(pcs[0], capturedAt)
+	}()
+	next, err := stream1.Next(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, next.PointCloud, test.ShouldEqual, pcs[0])
+	test.That(t, next.Dropped, test.ShouldEqual, 0)
+	<-published
+
+	test.That(t, stream1.Close(context.Background()), test.ShouldBeNil)
+	_, err = stream1.Next(context.Background())
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, publisher.HasStreams(), test.ShouldBeTrue)
+
+	publisher.Close()
+	test.That(t, publisher.HasStreams(), test.ShouldBeFalse)
+	_, err = stream2.Next(context.Background())
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = publisher.StreamPointClouds(context.Background(), camera.PointCloudStreamOptions{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStreamPointCloudsPolled(t *testing.T) {
+	pc := pointcloud.New()
+	calls := 0
+	cam := &inject.Camera{}
+	cam.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+		calls++
+		if calls > 3 {
+			return nil, errors.New("no more point clouds")
+		}
+		return pc, nil
+	}
+
+	_, err := camera.StreamPointClouds(context.Background(), cam, camera.PointCloudStreamOptions{MaxRate: -1})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	stream, err := camera.StreamPointClouds(context.Background(), cam, camera.PointCloudStreamOptions{MaxRate: 20})
+	test.That(t, err, test.ShouldBeNil)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		next, err := stream.Next(context.Background())
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next.PointCloud, test.ShouldEqual, pc)
+	}
+	// the first point cloud is not delayed, the other two are 50ms apart
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 100*time.Millisecond)
+	_, err = stream.Next(context.Background())
+	test.That(t, err, test.ShouldBeError, errors.New("no more point clouds"))
+
+	// waiting for the rate limit stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = stream.Next(ctx)
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+	test.That(t, calls, test.ShouldEqual, 4)
+	test.That(t, stream.Close(context.Background()), test.ShouldBeNil)
+}
+
+type streamingReader struct {
+	*camera.PointCloudPublisher
+}
+
+func (r streamingReader) Read(ctx context.Context) (image.Image, func(), error) {
+	return image.NewGray(image.Rect(0, 0, 1, 1)), func() {}, nil
+}
+
+func (r streamingReader) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	return pointcloud.New(), nil
+}
+
+func (r streamingReader) Close(ctx context.Context) error {
+	return nil
+}
+
+func TestStreamPointCloudsFromVideoSource(t *testing.T) {
+	reader := streamingReader{camera.NewPointCloudPublisher()}
+	src, err := camera.NewVideoSourceFromReader(context.Background(), reader, nil, camera.DepthStream)
+	test.That(t, err, test.ShouldBeNil)
+	cam := camera.FromVideoSource(camera.Named("lidar"), src)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	// cameras whose readers stream point clouds stream those
+	stream, err := camera.StreamPointClouds(context.Background(), cam, camera.PointCloudStreamOptions{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reader.HasStreams(), test.ShouldBeTrue)
+	pc := pointcloud.New()
+	reader.Publish(pc, time.Now())
+	next, err := stream.Next(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, next.PointCloud, test.ShouldEqual, pc)
+	test.That(t, stream.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, reader.HasStreams(), test.ShouldBeFalse)
+}
@@ -0,0 +1,139 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: v1/pointcloud_stream.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// PointCloudStreamServiceClient is the client API for PointCloudStreamService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PointCloudStreamServiceClient interface {
+	// StreamPointClouds streams the point clouds of a camera until the client cancels. Point clouds
+	// that the camera produces while the client is not ready for another are dropped in favor of the
+	// newest one.
+	StreamPointClouds(ctx context.Context, in *StreamPointCloudsRequest, opts ...grpc.CallOption) (PointCloudStreamService_StreamPointCloudsClient, error)
+}
+
+type pointCloudStreamServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPointCloudStreamServiceClient(cc grpc.ClientConnInterface) PointCloudStreamServiceClient {
+	return &pointCloudStreamServiceClient{cc}
+}
+
+func (c *pointCloudStreamServiceClient) StreamPointClouds(ctx context.Context, in *StreamPointCloudsRequest, opts ...grpc.CallOption) (PointCloudStreamService_StreamPointCloudsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PointCloudStreamService_ServiceDesc.Streams[0], "/viam.component.camera.stream.v1.PointCloudStreamService/StreamPointClouds", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pointCloudStreamServiceStreamPointCloudsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PointCloudStreamService_StreamPointCloudsClient interface {
+	Recv() (*StreamPointCloudsResponse, error)
+	grpc.ClientStream
+}
+
+type pointCloudStreamServiceStreamPointCloudsClient struct {
+	grpc.ClientStream
+}
+
+func (x *pointCloudStreamServiceStreamPointCloudsClient) Recv() (*StreamPointCloudsResponse, error) {
+	m := new(StreamPointCloudsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PointCloudStreamServiceServer is the server API for PointCloudStreamService service.
+// All implementations must embed UnimplementedPointCloudStreamServiceServer
+// for forward compatibility
+type PointCloudStreamServiceServer interface {
+	// StreamPointClouds streams the point clouds of a camera until the client cancels. Point clouds
+	// that the camera produces while the client is not ready for another are dropped in favor of the
+	// newest one.
+	StreamPointClouds(*StreamPointCloudsRequest, PointCloudStreamService_StreamPointCloudsServer) error
+	mustEmbedUnimplementedPointCloudStreamServiceServer()
+}
+
+// UnimplementedPointCloudStreamServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPointCloudStreamServiceServer struct {
+}
+
+func (UnimplementedPointCloudStreamServiceServer) StreamPointClouds(*StreamPointCloudsRequest, PointCloudStreamService_StreamPointCloudsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPointClouds not implemented")
+}
+func (UnimplementedPointCloudStreamServiceServer) mustEmbedUnimplementedPointCloudStreamServiceServer() {
+}
+
+// UnsafePointCloudStreamServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PointCloudStreamServiceServer will
+// result in compilation errors.
+type UnsafePointCloudStreamServiceServer interface {
+	mustEmbedUnimplementedPointCloudStreamServiceServer()
+}
+
+func RegisterPointCloudStreamServiceServer(s grpc.ServiceRegistrar, srv PointCloudStreamServiceServer) {
+	s.RegisterService(&PointCloudStreamService_ServiceDesc, srv)
+}
+
+func _PointCloudStreamService_StreamPointClouds_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamPointCloudsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PointCloudStreamServiceServer).StreamPointClouds(m, &pointCloudStreamServiceStreamPointCloudsServer{stream})
+}
+
+type PointCloudStreamService_StreamPointCloudsServer interface {
+	Send(*StreamPointCloudsResponse) error
+	grpc.ServerStream
+}
+
+type pointCloudStreamServiceStreamPointCloudsServer struct {
+	grpc.ServerStream
+}
+
+func (x *pointCloudStreamServiceStreamPointCloudsServer) Send(m *StreamPointCloudsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PointCloudStreamService_ServiceDesc is the grpc.ServiceDesc for PointCloudStreamService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PointCloudStreamService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "viam.component.camera.stream.v1.PointCloudStreamService",
+	HandlerType: (*PointCloudStreamServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPointClouds",
+			Handler:       _PointCloudStreamService_StreamPointClouds_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "v1/pointcloud_stream.proto",
+}
@@ -9,5 +9,6 @@ import (
 	_ "go.viam.com/rdk/components/camera/rtsp"
 	_ "go.viam.com/rdk/components/camera/transformpipeline"
 	_ "go.viam.com/rdk/components/camera/velodyne"
+	_ "go.viam.com/rdk/components/camera/videorecorder"
 	_ "go.viam.com/rdk/components/camera/videosource"
 )
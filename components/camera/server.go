@@ -9,8 +9,11 @@ import (
 	"go.opencensus.io/trace"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/component/camera/v1"
+	goutils "go.viam.com/utils"
 	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	streampb "go.viam.com/rdk/components/camera/proto/v1"
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
@@ -132,15 +135,15 @@ func (s *subtypeServer) GetPointCloud(
 
 	var buf bytes.Buffer
 	buf.Grow(200 + (pc.Size() * 4 * 4)) // 4 numbers per point, each 4 bytes
-	_, pcdSpan := trace.StartSpan(ctx, "camera::server::NextPointCloud::ToPCD")
-	err = pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary)
-	pcdSpan.End()
+	_, encodeSpan := trace.StartSpan(ctx, "camera::server::NextPointCloud::Encode")
+	mimeType, err := pointcloud.Encode(pc, &buf, req.MimeType)
+	encodeSpan.End()
 	if err != nil {
 		return nil, err
 	}
 
 	return &pb.GetPointCloudResponse{
-		MimeType:   utils.MimeTypePCD,
+		MimeType:   mimeType,
 		PointCloud: buf.Bytes(),
 	}, nil
 }
@@ -189,3 +192,57 @@ func (s *subtypeServer) DoCommand(ctx context.Context,
 	}
 	return protoutils.DoFromResourceServer(ctx, camera, req)
 }
+
+// streamServer implements the PointCloudStreamService from pointcloud_stream.proto.
+type streamServer struct {
+	streampb.UnimplementedPointCloudStreamServiceServer
+	coll resource.SubtypeCollection[Camera]
+}
+
+// NewPointCloudStreamServiceServer constructs a camera point cloud streaming gRPC service server.
+// It is intentionally untyped to prevent use outside of tests.
+func NewPointCloudStreamServiceServer(coll resource.SubtypeCollection[Camera]) interface{} {
+	return &streamServer{coll: coll}
+}
+
+// StreamPointClouds streams the point clouds of a camera of the underlying robot, at most max_rate_hz of them
+// per second if set. A point cloud is only taken from the camera once the last one has been sent, so a client
+// that is slow to receive gets the newest point clouds rather than falling further and further behind.
+func (s *streamServer) StreamPointClouds(
+	req *streampb.StreamPointCloudsRequest,
+	stream streampb.PointCloudStreamService_StreamPointCloudsServer,
+) error {
+	ctx := stream.Context()
+	cam, err := s.coll.Resource(req.Name)
+	if err != nil {
+		return err
+	}
+	pcs, err := StreamPointClouds(ctx, cam, PointCloudStreamOptions{MaxRate: req.MaxRateHz})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		goutils.UncheckedError(pcs.Close(ctx))
+	}()
+
+	var buf bytes.Buffer
+	for {
+		next, err := pcs.Next(ctx)
+		if err != nil {
+			return err
+		}
+		buf.Reset()
+		mimeType, err := pointcloud.Encode(next.PointCloud, &buf, req.MimeType)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&streampb.StreamPointCloudsResponse{
+			MimeType:   mimeType,
+			PointCloud: buf.Bytes(),
+			CapturedAt: timestamppb.New(next.CapturedAt),
+			Dropped:    next.Dropped,
+		}); err != nil {
+			return err
+		}
+	}
+}
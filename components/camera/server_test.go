@@ -352,10 +352,30 @@ func TestServer(t *testing.T) {
 		injectCamera.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
 			return pcA, nil
 		}
-		_, err = cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
+		resp, err := cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
 			Name: testCameraName,
 		})
 		test.That(t, err, test.ShouldBeNil)
+		test.That(t, resp.MimeType, test.ShouldEqual, utils.MimeTypePCD)
+
+		// the point cloud is encoded as requested
+		for _, mimeType := range []string{utils.MimeTypePLY, utils.MimeTypeCompactPointCloud, pointcloud.CompactMIMEType(1)} {
+			resp, err = cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
+				Name:     testCameraName,
+				MimeType: mimeType,
+			})
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, resp.MimeType, test.ShouldEqual, mimeType)
+			pc, err := pointcloud.Decode(resp.PointCloud, resp.MimeType)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, pointcloud.CloudContains(pc, 5, 5, 5), test.ShouldBeTrue)
+		}
+
+		_, err = cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
+			Name:     testCameraName,
+			MimeType: utils.MimeTypeCompactPointCloud + "; resolution_mm=-1",
+		})
+		test.That(t, err, test.ShouldNotBeNil)
 
 		_, err = cameraServer.GetPointCloud(context.Background(), &pb.GetPointCloudRequest{
 			Name: failCameraName,
@@ -0,0 +1,326 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/utils"
+)
+
+// colorSpace is the color space that the color_space transform converts images to.
+type colorSpace string
+
+const (
+	colorSpaceGrayscale = colorSpace("grayscale")
+	colorSpaceHSV       = colorSpace("hsv")
+)
+
+// colorSpaceConfig are the attributes for a color_space transform.
+type colorSpaceConfig struct {
+	ColorSpace colorSpace `json:"color_space" jsonschema:"enum=grayscale,enum=hsv"`
+}
+
+type colorSpaceSource struct {
+	originalStream gostream.VideoStream
+	colorSpace     colorSpace
+}
+
+// newColorSpaceTransform creates a new transform that converts color images to grayscale or HSV.
+// HSV images are encoded in the RGB channels of the output, with the hue scaled from [0, 360) to [0, 255].
+func newColorSpaceTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream == camera.DepthStream {
+		return nil, camera.UnspecifiedStream,
+			errors.Errorf("source has stream type %s, color_space only supports color stream inputs", stream)
+	}
+	conf, err := resource.TransformAttributeMap[*colorSpaceConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	switch conf.ColorSpace {
+	case colorSpaceGrayscale, colorSpaceHSV:
+	default:
+		return nil, camera.UnspecifiedStream, errors.Errorf("do not know color space %q", conf.ColorSpace)
+	}
+	cameraModel, err := cameraModelFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	reader := &colorSpaceSource{gostream.NewEmbeddedVideoStream(source), conf.ColorSpace}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, camera.ColorStream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, camera.ColorStream, err
+}
+
+// Read converts the color image to the configured color space.
+func (cs *colorSpaceSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::color_space::Read")
+	defer span.End()
+	orig, release, err := cs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := orig.Bounds()
+	switch cs.colorSpace {
+	case colorSpaceGrayscale:
+		gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		for y := 0; y < bounds.Dy(); y++ {
+			for x := 0; x < bounds.Dx(); x++ {
+				gray.SetGray(x, y, color.GrayModel.Convert(orig.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray))
+			}
+		}
+		return gray, release, nil
+	case colorSpaceHSV:
+		img := rimage.ConvertImage(orig)
+		hsv := image.NewNRGBA(image.Rect(0, 0, img.Width(), img.Height()))
+		for y := 0; y < img.Height(); y++ {
+			for x := 0; x < img.Width(); x++ {
+				h, s, v := img.GetXY(x, y).HsvNormal()
+				hsv.SetNRGBA(x, y, color.NRGBA{
+					R: uint8(math.Round(h * 255 / 360)),
+					G: uint8(math.Round(s * 255)),
+					B: uint8(math.Round(v * 255)),
+					A: 255,
+				})
+			}
+		}
+		return hsv, release, nil
+	default:
+		return nil, nil, errors.Errorf("do not know color space %q", cs.colorSpace)
+	}
+}
+
+// Close closes the original stream.
+func (cs *colorSpaceSource) Close(ctx context.Context) error {
+	return cs.originalStream.Close(ctx)
+}
+
+// equalizeMethod is the histogram equalization method used by the equalize transform.
+type equalizeMethod string
+
+const (
+	equalizeGlobal = equalizeMethod("global")
+	equalizeCLAHE  = equalizeMethod("clahe")
+
+	defaultCLAHETileGridSize = 8
+	defaultCLAHEClipLimit    = 2.0
+)
+
+// equalizeConfig are the attributes for an equalize transform.
+type equalizeConfig struct {
+	Method       equalizeMethod `json:"method,omitempty" jsonschema:"enum=global,enum=clahe,default=global"`
+	TileGridSize int            `json:"tile_grid_size,omitempty"`
+	ClipLimit    float64        `json:"clip_limit,omitempty"`
+}
+
+type equalizeSource struct {
+	originalStream gostream.VideoStream
+	method         equalizeMethod
+	tileGridSize   int
+	clipLimit      float64
+}
+
+// newEqualizeTransform creates a new transform that equalizes the histogram of the luminance of color images,
+// either over the whole image, or with contrast limited adaptive histogram equalization (CLAHE) over a grid of tiles.
+func newEqualizeTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream == camera.DepthStream {
+		return nil, camera.UnspecifiedStream,
+			errors.Errorf("source has stream type %s, equalize only supports color stream inputs", stream)
+	}
+	conf, err := resource.TransformAttributeMap[*equalizeConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	reader := &equalizeSource{
+		originalStream: gostream.NewEmbeddedVideoStream(source),
+		method:         conf.Method,
+		tileGridSize:   conf.TileGridSize,
+		clipLimit:      conf.ClipLimit,
+	}
+	switch conf.Method {
+	case "":
+		reader.method = equalizeGlobal
+	case equalizeGlobal, equalizeCLAHE:
+	default:
+		return nil, camera.UnspecifiedStream, errors.Errorf("do not know equalize method %q", conf.Method)
+	}
+	if conf.TileGridSize < 0 || conf.ClipLimit < 0 {
+		return nil, camera.UnspecifiedStream, errors.New("tile_grid_size and clip_limit of equalize transform cannot be negative")
+	}
+	if reader.tileGridSize == 0 {
+		reader.tileGridSize = defaultCLAHETileGridSize
+	}
+	if reader.clipLimit == 0 {
+		reader.clipLimit = defaultCLAHEClipLimit
+	}
+	cameraModel, err := cameraModelFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, camera.ColorStream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, camera.ColorStream, err
+}
+
+// Read equalizes the luminance of the color image, keeping its chrominance.
+func (es *equalizeSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::equalize::Read")
+	defer span.End()
+	orig, release, err := es.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := orig.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	lum := make([]uint8, width*height)
+	cb := make([]uint8, width*height)
+	cr := make([]uint8, width*height)
+	alpha := make([]uint8, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.NRGBAModel.Convert(orig.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA)
+			i := y*width + x
+			lum[i], cb[i], cr[i] = color.RGBToYCbCr(c.R, c.G, c.B)
+			alpha[i] = c.A
+		}
+	}
+
+	if es.method == equalizeCLAHE {
+		lum = equalizeAdaptive(lum, width, height, es.tileGridSize, es.clipLimit)
+	} else {
+		lum = equalizeGlobally(lum)
+	}
+
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			r, g, b := color.YCbCrToRGB(lum[i], cb[i], cr[i])
+			out.SetNRGBA(x, y, color.NRGBA{R: r, G: g, B: b, A: alpha[i]})
+		}
+	}
+	return out, release, nil
+}
+
+// Close closes the original stream.
+func (es *equalizeSource) Close(ctx context.Context) error {
+	return es.originalStream.Close(ctx)
+}
+
+// equalizeGlobally spreads the histogram of the values over the full range of [0, 255].
+func equalizeGlobally(values []uint8) []uint8 {
+	var hist [256]float64
+	for _, v := range values {
+		hist[v]++
+	}
+	lut := equalizationLUT(hist)
+	out := make([]uint8, len(values))
+	for i, v := range values {
+		out[i] = lut[v]
+	}
+	return out
+}
+
+// equalizeAdaptive equalizes each tile of a tileGridSize x tileGridSize grid separately, after clipping each bin of
+// the tile histograms to clipLimit times the average bin count. The value of each pixel is bilinearly interpolated
+// between the mappings of the four nearest tiles, so that the tile boundaries are not visible.
+func equalizeAdaptive(values []uint8, width, height, tileGridSize int, clipLimit float64) []uint8 {
+	tilesX, tilesY := utils.MinInt(tileGridSize, width), utils.MinInt(tileGridSize, height)
+	if tilesX == 0 || tilesY == 0 {
+		return values
+	}
+	tileWidth := int(math.Ceil(float64(width) / float64(tilesX)))
+	tileHeight := int(math.Ceil(float64(height) / float64(tilesY)))
+
+	luts := make([][256]uint8, tilesX*tilesY)
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			var hist [256]float64
+			count := 0.
+			for y := ty * tileHeight; y < utils.MinInt((ty+1)*tileHeight, height); y++ {
+				for x := tx * tileWidth; x < utils.MinInt((tx+1)*tileWidth, width); x++ {
+					hist[values[y*width+x]]++
+					count++
+				}
+			}
+			limit := math.Max(1, clipLimit*count/256)
+			excess := 0.
+			for v, n := range hist {
+				if n > limit {
+					excess += n - limit
+					hist[v] = limit
+				}
+			}
+			for v := range hist {
+				hist[v] += excess / 256
+			}
+			luts[ty*tilesX+tx] = equalizationLUT(hist)
+		}
+	}
+
+	// tileCoord returns the two nearest tiles to the pixel along an axis, and the weight of the second one
+	tileCoord := func(p, tileSize, tiles int) (int, int, float64) {
+		f := (float64(p)+0.5)/float64(tileSize) - 0.5
+		t0 := int(math.Floor(f))
+		w := f - float64(t0)
+		if t0 < 0 {
+			return 0, 0, 0
+		}
+		if t0 >= tiles-1 {
+			return tiles - 1, tiles - 1, 0
+		}
+		return t0, t0 + 1, w
+	}
+	out := make([]uint8, len(values))
+	for y := 0; y < height; y++ {
+		ty0, ty1, wy := tileCoord(y, tileHeight, tilesY)
+		for x := 0; x < width; x++ {
+			tx0, tx1, wx := tileCoord(x, tileWidth, tilesX)
+			v := values[y*width+x]
+			top := (1-wx)*float64(luts[ty0*tilesX+tx0][v]) + wx*float64(luts[ty0*tilesX+tx1][v])
+			bottom := (1-wx)*float64(luts[ty1*tilesX+tx0][v]) + wx*float64(luts[ty1*tilesX+tx1][v])
+			out[y*width+x] = uint8(math.Round((1-wy)*top + wy*bottom))
+		}
+	}
+	return out
+}
+
+// equalizationLUT returns the mapping of values that equalizes the given histogram.
+func equalizationLUT(hist [256]float64) [256]uint8 {
+	var lut [256]uint8
+	total, cdfMin := 0., 0.
+	for _, n := range hist {
+		if cdfMin == 0 && n > 0 {
+			cdfMin = n
+		}
+		total += n
+	}
+	if total <= cdfMin {
+		for v := range lut {
+			lut[v] = uint8(v)
+		}
+		return lut
+	}
+	cdf := 0.
+	for v, n := range hist {
+		cdf += n
+		lut[v] = uint8(math.Round(255 * math.Max(0, cdf-cdfMin) / (total - cdfMin)))
+	}
+	return lut
+}
@@ -0,0 +1,127 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/utils"
+)
+
+func TestColorSpace(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{G: 255, A: 255})
+	img.SetNRGBA(2, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	cam := newStaticCamera(img, testIntrinsics(), nil)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	gs, stream, err := newColorSpaceTransform(context.Background(), cam, camera.ColorStream,
+		utils.AttributeMap{"color_space": "grayscale"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	out, _, err := camera.ReadImage(context.Background(), gs)
+	test.That(t, err, test.ShouldBeNil)
+	gray, ok := out.(*image.Gray)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, gray.GrayAt(1, 0).Y, test.ShouldBeGreaterThan, gray.GrayAt(0, 0).Y)
+	test.That(t, gray.GrayAt(2, 0).Y, test.ShouldEqual, 255)
+	test.That(t, gray.GrayAt(3, 0).Y, test.ShouldEqual, 0)
+	props, err := gs.(camera.VideoSource).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams, test.ShouldResemble, testIntrinsics())
+	test.That(t, gs.Close(context.Background()), test.ShouldBeNil)
+
+	hs, _, err := newColorSpaceTransform(context.Background(), cam, camera.ColorStream,
+		utils.AttributeMap{"color_space": "hsv"})
+	test.That(t, err, test.ShouldBeNil)
+	out, _, err = camera.ReadImage(context.Background(), hs)
+	test.That(t, err, test.ShouldBeNil)
+	// hue, saturation and value of red, green and white
+	test.That(t, out.At(0, 0), test.ShouldResemble, color.NRGBA{R: 0, G: 255, B: 255, A: 255})
+	test.That(t, out.At(1, 0), test.ShouldResemble, color.NRGBA{R: 85, G: 255, B: 255, A: 255})
+	test.That(t, out.At(2, 0), test.ShouldResemble, color.NRGBA{R: 0, G: 0, B: 255, A: 255})
+	test.That(t, hs.Close(context.Background()), test.ShouldBeNil)
+
+	_, _, err = newColorSpaceTransform(context.Background(), cam, camera.ColorStream,
+		utils.AttributeMap{"color_space": "cmyk"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = newColorSpaceTransform(context.Background(), cam, camera.DepthStream,
+		utils.AttributeMap{"color_space": "grayscale"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestEqualize(t *testing.T) {
+	// a low contrast gradient
+	img := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetGray(x, y, color.Gray{uint8(100 + (x+y)/2)})
+		}
+	}
+	cam := newStaticCamera(img, nil, nil)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	contrast := func(img image.Image) (uint8, uint8) {
+		lo, hi := uint8(255), uint8(0)
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+				v := color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y
+				lo, hi = utils.MinUint8(lo, v), utils.MaxUint8(hi, v)
+			}
+		}
+		return lo, hi
+	}
+
+	es, stream, err := newEqualizeTransform(context.Background(), cam, camera.ColorStream, utils.AttributeMap{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	out, _, err := camera.ReadImage(context.Background(), es)
+	test.That(t, err, test.ShouldBeNil)
+	lo, hi := contrast(out)
+	test.That(t, lo, test.ShouldEqual, 0)
+	test.That(t, hi, test.ShouldEqual, 255)
+	test.That(t, es.Close(context.Background()), test.ShouldBeNil)
+
+	es, _, err = newEqualizeTransform(context.Background(), cam, camera.ColorStream,
+		utils.AttributeMap{"method": "clahe", "tile_grid_size": 4, "clip_limit": 3})
+	test.That(t, err, test.ShouldBeNil)
+	out, _, err = camera.ReadImage(context.Background(), es)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Bounds(), test.ShouldResemble, image.Rect(0, 0, 32, 32))
+	lo, hi = contrast(out)
+	test.That(t, int(hi)-int(lo), test.ShouldBeGreaterThan, 31)
+	// the equalized image is still a gradient
+	test.That(t, rimage.NewColorFromColor(out.At(31, 31)).Distance(rimage.NewColorFromColor(out.At(0, 0))),
+		test.ShouldBeGreaterThan, 0)
+	test.That(t, es.Close(context.Background()), test.ShouldBeNil)
+
+	_, _, err = newEqualizeTransform(context.Background(), cam, camera.ColorStream, utils.AttributeMap{"method": "local"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = newEqualizeTransform(context.Background(), cam, camera.ColorStream, utils.AttributeMap{"clip_limit": -1})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = newEqualizeTransform(context.Background(), cam, camera.DepthStream, utils.AttributeMap{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestEqualizationLUT(t *testing.T) {
+	var hist [256]float64
+	hist[10], hist[20] = 5, 5
+	lut := equalizationLUT(hist)
+	test.That(t, lut[10], test.ShouldEqual, 0)
+	test.That(t, lut[20], test.ShouldEqual, 255)
+
+	// a single value is left as is
+	hist[20] = 0
+	lut = equalizationLUT(hist)
+	test.That(t, lut[10], test.ShouldEqual, 10)
+}
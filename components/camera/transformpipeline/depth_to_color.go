@@ -0,0 +1,104 @@
+package transformpipeline
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/utils"
+)
+
+// depthToColorExtrinsics is the pose of the depth camera relative to the color camera.
+type depthToColorExtrinsics struct {
+	RotationRads  []float64 `json:"rotation_rads"`
+	TranslationMM []float64 `json:"translation_mm"`
+}
+
+// depthToColorConfig are the attributes for a depth_to_color transform, in the same format as
+// the camera_system of the join_color_depth camera.
+type depthToColorConfig struct {
+	ColorCamera *transform.PinholeCameraIntrinsics `json:"color_intrinsic_parameters"`
+	DepthCamera *transform.PinholeCameraIntrinsics `json:"depth_intrinsic_parameters"`
+	Extrinsics  *depthToColorExtrinsics            `json:"depth_to_color_extrinsic_parameters"`
+}
+
+type depthToColorSource struct {
+	originalStream gostream.VideoStream
+	cameraSystem   *transform.DepthColorIntrinsicsExtrinsics
+}
+
+// newDepthToColorTransform creates a new transform that reprojects a depth map into the frame of a color camera,
+// so that each pixel of the depth map lines up with the same pixel of the color image.
+func newDepthToColorTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	if stream != camera.DepthStream {
+		return nil, camera.UnspecifiedStream,
+			errors.Errorf("source has stream type %s, depth_to_color only supports depth stream inputs", stream)
+	}
+	conf, err := resource.TransformAttributeMap[*depthToColorConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if conf.ColorCamera == nil || conf.DepthCamera == nil {
+		return nil, camera.UnspecifiedStream, errors.Wrapf(transform.ErrNoIntrinsics, "cannot create depth_to_color transform")
+	}
+	if conf.Extrinsics == nil {
+		return nil, camera.UnspecifiedStream,
+			errors.New("cannot create depth_to_color transform without depth_to_color_extrinsic_parameters")
+	}
+	confBytes, err := json.Marshal(conf)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	cameraSystem, err := transform.NewDepthColorIntrinsicsExtrinsicsFromBytes(confBytes)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if err := cameraSystem.CheckValid(); err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+
+	colorIntrinsics := cameraSystem.ColorCamera
+	cameraModel := transform.PinholeCameraModel{PinholeCameraIntrinsics: &colorIntrinsics}
+	reader := &depthToColorSource{gostream.NewEmbeddedVideoStream(source), cameraSystem}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, stream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, stream, err
+}
+
+// Read reprojects the depth map into the frame of the color camera.
+func (ds *depthToColorSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::depth_to_color::Read")
+	defer span.End()
+	orig, release, err := ds.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	dm, err := rimage.ConvertImageToDepthMap(ctx, orig)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	aligned, err := ds.cameraSystem.TransformDepthMapToColorCoord(dm)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return aligned, release, nil
+}
+
+// Close closes the original stream.
+func (ds *depthToColorSource) Close(ctx context.Context) error {
+	return ds.originalStream.Close(ctx)
+}
@@ -0,0 +1,80 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"testing"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/utils"
+)
+
+func TestDepthToColor(t *testing.T) {
+	depthIntrinsics := &transform.PinholeCameraIntrinsics{Width: 10, Height: 8, Fx: 10, Fy: 10, Ppx: 5, Ppy: 4}
+	colorIntrinsics := &transform.PinholeCameraIntrinsics{Width: 20, Height: 16, Fx: 20, Fy: 20, Ppx: 10, Ppy: 8}
+	am := utils.AttributeMap{
+		"color_intrinsic_parameters": colorIntrinsics,
+		"depth_intrinsic_parameters": depthIntrinsics,
+		"depth_to_color_extrinsic_parameters": map[string]interface{}{
+			"rotation_rads":  []float64{1, 0, 0, 0, 1, 0, 0, 0, 1},
+			"translation_mm": []float64{0, 0, 0},
+		},
+	}
+	dm := rimage.NewEmptyDepthMap(10, 8)
+	dm.Set(5, 4, 1000)
+	cam := newStaticCamera(dm, depthIntrinsics, nil)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	ds, stream, err := newDepthToColorTransform(context.Background(), cam, camera.DepthStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	out, _, err := camera.ReadImage(context.Background(), ds)
+	test.That(t, err, test.ShouldBeNil)
+	outDepth, ok := out.(*rimage.DepthMap)
+	test.That(t, ok, test.ShouldBeTrue)
+	// the depth map is the size of the color image, and the principal points line up
+	test.That(t, outDepth.Bounds(), test.ShouldResemble, image.Rect(0, 0, 20, 16))
+	test.That(t, outDepth.GetDepth(10, 8), test.ShouldEqual, rimage.Depth(1000))
+	test.That(t, outDepth.GetDepth(0, 0), test.ShouldEqual, rimage.Depth(0))
+	props, err := ds.(camera.VideoSource).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams, test.ShouldResemble, colorIntrinsics)
+	test.That(t, ds.Close(context.Background()), test.ShouldBeNil)
+
+	_, _, err = newDepthToColorTransform(context.Background(), cam, camera.ColorStream, am)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = newDepthToColorTransform(context.Background(), cam, camera.DepthStream,
+		utils.AttributeMap{"depth_intrinsic_parameters": depthIntrinsics})
+	test.That(t, err, test.ShouldWrap, transform.ErrNoIntrinsics)
+	_, _, err = newDepthToColorTransform(context.Background(), cam, camera.DepthStream, utils.AttributeMap{
+		"color_intrinsic_parameters": colorIntrinsics,
+		"depth_intrinsic_parameters": depthIntrinsics,
+	})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+// releaseCountingStream streams color images, counting how many of them were released.
+type releaseCountingStream struct {
+	released int
+}
+
+func (s *releaseCountingStream) Next(ctx context.Context) (image.Image, func(), error) {
+	return rimage.NewImage(10, 8), func() { s.released++ }, nil
+}
+
+func (s *releaseCountingStream) Close(ctx context.Context) error { return nil }
+
+func TestDepthToColorReleasesOnError(t *testing.T) {
+	// a color image cannot be converted to a depth map
+	stream := &releaseCountingStream{}
+	ds := &depthToColorSource{originalStream: stream}
+	_, _, err := ds.Read(context.Background())
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, stream.released, test.ShouldEqual, 1)
+}
@@ -0,0 +1,82 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+// frameRateLimitConfig are the attributes for a frame_rate_limit transform.
+type frameRateLimitConfig struct {
+	MaxFrameRate float64 `json:"max_frame_rate"`
+}
+
+type frameRateLimitSource struct {
+	originalStream gostream.VideoStream
+	interval       time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// newFrameRateLimitTransform creates a new transform that reads frames from the source no more often than
+// the maximum frame rate, so that expensive transforms later in the pipeline, and the streams of the camera,
+// do not process more frames than needed.
+func newFrameRateLimitTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := resource.TransformAttributeMap[*frameRateLimitConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if conf.MaxFrameRate <= 0 {
+		return nil, camera.UnspecifiedStream, errors.New("max_frame_rate of frame_rate_limit transform must be greater than 0")
+	}
+	cameraModel, err := cameraModelFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	reader := &frameRateLimitSource{
+		originalStream: gostream.NewEmbeddedVideoStream(source),
+		interval:       time.Duration(float64(time.Second) / conf.MaxFrameRate),
+	}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, stream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, stream, err
+}
+
+// Read waits until a frame interval has passed since the previous frame was read, and then reads the next frame.
+func (fs *frameRateLimitSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::frame_rate_limit::Read")
+	defer span.End()
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if wait := time.Until(fs.last.Add(fs.interval)); wait > 0 {
+		if !goutils.SelectContextOrWait(ctx, wait) {
+			return nil, nil, ctx.Err()
+		}
+	}
+	img, release, err := fs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	fs.last = time.Now()
+	return img, release, nil
+}
+
+// Close closes the original stream.
+func (fs *frameRateLimitSource) Close(ctx context.Context) error {
+	return fs.originalStream.Close(ctx)
+}
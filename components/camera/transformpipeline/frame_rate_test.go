@@ -0,0 +1,50 @@
+package transformpipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/utils"
+)
+
+func TestFrameRateLimit(t *testing.T) {
+	cam := newStaticCamera(rimage.NewEmptyDepthMap(4, 4), testIntrinsics(), nil)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	_, _, err := newFrameRateLimitTransform(context.Background(), cam, camera.DepthStream, utils.AttributeMap{})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	fs, stream, err := newFrameRateLimitTransform(context.Background(), cam, camera.DepthStream,
+		utils.AttributeMap{"max_frame_rate": 20})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	defer func() {
+		test.That(t, fs.Close(context.Background()), test.ShouldBeNil)
+	}()
+	props, err := fs.(camera.VideoSource).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams, test.ShouldResemble, testIntrinsics())
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		out, _, err := camera.ReadImage(context.Background(), fs)
+		test.That(t, err, test.ShouldBeNil)
+		_, ok := out.(*rimage.DepthMap)
+		test.That(t, ok, test.ShouldBeTrue)
+	}
+	test.That(t, time.Since(start), test.ShouldBeGreaterThanOrEqualTo, 150*time.Millisecond)
+
+	// waiting for the next frame stops when the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reader := &frameRateLimitSource{interval: time.Hour, last: time.Now()}
+	_, _, err = reader.Read(ctx)
+	test.That(t, err, test.ShouldBeError, context.Canceled)
+}
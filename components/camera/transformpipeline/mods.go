@@ -138,3 +138,230 @@ func (rs *resizeSource) Read(ctx context.Context) (image.Image, func(), error) {
 func (rs *resizeSource) Close(ctx context.Context) error {
 	return rs.originalStream.Close(ctx)
 }
+
+// cameraModelFromVideoSource returns the camera model of the source, with copies of its intrinsics and distortion
+// parameters so that they can be changed to match the output of a transform.
+func cameraModelFromVideoSource(ctx context.Context, source gostream.VideoSource) (transform.PinholeCameraModel, error) {
+	var cameraModel transform.PinholeCameraModel
+	props, err := propsFromVideoSource(ctx, source)
+	if err != nil {
+		return cameraModel, err
+	}
+	if props.IntrinsicParams != nil {
+		intrinsics := *props.IntrinsicParams
+		cameraModel.PinholeCameraIntrinsics = &intrinsics
+	}
+	if bc, ok := props.DistortionParams.(*transform.BrownConrady); ok && bc != nil {
+		distortion := *bc
+		cameraModel.Distortion = &distortion
+	} else if props.DistortionParams != nil {
+		cameraModel.Distortion = props.DistortionParams
+	}
+	return cameraModel, nil
+}
+
+// cropConfig are the attributes for a crop transform. The region of interest includes the minimum
+// and excludes the maximum pixel coordinates.
+type cropConfig struct {
+	XMin int `json:"x_min_px"`
+	YMin int `json:"y_min_px"`
+	XMax int `json:"x_max_px"`
+	YMax int `json:"y_max_px"`
+}
+
+type cropSource struct {
+	originalStream gostream.VideoStream
+	stream         camera.ImageType
+	rect           image.Rectangle
+}
+
+// newCropTransform creates a new crop transform.
+func newCropTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := resource.TransformAttributeMap[*cropConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if conf.XMin < 0 || conf.YMin < 0 {
+		return nil, camera.UnspecifiedStream, errors.New("cannot set x_min_px or y_min_px of crop transform to less than 0")
+	}
+	if conf.XMax <= conf.XMin || conf.YMax <= conf.YMin {
+		return nil, camera.UnspecifiedStream,
+			errors.New("x_max_px and y_max_px of crop transform must be greater than x_min_px and y_min_px")
+	}
+	rect := image.Rect(conf.XMin, conf.YMin, conf.XMax, conf.YMax)
+
+	cameraModel, err := cameraModelFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if intrinsics := cameraModel.PinholeCameraIntrinsics; intrinsics != nil {
+		rect = rect.Intersect(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+		if rect.Empty() {
+			return nil, camera.UnspecifiedStream, errors.Errorf(
+				"crop region is outside of the %dx%d image of the source camera", intrinsics.Width, intrinsics.Height)
+		}
+		intrinsics.Width, intrinsics.Height = rect.Dx(), rect.Dy()
+		intrinsics.Ppx -= float64(rect.Min.X)
+		intrinsics.Ppy -= float64(rect.Min.Y)
+	}
+
+	reader := &cropSource{gostream.NewEmbeddedVideoStream(source), stream, rect}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, stream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, stream, err
+}
+
+// Read crops the 2D image to the region of interest depending on the stream type.
+func (cs *cropSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::crop::Read")
+	defer span.End()
+	orig, release, err := cs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	rect := cs.rect.Add(orig.Bounds().Min).Intersect(orig.Bounds())
+	if rect.Empty() {
+		return nil, nil, errors.Errorf("crop region %v is outside of the image bounds %v", cs.rect, orig.Bounds())
+	}
+	switch cs.stream {
+	case camera.ColorStream, camera.UnspecifiedStream:
+		return imaging.Crop(orig, rect), release, nil
+	case camera.DepthStream:
+		dm, err := rimage.ConvertImageToDepthMap(ctx, orig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dm.SubImage(rect.Sub(orig.Bounds().Min)), release, nil
+	default:
+		return nil, nil, camera.NewUnsupportedImageTypeError(cs.stream)
+	}
+}
+
+// Close closes the original stream.
+func (cs *cropSource) Close(ctx context.Context) error {
+	return cs.originalStream.Close(ctx)
+}
+
+// flipDirection is the axis about which the flip transform mirrors the image.
+type flipDirection string
+
+const (
+	flipHorizontal = flipDirection("horizontal")
+	flipVertical   = flipDirection("vertical")
+	flipBoth       = flipDirection("both")
+)
+
+// flipConfig are the attributes for a flip transform.
+type flipConfig struct {
+	Direction flipDirection `json:"direction,omitempty" jsonschema:"enum=horizontal,enum=vertical,enum=both,default=horizontal"`
+}
+
+type flipSource struct {
+	originalStream gostream.VideoStream
+	stream         camera.ImageType
+	horizontal     bool
+	vertical       bool
+}
+
+// newFlipTransform creates a new flip transform, which mirrors the image horizontally (left to right),
+// vertically (top to bottom), or both.
+func newFlipTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := resource.TransformAttributeMap[*flipConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	reader := &flipSource{originalStream: gostream.NewEmbeddedVideoStream(source), stream: stream}
+	switch conf.Direction {
+	case "", flipHorizontal:
+		reader.horizontal = true
+	case flipVertical:
+		reader.vertical = true
+	case flipBoth:
+		reader.horizontal, reader.vertical = true, true
+	default:
+		return nil, camera.UnspecifiedStream, errors.Errorf("do not know flip direction %q", conf.Direction)
+	}
+
+	cameraModel, err := cameraModelFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	// mirroring an axis mirrors the principal point, and flips the sign of the tangential distortion along it
+	bc, _ := cameraModel.Distortion.(*transform.BrownConrady)
+	if reader.horizontal {
+		if intrinsics := cameraModel.PinholeCameraIntrinsics; intrinsics != nil {
+			intrinsics.Ppx = float64(intrinsics.Width-1) - intrinsics.Ppx
+		}
+		if bc != nil {
+			bc.TangentialP2 = -bc.TangentialP2
+		}
+	}
+	if reader.vertical {
+		if intrinsics := cameraModel.PinholeCameraIntrinsics; intrinsics != nil {
+			intrinsics.Ppy = float64(intrinsics.Height-1) - intrinsics.Ppy
+		}
+		if bc != nil {
+			bc.TangentialP1 = -bc.TangentialP1
+		}
+	}
+
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, stream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, stream, err
+}
+
+// Read flips the 2D image depending on the stream type.
+func (fs *flipSource) Read(ctx context.Context) (image.Image, func(), error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::flip::Read")
+	defer span.End()
+	orig, release, err := fs.originalStream.Next(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch fs.stream {
+	case camera.ColorStream, camera.UnspecifiedStream:
+		var flipped image.Image = orig
+		if fs.horizontal {
+			flipped = imaging.FlipH(flipped)
+		}
+		if fs.vertical {
+			flipped = imaging.FlipV(flipped)
+		}
+		return flipped, release, nil
+	case camera.DepthStream:
+		dm, err := rimage.ConvertImageToDepthMap(ctx, orig)
+		if err != nil {
+			return nil, nil, err
+		}
+		width, height := dm.Width(), dm.Height()
+		flipped := rimage.NewEmptyDepthMap(width, height)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				fx, fy := x, y
+				if fs.horizontal {
+					fx = width - 1 - x
+				}
+				if fs.vertical {
+					fy = height - 1 - y
+				}
+				flipped.Set(fx, fy, dm.GetDepth(x, y))
+			}
+		}
+		return flipped, release, nil
+	default:
+		return nil, nil, camera.NewUnsupportedImageTypeError(fs.stream)
+	}
+}
+
+// Close closes the original stream.
+func (fs *flipSource) Close(ctx context.Context) error {
+	return fs.originalStream.Close(ctx)
+}
@@ -15,6 +15,8 @@ import (
 	"go.viam.com/rdk/components/camera"
 	"go.viam.com/rdk/components/camera/videosource"
 	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/testutils/inject"
 	"go.viam.com/rdk/utils"
 )
 
@@ -187,3 +189,137 @@ func BenchmarkDepthRotate(b *testing.B) {
 	test.That(b, rs.Close(context.Background()), test.ShouldBeNil)
 	test.That(b, source.Close(context.Background()), test.ShouldBeNil)
 }
+
+// newStaticCamera returns a camera that streams the image, with the given camera parameters.
+func newStaticCamera(img image.Image, intrinsics *transform.PinholeCameraIntrinsics, distortion *transform.BrownConrady,
+) *inject.Camera {
+	var ss *videosource.StaticSource
+	if _, ok := img.(*rimage.DepthMap); ok {
+		ss = &videosource.StaticSource{DepthImg: img}
+	} else {
+		ss = &videosource.StaticSource{ColorImg: img}
+	}
+	source := gostream.NewVideoSource(ss, prop.Video{})
+	cam := inject.NewCamera("static")
+	cam.StreamFunc = source.Stream
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		props := camera.Properties{IntrinsicParams: intrinsics}
+		if distortion != nil {
+			props.DistortionParams = distortion
+		}
+		return props, nil
+	}
+	cam.CloseFunc = source.Close
+	return cam
+}
+
+func testIntrinsics() *transform.PinholeCameraIntrinsics {
+	return &transform.PinholeCameraIntrinsics{Width: 10, Height: 8, Fx: 20, Fy: 20, Ppx: 4, Ppy: 3}
+}
+
+func TestCrop(t *testing.T) {
+	img := rimage.NewImage(10, 8)
+	img.SetXY(3, 2, rimage.Red)
+	dm := rimage.NewEmptyDepthMap(10, 8)
+	dm.Set(3, 2, 100)
+	am := utils.AttributeMap{"x_min_px": 2, "y_min_px": 1, "x_max_px": 6, "y_max_px": 20}
+
+	colorCam := newStaticCamera(img, testIntrinsics(), nil)
+	cs, stream, err := newCropTransform(context.Background(), colorCam, camera.ColorStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+	out, _, err := camera.ReadImage(context.Background(), cs)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out.Bounds(), test.ShouldResemble, image.Rect(0, 0, 4, 7))
+	test.That(t, rimage.NewColorFromColor(out.At(1, 1)), test.ShouldResemble, rimage.Red)
+
+	// the principal point moves with the crop
+	props, err := cs.(camera.VideoSource).Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.IntrinsicParams.Width, test.ShouldEqual, 4)
+	test.That(t, props.IntrinsicParams.Height, test.ShouldEqual, 7)
+	test.That(t, props.IntrinsicParams.Ppx, test.ShouldEqual, 2)
+	test.That(t, props.IntrinsicParams.Ppy, test.ShouldEqual, 2)
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+
+	depthCam := newStaticCamera(dm, nil, nil)
+	cs, stream, err = newCropTransform(context.Background(), depthCam, camera.DepthStream, am)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	out, _, err = camera.ReadImage(context.Background(), cs)
+	test.That(t, err, test.ShouldBeNil)
+	outDepth, ok := out.(*rimage.DepthMap)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, outDepth.Bounds(), test.ShouldResemble, image.Rect(0, 0, 4, 7))
+	test.That(t, outDepth.GetDepth(1, 1), test.ShouldEqual, rimage.Depth(100))
+	test.That(t, cs.Close(context.Background()), test.ShouldBeNil)
+
+	// a region outside of the image fails
+	_, _, err = newCropTransform(context.Background(), colorCam, camera.ColorStream,
+		utils.AttributeMap{"x_min_px": 20, "y_min_px": 0, "x_max_px": 30, "y_max_px": 5})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = newCropTransform(context.Background(), colorCam, camera.ColorStream,
+		utils.AttributeMap{"x_min_px": 5, "y_min_px": 0, "x_max_px": 5, "y_max_px": 5})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, colorCam.Close(context.Background()), test.ShouldBeNil)
+	test.That(t, depthCam.Close(context.Background()), test.ShouldBeNil)
+}
+
+func TestFlip(t *testing.T) {
+	img := rimage.NewImage(10, 8)
+	img.SetXY(1, 2, rimage.Red)
+	dm := rimage.NewEmptyDepthMap(10, 8)
+	dm.Set(1, 2, 100)
+	distortion := &transform.BrownConrady{RadialK1: 0.1, TangentialP1: 0.01, TangentialP2: 0.02}
+
+	for _, tc := range []struct {
+		direction  string
+		x, y       int
+		ppx, ppy   float64
+		tp1, tp2   float64
+		attributes utils.AttributeMap
+	}{
+		{"horizontal", 8, 2, 5, 3, 0.01, -0.02, utils.AttributeMap{}},
+		{"vertical", 1, 5, 4, 4, -0.01, 0.02, utils.AttributeMap{"direction": "vertical"}},
+		{"both", 8, 5, 5, 4, -0.01, -0.02, utils.AttributeMap{"direction": "both"}},
+	} {
+		t.Run(tc.direction, func(t *testing.T) {
+			colorCam := newStaticCamera(img, testIntrinsics(), distortion)
+			fs, stream, err := newFlipTransform(context.Background(), colorCam, camera.ColorStream, tc.attributes)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, stream, test.ShouldEqual, camera.ColorStream)
+			out, _, err := camera.ReadImage(context.Background(), fs)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, rimage.NewColorFromColor(out.At(tc.x, tc.y)), test.ShouldResemble, rimage.Red)
+
+			props, err := fs.(camera.VideoSource).Properties(context.Background())
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, props.IntrinsicParams.Ppx, test.ShouldEqual, tc.ppx)
+			test.That(t, props.IntrinsicParams.Ppy, test.ShouldEqual, tc.ppy)
+			bc, ok := props.DistortionParams.(*transform.BrownConrady)
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, bc.TangentialP1, test.ShouldEqual, tc.tp1)
+			test.That(t, bc.TangentialP2, test.ShouldEqual, tc.tp2)
+			test.That(t, fs.Close(context.Background()), test.ShouldBeNil)
+			test.That(t, colorCam.Close(context.Background()), test.ShouldBeNil)
+
+			depthCam := newStaticCamera(dm, nil, nil)
+			fs, _, err = newFlipTransform(context.Background(), depthCam, camera.DepthStream, tc.attributes)
+			test.That(t, err, test.ShouldBeNil)
+			out, _, err = camera.ReadImage(context.Background(), fs)
+			test.That(t, err, test.ShouldBeNil)
+			outDepth, ok := out.(*rimage.DepthMap)
+			test.That(t, ok, test.ShouldBeTrue)
+			test.That(t, outDepth.GetDepth(tc.x, tc.y), test.ShouldEqual, rimage.Depth(100))
+			test.That(t, fs.Close(context.Background()), test.ShouldBeNil)
+			test.That(t, depthCam.Close(context.Background()), test.ShouldBeNil)
+		})
+	}
+
+	// the source distortion is not changed
+	test.That(t, distortion.TangentialP2, test.ShouldEqual, 0.02)
+
+	_, _, err := newFlipTransform(context.Background(), newStaticCamera(img, nil, nil), camera.ColorStream,
+		utils.AttributeMap{"direction": "diagonal"})
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -15,6 +15,7 @@ import (
 	"go.uber.org/multierr"
 
 	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/transform"
@@ -79,6 +80,13 @@ func newTransformPipeline(
 	if len(cfg.Pipeline) == 0 {
 		return nil, errors.New("pipeline has no transforms in it")
 	}
+	// the transforms after a point cloud filter would only see its unfiltered images
+	for i, tr := range cfg.Pipeline[:len(cfg.Pipeline)-1] {
+		if transformType(tr.Type) == transformTypePointCloud {
+			return nil, errors.Errorf("%s transform must be the last of the pipeline, but is transform %d of %d",
+				transformTypePointCloud, i+1, len(cfg.Pipeline))
+		}
+	}
 	// check if the source produces a depth image or color image
 	img, release, err := camera.ReadImage(ctx, source)
 	if err != nil {
@@ -107,12 +115,17 @@ func newTransformPipeline(
 	}
 	lastSourceStream := gostream.NewEmbeddedVideoStream(lastSource)
 	cameraModel := camera.NewPinholeModelWithBrownConradyDistortion(cfg.CameraParameters, cfg.DistortionParameters)
-	return camera.NewVideoSourceFromReader(
-		ctx,
-		transformPipeline{pipeline, lastSourceStream, cfg.CameraParameters},
-		&cameraModel,
-		streamType,
-	)
+	tp := transformPipeline{pipeline, lastSourceStream, cfg.CameraParameters}
+	var reader gostream.VideoReader = tp
+	// point clouds are projected from the images of the pipeline, unless it ends by filtering them
+	if transformType(cfg.Pipeline[len(cfg.Pipeline)-1].Type) == transformTypePointCloud {
+		pcSource, ok := lastSource.(camera.PointCloudSource)
+		if !ok {
+			return nil, errors.New("last transform of pipeline does not have NextPointCloud method")
+		}
+		reader = pointCloudTransformPipeline{tp, pcSource}
+	}
+	return camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, streamType)
 }
 
 type transformPipeline struct {
@@ -127,6 +140,18 @@ func (tp transformPipeline) Read(ctx context.Context) (image.Image, func(), erro
 	return tp.stream.Next(ctx)
 }
 
+// pointCloudTransformPipeline is a transformPipeline whose point clouds are those of its last transform.
+type pointCloudTransformPipeline struct {
+	transformPipeline
+	pointCloudSource camera.PointCloudSource
+}
+
+func (tp pointCloudTransformPipeline) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::NextPointCloud")
+	defer span.End()
+	return tp.pointCloudSource.NextPointCloud(ctx)
+}
+
 func (tp transformPipeline) Close(ctx context.Context) error {
 	var errs error
 	for _, src := range tp.pipeline {
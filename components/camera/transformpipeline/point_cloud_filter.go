@@ -0,0 +1,181 @@
+package transformpipeline
+
+import (
+	"context"
+	"image"
+
+	"github.com/edaniels/gostream"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/utils"
+)
+
+// pointCloudFilterConfig are the attributes for a point_cloud_filter transform. Each filter that is set is
+// applied, in the order of the fields.
+type pointCloudFilterConfig struct {
+	CropBox            *cropBoxConfig            `json:"crop_box,omitempty"`
+	Frustum            *frustumConfig            `json:"frustum,omitempty"`
+	VoxelSize          float64                   `json:"voxel_size_mm,omitempty"`
+	StatisticalOutlier *statisticalOutlierConfig `json:"statistical_outlier,omitempty"`
+	RadiusOutlier      *radiusOutlierConfig      `json:"radius_outlier,omitempty"`
+	NormalsK           int                       `json:"normals_k,omitempty"`
+}
+
+// cropBoxConfig keeps the points within the box between the min and max points, or only those outside of it.
+type cropBoxConfig struct {
+	Min      r3.Vector `json:"min_mm"`
+	Max      r3.Vector `json:"max_mm"`
+	Negative bool      `json:"negative,omitempty"`
+}
+
+// frustumConfig keeps the points within the view of the camera.
+type frustumConfig struct {
+	HorizontalFOV float64 `json:"horizontal_fov_degs"`
+	VerticalFOV   float64 `json:"vertical_fov_degs"`
+	Near          float64 `json:"near_mm"`
+	Far           float64 `json:"far_mm"`
+}
+
+type statisticalOutlierConfig struct {
+	MeanK        int     `json:"mean_k"`
+	StdDevThresh float64 `json:"std_dev_threshold"`
+}
+
+type radiusOutlierConfig struct {
+	Radius       float64 `json:"radius_mm"`
+	MinNeighbors int     `json:"min_neighbors"`
+}
+
+type pointCloudFilterSource struct {
+	originalStream gostream.VideoStream
+	source         camera.PointCloudSource
+	filters        []func(pointcloud.PointCloud) (pointcloud.PointCloud, error)
+}
+
+// newPointCloudFilterTransform creates a new transform that filters the point clouds of the source, so that they are
+// downsampled and cleaned up before they are used for segmentation or motion planning. Images pass through unchanged.
+func newPointCloudFilterTransform(
+	ctx context.Context, source gostream.VideoSource, stream camera.ImageType, am utils.AttributeMap,
+) (gostream.VideoSource, camera.ImageType, error) {
+	conf, err := resource.TransformAttributeMap[*pointCloudFilterConfig](am)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	pcSource, ok := source.(camera.PointCloudSource)
+	if !ok {
+		return nil, camera.UnspecifiedStream, errors.New("source of point_cloud_filter transform does not have NextPointCloud method")
+	}
+	filters, err := conf.filters()
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	if len(filters) == 0 {
+		return nil, camera.UnspecifiedStream, errors.New("point_cloud_filter transform must have at least one filter")
+	}
+	cameraModel, err := cameraModelFromVideoSource(ctx, source)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	reader := &pointCloudFilterSource{
+		originalStream: gostream.NewEmbeddedVideoStream(source),
+		source:         pcSource,
+		filters:        filters,
+	}
+	src, err := camera.NewVideoSourceFromReader(ctx, reader, &cameraModel, stream)
+	if err != nil {
+		return nil, camera.UnspecifiedStream, err
+	}
+	return src, stream, err
+}
+
+func (conf *pointCloudFilterConfig) filters() ([]func(pointcloud.PointCloud) (pointcloud.PointCloud, error), error) {
+	var filters []func(pointcloud.PointCloud) (pointcloud.PointCloud, error)
+	if box := conf.CropBox; box != nil {
+		if box.Min.X > box.Max.X || box.Min.Y > box.Max.Y || box.Min.Z > box.Max.Z {
+			return nil, errors.New("min_mm of crop_box must not be greater than max_mm")
+		}
+		filters = append(filters, func(pc pointcloud.PointCloud) (pointcloud.PointCloud, error) {
+			return pointcloud.CropBox(pc, box.Min, box.Max, box.Negative)
+		})
+	}
+	if conf.Frustum != nil {
+		frustum := pointcloud.Frustum{
+			HorizontalFOV: conf.Frustum.HorizontalFOV,
+			VerticalFOV:   conf.Frustum.VerticalFOV,
+			Near:          conf.Frustum.Near,
+			Far:           conf.Frustum.Far,
+		}
+		if err := frustum.Validate(); err != nil {
+			return nil, err
+		}
+		filters = append(filters, func(pc pointcloud.PointCloud) (pointcloud.PointCloud, error) {
+			return pointcloud.CropFrustum(pc, frustum)
+		})
+	}
+	if conf.VoxelSize < 0 {
+		return nil, errors.New("voxel_size_mm must not be negative")
+	}
+	if conf.VoxelSize > 0 {
+		filters = append(filters, func(pc pointcloud.PointCloud) (pointcloud.PointCloud, error) {
+			return pointcloud.VoxelGridDownsample(pc, conf.VoxelSize)
+		})
+	}
+	if conf.StatisticalOutlier != nil {
+		filter, err := pointcloud.StatisticalOutlierFilter(conf.StatisticalOutlier.MeanK, conf.StatisticalOutlier.StdDevThresh)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	if conf.RadiusOutlier != nil {
+		filter, err := pointcloud.RadiusOutlierFilter(conf.RadiusOutlier.Radius, conf.RadiusOutlier.MinNeighbors)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+	if conf.NormalsK < 0 {
+		return nil, errors.New("normals_k must not be negative")
+	}
+	if conf.NormalsK > 0 {
+		if conf.NormalsK < 3 {
+			return nil, errors.New("normals_k must be at least 3")
+		}
+		filters = append(filters, func(pc pointcloud.PointCloud) (pointcloud.PointCloud, error) {
+			// point clouds are in the frame of the camera, which sees them from its origin
+			return pointcloud.EstimateNormals(pc, conf.NormalsK, r3.Vector{})
+		})
+	}
+	return filters, nil
+}
+
+// Read returns the image of the source unchanged.
+func (ps *pointCloudFilterSource) Read(ctx context.Context) (image.Image, func(), error) {
+	return ps.originalStream.Next(ctx)
+}
+
+// NextPointCloud returns the next point cloud of the source with the filters applied.
+func (ps *pointCloudFilterSource) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	ctx, span := trace.StartSpan(ctx, "camera::transformpipeline::point_cloud_filter::NextPointCloud")
+	defer span.End()
+	pc, err := ps.source.NextPointCloud(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, filter := range ps.filters {
+		if pc, err = filter(pc); err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+// Close closes the original stream.
+func (ps *pointCloudFilterSource) Close(ctx context.Context) error {
+	return ps.originalStream.Close(ctx)
+}
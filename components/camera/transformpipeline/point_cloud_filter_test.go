@@ -0,0 +1,108 @@
+package transformpipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+func newStaticPointCloudCamera(t *testing.T) *inject.Camera {
+	t.Helper()
+	pc := pointcloud.New()
+	// a dense 10x10 grid 1m in front of the camera, with a stray point near it and one far away
+	for x := 0.0; x < 10; x++ {
+		for y := 0.0; y < 10; y++ {
+			test.That(t, pc.Set(r3.Vector{x * 10, y * 10, 1000}, nil), test.ShouldBeNil)
+		}
+	}
+	test.That(t, pc.Set(r3.Vector{300, 300, 1000}, nil), test.ShouldBeNil)
+	test.That(t, pc.Set(r3.Vector{0, 0, 9000}, nil), test.ShouldBeNil)
+	cam := newStaticCamera(rimage.NewEmptyDepthMap(10, 8), testIntrinsics(), nil)
+	cam.NextPointCloudFunc = func(ctx context.Context) (pointcloud.PointCloud, error) {
+		return pc, nil
+	}
+	return cam
+}
+
+func TestPointCloudFilter(t *testing.T) {
+	cam := newStaticPointCloudCamera(t)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	for _, am := range []utils.AttributeMap{
+		{},
+		{"voxel_size_mm": -1},
+		{"normals_k": 2},
+		{"crop_box": map[string]interface{}{"min_mm": map[string]float64{"x": 1}}},
+		{"frustum": map[string]interface{}{"horizontal_fov_degs": 90}},
+		{"radius_outlier": map[string]interface{}{"radius_mm": 10}},
+		{"statistical_outlier": map[string]interface{}{"mean_k": 10}},
+	} {
+		_, _, err := newPointCloudFilterTransform(context.Background(), cam, camera.DepthStream, am)
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+
+	ps, stream, err := newPointCloudFilterTransform(context.Background(), cam, camera.DepthStream, utils.AttributeMap{
+		"frustum": map[string]interface{}{
+			"horizontal_fov_degs": 90, "vertical_fov_degs": 90, "near_mm": 100, "far_mm": 5000,
+		},
+		"voxel_size_mm":  20,
+		"radius_outlier": map[string]interface{}{"radius_mm": 30, "min_neighbors": 2},
+		"normals_k":      5,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, stream, test.ShouldEqual, camera.DepthStream)
+	defer func() {
+		test.That(t, ps.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	// images pass through unchanged
+	img, _, err := camera.ReadImage(context.Background(), ps)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds().Dx(), test.ShouldEqual, 10)
+
+	pc, err := ps.(camera.VideoSource).NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	// the far point is cropped, the grid is downsampled to 5x5 and the stray point is removed
+	test.That(t, pc.Size(), test.ShouldEqual, 25)
+	test.That(t, pc.MetaData().MaxZ, test.ShouldEqual, 1000)
+	test.That(t, pc.MetaData().MaxX, test.ShouldBeLessThan, 100)
+	test.That(t, pc.MetaData().HasNormal, test.ShouldBeTrue)
+}
+
+func TestTransformPipelinePointCloudFilter(t *testing.T) {
+	cam := newStaticPointCloudCamera(t)
+	defer func() {
+		test.That(t, cam.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	conf := &transformConfig{
+		Source: "source",
+		Pipeline: []Transformation{
+			{Type: "point_cloud_filter", Attributes: utils.AttributeMap{"voxel_size_mm": 20}},
+		},
+	}
+	tp, err := newTransformPipeline(context.Background(), cam, conf, &inject.Robot{})
+	test.That(t, err, test.ShouldBeNil)
+	pc, err := tp.NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 27)
+	props, err := tp.Properties(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, props.SupportsPCD, test.ShouldBeTrue)
+	test.That(t, tp.Close(context.Background()), test.ShouldBeNil)
+
+	// the filter must be the last transform, since the transforms after it would only see its unfiltered images
+	conf.Pipeline = append(conf.Pipeline, Transformation{Type: "rotate"})
+	_, err = newTransformPipeline(context.Background(), cam, conf, &inject.Robot{})
+	test.That(t, err, test.ShouldBeError, "point_cloud_filter transform must be the last of the pipeline, but is transform 1 of 2")
+}
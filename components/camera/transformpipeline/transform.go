@@ -28,6 +28,13 @@ const (
 	transformTypeClassifications = transformType("classifications")
 	transformTypeDepthEdges      = transformType("depth_edges")
 	transformTypeDepthPreprocess = transformType("depth_preprocess")
+	transformTypeCrop            = transformType("crop")
+	transformTypeFlip            = transformType("flip")
+	transformTypeColorSpace      = transformType("color_space")
+	transformTypeEqualize        = transformType("equalize")
+	transformTypeFrameRateLimit  = transformType("frame_rate_limit")
+	transformTypeDepthToColor    = transformType("depth_to_color")
+	transformTypePointCloud      = transformType("point_cloud_filter")
 )
 
 // emptyConfig is for transforms that have no attribute fields.
@@ -92,6 +99,42 @@ var registeredTransformConfigs = map[transformType]*transformRegistration{
 		&emptyConfig{},
 		"Applies some basic hole-filling and edge smoothing to a depth map.",
 	},
+	transformTypeCrop: {
+		string(transformTypeCrop),
+		&cropConfig{},
+		"Crops the image to the region of interest between the minimum and maximum pixel coordinates.",
+	},
+	transformTypeFlip: {
+		string(transformTypeFlip),
+		&flipConfig{},
+		"Mirrors the image horizontally, vertically, or both. Used when the camera image is mirrored.",
+	},
+	transformTypeColorSpace: {
+		string(transformTypeColorSpace),
+		&colorSpaceConfig{},
+		"Converts a color image to grayscale, or to HSV encoded in the RGB channels.",
+	},
+	transformTypeEqualize: {
+		string(transformTypeEqualize),
+		&equalizeConfig{},
+		"Equalizes the histogram of the brightness of a color image, globally or with CLAHE, to improve its contrast.",
+	},
+	transformTypeFrameRateLimit: {
+		string(transformTypeFrameRateLimit),
+		&frameRateLimitConfig{},
+		"Limits the rate at which frames are read from the source to the maximum frame rate.",
+	},
+	transformTypeDepthToColor: {
+		string(transformTypeDepthToColor),
+		&depthToColorConfig{},
+		"Reprojects a depth map into the frame of a color camera, using the intrinsics of both cameras and the extrinsics between them.",
+	},
+	transformTypePointCloud: {
+		string(transformTypePointCloud),
+		&pointCloudFilterConfig{},
+		"Crops, downsamples, removes outliers from and estimates normals of the point clouds of the source. " +
+			"Images pass through unchanged. Must be last in the pipeline for the camera to return the filtered point clouds.",
+	},
 }
 
 // Transformation states the type of transformation and the attributes that are specific to the given type.
@@ -144,6 +187,20 @@ func buildTransform(
 		return newDepthEdgesTransform(ctx, source, tr.Attributes)
 	case transformTypeDepthPreprocess:
 		return newDepthPreprocessTransform(ctx, source)
+	case transformTypeCrop:
+		return newCropTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeFlip:
+		return newFlipTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeColorSpace:
+		return newColorSpaceTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeEqualize:
+		return newEqualizeTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeFrameRateLimit:
+		return newFrameRateLimitTransform(ctx, source, stream, tr.Attributes)
+	case transformTypeDepthToColor:
+		return newDepthToColorTransform(ctx, source, stream, tr.Attributes)
+	case transformTypePointCloud:
+		return newPointCloudFilterTransform(ctx, source, stream, tr.Attributes)
 	default:
 		return nil, camera.UnspecifiedStream, errors.Errorf("do not know camera transform of type %q", tr.Type)
 	}
@@ -134,6 +134,22 @@ type client struct {
 	product   vlp16.ProductID
 	ip        string
 	packets   []vlp16.Packet
+	// rotation holds the packets of the rotation the lidar is in the middle of.
+	rotation []vlp16.Packet
+	// lastAzimuth is the azimuth of the last block read, used to find when the lidar finishes a rotation.
+	lastAzimuth uint16
+
+	// rotations hands the newest finished rotation over to be made into a point cloud and published, so that
+	// reading packets keeps up with the lidar.
+	rotations chan finishedRotation
+	publisher *camera.PointCloudPublisher
+}
+
+// A finishedRotation holds the packets of one rotation of the lidar.
+type finishedRotation struct {
+	product    vlp16.ProductID
+	packets    []vlp16.Packet
+	capturedAt time.Time
 }
 
 // New creates a connection to a Velodyne lidar and generates pointclouds from it.
@@ -150,14 +166,19 @@ func New(ctx context.Context, name resource.Name, logger golog.Logger, port, ttl
 		bindAddress:     bindAddress,
 		ttlMilliseconds: ttlMilliseconds,
 		logger:          logger,
+		rotations:       make(chan finishedRotation, 1),
+		publisher:       camera.NewPointCloudPublisher(),
 	}
 
 	cancelCtx, cancelFunc := context.WithCancel(context.Background())
 	c.cancelFunc = cancelFunc
-	c.activeBackgroundWorkers.Add(1)
+	c.activeBackgroundWorkers.Add(2)
 	gutils.PanicCapturingGo(func() {
 		c.run(cancelCtx, listener)
 	})
+	gutils.PanicCapturingGo(func() {
+		c.publishRotations(cancelCtx)
+	})
 
 	src, err := camera.NewVideoSourceFromReader(ctx, c, nil, camera.DepthStream)
 	if err != nil {
@@ -195,8 +216,11 @@ func (c *client) run(ctx context.Context, listener *vlp16.PacketListener) {
 			}
 		}
 
-		err = c.runLoop(listener)
+		rotation, err := c.runLoop(listener)
 		c.setLastError(err)
+		if rotation != nil && c.publisher.HasStreams() {
+			c.handOver(*rotation)
+		}
 		if err != nil {
 			c.logger.Infof("velodyne client error: %w", err)
 			err = listener.Close()
@@ -211,9 +235,10 @@ func (c *client) run(ctx context.Context, listener *vlp16.PacketListener) {
 	}
 }
 
-func (c *client) runLoop(listener *vlp16.PacketListener) error {
+// runLoop reads a packet and returns the rotation the lidar finished with it, if any.
+func (c *client) runLoop(listener *vlp16.PacketListener) (*finishedRotation, error) {
 	if err := listener.ReadPacket(); err != nil {
-		return err
+		return nil, err
 	}
 
 	p := listener.Packet()
@@ -226,37 +251,87 @@ func (c *client) runLoop(listener *vlp16.PacketListener) error {
 		c.ip = ipString
 	} else if c.ip != ipString {
 		c.packets = []vlp16.Packet{}
+		c.rotation = nil
 		c.product = 0
 		err := fmt.Errorf("velodyne ip changed from %s -> %s", c.ip, ipString)
 		c.ip = ipString
-		return err
+		return nil, err
 	}
 
 	if c.product == 0 {
 		c.product = p.ProductID
 	} else if c.product != p.ProductID {
 		c.packets = []vlp16.Packet{}
+		c.rotation = nil
 		err := fmt.Errorf("velodyne product changed from %s -> %s", c.product, p.ProductID)
 		c.product = 0
-		return err
+		return nil, err
 	}
 
 	// we remove the packets too old
+	c.packets = append(c.dropOlderPackets(c.packets, p), *p)
+	c.rotation = append(c.dropOlderPackets(c.rotation, p), *p)
+
+	rotated := false
+	for _, b := range p.Blocks {
+		if b.Azimuth < c.lastAzimuth {
+			rotated = true
+		}
+		c.lastAzimuth = b.Azimuth
+	}
+	if !rotated {
+		return nil, nil
+	}
+	rotation := &finishedRotation{product: c.product, packets: c.rotation, capturedAt: time.Now()}
+	c.rotation = nil
+	return rotation, nil
+}
+
+// dropOlderPackets returns the packets that are not older than the ttl as of the newest packet.
+func (c *client) dropOlderPackets(packets []vlp16.Packet, newest *vlp16.Packet) []vlp16.Packet {
 	firstToRemove := -1
-	for idx, old := range c.packets {
-		age := int(p.Timestamp) - int(old.Timestamp)
+	for idx, old := range packets {
+		age := int(newest.Timestamp) - int(old.Timestamp)
 		if age < c.ttlMilliseconds*1000 {
 			break
 		}
 		firstToRemove = idx
 	}
+	return packets[firstToRemove+1:]
+}
 
-	if firstToRemove >= 0 {
-		c.packets = c.packets[firstToRemove+1:]
+// handOver gives the rotation to publishRotations, replacing any it has not gotten to yet.
+func (c *client) handOver(rotation finishedRotation) {
+	for {
+		select {
+		case c.rotations <- rotation:
+			return
+		default:
+		}
+		select {
+		case <-c.rotations:
+		default:
+		}
 	}
+}
 
-	c.packets = append(c.packets, *p)
-	return nil
+// publishRotations makes a point cloud of each rotation handed to it and sends it to the streams of point clouds,
+// until the context is done.
+func (c *client) publishRotations(ctx context.Context) {
+	defer c.activeBackgroundWorkers.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rotation := <-c.rotations:
+			pc, err := pointCloudFromPackets(rotation.product, rotation.packets)
+			if err != nil {
+				c.logger.Debugw("failed to make point cloud to stream", "error", err)
+				continue
+			}
+			c.publisher.Publish(pc, rotation.capturedAt)
+		}
+	}
 }
 
 func pointFrom(yaw, pitch, distance float64) r3.Vector {
@@ -273,18 +348,24 @@ func pointFrom(yaw, pitch, distance float64) r3.Vector {
 
 func (c *client) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.lastError != nil {
-		return nil, c.lastError
+	// packets are only ever appended after the end of the slice, so the ones in it can be read without the lock
+	product, packets, err := c.product, c.packets, c.lastError
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
 	}
+	return pointCloudFromPackets(product, packets)
+}
 
-	config, ok := allProductData[c.product]
+// pointCloudFromPackets makes a point cloud of the returns in the packets of a lidar of the product.
+func pointCloudFromPackets(product vlp16.ProductID, packets []vlp16.Packet) (pointcloud.PointCloud, error) {
+	config, ok := allProductData[product]
 	if !ok {
-		return nil, fmt.Errorf("no config for %s", c.product)
+		return nil, fmt.Errorf("no config for %s", product)
 	}
 
 	pc := pointcloud.New()
-	for _, p := range c.packets {
+	for _, p := range packets {
 		for _, b := range p.Blocks {
 			yaw := float64(b.Azimuth) / 100
 			for channelID, c := range b.Channels {
@@ -345,8 +426,14 @@ func (c *client) Read(ctx context.Context) (image.Image, func(), error) {
 	return img, nil, nil
 }
 
+// StreamPointClouds streams a point cloud each time the lidar finishes a rotation.
+func (c *client) StreamPointClouds(ctx context.Context, opts camera.PointCloudStreamOptions) (camera.PointCloudStream, error) {
+	return c.publisher.StreamPointClouds(ctx, opts)
+}
+
 func (c *client) Close(ctx context.Context) error {
 	c.cancelFunc()
 	c.activeBackgroundWorkers.Wait()
+	c.publisher.Close()
 	return nil
 }
@@ -0,0 +1,203 @@
+package videorecorder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+)
+
+// The layout of the headers of an MJPEG AVI file with a single video stream. Sizes and counts that are only known
+// once all frames are written are patched at these offsets when the file is closed.
+const (
+	aviRIFFSizeOffset         = 4
+	aviHdrlSizeOffset         = 16
+	aviMicroSecPerFrameOffset = 32
+	aviMaxBytesPerSecOffset   = 36
+	aviTotalFramesOffset      = 48
+	aviAvihBufferSizeOffset   = 60
+	aviStrlSizeOffset         = 92
+	aviStrhScaleOffset        = 128
+	aviStrhRateOffset         = 132
+	aviStrhLengthOffset       = 140
+	aviStrhBufferSizeOffset   = 144
+	aviMoviListOffset         = 212
+	aviMoviSizeOffset         = 216
+	aviMoviFourCCOffset       = 220
+	aviHeaderSize             = 224
+	aviFlagHasIndex           = 0x10
+	aviIndexFlagKeyFrame      = 0x10
+	microSecondsPerSecond     = 1000000
+)
+
+type aviIndexEntry struct {
+	offset, size uint32
+}
+
+// An aviWriter writes JPEG frames to an AVI file as an MJPEG video stream. The frame rate of the stream is measured
+// from the capture times of the frames, so that the video plays back in real time even if frames were dropped.
+type aviWriter struct {
+	f             *os.File
+	width, height int
+	offset        int64
+	index         []aviIndexEntry
+	maxFrameSize  uint32
+	first, last   time.Time
+}
+
+// newAVIWriter creates the file at path and writes the headers of a video of the given size to it.
+func newAVIWriter(path string, width, height int) (*aviWriter, error) {
+	//nolint:gosec
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &aviWriter{f: f, width: width, height: height}
+	if _, err := f.Write(w.header()); err != nil {
+		return nil, multierr.Combine(errors.Wrap(err, "failed to write avi header"), f.Close())
+	}
+	w.offset = aviHeaderSize
+	return w, nil
+}
+
+func (w *aviWriter) header() []byte {
+	var buf bytes.Buffer
+	le := func(vs ...interface{}) {
+		for _, v := range vs {
+			//nolint:errcheck
+			binary.Write(&buf, binary.LittleEndian, v)
+		}
+	}
+	width, height := uint32(w.width), uint32(w.height)
+
+	buf.WriteString("RIFF")
+	le(uint32(0))
+	buf.WriteString("AVI ")
+
+	buf.WriteString("LIST")
+	le(uint32(aviMoviListOffset - aviHdrlSizeOffset - 4))
+	buf.WriteString("hdrl")
+
+	buf.WriteString("avih")
+	le(uint32(56))
+	le(uint32(0), uint32(0), uint32(0), uint32(aviFlagHasIndex), uint32(0), uint32(0), uint32(1), uint32(0), width, height)
+	le([4]uint32{})
+
+	buf.WriteString("LIST")
+	le(uint32(aviMoviListOffset - aviStrlSizeOffset - 4))
+	buf.WriteString("strl")
+
+	buf.WriteString("strh")
+	le(uint32(56))
+	buf.WriteString("vidsMJPG")
+	le(uint32(0), uint16(0), uint16(0), uint32(0), uint32(1), uint32(0), uint32(0), uint32(0), uint32(0))
+	le(int32(-1), uint32(0), [4]int16{0, 0, int16(w.width), int16(w.height)})
+
+	buf.WriteString("strf")
+	le(uint32(40))
+	le(uint32(40), int32(w.width), int32(w.height), uint16(1), uint16(24))
+	buf.WriteString("MJPG")
+	le(width*height*3, int32(0), int32(0), uint32(0), uint32(0))
+
+	buf.WriteString("LIST")
+	le(uint32(0))
+	buf.WriteString("movi")
+	return buf.Bytes()
+}
+
+// WriteFrame appends a JPEG encoded frame captured at the given time.
+func (w *aviWriter) WriteFrame(frame []byte, capturedAt time.Time) error {
+	size := uint32(len(frame))
+	chunk := make([]byte, 8, 8+len(frame)+1)
+	copy(chunk, "00dc")
+	binary.LittleEndian.PutUint32(chunk[4:], size)
+	chunk = append(chunk, frame...)
+	// chunks are word aligned
+	if len(frame)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	if _, err := w.f.Write(chunk); err != nil {
+		return err
+	}
+	w.index = append(w.index, aviIndexEntry{offset: uint32(w.offset - aviMoviFourCCOffset), size: size})
+	w.offset += int64(len(chunk))
+	if size > w.maxFrameSize {
+		w.maxFrameSize = size
+	}
+	if w.first.IsZero() {
+		w.first = capturedAt
+	}
+	w.last = capturedAt
+	return nil
+}
+
+// Frames returns the number of frames written.
+func (w *aviWriter) Frames() int {
+	return len(w.index)
+}
+
+// Duration returns the time between the first and last frames written.
+func (w *aviWriter) Duration() time.Duration {
+	return w.last.Sub(w.first)
+}
+
+// Close writes the index of the frames, completes the headers and closes the file.
+func (w *aviWriter) Close() error {
+	err := w.finish()
+	if closeErr := w.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (w *aviWriter) finish() error {
+	index := make([]byte, 8+16*len(w.index))
+	copy(index, "idx1")
+	binary.LittleEndian.PutUint32(index[4:], uint32(16*len(w.index)))
+	for i, entry := range w.index {
+		e := index[8+16*i:]
+		copy(e, "00dc")
+		binary.LittleEndian.PutUint32(e[4:], aviIndexFlagKeyFrame)
+		binary.LittleEndian.PutUint32(e[8:], entry.offset)
+		binary.LittleEndian.PutUint32(e[12:], entry.size)
+	}
+	if _, err := w.f.Write(index); err != nil {
+		return err
+	}
+
+	// rate / scale is the frame rate, in frames per second
+	microSecPerFrame, rate, scale := uint32(microSecondsPerSecond), uint32(1), uint32(1)
+	if frames := len(w.index); frames > 1 && w.Duration() > 0 {
+		microSecPerFrame = uint32(w.Duration().Microseconds() / int64(frames-1))
+		if microSecPerFrame == 0 {
+			microSecPerFrame = 1
+		}
+		rate, scale = microSecondsPerSecond, microSecPerFrame
+	}
+	patches := []struct {
+		offset int64
+		value  uint32
+	}{
+		{aviRIFFSizeOffset, uint32(w.offset + int64(len(index)) - 8)},
+		{aviMicroSecPerFrameOffset, microSecPerFrame},
+		{aviMaxBytesPerSecOffset, uint32(uint64(w.maxFrameSize) * microSecondsPerSecond / uint64(microSecPerFrame))},
+		{aviTotalFramesOffset, uint32(len(w.index))},
+		{aviAvihBufferSizeOffset, w.maxFrameSize},
+		{aviStrhScaleOffset, scale},
+		{aviStrhRateOffset, rate},
+		{aviStrhLengthOffset, uint32(len(w.index))},
+		{aviStrhBufferSizeOffset, w.maxFrameSize},
+		{aviMoviSizeOffset, uint32(w.offset - aviMoviFourCCOffset)},
+	}
+	var value [4]byte
+	for _, p := range patches {
+		binary.LittleEndian.PutUint32(value[:], p.value)
+		if _, err := w.f.WriteAt(value[:], p.offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
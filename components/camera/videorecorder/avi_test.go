@@ -0,0 +1,98 @@
+package videorecorder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage"
+)
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := rimage.NewImage(width, height)
+	img.SetXY(1, 1, rimage.Red)
+	var buf bytes.Buffer
+	test.That(t, jpeg.Encode(&buf, img, nil), test.ShouldBeNil)
+	return buf.Bytes()
+}
+
+func TestAVIWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.avi")
+	w, err := newAVIWriter(path, 16, 8)
+	test.That(t, err, test.ShouldBeNil)
+
+	frame := encodeJPEG(t, 16, 8)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		test.That(t, w.WriteFrame(frame, start.Add(time.Duration(i)*200*time.Millisecond)), test.ShouldBeNil)
+	}
+	// an odd sized frame is padded
+	test.That(t, w.WriteFrame(append(frame, 0), start.Add(600*time.Millisecond)), test.ShouldBeNil)
+	test.That(t, w.Frames(), test.ShouldEqual, 4)
+	test.That(t, w.Duration(), test.ShouldEqual, 600*time.Millisecond)
+	test.That(t, w.Close(), test.ShouldBeNil)
+
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	test.That(t, err, test.ShouldBeNil)
+	u32 := func(offset int) uint32 {
+		return binary.LittleEndian.Uint32(data[offset:])
+	}
+	test.That(t, string(data[0:4]), test.ShouldEqual, "RIFF")
+	test.That(t, int(u32(aviRIFFSizeOffset)), test.ShouldEqual, len(data)-8)
+	test.That(t, string(data[8:12]), test.ShouldEqual, "AVI ")
+	test.That(t, string(data[24:28]), test.ShouldEqual, "avih")
+	test.That(t, string(data[100:104]), test.ShouldEqual, "strh")
+	test.That(t, string(data[108:116]), test.ShouldEqual, "vidsMJPG")
+	test.That(t, string(data[164:168]), test.ShouldEqual, "strf")
+	test.That(t, string(data[aviMoviFourCCOffset:aviHeaderSize]), test.ShouldEqual, "movi")
+
+	// 5 frames per second
+	test.That(t, u32(aviMicroSecPerFrameOffset), test.ShouldEqual, 200000)
+	test.That(t, float64(u32(aviStrhRateOffset))/float64(u32(aviStrhScaleOffset)), test.ShouldAlmostEqual, 5)
+	test.That(t, u32(aviTotalFramesOffset), test.ShouldEqual, 4)
+	test.That(t, u32(aviStrhLengthOffset), test.ShouldEqual, 4)
+	test.That(t, u32(64), test.ShouldEqual, 16)
+	test.That(t, u32(68), test.ShouldEqual, 8)
+
+	// the index points at each frame, relative to the movi list
+	moviEnd := aviMoviFourCCOffset + int(u32(aviMoviSizeOffset))
+	test.That(t, string(data[moviEnd:moviEnd+4]), test.ShouldEqual, "idx1")
+	test.That(t, int(u32(moviEnd+4)), test.ShouldEqual, 4*16)
+	for i := 0; i < 4; i++ {
+		entry := moviEnd + 8 + 16*i
+		test.That(t, string(data[entry:entry+4]), test.ShouldEqual, "00dc")
+		offset := aviMoviFourCCOffset + int(u32(entry+8))
+		size := int(u32(entry + 12))
+		test.That(t, string(data[offset:offset+4]), test.ShouldEqual, "00dc")
+		test.That(t, int(u32(offset+4)), test.ShouldEqual, size)
+		img, err := jpeg.Decode(bytes.NewReader(data[offset+8 : offset+8+size]))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 16, 8))
+	}
+}
+
+func TestAVIWriterSingleFrame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.avi")
+	w, err := newAVIWriter(path, 16, 8)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, w.WriteFrame(encodeJPEG(t, 16, 8), time.Now()), test.ShouldBeNil)
+	test.That(t, w.Close(), test.ShouldBeNil)
+
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, binary.LittleEndian.Uint32(data[aviMicroSecPerFrameOffset:]), test.ShouldEqual, microSecondsPerSecond)
+	test.That(t, binary.LittleEndian.Uint32(data[aviTotalFramesOffset:]), test.ShouldEqual, 1)
+
+	_, err = newAVIWriter(filepath.Join(t.TempDir(), "missing", "test.avi"), 16, 8)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,469 @@
+// Package videorecorder implements a camera that continuously records the video of another camera on the robot.
+// The video is written as MJPEG AVI files of bounded duration, so that a recording is split into segments that can
+// be synced by the data manager, by adding the output directory to its additional sync paths, and deleted once they
+// exceed the retention limits. Recording is started and stopped through DoCommand, and the frames of the few
+// seconds before it is started can be kept in memory so that the segment includes what led up to an incident.
+package videorecorder
+
+import (
+	"bytes"
+	"context"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/utils"
+)
+
+var model = resource.NewDefaultModel("video_recorder")
+
+const (
+	defaultFrameRate      = 10.
+	defaultSegmentSeconds = 60.
+	defaultJPEGQuality    = 75
+	segmentExt            = ".avi"
+	partialExt            = ".partial"
+	segmentTimeFormat     = "2006-01-02T15-04-05.000Z"
+)
+
+// DoCommand commands and their results.
+const (
+	Command         = "command"
+	CommandStart    = "start"
+	CommandStop     = "stop"
+	CommandStatus   = "status"
+	ResultRecording = "recording"
+	ResultSegment   = "segment"
+	ResultSegments  = "segments"
+)
+
+func init() {
+	resource.RegisterComponent(camera.Subtype, model, resource.Registration[camera.Camera, *Config]{
+		Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger golog.Logger,
+		) (camera.Camera, error) {
+			newConf, err := resource.NativeConfig[*Config](conf)
+			if err != nil {
+				return nil, err
+			}
+			src, err := camera.FromDependencies(deps, newConf.SourceCamera)
+			if err != nil {
+				return nil, errors.Wrapf(err, "no source camera (%s)", newConf.SourceCamera)
+			}
+			return newRecorder(conf.ResourceName(), src, newConf, logger)
+		},
+	})
+}
+
+// Config describes how to configure the recorder.
+type Config struct {
+	SourceCamera string `json:"source_camera"`
+	// OutputDirectory holds the completed segments. It defaults to a directory named after the camera in
+	// ~/.viam/recordings. Segments being written are kept next to it, in a directory with the same name followed
+	// by ".partial", so that only complete segments are synced.
+	OutputDirectory string `json:"output_dir,omitempty"`
+	// FrameRate is how many frames per second are recorded.
+	FrameRate      float64 `json:"frame_rate,omitempty"`
+	SegmentSeconds float64 `json:"segment_seconds,omitempty"`
+	// PreRollSeconds is how long before recording starts frames are kept for, to start the recording with.
+	PreRollSeconds float64 `json:"pre_roll_seconds,omitempty"`
+	JPEGQuality    int     `json:"jpeg_quality,omitempty"`
+	// MaxSegments and MaxStorageMB limit the completed segments kept in the output directory; the oldest ones
+	// are deleted first. Zero means no limit.
+	MaxSegments  int     `json:"retention_max_segments,omitempty"`
+	MaxStorageMB float64 `json:"retention_max_mb,omitempty"`
+	// RecordOnStart starts recording as soon as the camera is created.
+	RecordOnStart bool `json:"record_on_start,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid and returns the implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.SourceCamera == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "source_camera")
+	}
+	if conf.FrameRate < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("frame_rate cannot be negative"))
+	}
+	if conf.SegmentSeconds < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("segment_seconds cannot be negative"))
+	}
+	if conf.PreRollSeconds < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("pre_roll_seconds cannot be negative"))
+	}
+	if conf.JPEGQuality < 0 || conf.JPEGQuality > 100 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("jpeg_quality must be between 0 and 100"))
+	}
+	if conf.MaxSegments < 0 || conf.MaxStorageMB < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("retention limits cannot be negative"))
+	}
+	return []string{conf.SourceCamera}, nil
+}
+
+// A frame is a JPEG encoded image of the source camera.
+type frame struct {
+	data          []byte
+	width, height int
+	capturedAt    time.Time
+}
+
+// recorder is a camera that passes through its source camera while recording its video.
+type recorder struct {
+	resource.Named
+	resource.AlwaysRebuild
+	camera.VideoSource
+	outputDir       string
+	partialDir      string
+	frameInterval   time.Duration
+	segmentDuration time.Duration
+	preRoll         time.Duration
+	quality         int
+	maxSegments     int
+	maxBytes        int64
+	logger          golog.Logger
+
+	// mu guards whether the recorder is recording and the pre-roll, and segmentMu the segment being written, so
+	// that the status can be read while segments are written, completed and deleted. segmentMu is taken first.
+	mu           sync.Mutex
+	recording    bool
+	preRollQueue []frame
+
+	segmentMu   sync.Mutex
+	segment     *aviWriter
+	segmentName string
+
+	stream                  gostream.VideoStream
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newRecorder(name resource.Name, src camera.VideoSource, conf *Config, logger golog.Logger) (*recorder, error) {
+	outputDir := conf.OutputDirectory
+	if outputDir == "" {
+		outputDir = filepath.Join(os.Getenv("HOME"), ".viam", "recordings", name.ShortName())
+	}
+	frameRate := conf.FrameRate
+	if frameRate == 0 {
+		frameRate = defaultFrameRate
+	}
+	segmentSeconds := conf.SegmentSeconds
+	if segmentSeconds == 0 {
+		segmentSeconds = defaultSegmentSeconds
+	}
+	quality := conf.JPEGQuality
+	if quality == 0 {
+		quality = defaultJPEGQuality
+	}
+	r := &recorder{
+		Named:           name.AsNamed(),
+		VideoSource:     src,
+		outputDir:       outputDir,
+		partialDir:      filepath.Clean(outputDir) + partialExt,
+		frameInterval:   time.Duration(float64(time.Second) / frameRate),
+		segmentDuration: time.Duration(segmentSeconds * float64(time.Second)),
+		preRoll:         time.Duration(conf.PreRollSeconds * float64(time.Second)),
+		quality:         quality,
+		maxSegments:     conf.MaxSegments,
+		maxBytes:        int64(conf.MaxStorageMB * 1024 * 1024),
+		logger:          logger,
+	}
+	for _, dir := range []string{r.outputDir, r.partialDir} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	r.recording = conf.RecordOnStart
+
+	r.cancelCtx, r.cancel = context.WithCancel(context.Background())
+	r.activeBackgroundWorkers.Add(1)
+	goutils.ManagedGo(r.captureFrames, r.activeBackgroundWorkers.Done)
+	return r, nil
+}
+
+// captureFrames reads frames of the source camera at the frame rate while recording, or keeping frames for the
+// pre-roll.
+func (r *recorder) captureFrames() {
+	ticker := time.NewTicker(r.frameInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.cancelCtx.Done():
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		wantsFrames := r.recording || r.preRoll > 0
+		r.mu.Unlock()
+		if !wantsFrames {
+			r.closeStream(r.cancelCtx)
+			continue
+		}
+		f, err := r.nextFrame(r.cancelCtx)
+		if err != nil {
+			if r.cancelCtx.Err() == nil {
+				r.logger.Debugw("failed to get frame to record", "error", err)
+			}
+			continue
+		}
+		if err := r.addFrame(f); err != nil {
+			r.logger.Errorw("failed to record frame", "error", err)
+		}
+	}
+}
+
+// nextFrame reads the next image of the source camera and encodes it as a JPEG.
+func (r *recorder) nextFrame(ctx context.Context) (frame, error) {
+	if r.stream == nil {
+		stream, err := r.VideoSource.Stream(gostream.WithMIMETypeHint(ctx, utils.MimeTypeJPEG))
+		if err != nil {
+			return frame{}, err
+		}
+		r.stream = stream
+	}
+	img, release, err := r.stream.Next(ctx)
+	if err != nil {
+		return frame{}, err
+	}
+	capturedAt := time.Now()
+	if release != nil {
+		defer release()
+	}
+	f := frame{width: img.Bounds().Dx(), height: img.Bounds().Dy(), capturedAt: capturedAt}
+	if lazy, ok := img.(*rimage.LazyEncodedImage); ok && lazy.MIMEType() == utils.MimeTypeJPEG {
+		f.data = append([]byte(nil), lazy.RawData()...)
+		return f, nil
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: r.quality}); err != nil {
+		return frame{}, err
+	}
+	f.data = buf.Bytes()
+	return f, nil
+}
+
+func (r *recorder) closeStream(ctx context.Context) {
+	if r.stream == nil {
+		return
+	}
+	if err := r.stream.Close(ctx); err != nil {
+		r.logger.Debugw("failed to close stream", "error", err)
+	}
+	r.stream = nil
+}
+
+// addFrame records a frame, or keeps it for the pre-roll when not recording.
+func (r *recorder) addFrame(f frame) error {
+	r.segmentMu.Lock()
+	defer r.segmentMu.Unlock()
+	r.mu.Lock()
+	recording := r.recording
+	if !recording && r.preRoll > 0 {
+		r.preRollQueue = append(r.preRollQueue, f)
+		keepFrom := f.capturedAt.Add(-r.preRoll)
+		for len(r.preRollQueue) > 0 && r.preRollQueue[0].capturedAt.Before(keepFrom) {
+			r.preRollQueue = r.preRollQueue[1:]
+		}
+	}
+	r.mu.Unlock()
+	if !recording {
+		return nil
+	}
+	return r.writeFrame(f)
+}
+
+// writeFrame writes a frame to the current segment, starting a new one when it is full or the size of the frames
+// changed. It must be called with segmentMu held.
+func (r *recorder) writeFrame(f frame) error {
+	if r.segment != nil &&
+		(f.capturedAt.Sub(r.segment.first) >= r.segmentDuration || f.width != r.segment.width || f.height != r.segment.height) {
+		if _, err := r.finishSegment(); err != nil {
+			return err
+		}
+	}
+	if r.segment == nil {
+		name := r.Name().ShortName() + "_" + f.capturedAt.UTC().Format(segmentTimeFormat) + segmentExt
+		segment, err := newAVIWriter(filepath.Join(r.partialDir, name), f.width, f.height)
+		if err != nil {
+			return err
+		}
+		r.segment, r.segmentName = segment, name
+	}
+	return r.segment.WriteFrame(f.data, f.capturedAt)
+}
+
+// start starts recording, beginning with the frames kept for the pre-roll. If the pre-roll cannot be written, the
+// recorder is left stopped, without the partial segment.
+func (r *recorder) start() error {
+	r.segmentMu.Lock()
+	defer r.segmentMu.Unlock()
+	r.mu.Lock()
+	if r.recording {
+		r.mu.Unlock()
+		return nil
+	}
+	r.recording = true
+	queue := r.preRollQueue
+	r.preRollQueue = nil
+	r.mu.Unlock()
+
+	for _, f := range queue {
+		if err := r.writeFrame(f); err != nil {
+			r.mu.Lock()
+			r.recording = false
+			r.mu.Unlock()
+			return multierr.Combine(err, r.discardSegment())
+		}
+	}
+	return nil
+}
+
+// stop stops recording and returns the path of the last segment, if any.
+func (r *recorder) stop() (string, error) {
+	r.segmentMu.Lock()
+	defer r.segmentMu.Unlock()
+	r.mu.Lock()
+	r.recording = false
+	r.mu.Unlock()
+	return r.finishSegment()
+}
+
+// discardSegment closes the current segment and deletes it. It must be called with segmentMu held.
+func (r *recorder) discardSegment() error {
+	if r.segment == nil {
+		return nil
+	}
+	segment, partialPath := r.segment, filepath.Join(r.partialDir, r.segmentName)
+	r.segment, r.segmentName = nil, ""
+	err := segment.Close()
+	if removeErr := os.Remove(partialPath); removeErr != nil && !os.IsNotExist(removeErr) {
+		err = multierr.Combine(err, removeErr)
+	}
+	return err
+}
+
+// finishSegment closes the current segment, moves it to the output directory and enforces the retention limits.
+// It returns the path of the segment. It must be called with segmentMu held.
+func (r *recorder) finishSegment() (string, error) {
+	if r.segment == nil {
+		return "", nil
+	}
+	segment, partialPath := r.segment, filepath.Join(r.partialDir, r.segmentName)
+	path := filepath.Join(r.outputDir, r.segmentName)
+	r.segment, r.segmentName = nil, ""
+	if err := segment.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(partialPath, path); err != nil {
+		return "", err
+	}
+	return path, r.enforceRetention()
+}
+
+// segments returns the paths of the completed segments, from oldest to newest.
+func (r *recorder) segments() ([]string, error) {
+	entries, err := os.ReadDir(r.outputDir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := r.Name().ShortName() + "_"
+	var paths []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), prefix) && filepath.Ext(entry.Name()) == segmentExt {
+			paths = append(paths, filepath.Join(r.outputDir, entry.Name()))
+		}
+	}
+	// names end with the time of their first frame
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// enforceRetention deletes the oldest segments that exceed the retention limits, always keeping the newest one.
+func (r *recorder) enforceRetention() error {
+	if r.maxSegments == 0 && r.maxBytes == 0 {
+		return nil
+	}
+	paths, err := r.segments()
+	if err != nil {
+		return err
+	}
+	sizes := make([]int64, len(paths))
+	var total int64
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			// the data manager may have synced and deleted it
+			continue
+		}
+		sizes[i] = info.Size()
+		total += sizes[i]
+	}
+	for i := 0; i < len(paths)-1; i++ {
+		overCount := r.maxSegments > 0 && len(paths)-i > r.maxSegments
+		overSize := r.maxBytes > 0 && total > r.maxBytes
+		if !overCount && !overSize {
+			break
+		}
+		if err := os.Remove(paths[i]); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= sizes[i]
+	}
+	return nil
+}
+
+// DoCommand starts and stops recording, and reports the status of the recorder.
+func (r *recorder) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	switch cmd[Command] {
+	case CommandStart:
+		if err := r.start(); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{ResultRecording: true}, nil
+	case CommandStop:
+		path, err := r.stop()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{ResultRecording: false, ResultSegment: path}, nil
+	case CommandStatus:
+		r.mu.Lock()
+		recording := r.recording
+		r.mu.Unlock()
+		paths, err := r.segments()
+		if err != nil {
+			return nil, err
+		}
+		segments := make([]interface{}, 0, len(paths))
+		for _, path := range paths {
+			segments = append(segments, path)
+		}
+		return map[string]interface{}{ResultRecording: recording, ResultSegments: segments}, nil
+	default:
+		return nil, errors.Errorf("no such command: %v", cmd[Command])
+	}
+}
+
+// Close stops recording, completing the current segment. The source camera is left open.
+func (r *recorder) Close(ctx context.Context) error {
+	r.cancel()
+	r.activeBackgroundWorkers.Wait()
+	r.closeStream(ctx)
+	_, err := r.stop()
+	return err
+}
@@ -0,0 +1,223 @@
+package videorecorder
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/rimage"
+)
+
+type staticReader struct {
+	img image.Image
+}
+
+func (s *staticReader) Read(ctx context.Context) (image.Image, func(), error) {
+	return s.img, func() {}, nil
+}
+
+func (s *staticReader) Close(ctx context.Context) error {
+	return nil
+}
+
+func newTestRecorder(t *testing.T, conf *Config) (*recorder, camera.VideoSource) {
+	t.Helper()
+	src, err := camera.NewVideoSourceFromReader(context.Background(), &staticReader{rimage.NewImage(32, 16)}, nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	if conf.OutputDirectory == "" {
+		conf.OutputDirectory = filepath.Join(t.TempDir(), "recordings")
+	}
+	r, err := newRecorder(camera.Named("rec"), src, conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return r, src
+}
+
+func testFrame(t *testing.T, at time.Time) frame {
+	t.Helper()
+	return frame{data: encodeJPEG(t, 32, 16), width: 32, height: 16, capturedAt: at}
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+
+	conf.SourceCamera = "cam"
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+
+	for _, bad := range []*Config{
+		{SourceCamera: "cam", FrameRate: -1},
+		{SourceCamera: "cam", SegmentSeconds: -1},
+		{SourceCamera: "cam", PreRollSeconds: -1},
+		{SourceCamera: "cam", JPEGQuality: 101},
+		{SourceCamera: "cam", MaxSegments: -1},
+	} {
+		_, err := bad.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestRecorderSegments(t *testing.T) {
+	// frames are added directly, so that the background capture doesn't record anything
+	r, src := newTestRecorder(t, &Config{SourceCamera: "cam", FrameRate: 0.001, SegmentSeconds: 1, PreRollSeconds: 0.5})
+	defer func() {
+		test.That(t, r.Close(context.Background()), test.ShouldBeNil)
+		test.That(t, src.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	// frames before the pre-roll are dropped
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		test.That(t, r.addFrame(testFrame(t, start.Add(time.Duration(i)*100*time.Millisecond))), test.ShouldBeNil)
+	}
+	test.That(t, r.preRollQueue, test.ShouldHaveLength, 6)
+	segments, err := r.segments()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, segments, test.ShouldBeEmpty)
+
+	// recording starts with the pre-roll, and is split into segments
+	test.That(t, r.start(), test.ShouldBeNil)
+	test.That(t, r.segment.Frames(), test.ShouldEqual, 6)
+	test.That(t, r.segment.first, test.ShouldEqual, start.Add(400*time.Millisecond))
+	for i := 10; i < 25; i++ {
+		test.That(t, r.addFrame(testFrame(t, start.Add(time.Duration(i)*100*time.Millisecond))), test.ShouldBeNil)
+	}
+	segments, err = r.segments()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, segments, test.ShouldHaveLength, 2)
+
+	// segments being written are kept out of the output directory
+	partial := filepath.Join(r.partialDir, r.segmentName)
+	_, err = os.Stat(partial)
+	test.That(t, err, test.ShouldBeNil)
+
+	path, err := r.stop()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filepath.Dir(path), test.ShouldEqual, r.outputDir)
+	_, err = os.Stat(partial)
+	test.That(t, os.IsNotExist(err), test.ShouldBeTrue)
+	segments, err = r.segments()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, segments, test.ShouldHaveLength, 3)
+	test.That(t, segments[2], test.ShouldEqual, path)
+	test.That(t, filepath.Base(segments[0]), test.ShouldEqual,
+		"rec_"+start.Add(400*time.Millisecond).UTC().Format(segmentTimeFormat)+".avi")
+
+	// stopping again does nothing
+	path, err = r.stop()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, path, test.ShouldBeEmpty)
+}
+
+func TestRecorderStartFailure(t *testing.T) {
+	r, src := newTestRecorder(t, &Config{SourceCamera: "cam", FrameRate: 0.001, PreRollSeconds: 1})
+	defer func() {
+		test.That(t, r.Close(context.Background()), test.ShouldBeNil)
+		test.That(t, src.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	// a pre-roll that cannot be written leaves the recorder stopped
+	start := time.Now()
+	test.That(t, r.addFrame(testFrame(t, start)), test.ShouldBeNil)
+	test.That(t, os.Remove(r.partialDir), test.ShouldBeNil)
+	test.That(t, r.start(), test.ShouldNotBeNil)
+	resp, err := r.DoCommand(context.Background(), map[string]interface{}{Command: CommandStatus})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[ResultRecording], test.ShouldBeFalse)
+	test.That(t, r.segment, test.ShouldBeNil)
+
+	// and it can start again once the problem is solved
+	test.That(t, os.Mkdir(r.partialDir, 0o700), test.ShouldBeNil)
+	test.That(t, r.start(), test.ShouldBeNil)
+	test.That(t, r.addFrame(testFrame(t, start.Add(100*time.Millisecond))), test.ShouldBeNil)
+	path, err := r.stop()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, path, test.ShouldNotBeEmpty)
+}
+
+func TestRecorderRetention(t *testing.T) {
+	r, src := newTestRecorder(t, &Config{SourceCamera: "cam", FrameRate: 0.001, SegmentSeconds: 1, MaxSegments: 2})
+	defer func() {
+		test.That(t, r.Close(context.Background()), test.ShouldBeNil)
+		test.That(t, src.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	test.That(t, r.start(), test.ShouldBeNil)
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		test.That(t, r.addFrame(testFrame(t, start.Add(time.Duration(i)*100*time.Millisecond))), test.ShouldBeNil)
+	}
+	_, err := r.stop()
+	test.That(t, err, test.ShouldBeNil)
+	segments, err := r.segments()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, segments, test.ShouldHaveLength, 2)
+	test.That(t, filepath.Base(segments[1]), test.ShouldEqual,
+		"rec_"+start.Add(4*time.Second).UTC().Format(segmentTimeFormat)+".avi")
+
+	// a storage limit smaller than a segment keeps only the newest one
+	r.maxSegments = 0
+	r.maxBytes = 1
+	test.That(t, r.enforceRetention(), test.ShouldBeNil)
+	segments, err = r.segments()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, segments, test.ShouldHaveLength, 1)
+}
+
+func TestRecorderDoCommand(t *testing.T) {
+	r, src := newTestRecorder(t, &Config{SourceCamera: "cam", FrameRate: 50})
+	defer func() {
+		test.That(t, r.Close(context.Background()), test.ShouldBeNil)
+		test.That(t, src.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	// the source camera is passed through
+	img, _, err := camera.ReadImage(context.Background(), r)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds().Dx(), test.ShouldEqual, 32)
+
+	resp, err := r.DoCommand(context.Background(), map[string]interface{}{Command: CommandStatus})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[ResultRecording], test.ShouldBeFalse)
+	test.That(t, resp[ResultSegments], test.ShouldBeEmpty)
+
+	resp, err = r.DoCommand(context.Background(), map[string]interface{}{Command: CommandStart})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[ResultRecording], test.ShouldBeTrue)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		r.segmentMu.Lock()
+		defer r.segmentMu.Unlock()
+		frames := 0
+		if r.segment != nil {
+			frames = r.segment.Frames()
+		}
+		test.That(tb, frames, test.ShouldBeGreaterThan, 2)
+	})
+
+	resp, err = r.DoCommand(context.Background(), map[string]interface{}{Command: CommandStop})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[ResultRecording], test.ShouldBeFalse)
+	path, ok := resp[ResultSegment].(string)
+	test.That(t, ok, test.ShouldBeTrue)
+	info, err := os.Stat(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, info.Size(), test.ShouldBeGreaterThan, aviHeaderSize)
+
+	resp, err = r.DoCommand(context.Background(), map[string]interface{}{Command: CommandStatus})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp[ResultSegments], test.ShouldResemble, []interface{}{path})
+
+	_, err = r.DoCommand(context.Background(), map[string]interface{}{Command: "rewind"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no such command")
+}
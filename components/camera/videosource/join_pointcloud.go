@@ -6,6 +6,8 @@ import (
 	"image"
 	"math"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
@@ -17,6 +19,7 @@ import (
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/transform"
 	"go.viam.com/rdk/robot"
 	"go.viam.com/rdk/robot/framesystem"
@@ -26,6 +29,10 @@ import (
 
 const numThreadsVideoSource = 8 // This should be a param
 
+// defaultMaxCaptureSkew is how far apart the images of a source camera may be captured, or its pose read after them,
+// when no max_capture_skew_ms is configured.
+const defaultMaxCaptureSkew = 50 * time.Millisecond
+
 var modelJoinPC = resource.NewDefaultModel("join_pointclouds")
 
 func init() {
@@ -66,6 +73,9 @@ type JoinConfig struct {
 	CameraParameters     *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters,omitempty"`
 	DistortionParameters *transform.BrownConrady            `json:"distortion_parameters,omitempty"`
 	Debug                bool                               `json:"debug,omitempty"`
+	// MaxCaptureSkewMs is how many milliseconds apart the color and depth images of a source camera may be captured
+	// for them to be projected together.
+	MaxCaptureSkewMs int `json:"max_capture_skew_ms,omitempty"`
 }
 
 // Validate ensures all parts of the config are valid.
@@ -74,6 +84,9 @@ func (cfg *JoinConfig) Validate(path string) ([]string, error) {
 	if len(cfg.SourceCameras) == 0 {
 		return nil, utils.NewConfigValidationFieldRequiredError(path, "source_cameras")
 	}
+	if cfg.MaxCaptureSkewMs < 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("max_capture_skew_ms cannot be negative"))
+	}
 	deps = append(deps, cfg.SourceCameras...)
 	return deps, nil
 }
@@ -106,12 +119,17 @@ type joinPointCloudSource struct {
 	resource.AlwaysRebuild
 	sourceCameras []camera.Camera
 	sourceNames   []string
+	// projectImages holds whether to project the point cloud of each source camera from its color and depth
+	// images, which are captured together, rather than getting the point cloud of the camera.
+	projectImages []atomic.Bool
 	targetName    string
 	robot         robot.Robot
 	mergeMethod   MergeMethodType
 	logger        golog.Logger
 	debug         bool
 	closeness     float64
+	// maxCaptureSkew is how far apart the color and depth images of a source camera may be captured.
+	maxCaptureSkew time.Duration
 }
 
 // newJoinPointCloudSource creates a camera that combines point cloud sources into one point cloud in the
@@ -129,6 +147,7 @@ func newJoinPointCloudSource(
 	// frame to merge from
 	joinSource.sourceCameras = make([]camera.Camera, len(conf.SourceCameras))
 	joinSource.sourceNames = make([]string, len(conf.SourceCameras))
+	joinSource.projectImages = make([]atomic.Bool, len(conf.SourceCameras))
 	for i, source := range conf.SourceCameras {
 		joinSource.sourceNames[i] = source
 		camSource, err := camera.FromRobot(r, source)
@@ -136,11 +155,17 @@ func newJoinPointCloudSource(
 			return nil, fmt.Errorf("no camera source called (%s): %w", source, err)
 		}
 		joinSource.sourceCameras[i] = camSource
+		_, isImagesSource := camSource.(camera.ImagesSource)
+		joinSource.projectImages[i].Store(isImagesSource)
 	}
 	// frame to merge to
 	joinSource.targetName = conf.TargetFrame
 	joinSource.robot = r
 	joinSource.closeness = conf.Closeness
+	joinSource.maxCaptureSkew = defaultMaxCaptureSkew
+	if conf.MaxCaptureSkewMs > 0 {
+		joinSource.maxCaptureSkew = time.Duration(conf.MaxCaptureSkewMs) * time.Millisecond
+	}
 
 	joinSource.logger = l
 	joinSource.debug = conf.Debug
@@ -190,32 +215,22 @@ func (jpcs *joinPointCloudSource) NextPointCloudNaive(ctx context.Context) (poin
 		return nil, err
 	}
 
-	inputs, err := jpcs.initializeInputs(ctx, fs)
-	if err != nil {
-		return nil, err
-	}
 	cloudFuncs := make([]pointcloud.CloudAndOffsetFunc, len(jpcs.sourceCameras))
-	for i, cam := range jpcs.sourceCameras {
+	for i := range jpcs.sourceCameras {
 		iCopy := i
-		camCopy := cam
 		pcSrc := func(ctx context.Context) (pointcloud.PointCloud, spatialmath.Pose, error) {
 			ctx, span := trace.StartSpan(ctx, "camera::joinPointCloudSource::NextPointCloud::"+jpcs.sourceNames[iCopy]+"-NextPointCloud")
 			defer span.End()
-			var framePose spatialmath.Pose
-			if jpcs.sourceNames[iCopy] != jpcs.targetName {
-				sourceFrame := referenceframe.NewPoseInFrame(jpcs.sourceNames[iCopy], spatialmath.NewZeroPose())
-				theTransform, err := fs.Transform(inputs, sourceFrame, jpcs.targetName)
-				if err != nil {
-					return nil, nil, err
-				}
-				framePose = theTransform.(*referenceframe.PoseInFrame).Pose()
-			}
-			pc, err := camCopy.NextPointCloud(ctx)
+			pc, capturedAt, err := jpcs.sourcePointCloud(ctx, iCopy)
 			if err != nil {
 				return nil, nil, err
 			}
-			if pc == nil {
-				return nil, nil, errors.Errorf("camera %q returned a nil point cloud", jpcs.sourceNames[iCopy])
+			if jpcs.sourceNames[iCopy] == jpcs.targetName {
+				return pc, nil, nil
+			}
+			framePose, err := jpcs.sourcePose(ctx, fs, iCopy, capturedAt)
+			if err != nil {
+				return nil, nil, err
 			}
 			return pc, framePose, nil
 		}
@@ -234,11 +249,6 @@ func (jpcs *joinPointCloudSource) NextPointCloudICP(ctx context.Context) (pointc
 		return nil, err
 	}
 
-	inputs, err := jpcs.initializeInputs(ctx, fs)
-	if err != nil {
-		return nil, err
-	}
-
 	targetIndex := 0
 
 	for i, camName := range jpcs.sourceNames {
@@ -248,7 +258,7 @@ func (jpcs *joinPointCloudSource) NextPointCloudICP(ctx context.Context) (pointc
 		}
 	}
 
-	targetPointCloud, err := jpcs.sourceCameras[targetIndex].NextPointCloud(ctx)
+	targetPointCloud, _, err := jpcs.sourcePointCloud(ctx, targetIndex)
 	if err != nil {
 		return nil, err
 	}
@@ -259,19 +269,18 @@ func (jpcs *joinPointCloudSource) NextPointCloudICP(ctx context.Context) (pointc
 			continue
 		}
 
-		pcSrc, err := jpcs.sourceCameras[i].NextPointCloud(ctx)
+		pcSrc, capturedAt, err := jpcs.sourcePointCloud(ctx, i)
 		if err != nil {
 			return nil, err
 		}
 
-		sourceFrame := referenceframe.NewPoseInFrame(jpcs.sourceNames[i], spatialmath.NewZeroPose())
-		theTransform, err := fs.Transform(inputs, sourceFrame, jpcs.targetName)
+		sourcePose, err := jpcs.sourcePose(ctx, fs, i, capturedAt)
 		if err != nil {
 			return nil, err
 		}
 
 		registeredPointCloud, info, err := pointcloud.RegisterPointCloudICP(pcSrc, finalPointCloud,
-			theTransform.(*referenceframe.PoseInFrame).Pose(), jpcs.debug, numThreadsVideoSource)
+			sourcePose, jpcs.debug, numThreadsVideoSource)
 		if err != nil {
 			return nil, err
 		}
@@ -304,6 +313,110 @@ func (jpcs *joinPointCloudSource) NextPointCloudICP(ctx context.Context) (pointc
 	return finalPointCloud, nil
 }
 
+// sourcePointCloud returns the next point cloud of the source camera at index i, along with when it was captured if
+// the camera says so. Cameras that capture color and depth images together are projected from a single set of images,
+// so that both come from the same instant; sets whose images were captured too far apart are rejected, since the
+// camera may have moved in between.
+func (jpcs *joinPointCloudSource) sourcePointCloud(ctx context.Context, i int) (pointcloud.PointCloud, time.Time, error) {
+	cam := jpcs.sourceCameras[i]
+	if jpcs.projectImages[i].Load() {
+		//nolint:forcetypeassert
+		imgs, err := cam.(camera.ImagesSource).Images(ctx)
+		if err == nil {
+			var col, depth camera.NamedImage
+			col, depth, err = findColorAndDepth(imgs)
+			if err == nil {
+				if skew := depth.CapturedAt.Sub(col.CapturedAt).Abs(); skew > jpcs.maxCaptureSkew {
+					return nil, time.Time{}, errors.Errorf(
+						"color and depth images of camera %q were captured %v apart, more than %v",
+						jpcs.sourceNames[i], skew, jpcs.maxCaptureSkew)
+				}
+				var pc pointcloud.PointCloud
+				pc, err = projectImages(ctx, cam, col, depth)
+				if err == nil {
+					return pc, latest(col.CapturedAt, depth.CapturedAt), nil
+				}
+			}
+			// the camera will not have both images or a projector next time either, so stop capturing them
+			jpcs.projectImages[i].Store(false)
+		}
+		if jpcs.debug {
+			jpcs.logger.Debugf("cannot project images of camera %q, getting its point cloud: %v", jpcs.sourceNames[i], err)
+		}
+	}
+
+	pc, err := cam.NextPointCloud(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if pc == nil {
+		return nil, time.Time{}, errors.Errorf("camera %q returned a nil point cloud", jpcs.sourceNames[i])
+	}
+	return pc, time.Time{}, nil
+}
+
+// sourcePose returns the pose of the source camera at index i in the target frame. The inputs of the frame system are
+// read right after the camera captures its point cloud rather than once for all of the cameras, so that each cloud is
+// placed where its camera was when it was captured even while the robot moves. If the inputs could only be read well
+// after the capture, the cloud may be misplaced, which is logged.
+func (jpcs *joinPointCloudSource) sourcePose(
+	ctx context.Context,
+	fs referenceframe.FrameSystem,
+	i int,
+	capturedAt time.Time,
+) (spatialmath.Pose, error) {
+	inputs, err := jpcs.initializeInputs(ctx, fs)
+	if err != nil {
+		return nil, err
+	}
+	if !capturedAt.IsZero() {
+		if lag := time.Since(capturedAt); lag > jpcs.maxCaptureSkew {
+			jpcs.logger.Warnf("pose of camera %q was read %v after it captured its images, so its points may be misplaced",
+				jpcs.sourceNames[i], lag)
+		}
+	}
+	sourceFrame := referenceframe.NewPoseInFrame(jpcs.sourceNames[i], spatialmath.NewZeroPose())
+	theTransform, err := fs.Transform(inputs, sourceFrame, jpcs.targetName)
+	if err != nil {
+		return nil, err
+	}
+	return theTransform.(*referenceframe.PoseInFrame).Pose(), nil
+}
+
+// findColorAndDepth returns the color and depth images from a set of images.
+func findColorAndDepth(imgs []camera.NamedImage) (camera.NamedImage, camera.NamedImage, error) {
+	col, ok := camera.FindNamedImage(imgs, string(camera.ColorStream))
+	if !ok {
+		return camera.NamedImage{}, camera.NamedImage{}, errors.New("camera has no color image")
+	}
+	depth, ok := camera.FindNamedImage(imgs, string(camera.DepthStream))
+	if !ok {
+		return camera.NamedImage{}, camera.NamedImage{}, errors.New("camera has no depth image")
+	}
+	return col, depth, nil
+}
+
+// projectImages projects the color and depth images of a camera to a point cloud.
+func projectImages(ctx context.Context, cam camera.Camera, col, depth camera.NamedImage) (pointcloud.PointCloud, error) {
+	proj, err := cam.Projector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dm, err := rimage.ConvertImageToDepthMap(ctx, depth.Image)
+	if err != nil {
+		return nil, err
+	}
+	return proj.RGBDToPointCloud(rimage.ConvertImage(col.Image), dm)
+}
+
+// latest returns the later of two times.
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
 // initalizeInputs gets all the input positions for the robot components in order to calculate the frame system offsets.
 func (jpcs *joinPointCloudSource) initializeInputs(
 	ctx context.Context,
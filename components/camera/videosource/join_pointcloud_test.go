@@ -5,6 +5,7 @@ import (
 	"image"
 	"image/color"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"go.viam.com/rdk/pointcloud"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/rimage/transform"
 	"go.viam.com/rdk/robot"
 	framesystemparts "go.viam.com/rdk/robot/framesystem/parts"
@@ -457,3 +459,77 @@ func TestMultiPointCloudICP(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, pc, test.ShouldNotBeNil)
 }
+
+func TestJoinPointCloudImages(t *testing.T) {
+	r := makeFakeRobot(t)
+	cam1, err := camera.FromRobot(r, "cam1")
+	test.That(t, err, test.ShouldBeNil)
+	injectCam := cam1.(*inject.Camera)
+
+	// a camera with color and depth images is projected from them
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 2, color.NRGBA{255, 0, 0, 255})
+	dm := rimage.NewEmptyDepthMap(4, 4)
+	dm.Set(1, 2, 100)
+	imagesCalls := 0
+	capturedAt := time.Now()
+	depthCapturedAt := capturedAt.Add(10 * time.Millisecond)
+	injectCam.ImagesFunc = func(ctx context.Context) ([]camera.NamedImage, error) {
+		imagesCalls++
+		return []camera.NamedImage{
+			{Image: img, SourceName: "color", CapturedAt: capturedAt, FrameID: 1},
+			{Image: dm, SourceName: "depth", CapturedAt: depthCapturedAt, FrameID: 1},
+		}, nil
+	}
+	intrinsics := &transform.PinholeCameraIntrinsics{Width: 4, Height: 4, Fx: 1, Fy: 1, Ppx: 2, Ppy: 2}
+	injectCam.ProjectorFunc = func(ctx context.Context) (transform.Projector, error) {
+		return intrinsics, nil
+	}
+
+	conf := &JoinConfig{SourceCameras: []string{"cam1", "cam2"}, TargetFrame: "cam1"}
+	joinedCam, err := newJoinPointCloudSource(context.Background(), r, utils.Logger, camera.Named("foo"), conf)
+	test.That(t, err, test.ShouldBeNil)
+	defer joinedCam.Close(context.Background())
+	src := &joinPointCloudSource{
+		sourceCameras:  []camera.Camera{injectCam},
+		sourceNames:    []string{"cam1"},
+		projectImages:  make([]atomic.Bool, 1),
+		logger:         utils.Logger,
+		maxCaptureSkew: defaultMaxCaptureSkew,
+	}
+	src.projectImages[0].Store(true)
+	pc, pcCapturedAt, err := src.sourcePointCloud(context.Background(), 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, imagesCalls, test.ShouldEqual, 1)
+	test.That(t, pcCapturedAt, test.ShouldEqual, depthCapturedAt)
+	x, y, z := intrinsics.PixelToPoint(1, 2, 100)
+	d, ok := pc.At(x, y, z)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Color(), test.ShouldResemble, &color.NRGBA{255, 0, 0, 255})
+
+	// sets of images captured too far apart are rejected, but the camera is still projected from its images next time
+	depthCapturedAt = capturedAt.Add(2 * defaultMaxCaptureSkew)
+	_, _, err = src.sourcePointCloud(context.Background(), 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "captured 100ms apart")
+	test.That(t, src.projectImages[0].Load(), test.ShouldBeTrue)
+	test.That(t, imagesCalls, test.ShouldEqual, 2)
+
+	// cameras whose images cannot be projected fall back to their point clouds, without capturing images again
+	injectCam.ImagesFunc = func(ctx context.Context) ([]camera.NamedImage, error) {
+		imagesCalls++
+		return []camera.NamedImage{{Image: img, SourceName: "color", FrameID: 2}}, nil
+	}
+	for i := 0; i < 2; i++ {
+		pc, pcCapturedAt, err = src.sourcePointCloud(context.Background(), 0)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, pcCapturedAt.IsZero(), test.ShouldBeTrue)
+		_, ok = pc.At(1, 0, 0)
+		test.That(t, ok, test.ShouldBeTrue)
+	}
+	test.That(t, imagesCalls, test.ShouldEqual, 3)
+
+	merged, err := joinedCam.NextPointCloud(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, merged.Size(), test.ShouldEqual, 2)
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/edaniels/golog"
@@ -353,6 +354,8 @@ type monitoredWebcam struct {
 	underlyingSource gostream.VideoSource
 	exposedSwapper   gostream.HotSwappableVideoSource
 	exposedProjector camera.VideoSource
+	// frameID numbers the images returned by Images.
+	frameID atomic.Uint64
 
 	// this is returned to us as a label in mediadevices but our config
 	// treats it as a video path.
@@ -511,6 +514,25 @@ func (c *monitoredWebcam) NextPointCloud(ctx context.Context) (pointcloud.PointC
 	return c.exposedProjector.NextPointCloud(ctx)
 }
 
+// Images returns the next image of the webcam, which has only the one sensor, stamped with when it was read and
+// numbered so that callers can tell repeated frames apart.
+func (c *monitoredWebcam) Images(ctx context.Context) ([]camera.NamedImage, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if err := c.ensureActive(); err != nil {
+		return nil, err
+	}
+	imgs, err := camera.Images(ctx, c.exposedProjector)
+	if err != nil {
+		return nil, err
+	}
+	frameID := c.frameID.Add(1)
+	for i := range imgs {
+		imgs[i].FrameID = frameID
+	}
+	return imgs, nil
+}
+
 func (c *monitoredWebcam) Properties(ctx context.Context) (camera.Properties, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -0,0 +1,207 @@
+// Package fiducial implements a pose tracker that finds ArUco-style fiducial markers in the images of a camera and
+// returns their poses in the frame of that camera.
+package fiducial
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+// ModelName is the name of the fiducial pose tracker model.
+var ModelName = resource.NewDefaultModel("fiducial")
+
+const (
+	arucoOriginal = "aruco_original"
+	custom        = "custom"
+)
+
+// Config is the config of a fiducial pose tracker.
+type Config struct {
+	Camera string `json:"camera"`
+	// Dictionary is the family of the markers, either aruco_original or custom for the markers whose codes are given.
+	Dictionary string `json:"dictionary,omitempty"`
+	// MarkerBits and Codes describe custom markers, as described by fiducial.NewDictionary. The codes are strings so
+	// that they can be written in hexadecimal, with a 0x prefix.
+	MarkerBits   int      `json:"marker_bits,omitempty"`
+	Codes        []string `json:"codes,omitempty"`
+	MaxBitErrors int      `json:"max_bit_errors,omitempty"`
+	// MarkerSizeMM is the length of the sides of the black squares of the markers, unless they are given for the
+	// ID of a marker in MarkerSizesMM.
+	MarkerSizeMM  float64            `json:"marker_size_mm"`
+	MarkerSizesMM map[string]float64 `json:"marker_sizes_mm,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *Config) Validate(path string) ([]string, error) {
+	if cfg.Camera == "" {
+		return nil, utils.NewConfigValidationFieldRequiredError(path, "camera")
+	}
+	if cfg.MarkerSizeMM <= 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("marker_size_mm must be positive"))
+	}
+	for id, size := range cfg.MarkerSizesMM {
+		if _, err := strconv.Atoi(id); err != nil {
+			return nil, utils.NewConfigValidationError(path, errors.Errorf("marker_sizes_mm has a size for %q, which is not a marker ID", id))
+		}
+		if size <= 0 {
+			return nil, utils.NewConfigValidationError(path, errors.Errorf("size of marker %s must be positive", id))
+		}
+	}
+	if cfg.MaxBitErrors < 0 {
+		return nil, utils.NewConfigValidationError(path, errors.New("max_bit_errors cannot be negative"))
+	}
+	if _, err := cfg.dictionary(); err != nil {
+		return nil, utils.NewConfigValidationError(path, err)
+	}
+	return []string{cfg.Camera}, nil
+}
+
+func (cfg *Config) dictionary() (*fiducial.Dictionary, error) {
+	switch cfg.Dictionary {
+	case "", arucoOriginal:
+		return fiducial.ArucoOriginal(), nil
+	case custom:
+		codes := make([]uint64, 0, len(cfg.Codes))
+		for _, c := range cfg.Codes {
+			code, err := strconv.ParseUint(c, 0, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid code %q", c)
+			}
+			codes = append(codes, code)
+		}
+		return fiducial.NewDictionary(custom, cfg.MarkerBits, codes)
+	default:
+		return nil, errors.Errorf("unknown dictionary %q, must be %s or %s", cfg.Dictionary, arucoOriginal, custom)
+	}
+}
+
+func init() {
+	resource.RegisterComponent(
+		posetracker.Subtype,
+		ModelName,
+		resource.Registration[posetracker.PoseTracker, *Config]{Constructor: NewPoseTracker},
+	)
+}
+
+type fiducialTracker struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+
+	camera     camera.Camera
+	cameraName string
+	detector   *fiducial.Detector
+	sizes      map[int]float64
+	size       float64
+	logger     golog.Logger
+}
+
+// NewPoseTracker returns a pose tracker of the fiducial markers seen by a camera.
+func NewPoseTracker(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger golog.Logger,
+) (posetracker.PoseTracker, error) {
+	newConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	cam, err := camera.FromDependencies(deps, newConf.Camera)
+	if err != nil {
+		return nil, err
+	}
+	dictionary, err := newConf.dictionary()
+	if err != nil {
+		return nil, err
+	}
+	detector := fiducial.NewDetector(dictionary)
+	detector.MaxBitErrors = newConf.MaxBitErrors
+
+	sizes := make(map[int]float64, len(newConf.MarkerSizesMM))
+	for id, size := range newConf.MarkerSizesMM {
+		n, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid marker ID %q", id)
+		}
+		sizes[n] = size
+	}
+
+	return &fiducialTracker{
+		Named:      conf.ResourceName().AsNamed(),
+		camera:     cam,
+		cameraName: newConf.Camera,
+		detector:   detector,
+		sizes:      sizes,
+		size:       newConf.MarkerSizeMM,
+		logger:     logger,
+	}, nil
+}
+
+// Poses returns the poses of the markers seen by the camera, in its frame, named after their IDs. Only the markers
+// with the given IDs are returned, unless none are given.
+func (ft *fiducialTracker) Poses(
+	ctx context.Context,
+	bodyNames []string,
+	extra map[string]interface{},
+) (posetracker.BodyToPoseInFrame, error) {
+	props, err := ft.camera.Properties(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if props.IntrinsicParams == nil {
+		return nil, errors.Errorf("camera %q needs intrinsic parameters to estimate the poses of markers", ft.cameraName)
+	}
+	cam := &fiducial.Camera{Intrinsics: props.IntrinsicParams, Distortion: props.DistortionParams}
+
+	img, release, err := camera.ReadImage(ctx, ft.camera)
+	if err != nil {
+		return nil, err
+	}
+	detections := ft.detector.Detect(img)
+	release()
+
+	wanted := make(map[string]bool, len(bodyNames))
+	for _, name := range bodyNames {
+		wanted[name] = true
+	}
+	poses := posetracker.BodyToPoseInFrame{}
+	errs := map[string]float64{}
+	for _, det := range detections {
+		name := strconv.Itoa(det.ID)
+		if len(wanted) > 0 && !wanted[name] {
+			continue
+		}
+		size, ok := ft.sizes[det.ID]
+		if !ok {
+			size = ft.size
+		}
+		pose, rms, err := fiducial.EstimatePose(det.Corners, size, cam)
+		if err != nil {
+			ft.logger.Debugw("could not estimate pose of marker", "id", det.ID, "error", err)
+			continue
+		}
+		// the same marker may be seen more than once, like when it was printed twice, so keep the best estimate
+		if prev, ok := errs[name]; ok && prev <= rms {
+			continue
+		}
+		errs[name] = rms
+		poses[name] = referenceframe.NewPoseInFrame(ft.cameraName, pose)
+	}
+	return poses, nil
+}
+
+// Readings returns the poses of all the markers seen by the camera.
+func (ft *fiducialTracker) Readings(ctx context.Context, extra map[string]interface{}) (map[string]interface{}, error) {
+	return posetracker.Readings(ctx, ft)
+}
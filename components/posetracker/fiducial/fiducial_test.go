@@ -0,0 +1,146 @@
+package fiducial
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/posetracker"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+var intrinsics = &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240}
+
+// facing turns markers around so that they face the camera.
+var facing = &spatialmath.R4AA{Theta: math.Pi, RX: 1}
+
+// newTestCamera returns a camera seeing the markers of the original ArUco dictionary with the given IDs and sizes at
+// the given poses.
+func newTestCamera(t *testing.T, ids []int, sizes []float64, poses []spatialmath.Pose) *inject.Camera {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, intrinsics.Width, intrinsics.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 240}), image.Point{}, draw.Src)
+	for i, id := range ids {
+		marker, err := fiducial.ArucoOriginal().MarkerImage(id, 10)
+		test.That(t, err, test.ShouldBeNil)
+		fiducial.DrawMarker(img, marker, sizes[i], poses[i], &fiducial.Camera{Intrinsics: intrinsics})
+	}
+
+	cam := inject.NewCamera("cam")
+	cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(
+			func(ctx context.Context) (image.Image, func(), error) {
+				return img, func() {}, nil
+			},
+		)), nil
+	}
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{IntrinsicParams: intrinsics}, nil
+	}
+	return cam
+}
+
+func newTestTracker(t *testing.T, cfg *Config, cam camera.Camera) posetracker.PoseTracker {
+	t.Helper()
+	tracker, err := NewPoseTracker(
+		context.Background(),
+		resource.Dependencies{camera.Named(cfg.Camera): cam},
+		resource.Config{Name: "tracker", API: posetracker.Subtype, Model: ModelName, ConvertedAttributes: cfg},
+		golog.NewTestLogger(t),
+	)
+	test.That(t, err, test.ShouldBeNil)
+	return tracker
+}
+
+func TestConfig(t *testing.T) {
+	cfg := &Config{Camera: "cam", MarkerSizeMM: 50}
+	deps, err := cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam"})
+
+	cfg = &Config{MarkerSizeMM: 50}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "camera")
+
+	cfg = &Config{Camera: "cam"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "marker_size_mm")
+
+	cfg = &Config{Camera: "cam", MarkerSizeMM: 50, MarkerSizesMM: map[string]float64{"first": 10}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "not a marker ID")
+
+	cfg = &Config{Camera: "cam", MarkerSizeMM: 50, Dictionary: "tag36h11"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "unknown dictionary")
+
+	cfg = &Config{Camera: "cam", MarkerSizeMM: 50, Dictionary: "custom", MarkerBits: 4, Codes: []string{"0x231b", "zzz"}}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid code")
+
+	cfg.Codes = []string{"0x231b", "0x2ea5"}
+	_, err = cfg.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestPoses(t *testing.T) {
+	ctx := context.Background()
+	poses := []spatialmath.Pose{
+		spatialmath.NewPose(r3.Vector{X: -100, Y: -30, Z: 600}, facing),
+		spatialmath.Compose(
+			spatialmath.NewPose(r3.Vector{X: 120, Y: 40, Z: 500}, facing),
+			spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: 0.4, RY: 1}),
+		),
+	}
+	cam := newTestCamera(t, []int{42, 7}, []float64{100, 60}, poses)
+	tracker := newTestTracker(t, &Config{Camera: "cam", MarkerSizeMM: 100, MarkerSizesMM: map[string]float64{"7": 60}}, cam)
+
+	found, err := tracker.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(found), test.ShouldEqual, 2)
+	for name, expected := range map[string]spatialmath.Pose{"42": poses[0], "7": poses[1]} {
+		pif, ok := found[name]
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, pif.Parent(), test.ShouldEqual, "cam")
+		test.That(t, pif.Pose().Point().Sub(expected.Point()).Norm(), test.ShouldBeLessThan, 5)
+		test.That(t, spatialmath.PoseBetween(expected, pif.Pose()).Orientation().AxisAngles().Theta, test.ShouldBeLessThan, 0.05)
+	}
+
+	found, err = tracker.Poses(ctx, []string{"7", "8"}, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(found), test.ShouldEqual, 1)
+	_, ok := found["7"]
+	test.That(t, ok, test.ShouldBeTrue)
+
+	readings, err := tracker.Readings(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(readings), test.ShouldEqual, 2)
+	_, ok = readings["42"].(*referenceframe.PoseInFrame)
+	test.That(t, ok, test.ShouldBeTrue)
+
+	cam.PropertiesFunc = func(ctx context.Context) (camera.Properties, error) {
+		return camera.Properties{}, nil
+	}
+	_, err = tracker.Poses(ctx, nil, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "intrinsic parameters")
+}
@@ -15,8 +15,7 @@ import (
 	_ "go.viam.com/rdk/components/input/register"
 	_ "go.viam.com/rdk/components/motor/register"
 	_ "go.viam.com/rdk/components/movementsensor/register"
-	// register subtypes without implementations directly.
-	_ "go.viam.com/rdk/components/posetracker"
+	_ "go.viam.com/rdk/components/posetracker/register"
 	_ "go.viam.com/rdk/components/sensor/register"
 	_ "go.viam.com/rdk/components/servo/register"
 )
@@ -582,6 +582,25 @@ type AuthConfig struct {
 	Handlers           []AuthHandlerConfig `json:"handlers"`
 	TLSAuthEntities    []string            `json:"tls_auth_entities"`
 	ExternalAuthConfig *ExternalAuthConfig `json:"external_auth_config,omitempty"`
+
+	// Allow, if set, is an allowlist of the calls authenticated callers may make: every call must
+	// match one of its permissions. It applies the same to every caller, since calls made over
+	// WebRTC are attributed to the robot's own names rather than to whoever signaled them, so it
+	// cannot give different callers different rights. Without it, any authenticated caller may
+	// call any method.
+	Allow []AuthPermissionConfig `json:"allow,omitempty"`
+}
+
+// AuthPermissionConfig allows calls to methods of resources. Each list that is set restricts the
+// calls allowed to those matching one of its entries; a permission with no lists set allows everything.
+type AuthPermissionConfig struct {
+	// Resources are names of resources, as sent in requests (e.g. "cam1" or "remote1:cam1").
+	Resources []string `json:"resources,omitempty"`
+	// Subtypes are resource subtypes (e.g. "rdk:component:camera").
+	Subtypes []string `json:"subtypes,omitempty"`
+	// Methods are method names (e.g. "GetImage") or full gRPC methods
+	// (e.g. "/viam.component.camera.v1.CameraService/GetImage").
+	Methods []string `json:"methods,omitempty"`
 }
 
 // ExternalAuthConfig contains information needed to verify externally authenticated tokens.
@@ -669,6 +688,28 @@ func (config *AuthConfig) Validate(path string) error {
 			return err
 		}
 	}
+
+	if len(config.Allow) != 0 && len(config.Handlers) == 0 {
+		return utils.NewConfigValidationError(
+			fmt.Sprintf("%s.%s", path, "allow"), errors.New("allowlist requires at least one auth handler"))
+	}
+	return validateAuthPermissions(path, config.Allow)
+}
+
+func validateAuthPermissions(path string, permissions []AuthPermissionConfig) error {
+	for idx, permission := range permissions {
+		permissionPath := fmt.Sprintf("%s.%s.%d", path, "allow", idx)
+		for _, subtype := range permission.Subtypes {
+			if _, err := resource.NewSubtypeFromString(subtype); err != nil {
+				return utils.NewConfigValidationError(permissionPath, err)
+			}
+		}
+		for _, method := range permission.Methods {
+			if method == "" {
+				return utils.NewConfigValidationError(permissionPath, errors.New("methods cannot be empty"))
+			}
+		}
+	}
 	return nil
 }
 
@@ -896,6 +896,57 @@ func TestAuthConfigEnsure(t *testing.T) {
 	})
 }
 
+func TestAuthConfigAllow(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	handlers := []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{"key": "abc123"},
+		},
+	}
+	allow := []config.AuthPermissionConfig{
+		{Subtypes: []string{"rdk:component:camera"}, Methods: []string{"GetImage"}},
+		{Resources: []string{"sensor1"}, Methods: []string{"/viam.component.sensor.v1.SensorService/GetReadings"}},
+	}
+
+	for _, tc := range []struct {
+		Name string
+		Auth config.AuthConfig
+		Err  string
+	}{
+		{
+			Name: "valid",
+			Auth: config.AuthConfig{Handlers: handlers, Allow: allow},
+		},
+		{
+			Name: "no handlers",
+			Auth: config.AuthConfig{Allow: allow},
+			Err:  "allowlist requires at least one auth handler",
+		},
+		{
+			Name: "bad subtype",
+			Auth: config.AuthConfig{Handlers: handlers, Allow: []config.AuthPermissionConfig{{Subtypes: []string{"camera"}}}},
+			Err:  `"camera" is not a valid subtype name`,
+		},
+		{
+			Name: "empty method",
+			Auth: config.AuthConfig{Handlers: handlers, Allow: []config.AuthPermissionConfig{{Methods: []string{""}}}},
+			Err:  "methods cannot be empty",
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			conf := config.Config{Auth: tc.Auth}
+			err := conf.Ensure(true, logger)
+			if tc.Err == "" {
+				test.That(t, err, test.ShouldBeNil)
+				return
+			}
+			test.That(t, err, test.ShouldNotBeNil)
+			test.That(t, err.Error(), test.ShouldContainSubstring, tc.Err)
+		})
+	}
+}
+
 func keysetToAttributeMap(t *testing.T, keyset jwks.KeySet) rutils.AttributeMap {
 	t.Helper()
 
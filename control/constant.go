@@ -26,6 +26,8 @@ func newConstant(config BlockConfig, logger golog.Logger) (Block, error) {
 }
 
 func (b *constant) Next(ctx context.Context, x []*Signal, dt time.Duration) ([]*Signal, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.y, true
 }
 
@@ -2,14 +2,24 @@ package control
 
 import (
 	"context"
+	"math"
 	"sync"
 	"time"
 
 	"github.com/edaniels/golog"
 	"github.com/pkg/errors"
 	"go.viam.com/utils"
+
+	"go.viam.com/rdk/metrics"
 )
 
+// loopJitter is how far the time between ticks of control loops is from their period.
+var loopJitter = metrics.DefaultRegistry.NewHistogramVec(
+	"viam_control_loop_jitter_seconds",
+	"How far the time between ticks of control loops is from their period.",
+	[]float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1},
+).With()
+
 // controlBlockInternal Holds internal variables to control the flow of data between blocks.
 type controlBlockInternal struct {
 	mu        sync.Mutex
@@ -206,6 +216,7 @@ func (l *Loop) Start() error {
 		ct := l.ct
 		ts := l.ts
 		close(waitCh)
+		var lastTick time.Time
 		for {
 			if l.cancelCtx.Err() != nil {
 				for _, c := range ts {
@@ -215,6 +226,10 @@ func (l *Loop) Start() error {
 			}
 			select {
 			case t := <-ct.ticker.C:
+				if !lastTick.IsZero() {
+					loopJitter.Observe(math.Abs((t.Sub(lastTick) - l.dt).Seconds()))
+				}
+				lastTick = t
 				for _, c := range ts {
 					c <- t
 				}
@@ -18,10 +18,17 @@ import (
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"go.viam.com/rdk/metrics"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/datamanager/datacapture"
 )
 
+var captureQueueDepths = metrics.DefaultRegistry.NewGaugeVec(
+	"viam_datacapture_queue_depth",
+	"Readings captured but not yet written to disk, by component and method.",
+	"component", "method",
+)
+
 // The cutoff at which if interval < cutoff, a sleep based capture func is used instead of a ticker.
 var sleepCaptureCutoff = 2 * time.Millisecond
 
@@ -50,6 +57,8 @@ type collector struct {
 	captureFunc    CaptureFunc
 	closed         bool
 	target         datacapture.BufferedWriter
+	queueLabels    []string
+	queueDepth     *metrics.Gauge
 }
 
 // Close closes the channels backing the Collector. It should always be called before disposing of a Collector to avoid
@@ -69,6 +78,7 @@ func (c *collector) Close() {
 	}
 	close(c.captureErrors)
 	c.logRoutine.Wait()
+	captureQueueDepths.Delete(c.queueLabels...)
 	//nolint:errcheck
 	_ = c.logger.Sync()
 }
@@ -226,6 +236,7 @@ func (c *collector) getAndPushNextReading() {
 	// still work when this happens.
 	case <-c.cancelCtx.Done():
 	case c.captureResults <- &msg:
+		c.queueDepth.Set(float64(len(c.captureResults)))
 	}
 }
 
@@ -243,6 +254,7 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 	} else {
 		c = params.Clock
 	}
+	queueLabels := []string{params.ComponentName, params.MethodName}
 	return &collector{
 		captureResults: make(chan *v1.SensorData, params.QueueSize),
 		captureErrors:  make(chan error, params.QueueSize),
@@ -255,11 +267,14 @@ func NewCollector(captureFunc CaptureFunc, params CollectorParams) (Collector, e
 		target:         params.Target,
 		clock:          c,
 		closed:         false,
+		queueLabels:    queueLabels,
+		queueDepth:     captureQueueDepths.With(queueLabels...),
 	}, nil
 }
 
 func (c *collector) writeCaptureResults() error {
 	for msg := range c.captureResults {
+		c.queueDepth.Set(float64(len(c.captureResults)))
 		if err := c.target.Write(msg); err != nil {
 			return err
 		}
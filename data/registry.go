@@ -19,6 +19,7 @@ type CollectorConstructor func(resource interface{}, params CollectorParams) (Co
 // CollectorParams contain the parameters needed to construct a Collector.
 type CollectorParams struct {
 	ComponentName string
+	MethodName    string
 	Interval      time.Duration
 	MethodParams  map[string]*anypb.Any
 	Target        datacapture.BufferedWriter
@@ -86,6 +86,7 @@ require (
 	go.viam.com/utils v0.1.20-0.20230420205943-ea8c41feeaa0
 	goji.io v2.0.2+incompatible
 	golang.org/x/image v0.7.0
+	golang.org/x/sys v0.7.0
 	golang.org/x/tools v0.7.0
 	gonum.org/v1/gonum v0.12.0
 	gonum.org/v1/plot v0.12.0
@@ -360,7 +361,6 @@ require (
 	golang.org/x/net v0.9.0 // indirect
 	golang.org/x/oauth2 v0.6.0 // indirect
 	golang.org/x/sync v0.1.0 // indirect
-	golang.org/x/sys v0.7.0 // indirect
 	golang.org/x/term v0.7.0 // indirect
 	golang.org/x/text v0.9.0 // indirect
 	golang.org/x/xerrors v0.0.0-20220907171357-04be3eba64a2 // indirect
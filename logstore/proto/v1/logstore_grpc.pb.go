@@ -0,0 +1,175 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: v1/logstore.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LogStoreServiceClient is the client API for LogStoreService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LogStoreServiceClient interface {
+	// GetLogs returns the stored logs matching a filter, oldest first.
+	GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error)
+	// TailLogs streams the most recent stored logs matching a filter, followed by new ones as
+	// they are written.
+	TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (LogStoreService_TailLogsClient, error)
+}
+
+type logStoreServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLogStoreServiceClient(cc grpc.ClientConnInterface) LogStoreServiceClient {
+	return &logStoreServiceClient{cc}
+}
+
+func (c *logStoreServiceClient) GetLogs(ctx context.Context, in *GetLogsRequest, opts ...grpc.CallOption) (*GetLogsResponse, error) {
+	out := new(GetLogsResponse)
+	err := c.cc.Invoke(ctx, "/viam.logstore.v1.LogStoreService/GetLogs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *logStoreServiceClient) TailLogs(ctx context.Context, in *TailLogsRequest, opts ...grpc.CallOption) (LogStoreService_TailLogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &LogStoreService_ServiceDesc.Streams[0], "/viam.logstore.v1.LogStoreService/TailLogs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logStoreServiceTailLogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LogStoreService_TailLogsClient interface {
+	Recv() (*TailLogsResponse, error)
+	grpc.ClientStream
+}
+
+type logStoreServiceTailLogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *logStoreServiceTailLogsClient) Recv() (*TailLogsResponse, error) {
+	m := new(TailLogsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LogStoreServiceServer is the server API for LogStoreService service.
+// All implementations must embed UnimplementedLogStoreServiceServer
+// for forward compatibility
+type LogStoreServiceServer interface {
+	// GetLogs returns the stored logs matching a filter, oldest first.
+	GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error)
+	// TailLogs streams the most recent stored logs matching a filter, followed by new ones as
+	// they are written.
+	TailLogs(*TailLogsRequest, LogStoreService_TailLogsServer) error
+	mustEmbedUnimplementedLogStoreServiceServer()
+}
+
+// UnimplementedLogStoreServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedLogStoreServiceServer struct {
+}
+
+func (UnimplementedLogStoreServiceServer) GetLogs(context.Context, *GetLogsRequest) (*GetLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogs not implemented")
+}
+func (UnimplementedLogStoreServiceServer) TailLogs(*TailLogsRequest, LogStoreService_TailLogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method TailLogs not implemented")
+}
+func (UnimplementedLogStoreServiceServer) mustEmbedUnimplementedLogStoreServiceServer() {}
+
+// UnsafeLogStoreServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LogStoreServiceServer will
+// result in compilation errors.
+type UnsafeLogStoreServiceServer interface {
+	mustEmbedUnimplementedLogStoreServiceServer()
+}
+
+func RegisterLogStoreServiceServer(s grpc.ServiceRegistrar, srv LogStoreServiceServer) {
+	s.RegisterService(&LogStoreService_ServiceDesc, srv)
+}
+
+func _LogStoreService_GetLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogStoreServiceServer).GetLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/viam.logstore.v1.LogStoreService/GetLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogStoreServiceServer).GetLogs(ctx, req.(*GetLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LogStoreService_TailLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LogStoreServiceServer).TailLogs(m, &logStoreServiceTailLogsServer{stream})
+}
+
+type LogStoreService_TailLogsServer interface {
+	Send(*TailLogsResponse) error
+	grpc.ServerStream
+}
+
+type logStoreServiceTailLogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *logStoreServiceTailLogsServer) Send(m *TailLogsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// LogStoreService_ServiceDesc is the grpc.ServiceDesc for LogStoreService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LogStoreService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "viam.logstore.v1.LogStoreService",
+	HandlerType: (*LogStoreServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLogs",
+			Handler:    _LogStoreService_GetLogs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TailLogs",
+			Handler:       _LogStoreService_TailLogs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "v1/logstore.proto",
+}
@@ -0,0 +1,206 @@
+package logstore
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+	apppb "go.viam.com/api/app/v1"
+	"go.viam.com/utils/protoutils"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "go.viam.com/rdk/logstore/proto/v1"
+)
+
+// tailBatchSize is the most entries sent in one response while tailing.
+const tailBatchSize = 100
+
+// A server serves the logs of a store over gRPC.
+type server struct {
+	pb.UnimplementedLogStoreServiceServer
+	store    *Store
+	hostname string
+}
+
+// NewServer returns a server for the logs of the store.
+func NewServer(store *Store) pb.LogStoreServiceServer {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = ""
+	}
+	return &server{store: store, hostname: hostname}
+}
+
+// GetLogs returns the stored logs matching a filter, oldest first.
+func (s *server) GetLogs(ctx context.Context, req *pb.GetLogsRequest) (*pb.GetLogsResponse, error) {
+	filter, err := FilterFromProto(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.store.Query(filter, int(req.Limit))
+	if err != nil {
+		return nil, err
+	}
+	logs, err := s.entriesToProto(entries)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetLogsResponse{Logs: logs}, nil
+}
+
+// TailLogs streams the most recent stored logs matching a filter, followed by new ones as they are written.
+func (s *server) TailLogs(req *pb.TailLogsRequest, stream pb.LogStoreService_TailLogsServer) error {
+	filter, err := FilterFromProto(req.Filter)
+	if err != nil {
+		return err
+	}
+	var newEntries <-chan Entry
+	var unsubscribe func()
+	if req.Recent > 0 {
+		// the recent logs end exactly where the subscription starts, so that none are missed or sent twice
+		var recent []Entry
+		if recent, newEntries, unsubscribe, err = s.store.querySubscribe(filter, int(req.Recent)); err != nil {
+			return err
+		}
+		defer unsubscribe()
+		if err := s.send(stream, recent); err != nil {
+			return err
+		}
+	} else {
+		newEntries, unsubscribe = s.store.Subscribe()
+		defer unsubscribe()
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case entry, ok := <-newEntries:
+			if !ok {
+				return nil
+			}
+			batch := []Entry{entry}
+		drain:
+			for len(batch) < tailBatchSize {
+				select {
+				case entry, ok := <-newEntries:
+					if !ok {
+						break drain
+					}
+					batch = append(batch, entry)
+				default:
+					break drain
+				}
+			}
+			batch = selectEntries(batch, filter)
+			if err := s.send(stream, batch); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *server) send(stream pb.LogStoreService_TailLogsServer, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	logs, err := s.entriesToProto(entries)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&pb.TailLogsResponse{Logs: logs})
+}
+
+func selectEntries(entries []Entry, filter Filter) []Entry {
+	selected := entries[:0]
+	for _, e := range entries {
+		if filter.Matches(e) {
+			selected = append(selected, e)
+		}
+	}
+	return selected
+}
+
+// FilterFromProto converts a filter of logs from its proto equivalent.
+func FilterFromProto(proto *pb.LogFilter) (Filter, error) {
+	var filter Filter
+	if proto == nil {
+		return filter, nil
+	}
+	if proto.Start != nil {
+		filter.Start = proto.Start.AsTime()
+	}
+	if proto.End != nil {
+		filter.End = proto.End.AsTime()
+	}
+	if proto.Level != "" {
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(strings.ToLower(proto.Level))); err != nil {
+			return Filter{}, errors.Wrapf(err, "invalid log level %q", proto.Level)
+		}
+		filter.Level = level
+	}
+	filter.LoggerName = proto.LoggerName
+	filter.Resource = proto.Resource
+	return filter, nil
+}
+
+// wrappedEntryCaller mirrors zapcore.EntryCaller, like the callers of logs sent to the cloud.
+type wrappedEntryCaller struct {
+	Defined bool
+	File    string
+	Line    int
+}
+
+func (s *server) entriesToProto(entries []Entry) ([]*apppb.LogEntry, error) {
+	logs := make([]*apppb.LogEntry, 0, len(entries))
+	for _, e := range entries {
+		log, err := entryToProto(e)
+		if err != nil {
+			return nil, err
+		}
+		log.Host = s.hostname
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+func entryToProto(e Entry) (*apppb.LogEntry, error) {
+	log := &apppb.LogEntry{
+		Level:      e.Level.String(),
+		Time:       timestamppb.New(e.Time),
+		LoggerName: e.LoggerName,
+		Message:    e.Message,
+		Stack:      e.Stack,
+	}
+
+	var wc wrappedEntryCaller
+	if e.Caller != "" {
+		wc.Defined = true
+		wc.File = e.Caller
+		if idx := strings.LastIndexByte(e.Caller, ':'); idx != -1 {
+			if line, err := strconv.Atoi(e.Caller[idx+1:]); err == nil {
+				wc.File = e.Caller[:idx]
+				wc.Line = line
+			}
+		}
+	}
+	caller, err := protoutils.StructToStructPb(wc)
+	if err != nil {
+		return nil, err
+	}
+	log.Caller = caller
+
+	for key, value := range e.Fields {
+		field, err := structpb.NewStruct(map[string]interface{}{key: value})
+		if err != nil {
+			return nil, err
+		}
+		log.Fields = append(log.Fields, field)
+	}
+	return log, nil
+}
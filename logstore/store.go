@@ -0,0 +1,431 @@
+// Package logstore keeps the recent structured logs of a robot in rotating files on its disk, so that they can be
+// queried over the robot's own API, even when it is offline.
+package logstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// DefaultMaxFileSize is the size a log file grows to before it is rotated.
+	DefaultMaxFileSize = 10 << 20
+	// DefaultMaxFiles is how many log files are kept, including the one being written.
+	DefaultMaxFiles = 5
+
+	fileName = "robot.log"
+)
+
+// the keys of entries in the files; all other keys are fields.
+const (
+	timeKey    = "time"
+	levelKey   = "level"
+	loggerKey  = "logger"
+	callerKey  = "caller"
+	messageKey = "message"
+	stackKey   = "stack"
+)
+
+var encoderConfig = zapcore.EncoderConfig{
+	TimeKey:        timeKey,
+	LevelKey:       levelKey,
+	NameKey:        loggerKey,
+	CallerKey:      callerKey,
+	MessageKey:     messageKey,
+	StacktraceKey:  stackKey,
+	LineEnding:     zapcore.DefaultLineEnding,
+	EncodeLevel:    zapcore.LowercaseLevelEncoder,
+	EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+	EncodeDuration: zapcore.StringDurationEncoder,
+	EncodeCaller:   zapcore.ShortCallerEncoder,
+}
+
+// An Entry is a log entry read back from a Store.
+type Entry struct {
+	Time       time.Time
+	Level      zapcore.Level
+	LoggerName string
+	Caller     string
+	Message    string
+	Stack      string
+	Fields     map[string]interface{}
+}
+
+// A Store writes log entries as JSON lines to a file in a directory, rotating it when it gets too big and keeping
+// only a few old ones.
+type Store struct {
+	dir         string
+	maxFileSize int64
+	maxFiles    int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	subs map[*subscription]struct{}
+}
+
+// NewStore returns a Store keeping logs in the given directory, which is created if needed. Files are rotated
+// once they exceed maxFileSize bytes, and at most maxFiles of them are kept.
+func NewStore(dir string, maxFileSize int64, maxFiles int) (*Store, error) {
+	if maxFileSize <= 0 {
+		return nil, errors.Errorf("max file size must be positive, got %d", maxFileSize)
+	}
+	if maxFiles < 1 {
+		return nil, errors.Errorf("must keep at least 1 file, got %d", maxFiles)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	s := &Store{
+		dir:         dir,
+		maxFileSize: maxFileSize,
+		maxFiles:    maxFiles,
+		subs:        map[*subscription]struct{}{},
+	}
+	if err := s.openFile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// path returns the path of the i-th newest file, where the 0th is the one being written.
+func (s *Store) path(i int) string {
+	if i == 0 {
+		return filepath.Join(s.dir, fileName)
+	}
+	return filepath.Join(s.dir, fileName+"."+strconv.Itoa(i))
+}
+
+func (s *Store) openFile() error {
+	//nolint:gosec
+	f, err := os.OpenFile(s.path(0), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return multierr.Combine(err, f.Close())
+	}
+	s.file = f
+	s.size = info.Size()
+	return nil
+}
+
+// Core returns a zap core that writes the entries enabled by the level to the store, to be teed with the other
+// cores of a logger.
+func (s *Store) Core(level zapcore.LevelEnabler) zapcore.Core {
+	return zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), s, level)
+}
+
+// Write writes one encoded entry, rotating the files first if it would not fit in the current one. It is called
+// by the core of the store with whole entries.
+func (s *Store) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return 0, errors.New("log store is closed")
+	}
+	if s.size > 0 && s.size+int64(len(p)) > s.maxFileSize {
+		if err := s.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if len(s.subs) != 0 {
+		if entry, err := parseEntry(bytes.TrimSpace(p)); err == nil {
+			for sub := range s.subs {
+				sub.send(entry)
+			}
+		}
+	}
+	return n, nil
+}
+
+// rotate moves each file to the next older path, dropping the oldest, and starts a new one.
+func (s *Store) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+	if err := os.Remove(s.path(s.maxFiles - 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.maxFiles - 2; i >= 0; i-- {
+		if err := os.Rename(s.path(i), s.path(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return s.openFile()
+}
+
+// Sync flushes the current file to disk.
+func (s *Store) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+// Close closes the current file and ends all subscriptions.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		sub.close()
+		delete(s.subs, sub)
+	}
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// A Filter selects log entries. Its zero value selects every entry.
+type Filter struct {
+	// Start and End, if set, bound the times of entries, with End exclusive.
+	Start time.Time
+	End   time.Time
+	// Level, if set, selects entries of the levels it enables.
+	Level zapcore.LevelEnabler
+	// LoggerName, if set, selects entries of the logger and its children.
+	LoggerName string
+	// Resource, if set, selects entries of the loggers of the resource with this name or full name
+	// (e.g. "cam1" or "rdk:component:camera/cam1").
+	Resource string
+}
+
+// Matches returns whether the filter selects the entry.
+func (f Filter) Matches(e Entry) bool {
+	if !f.Start.IsZero() && e.Time.Before(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && !e.Time.Before(f.End) {
+		return false
+	}
+	if f.Level != nil && !f.Level.Enabled(e.Level) {
+		return false
+	}
+	if f.LoggerName != "" && e.LoggerName != f.LoggerName && !strings.HasPrefix(e.LoggerName, f.LoggerName+".") {
+		return false
+	}
+	if f.Resource != "" && !loggerOfResource(e.LoggerName, f.Resource) {
+		return false
+	}
+	return true
+}
+
+// loggerOfResource returns whether the logger name has a part naming the resource. Resource loggers are named
+// after the full names of their resources, like "rdk:component:camera/cam1", under the robot's logger.
+func loggerOfResource(loggerName, resource string) bool {
+	for _, part := range strings.Split(loggerName, ".") {
+		if part == resource || strings.HasSuffix(part, "/"+resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// Query returns the entries selected by the filter, oldest first. If limit is positive, only that many of the
+// newest of them are returned.
+func (s *Store) Query(filter Filter, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	snap, err := s.snapshotLocked()
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return snap.query(filter, limit)
+}
+
+// querySubscribe is Query followed by Subscribe, with every entry written to the store either returned by the
+// query or sent to the subscription, and never both.
+func (s *Store) querySubscribe(filter Filter, limit int) ([]Entry, <-chan Entry, func(), error) {
+	s.mu.Lock()
+	snap, err := s.snapshotLocked()
+	if err != nil {
+		s.mu.Unlock()
+		return nil, nil, nil, err
+	}
+	entries, unsubscribe := s.subscribeLocked()
+	s.mu.Unlock()
+
+	recent, err := snap.query(filter, limit)
+	if err != nil {
+		unsubscribe()
+		return nil, nil, nil, err
+	}
+	return recent, entries, unsubscribe, nil
+}
+
+// A snapshot holds the files of a store open as they were at one moment, so that they can be read while the store
+// goes on writing and rotating them.
+type snapshot struct {
+	// files are the open files, oldest first.
+	files []*os.File
+	// newestSize is how much of the newest file had been written, or -1 to read all of it.
+	newestSize  int64
+	maxLineSize int
+}
+
+// snapshotLocked opens the files of the store. It is called with mu held, which keeps the files from being
+// written or rotated, so it must only open them and leave reading them to the snapshot.
+func (s *Store) snapshotLocked() (*snapshot, error) {
+	snap := &snapshot{newestSize: -1, maxLineSize: int(s.maxFileSize) + 1}
+	if s.file != nil {
+		snap.newestSize = s.size
+	}
+	for i := s.maxFiles - 1; i >= 0; i-- {
+		//nolint:gosec
+		f, err := os.Open(s.path(i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, multierr.Combine(err, snap.close())
+		}
+		snap.files = append(snap.files, f)
+	}
+	return snap, nil
+}
+
+func (snap *snapshot) close() error {
+	var err error
+	for _, f := range snap.files {
+		err = multierr.Combine(err, f.Close())
+	}
+	return err
+}
+
+// query returns the entries of the snapshot selected by the filter like Query, and closes the snapshot.
+func (snap *snapshot) query(filter Filter, limit int) (entries []Entry, err error) {
+	defer func() {
+		err = multierr.Combine(err, snap.close())
+	}()
+	for i, f := range snap.files {
+		var r io.Reader = f
+		if i == len(snap.files)-1 && snap.newestSize >= 0 {
+			r = io.LimitReader(f, snap.newestSize)
+		}
+		if err := snap.readFile(r, func(e Entry) {
+			if !filter.Matches(e) {
+				return
+			}
+			entries = append(entries, e)
+			if limit > 0 && len(entries) > 2*limit {
+				entries = append(entries[:0], entries[len(entries)-limit:]...)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
+
+func (snap *snapshot) readFile(r io.Reader, fn func(e Entry)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), snap.maxLineSize)
+	for scanner.Scan() {
+		// lines that cannot be parsed, like one cut short by a crash, are skipped
+		if e, err := parseEntry(scanner.Bytes()); err == nil {
+			fn(e)
+		}
+	}
+	return scanner.Err()
+}
+
+func parseEntry(line []byte) (Entry, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return Entry{}, err
+	}
+	var e Entry
+	timeStr, _ := raw[timeKey].(string)
+	t, err := time.Parse(time.RFC3339Nano, timeStr)
+	if err != nil {
+		return Entry{}, err
+	}
+	e.Time = t
+	levelStr, _ := raw[levelKey].(string)
+	if err := e.Level.UnmarshalText([]byte(levelStr)); err != nil {
+		return Entry{}, err
+	}
+	e.LoggerName, _ = raw[loggerKey].(string)
+	e.Caller, _ = raw[callerKey].(string)
+	e.Message, _ = raw[messageKey].(string)
+	e.Stack, _ = raw[stackKey].(string)
+	for _, key := range []string{timeKey, levelKey, loggerKey, callerKey, messageKey, stackKey} {
+		delete(raw, key)
+	}
+	if len(raw) != 0 {
+		e.Fields = raw
+	}
+	return e, nil
+}
+
+// subscriptionBuffer is how many entries a subscriber can fall behind by before entries are dropped for it.
+const subscriptionBuffer = 1024
+
+type subscription struct {
+	ch chan Entry
+}
+
+// send never blocks, so that logging never waits for a subscriber.
+func (sub *subscription) send(e Entry) {
+	select {
+	case sub.ch <- e:
+	default:
+	}
+}
+
+func (sub *subscription) close() {
+	close(sub.ch)
+}
+
+// Subscribe returns a channel receiving every entry written to the store from now on, until the returned function
+// is called or the store is closed, which close the channel. Entries are dropped for subscribers that fall too
+// far behind.
+func (s *Store) Subscribe() (<-chan Entry, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subscribeLocked()
+}
+
+func (s *Store) subscribeLocked() (<-chan Entry, func()) {
+	sub := &subscription{ch: make(chan Entry, subscriptionBuffer)}
+	if s.file == nil {
+		sub.close()
+	} else {
+		s.subs[sub] = struct{}{}
+	}
+	return sub.ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[sub]; ok {
+			delete(s.subs, sub)
+			sub.close()
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package logstore
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.viam.com/test"
+)
+
+func newTestLogger(t *testing.T, s *Store) *zap.SugaredLogger {
+	t.Helper()
+	return zap.New(s.Core(zapcore.DebugLevel), zap.AddCaller()).Sugar().Named("robot_server")
+}
+
+func TestStoreQuery(t *testing.T) {
+	s, err := NewStore(t.TempDir(), DefaultMaxFileSize, DefaultMaxFiles)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(), test.ShouldBeNil)
+	}()
+	logger := newTestLogger(t, s)
+
+	logger.Debug("starting")
+	logger.Named("rdk:component:camera/cam1").Infow("frame", "width", 640)
+	logger.Named("rdk:component:arm/arm1").Warn("slow")
+	logger.Named("modmanager").Named("mymodule").Error("crashed")
+
+	entries, err := s.Query(Filter{}, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 4)
+	test.That(t, entries[0].Message, test.ShouldEqual, "starting")
+	test.That(t, entries[0].Level, test.ShouldEqual, zapcore.DebugLevel)
+	test.That(t, entries[0].LoggerName, test.ShouldEqual, "robot_server")
+	test.That(t, entries[0].Caller, test.ShouldContainSubstring, "logstore/store_test.go:")
+	test.That(t, entries[1].Fields, test.ShouldResemble, map[string]interface{}{"width": 640.0})
+
+	for _, tc := range []struct {
+		name     string
+		filter   Filter
+		messages []string
+	}{
+		{"level", Filter{Level: zapcore.WarnLevel}, []string{"slow", "crashed"}},
+		{"logger", Filter{LoggerName: "robot_server.modmanager"}, []string{"crashed"}},
+		{"logger prefix is not a parent", Filter{LoggerName: "robot_server.mod"}, nil},
+		{"resource short name", Filter{Resource: "cam1"}, []string{"frame"}},
+		{"resource full name", Filter{Resource: "rdk:component:arm/arm1"}, []string{"slow"}},
+		{"start", Filter{Start: time.Now().Add(time.Minute)}, nil},
+		{"end", Filter{End: time.Now().Add(time.Minute)}, []string{"starting", "frame", "slow", "crashed"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := s.Query(tc.filter, 0)
+			test.That(t, err, test.ShouldBeNil)
+			var messages []string
+			for _, e := range entries {
+				messages = append(messages, e.Message)
+			}
+			test.That(t, messages, test.ShouldResemble, tc.messages)
+		})
+	}
+
+	entries, err = s.Query(Filter{}, 2)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 2)
+	test.That(t, entries[0].Message, test.ShouldEqual, "slow")
+	test.That(t, entries[1].Message, test.ShouldEqual, "crashed")
+}
+
+func TestStoreRotate(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(dir, 1024, 3)
+	test.That(t, err, test.ShouldBeNil)
+	logger := newTestLogger(t, s)
+
+	for i := 0; i < 100; i++ {
+		logger.Infow("hello", "i", i)
+	}
+	test.That(t, s.Close(), test.ShouldBeNil)
+
+	files, err := os.ReadDir(dir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, files, test.ShouldHaveLength, 3)
+	for _, f := range files {
+		info, err := f.Info()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, info.Size(), test.ShouldBeLessThanOrEqualTo, 1024)
+	}
+
+	// the newest entries are kept in order and survive reopening the store
+	s, err = NewStore(dir, 1024, 3)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(), test.ShouldBeNil)
+	}()
+	entries, err := s.Query(Filter{}, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(entries), test.ShouldBeBetween, 1, 100)
+	for i, e := range entries {
+		test.That(t, e.Fields["i"], test.ShouldEqual, float64(100-len(entries)+i))
+	}
+
+	_, err = NewStore(dir, 0, 3)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewStore(dir, 1024, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestStoreSubscribe(t *testing.T) {
+	s, err := NewStore(t.TempDir(), DefaultMaxFileSize, DefaultMaxFiles)
+	test.That(t, err, test.ShouldBeNil)
+	logger := newTestLogger(t, s)
+
+	logger.Info("before")
+	entries, unsubscribe := s.Subscribe()
+	logger.Info("after")
+	e := <-entries
+	test.That(t, e.Message, test.ShouldEqual, "after")
+
+	unsubscribe()
+	_, ok := <-entries
+	test.That(t, ok, test.ShouldBeFalse)
+	unsubscribe()
+
+	entries, _ = s.Subscribe()
+	test.That(t, s.Close(), test.ShouldBeNil)
+	_, ok = <-entries
+	test.That(t, ok, test.ShouldBeFalse)
+
+	entries, _ = s.Subscribe()
+	_, ok = <-entries
+	test.That(t, ok, test.ShouldBeFalse)
+}
+
+func TestStoreSnapshot(t *testing.T) {
+	s, err := NewStore(t.TempDir(), 1024, 2)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(), test.ShouldBeNil)
+	}()
+	logger := newTestLogger(t, s)
+
+	logger.Info("first")
+	logger.Info("second")
+	s.mu.Lock()
+	snap, err := s.snapshotLocked()
+	s.mu.Unlock()
+	test.That(t, err, test.ShouldBeNil)
+
+	// the snapshot does not keep the files from being written and rotated away
+	for i := 0; i < 100; i++ {
+		logger.Infow("hello", "i", i)
+	}
+	entries, err := snap.query(Filter{}, 0)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, entries, test.ShouldHaveLength, 2)
+	test.That(t, entries[0].Message, test.ShouldEqual, "first")
+	test.That(t, entries[1].Message, test.ShouldEqual, "second")
+}
+
+func TestStoreQuerySubscribe(t *testing.T) {
+	s, err := NewStore(t.TempDir(), DefaultMaxFileSize, DefaultMaxFiles)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, s.Close(), test.ShouldBeNil)
+	}()
+	logger := newTestLogger(t, s)
+
+	// every entry is either a recent one or sent to the subscription, whatever its time
+	for i := 0; i < 3; i++ {
+		logger.Infow("before", "i", i)
+	}
+	recent, entries, unsubscribe, err := s.querySubscribe(Filter{}, 2)
+	test.That(t, err, test.ShouldBeNil)
+	defer unsubscribe()
+	for i := 0; i < 2; i++ {
+		logger.Infow("after", "i", i)
+	}
+
+	test.That(t, recent, test.ShouldHaveLength, 2)
+	test.That(t, recent[0].Fields["i"], test.ShouldEqual, 1.0)
+	test.That(t, recent[1].Fields["i"], test.ShouldEqual, 2.0)
+	for i := 0; i < 2; i++ {
+		e := <-entries
+		test.That(t, e.Message, test.ShouldEqual, "after")
+		test.That(t, e.Fields["i"], test.ShouldEqual, float64(i))
+	}
+	test.That(t, entries, test.ShouldHaveLength, 0)
+}
@@ -0,0 +1,276 @@
+// Package metrics keeps counters, gauges and histograms of how a robot is doing, and exports them in the Prometheus
+// text format so that they can be scraped from the web server.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultRegistry is the registry robot code records its metrics in, and that the web server exports.
+var DefaultRegistry = NewRegistry()
+
+// DefaultBuckets are histogram buckets for durations in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type kind string
+
+const (
+	counterKind   = kind("counter")
+	gaugeKind     = kind("gauge")
+	histogramKind = kind("histogram")
+)
+
+// A Registry holds families of metrics by name.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// family is a metric and all of its series, one for each combination of label values.
+type family struct {
+	name    string
+	help    string
+	kind    kind
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64Value
+	// for histograms, the count of observations in each bucket, not including lower buckets, and their sum
+	bucketCounts []uint64
+	count        uint64
+}
+
+// float64Value is a float64 that can be changed concurrently.
+type float64Value struct {
+	bits uint64
+}
+
+func (v *float64Value) load() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&v.bits))
+}
+
+func (v *float64Value) store(f float64) {
+	atomic.StoreUint64(&v.bits, math.Float64bits(f))
+}
+
+func (v *float64Value) add(delta float64) {
+	for {
+		old := atomic.LoadUint64(&v.bits)
+		if atomic.CompareAndSwapUint64(&v.bits, old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// register returns the family with the given name, creating it if needed. Registering a name again with the same
+// kind and labels returns the same family, so that metrics can be declared where they are used. It panics if the
+// name is already registered differently, which is a programming error.
+func (r *Registry) register(name, help string, k kind, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.families[name]; ok {
+		if f.kind != k || strings.Join(f.labels, ",") != strings.Join(labels, ",") {
+			panic(fmt.Sprintf("metric %q is already registered as a %s with labels %v", name, f.kind, f.labels))
+		}
+		return f
+	}
+	f := &family{
+		name:    name,
+		help:    help,
+		kind:    k,
+		labels:  append([]string(nil), labels...),
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+	r.families[name] = f
+	return f
+}
+
+// with returns the series with the given label values, creating it if needed.
+func (f *family) with(labelValues []string) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metric %q has labels %v but got %d values", f.name, f.labels, len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == histogramKind {
+			s.bucketCounts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+func (f *family) delete(labelValues []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.series, strings.Join(labelValues, "\xff"))
+}
+
+// sortedSeries returns copies of the series of the family, ordered by label values.
+func (f *family) sortedSeries() []series {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	all := make([]series, 0, len(f.series))
+	for _, s := range f.series {
+		c := series{labelValues: s.labelValues, count: s.count}
+		c.value.store(s.value.load())
+		c.bucketCounts = append([]uint64(nil), s.bucketCounts...)
+		all = append(all, c)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return strings.Join(all[i].labelValues, "\xff") < strings.Join(all[j].labelValues, "\xff")
+	})
+	return all
+}
+
+// A CounterVec is a counter with a series for each combination of the values of its labels.
+type CounterVec struct {
+	f *family
+}
+
+// NewCounterVec registers a counter with the given labels. Counter names should end in _total.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{r.register(name, help, counterKind, nil, labels)}
+}
+
+// With returns the counter for the given label values, in the order of the labels.
+func (c *CounterVec) With(labelValues ...string) *Counter {
+	return &Counter{c.f.with(labelValues)}
+}
+
+// Delete removes the counter for the given label values.
+func (c *CounterVec) Delete(labelValues ...string) {
+	c.f.delete(labelValues)
+}
+
+// A Counter is a value that only goes up.
+type Counter struct {
+	s *series
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.s.value.add(1)
+}
+
+// Add adds a non-negative delta to the counter.
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("counters cannot decrease")
+	}
+	c.s.value.add(delta)
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() float64 {
+	return c.s.value.load()
+}
+
+// A GaugeVec is a gauge with a series for each combination of the values of its labels.
+type GaugeVec struct {
+	f *family
+}
+
+// NewGaugeVec registers a gauge with the given labels.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{r.register(name, help, gaugeKind, nil, labels)}
+}
+
+// With returns the gauge for the given label values, in the order of the labels.
+func (g *GaugeVec) With(labelValues ...string) *Gauge {
+	return &Gauge{g.f.with(labelValues)}
+}
+
+// Delete removes the gauge for the given label values, for example when what it measures goes away.
+func (g *GaugeVec) Delete(labelValues ...string) {
+	g.f.delete(labelValues)
+}
+
+// A Gauge is a value that can go up and down.
+type Gauge struct {
+	s *series
+}
+
+// Set sets the gauge to the value.
+func (g *Gauge) Set(value float64) {
+	g.s.value.store(value)
+}
+
+// Add adds delta, which may be negative, to the gauge.
+func (g *Gauge) Add(delta float64) {
+	g.s.value.add(delta)
+}
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() float64 {
+	return g.s.value.load()
+}
+
+// A HistogramVec is a histogram with a series for each combination of the values of its labels.
+type HistogramVec struct {
+	f *family
+}
+
+// NewHistogramVec registers a histogram with the given upper bounds of its buckets, in increasing order, and labels.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("buckets of histogram %q are not in increasing order", name))
+	}
+	return &HistogramVec{r.register(name, help, histogramKind, buckets, labels)}
+}
+
+// With returns the histogram for the given label values, in the order of the labels.
+func (h *HistogramVec) With(labelValues ...string) *Histogram {
+	return &Histogram{h.f, h.f.with(labelValues)}
+}
+
+// Delete removes the histogram for the given label values.
+func (h *HistogramVec) Delete(labelValues ...string) {
+	h.f.delete(labelValues)
+}
+
+// A Histogram counts observations in buckets.
+type Histogram struct {
+	f *family
+	s *series
+}
+
+// Observe adds an observation to the histogram.
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.f.buckets, value)
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	if i < len(h.s.bucketCounts) {
+		h.s.bucketCounts[i]++
+	}
+	h.s.count++
+	h.s.value.add(value)
+}
+
+// Count returns the number of observations and their sum.
+func (h *Histogram) Count() (uint64, float64) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	return h.s.count, h.s.value.load()
+}
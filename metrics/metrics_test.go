@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("test_requests_total", "Requests handled.", "method", "code")
+	queue := r.NewGaugeVec("test_queue_depth", "Items waiting.\nIn the queue.", "queue")
+	latency := r.NewHistogramVec("test_latency_seconds", "How long it took.", []float64{0.1, 1}, "method")
+	r.NewGaugeVec("test_unused", "Never set.")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			requests.With("Get", "OK").Inc()
+		}()
+	}
+	wg.Wait()
+	requests.With("Get", "Internal").Add(2)
+	requests.With("Delete", "OK").Inc()
+	test.That(t, requests.With("Get", "OK").Value(), test.ShouldEqual, 10)
+
+	queue.With(`a "quoted" \ name`).Set(3)
+	queue.With("b").Set(5)
+	queue.With("b").Add(-1)
+	queue.With("gone").Set(1)
+	queue.Delete("gone")
+	test.That(t, queue.With("b").Value(), test.ShouldEqual, 4)
+
+	latency.With("Get").Observe(0.05)
+	latency.With("Get").Observe(0.5)
+	latency.With("Get").Observe(0.1)
+	latency.With("Get").Observe(3)
+	count, sum := latency.With("Get").Count()
+	test.That(t, count, test.ShouldEqual, 4)
+	test.That(t, sum, test.ShouldAlmostEqual, 3.65)
+
+	// registering again returns the same metric, and registering differently is a bug
+	test.That(t, r.NewCounterVec("test_requests_total", "Requests handled.", "method", "code").With("Get", "OK").Value(),
+		test.ShouldEqual, 10)
+	test.That(t, func() { r.NewGaugeVec("test_requests_total", "", "method", "code") }, test.ShouldPanic)
+	test.That(t, func() { r.NewCounterVec("test_requests_total", "", "method") }, test.ShouldPanic)
+	test.That(t, func() { requests.With("Get") }, test.ShouldPanic)
+	test.That(t, func() { requests.With("Get", "OK").Add(-1) }, test.ShouldPanic)
+	test.That(t, func() { r.NewHistogramVec("test_bad_buckets", "", []float64{1, 0.1}) }, test.ShouldPanic)
+
+	var buf bytes.Buffer
+	test.That(t, r.WriteText(&buf), test.ShouldBeNil)
+	test.That(t, buf.String(), test.ShouldEqual, `# HELP test_latency_seconds How long it took.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{method="Get",le="0.1"} 2
+test_latency_seconds_bucket{method="Get",le="1"} 3
+test_latency_seconds_bucket{method="Get",le="+Inf"} 4
+test_latency_seconds_sum{method="Get"} 3.65
+test_latency_seconds_count{method="Get"} 4
+# HELP test_queue_depth Items waiting.\nIn the queue.
+# TYPE test_queue_depth gauge
+test_queue_depth{queue="a \"quoted\" \\ name"} 3
+test_queue_depth{queue="b"} 4
+# HELP test_requests_total Requests handled.
+# TYPE test_requests_total counter
+test_requests_total{method="Delete",code="OK"} 1
+test_requests_total{method="Get",code="Internal"} 2
+test_requests_total{method="Get",code="OK"} 10
+`)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	test.That(t, rec.Header().Get("Content-Type"), test.ShouldEqual, textContentType)
+	test.That(t, rec.Body.String(), test.ShouldEqual, buf.String())
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// textContentType is the content type of version 0.0.4 of the Prometheus text format.
+const textContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// WriteText writes all the metrics of the registry in the Prometheus text format, ordered by name.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.Unlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].name < families[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		all := f.sortedSeries()
+		if len(all) == 0 {
+			continue
+		}
+		bw.WriteString("# HELP " + f.name + " " + helpEscaper.Replace(f.help) + "\n")
+		bw.WriteString("# TYPE " + f.name + " " + string(f.kind) + "\n")
+		for _, s := range all {
+			labels := f.labelPairs(s.labelValues)
+			if f.kind != histogramKind {
+				writeSample(bw, f.name, labels, s.value.load())
+				continue
+			}
+			var cumulative uint64
+			for i, upper := range f.buckets {
+				cumulative += s.bucketCounts[i]
+				writeSample(bw, f.name+"_bucket", append(labels, "le", formatFloat(upper)), float64(cumulative))
+			}
+			writeSample(bw, f.name+"_bucket", append(labels, "le", "+Inf"), float64(s.count))
+			writeSample(bw, f.name+"_sum", labels, s.value.load())
+			writeSample(bw, f.name+"_count", labels, float64(s.count))
+		}
+	}
+	return bw.Flush()
+}
+
+// labelPairs returns the names and values of the labels of a series, alternating.
+func (f *family) labelPairs(values []string) []string {
+	pairs := make([]string, 0, 2*len(values)+2)
+	for i, name := range f.labels {
+		pairs = append(pairs, name, values[i])
+	}
+	return pairs
+}
+
+func writeSample(w *bufio.Writer, name string, labelPairs []string, value float64) {
+	w.WriteString(name)
+	if len(labelPairs) > 0 {
+		w.WriteByte('{')
+		for i := 0; i < len(labelPairs); i += 2 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labelPairs[i] + `="` + labelValueEscaper.Replace(labelPairs[i+1]) + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+// ServeHTTP writes all the metrics of the registry for Prometheus to scrape.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", textContentType)
+	//nolint:errcheck
+	_ = r.WriteText(w)
+}
@@ -0,0 +1,390 @@
+// Package onnx runs ONNX models on the CPU. It is a pure Go interpreter for the graphs of a common subset of
+// the default ONNX operator set, which covers typical classifiers and regressors such as MLPs and CNNs, so that
+// no native runtime needs to be installed.
+package onnx
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/utils"
+)
+
+// TensorInfo describes an input or output of a model.
+type TensorInfo struct {
+	Name        string
+	Description string
+	DataType    DataType
+	// Shape holds -1 for dimensions that are not fixed by the model, such as the batch dimension. It is nil
+	// if the model does not declare a shape.
+	Shape []int
+}
+
+// Metadata describes a model.
+type Metadata struct {
+	Name        string
+	Description string
+	Producer    string
+	// Opset is the version of the default ONNX operator set the model uses.
+	Opset int64
+	// Properties holds the metadata_props of the model, free form key value pairs set by its exporter.
+	Properties map[string]string
+	Inputs     []TensorInfo
+	Outputs    []TensorInfo
+}
+
+// Model is a loaded ONNX model. It is immutable, so a single model can run any number of inferences
+// concurrently. It implements inference.MLModel.
+type Model struct {
+	metadata     Metadata
+	nodes        []*node
+	initializers map[string]*Tensor
+}
+
+// Load reads and prepares the ONNX model at the given path.
+func Load(path string) (*Model, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading onnx model")
+	}
+	return Read(data)
+}
+
+// Read prepares an ONNX model from its serialized protobuf, checking that every operator it uses is supported.
+func Read(data []byte) (*Model, error) {
+	mp, err := parseModel(data)
+	if err != nil {
+		return nil, err
+	}
+	opset := int64(0)
+	for _, o := range mp.opsetImport {
+		if o.domain == "" || o.domain == "ai.onnx" {
+			opset = o.version
+		}
+	}
+	if opset == 0 {
+		return nil, errors.New("onnx model does not import the default operator set")
+	}
+
+	g := mp.graph
+	m := &Model{
+		metadata: Metadata{
+			Name:        g.name,
+			Description: mp.docString,
+			Producer:    strings.TrimSpace(mp.producerName + " " + mp.producerVersion),
+			Opset:       opset,
+			Properties:  mp.metadataProps,
+		},
+		initializers: map[string]*Tensor{},
+	}
+	if m.metadata.Description == "" {
+		m.metadata.Description = g.docString
+	}
+
+	defined := map[string]bool{}
+	for _, tp := range g.initializer {
+		t, err := tensorFromProto(tp)
+		if err != nil {
+			return nil, err
+		}
+		m.initializers[tp.name] = t
+		defined[tp.name] = true
+	}
+	// Older exporters list initializers as graph inputs too, but they are not inputs of the model.
+	for _, v := range g.inputs {
+		if _, ok := m.initializers[v.name]; ok {
+			continue
+		}
+		info, err := tensorInfo(v)
+		if err != nil {
+			return nil, err
+		}
+		m.metadata.Inputs = append(m.metadata.Inputs, info)
+		defined[v.name] = true
+	}
+
+	unsupported := map[string]bool{}
+	for i, np := range g.nodes {
+		op, ok := operators[np.opType]
+		if !ok || (np.domain != "" && np.domain != "ai.onnx") {
+			unsupported[np.opType] = true
+			continue
+		}
+		for _, in := range np.inputs {
+			if in != "" && !defined[in] {
+				return nil, errors.Errorf("input %q of node %d (%s) is not defined by a previous node", in, i, np.opType)
+			}
+		}
+		for _, out := range np.outputs {
+			defined[out] = true
+		}
+		m.nodes = append(m.nodes, &node{
+			name:    np.name,
+			opType:  np.opType,
+			inputs:  np.inputs,
+			outputs: np.outputs,
+			attrs:   np.attributes,
+			opset:   opset,
+			run:     op,
+		})
+	}
+	if len(unsupported) > 0 {
+		ops := make([]string, 0, len(unsupported))
+		for op := range unsupported {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops)
+		return nil, errors.Errorf("onnx model uses unsupported operators %v, supported operators are %v",
+			ops, supportedOperators())
+	}
+
+	for _, v := range g.outputs {
+		if !defined[v.name] {
+			return nil, errors.Errorf("output %q of the onnx model is not computed by the graph", v.name)
+		}
+		info, err := tensorInfo(v)
+		if err != nil {
+			return nil, err
+		}
+		m.metadata.Outputs = append(m.metadata.Outputs, info)
+	}
+	return m, nil
+}
+
+func tensorInfo(v *valueInfoProto) (TensorInfo, error) {
+	if !v.elemType.supported() {
+		return TensorInfo{}, errors.Errorf("tensor %q has unsupported data type %d", v.name, v.elemType)
+	}
+	info := TensorInfo{Name: v.name, Description: v.docString, DataType: v.elemType}
+	if v.hasShape {
+		info.Shape = append([]int{}, v.dims...)
+	}
+	return info, nil
+}
+
+// Info returns the description of the model and its inputs and outputs.
+func (m *Model) Info() Metadata {
+	return m.metadata
+}
+
+// Run runs the model on the given inputs, keyed by name, and returns its outputs by name.
+func (m *Model) Run(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+	values := make(map[string]*Tensor, len(m.initializers)+len(inputs))
+	for name, t := range m.initializers {
+		values[name] = t
+	}
+	for _, info := range m.metadata.Inputs {
+		t, ok := inputs[info.Name]
+		if !ok {
+			return nil, errors.Errorf("missing input %q", info.Name)
+		}
+		if err := checkShape(info, t.Shape); err != nil {
+			return nil, err
+		}
+		values[info.Name] = t
+	}
+
+	for _, n := range m.nodes {
+		in := make([]*Tensor, len(n.inputs))
+		for i, name := range n.inputs {
+			if name == "" {
+				continue
+			}
+			t, ok := values[name]
+			if !ok {
+				return nil, errors.Errorf("input %q of %s node %q was not computed", name, n.opType, n.name)
+			}
+			in[i] = t
+		}
+		if len(in) == 0 || in[0] == nil {
+			if n.opType != "Constant" {
+				return nil, errors.Errorf("%s node %q has no input", n.opType, n.name)
+			}
+		}
+		out, err := n.run(n, in)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error running %s node %q", n.opType, n.name)
+		}
+		for i, name := range n.outputs {
+			if i < len(out) && name != "" {
+				values[name] = out[i]
+			}
+		}
+	}
+
+	outputs := make(map[string]*Tensor, len(m.metadata.Outputs))
+	for _, info := range m.metadata.Outputs {
+		t, ok := values[info.Name]
+		if !ok {
+			return nil, errors.Errorf("output %q was not computed", info.Name)
+		}
+		outputs[info.Name] = t
+	}
+	return outputs, nil
+}
+
+// checkShape checks that a shape matches the fixed dimensions of a tensor description.
+func checkShape(info TensorInfo, shape []int) error {
+	if info.Shape == nil {
+		return nil
+	}
+	mismatch := len(shape) != len(info.Shape)
+	for i := 0; !mismatch && i < len(shape); i++ {
+		mismatch = info.Shape[i] >= 0 && info.Shape[i] != shape[i]
+	}
+	if mismatch {
+		return errors.Errorf("input %q has shape %v but the model expects %v", info.Name, shape, info.Shape)
+	}
+	return nil
+}
+
+// Infer runs a model with a single input on the given value, which may be anything accepted by NewInput, and
+// returns its outputs by name as flat slices of their Go type, e.g. []float32.
+func (m *Model) Infer(input interface{}) (utils.AttributeMap, error) {
+	if len(m.metadata.Inputs) != 1 {
+		return nil, errors.Errorf("model has %d inputs, use Run to provide them by name", len(m.metadata.Inputs))
+	}
+	t, err := NewInput(m.metadata.Inputs[0], input)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := m.Run(map[string]*Tensor{m.metadata.Inputs[0].Name: t})
+	if err != nil {
+		return nil, err
+	}
+	out := utils.AttributeMap{}
+	for name, t := range outputs {
+		out[name] = t.Values()
+	}
+	return out, nil
+}
+
+// Metadata returns the Metadata of the model.
+func (m *Model) Metadata() (interface{}, error) {
+	return m.metadata, nil
+}
+
+// Close releases the model. Models hold no resources other than memory, so this does nothing.
+func (m *Model) Close() error {
+	return nil
+}
+
+// NewInput converts a value into a tensor for the described input. The value may be a *Tensor, a flat slice
+// of any Go numeric type or bool, or nested []interface{} of numbers such as those decoded from JSON. Values
+// are converted to the data type of the input.
+//
+// The shape of a flat slice is that of the input, where a single unknown dimension is inferred from the
+// length of the slice. In particular, several samples can be batched into one slice for inputs whose first
+// dimension is the batch dimension. The shape of nested slices is given by their nesting.
+func NewInput(info TensorInfo, value interface{}) (*Tensor, error) {
+	if t, ok := value.(*Tensor); ok {
+		return t, nil
+	}
+	data, nested, err := flattenValue(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "input %q", info.Name)
+	}
+	for i, v := range data {
+		data[i] = castValue(info.DataType, v)
+	}
+
+	var shape []int
+	switch {
+	case len(nested) > 1 && (info.Shape == nil || len(nested) == len(info.Shape)):
+		shape = nested
+	case info.Shape == nil:
+		shape = []int{len(data)}
+	default:
+		shape = append([]int{}, info.Shape...)
+		unknown, known := -1, 1
+		for i, d := range shape {
+			if d >= 0 {
+				known *= d
+				continue
+			}
+			if unknown >= 0 {
+				return nil, errors.Errorf("input %q has several unknown dimensions %v, give it as nested lists",
+					info.Name, info.Shape)
+			}
+			unknown = i
+		}
+		if unknown >= 0 && known > 0 {
+			shape[unknown] = len(data) / known
+		}
+		if shapeSize(shape) != len(data) || len(data) == 0 {
+			return nil, errors.Errorf("input %q of %d values does not fit the expected shape %v",
+				info.Name, len(data), info.Shape)
+		}
+	}
+	return NewTensor(info.DataType, shape, data)
+}
+
+// flattenValue returns the numbers in a flat or nested slice along with the shape of its nesting.
+func flattenValue(value interface{}) ([]float64, []int, error) {
+	if nested, ok := value.([]interface{}); ok {
+		if len(nested) == 0 {
+			return nil, []int{0}, nil
+		}
+		var data []float64
+		var shape []int
+		for i, v := range nested {
+			d, s, err := flattenValue(v)
+			if err != nil {
+				return nil, nil, err
+			}
+			if i == 0 {
+				shape = s
+			} else if !reflect.DeepEqual(shape, s) {
+				return nil, nil, errors.New("nested lists have different lengths")
+			}
+			data = append(data, d...)
+		}
+		return data, append([]int{len(nested)}, shape...), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		v, err := number(rv)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []float64{v}, nil, nil
+	}
+	data := make([]float64, rv.Len())
+	for i := range data {
+		v, err := number(rv.Index(i))
+		if err != nil {
+			return nil, nil, err
+		}
+		data[i] = v
+	}
+	return data, []int{len(data)}, nil
+}
+
+func number(v reflect.Value) (float64, error) {
+	//nolint:exhaustive
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Bool:
+		if v.Bool() {
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.Interface:
+		return number(v.Elem())
+	case reflect.Invalid:
+		return 0, errors.New("cannot use nil as a tensor value")
+	default:
+		return 0, errors.Errorf("cannot use %v of type %v as a tensor value", v, v.Type())
+	}
+}
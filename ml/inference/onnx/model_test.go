@@ -0,0 +1,358 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The helpers below encode the parts of the ONNX protobuf schema used by the tests.
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v int64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// rawTensor encodes a float32 tensor with its data in raw_data.
+func rawTensor(name string, dims []int64, data []float32) []byte {
+	var b []byte
+	for _, d := range dims {
+		b = appendVarint(b, 1, d)
+	}
+	b = appendVarint(b, 2, int64(Float32))
+	b = appendString(b, 8, name)
+	raw := make([]byte, 4*len(data))
+	for i, v := range data {
+		binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(v))
+	}
+	return appendMessage(b, 9, raw)
+}
+
+// int64Tensor encodes an int64 tensor with its data in packed int64_data.
+func int64Tensor(name string, data []int64) []byte {
+	b := appendVarint(nil, 1, int64(len(data)))
+	b = appendVarint(b, 2, int64(Int64))
+	var packed []byte
+	for _, v := range data {
+		packed = protowire.AppendVarint(packed, uint64(v))
+	}
+	b = appendMessage(b, 7, packed)
+	return appendString(b, 8, name)
+}
+
+// valueInfo encodes a tensor description, where negative dimensions are symbolic.
+func valueInfo(name string, dt DataType, dims ...int64) []byte {
+	var shape []byte
+	for _, d := range dims {
+		var dim []byte
+		if d < 0 {
+			dim = appendString(nil, 2, "N")
+		} else {
+			dim = appendVarint(nil, 1, d)
+		}
+		shape = appendMessage(shape, 1, dim)
+	}
+	tensorType := appendVarint(nil, 1, int64(dt))
+	if len(dims) > 0 {
+		tensorType = appendMessage(tensorType, 2, shape)
+	}
+	b := appendString(nil, 1, name)
+	return appendMessage(b, 2, appendMessage(nil, 1, tensorType))
+}
+
+func intAttr(name string, v int64) []byte {
+	b := appendString(nil, 1, name)
+	b = appendVarint(b, 3, v)
+	return appendVarint(b, 20, attributeInt)
+}
+
+func floatAttr(name string, v float32) []byte {
+	b := appendString(nil, 1, name)
+	b = protowire.AppendTag(b, 2, protowire.Fixed32Type)
+	b = protowire.AppendFixed32(b, math.Float32bits(v))
+	return appendVarint(b, 20, attributeFloat)
+}
+
+func intsAttr(name string, vs ...int64) []byte {
+	b := appendString(nil, 1, name)
+	for _, v := range vs {
+		b = appendVarint(b, 8, v)
+	}
+	return appendVarint(b, 20, attributeInts)
+}
+
+func nodeProtoBytes(opType string, inputs, outputs []string, attrs ...[]byte) []byte {
+	var b []byte
+	for _, in := range inputs {
+		b = appendString(b, 1, in)
+	}
+	for _, out := range outputs {
+		b = appendString(b, 2, out)
+	}
+	b = appendString(b, 4, opType)
+	for _, a := range attrs {
+		b = appendMessage(b, 5, a)
+	}
+	return b
+}
+
+type testGraph struct {
+	nodes, initializers, inputs, outputs [][]byte
+}
+
+func (g testGraph) encode(opset int64) []byte {
+	var graph []byte
+	for _, n := range g.nodes {
+		graph = appendMessage(graph, 1, n)
+	}
+	graph = appendString(graph, 2, "test_graph")
+	for _, t := range g.initializers {
+		graph = appendMessage(graph, 5, t)
+	}
+	for _, v := range g.inputs {
+		graph = appendMessage(graph, 11, v)
+	}
+	for _, v := range g.outputs {
+		graph = appendMessage(graph, 12, v)
+	}
+
+	b := appendVarint(nil, 1, 8)
+	b = appendString(b, 2, "rdk")
+	b = appendString(b, 6, "a test model")
+	b = appendMessage(b, 7, graph)
+	opsetImport := appendString(nil, 1, "")
+	b = appendMessage(b, 8, appendVarint(opsetImport, 2, opset))
+	prop := appendString(nil, 1, "model_type")
+	return appendMessage(b, 14, appendString(prop, 2, "classifier"))
+}
+
+// mlpModel is a two layer perceptron classifying batches of 3 features into 2 classes.
+func mlpModel() []byte {
+	return testGraph{
+		nodes: [][]byte{
+			nodeProtoBytes("Gemm", []string{"x", "w1", "b1"}, []string{"h"}),
+			nodeProtoBytes("Relu", []string{"h"}, []string{"r"}),
+			nodeProtoBytes("MatMul", []string{"r", "w2"}, []string{"logits"}),
+			nodeProtoBytes("Softmax", []string{"logits"}, []string{"probs"}, intAttr("axis", 1)),
+		},
+		initializers: [][]byte{
+			rawTensor("w1", []int64{3, 2}, []float32{1, 0, 0, 1, 1, -1}),
+			rawTensor("b1", []int64{2}, []float32{0, 1}),
+			rawTensor("w2", []int64{2, 2}, []float32{1, 0, 0, 2}),
+		},
+		inputs:  [][]byte{valueInfo("x", Float32, -1, 3), valueInfo("w1", Float32, 3, 2)},
+		outputs: [][]byte{valueInfo("probs", Float32, -1, 2)},
+	}.encode(13)
+}
+
+func softmax2(a, b float64) []float32 {
+	ea, eb := math.Exp(a), math.Exp(b)
+	return []float32{float32(ea / (ea + eb)), float32(eb / (ea + eb))}
+}
+
+func TestMLP(t *testing.T) {
+	m, err := Read(mlpModel())
+	test.That(t, err, test.ShouldBeNil)
+
+	md := m.Info()
+	test.That(t, md.Name, test.ShouldEqual, "test_graph")
+	test.That(t, md.Description, test.ShouldEqual, "a test model")
+	test.That(t, md.Opset, test.ShouldEqual, 13)
+	test.That(t, md.Properties["model_type"], test.ShouldEqual, "classifier")
+	// Initializers listed as graph inputs are not inputs of the model.
+	test.That(t, md.Inputs, test.ShouldResemble, []TensorInfo{{Name: "x", DataType: Float32, Shape: []int{-1, 3}}})
+	test.That(t, md.Outputs, test.ShouldResemble, []TensorInfo{{Name: "probs", DataType: Float32, Shape: []int{-1, 2}}})
+
+	// A batch of two samples in one flat slice.
+	out, err := m.Infer([]float32{1, 2, 3, 0, 0, 2})
+	test.That(t, err, test.ShouldBeNil)
+	probs := out["probs"].([]float32)
+	test.That(t, len(probs), test.ShouldEqual, 4)
+	// h = x*w1 + b1 = [4, 0] then [2, -1], r = [4, 0] then [2, 0], logits = [4, 0] then [2, 0].
+	expected := append(softmax2(4, 0), softmax2(2, 0)...)
+	for i := range expected {
+		test.That(t, probs[i], test.ShouldAlmostEqual, expected[i], 1e-6)
+	}
+
+	// The same batch as nested lists, as decoded from JSON.
+	out, err = m.Infer([]interface{}{[]interface{}{1., 2., 3.}, []interface{}{0., 0., 2.}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out["probs"], test.ShouldResemble, probs)
+
+	_, err = m.Infer([]float32{1, 2})
+	test.That(t, err, test.ShouldBeError, `input "x" of 2 values does not fit the expected shape [-1 3]`)
+
+	filename := filepath.Join(t.TempDir(), "mlp.onnx")
+	test.That(t, os.WriteFile(filename, mlpModel(), 0o600), test.ShouldBeNil)
+	m, err = Load(filename)
+	test.That(t, err, test.ShouldBeNil)
+	out, err = m.Infer([]float64{1, 2, 3})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out["probs"], test.ShouldResemble, probs[:2])
+}
+
+func TestConvModel(t *testing.T) {
+	model := testGraph{
+		nodes: [][]byte{
+			nodeProtoBytes("Conv", []string{"image", "kernel", "bias"}, []string{"conv"},
+				intsAttr("pads", 1, 1, 1, 1), intsAttr("kernel_shape", 3, 3)),
+			nodeProtoBytes("MaxPool", []string{"conv"}, []string{"pool"},
+				intsAttr("kernel_shape", 2, 2), intsAttr("strides", 2, 2)),
+			nodeProtoBytes("GlobalAveragePool", []string{"pool"}, []string{"avg"}),
+			nodeProtoBytes("Flatten", []string{"pool"}, []string{"flat"}),
+			nodeProtoBytes("Shape", []string{"avg"}, []string{"shape"}),
+		},
+		initializers: [][]byte{
+			// The second filter is the identity, the first sums the 3x3 neighborhood.
+			rawTensor("kernel", []int64{2, 1, 3, 3}, []float32{
+				1, 1, 1, 1, 1, 1, 1, 1, 1,
+				0, 0, 0, 0, 1, 0, 0, 0, 0,
+			}),
+			rawTensor("bias", []int64{2}, []float32{0, 0.5}),
+		},
+		inputs:  [][]byte{valueInfo("image", Float32, 1, 1, 4, 4)},
+		outputs: [][]byte{valueInfo("flat", Float32), valueInfo("avg", Float32), valueInfo("shape", Int64)},
+	}.encode(11)
+	m, err := Read(model)
+	test.That(t, err, test.ShouldBeNil)
+
+	image := make([]float32, 16)
+	for i := range image {
+		image[i] = float32(i)
+	}
+	out, err := m.Infer(image)
+	test.That(t, err, test.ShouldBeNil)
+	// The maximum of each 2x2 block of neighborhood sums, and of each 2x2 block of the image plus 0.5.
+	test.That(t, out["flat"], test.ShouldResemble, []float32{45, 54, 81, 90, 5.5, 7.5, 13.5, 15.5})
+	test.That(t, out["avg"], test.ShouldResemble, []float32{67.5, 10.5})
+	test.That(t, out["shape"], test.ShouldResemble, []int64{1, 2, 1, 1})
+
+	_, err = m.Run(map[string]*Tensor{"image": newZeroTensor(Float32, []int{1, 1, 3, 3})})
+	test.That(t, err, test.ShouldBeError, `input "image" has shape [1 1 3 3] but the model expects [1 1 4 4]`)
+	_, err = m.Run(map[string]*Tensor{})
+	test.That(t, err, test.ShouldBeError, `missing input "image"`)
+}
+
+func TestShapeOperators(t *testing.T) {
+	// Exercises operators whose axes are given as inputs from opset 13.
+	model := testGraph{
+		nodes: [][]byte{
+			nodeProtoBytes("Reshape", []string{"x", "shape"}, []string{"r"}),
+			nodeProtoBytes("Transpose", []string{"r"}, []string{"t"}, intsAttr("perm", 1, 0)),
+			nodeProtoBytes("Slice", []string{"t", "starts", "ends", "axes", "steps"}, []string{"s"}),
+			nodeProtoBytes("Unsqueeze", []string{"s", "axes"}, []string{"u"}),
+			nodeProtoBytes("Concat", []string{"u", "u"}, []string{"c"}, intAttr("axis", 1)),
+			nodeProtoBytes("ReduceMean", []string{"t"}, []string{"mean"}, intsAttr("axes", 0), intAttr("keepdims", 0)),
+			nodeProtoBytes("ArgMax", []string{"t"}, []string{"argmax"}, intAttr("axis", 1)),
+			nodeProtoBytes("Gather", []string{"t", "indices"}, []string{"g"}),
+			nodeProtoBytes("Squeeze", []string{"u"}, []string{"sq"}),
+			nodeProtoBytes("Clip", []string{"x", "lo"}, []string{"clipped"}),
+			nodeProtoBytes("Cast", []string{"clipped"}, []string{"ints"}, intAttr("to", int64(Int32))),
+			nodeProtoBytes("Sub", []string{"ints", "ints"}, []string{"zeros"}),
+			nodeProtoBytes("LeakyRelu", []string{"x"}, []string{"leaky"}, floatAttr("alpha", 0.5)),
+		},
+		initializers: [][]byte{
+			int64Tensor("shape", []int64{2, -1}),
+			int64Tensor("starts", []int64{-1}),
+			int64Tensor("ends", []int64{math.MinInt64}),
+			int64Tensor("axes", []int64{0}),
+			int64Tensor("steps", []int64{-2}),
+			int64Tensor("indices", []int64{2}),
+			rawTensor("lo", nil, []float32{2}),
+		},
+		inputs: [][]byte{valueInfo("x", Float32, 6)},
+		outputs: [][]byte{
+			valueInfo("s", Float32), valueInfo("c", Float32), valueInfo("mean", Float32),
+			valueInfo("argmax", Int64), valueInfo("g", Float32), valueInfo("sq", Float32),
+			valueInfo("ints", Int32), valueInfo("zeros", Int32), valueInfo("leaky", Float32),
+		},
+	}.encode(13)
+	m, err := Read(model)
+	test.That(t, err, test.ShouldBeNil)
+
+	outputs, err := m.Run(map[string]*Tensor{"x": {DataType: Float32, Shape: []int{6}, Data: []float64{0, 1, 2, -3, 4, 5}}})
+	test.That(t, err, test.ShouldBeNil)
+	// r = [[0 1 2] [-3 4 5]], t = [[0 -3] [1 4] [2 5]]
+	test.That(t, outputs["s"].Shape, test.ShouldResemble, []int{2, 2})
+	test.That(t, outputs["s"].Data, test.ShouldResemble, []float64{2, 5, 0, -3})
+	test.That(t, outputs["c"].Shape, test.ShouldResemble, []int{1, 4, 2})
+	test.That(t, outputs["c"].Data, test.ShouldResemble, []float64{2, 5, 0, -3, 2, 5, 0, -3})
+	test.That(t, outputs["mean"].Shape, test.ShouldResemble, []int{2})
+	test.That(t, outputs["mean"].Data, test.ShouldResemble, []float64{1, 2})
+	test.That(t, outputs["argmax"].Data, test.ShouldResemble, []float64{0, 1, 1})
+	test.That(t, outputs["g"].Data, test.ShouldResemble, []float64{2, 5})
+	test.That(t, outputs["sq"].Shape, test.ShouldResemble, []int{2, 2})
+	test.That(t, outputs["ints"].Values(), test.ShouldResemble, []int32{2, 2, 2, 2, 4, 5})
+	test.That(t, outputs["zeros"].Values(), test.ShouldResemble, []int32{0, 0, 0, 0, 0, 0})
+	test.That(t, outputs["leaky"].Data, test.ShouldResemble, []float64{0, 1, 2, -1.5, 4, 5})
+}
+
+func TestReadErrors(t *testing.T) {
+	_, err := Read([]byte{0xff})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error parsing onnx model")
+
+	_, err = Read(testGraph{
+		nodes: [][]byte{
+			nodeProtoBytes("Relu", []string{"x"}, []string{"y"}),
+			nodeProtoBytes("NonMaxSuppression", []string{"y"}, []string{"z"}),
+			nodeProtoBytes("LSTM", []string{"y"}, []string{"z"}),
+		},
+		inputs:  [][]byte{valueInfo("x", Float32, 1)},
+		outputs: [][]byte{valueInfo("z", Float32, 1)},
+	}.encode(13))
+	test.That(t, err.Error(), test.ShouldStartWith, "onnx model uses unsupported operators [LSTM NonMaxSuppression]")
+
+	_, err = Read(testGraph{
+		nodes:   [][]byte{nodeProtoBytes("Relu", []string{"w"}, []string{"y"})},
+		inputs:  [][]byte{valueInfo("x", Float32, 1)},
+		outputs: [][]byte{valueInfo("y", Float32, 1)},
+	}.encode(13))
+	test.That(t, err, test.ShouldBeError, `input "w" of node 0 (Relu) is not defined by a previous node`)
+
+	_, err = Read(testGraph{
+		inputs:  [][]byte{valueInfo("x", Float32, 1)},
+		outputs: [][]byte{valueInfo("y", Float32, 1)},
+	}.encode(13))
+	test.That(t, err, test.ShouldBeError, `output "y" of the onnx model is not computed by the graph`)
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.onnx"))
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error reading onnx model")
+}
+
+func TestNewInput(t *testing.T) {
+	info := TensorInfo{Name: "in", DataType: Uint8, Shape: []int{-1, -1, 3}}
+	tensor, err := NewInput(info, []interface{}{
+		[]interface{}{[]interface{}{1, 2, 3}, []interface{}{4, 5, 6}},
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tensor.Shape, test.ShouldResemble, []int{1, 2, 3})
+	test.That(t, tensor.Values(), test.ShouldResemble, []uint8{1, 2, 3, 4, 5, 6})
+
+	_, err = NewInput(info, []uint8{1, 2, 3})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "several unknown dimensions")
+
+	_, err = NewInput(info, []interface{}{[]interface{}{1, 2}, []interface{}{3}})
+	test.That(t, err, test.ShouldBeError, `input "in": nested lists have different lengths`)
+
+	_, err = NewInput(info, []interface{}{"a"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "cannot use a of type string as a tensor value")
+
+	tensor, err = NewInput(TensorInfo{DataType: Float32}, []bool{true, false})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tensor.Values(), test.ShouldResemble, []float32{1, 0})
+}
@@ -0,0 +1,1006 @@
+package onnx
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
+)
+
+// node is a graph node bound to the implementation of its operator.
+type node struct {
+	name    string
+	opType  string
+	inputs  []string
+	outputs []string
+	attrs   attributes
+	// opset is the version of the default operator set the model was exported with, which selects between
+	// the behaviors of operators that changed over time.
+	opset int64
+	run   operator
+}
+
+// operator computes the outputs of a node from its inputs. Optional inputs that are omitted are nil.
+type operator func(n *node, inputs []*Tensor) ([]*Tensor, error)
+
+// operators holds every supported operator of the default ONNX domain.
+var operators map[string]operator
+
+func init() {
+	operators = map[string]operator{
+		"Abs":                unary(math.Abs),
+		"Add":                binaryOp(func(a, b float64) float64 { return a + b }),
+		"ArgMax":             argMax,
+		"AveragePool":        averagePool,
+		"BatchNormalization": batchNormalization,
+		"Cast":               cast,
+		"Ceil":               unary(math.Ceil),
+		"Clip":               clip,
+		"Concat":             concat,
+		"Constant":           constant,
+		"Conv":               conv,
+		"Div":                binaryOp(func(a, b float64) float64 { return a / b }),
+		"Dropout":            identity,
+		"Elu": func(n *node, in []*Tensor) ([]*Tensor, error) {
+			alpha := n.attrs.float("alpha", 1)
+			return unaryFn(in[0], func(x float64) float64 {
+				if x < 0 {
+					return alpha * (math.Exp(x) - 1)
+				}
+				return x
+			}), nil
+		},
+		"Erf":     unary(math.Erf),
+		"Exp":     unary(math.Exp),
+		"Flatten": flatten,
+		"Floor":   unary(math.Floor),
+		"Gather":  gather,
+		"Gemm":    gemm,
+		"GlobalAveragePool": func(n *node, in []*Tensor) ([]*Tensor, error) {
+			return globalPool(in[0], false)
+		},
+		"GlobalMaxPool": func(n *node, in []*Tensor) ([]*Tensor, error) {
+			return globalPool(in[0], true)
+		},
+		"HardSigmoid": func(n *node, in []*Tensor) ([]*Tensor, error) {
+			alpha, beta := n.attrs.float("alpha", 0.2), n.attrs.float("beta", 0.5)
+			return unaryFn(in[0], func(x float64) float64 { return math.Max(0, math.Min(1, alpha*x+beta)) }), nil
+		},
+		"Identity": identity,
+		"LeakyRelu": func(n *node, in []*Tensor) ([]*Tensor, error) {
+			alpha := n.attrs.float("alpha", 0.01)
+			return unaryFn(in[0], func(x float64) float64 {
+				if x < 0 {
+					return alpha * x
+				}
+				return x
+			}), nil
+		},
+		"Log":        unary(math.Log),
+		"LogSoftmax": softmax(true),
+		"MatMul":     matMul,
+		"Max":        variadic(math.Max),
+		"MaxPool":    maxPool,
+		"Min":        variadic(math.Min),
+		"Mul":        binaryOp(func(a, b float64) float64 { return a * b }),
+		"Neg":        unary(func(x float64) float64 { return -x }),
+		"Pow":        binaryOp(math.Pow),
+		"ReduceMean": reduceMean,
+		"Relu":       unary(func(x float64) float64 { return math.Max(0, x) }),
+		"Reshape":    reshape,
+		"Shape": func(n *node, in []*Tensor) ([]*Tensor, error) {
+			data := make([]float64, len(in[0].Shape))
+			for i, d := range in[0].Shape {
+				data[i] = float64(d)
+			}
+			return []*Tensor{{DataType: Int64, Shape: []int{len(data)}, Data: data}}, nil
+		},
+		"Sigmoid":   unary(func(x float64) float64 { return 1 / (1 + math.Exp(-x)) }),
+		"Slice":     slice,
+		"Softmax":   softmax(false),
+		"Sqrt":      unary(math.Sqrt),
+		"Squeeze":   squeeze,
+		"Sub":       binaryOp(func(a, b float64) float64 { return a - b }),
+		"Sum":       variadic(func(a, b float64) float64 { return a + b }),
+		"Tanh":      unary(math.Tanh),
+		"Transpose": transpose,
+		"Unsqueeze": unsqueeze,
+	}
+}
+
+// attributes are the attributes of a node by name.
+type attributes map[string]*attributeProto
+
+func (a attributes) int(name string, def int64) int64 {
+	if attr, ok := a[name]; ok {
+		return attr.i
+	}
+	return def
+}
+
+func (a attributes) float(name string, def float64) float64 {
+	if attr, ok := a[name]; ok {
+		return float64(attr.f)
+	}
+	return def
+}
+
+func (a attributes) string(name, def string) string {
+	if attr, ok := a[name]; ok {
+		return string(attr.s)
+	}
+	return def
+}
+
+func (a attributes) ints(name string, def []int64) []int64 {
+	if attr, ok := a[name]; ok {
+		return attr.ints
+	}
+	return def
+}
+
+func identity(n *node, in []*Tensor) ([]*Tensor, error) {
+	return []*Tensor{in[0]}, nil
+}
+
+func unaryFn(t *Tensor, fn func(float64) float64) []*Tensor {
+	out := &Tensor{DataType: t.DataType, Shape: t.Shape, Data: make([]float64, len(t.Data))}
+	for i, v := range t.Data {
+		out.Data[i] = fn(v)
+	}
+	return []*Tensor{out}
+}
+
+func unary(fn func(float64) float64) operator {
+	return func(n *node, in []*Tensor) ([]*Tensor, error) {
+		return unaryFn(in[0], fn), nil
+	}
+}
+
+func binaryFn(a, b *Tensor, fn func(a, b float64) float64) (*Tensor, error) {
+	shape, err := broadcastShapes(a.Shape, b.Shape)
+	if err != nil {
+		return nil, err
+	}
+	out := newZeroTensor(a.DataType, shape)
+	broadcastIndex(shape, [][]int{a.Shape, b.Shape}, func(i int, idx []int) {
+		out.Data[i] = fn(a.Data[idx[0]], b.Data[idx[1]])
+	})
+	if !out.DataType.isFloat() {
+		for i, v := range out.Data {
+			out.Data[i] = castValue(out.DataType, math.Trunc(v))
+		}
+	}
+	return out, nil
+}
+
+func binaryOp(fn func(a, b float64) float64) operator {
+	return func(n *node, in []*Tensor) ([]*Tensor, error) {
+		out, err := binaryFn(in[0], in[1], fn)
+		if err != nil {
+			return nil, err
+		}
+		return []*Tensor{out}, nil
+	}
+}
+
+func variadic(fn func(a, b float64) float64) operator {
+	return func(n *node, in []*Tensor) ([]*Tensor, error) {
+		out := in[0]
+		for _, t := range in[1:] {
+			var err error
+			if out, err = binaryFn(out, t, fn); err != nil {
+				return nil, err
+			}
+		}
+		return []*Tensor{out}, nil
+	}
+}
+
+func clip(n *node, in []*Tensor) ([]*Tensor, error) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if n.opset < 11 {
+		lo, hi = n.attrs.float("min", lo), n.attrs.float("max", hi)
+	} else {
+		if len(in) > 1 && in[1] != nil {
+			lo = in[1].Data[0]
+		}
+		if len(in) > 2 && in[2] != nil {
+			hi = in[2].Data[0]
+		}
+	}
+	return unaryFn(in[0], func(x float64) float64 { return math.Max(lo, math.Min(hi, x)) }), nil
+}
+
+func cast(n *node, in []*Tensor) ([]*Tensor, error) {
+	to := DataType(n.attrs.int("to", 0))
+	if !to.supported() {
+		return nil, errors.Errorf("cannot cast to unsupported data type %d", to)
+	}
+	out := unaryFn(in[0], func(x float64) float64 { return castValue(to, x) })
+	out[0].DataType = to
+	return out, nil
+}
+
+func constant(n *node, in []*Tensor) ([]*Tensor, error) {
+	if attr, ok := n.attrs["value"]; ok {
+		t, err := tensorFromProto(attr.t)
+		if err != nil {
+			return nil, err
+		}
+		return []*Tensor{t}, nil
+	}
+	if attr, ok := n.attrs["value_float"]; ok {
+		return []*Tensor{scalar(Float32, float64(attr.f))}, nil
+	}
+	if attr, ok := n.attrs["value_int"]; ok {
+		return []*Tensor{scalar(Int64, float64(attr.i))}, nil
+	}
+	if attr, ok := n.attrs["value_floats"]; ok {
+		data := make([]float64, len(attr.floats))
+		for i, v := range attr.floats {
+			data[i] = float64(v)
+		}
+		return []*Tensor{{DataType: Float32, Shape: []int{len(data)}, Data: data}}, nil
+	}
+	if attr, ok := n.attrs["value_ints"]; ok {
+		data := make([]float64, len(attr.ints))
+		for i, v := range attr.ints {
+			data[i] = float64(v)
+		}
+		return []*Tensor{{DataType: Int64, Shape: []int{len(data)}, Data: data}}, nil
+	}
+	return nil, errors.New("constant has no supported value attribute")
+}
+
+// matMul multiplies matrices with numpy semantics: leading dimensions are broadcast batch dimensions, and
+// one dimensional operands are promoted to matrices and the added dimension removed from the result.
+func matMul(n *node, in []*Tensor) ([]*Tensor, error) {
+	a, b := in[0], in[1]
+	aShape, bShape := a.Shape, b.Shape
+	if len(aShape) == 1 {
+		aShape = []int{1, aShape[0]}
+	}
+	if len(bShape) == 1 {
+		bShape = []int{bShape[0], 1}
+	}
+	m, k := aShape[len(aShape)-2], aShape[len(aShape)-1]
+	k2, cols := bShape[len(bShape)-2], bShape[len(bShape)-1]
+	if k != k2 {
+		return nil, errors.Errorf("cannot multiply matrices of shapes %v and %v", a.Shape, b.Shape)
+	}
+	batch, err := broadcastShapes(aShape[:len(aShape)-2], bShape[:len(bShape)-2])
+	if err != nil {
+		return nil, err
+	}
+	outShape := append(append([]int{}, batch...), m, cols)
+	out := newZeroTensor(a.DataType, outShape)
+	aBatch := append(append([]int{}, aShape[:len(aShape)-2]...), 1)
+	bBatch := append(append([]int{}, bShape[:len(bShape)-2]...), 1)
+	broadcastIndex(append(append([]int{}, batch...), 1), [][]int{aBatch, bBatch}, func(i int, idx []int) {
+		blas64.Gemm(blas.NoTrans, blas.NoTrans, 1,
+			blas64.General{Rows: m, Cols: k, Stride: k, Data: a.Data[idx[0]*m*k : (idx[0]+1)*m*k]},
+			blas64.General{Rows: k, Cols: cols, Stride: cols, Data: b.Data[idx[1]*k*cols : (idx[1]+1)*k*cols]},
+			0, blas64.General{Rows: m, Cols: cols, Stride: cols, Data: out.Data[i*m*cols : (i+1)*m*cols]})
+	})
+
+	// Remove the dimensions added to one dimensional operands.
+	final := outShape
+	if len(b.Shape) == 1 {
+		final = final[:len(final)-1]
+	}
+	if len(a.Shape) == 1 {
+		row := len(outShape) - 2
+		final = append(final[:row:row], final[row+1:]...)
+	}
+	out.Shape = final
+	return []*Tensor{out}, nil
+}
+
+// gemm computes alpha*A'*B' + beta*C, where A' and B' are optionally transposed and C is broadcast.
+func gemm(n *node, in []*Tensor) ([]*Tensor, error) {
+	a, b := in[0], in[1]
+	if len(a.Shape) != 2 || len(b.Shape) != 2 {
+		return nil, errors.New("gemm inputs must be matrices")
+	}
+	transA, transB := blas.NoTrans, blas.NoTrans
+	m, k := a.Shape[0], a.Shape[1]
+	if n.attrs.int("transA", 0) != 0 {
+		transA = blas.Trans
+		m, k = k, m
+	}
+	k2, cols := b.Shape[0], b.Shape[1]
+	if n.attrs.int("transB", 0) != 0 {
+		transB = blas.Trans
+		k2, cols = cols, k2
+	}
+	if k != k2 {
+		return nil, errors.Errorf("cannot multiply matrices of shapes %v and %v", a.Shape, b.Shape)
+	}
+	out := newZeroTensor(a.DataType, []int{m, cols})
+	beta := n.attrs.float("beta", 1)
+	if len(in) > 2 && in[2] != nil {
+		c := in[2]
+		if _, err := broadcastShapes(c.Shape, out.Shape); err != nil {
+			return nil, err
+		}
+		broadcastIndex(out.Shape, [][]int{c.Shape}, func(i int, idx []int) {
+			out.Data[i] = c.Data[idx[0]]
+		})
+	} else {
+		beta = 0
+	}
+	blas64.Gemm(transA, transB, n.attrs.float("alpha", 1),
+		blas64.General{Rows: a.Shape[0], Cols: a.Shape[1], Stride: a.Shape[1], Data: a.Data},
+		blas64.General{Rows: b.Shape[0], Cols: b.Shape[1], Stride: b.Shape[1], Data: b.Data},
+		beta, blas64.General{Rows: m, Cols: cols, Stride: cols, Data: out.Data})
+	return []*Tensor{out}, nil
+}
+
+// poolGeometry holds the output size and padding of a 2D convolution or pooling window.
+type poolGeometry struct {
+	kernel, strides, dilations []int
+	padsBegin                  []int
+	outShape                   []int
+}
+
+// windowGeometry computes the spatial output shape of sliding a kernel over an NCHW input.
+func windowGeometry(n *node, inShape, kernel []int, ceilMode bool) (poolGeometry, error) {
+	spatial := len(inShape) - 2
+	if spatial != 2 || len(kernel) != 2 {
+		return poolGeometry{}, errors.Errorf("%s only supports 2D inputs, got shape %v", n.opType, inShape)
+	}
+	g := poolGeometry{kernel: kernel, strides: []int{1, 1}, dilations: []int{1, 1}, padsBegin: []int{0, 0}}
+	for i, s := range n.attrs.ints("strides", nil) {
+		g.strides[i] = int(s)
+	}
+	for i, d := range n.attrs.ints("dilations", nil) {
+		g.dilations[i] = int(d)
+	}
+	padsEnd := []int{0, 0}
+	autoPad := n.attrs.string("auto_pad", "NOTSET")
+	for i := 0; i < spatial; i++ {
+		in := inShape[2+i]
+		extent := (kernel[i]-1)*g.dilations[i] + 1
+		switch autoPad {
+		case "SAME_UPPER", "SAME_LOWER":
+			out := (in + g.strides[i] - 1) / g.strides[i]
+			total := (out-1)*g.strides[i] + extent - in
+			if total < 0 {
+				total = 0
+			}
+			if autoPad == "SAME_UPPER" {
+				g.padsBegin[i], padsEnd[i] = total/2, total-total/2
+			} else {
+				g.padsBegin[i], padsEnd[i] = total-total/2, total/2
+			}
+		case "VALID":
+		case "NOTSET":
+			if pads := n.attrs.ints("pads", nil); len(pads) == 2*spatial {
+				g.padsBegin[i], padsEnd[i] = int(pads[i]), int(pads[i+spatial])
+			}
+		default:
+			return poolGeometry{}, errors.Errorf("unsupported auto_pad %q", autoPad)
+		}
+		span := in + g.padsBegin[i] + padsEnd[i] - extent
+		out := span/g.strides[i] + 1
+		if ceilMode && span%g.strides[i] != 0 {
+			out++
+		}
+		if out <= 0 {
+			return poolGeometry{}, errors.Errorf("%s kernel %v is larger than input %v", n.opType, kernel, inShape)
+		}
+		g.outShape = append(g.outShape, out)
+	}
+	return g, nil
+}
+
+// conv computes a 2D convolution of an NCHW input with grouped MxC/groupxKhxKw weights, by unrolling the
+// input windows into a matrix and multiplying it with the weights.
+func conv(n *node, in []*Tensor) ([]*Tensor, error) {
+	x, w := in[0], in[1]
+	if len(x.Shape) != 4 || len(w.Shape) != 4 {
+		return nil, errors.Errorf("Conv only supports 2D inputs, got shapes %v and %v", x.Shape, w.Shape)
+	}
+	kernel := []int{w.Shape[2], w.Shape[3]}
+	if ks := n.attrs.ints("kernel_shape", nil); len(ks) == 2 {
+		kernel = []int{int(ks[0]), int(ks[1])}
+	}
+	g, err := windowGeometry(n, x.Shape, kernel, false)
+	if err != nil {
+		return nil, err
+	}
+	batch, channels, height, width := x.Shape[0], x.Shape[1], x.Shape[2], x.Shape[3]
+	groups := int(n.attrs.int("group", 1))
+	filters := w.Shape[0]
+	groupChannels := channels / groups
+	groupFilters := filters / groups
+	if groupChannels*groups != channels || w.Shape[1] != groupChannels || groupFilters*groups != filters {
+		return nil, errors.Errorf("Conv weights of shape %v do not match input of shape %v with %d groups",
+			w.Shape, x.Shape, groups)
+	}
+	outH, outW := g.outShape[0], g.outShape[1]
+	out := newZeroTensor(x.DataType, []int{batch, filters, outH, outW})
+
+	// cols holds, for every output position, the input values under the kernel for one group.
+	rows := groupChannels * kernel[0] * kernel[1]
+	positions := outH * outW
+	cols := make([]float64, rows*positions)
+	for b := 0; b < batch; b++ {
+		for grp := 0; grp < groups; grp++ {
+			for c := 0; c < groupChannels; c++ {
+				plane := x.Data[((b*channels)+grp*groupChannels+c)*height*width:]
+				for ky := 0; ky < kernel[0]; ky++ {
+					for kx := 0; kx < kernel[1]; kx++ {
+						row := cols[((c*kernel[0]+ky)*kernel[1]+kx)*positions:]
+						for oy := 0; oy < outH; oy++ {
+							iy := oy*g.strides[0] - g.padsBegin[0] + ky*g.dilations[0]
+							for ox := 0; ox < outW; ox++ {
+								ix := ox*g.strides[1] - g.padsBegin[1] + kx*g.dilations[1]
+								v := 0.
+								if iy >= 0 && iy < height && ix >= 0 && ix < width {
+									v = plane[iy*width+ix]
+								}
+								row[oy*outW+ox] = v
+							}
+						}
+					}
+				}
+			}
+			weights := w.Data[grp*groupFilters*rows : (grp+1)*groupFilters*rows]
+			dst := out.Data[(b*filters+grp*groupFilters)*positions : (b*filters+(grp+1)*groupFilters)*positions]
+			blas64.Gemm(blas.NoTrans, blas.NoTrans, 1,
+				blas64.General{Rows: groupFilters, Cols: rows, Stride: rows, Data: weights},
+				blas64.General{Rows: rows, Cols: positions, Stride: positions, Data: cols},
+				0, blas64.General{Rows: groupFilters, Cols: positions, Stride: positions, Data: dst})
+		}
+	}
+	if len(in) > 2 && in[2] != nil {
+		bias := in[2].Data
+		for b := 0; b < batch; b++ {
+			for f := 0; f < filters; f++ {
+				plane := out.Data[(b*filters+f)*positions : (b*filters+f+1)*positions]
+				for i := range plane {
+					plane[i] += bias[f]
+				}
+			}
+		}
+	}
+	return []*Tensor{out}, nil
+}
+
+// pool slides a window over an NCHW input and reduces the values under it.
+func pool(n *node, x *Tensor, isMax bool) ([]*Tensor, error) {
+	ks := n.attrs.ints("kernel_shape", nil)
+	kernel := make([]int, len(ks))
+	for i, k := range ks {
+		kernel[i] = int(k)
+	}
+	g, err := windowGeometry(n, x.Shape, kernel, n.attrs.int("ceil_mode", 0) != 0)
+	if err != nil {
+		return nil, err
+	}
+	countPad := n.attrs.int("count_include_pad", 0) != 0
+	batch, channels, height, width := x.Shape[0], x.Shape[1], x.Shape[2], x.Shape[3]
+	outH, outW := g.outShape[0], g.outShape[1]
+	out := newZeroTensor(x.DataType, []int{batch, channels, outH, outW})
+	for p := 0; p < batch*channels; p++ {
+		plane := x.Data[p*height*width:]
+		for oy := 0; oy < outH; oy++ {
+			for ox := 0; ox < outW; ox++ {
+				acc, count := 0., 0
+				if isMax {
+					acc = math.Inf(-1)
+				}
+				for ky := 0; ky < kernel[0]; ky++ {
+					iy := oy*g.strides[0] - g.padsBegin[0] + ky*g.dilations[0]
+					for kx := 0; kx < kernel[1]; kx++ {
+						ix := ox*g.strides[1] - g.padsBegin[1] + kx*g.dilations[1]
+						if iy < 0 || iy >= height || ix < 0 || ix >= width {
+							// Padding past the end of the input, added by ceil_mode, is never counted.
+							if countPad && iy < height+g.padsBegin[0] && ix < width+g.padsBegin[1] {
+								count++
+							}
+							continue
+						}
+						v := plane[iy*width+ix]
+						if isMax {
+							acc = math.Max(acc, v)
+						} else {
+							acc += v
+						}
+						count++
+					}
+				}
+				if !isMax && count > 0 {
+					acc /= float64(count)
+				}
+				out.Data[(p*outH+oy)*outW+ox] = acc
+			}
+		}
+	}
+	return []*Tensor{out}, nil
+}
+
+func maxPool(n *node, in []*Tensor) ([]*Tensor, error) {
+	return pool(n, in[0], true)
+}
+
+func averagePool(n *node, in []*Tensor) ([]*Tensor, error) {
+	return pool(n, in[0], false)
+}
+
+// globalPool reduces every channel of an NC... input to a single value.
+func globalPool(x *Tensor, isMax bool) ([]*Tensor, error) {
+	if len(x.Shape) < 3 {
+		return nil, errors.Errorf("global pooling needs at least 3 dimensions, got shape %v", x.Shape)
+	}
+	outShape := []int{x.Shape[0], x.Shape[1]}
+	for range x.Shape[2:] {
+		outShape = append(outShape, 1)
+	}
+	out := newZeroTensor(x.DataType, outShape)
+	size := shapeSize(x.Shape[2:])
+	for p := range out.Data {
+		plane := x.Data[p*size : (p+1)*size]
+		acc := 0.
+		if isMax {
+			acc = math.Inf(-1)
+		}
+		for _, v := range plane {
+			if isMax {
+				acc = math.Max(acc, v)
+			} else {
+				acc += v
+			}
+		}
+		if !isMax {
+			acc /= float64(size)
+		}
+		out.Data[p] = acc
+	}
+	return []*Tensor{out}, nil
+}
+
+func batchNormalization(n *node, in []*Tensor) ([]*Tensor, error) {
+	x, scale, bias, mean, variance := in[0], in[1], in[2], in[3], in[4]
+	if len(x.Shape) < 2 {
+		return nil, errors.Errorf("BatchNormalization needs at least 2 dimensions, got shape %v", x.Shape)
+	}
+	epsilon := n.attrs.float("epsilon", 1e-5)
+	channels := x.Shape[1]
+	size := shapeSize(x.Shape[2:])
+	out := newZeroTensor(x.DataType, x.Shape)
+	for i := range x.Data {
+		c := (i / size) % channels
+		out.Data[i] = scale.Data[c]*(x.Data[i]-mean.Data[c])/math.Sqrt(variance.Data[c]+epsilon) + bias.Data[c]
+	}
+	return []*Tensor{out}, nil
+}
+
+// softmax normalizes exponentials along an axis. Before opset 13 the input is flattened into a matrix at
+// the axis, which defaults to 1, and every row normalized; from opset 13 only the axis, which defaults to
+// the last, is normalized.
+func softmax(logarithm bool) operator {
+	return func(n *node, in []*Tensor) ([]*Tensor, error) {
+		x := in[0]
+		def := int64(-1)
+		if n.opset < 13 {
+			def = 1
+		}
+		axis, err := normalizeAxis(n.attrs.int("axis", def), len(x.Shape))
+		if err != nil {
+			return nil, err
+		}
+		outer, length, inner := shapeSize(x.Shape[:axis]), x.Shape[axis], shapeSize(x.Shape[axis+1:])
+		if n.opset < 13 {
+			length, inner = length*inner, 1
+		}
+		out := newZeroTensor(x.DataType, x.Shape)
+		for o := 0; o < outer; o++ {
+			for i := 0; i < inner; i++ {
+				base := o*length*inner + i
+				maxV := math.Inf(-1)
+				for j := 0; j < length; j++ {
+					maxV = math.Max(maxV, x.Data[base+j*inner])
+				}
+				sum := 0.
+				for j := 0; j < length; j++ {
+					sum += math.Exp(x.Data[base+j*inner] - maxV)
+				}
+				for j := 0; j < length; j++ {
+					v := x.Data[base+j*inner] - maxV
+					if logarithm {
+						out.Data[base+j*inner] = v - math.Log(sum)
+					} else {
+						out.Data[base+j*inner] = math.Exp(v) / sum
+					}
+				}
+			}
+		}
+		return []*Tensor{out}, nil
+	}
+}
+
+func flatten(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	axis := int(n.attrs.int("axis", 1))
+	if axis < 0 {
+		axis += len(x.Shape)
+	}
+	if axis < 0 || axis > len(x.Shape) {
+		return nil, errors.Errorf("Flatten axis %d is out of range for shape %v", axis, x.Shape)
+	}
+	return []*Tensor{{
+		DataType: x.DataType,
+		Shape:    []int{shapeSize(x.Shape[:axis]), shapeSize(x.Shape[axis:])},
+		Data:     x.Data,
+	}}, nil
+}
+
+func reshape(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	requested := in[1].ints()
+	shape := make([]int, len(requested))
+	inferred := -1
+	known := 1
+	for i, d := range requested {
+		switch {
+		case d == 0 && n.attrs.int("allowzero", 0) == 0:
+			if i >= len(x.Shape) {
+				return nil, errors.Errorf("cannot copy dimension %d of shape %v", i, x.Shape)
+			}
+			shape[i] = x.Shape[i]
+		case d == -1:
+			if inferred >= 0 {
+				return nil, errors.New("reshape can only infer one dimension")
+			}
+			inferred = i
+			continue
+		default:
+			shape[i] = int(d)
+		}
+		known *= shape[i]
+	}
+	if inferred >= 0 {
+		if known == 0 || len(x.Data)%known != 0 {
+			return nil, errors.Errorf("cannot reshape %v into %v", x.Shape, requested)
+		}
+		shape[inferred] = len(x.Data) / known
+	}
+	if shapeSize(shape) != len(x.Data) {
+		return nil, errors.Errorf("cannot reshape %v into %v", x.Shape, requested)
+	}
+	return []*Tensor{{DataType: x.DataType, Shape: shape, Data: x.Data}}, nil
+}
+
+// axesInput returns the axes of Squeeze, Unsqueeze and ReduceMean, which moved from an attribute to an
+// optional input in opset 13 (18 for ReduceMean).
+func axesInput(n *node, in []*Tensor, inputOpset int64) []int64 {
+	if n.opset >= inputOpset {
+		if len(in) > 1 && in[1] != nil {
+			return in[1].ints()
+		}
+		return nil
+	}
+	return n.attrs.ints("axes", nil)
+}
+
+func squeeze(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	remove := map[int]bool{}
+	axes := axesInput(n, in, 13)
+	for _, a := range axes {
+		axis, err := normalizeAxis(a, len(x.Shape))
+		if err != nil {
+			return nil, err
+		}
+		if x.Shape[axis] != 1 {
+			return nil, errors.Errorf("cannot squeeze axis %d of shape %v", a, x.Shape)
+		}
+		remove[axis] = true
+	}
+	shape := []int{}
+	for i, d := range x.Shape {
+		if remove[i] || (len(axes) == 0 && d == 1) {
+			continue
+		}
+		shape = append(shape, d)
+	}
+	return []*Tensor{{DataType: x.DataType, Shape: shape, Data: x.Data}}, nil
+}
+
+func unsqueeze(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	axes := axesInput(n, in, 13)
+	rank := len(x.Shape) + len(axes)
+	insert := map[int]bool{}
+	for _, a := range axes {
+		axis, err := normalizeAxis(a, rank)
+		if err != nil {
+			return nil, err
+		}
+		insert[axis] = true
+	}
+	shape := make([]int, 0, rank)
+	j := 0
+	for i := 0; i < rank; i++ {
+		if insert[i] {
+			shape = append(shape, 1)
+			continue
+		}
+		shape = append(shape, x.Shape[j])
+		j++
+	}
+	return []*Tensor{{DataType: x.DataType, Shape: shape, Data: x.Data}}, nil
+}
+
+func concat(n *node, in []*Tensor) ([]*Tensor, error) {
+	first := in[0]
+	axis, err := normalizeAxis(n.attrs.int("axis", 0), len(first.Shape))
+	if err != nil {
+		return nil, err
+	}
+	shape := append([]int{}, first.Shape...)
+	shape[axis] = 0
+	for _, t := range in {
+		if len(t.Shape) != len(shape) {
+			return nil, errors.New("concatenated tensors must have the same rank")
+		}
+		for i := range shape {
+			if i != axis && t.Shape[i] != shape[i] {
+				return nil, errors.Errorf("cannot concatenate shapes %v and %v on axis %d", first.Shape, t.Shape, axis)
+			}
+		}
+		shape[axis] += t.Shape[axis]
+	}
+	outer := shapeSize(shape[:axis])
+	out := newZeroTensor(first.DataType, shape)
+	offset := 0
+	for o := 0; o < outer; o++ {
+		for _, t := range in {
+			chunk := shapeSize(t.Shape[axis:])
+			copy(out.Data[offset:], t.Data[o*chunk:(o+1)*chunk])
+			offset += chunk
+		}
+	}
+	return []*Tensor{out}, nil
+}
+
+func transpose(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	rank := len(x.Shape)
+	perm := n.attrs.ints("perm", nil)
+	if perm == nil {
+		for i := rank - 1; i >= 0; i-- {
+			perm = append(perm, int64(i))
+		}
+	}
+	if len(perm) != rank {
+		return nil, errors.Errorf("transpose permutation %v does not match rank %d", perm, rank)
+	}
+	inStrides := strides(x.Shape)
+	shape := make([]int, rank)
+	permStrides := make([]int, rank)
+	for i, p := range perm {
+		shape[i] = x.Shape[p]
+		permStrides[i] = inStrides[p]
+	}
+	out := newZeroTensor(x.DataType, shape)
+	coord := make([]int, rank)
+	src := 0
+	for i := range out.Data {
+		out.Data[i] = x.Data[src]
+		for d := rank - 1; d >= 0; d-- {
+			coord[d]++
+			src += permStrides[d]
+			if coord[d] < shape[d] {
+				break
+			}
+			src -= permStrides[d] * shape[d]
+			coord[d] = 0
+		}
+	}
+	return []*Tensor{out}, nil
+}
+
+func gather(n *node, in []*Tensor) ([]*Tensor, error) {
+	x, indices := in[0], in[1]
+	axis, err := normalizeAxis(n.attrs.int("axis", 0), len(x.Shape))
+	if err != nil {
+		return nil, err
+	}
+	outer, length, inner := shapeSize(x.Shape[:axis]), x.Shape[axis], shapeSize(x.Shape[axis+1:])
+	shape := append(append(append([]int{}, x.Shape[:axis]...), indices.Shape...), x.Shape[axis+1:]...)
+	out := newZeroTensor(x.DataType, shape)
+	offset := 0
+	for o := 0; o < outer; o++ {
+		for _, idx := range indices.ints() {
+			if idx < 0 {
+				idx += int64(length)
+			}
+			if idx < 0 || int(idx) >= length {
+				return nil, errors.Errorf("gather index %d is out of range for axis of length %d", idx, length)
+			}
+			start := (o*length + int(idx)) * inner
+			copy(out.Data[offset:], x.Data[start:start+inner])
+			offset += inner
+		}
+	}
+	return []*Tensor{out}, nil
+}
+
+func slice(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	rank := len(x.Shape)
+	var starts, ends, axes, steps []int64
+	if n.opset < 10 {
+		starts, ends, axes = n.attrs.ints("starts", nil), n.attrs.ints("ends", nil), n.attrs.ints("axes", nil)
+	} else {
+		starts, ends = in[1].ints(), in[2].ints()
+		if len(in) > 3 && in[3] != nil {
+			axes = in[3].ints()
+		}
+		if len(in) > 4 && in[4] != nil {
+			steps = in[4].ints()
+		}
+	}
+	begin := make([]int, rank)
+	step := make([]int, rank)
+	shape := append([]int{}, x.Shape...)
+	for i := range step {
+		step[i] = 1
+	}
+	for i := range starts {
+		axis := i
+		if axes != nil {
+			var err error
+			if axis, err = normalizeAxis(axes[i], rank); err != nil {
+				return nil, err
+			}
+		}
+		st := int64(1)
+		if steps != nil {
+			st = steps[i]
+		}
+		if st == 0 {
+			return nil, errors.New("slice step cannot be zero")
+		}
+		dim := int64(x.Shape[axis])
+		clamp := func(v, lo, hi int64) int64 {
+			if v < 0 {
+				v += dim
+			}
+			if v < lo {
+				return lo
+			}
+			if v > hi {
+				return hi
+			}
+			return v
+		}
+		var s, e int64
+		if st > 0 {
+			s, e = clamp(starts[i], 0, dim), clamp(ends[i], 0, dim)
+		} else {
+			s, e = clamp(starts[i], 0, dim-1), clamp(ends[i], -1, dim-1)
+			if ends[i] < -dim {
+				e = -1
+			}
+		}
+		count := int64(0)
+		if (st > 0 && e > s) || (st < 0 && e < s) {
+			count = (e - s + st - sign(st)) / st
+		}
+		begin[axis], step[axis], shape[axis] = int(s), int(st), int(count)
+	}
+	out := newZeroTensor(x.DataType, shape)
+	inStrides := strides(x.Shape)
+	coord := make([]int, rank)
+	for i := range out.Data {
+		src := 0
+		for d := range coord {
+			src += (begin[d] + coord[d]*step[d]) * inStrides[d]
+		}
+		out.Data[i] = x.Data[src]
+		for d := rank - 1; d >= 0; d-- {
+			coord[d]++
+			if coord[d] < shape[d] {
+				break
+			}
+			coord[d] = 0
+		}
+	}
+	return []*Tensor{out}, nil
+}
+
+func sign(v int64) int64 {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+func reduceMean(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	rank := len(x.Shape)
+	axes := axesInput(n, in, 18)
+	reduce := make([]bool, rank)
+	if len(axes) == 0 {
+		for i := range reduce {
+			reduce[i] = true
+		}
+	}
+	for _, a := range axes {
+		axis, err := normalizeAxis(a, rank)
+		if err != nil {
+			return nil, err
+		}
+		reduce[axis] = true
+	}
+	keptShape := make([]int, rank)
+	for i, d := range x.Shape {
+		keptShape[i] = d
+		if reduce[i] {
+			keptShape[i] = 1
+		}
+	}
+	out := newZeroTensor(x.DataType, keptShape)
+	broadcastIndex(x.Shape, [][]int{keptShape}, func(i int, idx []int) {
+		out.Data[idx[0]] += x.Data[i]
+	})
+	count := float64(len(x.Data)) / float64(len(out.Data))
+	for i := range out.Data {
+		out.Data[i] /= count
+	}
+	if n.attrs.int("keepdims", 1) == 0 {
+		shape := []int{}
+		for i, d := range keptShape {
+			if !reduce[i] {
+				shape = append(shape, d)
+			}
+		}
+		out.Shape = shape
+	}
+	return []*Tensor{out}, nil
+}
+
+func argMax(n *node, in []*Tensor) ([]*Tensor, error) {
+	x := in[0]
+	axis, err := normalizeAxis(n.attrs.int("axis", 0), len(x.Shape))
+	if err != nil {
+		return nil, err
+	}
+	outer, length, inner := shapeSize(x.Shape[:axis]), x.Shape[axis], shapeSize(x.Shape[axis+1:])
+	shape := append([]int{}, x.Shape...)
+	shape[axis] = 1
+	out := newZeroTensor(Int64, shape)
+	for o := 0; o < outer; o++ {
+		for i := 0; i < inner; i++ {
+			base := o*length*inner + i
+			best := 0
+			for j := 1; j < length; j++ {
+				if x.Data[base+j*inner] > x.Data[base+best*inner] {
+					best = j
+				}
+			}
+			out.Data[o*inner+i] = float64(best)
+		}
+	}
+	if n.attrs.int("keepdims", 1) == 0 {
+		out.Shape = append(shape[:axis:axis], shape[axis+1:]...)
+	}
+	return []*Tensor{out}, nil
+}
+
+// supportedOperators returns the sorted names of every supported operator.
+func supportedOperators() []string {
+	names := make([]string, 0, len(operators))
+	for name := range operators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,426 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The structs below hold the subset of the ONNX protobuf schema (onnx/onnx.proto3) needed to run a model.
+// They are decoded by hand with protowire rather than generated, so that the schema does not need to be
+// vendored. Field numbers match the schema.
+
+type modelProto struct {
+	irVersion       int64
+	opsetImport     []opsetProto
+	producerName    string
+	producerVersion string
+	domain          string
+	modelVersion    int64
+	docString       string
+	graph           *graphProto
+	metadataProps   map[string]string
+}
+
+type opsetProto struct {
+	domain  string
+	version int64
+}
+
+type graphProto struct {
+	name        string
+	docString   string
+	nodes       []*nodeProto
+	initializer []*tensorProto
+	inputs      []*valueInfoProto
+	outputs     []*valueInfoProto
+}
+
+type nodeProto struct {
+	name       string
+	opType     string
+	domain     string
+	inputs     []string
+	outputs    []string
+	attributes map[string]*attributeProto
+}
+
+// Attribute types of AttributeProto.
+const (
+	attributeFloat   = 1
+	attributeInt     = 2
+	attributeString  = 3
+	attributeTensor  = 4
+	attributeFloats  = 6
+	attributeInts    = 7
+	attributeStrings = 8
+)
+
+type attributeProto struct {
+	name    string
+	typ     int64
+	f       float32
+	i       int64
+	s       []byte
+	t       *tensorProto
+	floats  []float32
+	ints    []int64
+	strings [][]byte
+}
+
+type tensorProto struct {
+	name       string
+	dims       []int64
+	dataType   DataType
+	floatData  []float32
+	int32Data  []int64
+	int64Data  []int64
+	doubleData []float64
+	uint64Data []uint64
+	rawData    []byte
+	external   bool
+}
+
+type valueInfoProto struct {
+	name      string
+	docString string
+	elemType  DataType
+	// dims holds -1 for dimensions that are unknown or symbolic, such as the batch dimension.
+	dims     []int
+	hasShape bool
+}
+
+// field is a single decoded protobuf field.
+type field struct {
+	num     protowire.Number
+	typ     protowire.Type
+	varint  uint64
+	fixed32 uint32
+	fixed64 uint64
+	bytes   []byte
+}
+
+// forEachField calls fn with every field of an encoded protobuf message, in order.
+func forEachField(b []byte, fn func(f field) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		f := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			f.fixed32, n = protowire.ConsumeFixed32(b)
+		case protowire.Fixed64Type:
+			f.fixed64, n = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		case protowire.StartGroupType, protowire.EndGroupType:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		default:
+			return errors.Errorf("invalid protobuf wire type %d", typ)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// varints returns the values of a repeated integer field, which may or may not be packed.
+func (f field) varints() ([]uint64, error) {
+	if f.typ == protowire.VarintType {
+		return []uint64{f.varint}, nil
+	}
+	var out []uint64
+	b := f.bytes
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		out = append(out, v)
+		b = b[n:]
+	}
+	return out, nil
+}
+
+// floats returns the values of a repeated float field, which may or may not be packed.
+func (f field) floats() ([]float32, error) {
+	if f.typ == protowire.Fixed32Type {
+		return []float32{math.Float32frombits(f.fixed32)}, nil
+	}
+	if len(f.bytes)%4 != 0 {
+		return nil, errors.New("packed float field has a truncated value")
+	}
+	out := make([]float32, 0, len(f.bytes)/4)
+	for i := 0; i < len(f.bytes); i += 4 {
+		out = append(out, math.Float32frombits(binary.LittleEndian.Uint32(f.bytes[i:])))
+	}
+	return out, nil
+}
+
+// doubles returns the values of a repeated double field, which may or may not be packed.
+func (f field) doubles() ([]float64, error) {
+	if f.typ == protowire.Fixed64Type {
+		return []float64{math.Float64frombits(f.fixed64)}, nil
+	}
+	if len(f.bytes)%8 != 0 {
+		return nil, errors.New("packed double field has a truncated value")
+	}
+	out := make([]float64, 0, len(f.bytes)/8)
+	for i := 0; i < len(f.bytes); i += 8 {
+		out = append(out, math.Float64frombits(binary.LittleEndian.Uint64(f.bytes[i:])))
+	}
+	return out, nil
+}
+
+func appendInt64s(dst []int64, f field) ([]int64, error) {
+	vs, err := f.varints()
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range vs {
+		dst = append(dst, int64(v))
+	}
+	return dst, nil
+}
+
+func parseModel(b []byte) (*modelProto, error) {
+	m := &modelProto{metadataProps: map[string]string{}}
+	err := forEachField(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			m.irVersion = int64(f.varint)
+		case 2:
+			m.producerName = string(f.bytes)
+		case 3:
+			m.producerVersion = string(f.bytes)
+		case 4:
+			m.domain = string(f.bytes)
+		case 5:
+			m.modelVersion = int64(f.varint)
+		case 6:
+			m.docString = string(f.bytes)
+		case 7:
+			m.graph, err = parseGraph(f.bytes)
+		case 8:
+			var opset opsetProto
+			err = forEachField(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					opset.domain = string(f.bytes)
+				case 2:
+					opset.version = int64(f.varint)
+				}
+				return nil
+			})
+			m.opsetImport = append(m.opsetImport, opset)
+		case 14:
+			var key, value string
+			err = forEachField(f.bytes, func(f field) error {
+				switch f.num {
+				case 1:
+					key = string(f.bytes)
+				case 2:
+					value = string(f.bytes)
+				}
+				return nil
+			})
+			m.metadataProps[key] = value
+		}
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error parsing onnx model")
+	}
+	if m.graph == nil {
+		return nil, errors.New("onnx model has no graph")
+	}
+	return m, nil
+}
+
+func parseGraph(b []byte) (*graphProto, error) {
+	g := &graphProto{}
+	err := forEachField(b, func(f field) error {
+		switch f.num {
+		case 1:
+			n, err := parseNode(f.bytes)
+			if err != nil {
+				return err
+			}
+			g.nodes = append(g.nodes, n)
+		case 2:
+			g.name = string(f.bytes)
+		case 5:
+			t, err := parseTensor(f.bytes)
+			if err != nil {
+				return err
+			}
+			g.initializer = append(g.initializer, t)
+		case 10:
+			g.docString = string(f.bytes)
+		case 11, 12:
+			v, err := parseValueInfo(f.bytes)
+			if err != nil {
+				return err
+			}
+			if f.num == 11 {
+				g.inputs = append(g.inputs, v)
+			} else {
+				g.outputs = append(g.outputs, v)
+			}
+		}
+		return nil
+	})
+	return g, err
+}
+
+func parseNode(b []byte) (*nodeProto, error) {
+	n := &nodeProto{attributes: map[string]*attributeProto{}}
+	err := forEachField(b, func(f field) error {
+		switch f.num {
+		case 1:
+			n.inputs = append(n.inputs, string(f.bytes))
+		case 2:
+			n.outputs = append(n.outputs, string(f.bytes))
+		case 3:
+			n.name = string(f.bytes)
+		case 4:
+			n.opType = string(f.bytes)
+		case 5:
+			a, err := parseAttribute(f.bytes)
+			if err != nil {
+				return err
+			}
+			n.attributes[a.name] = a
+		case 7:
+			n.domain = string(f.bytes)
+		}
+		return nil
+	})
+	return n, err
+}
+
+func parseAttribute(b []byte) (*attributeProto, error) {
+	a := &attributeProto{}
+	err := forEachField(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			a.name = string(f.bytes)
+		case 2:
+			a.f = math.Float32frombits(f.fixed32)
+		case 3:
+			a.i = int64(f.varint)
+		case 4:
+			a.s = f.bytes
+		case 5:
+			a.t, err = parseTensor(f.bytes)
+		case 7:
+			var fs []float32
+			fs, err = f.floats()
+			a.floats = append(a.floats, fs...)
+		case 8:
+			a.ints, err = appendInt64s(a.ints, f)
+		case 9:
+			a.strings = append(a.strings, f.bytes)
+		case 20:
+			a.typ = int64(f.varint)
+		}
+		return err
+	})
+	return a, err
+}
+
+func parseTensor(b []byte) (*tensorProto, error) {
+	t := &tensorProto{}
+	err := forEachField(b, func(f field) error {
+		var err error
+		switch f.num {
+		case 1:
+			t.dims, err = appendInt64s(t.dims, f)
+		case 2:
+			t.dataType = DataType(f.varint)
+		case 4:
+			var fs []float32
+			fs, err = f.floats()
+			t.floatData = append(t.floatData, fs...)
+		case 5:
+			t.int32Data, err = appendInt64s(t.int32Data, f)
+		case 7:
+			t.int64Data, err = appendInt64s(t.int64Data, f)
+		case 8:
+			t.name = string(f.bytes)
+		case 9:
+			t.rawData = f.bytes
+		case 10:
+			var ds []float64
+			ds, err = f.doubles()
+			t.doubleData = append(t.doubleData, ds...)
+		case 11:
+			var vs []uint64
+			vs, err = f.varints()
+			t.uint64Data = append(t.uint64Data, vs...)
+		case 14:
+			// data_location EXTERNAL
+			t.external = f.varint == 1
+		}
+		return err
+	})
+	return t, err
+}
+
+func parseValueInfo(b []byte) (*valueInfoProto, error) {
+	v := &valueInfoProto{}
+	err := forEachField(b, func(f field) error {
+		switch f.num {
+		case 1:
+			v.name = string(f.bytes)
+		case 3:
+			v.docString = string(f.bytes)
+		case 2:
+			// TypeProto, of which only tensor_type is supported.
+			return forEachField(f.bytes, func(f field) error {
+				if f.num != 1 {
+					return nil
+				}
+				return forEachField(f.bytes, func(f field) error {
+					switch f.num {
+					case 1:
+						v.elemType = DataType(f.varint)
+					case 2:
+						v.hasShape = true
+						return forEachField(f.bytes, func(f field) error {
+							if f.num != 1 {
+								return nil
+							}
+							dim := -1
+							err := forEachField(f.bytes, func(f field) error {
+								if f.num == 1 && int64(f.varint) > 0 {
+									dim = int(f.varint)
+								}
+								return nil
+							})
+							v.dims = append(v.dims, dim)
+							return err
+						})
+					}
+					return nil
+				})
+			})
+		}
+		return nil
+	})
+	return v, err
+}
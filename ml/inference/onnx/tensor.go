@@ -0,0 +1,357 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// DataType is the element type of an ONNX tensor, numbered as in TensorProto.DataType.
+type DataType int32
+
+// The tensor element types that can be run.
+const (
+	Float32 DataType = 1
+	Uint8   DataType = 2
+	Int8    DataType = 3
+	Uint16  DataType = 4
+	Int16   DataType = 5
+	Int32   DataType = 6
+	Int64   DataType = 7
+	Bool    DataType = 9
+	Float64 DataType = 11
+	Uint32  DataType = 12
+	Uint64  DataType = 13
+)
+
+var dataTypeNames = map[DataType]string{
+	Float32: "float32",
+	Uint8:   "uint8",
+	Int8:    "int8",
+	Uint16:  "uint16",
+	Int16:   "int16",
+	Int32:   "int32",
+	Int64:   "int64",
+	Bool:    "bool",
+	Float64: "float64",
+	Uint32:  "uint32",
+	Uint64:  "uint64",
+}
+
+// String returns the name of the data type as used by the mlmodel service, e.g. float32.
+func (dt DataType) String() string {
+	if name, ok := dataTypeNames[dt]; ok {
+		return name
+	}
+	return "unsupported"
+}
+
+func (dt DataType) supported() bool {
+	_, ok := dataTypeNames[dt]
+	return ok
+}
+
+func (dt DataType) isFloat() bool {
+	return dt == Float32 || dt == Float64
+}
+
+// Tensor is a dense, row major tensor. Values of every data type are held as float64, which represents all
+// supported types exactly except for 64 bit integers beyond 2^53.
+type Tensor struct {
+	DataType DataType
+	Shape    []int
+	Data     []float64
+}
+
+// NewTensor returns a tensor of the given type and shape holding data, checking that their sizes agree.
+func NewTensor(dt DataType, shape []int, data []float64) (*Tensor, error) {
+	if !dt.supported() {
+		return nil, errors.Errorf("unsupported tensor data type %d", dt)
+	}
+	if n := shapeSize(shape); n != len(data) {
+		return nil, errors.Errorf("tensor of shape %v needs %d values but has %d", shape, n, len(data))
+	}
+	return &Tensor{DataType: dt, Shape: shape, Data: data}, nil
+}
+
+func newZeroTensor(dt DataType, shape []int) *Tensor {
+	return &Tensor{DataType: dt, Shape: shape, Data: make([]float64, shapeSize(shape))}
+}
+
+// scalar returns a tensor holding a single value.
+func scalar(dt DataType, v float64) *Tensor {
+	return &Tensor{DataType: dt, Shape: []int{}, Data: []float64{v}}
+}
+
+// Values returns the tensor's data as a slice of its Go type, e.g. []float32 for a Float32 tensor.
+func (t *Tensor) Values() interface{} {
+	switch t.DataType {
+	case Float32:
+		return convertSlice(t.Data, func(v float64) float32 { return float32(v) })
+	case Float64:
+		return append([]float64{}, t.Data...)
+	case Uint8:
+		return convertSlice(t.Data, func(v float64) uint8 { return uint8(v) })
+	case Int8:
+		return convertSlice(t.Data, func(v float64) int8 { return int8(v) })
+	case Uint16:
+		return convertSlice(t.Data, func(v float64) uint16 { return uint16(v) })
+	case Int16:
+		return convertSlice(t.Data, func(v float64) int16 { return int16(v) })
+	case Int32:
+		return convertSlice(t.Data, func(v float64) int32 { return int32(v) })
+	case Uint32:
+		return convertSlice(t.Data, func(v float64) uint32 { return uint32(v) })
+	case Int64:
+		return convertSlice(t.Data, func(v float64) int64 { return int64(v) })
+	case Uint64:
+		return convertSlice(t.Data, func(v float64) uint64 { return uint64(v) })
+	case Bool:
+		return convertSlice(t.Data, func(v float64) bool { return v != 0 })
+	default:
+		return nil
+	}
+}
+
+func convertSlice[T any](data []float64, convert func(float64) T) []T {
+	out := make([]T, len(data))
+	for i, v := range data {
+		out[i] = convert(v)
+	}
+	return out
+}
+
+// castValue converts a value to what it would be after conversion to the given data type.
+func castValue(dt DataType, v float64) float64 {
+	switch dt {
+	case Float32:
+		return float64(float32(v))
+	case Float64:
+		return v
+	case Bool:
+		if v != 0 {
+			return 1
+		}
+		return 0
+	case Uint8:
+		return float64(uint8(int64(v)))
+	case Int8:
+		return float64(int8(int64(v)))
+	case Uint16:
+		return float64(uint16(int64(v)))
+	case Int16:
+		return float64(int16(int64(v)))
+	case Int32:
+		return float64(int32(int64(v)))
+	case Uint32:
+		return float64(uint32(int64(v)))
+	case Int64:
+		return float64(int64(v))
+	case Uint64:
+		return float64(uint64(v))
+	default:
+		return v
+	}
+}
+
+// tensorFromProto decodes the data of an initializer or constant.
+func tensorFromProto(tp *tensorProto) (*Tensor, error) {
+	if tp.external {
+		return nil, errors.Errorf("tensor %q is stored in an external file, which is not supported", tp.name)
+	}
+	if !tp.dataType.supported() {
+		return nil, errors.Errorf("tensor %q has unsupported data type %d", tp.name, tp.dataType)
+	}
+	shape := make([]int, len(tp.dims))
+	for i, d := range tp.dims {
+		shape[i] = int(d)
+	}
+	n := shapeSize(shape)
+
+	var data []float64
+	switch {
+	case tp.rawData != nil:
+		var err error
+		if data, err = decodeRaw(tp.dataType, tp.rawData); err != nil {
+			return nil, errors.Wrapf(err, "tensor %q", tp.name)
+		}
+	case tp.floatData != nil:
+		data = make([]float64, len(tp.floatData))
+		for i, v := range tp.floatData {
+			data[i] = float64(v)
+		}
+	case tp.doubleData != nil:
+		data = tp.doubleData
+	case tp.int64Data != nil:
+		data = make([]float64, len(tp.int64Data))
+		for i, v := range tp.int64Data {
+			data[i] = float64(v)
+		}
+	case tp.int32Data != nil:
+		data = make([]float64, len(tp.int32Data))
+		for i, v := range tp.int32Data {
+			data[i] = castValue(tp.dataType, float64(v))
+		}
+	case tp.uint64Data != nil:
+		data = make([]float64, len(tp.uint64Data))
+		for i, v := range tp.uint64Data {
+			data[i] = float64(v)
+		}
+	default:
+		data = make([]float64, n)
+	}
+	if len(data) != n {
+		return nil, errors.Errorf("tensor %q of shape %v has %d values", tp.name, shape, len(data))
+	}
+	return &Tensor{DataType: tp.dataType, Shape: shape, Data: data}, nil
+}
+
+// decodeRaw decodes the little endian raw_data of a tensor.
+func decodeRaw(dt DataType, raw []byte) ([]float64, error) {
+	sizes := map[DataType]int{
+		Float32: 4, Float64: 8, Uint8: 1, Int8: 1, Bool: 1, Uint16: 2, Int16: 2,
+		Int32: 4, Uint32: 4, Int64: 8, Uint64: 8,
+	}
+	size := sizes[dt]
+	if len(raw)%size != 0 {
+		return nil, errors.Errorf("raw data of %d bytes is not a multiple of the %s size", len(raw), dt)
+	}
+	data := make([]float64, len(raw)/size)
+	for i := range data {
+		b := raw[i*size:]
+		switch dt {
+		case Float32:
+			data[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		case Float64:
+			data[i] = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		case Uint8, Bool:
+			data[i] = float64(b[0])
+		case Int8:
+			data[i] = float64(int8(b[0]))
+		case Uint16:
+			data[i] = float64(binary.LittleEndian.Uint16(b))
+		case Int16:
+			data[i] = float64(int16(binary.LittleEndian.Uint16(b)))
+		case Int32:
+			data[i] = float64(int32(binary.LittleEndian.Uint32(b)))
+		case Uint32:
+			data[i] = float64(binary.LittleEndian.Uint32(b))
+		case Int64:
+			data[i] = float64(int64(binary.LittleEndian.Uint64(b)))
+		case Uint64:
+			data[i] = float64(binary.LittleEndian.Uint64(b))
+		}
+	}
+	return data, nil
+}
+
+func shapeSize(shape []int) int {
+	n := 1
+	for _, d := range shape {
+		n *= d
+	}
+	return n
+}
+
+// strides returns the row major strides of a shape.
+func strides(shape []int) []int {
+	s := make([]int, len(shape))
+	acc := 1
+	for i := len(shape) - 1; i >= 0; i-- {
+		s[i] = acc
+		acc *= shape[i]
+	}
+	return s
+}
+
+// normalizeAxis resolves a possibly negative axis against a rank.
+func normalizeAxis(axis int64, rank int) (int, error) {
+	a := int(axis)
+	if a < 0 {
+		a += rank
+	}
+	if a < 0 || a >= rank {
+		return 0, errors.Errorf("axis %d is out of range for rank %d", axis, rank)
+	}
+	return a, nil
+}
+
+// broadcastShapes returns the shape resulting from multidirectional (numpy style) broadcasting.
+func broadcastShapes(shapes ...[]int) ([]int, error) {
+	rank := 0
+	for _, s := range shapes {
+		if len(s) > rank {
+			rank = len(s)
+		}
+	}
+	out := make([]int, rank)
+	for i := range out {
+		out[i] = 1
+	}
+	for _, s := range shapes {
+		offset := rank - len(s)
+		for i, d := range s {
+			switch {
+			case out[offset+i] == d || d == 1:
+			case out[offset+i] == 1:
+				out[offset+i] = d
+			default:
+				return nil, errors.Errorf("shapes %v cannot be broadcast together", shapes)
+			}
+		}
+	}
+	return out, nil
+}
+
+// broadcastStrides returns strides that index a tensor of the given shape as if it had been broadcast to
+// the output shape.
+func broadcastStrides(shape, out []int) []int {
+	s := strides(shape)
+	bs := make([]int, len(out))
+	offset := len(out) - len(shape)
+	for i := range shape {
+		if shape[i] != 1 {
+			bs[offset+i] = s[i]
+		}
+	}
+	return bs
+}
+
+// broadcastIndex iterates over every element of a tensor of the given shape, calling fn with the flat
+// output index and the flat indices into each of the broadcast inputs.
+func broadcastIndex(out []int, inputs [][]int, fn func(i int, idx []int)) {
+	ins := make([][]int, len(inputs))
+	for k, s := range inputs {
+		ins[k] = broadcastStrides(s, out)
+	}
+	n := shapeSize(out)
+	coord := make([]int, len(out))
+	idx := make([]int, len(inputs))
+	for i := 0; i < n; i++ {
+		fn(i, idx)
+		// Advance the coordinate like an odometer, updating the input indices incrementally.
+		for d := len(out) - 1; d >= 0; d-- {
+			coord[d]++
+			for k := range idx {
+				idx[k] += ins[k][d]
+			}
+			if coord[d] < out[d] {
+				break
+			}
+			for k := range idx {
+				idx[k] -= ins[k][d] * out[d]
+			}
+			coord[d] = 0
+		}
+	}
+}
+
+// ints returns the values of a tensor as ints, for inputs such as shapes and axes.
+func (t *Tensor) ints() []int64 {
+	out := make([]int64, len(t.Data))
+	for i, v := range t.Data {
+		out[i] = int64(v)
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package ml
+
+import (
+	"math"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// KNNClassifier is a k nearest neighbors classifier in pure Go whose neighbors vote with a weight inversely
+// proportional to their distance. Training only stores the examples, so it suits learning from a handful of
+// examples on the robot itself. Its fields are exported so that a trained classifier can be saved as JSON.
+type KNNClassifier struct {
+	K       int         `json:"k"`
+	Data    [][]float64 `json:"data"`
+	Correct []int       `json:"correct"`
+}
+
+// NewKNNClassifier returns an untrained classifier that considers the k nearest examples.
+func NewKNNClassifier(k int) *KNNClassifier {
+	return &KNNClassifier{K: k}
+}
+
+// Train replaces the examples of the classifier.
+func (c *KNNClassifier) Train(data [][]float64, correct []int) error {
+	if len(data) == 0 {
+		return errors.New("no data")
+	}
+	if len(data) != len(correct) {
+		return errors.Errorf("data and correct not the same lengths %d %d", len(data), len(correct))
+	}
+	for _, row := range data {
+		if len(row) != len(data[0]) {
+			return errors.Errorf("examples have different lengths %d and %d", len(data[0]), len(row))
+		}
+	}
+	if c.K <= 0 {
+		return errors.Errorf("k must be positive, got %d", c.K)
+	}
+	c.Data = data
+	c.Correct = correct
+	return nil
+}
+
+// Classify returns the class with the highest score.
+func (c *KNNClassifier) Classify(data []float64) (int, error) {
+	scores, err := c.Scores(data)
+	if err != nil {
+		return 0, err
+	}
+	best, bestScore := 0, -1.
+	for class, score := range scores {
+		if score > bestScore || (score == bestScore && class < best) {
+			best, bestScore = class, score
+		}
+	}
+	return best, nil
+}
+
+// Scores returns the share of the weighted votes of the nearest neighbors going to each class that has any,
+// which can be used as the confidence of the classification.
+func (c *KNNClassifier) Scores(data []float64) (map[int]float64, error) {
+	if len(c.Data) == 0 {
+		return nil, errors.New("classifier is not trained")
+	}
+	if len(data) != len(c.Data[0]) {
+		return nil, errors.Errorf("expected %d features, got %d", len(c.Data[0]), len(data))
+	}
+
+	type neighbor struct {
+		distance float64
+		class    int
+	}
+	neighbors := make([]neighbor, len(c.Data))
+	for i, row := range c.Data {
+		sum := 0.
+		for j, v := range row {
+			d := v - data[j]
+			sum += d * d
+		}
+		neighbors[i] = neighbor{math.Sqrt(sum), c.Correct[i]}
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool { return neighbors[i].distance < neighbors[j].distance })
+	if len(neighbors) > c.K {
+		neighbors = neighbors[:c.K]
+	}
+
+	const epsilon = 1e-9
+	scores := map[int]float64{}
+	total := 0.
+	for _, n := range neighbors {
+		w := 1 / (n.distance + epsilon)
+		scores[n.class] += w
+		total += w
+	}
+	for class := range scores {
+		scores[class] /= total
+	}
+	return scores, nil
+}
@@ -71,3 +71,30 @@ func TestGLSimpleNN1(t *testing.T) {
 
 	_checkCorrectness(t, c, data, correct)
 }
+
+func TestKNNSimple(t *testing.T) {
+	data, correct := _makeSimpleTest()
+
+	c := NewKNNClassifier(1)
+	err := c.Train(data, correct)
+	test.That(t, err, test.ShouldBeNil)
+
+	_checkCorrectness(t, c, data, correct)
+
+	scores, err := c.Scores([]float64{0.9, 0.1, 13})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, scores[1], test.ShouldAlmostEqual, 1)
+
+	c.K = 3
+	scores, err = c.Scores([]float64{0.5, 0, 10})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, scores[0], test.ShouldBeGreaterThan, 0)
+	test.That(t, scores[1], test.ShouldBeGreaterThan, 0)
+	test.That(t, scores[0]+scores[1], test.ShouldAlmostEqual, 1)
+
+	_, err = c.Scores([]float64{0, 0})
+	test.That(t, err, test.ShouldBeError, "expected 3 features, got 2")
+	_, err = NewKNNClassifier(1).Classify([]float64{0, 0, 0})
+	test.That(t, err, test.ShouldBeError, "classifier is not trained")
+	test.That(t, c.Train([][]float64{{0}, {0, 1}}, []int{0, 1}), test.ShouldBeError, "examples have different lengths 1 and 2")
+}
@@ -24,6 +24,7 @@ import (
 
 	"go.viam.com/rdk/config"
 	rdkgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/metrics"
 	modlib "go.viam.com/rdk/module"
 	modmanageroptions "go.viam.com/rdk/module/modmanager/options"
 	"go.viam.com/rdk/module/modmaninterface"
@@ -36,6 +37,12 @@ var (
 	validateConfigTimeout       = 5 * time.Second
 	errMessageExitStatus143     = "exit status 143"
 	errModularResourcesDisabled = errors.New("modular resources disabled in untrusted environment")
+	moduleReconfigures          = metrics.DefaultRegistry.NewCounterVec(
+		"viam_module_reconfigures_total",
+		"Reconfigurations of modules, each of which replaces the process of the module. Processes that exit "+
+			"unexpectedly are not counted.",
+		"module",
+	)
 )
 
 // NewManager returns a Manager.
@@ -159,6 +166,7 @@ func (mgr *Manager) Reconfigure(ctx context.Context, conf config.Module) ([]reso
 		// If re-addition fails, assume all handled resources are orphaned.
 		return handledResourceNames, err
 	}
+	moduleReconfigures.With(conf.Name).Inc()
 
 	// add old module process' resources to new module; warn if new module cannot
 	// handle old resource and consider that resource orphaned.
@@ -407,10 +415,10 @@ func (m *module) startProcess(ctx context.Context, parentAddr string, logger gol
 		return err
 	}
 	pconf := pexec.ProcessConfig{
-		ID:   m.name,
-		Name: m.exe,
-		Args: []string{m.addr},
-		Log:  true,
+		ID:        m.name,
+		Name:      m.exe,
+		Args:      []string{m.addr},
+		LogWriter: newModuleLogWriter(logger.Named(m.name)),
 	}
 	m.process = pexec.NewManagedProcess(pconf, logger)
 
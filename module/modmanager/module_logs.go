@@ -0,0 +1,116 @@
+package modmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// consoleTimeLayout is how the console loggers of golog, which modules use by default, format times.
+const consoleTimeLayout = "2006-01-02T15:04:05.000Z0700"
+
+var (
+	ansiEscapeRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+	callerRegexp     = regexp.MustCompile(`^\S+\.go:\d+$`)
+)
+
+// moduleLogWriter receives the output of a module's process line by line and logs each line as an entry of the
+// module's logger. Lines written by a golog console logger in the module keep their time, level, logger name,
+// caller and fields, so that they can be queried like the robot's own logs; any other output is logged as is.
+type moduleLogWriter struct {
+	logger *zap.Logger
+}
+
+func newModuleLogWriter(logger golog.Logger) *moduleLogWriter {
+	return &moduleLogWriter{logger: logger.Desugar()}
+}
+
+// Write logs one line of output. Process output is written a line at a time, each followed by a separate
+// newline, which is ignored.
+func (w *moduleLogWriter) Write(p []byte) (int, error) {
+	line := string(bytes.TrimRight(p, "\r\n"))
+	if line == "" {
+		return len(p), nil
+	}
+	entry, fields, ok := parseConsoleLine(line)
+	if !ok {
+		w.logger.Info(line)
+		return len(p), nil
+	}
+
+	logger := w.logger
+	if entry.LoggerName != "" {
+		logger = logger.Named(entry.LoggerName)
+	}
+	if ce := logger.Check(entry.Level, entry.Message); ce != nil {
+		ce.Time = entry.Time
+		ce.Caller = entry.Caller
+		ce.Write(fields...)
+	}
+	return len(p), nil
+}
+
+// parseConsoleLine parses a line written by a zap console encoder with golog's config, which separates the time,
+// level, optional logger name, optional caller, message and optional JSON fields with tabs.
+func parseConsoleLine(line string) (zapcore.Entry, []zap.Field, bool) {
+	var entry zapcore.Entry
+	parts := strings.Split(line, "\t")
+	if len(parts) < 3 {
+		return entry, nil, false
+	}
+	t, err := time.Parse(consoleTimeLayout, parts[0])
+	if err != nil {
+		return entry, nil, false
+	}
+	entry.Time = t
+	level := ansiEscapeRegexp.ReplaceAllString(parts[1], "")
+	if err := entry.Level.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return entry, nil, false
+	}
+	parts = parts[2:]
+
+	switch {
+	case len(parts) > 2 && callerRegexp.MatchString(parts[1]):
+		entry.LoggerName = parts[0]
+		entry.Caller = parseCaller(parts[1])
+		parts = parts[2:]
+	case len(parts) > 1 && callerRegexp.MatchString(parts[0]):
+		entry.Caller = parseCaller(parts[0])
+		parts = parts[1:]
+	}
+
+	var fields []zap.Field
+	if last := parts[len(parts)-1]; len(parts) > 1 && strings.HasPrefix(last, "{") {
+		var values map[string]interface{}
+		if err := json.Unmarshal([]byte(last), &values); err == nil {
+			keys := make([]string, 0, len(values))
+			for key := range values {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fields = append(fields, zap.Any(key, values[key]))
+			}
+			parts = parts[:len(parts)-1]
+		}
+	}
+	entry.Message = strings.Join(parts, "\t")
+	return entry, fields, true
+}
+
+func parseCaller(caller string) zapcore.EntryCaller {
+	idx := strings.LastIndexByte(caller, ':')
+	line, err := strconv.Atoi(caller[idx+1:])
+	if err != nil {
+		return zapcore.EntryCaller{}
+	}
+	return zapcore.EntryCaller{Defined: true, File: caller[:idx], Line: line}
+}
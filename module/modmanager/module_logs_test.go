@@ -0,0 +1,52 @@
+package modmanager
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"go.viam.com/test"
+)
+
+func TestModuleLogWriter(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	w := newModuleLogWriter(zap.New(core).Sugar().Named("modmanager").Named("mymodule"))
+
+	for _, line := range []string{
+		"2023-04-20T10:11:12.345-0400\t\x1b[34mINFO\x1b[0m\tSimpleModule\tsimplemodule/module.go:42\tcounter added\t{\"total\":3}",
+		"\n",
+		"2023-04-20T10:11:13.000Z\tERROR\tmymod/main.go:7\tno\tname",
+		"\n",
+		"panic: something went wrong",
+		"\n",
+	} {
+		n, err := w.Write([]byte(line))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, n, test.ShouldEqual, len(line))
+	}
+
+	entries := logs.AllUntimed()
+	test.That(t, entries, test.ShouldHaveLength, 3)
+
+	test.That(t, entries[0].LoggerName, test.ShouldEqual, "modmanager.mymodule.SimpleModule")
+	test.That(t, entries[0].Level, test.ShouldEqual, zapcore.InfoLevel)
+	test.That(t, entries[0].Message, test.ShouldEqual, "counter added")
+	test.That(t, entries[0].Caller.String(), test.ShouldEqual, "simplemodule/module.go:42")
+	test.That(t, entries[0].ContextMap(), test.ShouldResemble, map[string]interface{}{"total": 3.0})
+
+	test.That(t, entries[1].LoggerName, test.ShouldEqual, "modmanager.mymodule")
+	test.That(t, entries[1].Level, test.ShouldEqual, zapcore.ErrorLevel)
+	test.That(t, entries[1].Message, test.ShouldEqual, "no\tname")
+	test.That(t, entries[1].Caller.String(), test.ShouldEqual, "mymod/main.go:7")
+
+	test.That(t, entries[2].LoggerName, test.ShouldEqual, "modmanager.mymodule")
+	test.That(t, entries[2].Level, test.ShouldEqual, zapcore.InfoLevel)
+	test.That(t, entries[2].Message, test.ShouldEqual, "panic: something went wrong")
+
+	all := logs.All()
+	expectedTime, err := time.Parse(time.RFC3339, "2023-04-20T10:11:12.345-04:00")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, all[0].Time.Equal(expectedTime), test.ShouldBeTrue)
+}
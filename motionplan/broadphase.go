@@ -0,0 +1,156 @@
+package motionplan
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r3"
+
+	spatial "go.viam.com/rdk/spatialmath"
+)
+
+// bvhLeafSize is the maximum number of geometries in a leaf of a bounding volume hierarchy.
+const bvhLeafSize = 4
+
+// aabb is a box aligned with the world axes, used to quickly rule out collisions between geometries that are far apart.
+type aabb struct {
+	min, max r3.Vector
+}
+
+// newAABB returns the bounding box of a geometry, grown by the collision buffer so that geometries which are
+// considered in collision always have overlapping bounding boxes. The second return value is false if the
+// geometry type has no known bounding box.
+func newAABB(g spatial.Geometry) (aabb, bool) {
+	lo, hi, err := spatial.BoundingBox(g)
+	if err != nil {
+		return aabb{}, false
+	}
+	buffer := r3.Vector{X: spatial.CollisionBuffer, Y: spatial.CollisionBuffer, Z: spatial.CollisionBuffer}
+	return aabb{lo.Sub(buffer), hi.Add(buffer)}, true
+}
+
+func (a aabb) overlaps(b aabb) bool {
+	return a.min.X <= b.max.X && b.min.X <= a.max.X &&
+		a.min.Y <= b.max.Y && b.min.Y <= a.max.Y &&
+		a.min.Z <= b.max.Z && b.min.Z <= a.max.Z
+}
+
+func (a aabb) union(b aabb) aabb {
+	return aabb{
+		min: r3.Vector{X: math.Min(a.min.X, b.min.X), Y: math.Min(a.min.Y, b.min.Y), Z: math.Min(a.min.Z, b.min.Z)},
+		max: r3.Vector{X: math.Max(a.max.X, b.max.X), Y: math.Max(a.max.Y, b.max.Y), Z: math.Max(a.max.Z, b.max.Z)},
+	}
+}
+
+func (a aabb) center() r3.Vector {
+	return a.min.Add(a.max).Mul(0.5)
+}
+
+// bvhEntry is a named geometry with its bounding box.
+type bvhEntry struct {
+	name string
+	geom spatial.Geometry
+	box  aabb
+}
+
+// bvhNode is a node of a bounding volume hierarchy: a binary tree whose nodes hold the bounding box of all the
+// geometries below them, so that a query only descends into the branches whose boxes overlap the queried box.
+type bvhNode struct {
+	box         aabb
+	left, right *bvhNode
+	entries     []bvhEntry // only set on leaves
+}
+
+// newBVH builds a bounding volume hierarchy top down, splitting the geometries at the median of their centers
+// along the longest axis of their bounding box.
+func newBVH(entries []bvhEntry) *bvhNode {
+	node := &bvhNode{box: entries[0].box}
+	for _, e := range entries[1:] {
+		node.box = node.box.union(e.box)
+	}
+	if len(entries) <= bvhLeafSize {
+		node.entries = entries
+		return node
+	}
+
+	size := node.box.max.Sub(node.box.min)
+	axis := func(v r3.Vector) float64 { return v.X }
+	if size.Y > size.X && size.Y >= size.Z {
+		axis = func(v r3.Vector) float64 { return v.Y }
+	} else if size.Z > size.X && size.Z > size.Y {
+		axis = func(v r3.Vector) float64 { return v.Z }
+	}
+	sort.Slice(entries, func(i, j int) bool { return axis(entries[i].box.center()) < axis(entries[j].box.center()) })
+	mid := len(entries) / 2
+	node.left = newBVH(entries[:mid])
+	node.right = newBVH(entries[mid:])
+	return node
+}
+
+// query calls fn on every geometry whose bounding box overlaps box, until fn returns false.
+// It returns false if fn did.
+func (n *bvhNode) query(box aabb, fn func(name string, geom spatial.Geometry) bool) bool {
+	if !n.box.overlaps(box) {
+		return true
+	}
+	if n.entries != nil {
+		for _, e := range n.entries {
+			if e.box.overlaps(box) && !fn(e.name, e.geom) {
+				return false
+			}
+		}
+		return true
+	}
+	return n.left.query(box, fn) && n.right.query(box, fn)
+}
+
+// geometrySet is a set of named geometries which can quickly find the ones that may be in collision with another
+// geometry. Building it costs more than checking a handful of geometries pairwise, so a set of geometries that
+// do not move should be built once and reused, e.g. for every state checked while planning.
+type geometrySet struct {
+	geometries map[string]spatial.Geometry
+
+	// tree holds the geometries that have a bounding box, while unbounded holds the ones that do not and
+	// so always need to be checked.
+	tree      *bvhNode
+	unbounded map[string]spatial.Geometry
+}
+
+func newGeometrySet(geometries map[string]spatial.Geometry) *geometrySet {
+	set := &geometrySet{geometries: geometries, unbounded: map[string]spatial.Geometry{}}
+	entries := make([]bvhEntry, 0, len(geometries))
+	for name, geom := range geometries {
+		box, ok := newAABB(geom)
+		if !ok {
+			set.unbounded[name] = geom
+			continue
+		}
+		entries = append(entries, bvhEntry{name, geom, box})
+	}
+	if len(entries) > 0 {
+		set.tree = newBVH(entries)
+	}
+	return set
+}
+
+// forEachCandidate calls fn on every geometry of the set that may be in collision with g, until fn returns false.
+func (s *geometrySet) forEachCandidate(g spatial.Geometry, fn func(name string, geom spatial.Geometry) bool) {
+	box, ok := newAABB(g)
+	if !ok {
+		// without a bounding box for g, every geometry is a candidate
+		for name, geom := range s.geometries {
+			if !fn(name, geom) {
+				return
+			}
+		}
+		return
+	}
+	for name, geom := range s.unbounded {
+		if !fn(name, geom) {
+			return
+		}
+	}
+	if s.tree != nil {
+		s.tree.query(box, fn)
+	}
+}
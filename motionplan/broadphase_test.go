@@ -0,0 +1,166 @@
+package motionplan
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// randomGeometries returns n boxes, spheres and capsules with random poses within a cube of the given size.
+func randomGeometries(t testing.TB, rseed *rand.Rand, n int, size float64, prefix string) []spatial.Geometry {
+	t.Helper()
+	geoms := make([]spatial.Geometry, 0, n)
+	for i := 0; i < n; i++ {
+		pose := spatial.NewPose(
+			r3.Vector{X: rseed.Float64() * size, Y: rseed.Float64() * size, Z: rseed.Float64() * size},
+			&spatial.OrientationVector{OX: rseed.Float64() - 0.5, OY: rseed.Float64() - 0.5, OZ: rseed.Float64(), Theta: rseed.Float64()},
+		)
+		label := fmt.Sprintf("%s%d", prefix, i)
+		var geom spatial.Geometry
+		var err error
+		switch i % 3 {
+		case 0:
+			geom, err = spatial.NewBox(pose, r3.Vector{X: 1 + rseed.Float64()*20, Y: 1 + rseed.Float64()*20, Z: 1 + rseed.Float64()*20}, label)
+		case 1:
+			geom, err = spatial.NewSphere(pose, 1+rseed.Float64()*10, label)
+		default:
+			geom, err = spatial.NewCapsule(pose, 2, 5+rseed.Float64()*20, label)
+		}
+		test.That(t, err, test.ShouldBeNil)
+		geoms = append(geoms, geom)
+	}
+	return geoms
+}
+
+func TestGeometrySetCandidates(t *testing.T) {
+	rseed := rand.New(rand.NewSource(1))
+	obstacles := randomGeometries(t, rseed, 300, 200, "obstacle")
+	obstacleMap, err := createUniqueCollisionMap(obstacles)
+	test.That(t, err, test.ShouldBeNil)
+	set := newGeometrySet(obstacleMap)
+
+	pruned := 0
+	for _, query := range randomGeometries(t, rseed, 50, 200, "query") {
+		candidates := map[string]bool{}
+		set.forEachCandidate(query, func(name string, geom spatial.Geometry) bool {
+			candidates[name] = true
+			return true
+		})
+		pruned += len(obstacles) - len(candidates)
+		for _, obstacle := range obstacles {
+			collides, err := query.CollidesWith(obstacle)
+			test.That(t, err, test.ShouldBeNil)
+			if collides {
+				test.That(t, candidates[obstacle.Label()], test.ShouldBeTrue)
+			}
+		}
+	}
+	// most pairs are far apart and never reach the narrow phase
+	test.That(t, pruned, test.ShouldBeGreaterThan, 50*len(obstacles)*9/10)
+
+	// stopping early
+	calls := 0
+	set.forEachCandidate(obstacles[0], func(name string, geom spatial.Geometry) bool {
+		calls++
+		return false
+	})
+	test.That(t, calls, test.ShouldEqual, 1)
+}
+
+func TestCollisionGraphBroadPhase(t *testing.T) {
+	rseed := rand.New(rand.NewSource(2))
+	robot := randomGeometries(t, rseed, 20, 200, "robot")
+	obstacles := randomGeometries(t, rseed, 200, 200, "obstacle")
+
+	// checking all pairs finds all collisions
+	all, err := newCollisionGraph(robot, obstacles, nil, true)
+	test.That(t, err, test.ShouldBeNil)
+	expected := map[string]map[string]bool{}
+	for _, c := range all.collisions() {
+		if expected[c.name1] == nil {
+			expected[c.name1] = map[string]bool{}
+		}
+		expected[c.name1][c.name2] = true
+	}
+	test.That(t, expected, test.ShouldNotBeEmpty)
+
+	// checking only the candidates finds a collision of every colliding geometry, and no other
+	obstacleMap, err := createUniqueCollisionMap(obstacles)
+	test.That(t, err, test.ShouldBeNil)
+	set := newGeometrySet(obstacleMap)
+	for _, r := range robot {
+		cg, err := newCollisionGraphFromSet([]spatial.Geometry{r}, set, nil, false)
+		test.That(t, err, test.ShouldBeNil)
+		collisions := cg.collisions()
+		if len(expected[r.Label()]) == 0 {
+			test.That(t, collisions, test.ShouldBeEmpty)
+			continue
+		}
+		test.That(t, collisions, test.ShouldHaveLength, 1)
+		test.That(t, expected[r.Label()][collisions[0].name2], test.ShouldBeTrue)
+
+		// collisions in the reference graph are ignored
+		cg, err = newCollisionGraphFromSet([]spatial.Geometry{r}, set, all, false)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cg.collisions(), test.ShouldBeEmpty)
+	}
+
+	// a collision in the reference graph does not hide another one
+	box, err := spatial.NewBox(spatial.NewZeroPose(), r3.Vector{X: 100, Y: 10, Z: 10}, "box")
+	test.That(t, err, test.ShouldBeNil)
+	left, err := spatial.NewSphere(spatial.NewPoseFromPoint(r3.Vector{X: -50}), 5, "left")
+	test.That(t, err, test.ShouldBeNil)
+	right, err := spatial.NewSphere(spatial.NewPoseFromPoint(r3.Vector{X: 50}), 5, "right")
+	test.That(t, err, test.ShouldBeNil)
+	reference, err := newCollisionGraph([]spatial.Geometry{box}, []spatial.Geometry{left}, nil, true)
+	test.That(t, err, test.ShouldBeNil)
+	for i := 0; i < 10; i++ {
+		cg, err := newCollisionGraph([]spatial.Geometry{box}, []spatial.Geometry{left, right}, reference, false)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cg.collisions(), test.ShouldHaveLength, 1)
+		test.That(t, cg.collisions()[0].name2, test.ShouldEqual, "right")
+	}
+}
+
+func BenchmarkCollisionConstraintsClutter(b *testing.B) {
+	// a few hundred obstacles, like the boxes derived from the point cloud of a bin
+	rseed := rand.New(rand.NewSource(1))
+	obstacles := randomGeometries(b, rseed, 500, 200, "obstacle")
+	shifted := make([]spatial.Geometry, 0, len(obstacles))
+	for _, o := range obstacles {
+		g := o.Transform(spatial.NewPoseFromPoint(r3.Vector{X: 300, Y: 300, Z: -100}))
+		g.SetLabel(o.Label())
+		shifted = append(shifted, g)
+	}
+	worldState := &frame.WorldState{
+		Obstacles: []*frame.GeometriesInFrame{frame.NewGeometriesInFrame(frame.World, shifted)},
+	}
+
+	model, err := frame.ParseModelJSONFile(utils.ResolveFile("components/arm/xarm/xarm6_kinematics.json"), "")
+	test.That(b, err, test.ShouldBeNil)
+	fs := frame.NewEmptySimpleFrameSystem("test")
+	err = fs.AddFrame(model, fs.Frame(frame.World))
+	test.That(b, err, test.ShouldBeNil)
+	sf, err := newSolverFrame(fs, model.Name(), frame.World, frame.StartPositions(fs))
+	test.That(b, err, test.ShouldBeNil)
+	handler := &ConstraintHandler{}
+	collisionConstraints, err := createAllCollisionConstraints(sf, fs, worldState, frame.StartPositions(fs), nil)
+	test.That(b, err, test.ShouldBeNil)
+	for name, constraint := range collisionConstraints {
+		handler.AddStateConstraint(name, constraint)
+	}
+	var b1 bool
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		rfloats := frame.GenerateRandomConfiguration(model, rseed)
+		b1, _ = handler.CheckStateConstraints(&State{Configuration: frame.FloatsToInputs(rfloats), Frame: model})
+	}
+	bt = b1
+}
@@ -0,0 +1,185 @@
+package motionplan
+
+import (
+	"math"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	frame "go.viam.com/rdk/referenceframe"
+)
+
+const (
+	// defaultVelocityDamping is the damping factor used near singularities when none is given.
+	defaultVelocityDamping = 0.05
+
+	// defaultMaxJointSpeed is the speed, in radians or mm per second, that no input exceeds when none is given.
+	defaultMaxJointSpeed = 1.
+
+	// velocityLengthScale is the length, in mm, that makes a linear velocity comparable to an angular velocity
+	// when solving for both at once, so that the damping weighs on both similarly.
+	velocityLengthScale = 100.
+)
+
+// CartesianVelocityOptions configures a CartesianVelocityController.
+type CartesianVelocityOptions struct {
+	// Damping trades tracking accuracy for bounded joint speeds near singularities. Zero uses the default.
+	Damping float64
+
+	// MaxJointSpeed is the speed, in radians or mm per second, that no input will exceed. Velocities that would
+	// need faster inputs are slowed down as a whole, keeping their direction. Zero uses the default.
+	MaxJointSpeed float64
+
+	// WorldState holds the obstacles that the frame must not collide with.
+	WorldState *frame.WorldState
+}
+
+// CartesianVelocityController moves a frame at a commanded Cartesian velocity, e.g. to jog an arm, by turning the
+// velocity into small changes of the inputs with the damped least squares resolved-rate method.
+// Inputs never leave their limits, and steps that would collide are refused.
+type CartesianVelocityController struct {
+	frame         *solverFrame
+	constraints   *ConstraintHandler
+	damping       float64
+	maxJointSpeed float64
+}
+
+// NewCartesianVelocityController returns a controller for the frame with the given name, moving in the world frame of
+// the given frame system. The inputs are the current inputs of the frame system; collisions present at those inputs
+// are ignored.
+func NewCartesianVelocityController(
+	fs frame.FrameSystem,
+	frameName string,
+	inputs map[string][]frame.Input,
+	opts CartesianVelocityOptions,
+) (*CartesianVelocityController, error) {
+	sf, err := newSolverFrame(fs, frameName, frame.World, inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(sf.DoF()) == 0 {
+		return nil, errors.Errorf("frame %q has no inputs to move", frameName)
+	}
+	collisionConstraints, err := createAllCollisionConstraints(sf, fs, opts.WorldState, inputs, nil)
+	if err != nil {
+		return nil, err
+	}
+	handler := &ConstraintHandler{}
+	for name, constraint := range collisionConstraints {
+		handler.AddStateConstraint(name, constraint)
+	}
+
+	c := &CartesianVelocityController{
+		frame:         sf,
+		constraints:   handler,
+		damping:       opts.Damping,
+		maxJointSpeed: opts.MaxJointSpeed,
+	}
+	if c.damping <= 0 {
+		c.damping = defaultVelocityDamping
+	}
+	if c.maxJointSpeed <= 0 {
+		c.maxJointSpeed = defaultMaxJointSpeed
+	}
+	return c, nil
+}
+
+// Step returns the inputs reached after moving the frame for dt from the given inputs, at the given linear velocity,
+// in mm per second, and angular velocity, in radians per second, both expressed in the world frame.
+// Inputs that would leave their limits stop at them, and the others make up for them as well as they can.
+// An error is returned, and the frame should not be moved, if the step would end in collision.
+func (c *CartesianVelocityController) Step(
+	inputs map[string][]frame.Input,
+	linear, angular r3.Vector,
+	dt time.Duration,
+) (map[string][]frame.Input, error) {
+	current, err := c.frame.mapToSlice(inputs)
+	if err != nil {
+		return nil, err
+	}
+	jac, err := frame.ComputeJacobian(c.frame, current)
+	if err != nil {
+		return nil, err
+	}
+
+	seconds := dt.Seconds()
+	limits := c.frame.DoF()
+	// locked inputs stay at the limit they were clamped to while the others are solved for again
+	locked := make([]bool, len(limits))
+	next := make([]frame.Input, len(current))
+	// every pass locks at least one more input at its limit, so this ends
+	for pass := 0; pass <= len(limits); pass++ {
+		speeds := c.jointSpeeds(jac, locked, linear, angular)
+
+		// slow down as a whole so that no input is too fast, which keeps the direction of the motion
+		fastest := 0.
+		for _, s := range speeds {
+			fastest = math.Max(fastest, math.Abs(s))
+		}
+		if fastest > c.maxJointSpeed {
+			for i := range speeds {
+				speeds[i] *= c.maxJointSpeed / fastest
+			}
+		}
+
+		clamped := false
+		for i, s := range speeds {
+			if locked[i] {
+				continue
+			}
+			value := current[i].Value + s*seconds
+			if value < limits[i].Min || value > limits[i].Max {
+				value = math.Max(limits[i].Min, math.Min(limits[i].Max, value))
+				locked[i] = true
+				clamped = true
+			}
+			next[i] = frame.Input{Value: value}
+		}
+		if !clamped {
+			break
+		}
+	}
+
+	if ok, failName := c.constraints.CheckStateConstraints(&State{Configuration: next, Frame: c.frame}); !ok {
+		return nil, errors.Errorf("cannot move frame %q any further, it would violate constraint %s", c.frame.solveFrame.Name(), failName)
+	}
+	return c.frame.sliceToMap(next), nil
+}
+
+// jointSpeeds solves for the speeds of the inputs giving the requested velocity, with damped least squares:
+// speeds = Jᵀ(JJᵀ + λ²I)⁻¹v, where J is the Jacobian without the columns of the locked inputs.
+func (c *CartesianVelocityController) jointSpeeds(jac *mat.Dense, locked []bool, linear, angular r3.Vector) []float64 {
+	rows, cols := jac.Dims()
+	weighted := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		scale := 1.
+		if i < 3 {
+			scale = 1 / velocityLengthScale
+		}
+		for j := 0; j < cols; j++ {
+			if !locked[j] {
+				weighted.Set(i, j, jac.At(i, j)*scale)
+			}
+		}
+	}
+	twist := mat.NewVecDense(rows, []float64{
+		linear.X / velocityLengthScale, linear.Y / velocityLengthScale, linear.Z / velocityLengthScale,
+		angular.X, angular.Y, angular.Z,
+	})
+
+	var damped mat.Dense
+	damped.Mul(weighted, weighted.T())
+	for i := 0; i < rows; i++ {
+		damped.Set(i, i, damped.At(i, i)+c.damping*c.damping)
+	}
+	var y mat.VecDense
+	// damped is symmetric positive definite, so this does not fail
+	if err := y.SolveVec(&damped, twist); err != nil {
+		return make([]float64, cols)
+	}
+	var speeds mat.VecDense
+	speeds.MulVec(weighted.T(), &y)
+	return speeds.RawVector().Data
+}
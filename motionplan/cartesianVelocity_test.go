@@ -0,0 +1,120 @@
+package motionplan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	frame "go.viam.com/rdk/referenceframe"
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+func TestCartesianVelocityController(t *testing.T) {
+	model, err := frame.ParseModelJSONFile(utils.ResolveFile("components/arm/xarm/xarm6_kinematics.json"), "")
+	test.That(t, err, test.ShouldBeNil)
+	fs := frame.NewEmptySimpleFrameSystem("test")
+	test.That(t, fs.AddFrame(model, fs.World()), test.ShouldBeNil)
+	inputs := frame.StartPositions(fs)
+	inputs[model.Name()] = frame.FloatsToInputs([]float64{0, -0.5, -0.5, 0, 1, 0})
+
+	poseOf := func(inputs map[string][]frame.Input) spatial.Pose {
+		pose, err := model.Transform(inputs[model.Name()])
+		test.That(t, err, test.ShouldBeNil)
+		return pose
+	}
+
+	t.Run("linear jog", func(t *testing.T) {
+		c, err := NewCartesianVelocityController(fs, model.Name(), inputs, CartesianVelocityOptions{})
+		test.That(t, err, test.ShouldBeNil)
+		start := poseOf(inputs)
+		current := inputs
+		for i := 0; i < 20; i++ {
+			current, err = c.Step(current, r3.Vector{X: 50}, r3.Vector{}, 50*time.Millisecond)
+			test.That(t, err, test.ShouldBeNil)
+		}
+		// one second at 50mm/s
+		moved := poseOf(current).Point().Sub(start.Point())
+		test.That(t, moved.X, test.ShouldAlmostEqual, 50, 2)
+		test.That(t, moved.Y, test.ShouldAlmostEqual, 0, 2)
+		test.That(t, moved.Z, test.ShouldAlmostEqual, 0, 2)
+		test.That(t, spatial.OrientationAlmostEqualEps(start.Orientation(), poseOf(current).Orientation(), 0.02), test.ShouldBeTrue)
+	})
+
+	t.Run("angular jog", func(t *testing.T) {
+		c, err := NewCartesianVelocityController(fs, model.Name(), inputs, CartesianVelocityOptions{})
+		test.That(t, err, test.ShouldBeNil)
+		start := poseOf(inputs)
+		current := inputs
+		for i := 0; i < 10; i++ {
+			current, err = c.Step(current, r3.Vector{}, r3.Vector{Z: 0.2}, 50*time.Millisecond)
+			test.That(t, err, test.ShouldBeNil)
+		}
+		turned := spatial.QuatToR3AA(spatial.OrientationBetween(start.Orientation(), poseOf(current).Orientation()).Quaternion())
+		test.That(t, turned.Z, test.ShouldAlmostEqual, 0.1, 0.01)
+		test.That(t, poseOf(current).Point().Sub(start.Point()).Norm(), test.ShouldBeLessThan, 2)
+	})
+
+	t.Run("joint speed and limits", func(t *testing.T) {
+		c, err := NewCartesianVelocityController(fs, model.Name(), inputs, CartesianVelocityOptions{MaxJointSpeed: 0.1})
+		test.That(t, err, test.ShouldBeNil)
+		next, err := c.Step(inputs, r3.Vector{Z: 1000}, r3.Vector{}, time.Second)
+		test.That(t, err, test.ShouldBeNil)
+		for i, v := range next[model.Name()] {
+			test.That(t, v.Value-inputs[model.Name()][i].Value, test.ShouldBeBetweenOrEqual, -0.1-1e-9, 0.1+1e-9)
+		}
+
+		// reaching far away pins the inputs at their limits instead of going beyond them
+		c, err = NewCartesianVelocityController(fs, model.Name(), inputs, CartesianVelocityOptions{MaxJointSpeed: 100})
+		test.That(t, err, test.ShouldBeNil)
+		current := inputs
+		for i := 0; i < 50; i++ {
+			current, err = c.Step(current, r3.Vector{Z: -2000}, r3.Vector{}, 100*time.Millisecond)
+			test.That(t, err, test.ShouldBeNil)
+		}
+		for i, limit := range model.DoF() {
+			test.That(t, current[model.Name()][i].Value, test.ShouldBeBetweenOrEqual, limit.Min, limit.Max)
+		}
+
+		// an input that would pass its limit stops right at it rather than where it was
+		limit := model.DoF()[0]
+		nearLimit := frame.StartPositions(fs)
+		nearLimit[model.Name()] = frame.FloatsToInputs([]float64{limit.Max - 0.01, -0.5, -0.5, 0, 1, 0})
+		c, err = NewCartesianVelocityController(fs, model.Name(), nearLimit, CartesianVelocityOptions{MaxJointSpeed: 100})
+		test.That(t, err, test.ShouldBeNil)
+		next, err = c.Step(nearLimit, r3.Vector{}, r3.Vector{Z: 10}, time.Second)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, next[model.Name()][0].Value, test.ShouldEqual, limit.Max)
+	})
+
+	t.Run("collisions", func(t *testing.T) {
+		// a wall a few cm ahead of the end effector
+		start := poseOf(inputs).Point()
+		wall, err := spatial.NewBox(spatial.NewPoseFromPoint(r3.Vector{X: start.X + 60, Y: start.Y, Z: start.Z}),
+			r3.Vector{X: 10, Y: 1000, Z: 1000}, "wall")
+		test.That(t, err, test.ShouldBeNil)
+		worldState := &frame.WorldState{Obstacles: []*frame.GeometriesInFrame{frame.NewGeometriesInFrame(frame.World, []spatial.Geometry{wall})}}
+		c, err := NewCartesianVelocityController(fs, model.Name(), inputs, CartesianVelocityOptions{WorldState: worldState})
+		test.That(t, err, test.ShouldBeNil)
+
+		current := inputs
+		for i := 0; i < 40; i++ {
+			next, err := c.Step(current, r3.Vector{X: 50}, r3.Vector{}, 50*time.Millisecond)
+			if err != nil {
+				test.That(t, err.Error(), test.ShouldContainSubstring, defaultObstacleConstraintDesc)
+				break
+			}
+			current = next
+		}
+		// stopped at the wall, which spans 55 to 65mm ahead
+		test.That(t, poseOf(current).Point().X-start.X, test.ShouldBeBetween, 40, 65)
+		_, err = c.Step(current, r3.Vector{X: 50}, r3.Vector{}, 50*time.Millisecond)
+		test.That(t, err, test.ShouldNotBeNil)
+
+		// and can still back off
+		_, err = c.Step(current, r3.Vector{X: -50}, r3.Vector{}, 50*time.Millisecond)
+		test.That(t, err, test.ShouldBeNil)
+	})
+}
@@ -216,39 +216,71 @@ func newCollisionGraph(x, y []spatial.Geometry, reference *collisionGraph, repor
 	if y == nil {
 		y = x
 	}
-	xMap, err := createUniqueCollisionMap(x)
+	yMap, err := createUniqueCollisionMap(y)
 	if err != nil {
 		return nil, err
 	}
-	yMap, err := createUniqueCollisionMap(y)
+	return newCollisionGraphFromSet(x, newGeometrySet(yMap), reference, reportDistances)
+}
+
+// newCollisionGraphFromSet is newCollisionGraph for a set of y geometries that was built beforehand, so that it can be
+// reused when only the x geometries change. Unless all distances are reported, only the pairs of geometries whose
+// bounding boxes overlap are checked, and the others are left out of the graph.
+func newCollisionGraphFromSet(
+	x []spatial.Geometry,
+	y *geometrySet,
+	reference *collisionGraph,
+	reportDistances bool,
+) (cg *collisionGraph, err error) {
+	xMap, err := createUniqueCollisionMap(x)
 	if err != nil {
 		return nil, err
 	}
 
 	cg = &collisionGraph{
-		geometryGraph:   newGeometryGraph(xMap, yMap),
+		geometryGraph:   newGeometryGraph(xMap, y.geometries),
 		reportDistances: reportDistances,
 	}
 
-	var distance float64
+	// checkPair sets the distance between two geometries, and returns whether to keep checking other pairs
+	checkPair := func(xName string, xGeometry spatial.Geometry, yName string, yGeometry spatial.Geometry) bool {
+		if _, ok := cg.getDistance(xName, yName); ok || xGeometry == yGeometry {
+			// geometry pair already has distance information associated with it, or is comparing with itself - skip to next pair
+			return true
+		}
+		var distance float64
+		if reference != nil && reference.collisionBetween(xName, yName) {
+			// represent previously seen collisions as NaNs
+			// per IEE standards, any comparison with NaN will return false, so these will never be considered collisions
+			distance = math.NaN()
+		} else if distance, err = cg.checkCollision(xGeometry, yGeometry); err != nil {
+			return false
+		}
+		cg.setDistance(xName, yName, distance)
+		// if a collision is found and distances are not reported, can return early; previously seen collisions are NaNs
+		// and so do not stop the checks
+		return reportDistances || !(distance <= spatial.CollisionBuffer)
+	}
+
 	for xName, xGeometry := range cg.x {
-		for yName, yGeometry := range cg.y {
-			if _, ok := cg.getDistance(xName, yName); ok || xGeometry == yGeometry {
-				// geometry pair already has distance information associated with it, or is comparing with itself - skip to next pair
-				continue
-			}
-			if reference != nil && reference.collisionBetween(xName, yName) {
-				// represent previously seen collisions as NaNs
-				// per IEE standards, any comparison with NaN will return false, so these will never be considered collisions
-				distance = math.NaN()
-			} else if distance, err = cg.checkCollision(xGeometry, yGeometry); err != nil {
-				return nil, err
-			}
-			cg.setDistance(xName, yName, distance)
-			if !reportDistances && distance <= spatial.CollisionBuffer {
-				// collision found, can return early
-				return cg, nil
+		keepChecking := true
+		if reportDistances {
+			for yName, yGeometry := range cg.y {
+				if keepChecking = checkPair(xName, xGeometry, yName, yGeometry); !keepChecking {
+					break
+				}
 			}
+		} else {
+			y.forEachCandidate(xGeometry, func(yName string, yGeometry spatial.Geometry) bool {
+				keepChecking = checkPair(xName, xGeometry, yName, yGeometry)
+				return keepChecking
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !keepChecking {
+			return cg, nil
 		}
 	}
 	return cg, nil
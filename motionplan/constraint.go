@@ -326,6 +326,16 @@ func newCollisionConstraint(
 		zeroCG.addCollisionSpecification(specification)
 	}
 
+	// the static geometries do not change from one state to the next, so their broad phase structure is built once
+	var staticSet *geometrySet
+	if static != nil {
+		staticMap, err := createUniqueCollisionMap(static)
+		if err != nil {
+			return nil, err
+		}
+		staticSet = newGeometrySet(staticMap)
+	}
+
 	// create constraint from reference collision graph
 	constraint := func(state *State) bool {
 		internal, err := state.Frame.Geometries(state.Configuration)
@@ -333,7 +343,12 @@ func newCollisionConstraint(
 			return false
 		}
 
-		cg, err := newCollisionGraph(internal.Geometries(), static, zeroCG, reportDistances)
+		var cg *collisionGraph
+		if staticSet != nil {
+			cg, err = newCollisionGraphFromSet(internal.Geometries(), staticSet, zeroCG, reportDistances)
+		} else {
+			cg, err = newCollisionGraph(internal.Geometries(), nil, zeroCG, reportDistances)
+		}
 		if err != nil {
 			return false
 		}
@@ -343,6 +358,16 @@ func newCollisionConstraint(
 	return constraint, nil
 }
 
+// NewObstacleConstraint returns a constraint which is violated when any of the geometries of the frame of a state
+// collide with any of the obstacles, which are in the same frame as the geometries and do not move.
+func NewObstacleConstraint(obstacles []spatial.Geometry) (StateConstraint, error) {
+	if obstacles == nil {
+		// a nil set of static geometries would check the geometries of the frame against each other instead
+		obstacles = []spatial.Geometry{}
+	}
+	return newCollisionConstraint(nil, obstacles, nil, false)
+}
+
 // NewAbsoluteLinearInterpolatingConstraint provides a Constraint whose valid manifold allows a specified amount of deviation from the
 // shortest straight-line path between the start and the goal. linTol is the allowed linear deviation in mm, orientTol is the allowed
 // orientation deviation measured by norm of the R3AA orientation difference to the slerp path between start/goal orientations.
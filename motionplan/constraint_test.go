@@ -234,6 +234,29 @@ func TestCollisionConstraints(t *testing.T) {
 	}
 }
 
+func TestObstacleConstraint(t *testing.T) {
+	obstacle, err := spatial.NewBox(spatial.NewPoseFromPoint(r3.Vector{X: 100}), r3.Vector{20, 20, 20}, "obstacle")
+	test.That(t, err, test.ShouldBeNil)
+	constraint, err := NewObstacleConstraint([]spatial.Geometry{obstacle})
+	test.That(t, err, test.ShouldBeNil)
+
+	box, err := spatial.NewBox(spatial.NewZeroPose(), r3.Vector{10, 10, 10}, "")
+	test.That(t, err, test.ShouldBeNil)
+	stateAt := func(pt r3.Vector) *State {
+		f, err := frame.NewStaticFrameWithGeometry("box", spatial.NewZeroPose(), box.Transform(spatial.NewPoseFromPoint(pt)))
+		test.That(t, err, test.ShouldBeNil)
+		return &State{Frame: f}
+	}
+	test.That(t, constraint(stateAt(r3.Vector{})), test.ShouldBeTrue)
+	test.That(t, constraint(stateAt(r3.Vector{X: 90})), test.ShouldBeFalse)
+	test.That(t, constraint(stateAt(r3.Vector{X: 100, Y: 30})), test.ShouldBeTrue)
+
+	// without obstacles, the geometries of the frame are not checked against each other
+	constraint, err = NewObstacleConstraint(nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, constraint(stateAt(r3.Vector{X: 100})), test.ShouldBeTrue)
+}
+
 var bt bool
 
 func BenchmarkCollisionConstraints(b *testing.B) {
@@ -0,0 +1,376 @@
+// Package grasp plans grasps of the objects found by vision services for parallel jaw grippers, and uses them to pick
+// up and place objects.
+package grasp
+
+import (
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision"
+)
+
+const (
+	defaultPreGraspOffsetMM = 100.
+	defaultClearanceMM      = 5.
+	defaultAngleSteps       = 8
+
+	// approachStepMM is the distance between the poses of the gripper checked for collisions on the way from the
+	// pre-grasp pose to the grasp pose.
+	approachStepMM = 10.
+	// maxApproachZ keeps grasps from approaching objects from below, through what they rest on.
+	maxApproachZ = 0.1
+	// sameDirection is the cosine of the angle below which two directions are considered the same.
+	sameDirection = 0.99
+)
+
+// Gripper describes a parallel jaw gripper to plan grasps for. The gripper approaches objects along the +Z axis of
+// its frame, and its fingers close along the Y axis of its frame.
+type Gripper struct {
+	// Geometry of the gripper in its frame, leaving out the space between its open fingers. Grasps are not checked
+	// for collisions without it.
+	Geometry spatialmath.Geometry
+	// TCPOffsetMM is the distance along +Z from the origin of the frame of the gripper to the middle of the finger
+	// pads, where the gripper holds objects.
+	TCPOffsetMM float64
+	// MaxWidthMM is the distance between the finger pads when the gripper is open.
+	MaxWidthMM float64
+	// FingerLengthMM is the length of the finger pads along Z.
+	FingerLengthMM float64
+}
+
+// A Grasp is a pose of the gripper from which it can close its fingers on an object.
+type Grasp struct {
+	// Pose of the frame of the gripper in the world frame when grasping the object.
+	Pose spatialmath.Pose
+	// PreGrasp is the pose of the gripper to move to before approaching the object along Approach.
+	PreGrasp spatialmath.Pose
+	// Approach is the direction the gripper moves in, in the world frame, from the pre-grasp pose to the grasp pose.
+	Approach r3.Vector
+	// WidthMM is the width of the object between the fingers.
+	WidthMM float64
+	// Score ranks grasps from 0 to 1, higher being better. It favors grasps from above, grasps with room to spare
+	// between the fingers, and grasps close to the center of the object.
+	Score float64
+}
+
+// A Planner plans grasps of objects for a gripper.
+type Planner struct {
+	Gripper Gripper
+	// PreGraspOffsetMM is how far back from the grasp pose along the approach the pre-grasp pose is.
+	PreGraspOffsetMM float64
+	// ClearanceMM is the room to leave between the gripper and the object, on each side of the object between the
+	// fingers and between the object and the rest of the gripper.
+	ClearanceMM float64
+	// AngleSteps is how many ways of closing the fingers around each approach direction to try.
+	AngleSteps int
+}
+
+// NewPlanner returns a Planner for the gripper with default offsets.
+func NewPlanner(gripper Gripper) (*Planner, error) {
+	p := &Planner{
+		Gripper:          gripper,
+		PreGraspOffsetMM: defaultPreGraspOffsetMM,
+		ClearanceMM:      defaultClearanceMM,
+		AngleSteps:       defaultAngleSteps,
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Planner) validate() error {
+	if p.Gripper.MaxWidthMM <= 0 {
+		return errors.New("gripper max width must be positive")
+	}
+	if p.ClearanceMM < 0 || p.PreGraspOffsetMM < 0 {
+		return errors.New("clearance and pre-grasp offset cannot be negative")
+	}
+	if p.Gripper.FingerLengthMM <= 2*p.ClearanceMM {
+		return errors.Errorf("gripper finger length must be more than twice the clearance of %.1fmm", p.ClearanceMM)
+	}
+	if p.AngleSteps < 1 {
+		return errors.New("need at least one angle step")
+	}
+	return nil
+}
+
+// Plan returns the grasps of the object, whose points are in the given frame, that the gripper can make without
+// colliding with the object or with the obstacles of the world state, best first. The world state should not have the
+// object itself as an obstacle. The frame system and its inputs place the object and the obstacles in the world.
+func (p *Planner) Plan(
+	object *vision.Object,
+	objectFrame string,
+	fs referenceframe.FrameSystem,
+	inputs map[string][]referenceframe.Input,
+	worldState *referenceframe.WorldState,
+) ([]*Grasp, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	if object == nil || object.PointCloud == nil || object.Size() == 0 {
+		return nil, errors.New("object has no points to grasp")
+	}
+
+	tf, err := fs.Transform(inputs, referenceframe.NewPoseInFrame(objectFrame, spatialmath.NewZeroPose()), referenceframe.World)
+	if err != nil {
+		return nil, err
+	}
+	framePose := tf.(*referenceframe.PoseInFrame).Pose()
+	points := make([]r3.Vector, 0, object.Size())
+	object.Iterate(0, 0, func(pt r3.Vector, d pointcloud.Data) bool {
+		points = append(points, spatialmath.Compose(framePose, spatialmath.NewPoseFromPoint(pt)).Point())
+		return true
+	})
+
+	handler, err := p.collisionHandler(points, fs, inputs, worldState)
+	if err != nil {
+		return nil, err
+	}
+
+	centroid, axes := principalAxes(points)
+	var radius float64
+	for _, pt := range points {
+		radius = math.Max(radius, pt.Sub(centroid).Norm())
+	}
+
+	var grasps []*Grasp
+	for _, approach := range approachDirections(axes) {
+		for _, closing := range p.closingDirections(approach, axes) {
+			grasp, ok := p.grasp(points, approach, closing)
+			if !ok {
+				continue
+			}
+			if handler != nil && !p.collisionFree(handler, grasp) {
+				continue
+			}
+			centered := 1 - math.Min(grasp.center.Sub(centroid).Norm()/math.Max(radius, 1), 1)
+			grasp.Score = 0.5*(1-approach.Z)/2 + 0.3*(1-grasp.WidthMM/p.Gripper.MaxWidthMM) + 0.2*centered
+			grasps = append(grasps, grasp.Grasp)
+		}
+	}
+	sort.SliceStable(grasps, func(i, j int) bool { return grasps[i].Score > grasps[j].Score })
+	return grasps, nil
+}
+
+// collisionHandler returns the constraints keeping the gripper from hitting the object or the obstacles, or nil if
+// the gripper has no geometry to check.
+func (p *Planner) collisionHandler(
+	points []r3.Vector,
+	fs referenceframe.FrameSystem,
+	inputs map[string][]referenceframe.Input,
+	worldState *referenceframe.WorldState,
+) (*motionplan.ConstraintHandler, error) {
+	if p.Gripper.Geometry == nil {
+		return nil, nil
+	}
+	var obstacles []spatialmath.Geometry
+	if worldState != nil {
+		inWorld, err := worldState.ObstaclesInWorldFrame(fs, inputs)
+		if err != nil {
+			return nil, err
+		}
+		obstacles = inWorld.Geometries()
+	}
+	obstacleConstraint, err := motionplan.NewObstacleConstraint(obstacles)
+	if err != nil {
+		return nil, err
+	}
+
+	minPt, maxPt := points[0], points[0]
+	for _, pt := range points {
+		minPt = r3.Vector{X: math.Min(minPt.X, pt.X), Y: math.Min(minPt.Y, pt.Y), Z: math.Min(minPt.Z, pt.Z)}
+		maxPt = r3.Vector{X: math.Max(maxPt.X, pt.X), Y: math.Max(maxPt.Y, pt.Y), Z: math.Max(maxPt.Z, pt.Z)}
+	}
+	size := maxPt.Sub(minPt)
+	octree, err := pointcloud.NewBasicOctree(minPt.Add(maxPt).Mul(0.5), math.Max(size.X, math.Max(size.Y, size.Z))+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, pt := range points {
+		if err := octree.Set(pt, pointcloud.NewValueData(1)); err != nil {
+			return nil, err
+		}
+	}
+
+	handler := &motionplan.ConstraintHandler{}
+	handler.AddStateConstraint("obstacles", obstacleConstraint)
+	handler.AddStateConstraint("object", motionplan.NewOctreeCollisionConstraint(octree, 1, math.Max(p.ClearanceMM/2, 1)))
+	return handler, nil
+}
+
+// collisionFree returns whether the gripper can go from the pre-grasp pose to the grasp pose without collisions.
+func (p *Planner) collisionFree(handler *motionplan.ConstraintHandler, grasp *candidate) bool {
+	steps := int(math.Ceil(p.PreGraspOffsetMM / approachStepMM))
+	for i := 0; i <= steps; i++ {
+		var offset float64
+		if steps > 0 {
+			offset = p.PreGraspOffsetMM * float64(i) / float64(steps)
+		}
+		pose := spatialmath.Compose(spatialmath.NewPoseFromPoint(grasp.Approach.Mul(-offset)), grasp.Pose)
+		f, err := referenceframe.NewStaticFrameWithGeometry("gripper", spatialmath.NewZeroPose(), p.Gripper.Geometry.Transform(pose))
+		if err != nil {
+			return false
+		}
+		if ok, _ := handler.CheckStateConstraints(&motionplan.State{Frame: f}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// candidate is a grasp with the point between the finger pads.
+type candidate struct {
+	*Grasp
+	center r3.Vector
+}
+
+// grasp returns the grasp approaching the object along approach and closing the fingers along closing, if the object
+// fits between the fingers. The finger pads go as deep as the middle of the object, or as deep as they can while
+// leaving clearance between the object and the rest of the gripper.
+func (p *Planner) grasp(points []r3.Vector, approach, closing r3.Vector) (*candidate, bool) {
+	side := closing.Cross(approach)
+	near, far := math.Inf(1), math.Inf(-1)
+	for _, pt := range points {
+		near = math.Min(near, pt.Dot(approach))
+		far = math.Max(far, pt.Dot(approach))
+	}
+	depth := math.Min((far-near)/2, p.Gripper.FingerLengthMM/2-p.ClearanceMM)
+	along := near + depth
+
+	// only the points between the finger pads count for the width
+	minClosing, maxClosing := math.Inf(1), math.Inf(-1)
+	minSide, maxSide := math.Inf(1), math.Inf(-1)
+	for _, pt := range points {
+		if math.Abs(pt.Dot(approach)-along) > p.Gripper.FingerLengthMM/2 {
+			continue
+		}
+		minClosing, maxClosing = math.Min(minClosing, pt.Dot(closing)), math.Max(maxClosing, pt.Dot(closing))
+		minSide, maxSide = math.Min(minSide, pt.Dot(side)), math.Max(maxSide, pt.Dot(side))
+	}
+	width := maxClosing - minClosing
+	if width+2*p.ClearanceMM > p.Gripper.MaxWidthMM {
+		return nil, false
+	}
+
+	center := approach.Mul(along).
+		Add(closing.Mul((minClosing + maxClosing) / 2)).
+		Add(side.Mul((minSide + maxSide) / 2))
+	// the rows of the rotation matrix are the axes of the gripper in the world frame
+	orientation, err := spatialmath.NewRotationMatrix([]float64{
+		side.X, side.Y, side.Z,
+		closing.X, closing.Y, closing.Z,
+		approach.X, approach.Y, approach.Z,
+	})
+	if err != nil {
+		return nil, false
+	}
+	origin := center.Sub(approach.Mul(p.Gripper.TCPOffsetMM))
+	return &candidate{
+		Grasp: &Grasp{
+			Pose:     spatialmath.NewPose(origin, orientation),
+			PreGrasp: spatialmath.NewPose(origin.Sub(approach.Mul(p.PreGraspOffsetMM)), orientation),
+			Approach: approach,
+			WidthMM:  width,
+		},
+		center: center,
+	}, true
+}
+
+// principalAxes returns the centroid of the points and the directions they spread along, most spread first.
+func principalAxes(points []r3.Vector) (r3.Vector, []r3.Vector) {
+	var centroid r3.Vector
+	for _, pt := range points {
+		centroid = centroid.Add(pt)
+	}
+	centroid = centroid.Mul(1 / float64(len(points)))
+
+	cov := mat.NewSymDense(3, nil)
+	for _, pt := range points {
+		d := pt.Sub(centroid)
+		v := []float64{d.X, d.Y, d.Z}
+		for i := 0; i < 3; i++ {
+			for j := i; j < 3; j++ {
+				cov.SetSym(i, j, cov.At(i, j)+v[i]*v[j])
+			}
+		}
+	}
+	var eig mat.EigenSym
+	if !eig.Factorize(cov, true) {
+		return centroid, []r3.Vector{{X: 1}, {Y: 1}, {Z: 1}}
+	}
+	var vectors mat.Dense
+	eig.VectorsTo(&vectors)
+	// eigenvalues are in ascending order
+	axes := make([]r3.Vector, 3)
+	for i := 0; i < 3; i++ {
+		axes[2-i] = r3.Vector{X: vectors.At(0, i), Y: vectors.At(1, i), Z: vectors.At(2, i)}
+	}
+	return centroid, axes
+}
+
+// approachDirections returns the directions to approach the object from: from above, along its principal axes, and
+// along them leveled to approach from the side.
+func approachDirections(axes []r3.Vector) []r3.Vector {
+	candidates := []r3.Vector{{Z: -1}}
+	for _, axis := range axes {
+		candidates = append(candidates, axis, axis.Mul(-1))
+		if level := (r3.Vector{X: axis.X, Y: axis.Y}); level.Norm() > 0.1 {
+			candidates = append(candidates, level.Normalize(), level.Normalize().Mul(-1))
+		}
+	}
+	var directions []r3.Vector
+	for _, c := range candidates {
+		if c.Z > maxApproachZ || containsDirection(directions, c, false) {
+			continue
+		}
+		directions = append(directions, c)
+	}
+	return directions
+}
+
+// closingDirections returns the directions perpendicular to the approach to close the fingers along: evenly spread
+// ones and the principal axes of the object.
+func (p *Planner) closingDirections(approach r3.Vector, axes []r3.Vector) []r3.Vector {
+	u := approach.Ortho()
+	v := approach.Cross(u)
+	var directions []r3.Vector
+	for _, axis := range axes {
+		if in := axis.Sub(approach.Mul(axis.Dot(approach))); in.Norm() > 0.1 {
+			if in = in.Normalize(); !containsDirection(directions, in, true) {
+				directions = append(directions, in)
+			}
+		}
+	}
+	for i := 0; i < p.AngleSteps; i++ {
+		// the fingers close from both sides, so half a turn covers every way of closing them
+		angle := math.Pi * float64(i) / float64(p.AngleSteps)
+		if d := u.Mul(math.Cos(angle)).Add(v.Mul(math.Sin(angle))); !containsDirection(directions, d, true) {
+			directions = append(directions, d)
+		}
+	}
+	return directions
+}
+
+// containsDirection returns whether any of the unit vectors points the same way as d, or the opposite way if
+// eitherWay is set.
+func containsDirection(directions []r3.Vector, d r3.Vector, eitherWay bool) bool {
+	for _, other := range directions {
+		cos := other.Dot(d)
+		if eitherWay {
+			cos = math.Abs(cos)
+		}
+		if cos > sameDirection {
+			return true
+		}
+	}
+	return false
+}
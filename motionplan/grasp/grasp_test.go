@@ -0,0 +1,198 @@
+package grasp
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	motionpb "go.viam.com/api/service/motion/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/vision"
+)
+
+// testScene returns a frame system with a camera looking down from above the object, and the object, a box standing
+// on the table at the origin of the world, as seen by the camera.
+func testScene(t *testing.T, dims r3.Vector) (referenceframe.FrameSystem, *vision.Object) {
+	t.Helper()
+	fs := referenceframe.NewEmptySimpleFrameSystem("test")
+	cameraPose := spatialmath.NewPose(r3.Vector{X: 300, Z: 500}, &spatialmath.R4AA{Theta: math.Pi, RX: 1})
+	camera, err := referenceframe.NewStaticFrame("camera", cameraPose)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, fs.AddFrame(camera, fs.World()), test.ShouldBeNil)
+
+	// points on the faces of the box, which is centered on (300, 0)
+	cloud := pointcloud.New()
+	const step = 5.
+	for x := -dims.X / 2; x <= dims.X/2; x += step {
+		for y := -dims.Y / 2; y <= dims.Y/2; y += step {
+			for z := 0.; z <= dims.Z; z += step {
+				if math.Abs(x) < dims.X/2 && math.Abs(y) < dims.Y/2 && z > 0 && z < dims.Z {
+					continue
+				}
+				inCamera := spatialmath.Compose(
+					spatialmath.PoseInverse(cameraPose),
+					spatialmath.NewPoseFromPoint(r3.Vector{X: 300 + x, Y: y, Z: z}),
+				).Point()
+				test.That(t, cloud.Set(inCamera, nil), test.ShouldBeNil)
+			}
+		}
+	}
+	object, err := vision.NewObject(cloud)
+	test.That(t, err, test.ShouldBeNil)
+	return fs, object
+}
+
+// testGripper has a 80mm wide opening and a palm 60mm by 100mm across, behind 40mm long fingers.
+func testGripper(t *testing.T) Gripper {
+	t.Helper()
+	palm, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{Z: 60}), r3.Vector{X: 60, Y: 100, Z: 80}, "palm")
+	test.That(t, err, test.ShouldBeNil)
+	return Gripper{Geometry: palm, TCPOffsetMM: 120, MaxWidthMM: 80, FingerLengthMM: 40}
+}
+
+func TestPlan(t *testing.T) {
+	fs, object := testScene(t, r3.Vector{X: 40, Y: 60, Z: 100})
+	inputs := referenceframe.StartPositions(fs)
+	table, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{Z: -10}), r3.Vector{X: 1000, Y: 1000, Z: 20}, "table")
+	test.That(t, err, test.ShouldBeNil)
+	worldState := &referenceframe.WorldState{
+		Obstacles: []*referenceframe.GeometriesInFrame{
+			referenceframe.NewGeometriesInFrame(referenceframe.World, []spatialmath.Geometry{table}),
+		},
+	}
+
+	planner, err := NewPlanner(testGripper(t))
+	test.That(t, err, test.ShouldBeNil)
+	grasps, err := planner.Plan(object, "camera", fs, inputs, worldState)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(grasps), test.ShouldBeGreaterThan, 1)
+	for i, grasp := range grasps {
+		test.That(t, grasp.Approach.Z, test.ShouldBeLessThanOrEqualTo, maxApproachZ)
+		test.That(t, grasp.WidthMM, test.ShouldBeLessThanOrEqualTo, 70)
+		if i > 0 {
+			test.That(t, grasp.Score, test.ShouldBeLessThanOrEqualTo, grasps[i-1].Score)
+		}
+	}
+
+	// the best grasp comes from above, closing on the narrow side of the box, with the finger pads 15mm deep so that
+	// the palm stays clear of its top
+	best := grasps[0]
+	test.That(t, best.Approach.Z, test.ShouldAlmostEqual, -1)
+	test.That(t, best.WidthMM, test.ShouldAlmostEqual, 40)
+	test.That(t, spatialmath.R3VectorAlmostEqual(best.Pose.Point(), r3.Vector{X: 300, Z: 205}, 1e-6), test.ShouldBeTrue)
+	test.That(t, spatialmath.R3VectorAlmostEqual(best.PreGrasp.Point(), r3.Vector{X: 300, Z: 305}, 1e-6), test.ShouldBeTrue)
+	gripperAxis := func(pose spatialmath.Pose, axis r3.Vector) r3.Vector {
+		return spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(axis)).Point().Sub(pose.Point())
+	}
+	test.That(t, spatialmath.R3VectorAlmostEqual(gripperAxis(best.Pose, r3.Vector{Z: 1}), best.Approach, 1e-6), test.ShouldBeTrue)
+	test.That(t, math.Abs(gripperAxis(best.Pose, r3.Vector{Y: 1}).X), test.ShouldAlmostEqual, 1)
+
+	// with a shelf right above the box, only grasps from the side are left
+	shelf, err := spatialmath.NewBox(spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: 160}), r3.Vector{X: 200, Y: 200, Z: 20}, "shelf")
+	test.That(t, err, test.ShouldBeNil)
+	worldState.Obstacles = append(worldState.Obstacles,
+		referenceframe.NewGeometriesInFrame(referenceframe.World, []spatialmath.Geometry{shelf}))
+	grasps, err = planner.Plan(object, "camera", fs, inputs, worldState)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(grasps), test.ShouldBeGreaterThan, 0)
+	for _, grasp := range grasps {
+		test.That(t, grasp.Approach.Z, test.ShouldAlmostEqual, 0)
+	}
+
+	// a box wider than the gripper all around cannot be grasped
+	fs, wide := testScene(t, r3.Vector{X: 120, Y: 120, Z: 120})
+	grasps, err = planner.Plan(wide, "camera", fs, inputs, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, grasps, test.ShouldBeEmpty)
+
+	_, err = planner.Plan(vision.NewEmptyObject(), "camera", fs, inputs, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = planner.Plan(object, "nowhere", fs, inputs, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	_, err = NewPlanner(Gripper{FingerLengthMM: 40})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewPlanner(Gripper{MaxWidthMM: 80, FingerLengthMM: 5})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestPickAndPlace(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	grasps := []*Grasp{
+		{
+			Pose:     spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: 200}),
+			PreGrasp: spatialmath.NewPoseFromPoint(r3.Vector{X: 300, Z: 100}),
+		},
+		{
+			Pose:     spatialmath.NewPoseFromPoint(r3.Vector{X: 400, Z: 200}),
+			PreGrasp: spatialmath.NewPoseFromPoint(r3.Vector{X: 400, Z: 100}),
+		},
+	}
+	place := referenceframe.NewPoseInFrame("table", spatialmath.NewPoseFromPoint(r3.Vector{Y: 200, Z: 200}))
+
+	type move struct {
+		frame  string
+		point  r3.Vector
+		linear bool
+	}
+	var moves []move
+	var actions []string
+	ms := inject.NewMotionService("motion")
+	ms.MoveFunc = func(
+		ctx context.Context,
+		componentName resource.Name,
+		destination *referenceframe.PoseInFrame,
+		worldState *referenceframe.WorldState,
+		constraints *motionpb.Constraints,
+		extra map[string]interface{},
+	) (bool, error) {
+		test.That(t, componentName.ShortName(), test.ShouldEqual, "gripper")
+		moves = append(moves, move{destination.Parent(), destination.Pose().Point(), constraints != nil})
+		// the first grasp is out of reach
+		return destination.Pose().Point().X != 300, nil
+	}
+	g := inject.NewGripper("gripper")
+	grab := true
+	g.OpenFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		actions = append(actions, "open")
+		return nil
+	}
+	g.GrabFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		actions = append(actions, "grab")
+		return grab, nil
+	}
+
+	used, err := PickAndPlace(context.Background(), ms, g, grasps, place, nil, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, used, test.ShouldEqual, grasps[1])
+	test.That(t, actions, test.ShouldResemble, []string{"open", "grab", "open"})
+	test.That(t, moves, test.ShouldResemble, []move{
+		{referenceframe.World, r3.Vector{X: 300, Z: 100}, false},
+		{referenceframe.World, r3.Vector{X: 400, Z: 100}, false},
+		{referenceframe.World, r3.Vector{X: 400, Z: 200}, true},
+		{referenceframe.World, r3.Vector{X: 400, Z: 100}, true},
+		{"table", r3.Vector{Y: 200, Z: 100}, false},
+		{"table", r3.Vector{Y: 200, Z: 200}, true},
+		{"table", r3.Vector{Y: 200, Z: 100}, true},
+	})
+
+	// the gripper lets go and backs off when it closes on nothing
+	moves, actions, grab = nil, nil, false
+	_, err = PickAndPlace(context.Background(), ms, g, grasps, place, nil, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, actions, test.ShouldResemble, []string{"open", "grab", "open"})
+	test.That(t, moves[len(moves)-1], test.ShouldResemble, move{referenceframe.World, r3.Vector{X: 400, Z: 100}, true})
+
+	_, err = PickAndPlace(context.Background(), ms, g, grasps[:1], place, nil, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = PickAndPlace(context.Background(), ms, g, nil, place, nil, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,103 @@
+package grasp
+
+import (
+	"context"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	motionpb "go.viam.com/api/service/motion/v1"
+
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/spatialmath"
+)
+
+// linearMotion keeps the gripper on a straight line when approaching and leaving objects.
+var linearMotion = &motionpb.Constraints{LinearConstraint: []*motionpb.LinearConstraint{{}}}
+
+// PickAndPlace picks up an object with the first of the grasps, in order, whose pre-grasp pose the motion service
+// can move the gripper to, and puts it down with the gripper at the place pose. The gripper goes straight from the
+// pre-grasp pose to the grasp pose and back, and approaches and leaves the place pose along its Z axis by the same
+// distance. It returns the grasp it used.
+func PickAndPlace(
+	ctx context.Context,
+	ms motion.Service,
+	g gripper.Gripper,
+	grasps []*Grasp,
+	place *referenceframe.PoseInFrame,
+	worldState *referenceframe.WorldState,
+	logger golog.Logger,
+) (*Grasp, error) {
+	if len(grasps) == 0 {
+		return nil, errors.New("no grasps to pick the object with")
+	}
+	move := func(destination *referenceframe.PoseInFrame, constraints *motionpb.Constraints) error {
+		success, err := ms.Move(ctx, g.Name(), destination, worldState, constraints, nil)
+		if err != nil {
+			return err
+		}
+		if !success {
+			return errors.Errorf("could not move %q to %v", g.Name(), destination.Pose())
+		}
+		return nil
+	}
+	inWorld := func(pose spatialmath.Pose) *referenceframe.PoseInFrame {
+		return referenceframe.NewPoseInFrame(referenceframe.World, pose)
+	}
+
+	if err := g.Open(ctx, nil); err != nil {
+		return nil, err
+	}
+	var grasp *Grasp
+	for i, candidate := range grasps {
+		if err := move(inWorld(candidate.PreGrasp), nil); err != nil {
+			logger.Debugw("skipping grasp out of reach", "grasp", i, "error", err)
+			continue
+		}
+		grasp = candidate
+		break
+	}
+	if grasp == nil {
+		return nil, errors.Errorf("could not reach any of the %d grasps", len(grasps))
+	}
+
+	if err := move(inWorld(grasp.Pose), linearMotion); err != nil {
+		return nil, errors.Wrap(err, "approaching the object")
+	}
+	grabbed, err := g.Grab(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !grabbed {
+		err := errors.New("gripper closed without grabbing the object")
+		if openErr := g.Open(ctx, nil); openErr != nil {
+			return nil, multierr.Combine(err, openErr)
+		}
+		return nil, multierr.Combine(err, move(inWorld(grasp.PreGrasp), linearMotion))
+	}
+	if err := move(inWorld(grasp.PreGrasp), linearMotion); err != nil {
+		return nil, errors.Wrap(err, "lifting the object")
+	}
+
+	offset := grasp.Pose.Point().Sub(grasp.PreGrasp.Point()).Norm()
+	abovePlace := referenceframe.NewPoseInFrame(
+		place.Parent(),
+		spatialmath.Compose(place.Pose(), spatialmath.NewPoseFromPoint(r3.Vector{Z: -offset})),
+	)
+	if err := move(abovePlace, nil); err != nil {
+		return nil, errors.Wrap(err, "carrying the object")
+	}
+	if err := move(place, linearMotion); err != nil {
+		return nil, errors.Wrap(err, "placing the object")
+	}
+	if err := g.Open(ctx, nil); err != nil {
+		return nil, err
+	}
+	if err := move(abovePlace, linearMotion); err != nil {
+		return nil, errors.Wrap(err, "leaving the object")
+	}
+	return grasp, nil
+}
@@ -0,0 +1,398 @@
+package pointcloud
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"math"
+	"mime"
+	"strconv"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/utils"
+)
+
+// compactMagic starts every point cloud in the compact encoding, followed by its version.
+const compactMagic = "VIAMPC\x01"
+
+// the fields that a point cloud in the compact encoding has.
+const (
+	compactHasColor = 1 << iota
+	compactHasValue
+	compactHasIntensity
+	compactHasNormal
+)
+
+// compactResolutionParam is the MIME type parameter of utils.MimeTypeCompactPointCloud that quantizes the points.
+const compactResolutionParam = "resolution_mm"
+
+// ToCompact writes out a point cloud in a compact encoding meant for sending point clouds over the network, which
+// are often too large to send as PCD for every frame of a lidar. After a magic string and version, the encoding is
+// deflated and holds a byte of the fields that the points have, the number of points, and the resolution that the
+// positions are quantized to in millimeters, or 0 if they are not. Quantized positions follow as offsets from the
+// minimum position, in uint16 if they all fit and otherwise uint32, and other positions as float32 millimeters.
+// All the X positions come first, then the Y and then the Z, as do all the values of each field, which compresses
+// much better than points do. The fields are red, green and blue bytes, int32 values, uint16 intensities and float32
+// normals, in that order. Everything is little endian. Quantizing loses up to half of the resolution in each
+// dimension and merges points that end up at the same position.
+func ToCompact(cloud PointCloud, out io.Writer, resolution float64) error {
+	if resolution < 0 || math.IsNaN(resolution) || math.IsInf(resolution, 0) {
+		return errors.Errorf("invalid point cloud resolution %v", resolution)
+	}
+	positions := make([]r3.Vector, 0, cloud.Size())
+	data := make([]Data, 0, cloud.Size())
+	var fields byte
+	cloud.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		positions = append(positions, p)
+		data = append(data, d)
+		if d == nil {
+			return true
+		}
+		if d.HasColor() {
+			fields |= compactHasColor
+		}
+		if d.HasValue() {
+			fields |= compactHasValue
+		}
+		if d.Intensity() != 0 {
+			fields |= compactHasIntensity
+		}
+		if _, ok := DataNormal(d); ok {
+			fields |= compactHasNormal
+		}
+		return true
+	})
+
+	if _, err := io.WriteString(out, compactMagic); err != nil {
+		return err
+	}
+	fw, err := flate.NewWriter(out, flate.BestSpeed)
+	if err != nil {
+		return err
+	}
+	w := &compactWriter{w: fw}
+	w.write([]byte{fields})
+	w.putUint32(uint32(len(positions)))
+	w.putFloat64(resolution)
+
+	coords := func(p r3.Vector) [3]float64 { return [3]float64{p.X, p.Y, p.Z} }
+	if resolution > 0 {
+		var origin, extent [3]float64
+		for i, p := range positions {
+			for axis, v := range coords(p) {
+				switch {
+				case i == 0:
+					origin[axis] = v
+				case v < origin[axis]:
+					extent[axis] += origin[axis] - v
+					origin[axis] = v
+				}
+				extent[axis] = math.Max(extent[axis], v-origin[axis])
+			}
+		}
+		width := byte(2)
+		for _, e := range extent {
+			q := math.Round(e / resolution)
+			if q > math.MaxUint32 {
+				return errors.Errorf("point cloud resolution %v is too fine for its extent of %v", resolution, e)
+			}
+			if q > math.MaxUint16 {
+				width = 4
+			}
+		}
+		for _, o := range origin {
+			w.putFloat64(o)
+		}
+		w.write([]byte{width})
+		for axis := 0; axis < 3; axis++ {
+			for _, p := range positions {
+				q := uint32(math.Round((coords(p)[axis] - origin[axis]) / resolution))
+				if width == 2 {
+					w.putUint16(uint16(q))
+				} else {
+					w.putUint32(q)
+				}
+			}
+		}
+	} else {
+		for axis := 0; axis < 3; axis++ {
+			for _, p := range positions {
+				w.putUint32(math.Float32bits(float32(coords(p)[axis])))
+			}
+		}
+	}
+
+	if fields&compactHasColor != 0 {
+		column := make([]byte, len(data))
+		for channel := 0; channel < 3; channel++ {
+			for i, d := range data {
+				column[i] = 0
+				if d != nil && d.HasColor() {
+					r, g, b := d.RGB255()
+					column[i] = [3]uint8{r, g, b}[channel]
+				}
+			}
+			w.write(column)
+		}
+	}
+	if fields&compactHasValue != 0 {
+		for _, d := range data {
+			var v int
+			if d != nil {
+				v = d.Value()
+			}
+			w.putUint32(uint32(int32(v)))
+		}
+	}
+	if fields&compactHasIntensity != 0 {
+		for _, d := range data {
+			var v uint16
+			if d != nil {
+				v = d.Intensity()
+			}
+			w.putUint16(v)
+		}
+	}
+	if fields&compactHasNormal != 0 {
+		for axis := 0; axis < 3; axis++ {
+			for _, d := range data {
+				n, _ := DataNormal(d)
+				w.putUint32(math.Float32bits(float32(coords(n)[axis])))
+			}
+		}
+	}
+	if w.err != nil {
+		return w.err
+	}
+	return fw.Close()
+}
+
+// compactWriter writes little endian values, keeping the first error that it hits so that it only has to be
+// checked once at the end.
+type compactWriter struct {
+	w   io.Writer
+	buf [8]byte
+	err error
+}
+
+func (cw *compactWriter) write(b []byte) {
+	if cw.err == nil {
+		_, cw.err = cw.w.Write(b)
+	}
+}
+
+func (cw *compactWriter) putUint16(v uint16) {
+	binary.LittleEndian.PutUint16(cw.buf[:], v)
+	cw.write(cw.buf[:2])
+}
+
+func (cw *compactWriter) putUint32(v uint32) {
+	binary.LittleEndian.PutUint32(cw.buf[:], v)
+	cw.write(cw.buf[:4])
+}
+
+func (cw *compactWriter) putFloat64(v float64) {
+	binary.LittleEndian.PutUint64(cw.buf[:], math.Float64bits(v))
+	cw.write(cw.buf[:])
+}
+
+// ReadCompact reads a point cloud written by ToCompact.
+func ReadCompact(in io.Reader) (PointCloud, error) {
+	magic := make([]byte, len(compactMagic))
+	if _, err := io.ReadFull(in, magic); err != nil {
+		return nil, errors.Wrap(err, "error reading compact point cloud")
+	}
+	if string(magic) != compactMagic {
+		return nil, errors.New("not a compact point cloud, or of an unsupported version")
+	}
+	r := flate.NewReader(in)
+	//nolint:errcheck
+	defer r.Close()
+	// read everything up front, so that the sizes in the header can be checked before anything is allocated for them
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decompressing compact point cloud")
+	}
+
+	read := func(n int) ([]byte, error) {
+		if n < 0 || n > len(raw) {
+			return nil, errors.New("compact point cloud is truncated")
+		}
+		b := raw[:n]
+		raw = raw[n:]
+		return b, nil
+	}
+	header, err := read(1 + 4 + 8)
+	if err != nil {
+		return nil, err
+	}
+	fields := header[0]
+	numPoints := int(binary.LittleEndian.Uint32(header[1:]))
+	resolution := math.Float64frombits(binary.LittleEndian.Uint64(header[5:]))
+
+	// every point takes at least 6 bytes for its position
+	if numPoints > len(raw)/6 {
+		return nil, errors.New("compact point cloud is truncated")
+	}
+	positions := make([][3]float64, numPoints)
+	if resolution > 0 {
+		header, err := read(3*8 + 1)
+		if err != nil {
+			return nil, err
+		}
+		var origin [3]float64
+		for axis := range origin {
+			origin[axis] = math.Float64frombits(binary.LittleEndian.Uint64(header[8*axis:]))
+		}
+		width := int(header[24])
+		if width != 2 && width != 4 {
+			return nil, errors.Errorf("invalid compact point cloud position width %d", width)
+		}
+		for axis := 0; axis < 3; axis++ {
+			column, err := read(width * numPoints)
+			if err != nil {
+				return nil, err
+			}
+			for i := range positions {
+				var q uint32
+				if width == 2 {
+					q = uint32(binary.LittleEndian.Uint16(column[2*i:]))
+				} else {
+					q = binary.LittleEndian.Uint32(column[4*i:])
+				}
+				positions[i][axis] = origin[axis] + float64(q)*resolution
+			}
+		}
+	} else {
+		for axis := 0; axis < 3; axis++ {
+			column, err := read(4 * numPoints)
+			if err != nil {
+				return nil, err
+			}
+			for i := range positions {
+				positions[i][axis] = float64(math.Float32frombits(binary.LittleEndian.Uint32(column[4*i:])))
+			}
+		}
+	}
+
+	data := make([]*basicData, numPoints)
+	for i := range data {
+		data[i] = &basicData{}
+	}
+	if fields&compactHasColor != 0 {
+		column, err := read(3 * numPoints)
+		if err != nil {
+			return nil, err
+		}
+		for i, d := range data {
+			d.SetColor(color.NRGBA{column[i], column[numPoints+i], column[2*numPoints+i], 255})
+		}
+	}
+	if fields&compactHasValue != 0 {
+		column, err := read(4 * numPoints)
+		if err != nil {
+			return nil, err
+		}
+		for i, d := range data {
+			d.SetValue(int(int32(binary.LittleEndian.Uint32(column[4*i:]))))
+		}
+	}
+	if fields&compactHasIntensity != 0 {
+		column, err := read(2 * numPoints)
+		if err != nil {
+			return nil, err
+		}
+		for i, d := range data {
+			d.SetIntensity(binary.LittleEndian.Uint16(column[2*i:]))
+		}
+	}
+	if fields&compactHasNormal != 0 {
+		column, err := read(3 * 4 * numPoints)
+		if err != nil {
+			return nil, err
+		}
+		for i, d := range data {
+			n := r3.Vector{
+				X: float64(math.Float32frombits(binary.LittleEndian.Uint32(column[4*i:]))),
+				Y: float64(math.Float32frombits(binary.LittleEndian.Uint32(column[4*(numPoints+i):]))),
+				Z: float64(math.Float32frombits(binary.LittleEndian.Uint32(column[4*(2*numPoints+i):]))),
+			}
+			if n.Norm2() > 0 {
+				d.SetNormal(n)
+			}
+		}
+	}
+
+	pc := NewWithPrealloc(numPoints)
+	for i, p := range positions {
+		if err := pc.Set(r3.Vector{X: p[0], Y: p[1], Z: p[2]}, data[i]); err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+// Encode writes out a point cloud in the format of the given MIME type, and returns the MIME type it was written in.
+// This is how point clouds are sent over the network, where clients ask for the format they want. The compact
+// encoding of utils.MimeTypeCompactPointCloud is quantized if the MIME type has a resolution_mm parameter. Any MIME
+// type that is not known gets binary PCD, which all clients can read.
+func Encode(cloud PointCloud, out io.Writer, mimeType string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		mediaType = ""
+	}
+	switch mediaType {
+	case utils.MimeTypeCompactPointCloud:
+		var resolution float64
+		if param, ok := params[compactResolutionParam]; ok {
+			resolution, err = strconv.ParseFloat(param, 64)
+			if err != nil || resolution <= 0 {
+				return "", errors.Errorf("invalid %s %q of MIME type %q", compactResolutionParam, param, mimeType)
+			}
+		}
+		if err := ToCompact(cloud, out, resolution); err != nil {
+			return "", err
+		}
+		if resolution == 0 {
+			return utils.MimeTypeCompactPointCloud, nil
+		}
+		return CompactMIMEType(resolution), nil
+	case utils.MimeTypePLY:
+		return utils.MimeTypePLY, ToPLY(cloud, out, PLYBinary)
+	default:
+		return utils.MimeTypePCD, ToPCD(cloud, out, PCDBinary)
+	}
+}
+
+// CompactMIMEType returns the MIME type of the compact encoding, quantized to the given resolution in millimeters
+// if it is positive.
+func CompactMIMEType(resolution float64) string {
+	if resolution <= 0 {
+		return utils.MimeTypeCompactPointCloud
+	}
+	return mime.FormatMediaType(utils.MimeTypeCompactPointCloud, map[string]string{
+		compactResolutionParam: strconv.FormatFloat(resolution, 'g', -1, 64),
+	})
+}
+
+// Decode reads a point cloud written in the format of the given MIME type, as returned by Encode.
+func Decode(in []byte, mimeType string) (PointCloud, error) {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid point cloud MIME type %q", mimeType)
+	}
+	switch mediaType {
+	case utils.MimeTypePCD:
+		return ReadPCD(bytes.NewReader(in))
+	case utils.MimeTypePLY:
+		return ReadPLY(bytes.NewReader(in))
+	case utils.MimeTypeCompactPointCloud:
+		return ReadCompact(bytes.NewReader(in))
+	default:
+		return nil, errors.Errorf("unknown point cloud MIME type %q", mimeType)
+	}
+}
@@ -0,0 +1,133 @@
+package pointcloud
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/utils"
+)
+
+func TestCompact(t *testing.T) {
+	cloud := New()
+	test.That(t, cloud.Set(NewVector(-1.25, -2, 5), NewColoredData(color.NRGBA{255, 1, 2, 255}).SetValue(-5)), test.ShouldBeNil)
+	test.That(t, cloud.Set(NewVector(582, 12, 0), NewBasicData().SetIntensity(300).(NormalData).SetNormal(r3.Vector{0, 1, 0})), test.ShouldBeNil)
+	test.That(t, cloud.Set(NewVector(7, 6, 1), NewBasicData()), test.ShouldBeNil)
+
+	var buf bytes.Buffer
+	test.That(t, ToCompact(cloud, &buf, 0), test.ShouldBeNil)
+	cloud2, err := ReadCompact(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud2.Size(), test.ShouldEqual, 3)
+	d, ok := cloud2.At(-1.25, -2, 5)
+	test.That(t, ok, test.ShouldBeTrue)
+	r, g, b := d.RGB255()
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{255, 1, 2})
+	test.That(t, d.Value(), test.ShouldEqual, -5)
+	_, ok = DataNormal(d)
+	test.That(t, ok, test.ShouldBeFalse)
+	d, ok = cloud2.At(582, 12, 0)
+	test.That(t, ok, test.ShouldBeTrue)
+	// like PCD, points without color get one when others have it
+	r, g, b = d.RGB255()
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{0, 0, 0})
+	test.That(t, d.Intensity(), test.ShouldEqual, 300)
+	normal, ok := DataNormal(d)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, normal, test.ShouldResemble, r3.Vector{0, 1, 0})
+
+	// quantized to the nearest half millimeter from the minimum position
+	buf.Reset()
+	test.That(t, ToCompact(cloud, &buf, 0.5), test.ShouldBeNil)
+	cloud2, err = ReadCompact(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud2.Size(), test.ShouldEqual, 3)
+	test.That(t, CloudContains(cloud2, -1.25, -2, 5), test.ShouldBeTrue)
+	test.That(t, CloudContains(cloud2, 582.25, 12, 0), test.ShouldBeTrue)
+
+	// too fine for the extent of the cloud
+	test.That(t, ToCompact(cloud, &buf, 1e-7), test.ShouldNotBeNil)
+	test.That(t, ToCompact(cloud, &buf, -1), test.ShouldNotBeNil)
+	test.That(t, ToCompact(cloud, &buf, math.NaN()), test.ShouldNotBeNil)
+
+	// empty
+	buf.Reset()
+	test.That(t, ToCompact(New(), &buf, 1), test.ShouldBeNil)
+	cloud2, err = ReadCompact(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud2.Size(), test.ShouldEqual, 0)
+
+	// corrupt
+	_, err = ReadCompact(bytes.NewReader([]byte("VIAMPC\x02")))
+	test.That(t, err, test.ShouldNotBeNil)
+	buf.Reset()
+	test.That(t, ToCompact(cloud, &buf, 0), test.ShouldBeNil)
+	_, err = ReadCompact(bytes.NewReader(buf.Bytes()[:buf.Len()-4]))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	cloud := newBigPC()
+	var pcd bytes.Buffer
+	test.That(t, ToPCD(cloud, &pcd, PCDBinary), test.ShouldBeNil)
+
+	for _, tc := range []struct {
+		mimeType string
+		expected string
+	}{
+		{"", utils.MimeTypePCD},
+		{"image/jpeg", utils.MimeTypePCD},
+		{utils.MimeTypePCD, utils.MimeTypePCD},
+		{utils.MimeTypePLY, utils.MimeTypePLY},
+		{utils.MimeTypeCompactPointCloud, utils.MimeTypeCompactPointCloud},
+		{"pointcloud/vnd.viam.pc; resolution_mm=0.5", "pointcloud/vnd.viam.pc; resolution_mm=0.5"},
+	} {
+		var buf bytes.Buffer
+		mimeType, err := Encode(cloud, &buf, tc.mimeType)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mimeType, test.ShouldEqual, tc.expected)
+		if tc.expected != utils.MimeTypePCD {
+			test.That(t, buf.Len(), test.ShouldBeLessThan, pcd.Len())
+		}
+		cloud2, err := Decode(buf.Bytes(), mimeType)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud2.Size(), test.ShouldEqual, cloud.Size())
+		test.That(t, CloudContains(cloud2, 10, 20, 50), test.ShouldBeTrue)
+	}
+	test.That(t, CompactMIMEType(0), test.ShouldEqual, utils.MimeTypeCompactPointCloud)
+	test.That(t, CompactMIMEType(2), test.ShouldEqual, "pointcloud/vnd.viam.pc; resolution_mm=2")
+
+	_, err := Encode(cloud, &bytes.Buffer{}, "pointcloud/vnd.viam.pc; resolution_mm=a")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = Encode(cloud, &bytes.Buffer{}, "pointcloud/vnd.viam.pc; resolution_mm=0")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = Decode(pcd.Bytes(), "image/jpeg")
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = Decode(pcd.Bytes(), "")
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+// dataWithoutNormals is Data implemented outside this package, which need not support normals.
+type dataWithoutNormals struct {
+	Data
+}
+
+func TestCompactDataWithoutNormals(t *testing.T) {
+	cloud := New()
+	test.That(t, cloud.Set(NewVector(1, 2, 3), dataWithoutNormals{NewValueData(4)}), test.ShouldBeNil)
+	test.That(t, cloud.MetaData().HasNormal, test.ShouldBeFalse)
+
+	var buf bytes.Buffer
+	test.That(t, ToCompact(cloud, &buf, 0), test.ShouldBeNil)
+	cloud2, err := ReadCompact(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	d, ok := cloud2.At(1, 2, 3)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Value(), test.ShouldEqual, 4)
+	_, ok = DataNormal(d)
+	test.That(t, ok, test.ShouldBeFalse)
+}
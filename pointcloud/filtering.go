@@ -0,0 +1,227 @@
+package pointcloud
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/utils"
+)
+
+// VoxelGridDownsample returns a new point cloud with one point for each cube of the given size containing
+// points of the given one, at their centroid. The colors, intensities and normals of those points are
+// averaged, and the value of the first one is kept.
+func VoxelGridDownsample(pc PointCloud, voxelSize float64) (PointCloud, error) {
+	if voxelSize <= 0 {
+		return nil, errors.Errorf("voxel size must be positive, got %.2f", voxelSize)
+	}
+	if pc.Size() == 0 {
+		return New(), nil
+	}
+	type voxelSum struct {
+		n         int
+		point     r3.Vector
+		nColors   int
+		r, g, b   int
+		intensity int
+		normal    r3.Vector
+		nNormals  int
+		value     *int
+	}
+	meta := pc.MetaData()
+	ptMin := r3.Vector{meta.MinX, meta.MinY, meta.MinZ}
+	voxels := map[VoxelCoords]*voxelSum{}
+	// keep the order in which voxels are first seen so the output is deterministic
+	var order []VoxelCoords
+	pc.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		coords := GetVoxelCoordinates(p, ptMin, voxelSize)
+		sum, ok := voxels[coords]
+		if !ok {
+			sum = &voxelSum{}
+			voxels[coords] = sum
+			order = append(order, coords)
+		}
+		sum.n++
+		sum.point = sum.point.Add(p)
+		if d == nil {
+			return true
+		}
+		if d.HasColor() {
+			r, g, b := d.RGB255()
+			sum.r += int(r)
+			sum.g += int(g)
+			sum.b += int(b)
+			sum.nColors++
+		}
+		sum.intensity += int(d.Intensity())
+		if normal, ok := DataNormal(d); ok {
+			sum.normal = sum.normal.Add(normal)
+			sum.nNormals++
+		}
+		if d.HasValue() && sum.value == nil {
+			v := d.Value()
+			sum.value = &v
+		}
+		return true
+	})
+
+	downsampled := NewWithPrealloc(len(voxels))
+	for _, coords := range order {
+		sum := voxels[coords]
+		d := &basicData{}
+		if sum.nColors > 0 {
+			d.SetColor(color.NRGBA{
+				uint8(sum.r / sum.nColors),
+				uint8(sum.g / sum.nColors),
+				uint8(sum.b / sum.nColors),
+				255,
+			})
+		}
+		d.SetIntensity(uint16(sum.intensity / sum.n))
+		if sum.nNormals > 0 && sum.normal.Norm() > 0 {
+			d.SetNormal(sum.normal.Normalize())
+		}
+		if sum.value != nil {
+			d.SetValue(*sum.value)
+		}
+		if err := downsampled.Set(sum.point.Mul(1/float64(sum.n)), d); err != nil {
+			return nil, err
+		}
+	}
+	return downsampled, nil
+}
+
+// RadiusOutlierFilter returns a function that removes the points of a point cloud that have fewer than
+// minNeighbors other points within the radius of them.
+// https://pcl.readthedocs.io/projects/tutorials/en/latest/remove_outliers.html
+func RadiusOutlierFilter(radius float64, minNeighbors int) (func(PointCloud) (PointCloud, error), error) {
+	if radius <= 0 {
+		return nil, errors.Errorf("argument radius must be a positive float, got %.2f", radius)
+	}
+	if minNeighbors <= 0 {
+		return nil, errors.Errorf("argument minNeighbors must be a positive int, got %d", minNeighbors)
+	}
+	filterFunc := func(pc PointCloud) (PointCloud, error) {
+		kd := ToKDTree(pc)
+		filteredCloud := New()
+		var err error
+		kd.Iterate(0, 0, func(v r3.Vector, d Data) bool {
+			if len(kd.RadiusNearestNeighbors(v, radius, false)) >= minNeighbors {
+				err = filteredCloud.Set(v, d)
+			}
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return filteredCloud, nil
+	}
+	return filterFunc, nil
+}
+
+// CropBox returns a new point cloud with only the points of the given one within the axis-aligned box between
+// the min and max points, inclusive, or only the points outside of it if negative is true.
+func CropBox(pc PointCloud, minPt, maxPt r3.Vector, negative bool) (PointCloud, error) {
+	if minPt.X > maxPt.X || minPt.Y > maxPt.Y || minPt.Z > maxPt.Z {
+		return nil, errors.Errorf("min point %v of crop box must not be greater than max point %v", minPt, maxPt)
+	}
+	return selectPoints(pc, func(p r3.Vector) bool {
+		inside := p.X >= minPt.X && p.X <= maxPt.X &&
+			p.Y >= minPt.Y && p.Y <= maxPt.Y &&
+			p.Z >= minPt.Z && p.Z <= maxPt.Z
+		return inside != negative
+	})
+}
+
+// A Frustum is the pyramidal region seen by a camera looking down its positive Z axis, with X to its right and
+// Y below, between a near and a far distance along Z. Its field of view angles are in degrees.
+type Frustum struct {
+	HorizontalFOV float64
+	VerticalFOV   float64
+	Near          float64
+	Far           float64
+}
+
+// Validate ensures the angles and distances of the frustum describe a region.
+func (f Frustum) Validate() error {
+	if f.HorizontalFOV <= 0 || f.HorizontalFOV >= 180 || f.VerticalFOV <= 0 || f.VerticalFOV >= 180 {
+		return errors.Errorf("fields of view of frustum must be between 0 and 180 degrees, got %.2f and %.2f",
+			f.HorizontalFOV, f.VerticalFOV)
+	}
+	if f.Near < 0 || f.Far <= f.Near {
+		return errors.Errorf("frustum must have 0 <= near < far, got near %.2f and far %.2f", f.Near, f.Far)
+	}
+	return nil
+}
+
+// Contains returns whether the point is within the frustum.
+func (f Frustum) Contains(p r3.Vector) bool {
+	if p.Z < f.Near || p.Z > f.Far {
+		return false
+	}
+	return math.Abs(p.X) <= p.Z*math.Tan(utils.DegToRad(f.HorizontalFOV/2)) &&
+		math.Abs(p.Y) <= p.Z*math.Tan(utils.DegToRad(f.VerticalFOV/2))
+}
+
+// CropFrustum returns a new point cloud with only the points of the given one within the frustum.
+func CropFrustum(pc PointCloud, frustum Frustum) (PointCloud, error) {
+	if err := frustum.Validate(); err != nil {
+		return nil, err
+	}
+	return selectPoints(pc, frustum.Contains)
+}
+
+func selectPoints(pc PointCloud, keep func(p r3.Vector) bool) (PointCloud, error) {
+	selected := New()
+	var err error
+	pc.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		if keep(p) {
+			err = selected.Set(p, d)
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+// EstimateNormals returns a new point cloud with the points of the given one, with the surface normal of each
+// estimated from the plane best fitting the k points nearest to it, including itself. Normals are oriented towards the
+// viewpoint, such as the origin of the frame of the camera that captured the points.
+func EstimateNormals(pc PointCloud, k int, viewpoint r3.Vector) (PointCloud, error) {
+	if k < 3 {
+		return nil, errors.Errorf("need at least 3 points to fit a plane to when estimating normals, got %d", k)
+	}
+	kd := ToKDTree(pc)
+	withNormals := NewWithPrealloc(kd.Size())
+	var err error
+	kd.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		neighbors := kd.KNearestNeighbors(p, k, true)
+		newData := &basicData{}
+		if d != nil {
+			newData = copyData(d)
+		}
+		if len(neighbors) >= 3 {
+			points := make([]r3.Vector, 0, len(neighbors))
+			for _, n := range neighbors {
+				points = append(points, n.P)
+			}
+			normal := estimatePlaneNormalFromPoints(points)
+			if normal.Norm() > 0 {
+				if normal.Dot(viewpoint.Sub(p)) < 0 {
+					normal = normal.Mul(-1)
+				}
+				newData.SetNormal(normal)
+			}
+		}
+		err = withNormals.Set(p, newData)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withNormals, nil
+}
@@ -0,0 +1,138 @@
+package pointcloud
+
+import (
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+func TestVoxelGridDownsample(t *testing.T) {
+	pc := New()
+	test.That(t, pc.Set(NewVector(0, 0, 0), NewColoredData(color.NRGBA{0, 0, 0, 255}).SetValue(1)), test.ShouldBeNil)
+	test.That(t, pc.Set(NewVector(0.5, 0.5, 0.5), NewColoredData(color.NRGBA{100, 50, 20, 255}).SetValue(2)), test.ShouldBeNil)
+	test.That(t, pc.Set(NewVector(1.5, 0, 0), NewBasicData().SetIntensity(10)), test.ShouldBeNil)
+	test.That(t, pc.Set(NewVector(1.7, 0.2, 0.9), NewBasicData().SetIntensity(20)), test.ShouldBeNil)
+	test.That(t, pc.Set(NewVector(3.2, 3.2, 3.2), nil), test.ShouldBeNil)
+
+	downsampled, err := VoxelGridDownsample(pc, 1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, downsampled.Size(), test.ShouldEqual, 3)
+
+	d, got := downsampled.At(0.25, 0.25, 0.25)
+	test.That(t, got, test.ShouldBeTrue)
+	test.That(t, d.HasColor(), test.ShouldBeTrue)
+	r, g, b := d.RGB255()
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{50, 25, 10})
+	test.That(t, d.HasValue(), test.ShouldBeTrue)
+
+	d, got = downsampled.At(1.6, 0.1, 0.45)
+	test.That(t, got, test.ShouldBeTrue)
+	test.That(t, d.HasColor(), test.ShouldBeFalse)
+	test.That(t, d.Intensity(), test.ShouldEqual, 15)
+
+	_, got = downsampled.At(3.2, 3.2, 3.2)
+	test.That(t, got, test.ShouldBeTrue)
+
+	_, err = VoxelGridDownsample(pc, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+	empty, err := VoxelGridDownsample(New(), 1)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, empty.Size(), test.ShouldEqual, 0)
+}
+
+func TestRadiusOutlierFilter(t *testing.T) {
+	_, err := RadiusOutlierFilter(0, 1)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = RadiusOutlierFilter(1, 0)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	pc := New()
+	for x := 0.0; x < 5; x++ {
+		for y := 0.0; y < 5; y++ {
+			test.That(t, pc.Set(NewVector(x, y, 0), nil), test.ShouldBeNil)
+		}
+	}
+	test.That(t, pc.Set(NewVector(20, 20, 20), nil), test.ShouldBeNil)
+	test.That(t, pc.Set(NewVector(20, 21, 20), nil), test.ShouldBeNil)
+
+	filter, err := RadiusOutlierFilter(1.5, 2)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err := filter(pc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 25)
+	_, got := filtered.At(20, 20, 20)
+	test.That(t, got, test.ShouldBeFalse)
+
+	filter, err = RadiusOutlierFilter(1.5, 1)
+	test.That(t, err, test.ShouldBeNil)
+	filtered, err = filter(pc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, filtered.Size(), test.ShouldEqual, 27)
+}
+
+func TestCrop(t *testing.T) {
+	pc := New()
+	for _, p := range []r3.Vector{{0, 0, 1}, {1, 1, 1}, {-1, 0, 1}, {5, 0, 1}, {0, 0, 10}, {0, 0, -1}} {
+		test.That(t, pc.Set(p, nil), test.ShouldBeNil)
+	}
+
+	cropped, err := CropBox(pc, r3.Vector{-1, -1, 0}, r3.Vector{1, 1, 2}, false)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cropped.Size(), test.ShouldEqual, 3)
+	cropped, err = CropBox(pc, r3.Vector{-1, -1, 0}, r3.Vector{1, 1, 2}, true)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cropped.Size(), test.ShouldEqual, 3)
+	_, got := cropped.At(5, 0, 1)
+	test.That(t, got, test.ShouldBeTrue)
+	_, err = CropBox(pc, r3.Vector{1, 1, 1}, r3.Vector{0, 0, 0}, false)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	frustum := Frustum{HorizontalFOV: 90, VerticalFOV: 60, Near: 0.5, Far: 5}
+	cropped, err = CropFrustum(pc, frustum)
+	test.That(t, err, test.ShouldBeNil)
+	// (1, 1, 1) is within the 45 degrees to the right, but not the 30 degrees below
+	test.That(t, cropped.Size(), test.ShouldEqual, 2)
+	_, got = cropped.At(0, 0, 1)
+	test.That(t, got, test.ShouldBeTrue)
+	_, got = cropped.At(-1, 0, 1)
+	test.That(t, got, test.ShouldBeTrue)
+
+	_, err = CropFrustum(pc, Frustum{HorizontalFOV: 180, VerticalFOV: 60, Far: 5})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = CropFrustum(pc, Frustum{HorizontalFOV: 90, VerticalFOV: 60, Near: 5, Far: 5})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestEstimateNormals(t *testing.T) {
+	pc := New()
+	for x := 0.0; x < 5; x++ {
+		for y := 0.0; y < 5; y++ {
+			test.That(t, pc.Set(NewVector(x, y, 10), NewColoredData(color.NRGBA{1, 2, 3, 255})), test.ShouldBeNil)
+		}
+	}
+	withNormals, err := EstimateNormals(pc, 5, r3.Vector{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, withNormals.Size(), test.ShouldEqual, 25)
+	test.That(t, withNormals.MetaData().HasNormal, test.ShouldBeTrue)
+	withNormals.Iterate(0, 0, func(p r3.Vector, d Data) bool {
+		normal, ok := DataNormal(d)
+		test.That(t, ok, test.ShouldBeTrue)
+		// points on the plane z=10 seen from the origin face back towards it
+		test.That(t, normal.Z, test.ShouldAlmostEqual, -1)
+		test.That(t, math.Abs(normal.X), test.ShouldAlmostEqual, 0)
+		test.That(t, d.HasColor(), test.ShouldBeTrue)
+		return true
+	})
+
+	// the original points are unchanged
+	d, got := pc.At(0, 0, 10)
+	test.That(t, got, test.ShouldBeTrue)
+	_, got = DataNormal(d)
+	test.That(t, got, test.ShouldBeFalse)
+
+	_, err = EstimateNormals(pc, 2, r3.Vector{})
+	test.That(t, err, test.ShouldNotBeNil)
+}
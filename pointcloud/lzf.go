@@ -0,0 +1,149 @@
+package pointcloud
+
+import (
+	"github.com/pkg/errors"
+)
+
+// LZF is the compression of binary_compressed PCD data, as done by liblzf, which PCL uses.
+// http://oldhome.schmorp.de/marc/liblzf.html
+const (
+	lzfHashLog  = 14
+	lzfMaxLit   = 1 << 5
+	lzfMaxOff   = 1 << 13
+	lzfMaxRef   = (1 << 8) + (1 << 3)
+	lzfMinMatch = 3
+
+	// lzfMaxRatio is the most bytes each byte of compressed data can decompress to, reached by back references of
+	// three bytes and the longest length.
+	lzfMaxRatio = lzfMaxRef / 3
+)
+
+func lzfHash(in []byte, i int) uint32 {
+	v := uint32(in[i])<<16 | uint32(in[i+1])<<8 | uint32(in[i+2])
+	return ((v * 2654435761) >> (32 - lzfHashLog)) & (1<<lzfHashLog - 1)
+}
+
+// lzfCompress compresses the input into runs of literal bytes and back references to earlier bytes. A literal run
+// starts with a byte less than 32 giving its length minus one. A back reference starts with a byte whose top three
+// bits give its length minus two, or 7 followed by another byte adding to it, and whose bottom five bits are the
+// top of its offset minus one, followed by the bottom byte of that offset.
+func lzfCompress(in []byte) []byte {
+	out := make([]byte, 0, len(in)+len(in)/lzfMaxLit+1)
+	var htab [1 << lzfHashLog]int
+
+	litStart, lit := len(out), 0
+	out = append(out, 0)
+	endLiterals := func() {
+		if lit == 0 {
+			out = out[:len(out)-1]
+		} else {
+			out[litStart] = byte(lit - 1)
+		}
+	}
+	addLiteral := func(b byte) {
+		out = append(out, b)
+		lit++
+		if lit == lzfMaxLit {
+			out[litStart] = lzfMaxLit - 1
+			litStart, lit = len(out), 0
+			out = append(out, 0)
+		}
+	}
+
+	ip := 0
+	for ip+lzfMinMatch <= len(in) {
+		h := lzfHash(in, ip)
+		ref := htab[h] - 1
+		htab[h] = ip + 1
+		off := ip - ref - 1
+		if ref < 0 || off >= lzfMaxOff || in[ref] != in[ip] || in[ref+1] != in[ip+1] || in[ref+2] != in[ip+2] {
+			addLiteral(in[ip])
+			ip++
+			continue
+		}
+
+		maxLen := len(in) - ip
+		if maxLen > lzfMaxRef {
+			maxLen = lzfMaxRef
+		}
+		matchLen := lzfMinMatch
+		for matchLen < maxLen && in[ref+matchLen] == in[ip+matchLen] {
+			matchLen++
+		}
+
+		endLiterals()
+		encodedLen := matchLen - 2
+		if encodedLen < 7 {
+			out = append(out, byte(off>>8)|byte(encodedLen<<5))
+		} else {
+			out = append(out, byte(off>>8)|7<<5, byte(encodedLen-7))
+		}
+		out = append(out, byte(off))
+		litStart, lit = len(out), 0
+		out = append(out, 0)
+
+		// index the positions within the match too, so later data can refer back to them
+		for i := ip + 1; i < ip+matchLen && i+lzfMinMatch <= len(in); i++ {
+			htab[lzfHash(in, i)] = i + 1
+		}
+		ip += matchLen
+	}
+	for ; ip < len(in); ip++ {
+		addLiteral(in[ip])
+	}
+	endLiterals()
+	return out
+}
+
+// lzfDecompress decompresses the input, which must decompress to exactly the given size.
+func lzfDecompress(in []byte, size int) ([]byte, error) {
+	if size < 0 || size > lzfMaxRatio*len(in) {
+		return nil, errors.Errorf("lzf data of %d bytes cannot decompress to %d", len(in), size)
+	}
+	out := make([]byte, 0, size)
+	for ip := 0; ip < len(in); {
+		ctrl := int(in[ip])
+		ip++
+		if ctrl < lzfMaxLit {
+			n := ctrl + 1
+			if ip+n > len(in) {
+				return nil, errors.New("lzf literal run past end of input")
+			}
+			if len(out)+n > size {
+				return nil, errors.New("lzf data decompresses to more than expected")
+			}
+			out = append(out, in[ip:ip+n]...)
+			ip += n
+			continue
+		}
+
+		n := ctrl >> 5
+		if n == 7 {
+			if ip >= len(in) {
+				return nil, errors.New("lzf back reference past end of input")
+			}
+			n += int(in[ip])
+			ip++
+		}
+		n += 2
+		if ip >= len(in) {
+			return nil, errors.New("lzf back reference past end of input")
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[ip]) - 1
+		ip++
+		if ref < 0 {
+			return nil, errors.New("lzf back reference before start of output")
+		}
+		if len(out)+n > size {
+			return nil, errors.New("lzf data decompresses to more than expected")
+		}
+		// references may overlap what they produce, so copy byte by byte
+		for i := 0; i < n; i++ {
+			out = append(out, out[ref+i])
+		}
+	}
+	if len(out) != size {
+		return nil, errors.Errorf("lzf data decompressed to %d bytes, expected %d", len(out), size)
+	}
+	return out, nil
+}
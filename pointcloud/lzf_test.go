@@ -0,0 +1,38 @@
+package pointcloud
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+func TestLZF(t *testing.T) {
+	//nolint:gosec
+	rnd := rand.New(rand.NewSource(1))
+	random := make([]byte, 5000)
+	rnd.Read(random)
+	repetitive := bytes.Repeat([]byte("point cloud "), 1000)
+	for _, in := range [][]byte{{}, {1}, {1, 2}, {1, 2, 3}, random, repetitive, append(random, repetitive...)} {
+		compressed := lzfCompress(in)
+		out, err := lzfDecompress(compressed, len(in))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, out, test.ShouldResemble, in)
+	}
+	test.That(t, len(lzfCompress(repetitive)), test.ShouldBeLessThan, len(repetitive)/10)
+
+	// a literal a followed by a back reference of 9 bytes that overlaps what it produces
+	out, err := lzfDecompress([]byte{0x00, 'a', 0xe0, 0x00, 0x00}, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, string(out), test.ShouldEqual, "aaaaaaaaaa")
+
+	_, err = lzfDecompress([]byte{0x00, 'a', 0xe0, 0x00, 0x00}, 9)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = lzfDecompress([]byte{0x00, 'a', 0xe0, 0x00, 0x00}, 11)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = lzfDecompress([]byte{0x00, 'a', 0x20, 0x05}, 4)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = lzfDecompress([]byte{0x05, 'a'}, 6)
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -69,6 +69,30 @@ type Data interface {
 	SetIntensity(v uint16) Data
 }
 
+// NormalData is implemented by Data that can hold a surface normal, like that returned by NewBasicData and
+// the other constructors of this package. It is separate from Data so that other implementations of Data
+// need not support normals; use DataNormal to get the normal of any Data.
+type NormalData interface {
+	// HasNormal returns whether or not this point has a surface normal, like one estimated
+	// by EstimateNormals.
+	HasNormal() bool
+
+	// Normal returns the unit surface normal, if it exists.
+	Normal() r3.Vector
+
+	// SetNormal sets the given surface normal on the point.
+	SetNormal(n r3.Vector) Data
+}
+
+// DataNormal returns the surface normal of the data, if it is NormalData that has one.
+func DataNormal(d Data) (r3.Vector, bool) {
+	nd, ok := d.(NormalData)
+	if !ok || !nd.HasNormal() {
+		return r3.Vector{}, false
+	}
+	return nd.Normal(), true
+}
+
 type basicData struct {
 	hasColor bool
 	c        color.NRGBA
@@ -77,6 +101,9 @@ type basicData struct {
 	value    int
 
 	intensity uint16
+
+	hasNormal bool
+	normal    r3.Vector
 }
 
 // NewBasicData returns a point that is solely positionally based.
@@ -134,3 +161,41 @@ func (bp *basicData) SetIntensity(v uint16) Data {
 func (bp *basicData) Intensity() uint16 {
 	return bp.intensity
 }
+
+func (bp *basicData) SetNormal(n r3.Vector) Data {
+	bp.hasNormal = true
+	bp.normal = n
+	return bp
+}
+
+func (bp *basicData) HasNormal() bool {
+	return bp.hasNormal
+}
+
+func (bp *basicData) Normal() r3.Vector {
+	return bp.normal
+}
+
+// CopyData returns a new Data with the color, value, intensity and normal of the given one, so that they can
+// be changed without changing those of the original, or nil if it is nil.
+func CopyData(d Data) Data {
+	if d == nil {
+		return nil
+	}
+	return copyData(d)
+}
+
+func copyData(d Data) *basicData {
+	bp := &basicData{intensity: d.Intensity()}
+	if d.HasColor() {
+		r, g, b := d.RGB255()
+		bp.SetColor(color.NRGBA{r, g, b, 255})
+	}
+	if d.HasValue() {
+		bp.SetValue(d.Value())
+	}
+	if normal, ok := DataNormal(d); ok {
+		bp.SetNormal(normal)
+	}
+	return bp
+}
@@ -8,6 +8,7 @@ import (
 	"image/color"
 	"io"
 	"math"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -40,11 +41,24 @@ func NewFromFile(fn string, logger golog.Logger) (PointCloud, error) {
 	switch filepath.Ext(fn) {
 	case ".las":
 		return NewFromLASFile(fn, logger)
+	case ".pcd":
+		return readFile(fn, ReadPCD)
+	case ".ply":
+		return readFile(fn, ReadPLY)
 	default:
 		return nil, errors.Errorf("do not know how to read file %q", fn)
 	}
 }
 
+func readFile(fn string, read func(io.Reader) (PointCloud, error)) (PointCloud, error) {
+	f, err := os.Open(fn) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer utils.UncheckedErrorFunc(f.Close)
+	return read(f)
+}
+
 // pointValueDataTag encodes if the point has value data.
 const pointValueDataTag = "rc|pv"
 
@@ -285,11 +299,11 @@ func ToPCD(cloud PointCloud, out io.Writer, outputType PCDType) error {
 			return err
 		}
 	case PCDCompressed:
-		// _, err = fmt.Fprintf(out, "DATA binary_compressed\n")
-		// if err != nil {
-		// 	return err
-		// }
-		return errors.New("compressed PCD not yet implemented")
+		_, err = fmt.Fprintf(out, "DATA binary_compressed\n")
+		if err != nil {
+			return err
+		}
+		return writePCDCompressed(cloud, out)
 	}
 	err = writePCDData(cloud, out, outputType)
 	if err != nil {
@@ -317,8 +331,6 @@ func writePCDData(cloud PointCloud, out io.Writer, pcdtype PCDType) error {
 				_, err = out.Write(buf)
 			case PCDAscii:
 				_, err = fmt.Fprintf(out, "%f %f %f %d\n", x, y, z, c)
-			case PCDCompressed:
-				return false // TODO(aidanglickman): Implement compressed PCD
 			default:
 				return false
 			}
@@ -332,8 +344,6 @@ func writePCDData(cloud PointCloud, out io.Writer, pcdtype PCDType) error {
 				_, err = out.Write(buf)
 			case PCDAscii:
 				_, err = fmt.Fprintf(out, "%f %f %f\n", x, y, z)
-			case PCDCompressed:
-				return false // TODO(aidanglickman): Implement compressed PCD
 			default:
 				return false
 			}
@@ -343,6 +353,44 @@ func writePCDData(cloud PointCloud, out io.Writer, pcdtype PCDType) error {
 	return nil
 }
 
+// writePCDCompressed writes the data of a binary_compressed PCD. Like PCL, all the values of each field are written
+// one after the other, which compresses better than the points do, and are compressed with LZF behind the compressed
+// and uncompressed sizes.
+func writePCDCompressed(cloud PointCloud, out io.Writer) error {
+	numFields := int(pcdPointOnly)
+	hasColor := cloud.MetaData().HasColor
+	if hasColor {
+		numFields = int(pcdPointColor)
+	}
+	numPoints := cloud.Size()
+	data := make([]byte, 4*numFields*numPoints)
+	i := 0
+	cloud.Iterate(0, 0, func(pos r3.Vector, d Data) bool {
+		if i >= numPoints {
+			return false
+		}
+		// Converts RDK units (millimeters) to meters for PCD
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(float32(pos.X/1000.)))
+		binary.LittleEndian.PutUint32(data[4*(numPoints+i):], math.Float32bits(float32(pos.Y/1000.)))
+		binary.LittleEndian.PutUint32(data[4*(2*numPoints+i):], math.Float32bits(float32(pos.Z/1000.)))
+		if hasColor {
+			binary.LittleEndian.PutUint32(data[4*(3*numPoints+i):], uint32(_colorToPCDInt(d)))
+		}
+		i++
+		return true
+	})
+
+	compressed := lzfCompress(data)
+	sizes := make([]byte, 8)
+	binary.LittleEndian.PutUint32(sizes, uint32(len(compressed)))
+	binary.LittleEndian.PutUint32(sizes[4:], uint32(len(data)))
+	if _, err := out.Write(sizes); err != nil {
+		return err
+	}
+	_, err := out.Write(compressed)
+	return err
+}
+
 func readFloat(n uint32) float64 {
 	f := float64(math.Float32frombits(n))
 	return math.Round(f*10000) / 10000
@@ -547,9 +595,9 @@ func readPCDHelper(inRaw io.Reader, pctype PCType) (PointCloud, error) {
 	}
 	switch pctype {
 	case BasicType:
-		pc = NewWithPrealloc(int(header.points))
+		pc = NewWithPrealloc(headerPrealloc(int(header.points)))
 	case KDTreeType:
-		pc = NewKDTreeWithPrealloc(int(header.points))
+		pc = NewKDTreeWithPrealloc(headerPrealloc(int(header.points)))
 	case BasicOctreeType:
 
 		// Extract data from bufio.Reader to make a copy for metadata acquisition
@@ -577,8 +625,7 @@ func readPCDHelper(inRaw io.Reader, pctype PCType) (PointCloud, error) {
 	case PCDBinary:
 		return readPCDBinary(in, *header, pc)
 	case PCDCompressed:
-		// return readPCDCompressed(in, header)
-		return nil, errors.New("compressed pcd not yet supported")
+		return readPCDCompressed(in, *header, pc)
 	default:
 		return nil, fmt.Errorf("unsupported pcd data type %v", header.data)
 	}
@@ -670,6 +717,89 @@ func readPCDBinary(in *bufio.Reader, header pcdHeader, pc PointCloud) (PointClou
 	return pc, nil
 }
 
+// maxHeaderPrealloc is the most points preallocated for a point cloud read from a file, whose header may claim
+// any number of them; clouds with more grow as their points are read.
+const maxHeaderPrealloc = 1 << 20
+
+// headerPrealloc returns how many points to preallocate for a file whose header claims the given number.
+func headerPrealloc(points int) int {
+	if points < 0 || points > maxHeaderPrealloc {
+		return maxHeaderPrealloc
+	}
+	return points
+}
+
+// extractPCDPointsCompressed decompresses the data of a binary_compressed PCD, in which all the values of each field
+// are stored one after the other, and calls fn with each of its points.
+func extractPCDPointsCompressed(in *bufio.Reader, header pcdHeader, fn func(PointAndData) error) error {
+	sizes := make([]byte, 8)
+	if _, err := io.ReadFull(in, sizes); err != nil {
+		return errors.Wrap(err, "error reading compressed pcd sizes")
+	}
+	compressedSize := binary.LittleEndian.Uint32(sizes)
+	uncompressedSize := binary.LittleEndian.Uint32(sizes[4:])
+
+	numFields := int(header.fields)
+	offsets := make([]uint64, numFields)
+	var pointSize uint64
+	for j := 0; j < numFields; j++ {
+		if header.size[j] != 4 {
+			return errors.Errorf("unsupported compressed pcd field size %d", header.size[j])
+		}
+		offsets[j] = pointSize * header.points
+		pointSize += header.size[j] * header.count[j]
+	}
+	// compared by division so that a huge number of points cannot overflow into a match
+	if pointSize == 0 || uint64(uncompressedSize)%pointSize != 0 || uint64(uncompressedSize)/pointSize != header.points {
+		return errors.Errorf("compressed pcd uncompressed size %d does not match %d points of %d bytes",
+			uncompressedSize, header.points, pointSize)
+	}
+
+	// the sizes are only trusted as far as there is data to back them
+	compressed, err := io.ReadAll(io.LimitReader(in, int64(compressedSize)))
+	if err != nil {
+		return errors.Wrap(err, "error reading compressed pcd data")
+	}
+	if len(compressed) != int(compressedSize) {
+		return errors.Wrap(io.ErrUnexpectedEOF, "error reading compressed pcd data")
+	}
+	data, err := lzfDecompress(compressed, int(uncompressedSize))
+	if err != nil {
+		return err
+	}
+
+	value := func(field int, i uint64) uint32 {
+		stride := header.size[field] * header.count[field]
+		return binary.LittleEndian.Uint32(data[offsets[field]+i*stride:])
+	}
+	for i := uint64(0); i < header.points; i++ {
+		// Converts PCD units (meters) to millimeters for RDK
+		point := r3.Vector{
+			X: 1000. * readFloat(value(0, i)),
+			Y: 1000. * readFloat(value(1, i)),
+			Z: 1000. * readFloat(value(2, i)),
+		}
+		d := NewBasicData()
+		if header.fields == pcdPointColor {
+			d = NewColoredData(_pcdIntToColor(int(value(3, i))))
+		}
+		if err := fn(PointAndData{P: point, D: d}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readPCDCompressed(in *bufio.Reader, header pcdHeader, pc PointCloud) (PointCloud, error) {
+	err := extractPCDPointsCompressed(in, header, func(pd PointAndData) error {
+		return pc.Set(pd.P, pd.D)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
 func parsePCDMetaData(in bufio.Reader, header pcdHeader) (MetaData, error) {
 	meta := NewMetaData()
 	switch header.data {
@@ -691,8 +821,13 @@ func parsePCDMetaData(in bufio.Reader, header pcdHeader) (MetaData, error) {
 			meta.Merge(pd.P, pd.D)
 		}
 	case PCDCompressed:
-		// return readPCDCompressed(in, header)
-		return MetaData{}, errors.New("compressed pcd not yet supported")
+		err := extractPCDPointsCompressed(&in, header, func(pd PointAndData) error {
+			meta.Merge(pd.P, pd.D)
+			return nil
+		})
+		if err != nil {
+			return MetaData{}, err
+		}
 	default:
 		return MetaData{}, fmt.Errorf("unsupported pcd data type %v", header.data)
 	}
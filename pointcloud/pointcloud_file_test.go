@@ -6,6 +6,7 @@ import (
 	"image/color"
 	"math"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -66,6 +67,7 @@ func TestPCD(t *testing.T) {
 	testPCDHeaders(t)
 	testASCIIRoundTrip(t, cloud)
 	testBinaryRoundTrip(t, cloud)
+	testCompressedRoundTrip(t, cloud)
 }
 
 func testPCDHeaders(t *testing.T) {
@@ -159,6 +161,7 @@ func TestPCDNoColor(t *testing.T) {
 	testNoColorASCIIRoundTrip(t, cloud)
 	testNoColorBinaryRoundTrip(t, cloud)
 	testLargeBinaryNoError(t)
+	testLargeCompressed(t)
 }
 
 func testNoColorASCIIRoundTrip(t *testing.T, cloud PointCloud) {
@@ -254,6 +257,72 @@ func testBinaryRoundTrip(t *testing.T, cloud PointCloud) {
 	test.That(t, b, test.ShouldEqual, 2)
 }
 
+func testCompressedRoundTrip(t *testing.T, cloud PointCloud) {
+	t.Helper()
+	var buf bytes.Buffer
+	err := ToPCD(cloud, &buf, PCDCompressed)
+	test.That(t, err, test.ShouldBeNil)
+	gotPCD := buf.String()
+	test.That(t, gotPCD, test.ShouldContainSubstring, "POINTS 3\n")
+	test.That(t, gotPCD, test.ShouldContainSubstring, "DATA binary_compressed\n")
+
+	cloud2, err := ReadPCD(strings.NewReader(gotPCD))
+	test.That(t, err, test.ShouldBeNil)
+	testPCDOutput(t, cloud2)
+	data, dataFlag := cloud2.At(-1, -2, 5)
+	test.That(t, dataFlag, test.ShouldBeTrue)
+	test.That(t, data.HasColor(), test.ShouldBeTrue)
+	r, g, b := data.RGB255()
+	test.That(t, r, test.ShouldEqual, 255)
+	test.That(t, g, test.ShouldEqual, 1)
+	test.That(t, b, test.ShouldEqual, 2)
+
+	meta, err := GetPCDMetaData(strings.NewReader(gotPCD))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, meta.HasColor, test.ShouldBeTrue)
+	test.That(t, meta.MaxX, test.ShouldEqual, 582)
+
+	// the data is cut short
+	_, err = ReadPCD(strings.NewReader(gotPCD[:len(gotPCD)-1]))
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func testLargeCompressed(t *testing.T) {
+	t.Helper()
+	largeCloud := newBigPC()
+	var binaryBuf, compressedBuf bytes.Buffer
+	test.That(t, ToPCD(largeCloud, &binaryBuf, PCDBinary), test.ShouldBeNil)
+	test.That(t, ToPCD(largeCloud, &compressedBuf, PCDCompressed), test.ShouldBeNil)
+	test.That(t, compressedBuf.Len(), test.ShouldBeLessThan, binaryBuf.Len()/2)
+
+	readPointCloud, err := ReadPCDToBasicOctree(bytes.NewReader(compressedBuf.Bytes()))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, readPointCloud.Size(), test.ShouldEqual, largeCloud.Size())
+	test.That(t, CloudContains(readPointCloud, 10, 20, 50), test.ShouldBeTrue)
+}
+
+func TestNewFromPCDAndPLYFile(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	cloud := newBigPC()
+	dir := t.TempDir()
+
+	pcdFile, err := os.Create(filepath.Join(dir, "cloud.pcd"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ToPCD(cloud, pcdFile, PCDCompressed), test.ShouldBeNil)
+	test.That(t, pcdFile.Close(), test.ShouldBeNil)
+	plyFile, err := os.Create(filepath.Join(dir, "cloud.ply"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ToPLY(cloud, plyFile, PLYBinary), test.ShouldBeNil)
+	test.That(t, plyFile.Close(), test.ShouldBeNil)
+
+	for _, fn := range []string{pcdFile.Name(), plyFile.Name()} {
+		readCloud, err := NewFromFile(fn, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, readCloud.Size(), test.ShouldEqual, cloud.Size())
+		test.That(t, readCloud.MetaData().HasColor, test.ShouldBeTrue)
+	}
+}
+
 func testLargeBinaryNoError(t *testing.T) {
 	// This tests whether large pointclouds that exceed the usual buffered page size for a file error on reads
 	t.Helper()
@@ -412,3 +481,36 @@ func BenchmarkPCDBinaryRead(b *testing.B) {
 		test.That(b, err, test.ShouldBeNil)
 	}
 }
+
+func TestPCDCompressedMalformed(t *testing.T) {
+	cloud := New()
+	test.That(t, cloud.Set(NewVector(1, 2, 3), NewColoredData(color.NRGBA{1, 2, 3, 255})), test.ShouldBeNil)
+	var buf bytes.Buffer
+	test.That(t, ToPCD(cloud, &buf, PCDCompressed), test.ShouldBeNil)
+	header, _, ok := strings.Cut(buf.String(), "DATA binary_compressed\n")
+	test.That(t, ok, test.ShouldBeTrue)
+	header += "DATA binary_compressed\n"
+
+	withData := func(header string, compressedSize, uncompressedSize uint32, data []byte) string {
+		sizes := make([]byte, 8)
+		binary.LittleEndian.PutUint32(sizes, compressedSize)
+		binary.LittleEndian.PutUint32(sizes[4:], uncompressedSize)
+		return header + string(sizes) + string(data)
+	}
+	manyPoints := func(points string) string {
+		h := strings.Replace(header, "WIDTH 1\n", "WIDTH "+points+"\n", 1)
+		return strings.Replace(h, "POINTS 1\n", "POINTS "+points+"\n", 1)
+	}
+
+	for _, bad := range []string{
+		// sizes far beyond the data there is
+		withData(header, math.MaxUint32, 16, []byte{0, 1}),
+		// more points than the data could decompress to
+		withData(manyPoints("100000000"), 2, 1600000000, []byte{0, 1}),
+		// so many points that their size overflows into that of the data
+		withData(manyPoints("1152921504606846976"), 2, 0, []byte{0, 1}),
+	} {
+		_, err := ReadPCD(strings.NewReader(bad))
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
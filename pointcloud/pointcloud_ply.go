@@ -0,0 +1,372 @@
+package pointcloud
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+// PLYType is the format of a ply file.
+type PLYType int
+
+const (
+	// PLYAscii ascii format for ply.
+	PLYAscii PLYType = 0
+	// PLYBinary binary little endian format for ply.
+	PLYBinary PLYType = 1
+)
+
+// ToPLY writes out a point cloud to a PLY file of the specified type. Positions are written in meters, like PCD, as
+// float x y z properties, followed by uchar red green blue properties if the cloud has color and float nx ny nz
+// properties if it has normals.
+func ToPLY(cloud PointCloud, out io.Writer, outputType PLYType) error {
+	var format string
+	switch outputType {
+	case PLYAscii:
+		format = "ascii"
+	case PLYBinary:
+		format = "binary_little_endian"
+	default:
+		return errors.Errorf("unsupported ply type %d", outputType)
+	}
+	meta := cloud.MetaData()
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "ply\nformat %s 1.0\nelement vertex %d\n", format, cloud.Size())
+	fmt.Fprint(w, "property float x\nproperty float y\nproperty float z\n")
+	if meta.HasColor {
+		fmt.Fprint(w, "property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	if meta.HasNormal {
+		fmt.Fprint(w, "property float nx\nproperty float ny\nproperty float nz\n")
+	}
+	fmt.Fprint(w, "end_header\n")
+
+	buf := make([]byte, 27)
+	var err error
+	cloud.Iterate(0, 0, func(pos r3.Vector, d Data) bool {
+		// Converts RDK units (millimeters) to meters for PLY
+		values := []float64{pos.X / 1000., pos.Y / 1000., pos.Z / 1000.}
+		var r, g, b uint8
+		if d != nil && d.HasColor() {
+			r, g, b = d.RGB255()
+		}
+		normal, _ := DataNormal(d)
+
+		if outputType == PLYAscii {
+			line := make([]string, 0, 9)
+			for _, v := range values {
+				line = append(line, strconv.FormatFloat(v, 'f', -1, 32))
+			}
+			if meta.HasColor {
+				line = append(line, strconv.Itoa(int(r)), strconv.Itoa(int(g)), strconv.Itoa(int(b)))
+			}
+			if meta.HasNormal {
+				for _, v := range []float64{normal.X, normal.Y, normal.Z} {
+					line = append(line, strconv.FormatFloat(v, 'f', -1, 32))
+				}
+			}
+			_, err = fmt.Fprintln(w, strings.Join(line, " "))
+			return err == nil
+		}
+
+		n := 0
+		for _, v := range values {
+			binary.LittleEndian.PutUint32(buf[n:], math.Float32bits(float32(v)))
+			n += 4
+		}
+		if meta.HasColor {
+			buf[n], buf[n+1], buf[n+2] = r, g, b
+			n += 3
+		}
+		if meta.HasNormal {
+			for _, v := range []float64{normal.X, normal.Y, normal.Z} {
+				binary.LittleEndian.PutUint32(buf[n:], math.Float32bits(float32(v)))
+				n += 4
+			}
+		}
+		_, err = w.Write(buf[:n])
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+type plyProperty struct {
+	name string
+	// valType is the type of the property, or of the items of a list property.
+	valType string
+	// countType is the type of the length of a list property, and empty for other properties.
+	countType string
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+type plyHeader struct {
+	format    string
+	byteOrder binary.ByteOrder
+	elements  []plyElement
+}
+
+var plyTypeSizes = map[string]int{
+	"char": 1, "int8": 1, "uchar": 1, "uint8": 1,
+	"short": 2, "int16": 2, "ushort": 2, "uint16": 2,
+	"int": 4, "int32": 4, "uint": 4, "uint32": 4,
+	"float": 4, "float32": 4, "double": 8, "float64": 8,
+}
+
+func parsePLYHeader(in *bufio.Reader) (*plyHeader, error) {
+	line, err := in.ReadString('\n')
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading ply header")
+	}
+	if strings.TrimSpace(line) != "ply" {
+		return nil, errors.New("ply file must start with ply")
+	}
+
+	header := &plyHeader{}
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading ply header")
+		}
+		tokens := strings.Fields(line)
+		if len(tokens) == 0 {
+			continue
+		}
+		switch tokens[0] {
+		case "comment", "obj_info":
+		case "format":
+			if len(tokens) != 3 {
+				return nil, errors.Errorf("invalid ply format line %q", strings.TrimSpace(line))
+			}
+			header.format = tokens[1]
+			switch header.format {
+			case "ascii":
+			case "binary_little_endian":
+				header.byteOrder = binary.LittleEndian
+			case "binary_big_endian":
+				header.byteOrder = binary.BigEndian
+			default:
+				return nil, errors.Errorf("unsupported ply format %s", header.format)
+			}
+		case "element":
+			if len(tokens) != 3 {
+				return nil, errors.Errorf("invalid ply element line %q", strings.TrimSpace(line))
+			}
+			count, err := strconv.Atoi(tokens[2])
+			if err != nil || count < 0 {
+				return nil, errors.Errorf("invalid ply element count %s", tokens[2])
+			}
+			header.elements = append(header.elements, plyElement{name: tokens[1], count: count})
+		case "property":
+			if len(header.elements) == 0 {
+				return nil, errors.New("ply property must follow an element")
+			}
+			var prop plyProperty
+			switch {
+			case len(tokens) == 3:
+				prop = plyProperty{name: tokens[2], valType: tokens[1]}
+			case len(tokens) == 5 && tokens[1] == "list":
+				prop = plyProperty{name: tokens[4], valType: tokens[3], countType: tokens[2]}
+				if _, ok := plyTypeSizes[prop.countType]; !ok {
+					return nil, errors.Errorf("unsupported ply property type %s", prop.countType)
+				}
+			default:
+				return nil, errors.Errorf("invalid ply property line %q", strings.TrimSpace(line))
+			}
+			if _, ok := plyTypeSizes[prop.valType]; !ok {
+				return nil, errors.Errorf("unsupported ply property type %s", prop.valType)
+			}
+			element := &header.elements[len(header.elements)-1]
+			element.properties = append(element.properties, prop)
+		case "end_header":
+			if header.format == "" {
+				return nil, errors.New("ply header is missing its format")
+			}
+			return header, nil
+		default:
+			return nil, errors.Errorf("unexpected ply header line %q", strings.TrimSpace(line))
+		}
+	}
+}
+
+// readPLYBinaryValue reads a value of the given type from a binary ply.
+func readPLYBinaryValue(in *bufio.Reader, valType string, order binary.ByteOrder) (float64, error) {
+	buf := make([]byte, plyTypeSizes[valType])
+	if _, err := io.ReadFull(in, buf); err != nil {
+		return 0, err
+	}
+	switch valType {
+	case "char", "int8":
+		return float64(int8(buf[0])), nil
+	case "uchar", "uint8":
+		return float64(buf[0]), nil
+	case "short", "int16":
+		return float64(int16(order.Uint16(buf))), nil
+	case "ushort", "uint16":
+		return float64(order.Uint16(buf)), nil
+	case "int", "int32":
+		return float64(int32(order.Uint32(buf))), nil
+	case "uint", "uint32":
+		return float64(order.Uint32(buf)), nil
+	case "float", "float32":
+		return readFloat(order.Uint32(buf)), nil
+	default:
+		return math.Float64frombits(order.Uint64(buf)), nil
+	}
+}
+
+// readPLYElement reads the values of the properties of one instance of an element. The values of list properties
+// are skipped.
+func readPLYElement(in *bufio.Reader, header *plyHeader, element plyElement) ([]float64, error) {
+	values := make([]float64, len(element.properties))
+	if header.format == "ascii" {
+		line, err := in.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			return nil, err
+		}
+		tokens := strings.Fields(line)
+		t := 0
+		for i, prop := range element.properties {
+			if t >= len(tokens) {
+				return nil, errors.Errorf("too few values in ply %s line %q", element.name, strings.TrimSpace(line))
+			}
+			v, err := strconv.ParseFloat(tokens[t], 64)
+			if err != nil {
+				return nil, errors.Errorf("invalid ply %s value %s", element.name, tokens[t])
+			}
+			t++
+			if prop.countType != "" {
+				if v < 0 || v > float64(len(tokens)-t) {
+					return nil, errors.Errorf("invalid ply %s list length %s", element.name, tokens[t-1])
+				}
+				t += int(v)
+				continue
+			}
+			values[i] = v
+		}
+		return values, nil
+	}
+
+	for i, prop := range element.properties {
+		if prop.countType == "" {
+			v, err := readPLYBinaryValue(in, prop.valType, header.byteOrder)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+			continue
+		}
+		count, err := readPLYBinaryValue(in, prop.countType, header.byteOrder)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := in.Discard(int(count) * plyTypeSizes[prop.valType]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// ReadPLY reads a PLY file into a pointcloud. The vertices of the file become the points of the cloud, with their
+// positions converted from meters to millimeters like PCD, their red green blue properties as their color, their
+// nx ny nz properties as their normal and their intensity property, if any, as their intensity. Other elements,
+// such as faces, are ignored.
+func ReadPLY(inRaw io.Reader) (PointCloud, error) {
+	in := bufio.NewReader(inRaw)
+	header, err := parsePLYHeader(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var pc PointCloud
+	for _, element := range header.elements {
+		isVertex := element.name == "vertex"
+		if isVertex && pc != nil {
+			return nil, errors.New("ply has more than one vertex element")
+		}
+		if isVertex {
+			pc = NewWithPrealloc(headerPrealloc(element.count))
+		}
+		index := map[string]int{}
+		for i, prop := range element.properties {
+			if prop.countType == "" {
+				index[prop.name] = i
+			}
+		}
+		for i := 0; i < element.count; i++ {
+			values, err := readPLYElement(in, header, element)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error reading ply %s %d", element.name, i)
+			}
+			if !isVertex {
+				continue
+			}
+			pos, d, err := plyVertexToPoint(values, index)
+			if err != nil {
+				return nil, err
+			}
+			if err := pc.Set(pos, d); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if pc == nil {
+		return nil, errors.New("ply has no vertex element")
+	}
+	return pc, nil
+}
+
+func plyVertexToPoint(values []float64, index map[string]int) (r3.Vector, Data, error) {
+	value := func(names ...string) (float64, bool) {
+		for _, name := range names {
+			if i, ok := index[name]; ok {
+				return values[i], true
+			}
+		}
+		return 0, false
+	}
+	var coords [3]float64
+	for i, name := range []string{"x", "y", "z"} {
+		v, ok := value(name)
+		if !ok {
+			return r3.Vector{}, nil, fmt.Errorf("ply vertex is missing property %s", name)
+		}
+		coords[i] = v
+	}
+	// Converts PLY units (meters) to millimeters for RDK
+	pos := r3.Vector{X: 1000. * coords[0], Y: 1000. * coords[1], Z: 1000. * coords[2]}
+
+	d := &basicData{}
+	r, hasR := value("red", "diffuse_red")
+	g, hasG := value("green", "diffuse_green")
+	b, hasB := value("blue", "diffuse_blue")
+	if hasR && hasG && hasB {
+		d.SetColor(color.NRGBA{uint8(r), uint8(g), uint8(b), 255})
+	}
+	nx, hasNX := value("nx")
+	ny, hasNY := value("ny")
+	nz, hasNZ := value("nz")
+	if normal := (r3.Vector{X: nx, Y: ny, Z: nz}); hasNX && hasNY && hasNZ && normal.Norm2() > 0 {
+		d.SetNormal(normal)
+	}
+	if intensity, ok := value("intensity"); ok {
+		d.SetIntensity(uint16(intensity))
+	}
+	return pos, d, nil
+}
@@ -0,0 +1,132 @@
+package pointcloud
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image/color"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+)
+
+func TestPLYRoundTrip(t *testing.T) {
+	cloud := New()
+	test.That(t, cloud.Set(NewVector(-1, -2, 5), NewColoredData(color.NRGBA{255, 1, 2, 255}).(NormalData).SetNormal(r3.Vector{0, 0, -1})), test.ShouldBeNil)
+	test.That(t, cloud.Set(NewVector(582, 12, 0), NewColoredData(color.NRGBA{3, 4, 5, 255}).(NormalData).SetNormal(r3.Vector{1, 0, 0})), test.ShouldBeNil)
+	test.That(t, cloud.Set(NewVector(7, 6, 1), NewBasicData()), test.ShouldBeNil)
+
+	for _, plyType := range []PLYType{PLYAscii, PLYBinary} {
+		var buf bytes.Buffer
+		test.That(t, ToPLY(cloud, &buf, plyType), test.ShouldBeNil)
+		gotPLY := buf.String()
+		test.That(t, gotPLY, test.ShouldContainSubstring, "element vertex 3\n")
+		test.That(t, gotPLY, test.ShouldContainSubstring, "property uchar red\n")
+		test.That(t, gotPLY, test.ShouldContainSubstring, "property float nx\n")
+		if plyType == PLYAscii {
+			test.That(t, gotPLY, test.ShouldContainSubstring, "format ascii 1.0\n")
+			test.That(t, gotPLY, test.ShouldContainSubstring, "0.582 0.012 0 3 4 5 1 0 0\n")
+		} else {
+			test.That(t, gotPLY, test.ShouldContainSubstring, "format binary_little_endian 1.0\n")
+		}
+
+		cloud2, err := ReadPLY(strings.NewReader(gotPLY))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cloud2.Size(), test.ShouldEqual, 3)
+		d, ok := cloud2.At(-1, -2, 5)
+		test.That(t, ok, test.ShouldBeTrue)
+		r, g, b := d.RGB255()
+		test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{255, 1, 2})
+		normal, ok := DataNormal(d)
+		test.That(t, ok, test.ShouldBeTrue)
+		test.That(t, normal, test.ShouldResemble, r3.Vector{0, 0, -1})
+		d, ok = cloud2.At(7, 6, 1)
+		test.That(t, ok, test.ShouldBeTrue)
+		_, ok = DataNormal(d)
+		test.That(t, ok, test.ShouldBeFalse)
+	}
+
+	// no color or normals
+	cloud = New()
+	test.That(t, cloud.Set(NewVector(7, 6, 1), NewBasicData()), test.ShouldBeNil)
+	var buf bytes.Buffer
+	test.That(t, ToPLY(cloud, &buf, PLYAscii), test.ShouldBeNil)
+	test.That(t, buf.String(), test.ShouldNotContainSubstring, "red")
+	cloud2, err := ReadPLY(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, CloudContains(cloud2, 7, 6, 1), test.ShouldBeTrue)
+	test.That(t, cloud2.MetaData().HasColor, test.ShouldBeFalse)
+}
+
+func TestReadPLY(t *testing.T) {
+	// a mesh, whose faces are skipped, with double positions, an alpha channel and intensities
+	ascii := "ply\n" +
+		"format ascii 1.0\n" +
+		"comment made by hand\n" +
+		"element vertex 3\n" +
+		"property double x\n" +
+		"property double y\n" +
+		"property double z\n" +
+		"property uchar red\n" +
+		"property uchar green\n" +
+		"property uchar blue\n" +
+		"property uchar alpha\n" +
+		"property ushort intensity\n" +
+		"element face 1\n" +
+		"property list uchar int vertex_indices\n" +
+		"end_header\n" +
+		"0 0 0 10 20 30 255 7\n" +
+		"1 0 0 10 20 30 255 7\n" +
+		"0 1 0 10 20 30 255 7\n" +
+		"3 0 1 2\n"
+	cloud, err := ReadPLY(strings.NewReader(ascii))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud.Size(), test.ShouldEqual, 3)
+	d, ok := cloud.At(1000, 0, 0)
+	test.That(t, ok, test.ShouldBeTrue)
+	r, g, b := d.RGB255()
+	test.That(t, []uint8{r, g, b}, test.ShouldResemble, []uint8{10, 20, 30})
+	test.That(t, d.Intensity(), test.ShouldEqual, 7)
+
+	// big endian, with the faces first
+	var buf bytes.Buffer
+	buf.WriteString("ply\nformat binary_big_endian 1.0\n" +
+		"element face 1\nproperty list uchar int vertex_indices\n" +
+		"element vertex 2\nproperty float x\nproperty float y\nproperty float z\nproperty short value\n" +
+		"end_header\n")
+	buf.Write([]byte{3, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 2})
+	for _, vertex := range [][3]float32{{0.5, 0, 0}, {0, 0.25, 0}} {
+		for _, v := range vertex {
+			test.That(t, binary.Write(&buf, binary.BigEndian, math.Float32bits(v)), test.ShouldBeNil)
+		}
+		test.That(t, binary.Write(&buf, binary.BigEndian, int16(-1)), test.ShouldBeNil)
+	}
+	cloud, err = ReadPLY(&buf)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cloud.Size(), test.ShouldEqual, 2)
+	test.That(t, CloudContains(cloud, 500, 0, 0), test.ShouldBeTrue)
+	test.That(t, CloudContains(cloud, 0, 250, 0), test.ShouldBeTrue)
+
+	for _, bad := range []string{
+		"",
+		"pcd\n",
+		"ply\nformat ascii 1.0\n",
+		"ply\nformat binary 1.0\nend_header\n",
+		"ply\nelement vertex 1\nproperty float x\nend_header\n",
+		"ply\nformat ascii 1.0\nproperty float x\nend_header\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty quad x\nend_header\n",
+		"ply\nformat ascii 1.0\nelement face 0\nend_header\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nend_header\n1 2\n",
+		"ply\nformat ascii 1.0\nelement vertex 2\nproperty float x\nproperty float y\nproperty float z\nend_header\n1 2 3\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nend_header\n1 2 a\n",
+		// more vertices than could ever be read, which must not be preallocated
+		"ply\nformat ascii 1.0\nelement vertex 9000000000000000\nproperty float x\nproperty float y\nproperty float z\nend_header\n1 2 3\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty list uchar int i\nproperty float x\nproperty float y\n" +
+			"property float z\nend_header\n-3 1 2 3\n",
+	} {
+		_, err := ReadPLY(strings.NewReader(bad))
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
@@ -234,6 +234,8 @@ func NewStaticFrameFromFrame(frame Frame, pose spatial.Pose) (Frame, error) {
 		return NewStaticFrameWithGeometry(frame.Name(), pose, f.geometry)
 	case *mobile2DFrame:
 		return NewStaticFrameWithGeometry(frame.Name(), pose, f.geometry)
+	case *mimicFrame:
+		return NewStaticFrameFromFrame(f.Frame, pose)
 	default:
 		return NewStaticFrame(frame.Name(), pose)
 	}
@@ -32,9 +32,21 @@ type JointConfig struct {
 	Axis     spatial.AxisConfig      `json:"axis"`
 	Max      float64                 `json:"max"`                // in mm or degs
 	Min      float64                 `json:"min"`                // in mm or degs
+	Velocity float64                 `json:"velocity,omitempty"` // in mm/s or degs/s
+	Effort   float64                 `json:"effort,omitempty"`   // in N or Nm
+	Mimic    *MimicConfig            `json:"mimic,omitempty"`
 	Geometry *spatial.GeometryConfig `json:"geometry,omitempty"` // only valid for prismatic/translational joints
 }
 
+// MimicConfig makes a joint follow another joint of the same model, e.g. the second finger of a parallel gripper,
+// rather than take an input of its own. Its position is Multiplier times the position of the other joint plus Offset,
+// where positions are in mm or degs.
+type MimicConfig struct {
+	Joint      string  `json:"joint"`
+	Multiplier float64 `json:"multiplier"`
+	Offset     float64 `json:"offset,omitempty"`
+}
+
 // DHParamConfig is a revolute and static frame combined in a set of Denavit Hartenberg parameters.
 type DHParamConfig struct {
 	ID       string                  `json:"id"`
@@ -107,8 +119,15 @@ func (cfg *JointConfig) ToFrame() (Frame, error) {
 		return NewRotationalFrame(cfg.ID, cfg.Axis.ParseConfig(),
 			Limit{Min: utils.DegToRad(cfg.Min), Max: utils.DegToRad(cfg.Max)})
 	case PrismaticJoint:
-		return NewTranslationalFrame(cfg.ID, r3.Vector(cfg.Axis),
-			Limit{Min: cfg.Min, Max: cfg.Max})
+		limit := Limit{Min: cfg.Min, Max: cfg.Max}
+		if cfg.Geometry != nil {
+			geometry, err := cfg.Geometry.ParseConfig()
+			if err != nil {
+				return nil, err
+			}
+			return NewTranslationalFrameWithGeometry(cfg.ID, r3.Vector(cfg.Axis), limit, geometry)
+		}
+		return NewTranslationalFrame(cfg.ID, r3.Vector(cfg.Axis), limit)
 	default:
 		return nil, NewUnsupportedJointTypeError(cfg.Type)
 	}
@@ -0,0 +1,48 @@
+package referenceframe
+
+import (
+	"gonum.org/v1/gonum/mat"
+
+	spatial "go.viam.com/rdk/spatialmath"
+)
+
+// jacobianStep is the change of each input, in radians or mm, used to compute a Jacobian by central differences.
+const jacobianStep = 1e-5
+
+// ComputeJacobian returns the 6xN Jacobian of a frame at the given inputs, where N is the number of inputs of the frame.
+// Column i is the velocity of the frame, relative to and expressed in its parent, when input i moves at unit speed
+// while the others are held still. The first three rows are the linear velocity, in mm per unit of input, and the
+// last three rows are the angular velocity, in radians per unit of input.
+//
+// The Jacobian is computed from central differences of Transform, so it works for any frame whatever the way its
+// kinematics are defined, e.g. SVA, DH or URDF.
+func ComputeJacobian(f Frame, inputs []Input) (*mat.Dense, error) {
+	dof := len(f.DoF())
+	if len(inputs) != dof {
+		return nil, NewIncorrectInputLengthError(len(inputs), dof)
+	}
+	jac := mat.NewDense(6, dof, nil)
+	perturbed := make([]Input, dof)
+	for i := 0; i < dof; i++ {
+		copy(perturbed, inputs)
+		// inputs just beyond the limits of the frame still give a pose, which is all that is needed here
+		perturbed[i] = Input{inputs[i].Value - jacobianStep}
+		before, err := f.Transform(perturbed)
+		if before == nil {
+			return nil, err
+		}
+		perturbed[i] = Input{inputs[i].Value + jacobianStep}
+		after, err := f.Transform(perturbed)
+		if after == nil {
+			return nil, err
+		}
+
+		linear := after.Point().Sub(before.Point()).Mul(1 / (2 * jacobianStep))
+		rotation := spatial.OrientationBetween(before.Orientation(), after.Orientation()).Quaternion()
+		angular := spatial.QuatToR3AA(rotation).Mul(1 / (2 * jacobianStep))
+		for row, v := range []float64{linear.X, linear.Y, linear.Z, angular.X, angular.Y, angular.Z} {
+			jac.Set(row, i, v)
+		}
+	}
+	return jac, nil
+}
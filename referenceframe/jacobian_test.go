@@ -0,0 +1,58 @@
+package referenceframe
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	spatial "go.viam.com/rdk/spatialmath"
+)
+
+func TestComputeJacobian(t *testing.T) {
+	// a planar arm with two links of 300 and 200mm, both turning about Z
+	l1, l2 := 300., 200.
+	joint1, err := NewRotationalFrame("", spatial.R4AA{RZ: 1}, Limit{Min: -math.Pi, Max: math.Pi})
+	test.That(t, err, test.ShouldBeNil)
+	link1, err := NewStaticFrame("", spatial.NewPoseFromPoint(r3.Vector{X: l1}))
+	test.That(t, err, test.ShouldBeNil)
+	joint2, err := NewRotationalFrame("", spatial.R4AA{RZ: 1}, Limit{Min: -math.Pi, Max: math.Pi})
+	test.That(t, err, test.ShouldBeNil)
+	link2, err := NewStaticFrame("", spatial.NewPoseFromPoint(r3.Vector{X: l2}))
+	test.That(t, err, test.ShouldBeNil)
+	m := &SimpleModel{baseFrame: &baseFrame{name: "planar"}, OrdTransforms: []Frame{joint1, link1, joint2, link2}}
+
+	q1, q2 := 0.3, 0.7
+	jac, err := ComputeJacobian(m, FloatsToInputs([]float64{q1, q2}))
+	test.That(t, err, test.ShouldBeNil)
+	rows, cols := jac.Dims()
+	test.That(t, rows, test.ShouldEqual, 6)
+	test.That(t, cols, test.ShouldEqual, 2)
+
+	expected := [][]float64{
+		{-l1*math.Sin(q1) - l2*math.Sin(q1+q2), -l2 * math.Sin(q1+q2)},
+		{l1*math.Cos(q1) + l2*math.Cos(q1+q2), l2 * math.Cos(q1+q2)},
+		{0, 0},
+		{0, 0},
+		{0, 0},
+		{1, 1},
+	}
+	for i, row := range expected {
+		for j, v := range row {
+			test.That(t, jac.At(i, j), test.ShouldAlmostEqual, v, 1e-4)
+		}
+	}
+
+	// a joint about a tilted axis turns the frame about that axis
+	tilted, err := NewRotationalFrame("", spatial.R4AA{RX: 1, RZ: 1}, Limit{Min: -math.Pi, Max: math.Pi})
+	test.That(t, err, test.ShouldBeNil)
+	jac, err = ComputeJacobian(tilted, []Input{{2}})
+	test.That(t, err, test.ShouldBeNil)
+	for i, v := range []float64{0, 0, 0, math.Sqrt2 / 2, 0, math.Sqrt2 / 2} {
+		test.That(t, jac.At(i, 0), test.ShouldAlmostEqual, v, 1e-4)
+	}
+
+	_, err = ComputeJacobian(m, []Input{{0}})
+	test.That(t, err, test.ShouldBeError, NewIncorrectInputLengthError(1, 2))
+}
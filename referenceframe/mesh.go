@@ -0,0 +1,138 @@
+package referenceframe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+// resolveMeshPath returns the path of a mesh referenced by a URDF file in dir. Meshes of ROS packages, like
+// "package://ur_description/meshes/base.stl", are looked up in dir, its parent directory and a directory named after
+// the package, since the package itself cannot be located without ROS.
+func resolveMeshPath(filename, dir string) (string, error) {
+	filename = strings.TrimPrefix(filename, "file://")
+	if rest := strings.TrimPrefix(filename, "package://"); rest != filename {
+		pkg, path, ok := strings.Cut(rest, "/")
+		if !ok {
+			return "", errors.Errorf("invalid mesh filename %q", filename)
+		}
+		candidates := []string{
+			filepath.Join(dir, path),
+			filepath.Join(dir, "..", path),
+			filepath.Join(dir, pkg, path),
+		}
+		for _, candidate := range candidates {
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate, nil
+			}
+		}
+		return "", errors.Errorf("cannot find mesh %q", filename)
+	}
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(dir, filename)
+	}
+	return filename, nil
+}
+
+// meshBounds returns the corners of the axis aligned bounding box of the vertices of a mesh, in the units of the mesh.
+// STL, in its ASCII and binary forms, and OBJ meshes are supported.
+func meshBounds(filename string) (r3.Vector, r3.Vector, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return r3.Vector{}, r3.Vector{}, errors.Wrap(err, "failed to read mesh")
+	}
+
+	var vertices []r3.Vector
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".stl":
+		vertices, err = stlVertices(data)
+	case ".obj":
+		vertices, err = objVertices(data)
+	default:
+		return r3.Vector{}, r3.Vector{}, errors.Errorf("unsupported mesh format %q", filepath.Ext(filename))
+	}
+	if err != nil {
+		return r3.Vector{}, r3.Vector{}, errors.Wrapf(err, "invalid mesh %q", filename)
+	}
+	if len(vertices) == 0 {
+		return r3.Vector{}, r3.Vector{}, errors.Errorf("mesh %q has no vertices", filename)
+	}
+
+	lo, hi := vertices[0], vertices[0]
+	for _, v := range vertices[1:] {
+		lo, hi = minVector(lo, v), maxVector(hi, v)
+	}
+	return lo, hi, nil
+}
+
+// stlVertices reads the vertices of an STL mesh.
+func stlVertices(data []byte) ([]r3.Vector, error) {
+	// binary files have an 80 byte header, which may also start with "solid", followed by the number of triangles
+	// and 50 bytes per triangle
+	if len(data) >= 84 {
+		count := binary.LittleEndian.Uint32(data[80:84])
+		if uint64(len(data)) == 84+50*uint64(count) {
+			vertices := make([]r3.Vector, 0, 3*count)
+			for i := 0; i < int(count); i++ {
+				// each triangle has a normal, three vertices and two bytes of attributes
+				triangle := data[84+50*i:]
+				for j := 1; j <= 3; j++ {
+					vertices = append(vertices, r3.Vector{
+						X: float64(math.Float32frombits(binary.LittleEndian.Uint32(triangle[12*j:]))),
+						Y: float64(math.Float32frombits(binary.LittleEndian.Uint32(triangle[12*j+4:]))),
+						Z: float64(math.Float32frombits(binary.LittleEndian.Uint32(triangle[12*j+8:]))),
+					})
+				}
+			}
+			return vertices, nil
+		}
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("solid")) {
+		return nil, errors.New("not an ASCII or binary STL file")
+	}
+	return readVertexLines(data, "vertex")
+}
+
+// objVertices reads the vertices of an OBJ mesh.
+func objVertices(data []byte) ([]r3.Vector, error) {
+	return readVertexLines(data, "v")
+}
+
+// readVertexLines reads the vertices of text meshes, given by lines starting with the keyword followed by x, y and z.
+func readVertexLines(data []byte, keyword string) ([]r3.Vector, error) {
+	var vertices []r3.Vector
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] != keyword {
+			continue
+		}
+		var xyz [3]float64
+		for i := range xyz {
+			v, err := strconv.ParseFloat(fields[i+1], 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid vertex %q", scanner.Text())
+			}
+			xyz[i] = v
+		}
+		vertices = append(vertices, r3.Vector{X: xyz[0], Y: xyz[1], Z: xyz[2]})
+	}
+	return vertices, scanner.Err()
+}
+
+func minVector(a, b r3.Vector) r3.Vector {
+	return r3.Vector{X: math.Min(a.X, b.X), Y: math.Min(a.Y, b.Y), Z: math.Min(a.Z, b.Z)}
+}
+
+func maxVector(a, b r3.Vector) r3.Vector {
+	return r3.Vector{X: math.Max(a.X, b.X), Y: math.Max(a.Y, b.Y), Z: math.Max(a.Z, b.Z)}
+}
@@ -0,0 +1,86 @@
+package referenceframe
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	pb "go.viam.com/api/component/arm/v1"
+
+	spatial "go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+)
+
+// mimicFrame is a joint which follows another joint of its model rather than taking an input, see MimicConfig.
+// It has no degrees of freedom of its own, so only the model holding both joints can place it.
+type mimicFrame struct {
+	Frame
+	config MimicConfig
+
+	// multiplier and offset are in the input units of the joints, radians or mm.
+	multiplier float64
+	offset     float64
+}
+
+// newMimicFrame wraps a joint frame so that it follows the joint described by source.
+func newMimicFrame(joint Frame, cfg *JointConfig, source *JointConfig) (*mimicFrame, error) {
+	if source.Mimic != nil {
+		return nil, errors.Errorf("joint %q cannot mimic joint %q which is a mimic joint itself", cfg.ID, source.ID)
+	}
+	// converts positions in the units of a config, degs or mm, to the units of inputs, radians or mm
+	toInput := func(jointType string) float64 {
+		if jointType == RevoluteJoint {
+			return utils.DegToRad(1)
+		}
+		return 1
+	}
+	return &mimicFrame{
+		Frame:      joint,
+		config:     *cfg.Mimic,
+		multiplier: cfg.Mimic.Multiplier * toInput(cfg.Type) / toInput(source.Type),
+		offset:     cfg.Mimic.Offset * toInput(cfg.Type),
+	}, nil
+}
+
+// DoF returns no degrees of freedom, as the position of the joint follows another joint.
+func (mf *mimicFrame) DoF() []Limit {
+	return []Limit{}
+}
+
+// Transform fails, as the pose of a mimic joint depends on the input of another joint.
+func (mf *mimicFrame) Transform(inputs []Input) (spatial.Pose, error) {
+	return nil, errors.Errorf("joint %q mimics joint %q and can only be moved by its model", mf.Name(), mf.config.Joint)
+}
+
+// follow returns the pose of the joint when the joint it mimics is at the given input.
+func (mf *mimicFrame) follow(source Input) (spatial.Pose, error) {
+	return mf.Frame.Transform([]Input{{mf.multiplier*source.Value + mf.offset}})
+}
+
+// InputFromProtobuf returns no inputs, as a mimic joint takes none.
+func (mf *mimicFrame) InputFromProtobuf(jp *pb.JointPositions) []Input {
+	return []Input{}
+}
+
+// ProtobufFromInput returns no positions, as a mimic joint takes no inputs.
+func (mf *mimicFrame) ProtobufFromInput(input []Input) *pb.JointPositions {
+	return &pb.JointPositions{}
+}
+
+func (mf *mimicFrame) MarshalJSON() ([]byte, error) {
+	data, err := mf.Frame.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var cfg JointConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	mimic := mf.config
+	cfg.Mimic = &mimic
+	return json.Marshal(cfg)
+}
+
+func (mf *mimicFrame) AlmostEquals(otherFrame Frame) bool {
+	other, ok := otherFrame.(*mimicFrame)
+	return ok && mf.config == other.config && mf.Frame.AlmostEquals(other.Frame)
+}
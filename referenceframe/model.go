@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"math"
 	"math/rand"
+	"sort"
 	"strings"
 	"sync"
 
@@ -13,6 +14,7 @@ import (
 	pb "go.viam.com/api/component/arm/v1"
 
 	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
 )
 
 // errUnsupportedFileType is returned if we try to build a model from an inproper extension.
@@ -35,9 +37,13 @@ type SimpleModel struct {
 	*baseFrame
 	// OrdTransforms is the list of transforms ordered from end effector to base
 	OrdTransforms []Frame
-	modelConfig   *ModelConfig
-	poseCache     sync.Map
-	lock          sync.RWMutex
+	// sideChains are the branches off OrdTransforms which move with mimic joints, e.g. the second finger of a gripper.
+	// They only contribute geometries.
+	sideChains  []sideChain
+	mimics      bool
+	modelConfig *ModelConfig
+	poseCache   sync.Map
+	lock        sync.RWMutex
 }
 
 // NewSimpleModel constructs a new model.
@@ -173,6 +179,38 @@ func (m *SimpleModel) DoF() []Limit {
 	return limits
 }
 
+// JointDynamics are the maximum speed and effort of a joint, which the config of a model may give. They are zero
+// when unknown.
+type JointDynamics struct {
+	Velocity float64 // in radians or mm per second
+	Effort   float64 // in Nm or N
+}
+
+// JointDynamics returns the dynamics of each degree of freedom of the model, in the same order as DoF.
+func (m *SimpleModel) JointDynamics() []JointDynamics {
+	joints := map[string]JointConfig{}
+	if m.modelConfig != nil {
+		for _, joint := range m.modelConfig.Joints {
+			joints[joint.ID] = joint
+		}
+	}
+	dynamics := make([]JointDynamics, 0, len(m.OrdTransforms))
+	for _, transform := range m.OrdTransforms {
+		joint, ok := joints[transform.Name()]
+		for range transform.DoF() {
+			switch {
+			case !ok:
+				dynamics = append(dynamics, JointDynamics{})
+			case joint.Type == RevoluteJoint:
+				dynamics = append(dynamics, JointDynamics{Velocity: utils.DegToRad(joint.Velocity), Effort: joint.Effort})
+			default:
+				dynamics = append(dynamics, JointDynamics{Velocity: joint.Velocity, Effort: joint.Effort})
+			}
+		}
+	}
+	return dynamics
+}
+
 // MarshalJSON serializes a Model.
 func (m *SimpleModel) MarshalJSON() ([]byte, error) {
 	return json.Marshal(m.modelConfig)
@@ -212,8 +250,17 @@ func (m *SimpleModel) inputsToFrames(inputs []Input, collectAll bool) ([]*static
 	}
 	var err error
 	poses := make([]*staticFrame, 0, len(m.OrdTransforms))
+	var sources map[string]Input
+	if m.mimics {
+		sources = m.jointInputs(inputs)
+	}
 	// Start at ((1+0i+0j+0k)+(+0+0i+0j+0k)ϵ)
 	composedTransformation := spatialmath.NewZeroPose()
+	// the pose of each frame, where side chains start from
+	var chainPoses map[string]spatialmath.Pose
+	if collectAll && len(m.sideChains) > 0 {
+		chainPoses = map[string]spatialmath.Pose{World: composedTransformation}
+	}
 	posIdx := 0
 	// get quaternions from the base outwards.
 	for _, transform := range m.OrdTransforms {
@@ -221,7 +268,7 @@ func (m *SimpleModel) inputsToFrames(inputs []Input, collectAll bool) ([]*static
 		input := inputs[posIdx:dof]
 		posIdx = dof
 
-		pose, errNew := transform.Transform(input)
+		pose, errNew := frameTransform(transform, input, sources)
 		// Fail if inputs are incorrect and pose is nil, but allow querying out-of-bounds positions
 		if pose == nil || (err != nil && !strings.Contains(err.Error(), OOBErrString)) {
 			return nil, err
@@ -235,12 +282,83 @@ func (m *SimpleModel) inputsToFrames(inputs []Input, collectAll bool) ([]*static
 			poses = append(poses, tf.(*staticFrame))
 		}
 		composedTransformation = spatialmath.Compose(composedTransformation, pose)
+		if chainPoses != nil {
+			chainPoses[transform.Name()] = composedTransformation
+		}
+	}
+	for _, chain := range m.sideChains {
+		if chainPoses == nil {
+			break
+		}
+		chainTransformation := chainPoses[chain.parent]
+		for _, transform := range chain.frames {
+			pose, errNew := frameTransform(transform, []Input{}, sources)
+			if pose == nil {
+				return nil, errNew
+			}
+			multierr.AppendInto(&err, errNew)
+			tf, err := NewStaticFrameFromFrame(transform, chainTransformation)
+			if err != nil {
+				return nil, err
+			}
+			poses = append(poses, tf.(*staticFrame))
+			chainTransformation = spatialmath.Compose(chainTransformation, pose)
+			chainPoses[transform.Name()] = chainTransformation
+		}
 	}
 	// TODO(rb) as written this will return one too many frames, no need to return zeroth frame
 	poses = append(poses, &staticFrame{&baseFrame{"", []Limit{}}, composedTransformation, nil})
 	return poses, err
 }
 
+// frameTransform returns the pose of a frame of a model, where sources holds the inputs of the joints that mimic
+// joints follow.
+func frameTransform(f Frame, input []Input, sources map[string]Input) (spatialmath.Pose, error) {
+	if mimic, ok := f.(*mimicFrame); ok {
+		return mimic.follow(sources[mimic.config.Joint])
+	}
+	return f.Transform(input)
+}
+
+// jointInputs returns the input of each joint of the model by name.
+func (m *SimpleModel) jointInputs(inputs []Input) map[string]Input {
+	named := map[string]Input{}
+	posIdx := 0
+	for _, transform := range m.OrdTransforms {
+		dof := len(transform.DoF())
+		if dof == 1 {
+			named[transform.Name()] = inputs[posIdx]
+		}
+		posIdx += dof
+	}
+	return named
+}
+
+// validateMimicJoints checks that every mimic joint of the model follows a joint which takes an input.
+func (m *SimpleModel) validateMimicJoints() error {
+	joints := map[string]bool{}
+	for _, transform := range m.OrdTransforms {
+		if len(transform.DoF()) == 1 {
+			joints[transform.Name()] = true
+		}
+	}
+	frames := append([]Frame{}, m.OrdTransforms...)
+	for _, chain := range m.sideChains {
+		frames = append(frames, chain.frames...)
+	}
+	for _, f := range frames {
+		mimic, ok := f.(*mimicFrame)
+		if !ok {
+			continue
+		}
+		if !joints[mimic.config.Joint] {
+			return errors.Errorf("joint %q must mimic a joint leading to the end effector, not %q", mimic.Name(), mimic.config.Joint)
+		}
+		m.mimics = true
+	}
+	return nil
+}
+
 // floatsToString turns a float array into a serializable binary representation
 // This is very fast, about 100ns per call.
 func floatsToString(inputs []Input) string {
@@ -280,6 +398,66 @@ func sortTransforms(unsorted map[string]Frame, parentMap map[string]string, star
 	return orderedTransforms, nil
 }
 
+// sideChain is a branch of a model which does not lead to its end effector, ordered from the base outwards.
+type sideChain struct {
+	parent string // the frame the branch starts from
+	frames []Frame
+}
+
+// followsMimicJoint returns whether a mimic joint is on the way from the named transform to the base of its model.
+func followsMimicJoint(name string, transforms map[string]Frame, parentMap map[string]string) bool {
+	seen := map[string]bool{}
+	for name != World && name != "" && !seen[name] {
+		seen[name] = true
+		if _, ok := transforms[name].(*mimicFrame); ok {
+			return true
+		}
+		name = parentMap[name]
+	}
+	return false
+}
+
+// sortSideChains orders the branches ending at the given leaves, each from the frame of the model it starts from
+// outwards. Branches sharing frames are split so that every frame is in a single branch.
+func sortSideChains(
+	unsorted map[string]Frame,
+	parentMap map[string]string,
+	ordTransforms []Frame,
+	leaves []string,
+) ([]sideChain, error) {
+	placed := map[string]bool{World: true}
+	for _, f := range ordTransforms {
+		placed[f.Name()] = true
+	}
+	sort.Strings(leaves)
+	chains := make([]sideChain, 0, len(leaves))
+	for _, leaf := range leaves {
+		var frames []Frame
+		seen := map[string]bool{}
+		name := leaf
+		for !placed[name] {
+			if seen[name] {
+				return nil, ErrCircularReference
+			}
+			seen[name] = true
+			f, ok := unsorted[name]
+			if !ok {
+				return nil, NewFrameMissingError(name)
+			}
+			frames = append(frames, f)
+			name = parentMap[name]
+		}
+		for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+			frames[i], frames[j] = frames[j], frames[i]
+		}
+		for _, f := range frames {
+			placed[f.Name()] = true
+		}
+		chains = append(chains, sideChain{parent: name, frames: frames})
+	}
+	return chains, nil
+}
+
 // ModelFromPath returns a Model from a given path.
 func ModelFromPath(modelPath, name string) (Model, error) {
 	var (
@@ -63,6 +63,24 @@ func (cfg *ModelConfig) ParseConfig(modelName string) (Model, error) {
 				return nil, err
 			}
 		}
+		for i, joint := range cfg.Joints {
+			if joint.Mimic == nil {
+				continue
+			}
+			var source *JointConfig
+			for j := range cfg.Joints {
+				if cfg.Joints[j].ID == joint.Mimic.Joint {
+					source = &cfg.Joints[j]
+				}
+			}
+			if source == nil {
+				return nil, errors.Errorf("joint %q mimics joint %q which does not exist", joint.ID, joint.Mimic.Joint)
+			}
+			transforms[joint.ID], err = newMimicFrame(transforms[joint.ID], &cfg.Joints[i], source)
+			if err != nil {
+				return nil, err
+			}
+		}
 
 	case "DH":
 		for _, dh := range cfg.DHParams {
@@ -96,24 +114,35 @@ func (cfg *ModelConfig) ParseConfig(modelName string) (Model, error) {
 		delete(parents, parentMap[trans.Name()])
 	}
 
-	if len(parents) > 1 {
+	// Branches which move with a mimic joint, like the fingers of a parallel gripper, hang off the chain leading to
+	// the end effector
+	var eenames, sideLeaves []string
+	for id := range parents {
+		if followsMimicJoint(id, transforms, parentMap) {
+			sideLeaves = append(sideLeaves, id)
+		} else {
+			eenames = append(eenames, id)
+		}
+	}
+	if len(eenames) > 1 {
 		return nil, errors.New("more than one end effector not supported")
 	}
-	if len(parents) < 1 {
+	if len(eenames) < 1 {
 		return nil, errors.New("need at least one end effector")
 	}
-	var eename string
-	// TODO(pl): is there a better way to do all this? Annoying to iterate over a map three times. Maybe if we
-	// implement Child() as well as Parent()?
-	for id := range parents {
-		eename = id
-	}
 
 	// Create an ordered list of transforms
-	model.OrdTransforms, err = sortTransforms(transforms, parentMap, eename, World)
+	model.OrdTransforms, err = sortTransforms(transforms, parentMap, eenames[0], World)
+	if err != nil {
+		return nil, err
+	}
+	model.sideChains, err = sortSideChains(transforms, parentMap, model.OrdTransforms, sideLeaves)
 	if err != nil {
 		return nil, err
 	}
+	if err := model.validateMimicJoints(); err != nil {
+		return nil, err
+	}
 
 	return model, nil
 }
@@ -2,8 +2,10 @@ package referenceframe
 
 import (
 	"encoding/xml"
+	"fmt"
 	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -24,60 +26,70 @@ type URDFConfig struct {
 
 // URDFLink is a struct which details the XML used in a URDF link element.
 type URDFLink struct {
-	XMLName   xml.Name `xml:"link"`
-	Name      string   `xml:"name,attr"`
-	Collision []struct {
-		XMLName xml.Name `xml:"collision"`
-		Name    string   `xml:"name,attr"`
-		Origin  struct {
-			XMLName xml.Name `xml:"origin"`
-			RPY     string   `xml:"rpy,attr"` // Fixed frame angle "r p y" format, in radians
-			XYZ     string   `xml:"xyz,attr"` // "x y z" format, in meters
-		} `xml:"origin"`
-		Geometry struct {
-			XMLName xml.Name `xml:"geometry"`
-			Box     struct {
-				XMLName xml.Name `xml:"box"`
-				Size    string   `xml:"size,attr"` // "x y z" format, in meters
-			} `xml:"box"`
-			Sphere struct {
-				XMLName xml.Name `xml:"sphere"`
-				Radius  float64  `xml:"radius,attr"` // in meters
-			} `xml:"sphere"`
-		} `xml:"geometry"`
-	} `xml:"collision"`
+	XMLName   xml.Name        `xml:"link"`
+	Name      string          `xml:"name,attr"`
+	Collision []URDFCollision `xml:"collision"`
+}
+
+// URDFCollision is a struct which details the XML used in a URDF collision element.
+type URDFCollision struct {
+	XMLName  xml.Name    `xml:"collision"`
+	Name     string      `xml:"name,attr,omitempty"`
+	Origin   *URDFOrigin `xml:"origin,omitempty"`
+	Geometry struct {
+		Box *struct {
+			Size string `xml:"size,attr"` // "x y z" format, in meters
+		} `xml:"box,omitempty"`
+		Sphere *struct {
+			Radius float64 `xml:"radius,attr"` // in meters
+		} `xml:"sphere,omitempty"`
+		Cylinder *struct {
+			Radius float64 `xml:"radius,attr"` // in meters
+			Length float64 `xml:"length,attr"` // in meters
+		} `xml:"cylinder,omitempty"`
+		Mesh *struct {
+			Filename string `xml:"filename,attr"`
+			Scale    string `xml:"scale,attr,omitempty"` // "x y z" format
+		} `xml:"mesh,omitempty"`
+	} `xml:"geometry"`
+}
+
+// URDFOrigin is a struct which details the XML used in a URDF origin element.
+type URDFOrigin struct {
+	RPY string `xml:"rpy,attr"` // Fixed frame angle "r p y" format, in radians
+	XYZ string `xml:"xyz,attr"` // "x y z" format, in meters
 }
 
 // URDFJoint is a struct which details the XML used in a URDF joint element.
 type URDFJoint struct {
-	XMLName xml.Name `xml:"joint"`
-	Name    string   `xml:"name,attr"`
-	Type    string   `xml:"type,attr"`
-	Origin  struct {
-		XMLName xml.Name `xml:"origin"`
-		RPY     string   `xml:"rpy,attr"` // Fixed frame angle "r p y" format, in radians
-		XYZ     string   `xml:"xyz,attr"` // "x y z" format, in meters
-	} `xml:"origin"`
-	Parent struct {
-		XMLName xml.Name `xml:"parent"`
-		Link    string   `xml:"link,attr"`
+	XMLName xml.Name    `xml:"joint"`
+	Name    string      `xml:"name,attr"`
+	Type    string      `xml:"type,attr"`
+	Origin  *URDFOrigin `xml:"origin,omitempty"`
+	Parent  struct {
+		Link string `xml:"link,attr"`
 	} `xml:"parent"`
 	Child struct {
-		XMLName xml.Name `xml:"child"`
-		Link    string   `xml:"link,attr"`
+		Link string `xml:"link,attr"`
 	} `xml:"child"`
-	Axis struct {
-		XMLName xml.Name `xml:"axis"`
-		XYZ     string   `xml:"xyz,attr"` // "x y z" format, in meters
-	} `xml:"axis"`
-	Limit struct {
-		XMLName xml.Name `xml:"limit"`
-		Lower   float64  `xml:"lower,attr"` // translation limits are in meters, revolute limits are in radians
-		Upper   float64  `xml:"upper,attr"` // translation limits are in meters, revolute limits are in radians
-	} `xml:"limit"`
+	Axis *struct {
+		XYZ string `xml:"xyz,attr"` // "x y z" format
+	} `xml:"axis,omitempty"`
+	Limit *struct {
+		Lower    float64 `xml:"lower,attr"`              // translation limits are in meters, revolute limits are in radians
+		Upper    float64 `xml:"upper,attr"`              // translation limits are in meters, revolute limits are in radians
+		Velocity float64 `xml:"velocity,attr,omitempty"` // in meters or radians per second
+		Effort   float64 `xml:"effort,attr,omitempty"`   // in N or Nm
+	} `xml:"limit,omitempty"`
+	Mimic *struct {
+		Joint      string   `xml:"joint,attr"`
+		Multiplier *float64 `xml:"multiplier,attr,omitempty"` // 1 if not set
+		Offset     float64  `xml:"offset,attr,omitempty"`     // in meters or radians
+	} `xml:"mimic,omitempty"`
 }
 
 // ParseURDFFile will read a given file and parse the contained URDF XML data into an equivalent ModelConfig struct.
+// Meshes are looked up relative to the directory of the file.
 func ParseURDFFile(filename, modelName string) (Model, error) {
 	//nolint:gosec
 	xmlData, err := os.ReadFile(filename)
@@ -85,7 +97,7 @@ func ParseURDFFile(filename, modelName string) (Model, error) {
 		return nil, errors.Wrap(err, "Failed to read URDF file")
 	}
 
-	mc, err := ConvertURDFToConfig(xmlData, modelName)
+	mc, err := convertURDFToConfig(xmlData, modelName, filepath.Dir(filename))
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +108,19 @@ func ParseURDFFile(filename, modelName string) (Model, error) {
 // ConvertURDFToConfig will transfer the given URDF XML data into an equivalent ModelConfig. Direct unmarshaling in the
 // same fashion as ModelJSON is not possible, as URDF data will need to be evaluated to accommodate differences
 // between the two kinematics encoding schemes.
+//
+// Each URDF link becomes a frame of the same name. The origin of a moving joint is the pose of its child link relative
+// to the joint, and a fixed joint becomes a static frame of its own, with its child link at its origin. A joint with
+// the name of its child link, as exported by MarshalURDF, is the frame of its child link instead. The first collision
+// element of a link is attached to the frame of the link when it is static, others become static frames with a
+// "_collision<i>" suffix. Cylinders, which have no equivalent, become the capsules of the same length and radius,
+// which MarshalURDF exports as cylinders, and meshes become their bounding boxes. Meshes are looked up relative to the
+// working directory.
 func ConvertURDFToConfig(xmlData []byte, modelName string) (*ModelConfig, error) {
+	return convertURDFToConfig(xmlData, modelName, "")
+}
+
+func convertURDFToConfig(xmlData []byte, modelName, meshDir string) (*ModelConfig, error) {
 	// empty data probably means that the read URDF has no actionable information
 	if len(xmlData) == 0 {
 		return nil, ErrNoModelInformation
@@ -113,97 +137,77 @@ func ConvertURDFToConfig(xmlData []byte, modelName string) (*ModelConfig, error)
 		modelName = urdf.Name
 	}
 
-	parentMap := map[string]string{}
-
 	// Migrate URDF elements into an equivalent ModelConfig representation
 	mc.Name = modelName
 	mc.KinParamType = "SVA"
 
+	jointTypes := map[string]string{}
+	for _, jointElem := range urdf.Joints {
+		jointTypes[jointElem.Name] = jointElem.Type
+	}
+	// links which have a frame of their own, as opposed to links which are the child of a joint of the same name
+	staticLinks := map[string]int{}
+	childLinks := map[string]bool{}
+
 	// Handle joints
 	for _, jointElem := range urdf.Joints {
 		// Checking for reserved names in this or adjacent elements
-		if jointElem.Name == World {
+		if jointElem.Name == World || jointElem.Child.Link == World {
 			return nil, errors.New("Joints with the name 'world' are not supported by config parsers")
 		}
+		childLinks[jointElem.Child.Link] = true
 
-		// Relationship tracking
-		parentMap[jointElem.Name] = jointElem.Parent.Link
-		parentMap[jointElem.Child.Link] = jointElem.Name
-
-		// Set up the child link mentioned in this joint; fill out the details in the link parsing section later
-
-		childLink := LinkConfig{ID: jointElem.Child.Link, Parent: jointElem.Name}
+		origin, err := jointElem.Origin.pose()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid origin for joint %q", jointElem.Name)
+		}
+		orient, err := spatial.NewOrientationConfig(origin.Orientation().AxisAngles())
+		if err != nil {
+			return nil, err
+		}
 
 		switch jointElem.Type {
 		case ContinuousJoint, RevoluteJoint, PrismaticJoint:
-			// Parse important details about each joint, including axes and limits
-			jointAxes := convStringAttrToFloats(jointElem.Axis.XYZ)
-			thisJoint := JointConfig{
-				ID:     jointElem.Name,
-				Type:   jointElem.Type,
-				Parent: jointElem.Parent.Link,
-				Axis:   spatial.AxisConfig{jointAxes[0], jointAxes[1], jointAxes[2]},
-			}
-
-			// Slightly different limits handling for continuous, revolute, and prismatic joints
-			switch jointElem.Type {
-			case ContinuousJoint:
-				thisJoint.Type = RevoluteJoint // Currently, we treate a continuous joint as a special case of a revolute joint
-				thisJoint.Min, thisJoint.Max = math.Inf(-1), math.Inf(1)
-			case PrismaticJoint:
-				thisJoint.Min, thisJoint.Max = metersToMM(jointElem.Limit.Lower), metersToMM(jointElem.Limit.Upper)
-			case RevoluteJoint:
-				thisJoint.Min, thisJoint.Max = utils.RadToDeg(jointElem.Limit.Lower), utils.RadToDeg(jointElem.Limit.Upper)
-			default:
+			thisJoint, err := jointElem.toConfig(jointElem.Parent.Link, jointTypes)
+			if err != nil {
 				return nil, err
 			}
-
 			mc.Joints = append(mc.Joints, thisJoint)
 
 			// Generate child link translation and orientation data, which is held by this joint per the URDF design
-			childXYZ := convStringAttrToFloats(jointElem.Origin.XYZ)
-			childRPY := convStringAttrToFloats(jointElem.Origin.RPY)
-			childEA := spatial.EulerAngles{Roll: childRPY[0], Pitch: childRPY[1], Yaw: childRPY[2]}
-			childOrient, err := spatial.NewOrientationConfig(childEA.AxisAngles())
-
-			// Note the conversion from meters to mm
-			childLink.Translation = r3.Vector{
-				metersToMM(childXYZ[0]),
-				metersToMM(childXYZ[1]),
-				metersToMM(childXYZ[2]),
-			}
-			childLink.Orientation = childOrient
-
-			if err != nil {
-				return nil, err
+			if jointElem.Child.Link != jointElem.Name {
+				staticLinks[jointElem.Child.Link] = len(mc.Links)
+				mc.Links = append(mc.Links, LinkConfig{
+					ID:          jointElem.Child.Link,
+					Parent:      jointElem.Name,
+					Translation: origin.Point(),
+					Orientation: orient,
+				})
+			} else if !spatial.PoseAlmostEqualEps(origin, spatial.NewZeroPose(), 1e-10) {
+				return nil, errors.Errorf("joint %q has the name of its child link, so it cannot have an origin", jointElem.Name)
 			}
 		case FixedJoint:
 			// Handle fixed joint -> static link conversion instead of adding to Joints[]
-			thisLink := LinkConfig{ID: jointElem.Name, Parent: jointElem.Parent.Link}
-
-			linkXYZ := convStringAttrToFloats(jointElem.Origin.XYZ)
-			linkRPY := convStringAttrToFloats(jointElem.Origin.RPY)
-			linkEA := spatial.EulerAngles{Roll: linkRPY[0], Pitch: linkRPY[1], Yaw: linkRPY[2]}
-			linkOrient, err := spatial.NewOrientationConfig(linkEA.AxisAngles())
-
-			// Note the conversion from meters to mm
-			thisLink.Translation = r3.Vector{
-				metersToMM(linkXYZ[0]),
-				metersToMM(linkXYZ[1]),
-				metersToMM(linkXYZ[2]),
+			parent := jointElem.Parent.Link
+			if jointElem.Child.Link != jointElem.Name {
+				mc.Links = append(mc.Links, LinkConfig{
+					ID:          jointElem.Name,
+					Parent:      parent,
+					Translation: origin.Point(),
+					Orientation: orient,
+				})
+				parent, origin, orient = jointElem.Name, spatial.NewZeroPose(), &spatial.OrientationConfig{}
 			}
-			thisLink.Orientation = linkOrient
-
-			if err != nil {
-				return nil, err
-			}
-
-			mc.Links = append(mc.Links, thisLink)
+			staticLinks[jointElem.Child.Link] = len(mc.Links)
+			mc.Links = append(mc.Links, LinkConfig{
+				ID:          jointElem.Child.Link,
+				Parent:      parent,
+				Translation: origin.Point(),
+				Orientation: orient,
+			})
 		default:
 			return nil, NewUnsupportedJointTypeError(jointElem.Type)
 		}
-
-		mc.Links = append(mc.Links, childLink)
 	}
 
 	// Handle links
@@ -213,40 +217,438 @@ func ConvertURDFToConfig(xmlData []byte, modelName string) (*ModelConfig, error)
 			continue
 		}
 
-		// Find matching links which already exist, take care of geometry if collision elements are detected
-		hasCollision := len(linkElem.Collision) > 0
-		for idx, prefabLink := range mc.Links {
-			if prefabLink.ID == linkElem.Name && hasCollision {
-				geoCfg, err := createConfigFromCollision(linkElem)
-				if err != nil {
-					return nil, err
-				}
-				mc.Links[idx].Geometry = &geoCfg
-				break
+		// Links which are not the child of any joint hang off the world
+		if !childLinks[linkElem.Name] {
+			staticLinks[linkElem.Name] = len(mc.Links)
+			mc.Links = append(mc.Links, LinkConfig{ID: linkElem.Name, Parent: World, Orientation: &spatial.OrientationConfig{}})
+		}
+
+		geometries := make([]spatial.Geometry, 0, len(linkElem.Collision))
+		for _, collision := range linkElem.Collision {
+			geometry, err := collision.toGeometry(meshDir)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid collision geometry for link %q", linkElem.Name)
 			}
+			geometries = append(geometries, geometry)
+		}
+		if len(geometries) == 0 {
+			continue
 		}
 
-		// In the event the link does not already exist in the ModelConfig, we will have to generate it now
-		// Most likely, this is a link normally whose parent is the World
-		if _, ok := parentMap[linkElem.Name]; !ok {
-			thisLink := LinkConfig{ID: linkElem.Name, Parent: World}
-			thisLink.Translation = r3.Vector{0.0, 0.0, 0.0}
-			thisLink.Orientation = &spatial.OrientationConfig{} // Orientation is guaranteed to be zero for this
-
-			if hasCollision {
-				geoCfg, err := createConfigFromCollision(linkElem)
-				if err != nil {
-					return nil, err
-				}
-				thisLink.Geometry = &geoCfg
+		// The geometry of a static frame is placed before the pose of the frame, so it has to be moved by that pose
+		if idx, ok := staticLinks[linkElem.Name]; ok {
+			pose, err := mc.Links[idx].Pose()
+			if err != nil {
+				return nil, err
 			}
+			geometry := geometries[0].Transform(pose)
+			geometry.SetLabel(geometries[0].Label())
+			if mc.Links[idx].Geometry, err = spatial.NewGeometryConfig(geometry); err != nil {
+				return nil, err
+			}
+			geometries = geometries[1:]
+		}
 
-			mc.Links = append(mc.Links, thisLink)
+		// Other geometries get frames of their own, inserted between the link and its children
+		parent := linkElem.Name
+		var inserted []LinkConfig
+		for i, geometry := range geometries {
+			geoCfg, err := spatial.NewGeometryConfig(geometry)
+			if err != nil {
+				return nil, err
+			}
+			id := fmt.Sprintf("%s_collision%d", linkElem.Name, i)
+			inserted = append(inserted, LinkConfig{ID: id, Parent: parent, Orientation: &spatial.OrientationConfig{}, Geometry: geoCfg})
+			parent = id
+		}
+		if parent != linkElem.Name {
+			for i := range mc.Links {
+				if mc.Links[i].Parent == linkElem.Name {
+					mc.Links[i].Parent = parent
+				}
+			}
+			for i := range mc.Joints {
+				if mc.Joints[i].Parent == linkElem.Name {
+					mc.Joints[i].Parent = parent
+				}
+			}
+			mc.Links = append(mc.Links, inserted...)
 		}
 	}
 	return mc, nil
 }
 
+// toConfig converts a moving URDF joint into a JointConfig with the given parent.
+func (jointElem *URDFJoint) toConfig(parent string, jointTypes map[string]string) (JointConfig, error) {
+	// Parse important details about each joint, including axes and limits
+	axis := r3.Vector{X: 1} // the default axis of URDF joints
+	if jointElem.Axis != nil {
+		jointAxes := convStringAttrToFloats(jointElem.Axis.XYZ)
+		if len(jointAxes) != 3 {
+			return JointConfig{}, errors.Errorf("invalid axis %q for joint %q", jointElem.Axis.XYZ, jointElem.Name)
+		}
+		axis = r3.Vector{X: jointAxes[0], Y: jointAxes[1], Z: jointAxes[2]}
+	}
+	thisJoint := JointConfig{
+		ID:     jointElem.Name,
+		Type:   jointElem.Type,
+		Parent: parent,
+		Axis:   spatial.AxisConfig(axis),
+	}
+	if jointElem.Limit != nil {
+		thisJoint.Min = jointElem.Limit.Lower
+		thisJoint.Max = jointElem.Limit.Upper
+		thisJoint.Velocity = jointElem.Limit.Velocity
+		thisJoint.Effort = jointElem.Limit.Effort
+	}
+
+	// Slightly different limits handling for continuous, revolute, and prismatic joints
+	if jointElem.Type == ContinuousJoint {
+		thisJoint.Type = RevoluteJoint // Currently, we treate a continuous joint as a special case of a revolute joint
+		thisJoint.Min, thisJoint.Max = math.Inf(-1), math.Inf(1)
+	}
+	scale := urdfToConfigScale(thisJoint.Type)
+	thisJoint.Min *= scale
+	thisJoint.Max *= scale
+	thisJoint.Velocity *= scale
+
+	if jointElem.Mimic != nil {
+		sourceType, ok := jointTypes[jointElem.Mimic.Joint]
+		if !ok {
+			return JointConfig{}, errors.Errorf("joint %q mimics joint %q which does not exist", jointElem.Name, jointElem.Mimic.Joint)
+		}
+		multiplier := 1.
+		if jointElem.Mimic.Multiplier != nil {
+			multiplier = *jointElem.Mimic.Multiplier
+		}
+		thisJoint.Mimic = &MimicConfig{
+			Joint:      jointElem.Mimic.Joint,
+			Multiplier: multiplier * scale / urdfToConfigScale(sourceType),
+			Offset:     jointElem.Mimic.Offset * scale,
+		}
+	}
+	return thisJoint, nil
+}
+
+// urdfToConfigScale returns the factor from URDF units, meters or radians, to ModelConfig units, mm or degs.
+func urdfToConfigScale(jointType string) float64 {
+	if jointType == PrismaticJoint {
+		return metersToMM(1)
+	}
+	return utils.RadToDeg(1)
+}
+
+// pose returns the pose described by a URDF origin element, in mm. A missing origin is the identity.
+func (o *URDFOrigin) pose() (spatial.Pose, error) {
+	if o == nil {
+		return spatial.NewZeroPose(), nil
+	}
+	xyz, rpy := []float64{0, 0, 0}, []float64{0, 0, 0}
+	if o.XYZ != "" {
+		xyz = convStringAttrToFloats(o.XYZ)
+	}
+	if o.RPY != "" {
+		rpy = convStringAttrToFloats(o.RPY)
+	}
+	if len(xyz) != 3 || len(rpy) != 3 {
+		return nil, errors.Errorf("invalid origin xyz=%q rpy=%q", o.XYZ, o.RPY)
+	}
+	// Note the conversion from meters to mm
+	return spatial.NewPose(
+		r3.Vector{X: metersToMM(xyz[0]), Y: metersToMM(xyz[1]), Z: metersToMM(xyz[2])},
+		&spatial.EulerAngles{Roll: rpy[0], Pitch: rpy[1], Yaw: rpy[2]},
+	), nil
+}
+
+// toGeometry converts a URDF collision element into a geometry in the frame of its link.
+func (collision *URDFCollision) toGeometry(meshDir string) (spatial.Geometry, error) {
+	offset, err := collision.Origin.pose()
+	if err != nil {
+		return nil, err
+	}
+	g := collision.Geometry
+	switch {
+	case g.Box != nil:
+		boxDims := convStringAttrToFloats(g.Box.Size)
+		if len(boxDims) != 3 {
+			return nil, errors.Errorf("invalid box size %q", g.Box.Size)
+		}
+		dims := r3.Vector{X: metersToMM(boxDims[0]), Y: metersToMM(boxDims[1]), Z: metersToMM(boxDims[2])}
+		return spatial.NewBox(offset, dims, collision.Name)
+	case g.Sphere != nil:
+		return spatial.NewSphere(offset, metersToMM(g.Sphere.Radius), collision.Name)
+	case g.Cylinder != nil:
+		// the capsule of the same length is the closest supported geometry, and keeps exported capsules unchanged
+		return spatial.NewCapsule(offset, metersToMM(g.Cylinder.Radius), metersToMM(g.Cylinder.Length), collision.Name)
+	case g.Mesh != nil:
+		scale := r3.Vector{X: 1, Y: 1, Z: 1}
+		if g.Mesh.Scale != "" {
+			s := convStringAttrToFloats(g.Mesh.Scale)
+			if len(s) != 3 {
+				return nil, errors.Errorf("invalid mesh scale %q", g.Mesh.Scale)
+			}
+			scale = r3.Vector{X: s[0], Y: s[1], Z: s[2]}
+		}
+		filename, err := resolveMeshPath(g.Mesh.Filename, meshDir)
+		if err != nil {
+			return nil, err
+		}
+		lo, hi, err := meshBounds(filename)
+		if err != nil {
+			return nil, err
+		}
+		// scaling may flip the mesh, so the bounds are sorted again
+		lo, hi = r3.Vector{X: lo.X * scale.X, Y: lo.Y * scale.Y, Z: lo.Z * scale.Z}, r3.Vector{X: hi.X * scale.X, Y: hi.Y * scale.Y, Z: hi.Z * scale.Z}
+		lo, hi = minVector(lo, hi), maxVector(lo, hi)
+		center := lo.Add(hi).Mul(metersToMM(0.5))
+		dims := hi.Sub(lo).Mul(metersToMM(1))
+		return spatial.NewBox(spatial.Compose(offset, spatial.NewPoseFromPoint(center)), dims, collision.Name)
+	default:
+		return nil, errors.Errorf("Unsupported collision geometry type detected for [ %v ] collision", collision.Name)
+	}
+}
+
+// MarshalURDF converts the ModelConfig into URDF XML. Each frame of the model becomes a link of the same name,
+// attached to the link of its parent by a joint of the same name, so that converting the URDF back gives the same
+// frames. As in a ModelConfig, the geometry of a prismatic joint stays with the link it slides on.
+func (cfg *ModelConfig) MarshalURDF() ([]byte, error) {
+	sva, err := cfg.svaConfig()
+	if err != nil {
+		return nil, err
+	}
+	urdf := &URDFConfig{Name: sva.Name, Links: []URDFLink{{Name: World}}}
+	linkIdx := map[string]int{World: 0}
+	for _, link := range sva.Links {
+		linkIdx[link.ID] = len(urdf.Links)
+		urdf.Links = append(urdf.Links, URDFLink{Name: link.ID})
+	}
+	for _, joint := range sva.Joints {
+		linkIdx[joint.ID] = len(urdf.Links)
+		urdf.Links = append(urdf.Links, URDFLink{Name: joint.ID})
+	}
+	parentOf := func(parent string) (int, error) {
+		if parent == "" {
+			parent = World
+		}
+		idx, ok := linkIdx[parent]
+		if !ok {
+			return 0, NewFrameMissingError(parent)
+		}
+		return idx, nil
+	}
+
+	for _, link := range sva.Links {
+		parentIdx, err := parentOf(link.Parent)
+		if err != nil {
+			return nil, err
+		}
+		pose, err := link.Pose()
+		if err != nil {
+			return nil, err
+		}
+		joint := URDFJoint{Name: link.ID, Type: FixedJoint, Origin: newURDFOrigin(pose)}
+		joint.Parent.Link = urdf.Links[parentIdx].Name
+		joint.Child.Link = link.ID
+		urdf.Joints = append(urdf.Joints, joint)
+
+		if link.Geometry != nil {
+			// the geometry of a static frame is placed before its pose, while URDF collisions are placed after it
+			geometry, err := link.Geometry.ParseConfig()
+			if err != nil {
+				return nil, err
+			}
+			collision, err := newURDFCollision(geometry.Transform(spatial.PoseInverse(pose)), link.Geometry.Label)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot export the geometry of link %q", link.ID)
+			}
+			idx := linkIdx[link.ID]
+			urdf.Links[idx].Collision = append(urdf.Links[idx].Collision, collision)
+		}
+	}
+
+	jointTypes := map[string]string{}
+	for _, joint := range sva.Joints {
+		jointTypes[joint.ID] = joint.Type
+	}
+	for _, joint := range sva.Joints {
+		parentIdx, err := parentOf(joint.Parent)
+		if err != nil {
+			return nil, err
+		}
+		urdfJoint, err := newURDFJoint(joint, urdf.Links[parentIdx].Name, jointTypes)
+		if err != nil {
+			return nil, err
+		}
+		urdf.Joints = append(urdf.Joints, urdfJoint)
+
+		if joint.Geometry != nil && joint.Type == PrismaticJoint {
+			geometry, err := joint.Geometry.ParseConfig()
+			if err != nil {
+				return nil, err
+			}
+			collision, err := newURDFCollision(geometry, joint.Geometry.Label)
+			if err != nil {
+				return nil, errors.Wrapf(err, "cannot export the geometry of joint %q", joint.ID)
+			}
+			urdf.Links[parentIdx].Collision = append(urdf.Links[parentIdx].Collision, collision)
+		}
+	}
+
+	data, err := xml.MarshalIndent(urdf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// newURDFJoint converts a JointConfig into a URDF joint whose child link has the name of the joint.
+func newURDFJoint(joint JointConfig, parent string, jointTypes map[string]string) (URDFJoint, error) {
+	urdfJoint := URDFJoint{Name: joint.ID, Type: joint.Type}
+	urdfJoint.Parent.Link = parent
+	urdfJoint.Child.Link = joint.ID
+	urdfJoint.Axis = &struct {
+		XYZ string `xml:"xyz,attr"`
+	}{formatURDFFloats(joint.Axis.X, joint.Axis.Y, joint.Axis.Z)}
+
+	switch joint.Type {
+	case RevoluteJoint, PrismaticJoint:
+	default:
+		return URDFJoint{}, NewUnsupportedJointTypeError(joint.Type)
+	}
+	scale := 1 / urdfToConfigScale(joint.Type)
+	if joint.Type == RevoluteJoint && math.IsInf(joint.Min, -1) && math.IsInf(joint.Max, 1) {
+		urdfJoint.Type = ContinuousJoint
+	}
+	urdfJoint.Limit = &struct {
+		Lower    float64 `xml:"lower,attr"`
+		Upper    float64 `xml:"upper,attr"`
+		Velocity float64 `xml:"velocity,attr,omitempty"`
+		Effort   float64 `xml:"effort,attr,omitempty"`
+	}{
+		Velocity: joint.Velocity * scale,
+		Effort:   joint.Effort,
+	}
+	if urdfJoint.Type != ContinuousJoint {
+		urdfJoint.Limit.Lower = joint.Min * scale
+		urdfJoint.Limit.Upper = joint.Max * scale
+	}
+
+	if joint.Mimic != nil {
+		sourceType, ok := jointTypes[joint.Mimic.Joint]
+		if !ok {
+			return URDFJoint{}, errors.Errorf("joint %q mimics joint %q which does not exist", joint.ID, joint.Mimic.Joint)
+		}
+		multiplier := joint.Mimic.Multiplier * scale * urdfToConfigScale(sourceType)
+		urdfJoint.Mimic = &struct {
+			Joint      string   `xml:"joint,attr"`
+			Multiplier *float64 `xml:"multiplier,attr,omitempty"`
+			Offset     float64  `xml:"offset,attr,omitempty"`
+		}{
+			Joint:      joint.Mimic.Joint,
+			Multiplier: &multiplier,
+			Offset:     joint.Mimic.Offset * scale,
+		}
+	}
+	return urdfJoint, nil
+}
+
+// newURDFCollision converts a geometry into a URDF collision element. Capsules become cylinders of the same length.
+func newURDFCollision(geometry spatial.Geometry, name string) (URDFCollision, error) {
+	geoCfg, err := spatial.NewGeometryConfig(geometry)
+	if err != nil {
+		return URDFCollision{}, err
+	}
+	collision := URDFCollision{Name: name, Origin: newURDFOrigin(geometry.Pose())}
+	g := &collision.Geometry
+	switch geoCfg.Type {
+	case spatial.BoxType:
+		g.Box = &struct {
+			Size string `xml:"size,attr"`
+		}{formatURDFFloats(geoCfg.X/1000, geoCfg.Y/1000, geoCfg.Z/1000)}
+	case spatial.SphereType:
+		g.Sphere = &struct {
+			Radius float64 `xml:"radius,attr"`
+		}{geoCfg.R / 1000}
+	case spatial.CapsuleType:
+		g.Cylinder = &struct {
+			Radius float64 `xml:"radius,attr"`
+			Length float64 `xml:"length,attr"`
+		}{geoCfg.R / 1000, geoCfg.L / 1000}
+	default:
+		return URDFCollision{}, errors.Wrap(spatial.ErrGeometryTypeUnsupported, string(geoCfg.Type))
+	}
+	return collision, nil
+}
+
+// newURDFOrigin converts a pose in mm into a URDF origin element.
+func newURDFOrigin(pose spatial.Pose) *URDFOrigin {
+	pt := pose.Point()
+	roll, pitch, yaw := urdfRPY(pose.Orientation())
+	return &URDFOrigin{
+		XYZ: formatURDFFloats(pt.X/1000, pt.Y/1000, pt.Z/1000),
+		RPY: formatURDFFloats(roll, pitch, yaw),
+	}
+}
+
+// urdfRPY returns the fixed axis roll, pitch and yaw of an orientation, such that it is the rotation about Z by yaw
+// after the rotation about Y by pitch after the rotation about X by roll. Unlike the conversion to EulerAngles, it
+// holds when the pitch is a right angle, in which case the yaw is 0.
+func urdfRPY(o spatial.Orientation) (float64, float64, float64) {
+	// the rows of a RotationMatrix are the rotated axes
+	rm := o.RotationMatrix()
+	x, y, z := rm.Row(0), rm.Row(1), rm.Row(2)
+	pitch := math.Asin(math.Max(-1, math.Min(1, -x.Z)))
+	if math.Abs(x.Z) < 1-1e-9 {
+		return math.Atan2(y.Z, z.Z), pitch, math.Atan2(x.Y, x.X)
+	}
+	sinPitch := math.Copysign(1, -x.Z)
+	return math.Atan2(sinPitch*y.X, sinPitch*z.X), pitch, 0
+}
+
+// svaConfig returns the config itself if it is in the SVA format, or its equivalent in the SVA format if it is in
+// the DH format.
+func (cfg *ModelConfig) svaConfig() (*ModelConfig, error) {
+	switch cfg.KinParamType {
+	case "SVA", "":
+		return cfg, nil
+	case "DH":
+		sva := &ModelConfig{Name: cfg.Name, KinParamType: "SVA"}
+		for _, dh := range cfg.DHParams {
+			orient, err := spatial.NewOrientationConfig(
+				spatial.NewPoseFromDH(dh.A, dh.D, utils.DegToRad(dh.Alpha)).Orientation().AxisAngles())
+			if err != nil {
+				return nil, err
+			}
+			sva.Joints = append(sva.Joints, JointConfig{
+				ID:     dh.ID + "_j",
+				Type:   RevoluteJoint,
+				Parent: dh.Parent,
+				Axis:   spatial.AxisConfig{Z: 1},
+				Min:    dh.Min,
+				Max:    dh.Max,
+			})
+			sva.Links = append(sva.Links, LinkConfig{
+				ID:          dh.ID,
+				Parent:      dh.ID + "_j",
+				Translation: spatial.NewPoseFromDH(dh.A, dh.D, utils.DegToRad(dh.Alpha)).Point(),
+				Orientation: orient,
+				Geometry:    dh.Geometry,
+			})
+		}
+		return sva, nil
+	default:
+		return nil, errors.Errorf("unsupported param type: %s, supported params are SVA and DH", cfg.KinParamType)
+	}
+}
+
+// MarshalURDF converts the model into URDF XML, see ModelConfig.MarshalURDF.
+func (m *SimpleModel) MarshalURDF() ([]byte, error) {
+	if m.modelConfig == nil {
+		return nil, errors.Errorf("model %q was not built from a config and cannot be exported", m.name)
+	}
+	return m.modelConfig.MarshalURDF()
+}
+
 // Convenience method to split up space-delimited fields in URDFs, such as xyz or rpy attributes.
 func convStringAttrToFloats(attr string) []float64 {
 	var converted []float64
@@ -264,53 +666,13 @@ func convStringAttrToFloats(attr string) []float64 {
 	return converted
 }
 
-// Convenience method to simplify creating geometry configs from URDF XML that has a collision element specified.
-func createConfigFromCollision(link URDFLink) (spatial.GeometryConfig, error) {
-	var geoCfg spatial.GeometryConfig
-	boxGeometry := link.Collision[0].Geometry.Box
-	sphereGeometry := link.Collision[0].Geometry.Sphere
-
-	// Offset for the geometry origin from the reference link origin
-	geomXYZ := convStringAttrToFloats(link.Collision[0].Origin.XYZ)
-	geomTx := r3.Vector{geomXYZ[0], geomXYZ[1], geomXYZ[2]}
-	geomRPY := convStringAttrToFloats(link.Collision[0].Origin.RPY)
-	geomEA := spatial.EulerAngles{
-		Roll:  utils.RadToDeg(geomRPY[0]),
-		Pitch: utils.RadToDeg(geomRPY[1]),
-		Yaw:   utils.RadToDeg(geomRPY[2]),
-	}
-	geomOx, err := spatial.NewOrientationConfig(geomEA.AxisAngles())
-	if err != nil {
-		return spatial.GeometryConfig{}, err
-	}
-
-	// Logic specific to the geometry type
-	switch {
-	case len(boxGeometry.Size) > 0:
-		boxDims := convStringAttrToFloats(boxGeometry.Size)
-		geoCfg = spatial.GeometryConfig{
-			Type:              "box",
-			X:                 metersToMM(boxDims[0]),
-			Y:                 metersToMM(boxDims[1]),
-			Z:                 metersToMM(boxDims[2]),
-			TranslationOffset: geomTx,
-			OrientationOffset: *geomOx,
-			Label:             "box",
-		}
-	case sphereGeometry.Radius > 0:
-		sphereRadius := metersToMM(sphereGeometry.Radius)
-		geoCfg = spatial.GeometryConfig{
-			Type:              "sphere",
-			R:                 sphereRadius,
-			TranslationOffset: geomTx,
-			OrientationOffset: *geomOx,
-			Label:             "sphere",
-		}
-	default:
-		return spatial.GeometryConfig{}, errors.Errorf("Unsupported collision geometry type detected for [ %v ] link", link.Collision[0].Name)
+// formatURDFFloats joins floats into a space-delimited field, with as many digits as needed to read them back exactly.
+func formatURDFFloats(values ...float64) string {
+	formatted := make([]string, 0, len(values))
+	for _, v := range values {
+		formatted = append(formatted, strconv.FormatFloat(v, 'g', -1, 64))
 	}
-
-	return geoCfg, nil
+	return strings.Join(formatted, " ")
 }
 
 // Convenience function to change engineering unit scale for the given input.
@@ -1,9 +1,14 @@
 package referenceframe
 
 import (
+	"encoding/binary"
+	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/golang/geo/r3"
 	"go.viam.com/test"
 
 	spatial "go.viam.com/rdk/spatialmath"
@@ -85,4 +90,171 @@ func TestURDFGeometries(t *testing.T) {
 	inputs = make([]Input, len(ur5ViamModel.DoF()))
 	modelGeo, _ = ur5ViamModel.Geometries(inputs)
 	test.That(t, len(modelGeo.geometries), test.ShouldEqual, 5)
+
+	// cylinders become capsules
+	ur5Dynamics, err := ParseURDFFile(utils.ResolveFile("referenceframe/testurdf/ur5_dynamics.urdf"), "")
+	test.That(t, err, test.ShouldBeNil)
+	modelGeo, err = ur5Dynamics.Geometries(make([]Input, len(ur5Dynamics.DoF())))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, modelGeo.Geometries(), test.ShouldHaveLength, 5)
+	wrist, err := spatial.NewGeometryConfig(modelGeo.GeometryByName("ur5:wrist_2_link"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, wrist.Type, test.ShouldEqual, spatial.CapsuleType)
+	test.That(t, wrist.R, test.ShouldAlmostEqual, 40)
+	test.That(t, wrist.L, test.ShouldAlmostEqual, 150)
+
+	// meshes become their bounding boxes, and fingers driven by a mimic joint move with the joint they follow
+	gripper, err := ParseURDFFile(utils.ResolveFile("referenceframe/testurdf/parallel_gripper.urdf"), "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, gripper.DoF(), test.ShouldHaveLength, 1)
+	palm, err := spatial.NewBox(spatial.NewPoseFromPoint(r3.Vector{Z: 25}), r3.Vector{X: 80, Y: 40, Z: 50}, "")
+	test.That(t, err, test.ShouldBeNil)
+	for _, opening := range []float64{0, 15, 40} {
+		modelGeo, err = gripper.Geometries([]Input{{opening}})
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, modelGeo.GeometryByName("gripper:gripper_base").AlmostEqual(palm), test.ShouldBeTrue)
+		for name, y := range map[string]float64{"gripper:left_finger": 10 + opening, "gripper:right_finger": -10 - opening} {
+			finger, err := spatial.NewBox(spatial.NewPoseFromPoint(r3.Vector{Y: y, Z: 80}), r3.Vector{X: 20, Y: 10, Z: 60}, "")
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, modelGeo.GeometryByName(name).AlmostEqual(finger), test.ShouldBeTrue)
+		}
+	}
+}
+
+func TestURDFLimits(t *testing.T) {
+	ur5, err := ParseURDFFile(utils.ResolveFile("referenceframe/testurdf/ur5_dynamics.urdf"), "")
+	test.That(t, err, test.ShouldBeNil)
+	limits := ur5.DoF()
+	test.That(t, limits, test.ShouldHaveLength, 6)
+	test.That(t, limits[0].Max, test.ShouldAlmostEqual, 6.283185)
+	test.That(t, limits[2].Min, test.ShouldAlmostEqual, -3.141592)
+	dynamics := ur5.(*SimpleModel).JointDynamics()
+	test.That(t, dynamics, test.ShouldHaveLength, 6)
+	for i, effort := range []float64{150, 150, 150, 28, 28, 28} {
+		test.That(t, dynamics[i].Velocity, test.ShouldAlmostEqual, 3.14)
+		test.That(t, dynamics[i].Effort, test.ShouldEqual, effort)
+	}
+
+	// prismatic limits are converted to mm
+	gripper, err := ParseURDFFile(utils.ResolveFile("referenceframe/testurdf/parallel_gripper.urdf"), "")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, gripper.DoF(), test.ShouldResemble, []Limit{{Min: 0, Max: 40}})
+	test.That(t, gripper.(*SimpleModel).JointDynamics(), test.ShouldResemble, []JointDynamics{{Velocity: 100, Effort: 40}})
+}
+
+func TestMarshalURDF(t *testing.T) {
+	for _, file := range []string{
+		"components/arm/universalrobots/ur5e.json",
+		"referenceframe/testjson/ur5eDH.json",
+		"components/arm/xarm/xarm6_kinematics.json",
+	} {
+		t.Run(file, func(t *testing.T) {
+			model, err := ParseModelJSONFile(utils.ResolveFile(file), "")
+			test.That(t, err, test.ShouldBeNil)
+			data, err := model.(*SimpleModel).MarshalURDF()
+			test.That(t, err, test.ShouldBeNil)
+			cfg, err := ConvertURDFToConfig(data, "")
+			test.That(t, err, test.ShouldBeNil)
+			converted, err := cfg.ParseConfig("")
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, converted.Name(), test.ShouldEqual, model.Name())
+			test.That(t, converted.DoF(), test.ShouldHaveLength, len(model.DoF()))
+			for i, limit := range model.DoF() {
+				test.That(t, converted.DoF()[i].Min, test.ShouldAlmostEqual, limit.Min)
+				test.That(t, converted.DoF()[i].Max, test.ShouldAlmostEqual, limit.Max)
+			}
+
+			seed := rand.New(rand.NewSource(1))
+			for i := 0; i < 10; i++ {
+				inputs := FloatsToInputs(GenerateRandomConfiguration(model, seed))
+				expected, err := model.Transform(inputs)
+				test.That(t, err, test.ShouldBeNil)
+				actual, err := converted.Transform(inputs)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, spatial.PoseAlmostEqual(expected, actual), test.ShouldBeTrue)
+
+				expectedGeo, err := model.Geometries(inputs)
+				test.That(t, err, test.ShouldBeNil)
+				actualGeo, err := converted.Geometries(inputs)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, actualGeo.Geometries(), test.ShouldHaveLength, len(expectedGeo.Geometries()))
+				for _, g := range expectedGeo.Geometries() {
+					test.That(t, actualGeo.GeometryByName(g.Label()).AlmostEqual(g), test.ShouldBeTrue)
+				}
+			}
+		})
+	}
+
+	t.Run("mimic joints", func(t *testing.T) {
+		model, err := ParseURDFFile(utils.ResolveFile("referenceframe/testurdf/parallel_gripper.urdf"), "")
+		test.That(t, err, test.ShouldBeNil)
+		data, err := model.(*SimpleModel).MarshalURDF()
+		test.That(t, err, test.ShouldBeNil)
+		cfg, err := ConvertURDFToConfig(data, "")
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, cfg.Joints, test.ShouldHaveLength, 2)
+		test.That(t, cfg.Joints[1].Mimic, test.ShouldResemble, &MimicConfig{Joint: "left_finger_joint", Multiplier: 1})
+		converted, err := cfg.ParseConfig("")
+		test.That(t, err, test.ShouldBeNil)
+		expectedGeo, err := model.Geometries([]Input{{20}})
+		test.That(t, err, test.ShouldBeNil)
+		actualGeo, err := converted.Geometries([]Input{{20}})
+		test.That(t, err, test.ShouldBeNil)
+		for _, g := range expectedGeo.Geometries() {
+			test.That(t, actualGeo.GeometryByName(g.Label()).AlmostEqual(g), test.ShouldBeTrue)
+		}
+	})
+}
+
+func TestURDFOrigin(t *testing.T) {
+	for _, o := range []spatial.Orientation{
+		&spatial.EulerAngles{Roll: 0.3, Pitch: 0.2, Yaw: 0.1},
+		&spatial.EulerAngles{Roll: 0.3, Pitch: math.Pi / 2, Yaw: 0.1},
+		&spatial.EulerAngles{Roll: 0.3, Pitch: -math.Pi / 2, Yaw: 0.1},
+		&spatial.OrientationVectorDegrees{OY: -1},
+		&spatial.OrientationVectorDegrees{OX: -1, Theta: 30},
+		&spatial.OrientationVectorDegrees{OZ: -1},
+	} {
+		pose := spatial.NewPose(r3.Vector{X: 1, Y: -20, Z: 300}, o)
+		origin := newURDFOrigin(pose)
+		parsed, err := origin.pose()
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, spatial.PoseAlmostEqual(parsed, pose), test.ShouldBeTrue)
+	}
+
+	// a missing origin is the identity
+	var origin *URDFOrigin
+	parsed, err := origin.pose()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatial.PoseAlmostEqual(parsed, spatial.NewZeroPose()), test.ShouldBeTrue)
+	_, err = (&URDFOrigin{XYZ: "1 2"}).pose()
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestMeshBounds(t *testing.T) {
+	dir := t.TempDir()
+
+	// a binary STL with a single triangle
+	stl := make([]byte, 84+50)
+	binary.LittleEndian.PutUint32(stl[80:], 1)
+	for i, v := range []float32{0, 0, 1, -1, 2, 0, 3, -4, 0, 0, 0, 5} {
+		binary.LittleEndian.PutUint32(stl[84+4*i:], math.Float32bits(v))
+	}
+	test.That(t, os.WriteFile(filepath.Join(dir, "triangle.stl"), stl, 0o600), test.ShouldBeNil)
+	obj := "# a triangle\nv -1 2 0\nv 3 -4 0\nv 0 0 5\nf 1 2 3\n"
+	test.That(t, os.WriteFile(filepath.Join(dir, "triangle.obj"), []byte(obj), 0o600), test.ShouldBeNil)
+	test.That(t, os.WriteFile(filepath.Join(dir, "triangle.dae"), []byte(obj), 0o600), test.ShouldBeNil)
+
+	for _, name := range []string{"triangle.stl", "triangle.obj"} {
+		filename, err := resolveMeshPath("package://description/"+name, filepath.Join(dir, "urdf"))
+		test.That(t, err, test.ShouldBeNil)
+		lo, hi, err := meshBounds(filename)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, lo, test.ShouldResemble, r3.Vector{X: -1, Y: -4, Z: 0})
+		test.That(t, hi, test.ShouldResemble, r3.Vector{X: 3, Y: 2, Z: 5})
+	}
+	_, _, err := meshBounds(filepath.Join(dir, "triangle.dae"))
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = resolveMeshPath("package://description/missing.stl", dir)
+	test.That(t, err, test.ShouldNotBeNil)
 }
@@ -107,6 +107,10 @@ type SubtypeRegistration[ResourceT Resource] struct {
 	ReflectRPCServiceDesc       *desc.ServiceDescriptor
 	RPCClient                   CreateRPCClient[ResourceT]
 
+	// ExtraRPCServices are served alongside the RPC service of the subtype, for RPCs that its API
+	// does not have yet, like streaming ones.
+	ExtraRPCServices []ExtraRPCService[ResourceT]
+
 	// MaxInstance sets a limit on the number of this subtype allowed on a robot.
 	// If MaxInstance is not set then it will default to 0 and there will be no limit.
 	MaxInstance int
@@ -116,6 +120,14 @@ type SubtypeRegistration[ResourceT Resource] struct {
 	typedVersion interface{} // the registry guarantees the type safety here
 }
 
+// An ExtraRPCService is another RPC service of a subtype, whose servers are constructed from the
+// resources of the subtype like its main one.
+type ExtraRPCService[ResourceT Resource] struct {
+	ServerConstructor func(subtypeColl SubtypeCollection[ResourceT]) interface{}
+	Handler           rpc.RegisterServiceHandlerFromEndpointFunc
+	Desc              *grpc.ServiceDesc
+}
+
 // RegisterRPCService registers this subtype into the given RPC server.
 func (rs SubtypeRegistration[ResourceT]) RegisterRPCService(
 	ctx context.Context,
@@ -125,12 +137,24 @@ func (rs SubtypeRegistration[ResourceT]) RegisterRPCService(
 	if rs.RPCServiceServerConstructor == nil {
 		return nil
 	}
-	return rpcServer.RegisterServiceServer(
+	if err := rpcServer.RegisterServiceServer(
 		ctx,
 		rs.RPCServiceDesc,
 		rs.RPCServiceServerConstructor(subtypeColl),
 		rs.RPCServiceHandler,
-	)
+	); err != nil {
+		return err
+	}
+	for _, extra := range rs.ExtraRPCServices {
+		var handlers []rpc.RegisterServiceHandlerFromEndpointFunc
+		if extra.Handler != nil {
+			handlers = append(handlers, extra.Handler)
+		}
+		if err := rpcServer.RegisterServiceServer(ctx, extra.Desc, extra.ServerConstructor(subtypeColl), handlers...); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // An AssociatedConfigRegistration describes how to convert all attributes
@@ -323,6 +347,11 @@ func RegisterSubtype[ResourceT Resource](subtype Subtype, creator SubtypeRegistr
 		(creator.RPCServiceDesc == nil || creator.RPCServiceHandler == nil) {
 		panic(errors.Errorf("cannot register a RPC enabled subtype with no RPC service description or handler: %s", subtype))
 	}
+	for _, extra := range creator.ExtraRPCServices {
+		if creator.RPCServiceServerConstructor == nil || extra.ServerConstructor == nil || extra.Desc == nil {
+			panic(errors.Errorf("cannot register an extra RPC service with no server constructor or service description: %s", subtype))
+		}
+	}
 
 	if creator.RPCServiceDesc != nil && creator.ReflectRPCServiceDesc == nil {
 		reflectSvcDesc, err := grpcreflect.LoadServiceDescriptor(creator.RPCServiceDesc)
@@ -478,6 +507,20 @@ func makeGenericSubtypeRegistration[ResourceT Resource](
 			return typed.RPCServiceServerConstructor(genericColl.typed)
 		}
 	}
+	for _, extra := range typed.ExtraRPCServices {
+		extra := extra
+		reg.ExtraRPCServices = append(reg.ExtraRPCServices, ExtraRPCService[Resource]{
+			ServerConstructor: func(coll SubtypeCollection[Resource]) interface{} {
+				genericColl, err := utils.AssertType[genericSubypeCollection[ResourceT]](coll)
+				if err != nil {
+					return err
+				}
+				return extra.ServerConstructor(genericColl.typed)
+			},
+			Handler: extra.Handler,
+			Desc:    extra.Desc,
+		})
+	}
 	if typed.RPCClient != nil {
 		reg.RPCClient = func(
 			ctx context.Context,
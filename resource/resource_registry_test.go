@@ -147,6 +147,46 @@ func TestResourceSubtypeRegistry(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 	test.That(t, subtypeInfo.ReflectRPCServiceDesc, test.ShouldResemble, reflectSvcDesc)
 
+	subtypeExtra := resource.NewSubtype(resource.Namespace("acme5"), resource.ResourceTypeComponent, button)
+	extra := resource.ExtraRPCService[arm.Arm]{
+		ServerConstructor: func(subtypeColl resource.SubtypeCollection[arm.Arm]) interface{} {
+			capColl = subtypeColl
+			return 6
+		},
+		Handler: pb.RegisterRobotServiceHandlerFromEndpoint,
+		Desc:    &pb.RobotService_ServiceDesc,
+	}
+	test.That(t, func() {
+		resource.RegisterSubtype(subtypeExtra, resource.SubtypeRegistration[arm.Arm]{
+			ExtraRPCServices: []resource.ExtraRPCService[arm.Arm]{extra},
+		})
+	}, test.ShouldPanic)
+	test.That(t, func() {
+		resource.RegisterSubtype(subtypeExtra, resource.SubtypeRegistration[arm.Arm]{
+			RPCServiceServerConstructor: sf,
+			RPCServiceDesc:              &pb.RobotService_ServiceDesc,
+			RPCServiceHandler:           pb.RegisterRobotServiceHandlerFromEndpoint,
+			ExtraRPCServices:            []resource.ExtraRPCService[arm.Arm]{{ServerConstructor: extra.ServerConstructor}},
+		})
+	}, test.ShouldPanic)
+	resource.RegisterSubtype(subtypeExtra, resource.SubtypeRegistration[arm.Arm]{
+		RPCServiceServerConstructor: sf,
+		RPCServiceDesc:              &pb.RobotService_ServiceDesc,
+		RPCServiceHandler:           pb.RegisterRobotServiceHandlerFromEndpoint,
+		ExtraRPCServices:            []resource.ExtraRPCService[arm.Arm]{extra},
+	})
+	genericInfo, ok := resource.LookupGenericSubtypeRegistration(subtypeExtra)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, genericInfo.ExtraRPCServices, test.ShouldHaveLength, 1)
+	test.That(t, genericInfo.ExtraRPCServices[0].Desc, test.ShouldEqual, &pb.RobotService_ServiceDesc)
+	extraInfo, ok, err := resource.LookupSubtypeRegistration[arm.Arm](subtypeExtra)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, extraInfo.ExtraRPCServices, test.ShouldHaveLength, 1)
+	test.That(t, extraInfo.ExtraRPCServices[0].ServerConstructor(coll), test.ShouldEqual, 6)
+	test.That(t, capColl, test.ShouldEqual, coll)
+	resource.DeregisterSubtype(subtypeExtra)
+
 	subtype3 := resource.NewSubtype(resource.Namespace("acme3"), resource.ResourceTypeComponent, button)
 	_, ok, err = resource.LookupSubtypeRegistration[arm.Arm](subtype3)
 	test.That(t, err, test.ShouldBeNil)
@@ -0,0 +1,70 @@
+// Given images of a checkerboard taken by a camera from different angles, estimates the intrinsic parameters and
+// distortion of the camera, and prints them as the attributes to add to its config.
+// The board is described by the number of inner corners, where four squares meet, along its rows and columns.
+// $./intrinsic_calibration -columns=9 -rows=6 -square_mm=25 /path/to/image1.jpg /path/to/image2.jpg ...
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/vision/calibration"
+)
+
+func main() {
+	columns := flag.Int("columns", 9, "number of inner corners along the rows of the checkerboard")
+	rows := flag.Int("rows", 6, "number of inner corners along the columns of the checkerboard")
+	squareMM := flag.Float64("square_mm", 25, "length of the sides of the squares of the checkerboard in millimeters")
+	flag.Parse()
+	logger := golog.NewLogger("intrinsic_calibration")
+	board := calibration.Checkerboard{Columns: *columns, Rows: *rows, SquareMM: *squareMM}
+	if err := calibrate(board, flag.Args(), os.Stdout, logger); err != nil {
+		logger.Fatal(err)
+	}
+	os.Exit(0)
+}
+
+func calibrate(board calibration.Checkerboard, paths []string, out io.Writer, logger golog.Logger) error {
+	calibrator, err := calibration.NewCalibrator(board)
+	if err != nil {
+		return err
+	}
+	var used []string
+	for _, path := range paths {
+		img, err := rimage.NewImageFromFile(path)
+		if err != nil {
+			return err
+		}
+		if err := calibrator.AddImage(img); err != nil {
+			if errors.Is(err, calibration.ErrBoardNotFound) {
+				logger.Warnf("skipping %q: the whole board is not in it", path)
+				continue
+			}
+			return errors.Wrapf(err, "path=%q", path)
+		}
+		used = append(used, path)
+	}
+
+	result, err := calibrator.Calibrate()
+	if err != nil {
+		return err
+	}
+	for i, path := range used {
+		logger.Infof("%s: reprojection error %.3f px", path, result.ViewErrorsPx[i])
+	}
+	logger.Infof("reprojection error over %d views: %.3f px", len(used), result.RMSErrorPx)
+
+	attrs, err := json.MarshalIndent(result.CameraAttributes(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(attrs))
+	return err
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision/calibration"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+func TestMainCalibrate(t *testing.T) {
+	outDir := testutils.TempDirT(t, "", "transform_cmd_intrinsic_calibration")
+	logger := golog.NewTestLogger(t)
+	board := calibration.Checkerboard{Columns: 6, Rows: 5, SquareMM: 30}
+
+	// a board of 7 by 6 squares with a white margin, in a square image
+	const squarePx = 20
+	boardImg := image.NewGray(image.Rect(0, 0, 9*squarePx, 9*squarePx))
+	draw.Draw(boardImg, boardImg.Bounds(), image.NewUniform(color.Gray{Y: 250}), image.Point{}, draw.Src)
+	for row := 0; row < 6; row++ {
+		for col := 0; col < 7; col++ {
+			if (row+col)%2 == 0 {
+				r := image.Rect((col+1)*squarePx, (row+1)*squarePx, (col+2)*squarePx, (row+2)*squarePx)
+				draw.Draw(boardImg, r, image.NewUniform(color.Gray{Y: 10}), image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	camera := &fiducial.Camera{
+		Intrinsics: &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240},
+		Distortion: &transform.BrownConrady{RadialK1: -0.1},
+	}
+	facing := &spatialmath.R4AA{Theta: math.Pi, RX: 1}
+	var paths []string
+	for i, tilt := range []r3.Vector{{X: 0.01}, {X: 0.4}, {Y: 0.4}, {X: -0.3, Y: 0.3}, {X: 0.3, Y: -0.3}} {
+		img := image.NewGray(image.Rect(0, 0, 640, 480))
+		draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 128}), image.Point{}, draw.Src)
+		pose := spatialmath.Compose(
+			spatialmath.NewPose(r3.Vector{X: float64(i-2) * 40, Z: 700}, &spatialmath.R4AA{Theta: tilt.Norm(), RX: tilt.X, RY: tilt.Y}),
+			spatialmath.NewPoseFromOrientation(facing),
+		)
+		fiducial.DrawMarker(img, boardImg, 9*board.SquareMM, pose, camera)
+		path := filepath.Join(outDir, fmt.Sprintf("view%d.png", i))
+		test.That(t, rimage.WriteImageToFile(path, img), test.ShouldBeNil)
+		paths = append(paths, path)
+	}
+	blank := filepath.Join(outDir, "blank.png")
+	test.That(t, rimage.WriteImageToFile(blank, image.NewGray(image.Rect(0, 0, 640, 480))), test.ShouldBeNil)
+
+	var out bytes.Buffer
+	test.That(t, calibrate(board, append(paths, blank), &out, logger), test.ShouldBeNil)
+	var attrs calibration.CameraAttributes
+	test.That(t, json.Unmarshal(out.Bytes(), &attrs), test.ShouldBeNil)
+	test.That(t, attrs.IntrinsicParams.Width, test.ShouldEqual, 640)
+	test.That(t, attrs.IntrinsicParams.Fx, test.ShouldAlmostEqual, 600, 10)
+	test.That(t, attrs.IntrinsicParams.Ppx, test.ShouldAlmostEqual, 320, 10)
+	test.That(t, attrs.DistortionParams.RadialK1, test.ShouldAlmostEqual, -0.1, 0.05)
+
+	test.That(t, calibrate(board, paths[:2], &out, logger), test.ShouldNotBeNil)
+}
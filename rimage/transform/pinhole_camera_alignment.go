@@ -128,8 +128,18 @@ func (dcie *DepthColorIntrinsicsExtrinsics) TransformDepthCoordToColorCoord(
 			errors.Errorf("camera matrices expected color image of (%#v,%#v), got (%#v, %#v)",
 				dcie.ColorCamera.Width, dcie.ColorCamera.Height, col.Width(), col.Height())
 	}
+	outmap, err := dcie.TransformDepthMapToColorCoord(dep)
+	if err != nil {
+		return nil, nil, err
+	}
+	return col, outmap, nil
+}
+
+// TransformDepthMapToColorCoord reprojects the depth map into the coordinate system of the color camera,
+// returning a depth map the size of the color image.
+func (dcie *DepthColorIntrinsicsExtrinsics) TransformDepthMapToColorCoord(dep *rimage.DepthMap) (*rimage.DepthMap, error) {
 	if dep.Height() != dcie.DepthCamera.Height || dep.Width() != dcie.DepthCamera.Width {
-		return nil, nil,
+		return nil,
 			errors.Errorf("camera matrices expected depth image of (%#v,%#v), got (%#v, %#v)",
 				dcie.DepthCamera.Width, dcie.DepthCamera.Height, dep.Width(), dep.Height())
 	}
@@ -158,7 +168,7 @@ func (dcie *DepthColorIntrinsicsExtrinsics) TransformDepthCoordToColorCoord(
 			}
 		}
 	}
-	return col, outmap, nil
+	return outmap, nil
 }
 
 // ImagePointTo3DPoint takes in a image coordinate and returns the 3D point from the camera matrix.
@@ -124,6 +124,7 @@ type RobotClient struct {
 	client              pb.RobotServiceClient
 	refClient           *grpcreflect.Client
 	connected           atomic.Bool
+	callPolicies        *callPolicies
 
 	activeBackgroundWorkers sync.WaitGroup
 	backgroundCtx           context.Context
@@ -192,8 +193,10 @@ func (rc *RobotClient) handleUnaryDisconnect(
 	}
 
 	if err := rc.checkConnected(); err != nil {
-		rc.Logger().Debugw("connection is down, skipping method call", "method", method)
-		return status.Error(codes.Unavailable, err.Error())
+		if !rc.callPolicies.waitForReconnect(ctx, method, rc.connected.Load) || rc.checkConnected() != nil {
+			rc.Logger().Debugw("connection is down, skipping method call", "method", method)
+			return status.Error(codes.Unavailable, err.Error())
+		}
 	}
 
 	err := invoker(ctx, method, req, reply, cc, opts...)
@@ -276,11 +279,15 @@ func New(ctx context.Context, address string, logger golog.Logger, opts ...Robot
 		sessionsDisabled:    rOpts.disableSessions,
 		heartbeatCtx:        heartbeatCtx,
 		heartbeatCtxCancel:  heartbeatCtxCancel,
+		callPolicies:        newCallPolicies(rOpts),
 	}
 
 	// interceptors are applied in order from first to last
 	rc.dialOptions = append(
 		rc.dialOptions,
+		// deadlines and retries, around the error handling so that calls made while the
+		// connection is down can be retried
+		rpc.WithUnaryClientInterceptor(rc.retryUnaryClientInterceptor),
 		// error handling
 		rpc.WithUnaryClientInterceptor(rc.handleUnaryDisconnect),
 		rpc.WithStreamClientInterceptor(rc.handleStreamDisconnect),
@@ -389,6 +396,7 @@ func (rc *RobotClient) connect(ctx context.Context) error {
 	rc.client = client
 	rc.refClient = refClient
 	rc.connected.Store(true)
+	rc.callPolicies.notifyReconnected()
 	if len(rc.resourceClients) != 0 {
 		if err := rc.updateResources(ctx); err != nil {
 			return err
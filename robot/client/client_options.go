@@ -32,6 +32,21 @@ type robotClientOpts struct {
 
 	// controls whether or not sessions are disabled.
 	disableSessions bool
+
+	// retryPolicy, if set, is the policy used to retry calls of the methods that are safe to call
+	// again, unless methodRetryPolicies has a policy for the method.
+	retryPolicy         *RetryPolicy
+	methodRetryPolicies map[string]RetryPolicy
+
+	// defaultDeadline, if positive, bounds calls of the methods that are safe to call again
+	// that do not already have a deadline, unless methodDeadlines has a deadline for the method.
+	defaultDeadline time.Duration
+	methodDeadlines map[string]time.Duration
+
+	// offlineQueueSize is how many calls of methods that are safe to call again may wait
+	// for up to offlineQueueWait for the robot to reconnect, instead of failing immediately.
+	offlineQueueSize int
+	offlineQueueWait time.Duration
 }
 
 // RobotClientOption configures how we set up the connection.
@@ -100,6 +115,61 @@ func WithDialOptions(opts ...rpc.DialOption) RobotClientOption {
 	})
 }
 
+// WithRetryPolicy returns a RobotClientOption that retries failed calls of the methods that only read
+// the state of the robot, like GetReadings, GetPosition, GetJointPositions and GetImage, according to
+// the given policy. Methods that actuate are never retried, unless they are given a policy with
+// WithMethodRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.retryPolicy = &policy
+	})
+}
+
+// WithMethodRetryPolicy returns a RobotClientOption that retries failed calls of the given method,
+// fully qualified like "/viam.component.sensor.v1.SensorService/GetReadings", according to the given
+// policy. A policy with MaxAttempts of 1 disables retries of the method.
+func WithMethodRetryPolicy(method string, policy RetryPolicy) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		if o.methodRetryPolicies == nil {
+			o.methodRetryPolicies = map[string]RetryPolicy{}
+		}
+		o.methodRetryPolicies[method] = policy
+	})
+}
+
+// WithDefaultDeadline returns a RobotClientOption that bounds the calls of the methods that only read
+// the state of the robot to the given duration, including retries, when their context has no deadline.
+// Methods that actuate may legitimately run for a long time, so they are only bounded by their context,
+// unless they are given a deadline with WithMethodDeadline.
+func WithDefaultDeadline(deadline time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.defaultDeadline = deadline
+	})
+}
+
+// WithMethodDeadline returns a RobotClientOption that bounds the calls of the given method, fully
+// qualified, to the given duration when their context has no deadline. A non-positive deadline
+// removes the default deadline of the method.
+func WithMethodDeadline(method string, deadline time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		if o.methodDeadlines == nil {
+			o.methodDeadlines = map[string]time.Duration{}
+		}
+		o.methodDeadlines[method] = deadline
+	})
+}
+
+// WithOfflineQueue returns a RobotClientOption that lets up to size calls of the methods that only read
+// the state of the robot wait for up to maxWait for the robot to reconnect when the connection is down,
+// instead of failing immediately. Calls that do not fit in the queue fail immediately, and calls of
+// methods that actuate are never queued, since they could run long after the caller expected.
+func WithOfflineQueue(size int, maxWait time.Duration) RobotClientOption {
+	return newFuncRobotClientOption(func(o *robotClientOpts) {
+		o.offlineQueueSize = size
+		o.offlineQueueWait = maxWait
+	})
+}
+
 // ExtractDialOptions extracts RPC dial options from the given options, if any exist.
 func ExtractDialOptions(opts ...RobotClientOption) []rpc.DialOption {
 	var rOpts robotClientOpts
@@ -0,0 +1,311 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.viam.com/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// readOnlyMethods are the methods that only read the state of the robot, and so are safe to call again
+// after a failure or to call twice at once. The methods the client uses to check its connection are left
+// out, so that retrying them does not delay noticing that the connection is lost.
+var readOnlyMethods = map[string]bool{
+	"/viam.component.arm.v1.ArmService/GetEndPosition":                              true,
+	"/viam.component.arm.v1.ArmService/GetJointPositions":                           true,
+	"/viam.component.arm.v1.ArmService/IsMoving":                                    true,
+	"/viam.component.base.v1.BaseService/IsMoving":                                  true,
+	"/viam.component.board.v1.BoardService/GetDigitalInterruptValue":                true,
+	"/viam.component.board.v1.BoardService/GetGPIO":                                 true,
+	"/viam.component.board.v1.BoardService/ReadAnalogReader":                        true,
+	"/viam.component.board.v1.BoardService/Status":                                  true,
+	"/viam.component.camera.v1.CameraService/GetImage":                              true,
+	"/viam.component.camera.v1.CameraService/GetPointCloud":                         true,
+	"/viam.component.camera.v1.CameraService/GetProperties":                         true,
+	"/viam.component.camera.v1.CameraService/RenderFrame":                           true,
+	"/viam.component.encoder.v1.EncoderService/GetPosition":                         true,
+	"/viam.component.encoder.v1.EncoderService/GetProperties":                       true,
+	"/viam.component.gantry.v1.GantryService/GetLengths":                            true,
+	"/viam.component.gantry.v1.GantryService/GetPosition":                           true,
+	"/viam.component.gantry.v1.GantryService/IsMoving":                              true,
+	"/viam.component.gripper.v1.GripperService/IsMoving":                            true,
+	"/viam.component.inputcontroller.v1.InputControllerService/GetControls":         true,
+	"/viam.component.inputcontroller.v1.InputControllerService/GetEvents":           true,
+	"/viam.component.motor.v1.MotorService/GetPosition":                             true,
+	"/viam.component.motor.v1.MotorService/GetProperties":                           true,
+	"/viam.component.motor.v1.MotorService/IsMoving":                                true,
+	"/viam.component.motor.v1.MotorService/IsPowered":                               true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetAccuracy":           true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetAngularVelocity":    true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetCompassHeading":     true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetLinearAcceleration": true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetLinearVelocity":     true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetOrientation":        true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetPosition":           true,
+	"/viam.component.movementsensor.v1.MovementSensorService/GetProperties":         true,
+	"/viam.component.posetracker.v1.PoseTrackerService/GetPoses":                    true,
+	"/viam.component.sensor.v1.SensorService/GetReadings":                           true,
+	"/viam.component.servo.v1.ServoService/GetPosition":                             true,
+	"/viam.component.servo.v1.ServoService/IsMoving":                                true,
+	"/viam.robot.v1.RobotService/FrameSystemConfig":                                 true,
+	"/viam.robot.v1.RobotService/GetOperations":                                     true,
+	"/viam.robot.v1.RobotService/GetStatus":                                         true,
+	"/viam.robot.v1.RobotService/TransformPose":                                     true,
+	"/viam.service.motion.v1.MotionService/GetPose":                                 true,
+	"/viam.service.navigation.v1.NavigationService/GetLocation":                     true,
+	"/viam.service.navigation.v1.NavigationService/GetMode":                         true,
+	"/viam.service.navigation.v1.NavigationService/GetWaypoints":                    true,
+	"/viam.service.sensors.v1.SensorsService/GetReadings":                           true,
+	"/viam.service.sensors.v1.SensorsService/GetSensors":                            true,
+	"/viam.service.slam.v1.SLAMService/GetPosition":                                 true,
+	"/viam.service.vision.v1.VisionService/GetClassifications":                      true,
+	"/viam.service.vision.v1.VisionService/GetClassificationsFromCamera":            true,
+	"/viam.service.vision.v1.VisionService/GetClassifierNames":                      true,
+	"/viam.service.vision.v1.VisionService/GetDetections":                           true,
+	"/viam.service.vision.v1.VisionService/GetDetectionsFromCamera":                 true,
+	"/viam.service.vision.v1.VisionService/GetDetectorNames":                        true,
+	"/viam.service.vision.v1.VisionService/GetObjectPointClouds":                    true,
+	"/viam.service.vision.v1.VisionService/GetSegmenterNames":                       true,
+	"/viam.service.vision.v1.VisionService/GetModelParameterSchema":                 true,
+}
+
+// RetryPolicy configures how failed calls of a method are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the method is called, including the first call.
+	MaxAttempts int
+	// InitialBackoff is how long to wait before the first retry. Every retry waits BackoffMultiplier
+	// times longer than the previous one, up to MaxBackoff. Waits are randomized by up to 20% so that
+	// many clients do not retry in lockstep.
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	// RetryableCodes are the status codes of the errors that are retried.
+	RetryableCodes []codes.Code
+	// HedgingDelay, if positive, is how long to wait for a reply before calling the method a second time
+	// at once, and using whichever reply comes first. This cuts the latency added by a slow connection or
+	// server, at the cost of extra calls.
+	HedgingDelay time.Duration
+}
+
+// DefaultRetryPolicy returns a policy that retries calls that failed because the robot was unreachable
+// up to 5 times over a few seconds.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:       5,
+		InitialBackoff:    100 * time.Millisecond,
+		MaxBackoff:        2 * time.Second,
+		BackoffMultiplier: 2,
+		RetryableCodes:    []codes.Code{codes.Unavailable, codes.ResourceExhausted, codes.Aborted},
+	}
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	code := status.Code(err)
+	for _, c := range p.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) nextBackoff(backoff time.Duration) time.Duration {
+	multiplier := p.BackoffMultiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	backoff = time.Duration(float64(backoff) * multiplier)
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
+
+func jitter(d time.Duration) time.Duration {
+	//nolint:gosec
+	return time.Duration(float64(d) * (0.8 + 0.4*rand.Float64()))
+}
+
+// callPolicies holds the retry, deadline and queueing policies of the methods called by a client.
+type callPolicies struct {
+	retryPolicy         *RetryPolicy
+	methodRetryPolicies map[string]RetryPolicy
+	defaultDeadline     time.Duration
+	methodDeadlines     map[string]time.Duration
+	offlineQueueSize    int
+	offlineQueueWait    time.Duration
+
+	queueMu     sync.Mutex
+	queued      int
+	reconnected chan struct{}
+}
+
+func newCallPolicies(opts robotClientOpts) *callPolicies {
+	return &callPolicies{
+		retryPolicy:         opts.retryPolicy,
+		methodRetryPolicies: opts.methodRetryPolicies,
+		defaultDeadline:     opts.defaultDeadline,
+		methodDeadlines:     opts.methodDeadlines,
+		offlineQueueSize:    opts.offlineQueueSize,
+		offlineQueueWait:    opts.offlineQueueWait,
+		reconnected:         make(chan struct{}),
+	}
+}
+
+func (cp *callPolicies) retryPolicyFor(method string) (RetryPolicy, bool) {
+	if p, ok := cp.methodRetryPolicies[method]; ok {
+		return p, p.MaxAttempts > 1
+	}
+	if cp.retryPolicy != nil && readOnlyMethods[method] {
+		return *cp.retryPolicy, cp.retryPolicy.MaxAttempts > 1
+	}
+	return RetryPolicy{}, false
+}
+
+func (cp *callPolicies) deadlineFor(method string) time.Duration {
+	if d, ok := cp.methodDeadlines[method]; ok {
+		return d
+	}
+	if readOnlyMethods[method] {
+		return cp.defaultDeadline
+	}
+	return 0
+}
+
+// notifyReconnected wakes up the calls waiting in the offline queue.
+func (cp *callPolicies) notifyReconnected() {
+	cp.queueMu.Lock()
+	defer cp.queueMu.Unlock()
+	close(cp.reconnected)
+	cp.reconnected = make(chan struct{})
+}
+
+// waitForReconnect waits for the client to reconnect if the method may be queued and the queue has room.
+// It returns whether the client may have reconnected.
+func (cp *callPolicies) waitForReconnect(ctx context.Context, method string, connected func() bool) bool {
+	if cp.offlineQueueSize <= 0 || cp.offlineQueueWait <= 0 || !readOnlyMethods[method] {
+		return false
+	}
+	cp.queueMu.Lock()
+	if cp.queued >= cp.offlineQueueSize {
+		cp.queueMu.Unlock()
+		return false
+	}
+	cp.queued++
+	reconnected := cp.reconnected
+	cp.queueMu.Unlock()
+	defer func() {
+		cp.queueMu.Lock()
+		cp.queued--
+		cp.queueMu.Unlock()
+	}()
+
+	// the client may have reconnected between checking the connection and entering the queue
+	if connected() {
+		return true
+	}
+	timer := time.NewTimer(cp.offlineQueueWait)
+	defer timer.Stop()
+	select {
+	case <-reconnected:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// retryUnaryClientInterceptor applies the deadline and retry policy of a method to its calls.
+func (rc *RobotClient) retryUnaryClientInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if deadline := rc.callPolicies.deadlineFor(method); deadline > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel func()
+			ctx, cancel = context.WithTimeout(ctx, deadline)
+			defer cancel()
+		}
+	}
+	policy, ok := rc.callPolicies.retryPolicyFor(method)
+	if !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := hedgedInvoke(ctx, policy.HedgingDelay, method, req, reply, cc, invoker, opts...)
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return err
+		}
+		rc.Logger().Debugw("retrying failed call", "method", method, "attempt", attempt, "error", err)
+		if !utils.SelectContextOrWait(ctx, jitter(backoff)) {
+			return err
+		}
+		backoff = policy.nextBackoff(backoff)
+	}
+}
+
+// hedgedInvoke calls a method, and calls it a second time at once if it has not replied after delay,
+// returning the first successful reply.
+func hedgedInvoke(
+	ctx context.Context,
+	delay time.Duration,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	replyMsg, ok := reply.(proto.Message)
+	if delay <= 0 || !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	// the losing call is canceled when the winner returns
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		reply proto.Message
+		err   error
+	}
+	results := make(chan result, 2)
+	call := func() {
+		attemptReply := replyMsg.ProtoReflect().New().Interface()
+		err := invoker(ctx, method, req, attemptReply, cc, opts...)
+		results <- result{attemptReply, err}
+	}
+	utils.PanicCapturingGo(call)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	pending := 1
+	for {
+		select {
+		case <-timer.C:
+			pending++
+			utils.PanicCapturingGo(call)
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				proto.Merge(replyMsg, res.reply)
+				return nil
+			}
+			// a call that failed before the hedging delay is left for the retry policy to handle
+			if pending == 0 {
+				return res.err
+			}
+		}
+	}
+}
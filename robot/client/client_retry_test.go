@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	pb "go.viam.com/api/robot/v1"
+	"go.viam.com/test"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	readMethod     = "/viam.component.sensor.v1.SensorService/GetReadings"
+	actuatorMethod = "/viam.component.motor.v1.MotorService/SetPower"
+)
+
+func newPolicyTestClient(t *testing.T, opts ...RobotClientOption) *RobotClient {
+	t.Helper()
+	var rOpts robotClientOpts
+	for _, opt := range opts {
+		opt.apply(&rOpts)
+	}
+	return &RobotClient{logger: golog.NewTestLogger(t), callPolicies: newCallPolicies(rOpts)}
+}
+
+// failingInvoker fails with the given code the given number of times before succeeding.
+func failingInvoker(failures int, code codes.Code, calls *int32) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if int(atomic.AddInt32(calls, 1)) <= failures {
+			return status.Error(code, "nope")
+		}
+		return nil
+	}
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxAttempts = 3
+
+	t.Run("no policy", func(t *testing.T) {
+		rc := newPolicyTestClient(t)
+		var calls int32
+		err := rc.retryUnaryClientInterceptor(ctx, readMethod, nil, nil, nil, failingInvoker(1, codes.Unavailable, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+		test.That(t, calls, test.ShouldEqual, 1)
+	})
+
+	rc := newPolicyTestClient(t, WithRetryPolicy(policy))
+	t.Run("read is retried", func(t *testing.T) {
+		var calls int32
+		err := rc.retryUnaryClientInterceptor(ctx, readMethod, nil, nil, nil, failingInvoker(2, codes.Unavailable, &calls))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, calls, test.ShouldEqual, 3)
+	})
+	t.Run("attempts are limited", func(t *testing.T) {
+		var calls int32
+		err := rc.retryUnaryClientInterceptor(ctx, readMethod, nil, nil, nil, failingInvoker(5, codes.Unavailable, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+		test.That(t, calls, test.ShouldEqual, 3)
+	})
+	t.Run("only retryable codes are retried", func(t *testing.T) {
+		var calls int32
+		err := rc.retryUnaryClientInterceptor(ctx, readMethod, nil, nil, nil, failingInvoker(1, codes.InvalidArgument, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.InvalidArgument)
+		test.That(t, calls, test.ShouldEqual, 1)
+	})
+	t.Run("actuator is not retried", func(t *testing.T) {
+		var calls int32
+		err := rc.retryUnaryClientInterceptor(ctx, actuatorMethod, nil, nil, nil, failingInvoker(1, codes.Unavailable, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+		test.That(t, calls, test.ShouldEqual, 1)
+	})
+
+	t.Run("method policies", func(t *testing.T) {
+		rc := newPolicyTestClient(t,
+			WithRetryPolicy(policy),
+			WithMethodRetryPolicy(actuatorMethod, policy),
+			WithMethodRetryPolicy(readMethod, RetryPolicy{MaxAttempts: 1}),
+		)
+		var calls int32
+		err := rc.retryUnaryClientInterceptor(ctx, actuatorMethod, nil, nil, nil, failingInvoker(1, codes.Unavailable, &calls))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, calls, test.ShouldEqual, 2)
+
+		calls = 0
+		err = rc.retryUnaryClientInterceptor(ctx, readMethod, nil, nil, nil, failingInvoker(1, codes.Unavailable, &calls))
+		test.That(t, status.Code(err), test.ShouldEqual, codes.Unavailable)
+		test.That(t, calls, test.ShouldEqual, 1)
+	})
+}
+
+func TestClientHedging(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.HedgingDelay = 10 * time.Millisecond
+	rc := newPolicyTestClient(t, WithRetryPolicy(policy))
+
+	// the first call hangs until it is canceled, the second one replies
+	var calls int32
+	canceled := make(chan struct{})
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-ctx.Done()
+			close(canceled)
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		reply.(*pb.GetOperationsResponse).Operations = []*pb.Operation{{Id: "hedged"}}
+		return nil
+	}
+	reply := &pb.GetOperationsResponse{}
+	err := rc.retryUnaryClientInterceptor(context.Background(), readMethod, nil, reply, nil, invoker)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reply.Operations, test.ShouldHaveLength, 1)
+	test.That(t, reply.Operations[0].Id, test.ShouldEqual, "hedged")
+	test.That(t, calls, test.ShouldEqual, 2)
+	<-canceled
+
+	// a call that replies before the delay is not hedged
+	calls = 0
+	reply = &pb.GetOperationsResponse{}
+	err = rc.retryUnaryClientInterceptor(context.Background(), readMethod, nil, reply, nil, failingInvoker(0, codes.OK, &calls))
+	test.That(t, err, test.ShouldBeNil)
+	time.Sleep(2 * policy.HedgingDelay)
+	test.That(t, atomic.LoadInt32(&calls), test.ShouldEqual, 1)
+}
+
+func TestClientDefaultDeadline(t *testing.T) {
+	rc := newPolicyTestClient(t,
+		WithDefaultDeadline(time.Second),
+		WithMethodDeadline("/viam.component.arm.v1.ArmService/MoveToPosition", time.Minute),
+		WithMethodDeadline("/viam.component.arm.v1.ArmService/GetJointPositions", 0),
+	)
+	deadlineOf := func(ctx context.Context, method string) time.Duration {
+		var remaining time.Duration
+		invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			if deadline, ok := ctx.Deadline(); ok {
+				remaining = time.Until(deadline)
+			}
+			return nil
+		}
+		test.That(t, rc.retryUnaryClientInterceptor(ctx, method, nil, nil, nil, invoker), test.ShouldBeNil)
+		return remaining
+	}
+
+	test.That(t, deadlineOf(context.Background(), readMethod), test.ShouldBeBetween, 0, time.Second)
+	test.That(t, deadlineOf(context.Background(), actuatorMethod), test.ShouldEqual, 0)
+	test.That(t, deadlineOf(context.Background(), "/viam.component.arm.v1.ArmService/MoveToPosition"),
+		test.ShouldBeBetween, time.Second, time.Minute)
+	test.That(t, deadlineOf(context.Background(), "/viam.component.arm.v1.ArmService/GetJointPositions"), test.ShouldEqual, 0)
+
+	// an existing deadline is kept
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	test.That(t, deadlineOf(ctx, readMethod), test.ShouldBeGreaterThan, time.Minute)
+}
+
+func TestClientOfflineQueue(t *testing.T) {
+	ctx := context.Background()
+	cp := newPolicyTestClient(t, WithOfflineQueue(1, time.Minute)).callPolicies
+	disconnected := func() bool { return false }
+
+	test.That(t, cp.waitForReconnect(ctx, actuatorMethod, disconnected), test.ShouldBeFalse)
+	test.That(t, cp.waitForReconnect(ctx, readMethod, func() bool { return true }), test.ShouldBeTrue)
+
+	result := make(chan bool)
+	go func() {
+		result <- cp.waitForReconnect(ctx, readMethod, disconnected)
+	}()
+	// wait for the call to be queued
+	for {
+		cp.queueMu.Lock()
+		queued := cp.queued
+		cp.queueMu.Unlock()
+		if queued == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// the queue is full
+	test.That(t, cp.waitForReconnect(ctx, readMethod, disconnected), test.ShouldBeFalse)
+	cp.notifyReconnected()
+	test.That(t, <-result, test.ShouldBeTrue)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	test.That(t, cp.waitForReconnect(cancelCtx, readMethod, disconnected), test.ShouldBeFalse)
+
+	cp = newPolicyTestClient(t, WithOfflineQueue(1, time.Millisecond)).callPolicies
+	test.That(t, cp.waitForReconnect(ctx, readMethod, disconnected), test.ShouldBeFalse)
+	cp = newPolicyTestClient(t).callPolicies
+	test.That(t, cp.waitForReconnect(ctx, readMethod, disconnected), test.ShouldBeFalse)
+}
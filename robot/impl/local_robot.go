@@ -21,6 +21,7 @@ import (
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/internal"
 	"go.viam.com/rdk/internal/cloud"
+	"go.viam.com/rdk/metrics"
 	"go.viam.com/rdk/module/modmanager"
 	modmanageroptions "go.viam.com/rdk/module/modmanager/options"
 	modif "go.viam.com/rdk/module/modmaninterface"
@@ -41,6 +42,12 @@ import (
 
 var _ = robot.LocalRobot(&localRobot{})
 
+var reconfigurationDurations = metrics.DefaultRegistry.NewHistogramVec(
+	"viam_robot_reconfiguration_duration_seconds",
+	"How long reconfiguring the robot for a changed config took.",
+	[]float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+).With()
+
 // localRobot satisfies robot.LocalRobot and defers most
 // logic to its manager.
 type localRobot struct {
@@ -813,6 +820,7 @@ func dialRobotClient(
 // possibly leak resources.
 // The given config is assumed to be owned by the robot now.
 func (r *localRobot) Reconfigure(ctx context.Context, newConfig *config.Config) {
+	start := time.Now()
 	var allErrs error
 
 	// Add default services and process their dependencies. Dependencies may
@@ -910,6 +918,10 @@ func (r *localRobot) Reconfigure(ctx context.Context, newConfig *config.Config)
 	if diff.ResourcesEqual {
 		return
 	}
+	// only time reconfigurations that change something, and not the frequent checks for new configs
+	defer func() {
+		reconfigurationDurations.Observe(time.Since(start).Seconds())
+	}()
 
 	if r.revealSensitiveConfigDiffs {
 		r.logger.Debugf("(re)configuring with %+v", diff)
@@ -11,10 +11,13 @@ import (
 	pb "go.viam.com/api/robot/v1"
 	"go.viam.com/utils"
 
+	"go.viam.com/rdk/metrics"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/session"
 )
 
+var activeSessions = metrics.DefaultRegistry.NewGaugeVec("viam_sessions", "Sessions of clients with the robot.").With()
+
 // NewSessionManager creates a new manager for holding sessions.
 func NewSessionManager(robot Robot, heartbeatWindow time.Duration) *SessionManager {
 	cancelCtx, cancel := context.WithCancel(context.Background())
@@ -92,6 +95,7 @@ func (m *SessionManager) expireLoop(ctx context.Context) {
 			for id := range toDelete {
 				delete(m.sessions, id)
 			}
+			activeSessions.Set(float64(len(m.sessions)))
 
 			if len(toStop) == 0 {
 				return
@@ -147,6 +151,7 @@ func (m *SessionManager) Start(
 		return nil, errors.New("too many concurrent sessions")
 	}
 	m.sessions[sess.ID()] = sess
+	activeSessions.Set(float64(len(m.sessions)))
 	m.sessionResourceMu.Unlock()
 	return sess, nil
 }
@@ -0,0 +1,216 @@
+package web
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/jhump/protoreflect/dynamic"
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/resource"
+)
+
+// unauthorizedMethodPrefixes are the methods of the services of the RPC framework itself, like
+// authentication and signaling, which the allowlist does not apply to.
+var unauthorizedMethodPrefixes = []string{"/proto.rpc.", "/grpc."}
+
+// An authorizer decides which methods of which resources may be called, according to the allowlist
+// of the auth config. It applies the same to every authenticated caller.
+type authorizer struct {
+	permissions []config.AuthPermissionConfig
+}
+
+// newAuthorizer returns an authorizer for the allowlist of the auth config, or nil if there is none
+// and so every authenticated caller may call anything.
+func newAuthorizer(auth config.AuthConfig) *authorizer {
+	if len(auth.Allow) == 0 {
+		return nil
+	}
+	return &authorizer{permissions: auth.Allow}
+}
+
+// an rpcCall is what a caller is trying to do.
+type rpcCall struct {
+	fullMethod string
+	// resource is the name of the resource in the request, if known yet
+	resource      string
+	resourceKnown bool
+
+	subtypeOnce sync.Once
+	subtype     string
+}
+
+func (c *rpcCall) method() string {
+	return c.fullMethod[strings.LastIndex(c.fullMethod, "/")+1:]
+}
+
+// serviceSubtype returns the resource subtype whose service the method belongs to, or an empty string
+// if it is not one.
+func (c *rpcCall) serviceSubtype() string {
+	c.subtypeOnce.Do(func() {
+		service := strings.TrimPrefix(c.fullMethod, "/")
+		service = service[:strings.LastIndex(service, "/")+1]
+		for subtype, reg := range resource.RegisteredSubtypes() {
+			if reg.RPCServiceDesc != nil && reg.RPCServiceDesc.ServiceName+"/" == service {
+				c.subtype = subtype.String()
+				return
+			}
+			for _, extra := range reg.ExtraRPCServices {
+				if extra.Desc.ServiceName+"/" == service {
+					c.subtype = subtype.String()
+					return
+				}
+			}
+		}
+	})
+	return c.subtype
+}
+
+// allows returns whether the permission allows the call. If the resource of the call is not known
+// yet, it tells whether it would allow the call for some resource.
+func allows(permission config.AuthPermissionConfig, call *rpcCall) bool {
+	if len(permission.Methods) != 0 && !containsMethod(permission.Methods, call) {
+		return false
+	}
+	if len(permission.Subtypes) != 0 && !contains(permission.Subtypes, call.serviceSubtype()) {
+		return false
+	}
+	if len(permission.Resources) != 0 && call.resourceKnown && !contains(permission.Resources, call.resource) {
+		return false
+	}
+	return true
+}
+
+func containsMethod(methods []string, call *rpcCall) bool {
+	for _, method := range methods {
+		if method == call.fullMethod || method == call.method() {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize returns a PermissionDenied error unless the allowlist allows the call. Calls whose
+// resource is not known yet are allowed if some permission could allow them.
+func (a *authorizer) authorize(call *rpcCall) error {
+	for _, permission := range a.permissions {
+		if allows(permission, call) {
+			return nil
+		}
+	}
+	if call.resource != "" {
+		return status.Errorf(codes.PermissionDenied, "calling %s on %q is not allowed", call.fullMethod, call.resource)
+	}
+	return status.Errorf(codes.PermissionDenied, "calling %s is not allowed", call.fullMethod)
+}
+
+func isAuthorizedMethod(fullMethod string) bool {
+	for _, prefix := range unauthorizedMethodPrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// requestResourceName is the name of the resource a request is addressed to, including requests for
+// resources whose services the robot only knows by reflection.
+func requestResourceName(req interface{}) string {
+	if msg, ok := req.(*dynamic.Message); ok {
+		if name, err := msg.TryGetFieldByName("name"); err == nil {
+			if nameStr, ok := name.(string); ok {
+				return nameStr
+			}
+		}
+		return ""
+	}
+	return requestResource(req)
+}
+
+// unaryServerInterceptor denies unary calls the allowlist does not allow.
+func (a *authorizer) unaryServerInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *googlegrpc.UnaryServerInfo,
+	handler googlegrpc.UnaryHandler,
+) (interface{}, error) {
+	if isAuthorizedMethod(info.FullMethod) {
+		call := &rpcCall{fullMethod: info.FullMethod, resource: requestResourceName(req), resourceKnown: true}
+		if err := a.authorize(call); err != nil {
+			return nil, err
+		}
+	}
+	return handler(ctx, req)
+}
+
+// streamServerInterceptor denies streaming calls the allowlist does not allow. Since the resource of a
+// stream is named in its first message, a call that is allowed for only some resources is checked
+// again once that message is received, and nothing can be sent on the stream before then.
+func (a *authorizer) streamServerInterceptor(
+	srv interface{},
+	ss googlegrpc.ServerStream,
+	info *googlegrpc.StreamServerInfo,
+	handler googlegrpc.StreamHandler,
+) error {
+	if !isAuthorizedMethod(info.FullMethod) {
+		return handler(srv, ss)
+	}
+	call := &rpcCall{fullMethod: info.FullMethod}
+	if err := a.authorize(call); err != nil {
+		return err
+	}
+	return handler(srv, &authorizingStream{ServerStream: ss, authorizer: a, call: call})
+}
+
+// authorizingStream authorizes a streaming call once the resource it is for is known.
+type authorizingStream struct {
+	googlegrpc.ServerStream
+	authorizer *authorizer
+	call       *rpcCall
+
+	mu  sync.Mutex
+	err error
+}
+
+func (s *authorizingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.call.resourceKnown {
+		s.call.resource = requestResourceName(m)
+		s.call.resourceKnown = true
+		s.err = s.authorizer.authorize(s.call)
+	}
+	return s.err
+}
+
+func (s *authorizingStream) SendMsg(m interface{}) error {
+	s.mu.Lock()
+	err := s.err
+	if !s.call.resourceKnown {
+		err = status.Errorf(codes.PermissionDenied, "cannot authorize %s before receiving a request", s.call.fullMethod)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
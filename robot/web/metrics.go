@@ -0,0 +1,98 @@
+package web
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	googlegrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"go.viam.com/rdk/metrics"
+)
+
+var (
+	rpcRequests = metrics.DefaultRegistry.NewCounterVec(
+		"viam_rpc_requests_total",
+		"RPCs handled by the robot, by method, resource and status code.",
+		"method", "resource", "code",
+	)
+	rpcDurations = metrics.DefaultRegistry.NewHistogramVec(
+		"viam_rpc_duration_seconds",
+		"How long the robot took to handle RPCs, by method and resource.",
+		metrics.DefaultBuckets,
+		"method", "resource",
+	)
+)
+
+// namedRequest is implemented by the requests of the RPCs of resources, which are addressed by name.
+type namedRequest interface {
+	GetName() string
+}
+
+func requestResource(req interface{}) string {
+	if named, ok := req.(namedRequest); ok {
+		return named.GetName()
+	}
+	return ""
+}
+
+func recordRPC(method, resource string, start time.Time, err error) {
+	rpcRequests.With(method, resource, status.Code(err).String()).Inc()
+	rpcDurations.With(method, resource).Observe(time.Since(start).Seconds())
+}
+
+// metricsUnaryInterceptor records the count, status and duration of unary RPCs.
+func metricsUnaryInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *googlegrpc.UnaryServerInfo,
+	handler googlegrpc.UnaryHandler,
+) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	recordRPC(info.FullMethod, requestResource(req), start, err)
+	return resp, err
+}
+
+// metricsStreamInterceptor records the count, status and duration of streaming RPCs, for the resource named in the
+// first message received on the stream.
+func metricsStreamInterceptor(
+	srv interface{},
+	ss googlegrpc.ServerStream,
+	info *googlegrpc.StreamServerInfo,
+	handler googlegrpc.StreamHandler,
+) error {
+	start := time.Now()
+	stream := &resourceRecordingStream{ServerStream: ss}
+	err := handler(srv, stream)
+	recordRPC(info.FullMethod, stream.resource(), start, err)
+	return err
+}
+
+// resourceRecordingStream remembers the resource named in the first message received on a stream.
+type resourceRecordingStream struct {
+	googlegrpc.ServerStream
+	mu       sync.Mutex
+	received bool
+	name     string
+}
+
+func (s *resourceRecordingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		if !s.received {
+			s.received = true
+			s.name = requestResource(m)
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+func (s *resourceRecordingStream) resource() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.name
+}
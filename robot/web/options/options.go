@@ -12,6 +12,7 @@ import (
 	"go.viam.com/utils/rpc"
 
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logstore"
 	"go.viam.com/rdk/utils"
 )
 
@@ -20,6 +21,14 @@ type Options struct {
 	// Pprof turns on the pprof profiler accessible at /debug
 	Pprof bool
 
+	// Metrics turns on the Prometheus metrics endpoint accessible at /metrics. Like the pprof profiler, it is not
+	// authenticated, even when the robot requires auth, and its metrics name the resources and modules of the
+	// robot, so it must only be turned on when the web server is reachable from trusted networks alone.
+	Metrics bool
+
+	// LogStore, if set, is the store of the robot's logs to serve queries of.
+	LogStore *logstore.Store
+
 	// SharedDir is the location of static web assets.
 	SharedDir string
 
@@ -42,6 +42,9 @@ import (
 	"go.viam.com/rdk/components/camera"
 	"go.viam.com/rdk/config"
 	"go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logstore"
+	logstorepb "go.viam.com/rdk/logstore/proto/v1"
+	"go.viam.com/rdk/metrics"
 	"go.viam.com/rdk/module"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
@@ -811,6 +814,17 @@ func (svc *webService) runWeb(ctx context.Context, options weboptions.Options) (
 		options.WebRTC = true
 	}
 
+	if options.LogStore != nil {
+		if err := svc.rpcServer.RegisterServiceServer(
+			ctx,
+			&logstorepb.LogStoreService_ServiceDesc,
+			logstore.NewServer(options.LogStore),
+			logstorepb.RegisterLogStoreServiceHandlerFromEndpoint,
+		); err != nil {
+			return err
+		}
+	}
+
 	if options.Debug {
 		if err := svc.rpcServer.RegisterServiceServer(
 			ctx,
@@ -913,7 +927,7 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 	}
 	var unaryInterceptors []googlegrpc.UnaryServerInterceptor
 
-	unaryInterceptors = append(unaryInterceptors, ensureTimeoutUnaryInterceptor)
+	unaryInterceptors = append(unaryInterceptors, ensureTimeoutUnaryInterceptor, metricsUnaryInterceptor)
 
 	if options.Debug {
 		rpcOpts = append(rpcOpts, rpc.WithDebug())
@@ -940,7 +954,14 @@ func (svc *webService) initRPCOptions(listenerTCPAddr *net.TCPAddr, options webo
 	}
 	rpcOpts = append(rpcOpts, authOpts...)
 
-	var streamInterceptors []googlegrpc.StreamServerInterceptor
+	streamInterceptors := []googlegrpc.StreamServerInterceptor{metricsStreamInterceptor}
+
+	// authorization applies the same to direct gRPC and WebRTC connections, since both are served
+	// through these interceptors after the caller has been authenticated.
+	if authz := newAuthorizer(options.Auth); authz != nil {
+		unaryInterceptors = append(unaryInterceptors, authz.unaryServerInterceptor)
+		streamInterceptors = append(streamInterceptors, authz.streamServerInterceptor)
+	}
 
 	opManager := svc.r.OperationManager()
 	sessManagerInts := svc.r.SessionManager().ServerInterceptors()
@@ -1110,6 +1131,13 @@ func (svc *webService) initMux(options weboptions.Options) (*goji.Mux, error) {
 		mux.HandleFunc(pat.New("/debug/pprof/trace"), pprof.Trace)
 	}
 
+	if options.Metrics {
+		if len(options.Auth.Handlers) != 0 {
+			svc.logger.Warn("/metrics is not authenticated; only serve it to trusted networks")
+		}
+		mux.Handle(pat.Get("/metrics"), metrics.DefaultRegistry)
+	}
+
 	prefix := "/viam"
 	addPrefix := func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -7,7 +7,9 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
 	"testing"
 	"time"
 
@@ -20,6 +22,8 @@ import (
 	"github.com/jhump/protoreflect/grpcreflect"
 	"github.com/lestrrat-go/jwx/jwk"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	echopb "go.viam.com/api/component/testecho/v1"
 	robotpb "go.viam.com/api/robot/v1"
 	"go.viam.com/test"
@@ -36,6 +40,8 @@ import (
 	"go.viam.com/rdk/config"
 	gizmopb "go.viam.com/rdk/examples/customresources/apis/proto/api/component/gizmo/v1"
 	rgrpc "go.viam.com/rdk/grpc"
+	"go.viam.com/rdk/logstore"
+	logstorepb "go.viam.com/rdk/logstore/proto/v1"
 	"go.viam.com/rdk/referenceframe"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/robot"
@@ -169,6 +175,123 @@ func TestWebStartOptions(t *testing.T) {
 	test.That(t, err, test.ShouldBeNil)
 }
 
+func TestWebMetrics(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	ctx, injectRobot := setupRobotCtx(t)
+
+	svc := web.New(injectRobot, logger)
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.Metrics = true
+	err := svc.Start(ctx, options)
+	test.That(t, err, test.ShouldBeNil)
+
+	conn, err := rgrpc.Dial(context.Background(), addr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	arm1, err := arm.NewClientFromConn(context.Background(), conn, arm.Named(arm1String), logger)
+	test.That(t, err, test.ShouldBeNil)
+	_, err = arm1.EndPosition(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/metrics", addr), nil)
+	test.That(t, err, test.ShouldBeNil)
+	resp, err := http.DefaultClient.Do(req)
+	test.That(t, err, test.ShouldBeNil)
+	body, err := io.ReadAll(resp.Body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.Body.Close(), test.ShouldBeNil)
+	test.That(t, resp.StatusCode, test.ShouldEqual, http.StatusOK)
+	test.That(t, string(body), test.ShouldContainSubstring,
+		`viam_rpc_requests_total{method="/viam.component.arm.v1.ArmService/GetEndPosition",resource="arm1",code="OK"}`)
+	test.That(t, string(body), test.ShouldContainSubstring,
+		`viam_rpc_duration_seconds_count{method="/viam.component.arm.v1.ArmService/GetEndPosition",resource="arm1"}`)
+
+	test.That(t, conn.Close(), test.ShouldBeNil)
+	err = svc.Close(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+}
+
+func TestWebLogStore(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	ctx, injectRobot := setupRobotCtx(t)
+
+	store, err := logstore.NewStore(t.TempDir(), logstore.DefaultMaxFileSize, logstore.DefaultMaxFiles)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, store.Close(), test.ShouldBeNil)
+	}()
+	robotLogger := zap.New(store.Core(zapcore.DebugLevel)).Sugar().Named("robot_server")
+	robotLogger.Info("started")
+	robotLogger.Named("rdk:component:arm/arm1").Warn("arm is slow")
+
+	svc := web.New(injectRobot, logger)
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.LogStore = store
+	err = svc.Start(ctx, options)
+	test.That(t, err, test.ShouldBeNil)
+
+	conn, err := rgrpc.Dial(context.Background(), addr, logger)
+	test.That(t, err, test.ShouldBeNil)
+	client := logstorepb.NewLogStoreServiceClient(conn)
+
+	resp, err := client.GetLogs(ctx, &logstorepb.GetLogsRequest{})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.Logs, test.ShouldHaveLength, 2)
+	test.That(t, resp.Logs[0].Message, test.ShouldEqual, "started")
+	test.That(t, resp.Logs[0].Level, test.ShouldEqual, "info")
+	test.That(t, resp.Logs[0].LoggerName, test.ShouldEqual, "robot_server")
+
+	resp, err = client.GetLogs(ctx, &logstorepb.GetLogsRequest{Filter: &logstorepb.LogFilter{Resource: arm1String}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, resp.Logs, test.ShouldHaveLength, 1)
+	test.That(t, resp.Logs[0].Message, test.ShouldEqual, "arm is slow")
+
+	_, err = client.GetLogs(ctx, &logstorepb.GetLogsRequest{Filter: &logstorepb.LogFilter{Level: "loud"}})
+	test.That(t, err, test.ShouldNotBeNil)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("http://%s/api/v1/logs?filter.level=warn", addr), nil)
+	test.That(t, err, test.ShouldBeNil)
+	httpResp, err := http.DefaultClient.Do(req)
+	test.That(t, err, test.ShouldBeNil)
+	body, err := io.ReadAll(httpResp.Body)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, httpResp.Body.Close(), test.ShouldBeNil)
+	test.That(t, httpResp.StatusCode, test.ShouldEqual, http.StatusOK)
+	test.That(t, string(body), test.ShouldContainSubstring, "arm is slow")
+	test.That(t, string(body), test.ShouldNotContainSubstring, "started")
+
+	tailCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	tailClient, err := client.TailLogs(tailCtx, &logstorepb.TailLogsRequest{
+		Filter: &logstorepb.LogFilter{LoggerName: "robot_server"},
+		Recent: 1,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	tailResp, err := tailClient.Recv()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tailResp.Logs, test.ShouldHaveLength, 1)
+	test.That(t, tailResp.Logs[0].Message, test.ShouldEqual, "arm is slow")
+
+	robotLogger.Named("other").Info("from a child")
+	robotLogger.Error("stopping")
+	var tailed []string
+	for len(tailed) < 2 {
+		tailResp, err = tailClient.Recv()
+		test.That(t, err, test.ShouldBeNil)
+		for _, log := range tailResp.Logs {
+			tailed = append(tailed, log.Message)
+		}
+	}
+	test.That(t, tailed, test.ShouldResemble, []string{"from a child", "stopping"})
+	cancel()
+
+	test.That(t, conn.Close(), test.ShouldBeNil)
+	err = svc.Close(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+}
+
 func TestWebWithAuth(t *testing.T) {
 	logger := golog.NewTestLogger(t)
 	ctx, injectRobot := setupRobotCtx(t)
@@ -372,6 +495,95 @@ func TestWebWithAuth(t *testing.T) {
 	}
 }
 
+func TestWebWithAuthorization(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	ctx, injectRobot := setupRobotCtx(t)
+	var moved bool
+	injectArm := &inject.Arm{}
+	injectArm.EndPositionFunc = func(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+		return pos, nil
+	}
+	injectArm.MoveToPositionFunc = func(ctx context.Context, to spatialmath.Pose, extra map[string]interface{}) error {
+		moved = true
+		return nil
+	}
+	injectRobot.(*inject.Robot).ResourceByNameFunc = func(name resource.Name) (resource.Resource, error) {
+		return injectArm, nil
+	}
+
+	svc := web.New(injectRobot, logger)
+
+	options, _, addr := robottestutils.CreateBaseOptionsAndListener(t)
+	options.FQDN = "something-different"
+	options.LocalFQDN = primitive.NewObjectID().Hex()
+	apiKey := "sosecret"
+	options.Auth.Handlers = []config.AuthHandlerConfig{
+		{
+			Type:   rpc.CredentialsTypeAPIKey,
+			Config: rutils.AttributeMap{"key": apiKey},
+		},
+	}
+	options.Auth.Allow = []config.AuthPermissionConfig{
+		{Subtypes: []string{arm.Subtype.String()}, Methods: []string{"GetEndPosition"}},
+		{Methods: []string{"/viam.robot.v1.RobotService/ResourceNames"}},
+	}
+
+	err := svc.Start(ctx, options)
+	test.That(t, err, test.ShouldBeNil)
+
+	checkAllowed := func(t *testing.T, conn rpc.ClientConn) {
+		t.Helper()
+		arm1, err := arm.NewClientFromConn(context.Background(), conn, arm.Named(arm1String), logger)
+		test.That(t, err, test.ShouldBeNil)
+		arm1Position, err := arm1.EndPosition(ctx, nil)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, arm1Position, test.ShouldResemble, pos)
+
+		err = arm1.MoveToPosition(ctx, pos, nil)
+		test.That(t, err, test.ShouldNotBeNil)
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+		test.That(t, moved, test.ShouldBeFalse)
+
+		robotClient := robotpb.NewRobotServiceClient(conn)
+		_, err = robotClient.ResourceNames(ctx, &robotpb.ResourceNamesRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		statusStream, err := robotClient.StreamStatus(ctx, &robotpb.StreamStatusRequest{})
+		test.That(t, err, test.ShouldBeNil)
+		_, err = statusStream.Recv()
+		test.That(t, status.Code(err), test.ShouldEqual, codes.PermissionDenied)
+	}
+
+	t.Run("direct grpc", func(t *testing.T) {
+		conn, err := rgrpc.Dial(context.Background(), addr, logger,
+			rpc.WithForceDirectGRPC(),
+			rpc.WithAllowInsecureWithCredentialsDowngrade(),
+			rpc.WithCredentials(rpc.Credentials{Type: rpc.CredentialsTypeAPIKey, Payload: apiKey}),
+		)
+		test.That(t, err, test.ShouldBeNil)
+		checkAllowed(t, conn)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
+
+	t.Run("webrtc", func(t *testing.T) {
+		conn, err := rgrpc.Dial(context.Background(), addr, logger,
+			rpc.WithDisableDirectGRPC(),
+			rpc.WithAllowInsecureWithCredentialsDowngrade(),
+			rpc.WithWebRTCOptions(rpc.DialWebRTCOptions{
+				SignalingServerAddress: addr,
+				SignalingInsecure:      true,
+				SignalingAuthEntity:    options.FQDN,
+				SignalingCreds:         rpc.Credentials{Type: rpc.CredentialsTypeAPIKey, Payload: apiKey},
+			}),
+		)
+		test.That(t, err, test.ShouldBeNil)
+		checkAllowed(t, conn)
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	})
+
+	err = svc.Close(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+}
+
 func TestWebWithTLSAuth(t *testing.T) {
 	logger := golog.NewTestLogger(t)
 	ctx, injectRobot := setupRobotCtx(t)
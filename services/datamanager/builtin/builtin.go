@@ -15,6 +15,7 @@ import (
 	v1 "go.viam.com/api/app/datasync/v1"
 	"go.viam.com/rdk/data"
 	"go.viam.com/rdk/internal/cloud"
+	"go.viam.com/rdk/metrics"
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/datamanager"
@@ -58,6 +59,11 @@ const defaultCaptureBufferSize = 4096
 
 var clock = clk.New()
 
+var syncBacklog = metrics.DefaultRegistry.NewGaugeVec(
+	"viam_datasync_backlog_files",
+	"Files waiting to be synced to the cloud as of the last sync.",
+).With()
+
 var errCaptureDirectoryConfigurationDisabled = errors.New("changing the capture directory is prohibited in this environment")
 
 // Config describes how to configure the service.
@@ -253,6 +259,7 @@ func (svc *builtIn) initializeOrUpdateCollector(
 	}
 	params := data.CollectorParams{
 		ComponentName: config.Name.ShortName(),
+		MethodName:    captureMetadata.GetMethodName(),
 		Interval:      interval,
 		MethodParams:  methodParams,
 		Target:        datacapture.NewBuffer(targetDir, captureMetadata),
@@ -506,6 +513,7 @@ func (svc *builtIn) sync() {
 	for _, ap := range svc.additionalSyncPaths {
 		toSync = append(toSync, getAllFilesToSync(ap, svc.waitAfterLastModifiedMillis)...)
 	}
+	syncBacklog.Set(float64(len(toSync)))
 	for _, p := range toSync {
 		svc.syncer.SyncFile(p)
 	}
@@ -2,6 +2,7 @@ package builtin
 
 import (
 	"context"
+	"image"
 	"io"
 	"os"
 	"path/filepath"
@@ -12,14 +13,19 @@ import (
 
 	clk "github.com/benbjohnson/clock"
 	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
 	"github.com/pkg/errors"
 	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/components/camera/videorecorder"
 	"go.viam.com/rdk/internal/cloud"
 	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
 	"go.viam.com/rdk/services/datamanager/datacapture"
 	"go.viam.com/rdk/services/datamanager/datasync"
 	"go.viam.com/test"
 	"go.viam.com/utils/rpc"
+	"go.viam.com/utils/testutils"
 	"google.golang.org/grpc"
 )
 
@@ -455,6 +461,51 @@ func TestArbitraryFileUpload(t *testing.T) {
 	}
 }
 
+// The segments of a video recorder are synced by adding its output directory to the additional sync paths, which
+// must only pick up the segments once they are complete.
+func TestVideoRecorderSegmentsAreSynced(t *testing.T) {
+	ctx := context.Background()
+	src, err := camera.NewVideoSourceFromReader(ctx, gostream.VideoReaderFunc(
+		func(ctx context.Context) (image.Image, func(), error) {
+			return rimage.NewImage(32, 16), func() {}, nil
+		}), nil, camera.ColorStream)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, src.Close(ctx), test.ShouldBeNil)
+	}()
+	deps := resource.Dependencies{camera.Named("cam"): camera.FromVideoSource(camera.Named("cam"), src)}
+
+	outputDir := filepath.Join(t.TempDir(), "recordings")
+	model := resource.NewDefaultModel("video_recorder")
+	reg, ok := resource.LookupRegistration(camera.Subtype, model)
+	test.That(t, ok, test.ShouldBeTrue)
+	rec, err := reg.Constructor(ctx, deps, resource.Config{
+		Name:                "rec",
+		API:                 camera.Subtype,
+		Model:               model,
+		ConvertedAttributes: &videorecorder.Config{SourceCamera: "cam", OutputDirectory: outputDir, FrameRate: 50},
+	}, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, rec.Close(ctx), test.ShouldBeNil)
+	}()
+
+	// the segment being recorded is not synced
+	_, err = rec.DoCommand(ctx, map[string]interface{}{videorecorder.Command: videorecorder.CommandStart})
+	test.That(t, err, test.ShouldBeNil)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, getAllFilePaths(outputDir+".partial"), test.ShouldNotBeEmpty)
+	})
+	test.That(t, getAllFilesToSync(outputDir, 0), test.ShouldBeEmpty)
+
+	// and is once completed
+	resp, err := rec.DoCommand(ctx, map[string]interface{}{videorecorder.Command: videorecorder.CommandStop})
+	test.That(t, err, test.ShouldBeNil)
+	path, ok := resp[videorecorder.ResultSegment].(string)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, getAllFilesToSync(outputDir, 0), test.ShouldResemble, []string{path})
+}
+
 func getAllFilePaths(dir string) []string {
 	var filePaths []string
 
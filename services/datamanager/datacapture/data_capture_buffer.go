@@ -4,6 +4,14 @@ import (
 	"sync"
 
 	v1 "go.viam.com/api/app/datasync/v1"
+
+	"go.viam.com/rdk/metrics"
+)
+
+var bytesWritten = metrics.DefaultRegistry.NewCounterVec(
+	"viam_datacapture_written_bytes_total",
+	"Bytes of captured readings written to disk, by component and method.",
+	"component", "method",
 )
 
 // MaxFileSize is the maximum size in bytes of a data capture file.
@@ -44,7 +52,7 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 		if err != nil {
 			return err
 		}
-		if err := binFile.WriteNext(item); err != nil {
+		if err := b.writeNext(binFile, item); err != nil {
 			return err
 		}
 		if err := binFile.Close(); err != nil {
@@ -70,7 +78,17 @@ func (b *Buffer) Write(item *v1.SensorData) error {
 		b.nextFile = nextFile
 	}
 
-	return b.nextFile.WriteNext(item)
+	return b.writeNext(b.nextFile, item)
+}
+
+// writeNext writes item to f, counting the bytes written.
+func (b *Buffer) writeNext(f *File, item *v1.SensorData) error {
+	before := f.Size()
+	if err := f.WriteNext(item); err != nil {
+		return err
+	}
+	bytesWritten.With(b.MetaData.GetComponentName(), b.MetaData.GetMethodName()).Add(float64(f.Size() - before))
+	return nil
 }
 
 // Flush flushes all buffered data to disk and marks any in progress file as complete.
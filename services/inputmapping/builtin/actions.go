@@ -0,0 +1,446 @@
+package builtin
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	vutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/utils"
+)
+
+// The types of action a binding can run.
+const (
+	actionMotorPower  = "motor_power"
+	actionServoAngle  = "servo_angle"
+	actionGripperGrab = "gripper_grab"
+	actionGripperOpen = "gripper_open"
+	actionArmJog      = "arm_jog"
+	actionDoCommand   = "do_command"
+)
+
+// jogRepeatPeriod is how often the jog of an arm is repeated while it moves. It must be shorter than the timeout
+// after which the motion service stops jogging a component, so that a lost controller stops the arm.
+const jogRepeatPeriod = 200 * time.Millisecond
+
+// jogAxes are the directions an arm_jog action can jog in: x, y and z are linear, in mm/s, and rx, ry and rz are
+// angular, in deg/s.
+var jogAxes = map[string]struct {
+	linear, angular r3.Vector
+}{
+	"x":  {linear: r3.Vector{X: 1}},
+	"y":  {linear: r3.Vector{Y: 1}},
+	"z":  {linear: r3.Vector{Z: 1}},
+	"rx": {angular: r3.Vector{X: 1}},
+	"ry": {angular: r3.Vector{Y: 1}},
+	"rz": {angular: r3.Vector{Z: 1}},
+}
+
+// ActionConfig describes the action a binding runs, and the resource it runs it on.
+type ActionConfig struct {
+	Type     string `json:"type"`
+	Resource string `json:"resource"`
+
+	// Power is the power of a motor_power action at full deflection, from -1 to 1. It defaults to 1.
+	Power float64 `json:"power,omitempty"`
+
+	// MinAngleDeg and MaxAngleDeg are the angles a servo_angle action moves the servo between. They default to
+	// the full range of 0 to 180 degrees.
+	MinAngleDeg float64 `json:"min_angle_deg,omitempty"`
+	MaxAngleDeg float64 `json:"max_angle_deg,omitempty"`
+
+	// MotionService is the motion service an arm_jog action jogs the arm with. It defaults to the builtin one.
+	MotionService string `json:"motion_service,omitempty"`
+	// Axis is the direction an arm_jog action jogs in: x, y, z, rx, ry or rz.
+	Axis string `json:"axis,omitempty"`
+	// Speed is the speed of an arm_jog action at full deflection, in mm/s along x, y and z and deg/s around them.
+	Speed float64 `json:"speed,omitempty"`
+
+	// Command is sent to the resource by a do_command action. If ValueKey is set, the value of the control is
+	// added to the command under that key; otherwise the command is only sent when the control is active.
+	Command  map[string]interface{} `json:"command,omitempty"`
+	ValueKey string                 `json:"value_key,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid, and returns the resources the action depends on.
+func (conf *ActionConfig) Validate(path string) ([]string, error) {
+	if conf.Type == "" {
+		return nil, vutils.NewConfigValidationFieldRequiredError(path, "type")
+	}
+	if conf.Resource == "" {
+		return nil, vutils.NewConfigValidationFieldRequiredError(path, "resource")
+	}
+	switch conf.Type {
+	case actionMotorPower:
+		if conf.Power < -1 || conf.Power > 1 {
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("power must be between -1 and 1, got %v", conf.Power))
+		}
+		return []string{motor.Named(conf.Resource).String()}, nil
+	case actionServoAngle:
+		if conf.MinAngleDeg < 0 || conf.MaxAngleDeg > 180 || conf.MinAngleDeg > conf.maxAngleDeg() {
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf(
+				"min_angle_deg (%v) and max_angle_deg (%v) must be in order between 0 and 180", conf.MinAngleDeg, conf.MaxAngleDeg))
+		}
+		return []string{servo.Named(conf.Resource).String()}, nil
+	case actionGripperGrab, actionGripperOpen:
+		return []string{gripper.Named(conf.Resource).String()}, nil
+	case actionArmJog:
+		if _, ok := jogAxes[conf.Axis]; !ok {
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("axis must be one of x, y, z, rx, ry or rz, got %q", conf.Axis))
+		}
+		if conf.Speed <= 0 {
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("speed must be positive, got %v", conf.Speed))
+		}
+		return []string{motion.Named(conf.motionService()).String()}, nil
+	case actionDoCommand:
+		if len(conf.Command) == 0 {
+			return nil, vutils.NewConfigValidationFieldRequiredError(path, "command")
+		}
+		return []string{conf.Resource}, nil
+	default:
+		return nil, vutils.NewConfigValidationError(path, errors.Errorf("unknown action type %q", conf.Type))
+	}
+}
+
+func (conf *ActionConfig) power() float64 {
+	if conf.Power == 0 {
+		return 1
+	}
+	return conf.Power
+}
+
+func (conf *ActionConfig) maxAngleDeg() float64 {
+	if conf.MinAngleDeg == 0 && conf.MaxAngleDeg == 0 {
+		return 180
+	}
+	return conf.MaxAngleDeg
+}
+
+func (conf *ActionConfig) motionService() string {
+	if conf.MotionService == "" {
+		return resource.DefaultServiceName
+	}
+	return conf.MotionService
+}
+
+// An action is what a binding runs when the value of its control changes.
+type action interface {
+	// run runs the action with the value of the control, from -1 to 1 for axes, and 0 or 1 for buttons.
+	run(ctx context.Context, value float64) error
+	// stop stops whatever the action set in motion.
+	stop(ctx context.Context) error
+	// resource returns the resource the action runs on.
+	resource() resource.Resource
+}
+
+// newAction creates the action of the binding with the given id. Arm jogs share the joggers of their arms.
+func newAction(
+	conf *ActionConfig,
+	id int,
+	isAxis bool,
+	deps resource.Dependencies,
+	joggers *armJoggers,
+) (action, error) {
+	switch conf.Type {
+	case actionMotorPower:
+		m, err := motor.FromDependencies(deps, conf.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return &motorPowerAction{motor: m, power: conf.power()}, nil
+	case actionServoAngle:
+		s, err := resource.FromDependencies[servo.Servo](deps, servo.Named(conf.Resource))
+		if err != nil {
+			return nil, err
+		}
+		return &servoAngleAction{servo: s, min: conf.MinAngleDeg, max: conf.maxAngleDeg(), isAxis: isAxis}, nil
+	case actionGripperGrab, actionGripperOpen:
+		g, err := resource.FromDependencies[gripper.Gripper](deps, gripper.Named(conf.Resource))
+		if err != nil {
+			return nil, err
+		}
+		return &gripperAction{gripper: g, grab: conf.Type == actionGripperGrab}, nil
+	case actionArmJog:
+		ms, err := resource.FromDependencies[motion.Service](deps, motion.Named(conf.motionService()))
+		if err != nil {
+			return nil, err
+		}
+		axis := jogAxes[conf.Axis]
+		return &armJogAction{
+			jogger:  joggers.get(ms, conf.Resource),
+			id:      id,
+			linear:  axis.linear.Mul(conf.Speed),
+			angular: axis.angular.Mul(utils.DegToRad(conf.Speed)),
+		}, nil
+	case actionDoCommand:
+		res, err := lookupDependency(deps, conf.Resource)
+		if err != nil {
+			return nil, err
+		}
+		return &doCommandAction{res: res, command: conf.Command, valueKey: conf.ValueKey}, nil
+	default:
+		return nil, errors.Errorf("unknown action type %q", conf.Type)
+	}
+}
+
+// lookupDependency finds a dependency by its full name, or by its short name if that is not ambiguous.
+func lookupDependency(deps resource.Dependencies, name string) (resource.Resource, error) {
+	if fullName, err := resource.NewFromString(name); err == nil {
+		return deps.Lookup(fullName)
+	}
+	var found []resource.Name
+	for depName := range deps {
+		if depName.ShortName() == name {
+			found = append(found, depName)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, errors.Errorf("no dependency named %q", name)
+	case 1:
+		return deps[found[0]], nil
+	default:
+		return nil, errors.Errorf("dependency name %q is ambiguous between %v", name, found)
+	}
+}
+
+// motorPowerAction sets the power of a motor in proportion to the value.
+type motorPowerAction struct {
+	motor motor.Motor
+	power float64
+}
+
+func (a *motorPowerAction) run(ctx context.Context, value float64) error {
+	if value == 0 {
+		return a.motor.Stop(ctx, nil)
+	}
+	return a.motor.SetPower(ctx, a.power*value, nil)
+}
+
+func (a *motorPowerAction) stop(ctx context.Context) error {
+	return a.motor.Stop(ctx, nil)
+}
+
+func (a *motorPowerAction) resource() resource.Resource {
+	return a.motor
+}
+
+// servoAngleAction moves a servo between its angles. Buttons move it to the max angle while active and the min angle
+// otherwise, and axes move it over the whole range, with the center of the axis at the middle of the range.
+type servoAngleAction struct {
+	servo    servo.Servo
+	min, max float64
+	isAxis   bool
+}
+
+func (a *servoAngleAction) run(ctx context.Context, value float64) error {
+	fraction := value
+	if a.isAxis {
+		fraction = (value + 1) / 2
+	}
+	return a.servo.Move(ctx, uint32(math.Round(a.min+(a.max-a.min)*fraction)), nil)
+}
+
+// stop leaves the servo where it is, since it holds its position.
+func (a *servoAngleAction) stop(ctx context.Context) error {
+	return nil
+}
+
+func (a *servoAngleAction) resource() resource.Resource {
+	return a.servo
+}
+
+// gripperAction grabs with (or opens) a gripper while the value is active, and does the opposite otherwise.
+type gripperAction struct {
+	gripper gripper.Gripper
+	grab    bool
+}
+
+func (a *gripperAction) run(ctx context.Context, value float64) error {
+	if (value != 0) == a.grab {
+		_, err := a.gripper.Grab(ctx, nil)
+		return err
+	}
+	return a.gripper.Open(ctx, nil)
+}
+
+func (a *gripperAction) stop(ctx context.Context) error {
+	return a.gripper.Stop(ctx, nil)
+}
+
+func (a *gripperAction) resource() resource.Resource {
+	return a.gripper
+}
+
+// doCommandAction sends a command to a resource.
+type doCommandAction struct {
+	res      resource.Resource
+	command  map[string]interface{}
+	valueKey string
+}
+
+func (a *doCommandAction) run(ctx context.Context, value float64) error {
+	if a.valueKey == "" {
+		if value == 0 {
+			return nil
+		}
+		_, err := a.res.DoCommand(ctx, a.command)
+		return err
+	}
+	command := make(map[string]interface{}, len(a.command)+1)
+	for k, v := range a.command {
+		command[k] = v
+	}
+	command[a.valueKey] = value
+	_, err := a.res.DoCommand(ctx, command)
+	return err
+}
+
+func (a *doCommandAction) stop(ctx context.Context) error {
+	return nil
+}
+
+func (a *doCommandAction) resource() resource.Resource {
+	return a.res
+}
+
+// armJogAction jogs an arm along or around one axis, at a speed in proportion to the value.
+type armJogAction struct {
+	jogger          *armJogger
+	id              int
+	linear, angular r3.Vector
+}
+
+func (a *armJogAction) run(ctx context.Context, value float64) error {
+	a.jogger.set(a.id, a.linear.Mul(value), a.angular.Mul(value))
+	return nil
+}
+
+func (a *armJogAction) stop(ctx context.Context) error {
+	a.jogger.set(a.id, r3.Vector{}, r3.Vector{})
+	return nil
+}
+
+func (a *armJogAction) resource() resource.Resource {
+	return a.jogger.motion
+}
+
+type jogKey struct {
+	motion    resource.Name
+	component string
+}
+
+// armJoggers holds the jogger of every arm jogged by the bindings.
+type armJoggers struct {
+	logger  golog.Logger
+	joggers map[jogKey]*armJogger
+}
+
+func (j *armJoggers) get(ms motion.Service, component string) *armJogger {
+	key := jogKey{motion: ms.Name(), component: component}
+	jogger, ok := j.joggers[key]
+	if !ok {
+		jogger = &armJogger{
+			motion:    ms,
+			component: component,
+			logger:    j.logger,
+			linear:    map[int]r3.Vector{},
+			angular:   map[int]r3.Vector{},
+			changed:   make(chan struct{}, 1),
+		}
+		j.joggers[key] = jogger
+	}
+	return jogger
+}
+
+// An armJogger jogs an arm with the sum of the velocities of all the bindings jogging it, since every jog command
+// replaces the whole velocity of the arm, and repeats the jog for as long as the arm moves.
+type armJogger struct {
+	motion    motion.Service
+	component string
+	logger    golog.Logger
+
+	mu      sync.Mutex
+	linear  map[int]r3.Vector
+	angular map[int]r3.Vector
+	changed chan struct{}
+}
+
+// set sets the velocity the binding with the given id contributes.
+func (j *armJogger) set(id int, linear, angular r3.Vector) {
+	j.mu.Lock()
+	j.linear[id] = linear
+	j.angular[id] = angular
+	j.mu.Unlock()
+	select {
+	case j.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (j *armJogger) velocity() (r3.Vector, r3.Vector) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var linear, angular r3.Vector
+	for _, v := range j.linear {
+		linear = linear.Add(v)
+	}
+	for _, v := range j.angular {
+		angular = angular.Add(v)
+	}
+	return linear, angular
+}
+
+// run jogs the arm until the context is done, and then stops it if it is moving.
+func (j *armJogger) run(ctx context.Context) {
+	ticker := time.NewTicker(jogRepeatPeriod)
+	defer ticker.Stop()
+	var jogging bool
+	for {
+		select {
+		case <-ctx.Done():
+			if jogging {
+				j.stopJog(context.Background())
+			}
+			return
+		case <-j.changed:
+		case <-ticker.C:
+		}
+		linear, angular := j.velocity()
+		if linear == (r3.Vector{}) && angular == (r3.Vector{}) {
+			if jogging {
+				j.stopJog(ctx)
+				jogging = false
+			}
+			continue
+		}
+		jogging = true
+		if _, err := j.motion.DoCommand(ctx, map[string]interface{}{
+			"command":        "jog",
+			"component_name": j.component,
+			"linear":         map[string]interface{}{"x": linear.X, "y": linear.Y, "z": linear.Z},
+			"angular":        map[string]interface{}{"x": angular.X, "y": angular.Y, "z": angular.Z},
+		}); err != nil {
+			j.logger.Errorw("error jogging", "component", j.component, "error", err)
+		}
+	}
+}
+
+func (j *armJogger) stopJog(ctx context.Context) {
+	if _, err := j.motion.DoCommand(ctx, map[string]interface{}{
+		"command":        "stop_jog",
+		"component_name": j.component,
+	}); err != nil {
+		j.logger.Errorw("error stopping jog", "component", j.component, "error", err)
+	}
+}
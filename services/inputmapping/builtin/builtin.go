@@ -0,0 +1,563 @@
+// Package builtin implements an input mapping service that binds controls to actions declared in its config.
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	vutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/inputmapping"
+	"go.viam.com/rdk/session"
+)
+
+func init() {
+	resource.RegisterService(inputmapping.Subtype, resource.DefaultServiceModel, resource.Registration[inputmapping.Service, *Config]{
+		Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger golog.Logger,
+		) (inputmapping.Service, error) {
+			return NewBuiltIn(ctx, deps, conf, logger)
+		},
+	})
+}
+
+// The modes of a binding of a button. A press binding runs its action when the button is pressed, and a release
+// binding when it is released. A hold binding runs its action while the button is held, and a toggle binding
+// alternates between running it and not on every press.
+const (
+	modePress   = "press"
+	modeRelease = "release"
+	modeHold    = "hold"
+	modeToggle  = "toggle"
+)
+
+// The response curves of a binding of an axis.
+const (
+	curveLinear    = "linear"
+	curveQuadratic = "quadratic"
+	curveCubic     = "cubic"
+	curveExpo      = "expo"
+)
+
+// Config describes how to configure the service.
+type Config struct {
+	InputControllerName string          `json:"input_controller"`
+	Bindings            []BindingConfig `json:"bindings"`
+}
+
+// BindingConfig binds a control of the input controller to an action. If modifiers are given, the binding is a
+// chord that only applies while all of them are held; when several bindings of a control apply, the one with the
+// most modifiers wins.
+type BindingConfig struct {
+	Control   string   `json:"control"`
+	Modifiers []string `json:"modifiers,omitempty"`
+	// Mode is the mode of a button binding: press (the default), release, hold or toggle.
+	Mode string `json:"mode,omitempty"`
+
+	// Deadzone is the fraction of the travel of an axis around its center that is ignored.
+	Deadzone float64 `json:"deadzone,omitempty"`
+	// ResponseCurve shapes the value of an axis: linear (the default), quadratic, cubic, or expo, which blends
+	// linear and cubic by the Expo fraction.
+	ResponseCurve string  `json:"response_curve,omitempty"`
+	Expo          float64 `json:"expo,omitempty"`
+	Invert        bool    `json:"invert,omitempty"`
+
+	Action ActionConfig `json:"action"`
+}
+
+// isAxis returns whether the control is an axis rather than a button.
+func isAxis(control input.Control) bool {
+	return strings.HasPrefix(string(control), "Absolute")
+}
+
+// Validate ensures all parts of the binding are valid, and returns the resources its action depends on.
+func (conf *BindingConfig) Validate(path string) ([]string, error) {
+	if conf.Control == "" {
+		return nil, vutils.NewConfigValidationFieldRequiredError(path, "control")
+	}
+	for _, modifier := range conf.Modifiers {
+		if isAxis(input.Control(modifier)) || modifier == conf.Control {
+			return nil, vutils.NewConfigValidationError(path,
+				errors.Errorf("modifier %q must be a button other than the control", modifier))
+		}
+	}
+	if isAxis(input.Control(conf.Control)) {
+		if conf.Mode != "" {
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("axis %q cannot have a mode", conf.Control))
+		}
+		if conf.Deadzone < 0 || conf.Deadzone >= 1 {
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("deadzone must be in [0, 1), got %v", conf.Deadzone))
+		}
+		switch conf.ResponseCurve {
+		case "", curveLinear, curveQuadratic, curveCubic, curveExpo:
+		default:
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("unknown response curve %q", conf.ResponseCurve))
+		}
+		if conf.Expo < 0 || conf.Expo > 1 {
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("expo must be in [0, 1], got %v", conf.Expo))
+		}
+	} else {
+		switch conf.Mode {
+		case "", modePress, modeRelease, modeHold, modeToggle:
+		default:
+			return nil, vutils.NewConfigValidationError(path, errors.Errorf("unknown mode %q", conf.Mode))
+		}
+		if conf.Deadzone != 0 || conf.ResponseCurve != "" || conf.Expo != 0 || conf.Invert {
+			return nil, vutils.NewConfigValidationError(path,
+				errors.Errorf("button %q cannot have a deadzone, response curve or inversion", conf.Control))
+		}
+	}
+	return conf.Action.Validate(path + ".action")
+}
+
+// Validate creates the list of implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.InputControllerName == "" {
+		return nil, vutils.NewConfigValidationFieldRequiredError(path, "input_controller")
+	}
+	deps := []string{conf.InputControllerName}
+	seen := map[string]bool{conf.InputControllerName: true}
+	for i := range conf.Bindings {
+		bindingDeps, err := conf.Bindings[i].Validate(fmt.Sprintf("%s.bindings.%d", path, i))
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range bindingDeps {
+			if !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// builtIn is the structure of the input mapping service.
+type builtIn struct {
+	resource.Named
+
+	mu      sync.Mutex
+	mapping *mapping
+	logger  golog.Logger
+}
+
+// NewBuiltIn returns a new input mapping service.
+func NewBuiltIn(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+	logger golog.Logger,
+) (inputmapping.Service, error) {
+	svc := &builtIn{
+		Named:  conf.ResourceName().AsNamed(),
+		logger: logger,
+	}
+	if err := svc.Reconfigure(ctx, deps, conf); err != nil {
+		return nil, err
+	}
+	return svc, nil
+}
+
+// Reconfigure replaces the bindings of the service, stopping everything the old ones set in motion.
+func (svc *builtIn) Reconfigure(
+	ctx context.Context,
+	deps resource.Dependencies,
+	conf resource.Config,
+) error {
+	svcConfig, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return err
+	}
+	newMapping, err := newMapping(deps, svcConfig, svc.logger)
+	if err != nil {
+		return err
+	}
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	// The old mapping must be closed first: closing it unregisters its callbacks, which may be on the same controls.
+	if svc.mapping != nil {
+		if err := svc.mapping.close(ctx); err != nil {
+			svc.logger.Errorw("error closing old input mapping", "error", err)
+		}
+		svc.mapping = nil
+	}
+	if err := newMapping.start(ctx); err != nil {
+		return multierr.Combine(errors.Wrap(err, "error with starting input mapping service"), newMapping.close(ctx))
+	}
+	svc.mapping = newMapping
+	return nil
+}
+
+// ControllerInputs returns the list of inputs from the controller that are being monitored.
+func (svc *builtIn) ControllerInputs() []input.Control {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.mapping == nil {
+		return []input.Control{}
+	}
+	return append([]input.Control{}, svc.mapping.controls...)
+}
+
+// Close stops everything the bindings set in motion.
+func (svc *builtIn) Close(ctx context.Context) error {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.mapping == nil {
+		return nil
+	}
+	err := svc.mapping.close(ctx)
+	svc.mapping = nil
+	return err
+}
+
+// A binding is a BindingConfig with its action. Its action runs in the background, so that slow actions neither
+// block the controller nor each other; only the latest value of the control is kept while the action runs.
+type binding struct {
+	conf      *BindingConfig
+	control   input.Control
+	modifiers []input.Control
+	isAxis    bool
+	action    action
+
+	// toggled is guarded by the mutex of the mapping.
+	toggled bool
+
+	mu       sync.Mutex
+	value    float64
+	stopping bool
+	changed  chan struct{}
+}
+
+// shape applies the deadzone, inversion and response curve of the binding to the value of an axis.
+func (b *binding) shape(value float64) float64 {
+	if b.conf.Invert {
+		value = -value
+	}
+	magnitude := math.Min(math.Abs(value), 1)
+	if magnitude <= b.conf.Deadzone {
+		return 0
+	}
+	magnitude = (magnitude - b.conf.Deadzone) / (1 - b.conf.Deadzone)
+	switch b.conf.ResponseCurve {
+	case curveQuadratic:
+		magnitude *= magnitude
+	case curveCubic:
+		magnitude *= magnitude * magnitude
+	case curveExpo:
+		magnitude = (1-b.conf.Expo)*magnitude + b.conf.Expo*magnitude*magnitude*magnitude
+	}
+	return math.Copysign(magnitude, value)
+}
+
+// send has the action run with the value.
+func (b *binding) send(ctx context.Context, value float64) {
+	b.mu.Lock()
+	b.value = value
+	b.stopping = false
+	b.mu.Unlock()
+	b.signal()
+	session.SafetyMonitor(ctx, b.action.resource())
+}
+
+// requestStop has the action stop.
+func (b *binding) requestStop() {
+	b.mu.Lock()
+	b.stopping = true
+	b.mu.Unlock()
+	b.signal()
+}
+
+func (b *binding) signal() {
+	// If we do not manage to signal, the worker has yet to see the last signal, and will see our value with it.
+	select {
+	case b.changed <- struct{}{}:
+	default:
+	}
+}
+
+// work runs the action with the latest values of the control until the context is done.
+func (b *binding) work(ctx context.Context, logger golog.Logger) {
+	var last float64
+	var applied bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.changed:
+		}
+		b.mu.Lock()
+		value, stopping := b.value, b.stopping
+		b.stopping = false
+		b.mu.Unlock()
+
+		if stopping {
+			applied = false
+			if err := b.action.stop(ctx); err != nil {
+				logger.Errorw("error stopping action", "control", b.control, "action", b.conf.Action.Type, "error", err)
+			}
+			continue
+		}
+		// Axes report small changes all the time, so only changes of the shaped value are worth acting on.
+		if b.isAxis && applied && value == last {
+			continue
+		}
+		last, applied = value, true
+		if err := b.action.run(ctx, value); err != nil {
+			logger.Errorw("error running action", "control", b.control, "action", b.conf.Action.Type, "error", err)
+		}
+	}
+}
+
+// A mapping is the controller and bindings of one configuration of the service.
+type mapping struct {
+	controller input.Controller
+	bindings   []*binding
+	joggers    *armJoggers
+	controls   []input.Control
+	logger     golog.Logger
+
+	// mu guards the state of the controls.
+	mu         sync.Mutex
+	pressed    map[input.Control]bool
+	held       map[input.Control]*binding
+	activeAxes map[input.Control]*binding
+
+	cancelCtx               context.Context
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+func newMapping(deps resource.Dependencies, conf *Config, logger golog.Logger) (*mapping, error) {
+	controller, err := input.FromDependencies(deps, conf.InputControllerName)
+	if err != nil {
+		return nil, err
+	}
+	m := &mapping{
+		controller: controller,
+		joggers:    &armJoggers{logger: logger, joggers: map[jogKey]*armJogger{}},
+		logger:     logger,
+		pressed:    map[input.Control]bool{},
+		held:       map[input.Control]*binding{},
+		activeAxes: map[input.Control]*binding{},
+	}
+
+	controls := map[input.Control]bool{}
+	for i := range conf.Bindings {
+		bindingConf := &conf.Bindings[i]
+		b := &binding{
+			conf:    bindingConf,
+			control: input.Control(bindingConf.Control),
+			isAxis:  isAxis(input.Control(bindingConf.Control)),
+			changed: make(chan struct{}, 1),
+		}
+		b.action, err = newAction(&bindingConf.Action, i, b.isAxis, deps, m.joggers)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot create the action bound to %q", bindingConf.Control)
+		}
+		controls[b.control] = true
+		for _, modifier := range bindingConf.Modifiers {
+			b.modifiers = append(b.modifiers, input.Control(modifier))
+			controls[input.Control(modifier)] = true
+		}
+		m.bindings = append(m.bindings, b)
+	}
+	for control := range controls {
+		m.controls = append(m.controls, control)
+	}
+	sort.Slice(m.controls, func(i, j int) bool { return m.controls[i] < m.controls[j] })
+	return m, nil
+}
+
+// triggers returns the events of the control the mapping listens to.
+func triggers(control input.Control) []input.EventType {
+	if isAxis(control) {
+		return []input.EventType{input.PositionChangeAbs, input.Connect, input.Disconnect}
+	}
+	return []input.EventType{input.ButtonChange, input.Connect, input.Disconnect}
+}
+
+// start starts the background workers of the mapping, and registers its callbacks.
+func (m *mapping) start(ctx context.Context) error {
+	m.cancelCtx, m.cancel = context.WithCancel(context.Background())
+	for _, b := range m.bindings {
+		b := b
+		m.activeBackgroundWorkers.Add(1)
+		vutils.ManagedGo(func() {
+			b.work(m.cancelCtx, m.logger)
+		}, m.activeBackgroundWorkers.Done)
+	}
+	for _, jogger := range m.joggers.joggers {
+		jogger := jogger
+		m.activeBackgroundWorkers.Add(1)
+		vutils.ManagedGo(func() {
+			jogger.run(m.cancelCtx)
+		}, m.activeBackgroundWorkers.Done)
+	}
+
+	for _, control := range m.controls {
+		if err := m.controller.RegisterControlCallback(ctx, control, triggers(control), m.handleEvent, map[string]interface{}{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close unregisters the callbacks of the mapping, and stops its workers and everything its actions set in motion.
+func (m *mapping) close(ctx context.Context) error {
+	var err error
+	for _, control := range m.controls {
+		err = multierr.Combine(err, m.controller.RegisterControlCallback(ctx, control, triggers(control), nil, map[string]interface{}{}))
+	}
+	if m.cancel != nil {
+		m.cancel()
+		m.activeBackgroundWorkers.Wait()
+	}
+	for _, b := range m.bindings {
+		err = multierr.Combine(err, b.action.stop(ctx))
+	}
+	return err
+}
+
+// handleEvent is the callback of every control the mapping listens to.
+func (m *mapping) handleEvent(ctx context.Context, event input.Event) {
+	if m.cancelCtx.Err() != nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch event.Event {
+	case input.Connect, input.Disconnect:
+		// Connect and Disconnect events should both stop everything.
+		m.stopAllLocked()
+	case input.ButtonPress:
+		m.handleButtonLocked(ctx, event.Control, true)
+	case input.ButtonRelease:
+		m.handleButtonLocked(ctx, event.Control, false)
+	case input.PositionChangeAbs:
+		m.handleAxisLocked(ctx, event.Control, event.Value)
+	case input.AllEvents, input.ButtonHold, input.ButtonChange, input.PositionChangeRel:
+		fallthrough
+	default:
+	}
+}
+
+// selectLocked returns the binding of the control that applies with the buttons held now, of those matching.
+func (m *mapping) selectLocked(control input.Control, matches func(b *binding) bool) *binding {
+	var selected *binding
+	for _, b := range m.bindings {
+		if b.control != control || !matches(b) || !m.modifiersHeldLocked(b) {
+			continue
+		}
+		if selected == nil || len(b.modifiers) > len(selected.modifiers) {
+			selected = b
+		}
+	}
+	return selected
+}
+
+func (m *mapping) modifiersHeldLocked(b *binding) bool {
+	for _, modifier := range b.modifiers {
+		if !m.pressed[modifier] {
+			return false
+		}
+	}
+	return true
+}
+
+func isPressBinding(b *binding) bool {
+	return !b.isAxis && b.conf.Mode != modeRelease
+}
+
+func isReleaseBinding(b *binding) bool {
+	return !b.isAxis && b.conf.Mode == modeRelease
+}
+
+func isAxisBinding(b *binding) bool {
+	return b.isAxis
+}
+
+func (m *mapping) handleButtonLocked(ctx context.Context, control input.Control, pressed bool) {
+	if m.pressed[control] == pressed {
+		return
+	}
+	m.pressed[control] = pressed
+
+	if pressed {
+		if b := m.selectLocked(control, isPressBinding); b != nil {
+			switch b.conf.Mode {
+			case modeHold:
+				m.held[control] = b
+				b.send(ctx, 1)
+			case modeToggle:
+				b.toggled = !b.toggled
+				if b.toggled {
+					b.send(ctx, 1)
+				} else {
+					b.send(ctx, 0)
+				}
+			default:
+				b.send(ctx, 1)
+			}
+		}
+	} else {
+		if b, ok := m.held[control]; ok {
+			delete(m.held, control)
+			b.send(ctx, 0)
+		}
+		if b := m.selectLocked(control, isReleaseBinding); b != nil {
+			b.send(ctx, 1)
+		}
+	}
+
+	// The button may have been a modifier, so a chord that was active may not apply anymore.
+	for heldControl, b := range m.held {
+		if !m.modifiersHeldLocked(b) {
+			delete(m.held, heldControl)
+			b.send(ctx, 0)
+		}
+	}
+	for axis, b := range m.activeAxes {
+		if m.selectLocked(axis, isAxisBinding) != b {
+			delete(m.activeAxes, axis)
+			b.send(ctx, 0)
+		}
+	}
+}
+
+func (m *mapping) handleAxisLocked(ctx context.Context, control input.Control, value float64) {
+	b := m.selectLocked(control, isAxisBinding)
+	if active, ok := m.activeAxes[control]; ok && active != b {
+		delete(m.activeAxes, control)
+		active.send(ctx, 0)
+	}
+	if b == nil {
+		return
+	}
+	m.activeAxes[control] = b
+	b.send(ctx, b.shape(value))
+}
+
+// stopAllLocked forgets the state of every control, and stops every action.
+func (m *mapping) stopAllLocked() {
+	m.pressed = map[input.Control]bool{}
+	m.held = map[input.Control]*binding{}
+	m.activeAxes = map[input.Control]*binding{}
+	for _, b := range m.bindings {
+		b.toggled = false
+		b.requestStop()
+	}
+}
@@ -0,0 +1,337 @@
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils"
+
+	"go.viam.com/rdk/components/gripper"
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/components/input/webgamepad"
+	"go.viam.com/rdk/components/motor"
+	"go.viam.com/rdk/components/servo"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/inputmapping"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestValidate(t *testing.T) {
+	conf := &Config{
+		InputControllerName: "gamepad",
+		Bindings: []BindingConfig{
+			{Control: "ButtonSouth", Mode: modeHold, Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+			{Control: "ButtonSouth", Modifiers: []string{"ButtonLT"}, Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+			{Control: "AbsoluteX", Deadzone: 0.1, Action: ActionConfig{Type: actionArmJog, Resource: "arm", Axis: "x", Speed: 10}},
+			{Control: "ButtonEast", Action: ActionConfig{
+				Type: actionDoCommand, Resource: "rdk:component:base/b", Command: map[string]interface{}{"command": "honk"},
+			}},
+		},
+	}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, utils.NewStringSet(deps...), test.ShouldResemble, utils.NewStringSet(
+		"gamepad", "rdk:component:motor/m", "rdk:service:motion/builtin", "rdk:component:base/b"))
+	test.That(t, deps, test.ShouldHaveLength, 4)
+
+	_, err = (&Config{}).Validate("path")
+	test.That(t, err, test.ShouldBeError, utils.NewConfigValidationFieldRequiredError("path", "input_controller"))
+
+	for _, bad := range []BindingConfig{
+		{Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+		{Control: "ButtonSouth", Modifiers: []string{"AbsoluteX"}, Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+		{Control: "ButtonSouth", Mode: "double_tap", Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+		{Control: "ButtonSouth", Deadzone: 0.1, Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+		{Control: "AbsoluteX", Mode: modeHold, Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+		{Control: "AbsoluteX", Deadzone: 1, Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+		{Control: "AbsoluteX", ResponseCurve: "sine", Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+		{Control: "ButtonSouth", Action: ActionConfig{Type: actionMotorPower}},
+		{Control: "ButtonSouth", Action: ActionConfig{Type: "teleport", Resource: "m"}},
+		{Control: "ButtonSouth", Action: ActionConfig{Type: actionMotorPower, Resource: "m", Power: 2}},
+		{Control: "ButtonSouth", Action: ActionConfig{Type: actionServoAngle, Resource: "s", MinAngleDeg: 90, MaxAngleDeg: 45}},
+		{Control: "ButtonSouth", Action: ActionConfig{Type: actionArmJog, Resource: "arm", Axis: "w", Speed: 10}},
+		{Control: "ButtonSouth", Action: ActionConfig{Type: actionArmJog, Resource: "arm", Axis: "x"}},
+		{Control: "ButtonSouth", Action: ActionConfig{Type: actionDoCommand, Resource: "b"}},
+	} {
+		conf := &Config{InputControllerName: "gamepad", Bindings: []BindingConfig{bad}}
+		_, err := conf.Validate("path")
+		test.That(t, err, test.ShouldNotBeNil)
+	}
+}
+
+func TestShape(t *testing.T) {
+	b := &binding{conf: &BindingConfig{Deadzone: 0.2}}
+	test.That(t, b.shape(0.1), test.ShouldEqual, 0)
+	test.That(t, b.shape(-0.2), test.ShouldEqual, 0)
+	test.That(t, b.shape(0.6), test.ShouldAlmostEqual, 0.5)
+	test.That(t, b.shape(-1), test.ShouldAlmostEqual, -1)
+	test.That(t, b.shape(1.5), test.ShouldAlmostEqual, 1)
+
+	b.conf = &BindingConfig{Invert: true, ResponseCurve: curveQuadratic}
+	test.That(t, b.shape(0.5), test.ShouldAlmostEqual, -0.25)
+	b.conf = &BindingConfig{ResponseCurve: curveCubic}
+	test.That(t, b.shape(-0.5), test.ShouldAlmostEqual, -0.125)
+	b.conf = &BindingConfig{ResponseCurve: curveExpo, Expo: 0.5}
+	test.That(t, b.shape(0.5), test.ShouldAlmostEqual, 0.3125)
+}
+
+// recorder records the calls made to the injected resources.
+type recorder chan string
+
+func (r recorder) record(format string, args ...interface{}) {
+	r <- fmt.Sprintf(format, args...)
+}
+
+func (r recorder) expect(t *testing.T, want string) {
+	t.Helper()
+	select {
+	case got := <-r:
+		test.That(t, got, test.ShouldEqual, want)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %q", want)
+	}
+}
+
+func (r recorder) drain() {
+	for {
+		select {
+		case <-r:
+		default:
+			return
+		}
+	}
+}
+
+func TestInputMapping(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+
+	gamepad, err := webgamepad.NewController(ctx, nil, resource.Config{}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	trigger := func(event input.EventType, control input.Control, value float64) {
+		t.Helper()
+		test.That(t, gamepad.(input.Triggerable).TriggerEvent(ctx, input.Event{
+			Event: event, Control: control, Value: value,
+		}, nil), test.ShouldBeNil)
+	}
+
+	motorCalls := recorder(make(chan string, 100))
+	injectMotor := inject.NewMotor("m")
+	injectMotor.SetPowerFunc = func(ctx context.Context, powerPct float64, extra map[string]interface{}) error {
+		motorCalls.record("power %v", powerPct)
+		return nil
+	}
+	injectMotor.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		motorCalls.record("stop")
+		return nil
+	}
+	injectMotor.DoFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		motorCalls.record("do %v %v", cmd["command"], cmd["value"])
+		return nil, nil
+	}
+
+	servoCalls := recorder(make(chan string, 100))
+	injectServo := inject.NewServo("s")
+	injectServo.MoveFunc = func(ctx context.Context, angleDeg uint32, extra map[string]interface{}) error {
+		servoCalls.record("move %d", angleDeg)
+		return nil
+	}
+
+	gripperCalls := recorder(make(chan string, 100))
+	injectGripper := inject.NewGripper("g")
+	injectGripper.GrabFunc = func(ctx context.Context, extra map[string]interface{}) (bool, error) {
+		gripperCalls.record("grab")
+		return true, nil
+	}
+	injectGripper.OpenFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		gripperCalls.record("open")
+		return nil
+	}
+	injectGripper.StopFunc = func(ctx context.Context, extra map[string]interface{}) error {
+		gripperCalls.record("stop")
+		return nil
+	}
+
+	motionCalls := recorder(make(chan string, 100))
+	injectMotion := inject.NewMotionService("builtin")
+	injectMotion.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		if cmd["command"] == "stop_jog" {
+			motionCalls.record("stop_jog %v", cmd["component_name"])
+			return nil, nil
+		}
+		linear := cmd["linear"].(map[string]interface{})
+		angular := cmd["angular"].(map[string]interface{})
+		motionCalls.record("jog %v %.0f %.2f", cmd["component_name"], linear["x"], angular["z"])
+		return nil, nil
+	}
+
+	deps := resource.Dependencies{
+		input.Named("gamepad"):  gamepad,
+		motor.Named("m"):        injectMotor,
+		servo.Named("s"):        injectServo,
+		gripper.Named("g"):      injectGripper,
+		motion.Named("builtin"): injectMotion,
+	}
+	conf := &Config{
+		InputControllerName: "gamepad",
+		Bindings: []BindingConfig{
+			{Control: "ButtonSouth", Mode: modeHold, Action: ActionConfig{Type: actionMotorPower, Resource: "m", Power: 0.5}},
+			{Control: "ButtonSouth", Modifiers: []string{"ButtonLT"}, Action: ActionConfig{
+				Type: actionDoCommand, Resource: "m", Command: map[string]interface{}{"command": "boost"},
+			}},
+			{Control: "AbsoluteY", Deadzone: 0.1, Invert: true, Action: ActionConfig{
+				Type: actionDoCommand, Resource: "m", Command: map[string]interface{}{"command": "speed"}, ValueKey: "value",
+			}},
+			{Control: "ButtonEast", Mode: modeToggle, Action: ActionConfig{Type: actionGripperGrab, Resource: "g"}},
+			{Control: "AbsoluteX", Action: ActionConfig{Type: actionArmJog, Resource: "arm1", Axis: "x", Speed: 100}},
+			{Control: "AbsoluteRX", Action: ActionConfig{Type: actionArmJog, Resource: "arm1", Axis: "rz", Speed: 90}},
+			{Control: "AbsoluteHat0X", Modifiers: []string{"ButtonRT"}, Action: ActionConfig{
+				Type: actionServoAngle, Resource: "s", MinAngleDeg: 10, MaxAngleDeg: 170,
+			}},
+		},
+	}
+	_, err = conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	svc, err := NewBuiltIn(ctx, deps, resource.Config{
+		Name:                "mapping",
+		API:                 inputmapping.Subtype,
+		ConvertedAttributes: conf,
+	}, logger)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, svc.ControllerInputs(), test.ShouldResemble, []input.Control{
+		input.AbsoluteHat0X, input.AbsoluteRX, input.AbsoluteX, input.AbsoluteY,
+		input.ButtonEast, input.ButtonLT, input.ButtonRT, input.ButtonSouth,
+	})
+
+	t.Run("hold and chords", func(t *testing.T) {
+		trigger(input.ButtonPress, input.ButtonSouth, 1)
+		motorCalls.expect(t, "power 0.5")
+		trigger(input.ButtonRelease, input.ButtonSouth, 0)
+		motorCalls.expect(t, "stop")
+
+		// the chord with more modifiers wins
+		trigger(input.ButtonPress, input.ButtonLT, 1)
+		trigger(input.ButtonPress, input.ButtonSouth, 1)
+		motorCalls.expect(t, "do boost <nil>")
+		trigger(input.ButtonRelease, input.ButtonSouth, 0)
+		trigger(input.ButtonRelease, input.ButtonLT, 0)
+
+		// releasing a modifier deactivates the chords it is part of
+		trigger(input.ButtonPress, input.ButtonRT, 1)
+		trigger(input.PositionChangeAbs, input.AbsoluteHat0X, 1)
+		servoCalls.expect(t, "move 170")
+		trigger(input.ButtonRelease, input.ButtonRT, 0)
+		servoCalls.expect(t, "move 90")
+		trigger(input.PositionChangeAbs, input.AbsoluteHat0X, -1)
+		trigger(input.ButtonPress, input.ButtonSouth, 1)
+		motorCalls.expect(t, "power 0.5")
+		trigger(input.ButtonRelease, input.ButtonSouth, 0)
+		motorCalls.expect(t, "stop")
+		servoCalls.drain()
+		test.That(t, servoCalls, test.ShouldBeEmpty)
+	})
+
+	t.Run("axes", func(t *testing.T) {
+		trigger(input.PositionChangeAbs, input.AbsoluteY, -1)
+		motorCalls.expect(t, "do speed 1")
+		trigger(input.PositionChangeAbs, input.AbsoluteY, 0.05)
+		motorCalls.expect(t, "do speed 0")
+	})
+
+	t.Run("toggle", func(t *testing.T) {
+		trigger(input.ButtonPress, input.ButtonEast, 1)
+		gripperCalls.expect(t, "grab")
+		trigger(input.ButtonRelease, input.ButtonEast, 0)
+		trigger(input.ButtonPress, input.ButtonEast, 1)
+		gripperCalls.expect(t, "open")
+		trigger(input.ButtonRelease, input.ButtonEast, 0)
+	})
+
+	t.Run("jogs of the same arm are merged", func(t *testing.T) {
+		trigger(input.PositionChangeAbs, input.AbsoluteX, 0.5)
+		motionCalls.expect(t, "jog arm1 50 0.00")
+		trigger(input.PositionChangeAbs, input.AbsoluteRX, 1)
+		motionCalls.expect(t, "jog arm1 50 1.57")
+		// the jog is repeated while the arm moves
+		motionCalls.expect(t, "jog arm1 50 1.57")
+		trigger(input.PositionChangeAbs, input.AbsoluteX, 0)
+		trigger(input.PositionChangeAbs, input.AbsoluteRX, 0)
+		for {
+			select {
+			case got := <-motionCalls:
+				if got == "stop_jog arm1" {
+					return
+				}
+				test.That(t, got, test.ShouldStartWith, "jog arm1")
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for stop_jog")
+			}
+		}
+	})
+
+	t.Run("disconnect stops everything", func(t *testing.T) {
+		trigger(input.ButtonPress, input.ButtonSouth, 1)
+		motorCalls.expect(t, "power 0.5")
+		trigger(input.ButtonPress, input.ButtonEast, 1)
+		gripperCalls.expect(t, "grab")
+		trigger(input.Disconnect, input.ButtonSouth, 0)
+		motorCalls.expect(t, "stop")
+		gripperCalls.expect(t, "stop")
+
+		// the state of the buttons is forgotten too
+		trigger(input.ButtonRelease, input.ButtonSouth, 0)
+		trigger(input.ButtonRelease, input.ButtonEast, 0)
+		trigger(input.ButtonPress, input.ButtonEast, 1)
+		gripperCalls.expect(t, "grab")
+		trigger(input.ButtonRelease, input.ButtonEast, 0)
+	})
+
+	t.Run("reconfigure", func(t *testing.T) {
+		conf := &Config{
+			InputControllerName: "gamepad",
+			Bindings: []BindingConfig{
+				{Control: "ButtonWest", Action: ActionConfig{Type: actionMotorPower, Resource: "m", Power: -1}},
+			},
+		}
+		test.That(t, svc.Reconfigure(ctx, deps, resource.Config{
+			Name:                "mapping",
+			API:                 inputmapping.Subtype,
+			ConvertedAttributes: conf,
+		}), test.ShouldBeNil)
+		test.That(t, svc.ControllerInputs(), test.ShouldResemble, []input.Control{input.ButtonWest})
+		motorCalls.drain()
+		gripperCalls.drain()
+
+		// the old bindings are gone
+		trigger(input.ButtonPress, input.ButtonSouth, 1)
+		trigger(input.ButtonPress, input.ButtonWest, 1)
+		motorCalls.expect(t, "power -1")
+		test.That(t, motorCalls, test.ShouldBeEmpty)
+	})
+
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+	motorCalls.expect(t, "stop")
+}
+
+func TestMissingDependency(t *testing.T) {
+	ctx := context.Background()
+	logger := golog.NewTestLogger(t)
+	gamepad, err := webgamepad.NewController(ctx, nil, resource.Config{}, logger)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = NewBuiltIn(ctx, resource.Dependencies{input.Named("gamepad"): gamepad}, resource.Config{
+		ConvertedAttributes: &Config{
+			InputControllerName: "gamepad",
+			Bindings: []BindingConfig{
+				{Control: "ButtonSouth", Action: ActionConfig{Type: actionMotorPower, Resource: "m"}},
+			},
+		},
+	}, logger)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "ButtonSouth")
+}
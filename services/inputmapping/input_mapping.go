@@ -0,0 +1,38 @@
+// Package inputmapping implements a service that binds the controls of an input controller to actions on other resources.
+package inputmapping
+
+import (
+	"context"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/resource"
+)
+
+// SubtypeName is the name of the type of service.
+const SubtypeName = resource.SubtypeName("input_mapping")
+
+// Subtype is a constant that identifies the input mapping resource subtype.
+var Subtype = resource.NewSubtype(
+	resource.ResourceNamespaceRDK,
+	resource.ResourceTypeService,
+	SubtypeName,
+)
+
+// Named is a helper for getting the named input mapping service's typed resource name.
+func Named(name string) resource.Name {
+	return resource.NameFromSubtype(Subtype, name)
+}
+
+func init() {
+	resource.RegisterSubtype(Subtype, resource.SubtypeRegistration[Service]{})
+}
+
+// A Service maps the events of an input controller to actions on other resources, such as setting the power of a
+// motor, moving a servo, or jogging an arm.
+type Service interface {
+	resource.Resource
+	// Close stops everything the mapped actions set in motion.
+	Close(ctx context.Context) error
+	// ControllerInputs returns the list of inputs from the controller that are being monitored.
+	ControllerInputs() []input.Control
+}
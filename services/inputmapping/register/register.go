@@ -0,0 +1,7 @@
+// Package register registers all relevant input mapping models and also subtype specific functions
+package register
+
+import (
+	// for input mapping models.
+	_ "go.viam.com/rdk/services/inputmapping/builtin"
+)
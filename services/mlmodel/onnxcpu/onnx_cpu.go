@@ -0,0 +1,189 @@
+// Package onnxcpu runs ONNX model files on the host's CPU, as an implementation the ML model service.
+package onnxcpu
+
+import (
+	"context"
+	fp "path/filepath"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/ml/inference/onnx"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/mlmodel"
+	"go.viam.com/rdk/utils"
+)
+
+var sModel = resource.NewDefaultModel("onnx_cpu")
+
+// modelTypeKey is the key of the ONNX metadata property reported as the model type, if the exporter set it.
+const modelTypeKey = "model_type"
+
+func init() {
+	resource.RegisterService(mlmodel.Subtype, sModel, resource.Registration[mlmodel.Service, *ONNXConfig]{
+		Constructor: func(
+			ctx context.Context,
+			_ resource.Dependencies,
+			conf resource.Config,
+			logger golog.Logger,
+		) (mlmodel.Service, error) {
+			svcConf, err := resource.NativeConfig[*ONNXConfig](conf)
+			if err != nil {
+				return nil, err
+			}
+			return NewONNXCPUModel(ctx, svcConf, conf.ResourceName())
+		},
+	})
+}
+
+// ONNXConfig contains the parameters specific to an onnx_cpu implementation
+// of the MLMS (machine learning model service).
+type ONNXConfig struct {
+	ModelPath string  `json:"model_path"`
+	LabelPath *string `json:"label_path"`
+}
+
+// Validate ensures all parts of the config are valid.
+func (cfg *ONNXConfig) Validate(path string) ([]string, error) {
+	if cfg.ModelPath == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "model_path")
+	}
+	return nil, nil
+}
+
+// Walk implements the Walker interface and correctly replaces model and label paths.
+func (cfg *ONNXConfig) Walk(visitor utils.Visitor) (interface{}, error) {
+	modelPath, err := visitor.Visit(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ModelPath = modelPath.(string)
+
+	labelPath, err := visitor.Visit(cfg.LabelPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LabelPath = labelPath.(*string)
+
+	return cfg, nil
+}
+
+// Model is a struct that implements the ONNX CPU implementation of the MLMS.
+type Model struct {
+	resource.Named
+	resource.AlwaysRebuild
+	resource.TriviallyCloseable
+	attrs    ONNXConfig
+	model    *onnx.Model
+	metadata mlmodel.MLMetadata
+}
+
+// NewONNXCPUModel is a constructor that builds an onnx cpu implementation of the MLMS.
+func NewONNXCPUModel(ctx context.Context, params *ONNXConfig, name resource.Name) (mlmodel.Service, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::NewONNXCPUModel")
+	defer span.End()
+
+	if params == nil {
+		return nil, errors.New("could not find parameters")
+	}
+	path, err := fp.Abs(params.ModelPath)
+	if err != nil {
+		path = params.ModelPath
+	}
+	model, err := onnx.Load(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not add model from location %s", params.ModelPath)
+	}
+	return &Model{
+		Named:    name.AsNamed(),
+		attrs:    *params,
+		model:    model,
+		metadata: convertMetadata(model.Info(), params.LabelPath),
+	}, nil
+}
+
+// Infer runs the model on the inputs in the map, keyed by the names of the model's inputs. A model with a
+// single input uses the only value of the map whatever its name. Inputs may hold a batch of samples if the
+// model's first input dimension is unknown. The outputs are flat slices keyed by the names of the model's
+// outputs, shaped as described by Metadata with unknown dimensions given by the batch size.
+func (m *Model) Infer(ctx context.Context, input map[string]interface{}) (map[string]interface{}, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::onnx_cpu::Infer")
+	defer span.End()
+
+	info := m.model.Info()
+	inputs := make(map[string]*onnx.Tensor, len(info.Inputs))
+	for _, in := range info.Inputs {
+		value, ok := input[in.Name]
+		if !ok && len(info.Inputs) == 1 && len(input) == 1 {
+			for _, v := range input {
+				value, ok = v, true
+			}
+		}
+		if !ok {
+			return nil, errors.Errorf("input map has no input named %q", in.Name)
+		}
+		t, err := onnx.NewInput(in, value)
+		if err != nil {
+			return nil, err
+		}
+		inputs[in.Name] = t
+	}
+
+	outputs, err := m.model.Run(inputs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't infer from model %q", m.Name())
+	}
+	outMap := make(map[string]interface{}, len(outputs))
+	for name, t := range outputs {
+		outMap[name] = t.Values()
+	}
+	return outMap, nil
+}
+
+// Metadata returns the names, types and shapes of the model's inputs and outputs, where -1 marks a dimension
+// that is not fixed by the model, such as the batch dimension.
+func (m *Model) Metadata(ctx context.Context) (mlmodel.MLMetadata, error) {
+	_, span := trace.StartSpan(ctx, "service::mlmodel::onnx_cpu::Metadata")
+	defer span.End()
+	return m.metadata, nil
+}
+
+// convertMetadata converts the description of an ONNX model into the metadata of the mlmodel service. Since
+// ONNX models do not carry label files, the configured one is attached to the outputs.
+func convertMetadata(info onnx.Metadata, labelPath *string) mlmodel.MLMetadata {
+	out := mlmodel.MLMetadata{
+		ModelName:        info.Name,
+		ModelType:        info.Properties[modelTypeKey],
+		ModelDescription: info.Description,
+	}
+	if out.ModelName == "" {
+		out.ModelName = info.Producer
+	}
+	for _, in := range info.Inputs {
+		out.Inputs = append(out.Inputs, tensorInfo(in, nil))
+	}
+	var files []mlmodel.File
+	if labelPath != nil && *labelPath != "" {
+		files = []mlmodel.File{{
+			Name:        *labelPath,
+			Description: "labels for the categories of the output",
+			LabelType:   mlmodel.LabelTypeTensorAxis,
+		}}
+	}
+	for _, o := range info.Outputs {
+		out.Outputs = append(out.Outputs, tensorInfo(o, files))
+	}
+	return out
+}
+
+func tensorInfo(info onnx.TensorInfo, files []mlmodel.File) mlmodel.TensorInfo {
+	return mlmodel.TensorInfo{
+		Name:            info.Name,
+		Description:     info.Description,
+		DataType:        info.DataType.String(),
+		Shape:           info.Shape,
+		AssociatedFiles: files,
+	}
+}
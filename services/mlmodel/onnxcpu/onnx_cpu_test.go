@@ -0,0 +1,136 @@
+package onnxcpu
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"go.viam.com/rdk/services/mlmodel"
+)
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v int64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+// valueInfo encodes the description of a float32 tensor, where negative dimensions are symbolic.
+func valueInfo(name string, dims ...int64) []byte {
+	var shape []byte
+	for _, d := range dims {
+		dim := appendVarint(nil, 1, d)
+		if d < 0 {
+			dim = appendString(nil, 2, "batch")
+		}
+		shape = appendMessage(shape, 1, dim)
+	}
+	tensorType := appendMessage(appendVarint(nil, 1, 1), 2, shape)
+	return appendMessage(appendString(nil, 1, name), 2, appendMessage(nil, 1, tensorType))
+}
+
+// writeTestModel writes an ONNX model that scores batches of 2 features for 3 categories with a single
+// matrix multiplication, to a file.
+func writeTestModel(t *testing.T) string {
+	t.Helper()
+	weights := []float32{1, 0, 1, 0, 1, 1}
+	raw := make([]byte, 4*len(weights))
+	for i, v := range weights {
+		binary.LittleEndian.PutUint32(raw[4*i:], math.Float32bits(v))
+	}
+	initializer := appendVarint(appendVarint(nil, 1, 2), 1, 3)
+	initializer = appendVarint(initializer, 2, 1)
+	initializer = appendString(initializer, 8, "weights")
+	initializer = appendMessage(initializer, 9, raw)
+
+	node := appendString(nil, 1, "features")
+	node = appendString(node, 1, "weights")
+	node = appendString(node, 2, "scores")
+	node = appendString(node, 4, "MatMul")
+
+	graph := appendMessage(nil, 1, node)
+	graph = appendString(graph, 2, "scorer")
+	graph = appendMessage(graph, 5, initializer)
+	graph = appendMessage(graph, 11, valueInfo("features", -1, 2))
+	graph = appendMessage(graph, 12, valueInfo("scores", -1, 3))
+
+	model := appendVarint(nil, 1, 8)
+	model = appendString(model, 6, "scores features")
+	model = appendMessage(model, 7, graph)
+	model = appendMessage(model, 8, appendVarint(nil, 2, 13))
+	prop := appendString(nil, 1, "model_type")
+	model = appendMessage(model, 14, appendString(prop, 2, "onnx_classifier"))
+
+	filename := filepath.Join(t.TempDir(), "scorer.onnx")
+	test.That(t, os.WriteFile(filename, model, 0o600), test.ShouldBeNil)
+	return filename
+}
+
+func TestONNXConfig(t *testing.T) {
+	_, err := (&ONNXConfig{}).Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, `"model_path" is required`)
+
+	deps, err := (&ONNXConfig{ModelPath: "model.onnx"}).Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldBeEmpty)
+}
+
+func TestONNXCPUModel(t *testing.T) {
+	ctx := context.Background()
+	_, err := NewONNXCPUModel(ctx, &ONNXConfig{ModelPath: filepath.Join(t.TempDir(), "missing.onnx")},
+		mlmodel.Named("missing"))
+	test.That(t, err.Error(), test.ShouldContainSubstring, "could not add model from location")
+
+	labels := "labels.txt"
+	svc, err := NewONNXCPUModel(ctx, &ONNXConfig{ModelPath: writeTestModel(t), LabelPath: &labels},
+		mlmodel.Named("scorer"))
+	test.That(t, err, test.ShouldBeNil)
+
+	md, err := svc.Metadata(ctx)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, md.ModelName, test.ShouldEqual, "scorer")
+	test.That(t, md.ModelType, test.ShouldEqual, "onnx_classifier")
+	test.That(t, md.ModelDescription, test.ShouldEqual, "scores features")
+	test.That(t, md.Inputs, test.ShouldResemble, []mlmodel.TensorInfo{
+		{Name: "features", DataType: "float32", Shape: []int{-1, 2}},
+	})
+	test.That(t, len(md.Outputs), test.ShouldEqual, 1)
+	test.That(t, md.Outputs[0].Name, test.ShouldEqual, "scores")
+	test.That(t, md.Outputs[0].DataType, test.ShouldEqual, "float32")
+	test.That(t, md.Outputs[0].Shape, test.ShouldResemble, []int{-1, 3})
+	test.That(t, md.Outputs[0].AssociatedFiles, test.ShouldResemble, []mlmodel.File{{
+		Name:        labels,
+		Description: "labels for the categories of the output",
+		LabelType:   mlmodel.LabelTypeTensorAxis,
+	}})
+
+	// A batch of two samples, given under the input's name.
+	out, err := svc.Infer(ctx, map[string]interface{}{"features": []float32{1, 2, 3, 4}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldResemble, map[string]interface{}{"scores": []float32{1, 2, 3, 3, 4, 7}})
+
+	// The only input is used whatever its name, and values decoded from gRPC are converted.
+	out, err = svc.Infer(ctx, map[string]interface{}{"input": []interface{}{1., 2.}})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, out, test.ShouldResemble, map[string]interface{}{"scores": []float32{1, 2, 3}})
+
+	_, err = svc.Infer(ctx, map[string]interface{}{"a": []float32{1, 2}, "b": []float32{1, 2}})
+	test.That(t, err, test.ShouldBeError, `input map has no input named "features"`)
+
+	_, err = svc.Infer(ctx, map[string]interface{}{"features": []float32{1, 2, 3}})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "does not fit the expected shape")
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
@@ -44,20 +45,24 @@ func init() {
 
 // NewBuiltIn returns a new move and grab service for the given robot.
 func NewBuiltIn(ctx context.Context, r robot.Robot, conf resource.Config, logger golog.Logger) (motion.Service, error) {
-	return &builtIn{
-		Named:  conf.ResourceName().AsNamed(),
-		r:      r,
-		logger: logger,
-	}, nil
+	ms := &builtIn{
+		Named:   conf.ResourceName().AsNamed(),
+		r:       r,
+		logger:  logger,
+		joggers: map[string]*jogger{},
+	}
+	return ms, nil
 }
 
 type builtIn struct {
 	resource.Named
 	// TODO(RSDK-2693): This should support reconfiguration and not use the robot constructor
 	resource.TriviallyReconfigurable
-	resource.TriviallyCloseable
 	r      robot.Robot
 	logger golog.Logger
+
+	joggersMu sync.Mutex
+	joggers   map[string]*jogger // by component name
 }
 
 // Move takes a goal location and will plan and execute a movement to move a component specified by its name to that destination.
@@ -220,3 +225,44 @@ func (ms *builtIn) GetPose(
 		supplementalTransforms,
 	)
 }
+
+// DoCommand supports jogging a component at a Cartesian velocity:
+//
+//	{"command": "jog", "component_name": "arm1", "linear": {"x": 10}, "angular": {"z": 0.1}}
+//
+// moves the component at the given linear velocity, in mm/s, and angular velocity, in rad/s, both in the world frame,
+// avoiding its limits and collisions. It keeps moving for half a second only, so the command must be repeated for as long
+// as the component should move. Several components can be jogged at once.
+//
+//	{"command": "stop_jog", "component_name": "arm1"}
+//
+// stops the component right away; without a component_name, every jogging component stops.
+func (ms *builtIn) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	name, ok := cmd["command"]
+	if !ok {
+		return nil, errors.New("missing 'command' value")
+	}
+	switch name {
+	case "jog":
+		command, err := parseJogCommand(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{}, ms.jog(ctx, command)
+	case "stop_jog":
+		component, ok := cmd["component_name"].(string)
+		if _, hasComponent := cmd["component_name"]; hasComponent && !ok {
+			return nil, fmt.Errorf("component_name must be a string, got %v", cmd["component_name"])
+		}
+		ms.stopJogging(component)
+		return map[string]interface{}{}, nil
+	default:
+		return nil, fmt.Errorf("no such command: %s", name)
+	}
+}
+
+// Close stops any jogging component.
+func (ms *builtIn) Close(ctx context.Context) error {
+	ms.stopJogging("")
+	return nil
+}
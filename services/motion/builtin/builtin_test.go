@@ -8,6 +8,7 @@ import (
 	"github.com/edaniels/golog"
 	"github.com/golang/geo/r3"
 	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
 
 	"go.viam.com/rdk/components/arm"
 	"go.viam.com/rdk/components/camera"
@@ -220,6 +221,70 @@ func TestMoveSingleComponent(t *testing.T) {
 	})
 }
 
+func TestJog(t *testing.T) {
+	ms, teardown := setupMotionServiceFromConfig(t, "../data/moving_arm.json")
+	defer teardown()
+	ctx := context.Background()
+	startPose, err := ms.GetPose(ctx, arm.Named("pieceArm"), "", nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	jog := map[string]interface{}{
+		"command":        "jog",
+		"component_name": "pieceArm",
+		"linear":         map[string]interface{}{"x": 100.},
+	}
+	// the command is repeated like a client holding a joystick would, until the arm has moved
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		_, err := ms.DoCommand(ctx, jog)
+		test.That(tb, err, test.ShouldBeNil)
+		pose, err := ms.GetPose(ctx, arm.Named("pieceArm"), "", nil, nil)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, pose.Pose().Point().X-startPose.Pose().Point().X, test.ShouldBeGreaterThan, 10)
+	})
+	// stopping another component leaves the arm jogging
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "stop_jog", "component_name": "pieceGripper"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, builtin.Jogging(ms, "pieceArm"), test.ShouldBeTrue)
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "stop_jog", "component_name": "pieceArm"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, builtin.Jogging(ms, "pieceArm"), test.ShouldBeFalse)
+	test.That(t, builtin.JoggerCount(ms), test.ShouldEqual, 0)
+
+	pose, err := ms.GetPose(ctx, arm.Named("pieceArm"), "", nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	moved := pose.Pose().Point().Sub(startPose.Pose().Point())
+	test.That(t, moved.X, test.ShouldBeGreaterThan, 10)
+	test.That(t, math.Abs(moved.Y), test.ShouldBeLessThan, 5)
+	test.That(t, math.Abs(moved.Z), test.ShouldBeLessThan, 5)
+
+	// jogging stops by itself when the commands stop coming
+	_, err = ms.DoCommand(ctx, jog)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, builtin.Jogging(ms, "pieceArm"), test.ShouldBeTrue)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		test.That(tb, builtin.Jogging(ms, "pieceArm"), test.ShouldBeFalse)
+		test.That(tb, builtin.JoggerCount(ms), test.ShouldEqual, 0)
+	})
+	pose, err = ms.GetPose(ctx, arm.Named("pieceArm"), "", nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	stopped, err := ms.GetPose(ctx, arm.Named("pieceArm"), "", nil, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqual(pose.Pose(), stopped.Pose()), test.ShouldBeTrue)
+
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "jog"})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "jog", "component_name": "pieceArm", "linear": 3.})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "jog", "component_name": "nope", "linear": map[string]interface{}{"x": 1.}})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "stop_jog", "component_name": 3.})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, builtin.JoggerCount(ms), test.ShouldEqual, 0)
+	_, err = ms.DoCommand(ctx, map[string]interface{}{"command": "dance"})
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, ms.Close(ctx), test.ShouldBeNil)
+}
+
 func TestMultiplePieces(t *testing.T) {
 	var err error
 	ms, teardown := setupMotionServiceFromConfig(t, "../data/fake_tomato.json")
@@ -0,0 +1,28 @@
+// export_test.go adds functionality to the builtin package that we only want to use and expose during testing.
+package builtin
+
+import (
+	"go.viam.com/rdk/services/motion"
+)
+
+// Jogging returns whether the motion service is jogging the component.
+func Jogging(ms motion.Service, component string) bool {
+	b := ms.(*builtIn)
+	b.joggersMu.Lock()
+	j, ok := b.joggers[component]
+	b.joggersMu.Unlock()
+	if !ok {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.runningLocked()
+}
+
+// JoggerCount returns how many components the motion service keeps a jogger for.
+func JoggerCount(ms motion.Service) int {
+	b := ms.(*builtIn)
+	b.joggersMu.Lock()
+	defer b.joggersMu.Unlock()
+	return len(b.joggers)
+}
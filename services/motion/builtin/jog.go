@@ -0,0 +1,294 @@
+package builtin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/geo/r3"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/motionplan"
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/robot/framesystem"
+)
+
+const (
+	// jogPeriod is how often a jogged component is moved.
+	jogPeriod = 50 * time.Millisecond
+
+	// jogTimeout is how long a component keeps jogging after the last jog command. Clients jogging continuously, e.g.
+	// from a gamepad, must repeat the command more often than this, so that a lost connection stops the component.
+	jogTimeout = 500 * time.Millisecond
+)
+
+// jogCommand is the velocity requested by the last jog command.
+type jogCommand struct {
+	component     string
+	linear        r3.Vector // mm/s in the world frame
+	angular       r3.Vector // rad/s in the world frame
+	maxJointSpeed float64
+	received      time.Time
+}
+
+// jogger moves a component at a Cartesian velocity in the background, for as long as jog commands keep coming. Each
+// component has its own, so that jogging one does not stop another.
+type jogger struct {
+	ms *builtIn
+
+	// mu guards the background loop, which never takes it, while commandMu guards the command it reads.
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	commandMu sync.Mutex
+	command   jogCommand
+
+	// removed is set, under mu, once the motion service forgets the jogger, after which it must not start again.
+	removed bool
+}
+
+// errJoggerRemoved is returned by jog when the jogger was forgotten by the motion service while the command waited.
+var errJoggerRemoved = errors.New("jogger was removed")
+
+// jog sets the velocity of the component, starting the background loop if it is not running.
+func (j *jogger) jog(ctx context.Context, command jogCommand) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.removed {
+		return errJoggerRemoved
+	}
+	command.received = time.Now()
+	if j.runningLocked() {
+		j.commandMu.Lock()
+		if j.command.maxJointSpeed == command.maxJointSpeed {
+			j.command = command
+			j.commandMu.Unlock()
+			return nil
+		}
+		j.commandMu.Unlock()
+	}
+	j.stopLocked()
+	if command.linear.Norm() == 0 && command.angular.Norm() == 0 {
+		return nil
+	}
+
+	frameSys, err := framesystem.RobotFrameSystem(ctx, j.ms.r, nil)
+	if err != nil {
+		return err
+	}
+	inputs, resources, err := framesystem.RobotFsCurrentInputs(ctx, j.ms.r, frameSys)
+	if err != nil {
+		return err
+	}
+	if frameSys.Frame(command.component) == nil {
+		return fmt.Errorf("component named %s not found in robot frame system", command.component)
+	}
+	controller, err := motionplan.NewCartesianVelocityController(frameSys, command.component, inputs,
+		motionplan.CartesianVelocityOptions{MaxJointSpeed: command.maxJointSpeed})
+	if err != nil {
+		return err
+	}
+
+	j.commandMu.Lock()
+	j.command = command
+	j.commandMu.Unlock()
+	loopCtx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	done := j.done
+	goutils.PanicCapturingGo(func() {
+		defer j.ms.removeIdleJogger(command.component, j)
+		defer close(done)
+		j.run(loopCtx, controller, inputs, resources)
+	})
+	return nil
+}
+
+// run moves the component every jogPeriod until canceled, the jog commands stop coming or a step fails.
+func (j *jogger) run(
+	ctx context.Context,
+	controller *motionplan.CartesianVelocityController,
+	inputs map[string][]referenceframe.Input,
+	resources map[string]referenceframe.InputEnabled,
+) {
+	ticker := time.NewTicker(jogPeriod)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			j.commandMu.Lock()
+			command := j.command
+			j.commandMu.Unlock()
+			if now.Sub(command.received) > jogTimeout || (command.linear.Norm() == 0 && command.angular.Norm() == 0) {
+				return
+			}
+
+			// inputs are tracked rather than read back, so that the lag of the component does not slow the jog down
+			next, err := controller.Step(inputs, command.linear, command.angular, now.Sub(last))
+			last = now
+			if err != nil {
+				j.ms.logger.Warnw("stopped jogging", "component", command.component, "error", err)
+				return
+			}
+			for name, frameInputs := range next {
+				if resource, ok := resources[name]; ok && !sameInputs(inputs[name], frameInputs) {
+					if err := resource.GoToInputs(ctx, frameInputs); err != nil {
+						if ctx.Err() == nil {
+							j.ms.logger.Warnw("stopped jogging", "component", command.component, "error", err)
+						}
+						return
+					}
+				}
+			}
+			inputs = next
+		}
+	}
+}
+
+// runningLocked returns whether the background loop is running; it ends by itself when the jog commands stop coming.
+func (j *jogger) runningLocked() bool {
+	if j.done == nil {
+		return false
+	}
+	select {
+	case <-j.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// stop stops jogging and waits for the background loop to end.
+func (j *jogger) stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.stopLocked()
+}
+
+func (j *jogger) stopLocked() {
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	<-j.done
+	j.cancel = nil
+	j.done = nil
+}
+
+// jog jogs the component of the command with its own jogger, creating one on the first jog of the component.
+func (ms *builtIn) jog(ctx context.Context, command jogCommand) error {
+	for {
+		j := ms.componentJogger(command.component)
+		err := j.jog(ctx, command)
+		if errors.Is(err, errJoggerRemoved) {
+			continue
+		}
+		ms.removeIdleJogger(command.component, j)
+		return err
+	}
+}
+
+// componentJogger returns the jogger of the component, creating it if there is none.
+func (ms *builtIn) componentJogger(component string) *jogger {
+	ms.joggersMu.Lock()
+	defer ms.joggersMu.Unlock()
+	j, ok := ms.joggers[component]
+	if !ok {
+		j = &jogger{ms: ms}
+		ms.joggers[component] = j
+	}
+	return j
+}
+
+// stopJogging stops jogging the component, or every component if none is named.
+func (ms *builtIn) stopJogging(component string) {
+	ms.joggersMu.Lock()
+	joggers := map[string]*jogger{}
+	for name, j := range ms.joggers {
+		if component == "" || name == component {
+			joggers[name] = j
+		}
+	}
+	ms.joggersMu.Unlock()
+	for name, j := range joggers {
+		j.stop()
+		ms.removeIdleJogger(name, j)
+	}
+}
+
+// removeIdleJogger forgets the jogger of the component unless its loop is running, so that the motion service only
+// keeps joggers for the components it is jogging rather than for every component it ever jogged.
+func (ms *builtIn) removeIdleJogger(component string, j *jogger) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	ms.joggersMu.Lock()
+	defer ms.joggersMu.Unlock()
+	if ms.joggers[component] != j || j.runningLocked() {
+		return
+	}
+	j.removed = true
+	delete(ms.joggers, component)
+}
+
+func sameInputs(a, b []referenceframe.Input) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseJogCommand reads a jog command from the arguments of DoCommand.
+func parseJogCommand(cmd map[string]interface{}) (jogCommand, error) {
+	component, ok := cmd["component_name"].(string)
+	if !ok || component == "" {
+		return jogCommand{}, errors.New("jog requires a component_name")
+	}
+	linear, err := parseJogVector(cmd, "linear")
+	if err != nil {
+		return jogCommand{}, err
+	}
+	angular, err := parseJogVector(cmd, "angular")
+	if err != nil {
+		return jogCommand{}, err
+	}
+	command := jogCommand{component: component, linear: linear, angular: angular}
+	if speed, ok := cmd["max_joint_speed"]; ok {
+		if command.maxJointSpeed, ok = speed.(float64); !ok {
+			return jogCommand{}, fmt.Errorf("max_joint_speed must be a number, got %v", speed)
+		}
+	}
+	return command, nil
+}
+
+func parseJogVector(cmd map[string]interface{}, key string) (r3.Vector, error) {
+	raw, ok := cmd[key]
+	if !ok {
+		return r3.Vector{}, nil
+	}
+	values, ok := raw.(map[string]interface{})
+	if !ok {
+		return r3.Vector{}, fmt.Errorf("%s must be an object with x, y and z, got %v", key, raw)
+	}
+	var v r3.Vector
+	for axis, dst := range map[string]*float64{"x": &v.X, "y": &v.Y, "z": &v.Z} {
+		value, ok := values[axis]
+		if !ok {
+			continue
+		}
+		if *dst, ok = value.(float64); !ok {
+			return r3.Vector{}, fmt.Errorf("%s.%s must be a number, got %v", key, axis, value)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,136 @@
+package motion
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"go.viam.com/rdk/components/input"
+)
+
+// jogRepeatPeriod is how often the jog command is repeated while an axis is held, since the motion service stops jogging
+// shortly after the last command.
+const jogRepeatPeriod = 200 * time.Millisecond
+
+// JogInputConfig describes how the axes of an input controller jog a component.
+type JogInputConfig struct {
+	// ComponentName is the name of the component to jog.
+	ComponentName string
+
+	// LinearSpeed is the speed, in mm/s, of a linear axis at full deflection.
+	LinearSpeed float64
+
+	// AngularSpeed is the speed, in rad/s, of an angular axis at full deflection.
+	AngularSpeed float64
+
+	// Deadzone is the deflection, between 0 and 1, under which an axis is considered at rest.
+	Deadzone float64
+}
+
+// jogAxes maps the controls of a gamepad to the velocity they drive: the left stick moves in X and Y, the right stick
+// moves in Z and turns about Z, and the hat turns about X and Y. Sticks are up when negative.
+var jogAxes = map[input.Control]struct {
+	key, axis string
+	sign      float64
+}{
+	input.AbsoluteX:     {"linear", "x", 1},
+	input.AbsoluteY:     {"linear", "y", -1},
+	input.AbsoluteRY:    {"linear", "z", -1},
+	input.AbsoluteRX:    {"angular", "z", -1},
+	input.AbsoluteHat0X: {"angular", "y", 1},
+	input.AbsoluteHat0Y: {"angular", "x", -1},
+}
+
+// JogWithInputController jogs a component with the sticks of an input controller, e.g. a gamepad, through the jog
+// command of the motion service, until the context is done. When it returns, the component is stopped and the
+// callbacks are removed from the controller.
+func JogWithInputController(ctx context.Context, svc Service, controller input.Controller, cfg JogInputConfig) error {
+	var mu sync.Mutex
+	deflections := map[input.Control]float64{}
+
+	command := func() (map[string]interface{}, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		velocities := map[string]map[string]interface{}{"linear": {}, "angular": {}}
+		moving := false
+		for control, value := range deflections {
+			if math.Abs(value) <= cfg.Deadzone {
+				continue
+			}
+			mapping := jogAxes[control]
+			speed := cfg.LinearSpeed
+			if mapping.key == "angular" {
+				speed = cfg.AngularSpeed
+			}
+			velocities[mapping.key][mapping.axis] = mapping.sign * value * speed
+			moving = true
+		}
+		return map[string]interface{}{
+			"command":        "jog",
+			"component_name": cfg.ComponentName,
+			"linear":         velocities["linear"],
+			"angular":        velocities["angular"],
+		}, moving
+	}
+
+	changed := make(chan struct{}, 1)
+	onEvent := func(ctx context.Context, ev input.Event) {
+		mu.Lock()
+		deflections[ev.Control] = ev.Value
+		mu.Unlock()
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	controls, err := controller.Controls(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var registered []input.Control
+	defer func() {
+		for _, control := range registered {
+			//nolint:errcheck
+			controller.RegisterControlCallback(context.Background(), control, []input.EventType{input.PositionChangeAbs}, nil, nil)
+		}
+		//nolint:errcheck
+		svc.DoCommand(context.Background(), map[string]interface{}{"command": "stop_jog", "component_name": cfg.ComponentName})
+	}()
+	for _, control := range controls {
+		if _, ok := jogAxes[control]; !ok {
+			continue
+		}
+		if err := controller.RegisterControlCallback(ctx, control, []input.EventType{input.PositionChangeAbs}, onEvent, nil); err != nil {
+			return err
+		}
+		registered = append(registered, control)
+	}
+
+	ticker := time.NewTicker(jogRepeatPeriod)
+	defer ticker.Stop()
+	wasMoving := false
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+			if !wasMoving {
+				continue
+			}
+		}
+		cmd, moving := command()
+		if !moving && !wasMoving {
+			continue
+		}
+		if !moving {
+			cmd = map[string]interface{}{"command": "stop_jog", "component_name": cfg.ComponentName}
+		}
+		if _, err := svc.DoCommand(ctx, cmd); err != nil && ctx.Err() == nil {
+			return err
+		}
+		wasMoving = moving
+	}
+}
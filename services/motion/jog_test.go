@@ -0,0 +1,113 @@
+package motion_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/input"
+	"go.viam.com/rdk/services/motion"
+	"go.viam.com/rdk/testutils/inject"
+)
+
+func TestJogWithInputController(t *testing.T) {
+	var mu sync.Mutex
+	callbacks := map[input.Control]input.ControlFunction{}
+	controller := inject.NewInputController("gamepad")
+	controller.ControlsFunc = func(ctx context.Context, extra map[string]interface{}) ([]input.Control, error) {
+		return []input.Control{input.AbsoluteX, input.AbsoluteY, input.ButtonSouth}, nil
+	}
+	controller.RegisterControlCallbackFunc = func(
+		ctx context.Context,
+		control input.Control,
+		triggers []input.EventType,
+		ctrlFunc input.ControlFunction,
+		extra map[string]interface{},
+	) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctrlFunc == nil {
+			delete(callbacks, control)
+		} else {
+			callbacks[control] = ctrlFunc
+		}
+		return nil
+	}
+
+	var commands []map[string]interface{}
+	svc := inject.NewMotionService("motion")
+	svc.DoCommandFunc = func(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		commands = append(commands, cmd)
+		return map[string]interface{}{}, nil
+	}
+	lastCommand := func() (map[string]interface{}, int) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(commands) == 0 {
+			return nil, 0
+		}
+		return commands[len(commands)-1], len(commands)
+	}
+	trigger := func(control input.Control, value float64) {
+		mu.Lock()
+		callback := callbacks[control]
+		mu.Unlock()
+		callback(context.Background(), input.Event{Event: input.PositionChangeAbs, Control: control, Value: value})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- motion.JogWithInputController(ctx, svc, controller, motion.JogInputConfig{
+			ComponentName: "arm1",
+			LinearSpeed:   100,
+			AngularSpeed:  0.5,
+			Deadzone:      0.1,
+		})
+	}()
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		mu.Lock()
+		defer mu.Unlock()
+		test.That(tb, callbacks, test.ShouldHaveLength, 2)
+	})
+
+	// the dead zone is ignored
+	trigger(input.AbsoluteX, 0.05)
+	time.Sleep(50 * time.Millisecond)
+	_, count := lastCommand()
+	test.That(t, count, test.ShouldEqual, 0)
+
+	// pushing the left stick up and right jogs in +X and +Y, and is repeated while held
+	trigger(input.AbsoluteX, 0.5)
+	trigger(input.AbsoluteY, -1)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		cmd, count := lastCommand()
+		test.That(tb, count, test.ShouldBeGreaterThan, 2)
+		test.That(tb, cmd["command"], test.ShouldEqual, "jog")
+		test.That(tb, cmd["component_name"], test.ShouldEqual, "arm1")
+		test.That(tb, cmd["linear"], test.ShouldResemble, map[string]interface{}{"x": 50., "y": 100.})
+	})
+
+	// releasing the stick stops the component
+	trigger(input.AbsoluteX, 0)
+	trigger(input.AbsoluteY, 0.02)
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		cmd, _ := lastCommand()
+		test.That(tb, cmd["command"], test.ShouldEqual, "stop_jog")
+		test.That(tb, cmd["component_name"], test.ShouldEqual, "arm1")
+	})
+	_, stoppedCount := lastCommand()
+	time.Sleep(300 * time.Millisecond)
+	_, count = lastCommand()
+	test.That(t, count, test.ShouldEqual, stoppedCount)
+
+	cancel()
+	test.That(t, <-done, test.ShouldBeNil)
+	test.That(t, callbacks, test.ShouldBeEmpty)
+}
@@ -5,6 +5,7 @@ import (
 	// register services.
 	_ "go.viam.com/rdk/services/baseremotecontrol/register"
 	_ "go.viam.com/rdk/services/datamanager/register"
+	_ "go.viam.com/rdk/services/inputmapping/register"
 	_ "go.viam.com/rdk/services/mlmodel/register"
 	_ "go.viam.com/rdk/services/motion/register"
 	_ "go.viam.com/rdk/services/navigation/register"
@@ -257,6 +257,32 @@ func (slamSvc *builtIn) GetPosition(ctx context.Context) (spatialmath.Pose, stri
 	return slamUtils.CheckQuaternionFromClientAlgo(pose, componentReference, returnedExt)
 }
 
+// GetPositionWithConfidence forwards the request for positional data to the slam library's gRPC service and
+// additionally returns the pose confidence the library reported in the extra field of its response. If it reported
+// none, the pose is returned with an error wrapping ErrNoConfidence.
+func (slamSvc *builtIn) GetPositionWithConfidence(ctx context.Context) (spatialmath.Pose, string, float64, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::builtIn::GetPositionWithConfidence")
+	defer span.End()
+
+	req := &pb.GetPositionRequest{Name: slamSvc.Name().ShortName()}
+
+	resp, err := slamSvc.clientAlgo.GetPosition(ctx, req)
+	if err != nil {
+		return nil, "", 0, errors.Wrap(err, "error getting SLAM position")
+	}
+	returnedExt := resp.Extra.AsMap()
+	pose, componentReference, err := slamUtils.CheckQuaternionFromClientAlgo(
+		spatialmath.NewPoseFromProtobuf(resp.GetPose()), resp.GetComponentReference(), returnedExt)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	confidence, ok := returnedExt[slam.ConfidenceKey].(float64)
+	if !ok {
+		return pose, componentReference, 0, errors.Wrapf(slam.ErrNoConfidence, "%v algorithm", slamSvc.slamLib.AlgoName)
+	}
+	return pose, componentReference, confidence, nil
+}
+
 // GetPointCloudMap creates a request, calls the slam algorithms GetPointCloudMap endpoint and returns a callback
 // function which will return the next chunk of the current pointcloud map.
 func (slamSvc *builtIn) GetPointCloudMap(ctx context.Context) (func() ([]byte, error), error) {
@@ -304,6 +330,12 @@ func NewBuiltIn(ctx context.Context, deps resource.Dependencies, conf resource.C
 
 	slamConfig.SetupDirectories(svcConfig.DataDirectory, logger)
 
+	if svcConfig.MapFile != "" {
+		if err := slamConfig.InstallMapFile(svcConfig.DataDirectory, svcConfig.MapFile, slamLib.MapFileType); err != nil {
+			return nil, err
+		}
+	}
+
 	if slamMode == slam.Rgbd || slamMode == slam.Mono {
 		var directoryNames []string
 		if slamMode == slam.Rgbd {
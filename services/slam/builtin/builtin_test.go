@@ -10,6 +10,7 @@ import (
 	"image"
 	"net"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -72,6 +73,7 @@ func createFakeSLAMLibraries() {
 			AlgoType:       s.AlgoType,
 			SlamMode:       s.SlamMode,
 			BinaryLocation: "true",
+			MapFileType:    s.MapFileType,
 		}
 	}
 }
@@ -634,6 +636,34 @@ func TestCartographerNew(t *testing.T) {
 		test.That(t, err, test.ShouldBeError,
 			errors.New("runtime slam service error: error getting data in desired mode: camera not lidar"))
 	})
+
+	t.Run("New cartographer service localizing against a map file", func(t *testing.T) {
+		mapFile := filepath.Join(t.TempDir(), "warehouse.pbstream")
+		test.That(t, os.WriteFile(mapFile, []byte("map"), 0o600), test.ShouldBeNil)
+
+		grpcServer, port := setupTestGRPCServer(t)
+		conf := &slamConfig.Config{
+			Sensors:       []string{"good_lidar"},
+			ConfigParams:  map[string]string{"mode": "2d"},
+			DataDirectory: name,
+			DataRateMsec:  validDataRateMS,
+			Port:          "localhost:" + strconv.Itoa(port),
+			UseLiveData:   &_true,
+			MapFile:       mapFile,
+		}
+
+		// Create slam service
+		svc, err := createSLAMService(t, conf, "fake_cartographer", logger, false, true)
+		test.That(t, err, test.ShouldBeNil)
+
+		installed, err := filepath.Glob(filepath.Join(name, "map", "map_data_*.pbstream"))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(installed), test.ShouldEqual, 1)
+		test.That(t, svc.(testhelper.Service).GetSLAMProcessConfig().Args, test.ShouldContain, "-map_rate_sec=0")
+
+		grpcServer.Stop()
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	})
 	closeOutSLAMService(t, name)
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
 	pb "go.viam.com/api/service/slam/v1"
 	"go.viam.com/utils/rpc"
@@ -56,6 +57,31 @@ func (c *client) GetPosition(ctx context.Context) (spatialmath.Pose, string, err
 	return spatialmath.NewPoseFromProtobuf(p), componentReference, nil
 }
 
+// GetPositionWithConfidence creates a request, calls the slam service GetPosition, and parses the response into a Pose with a
+// component reference string and the confidence reported by the service. If the service did not report one, the pose and
+// component reference are returned with an error wrapping ErrNoConfidence.
+func (c *client) GetPositionWithConfidence(ctx context.Context) (spatialmath.Pose, string, float64, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::client::GetPositionWithConfidence")
+	defer span.End()
+
+	req := &pb.GetPositionRequest{
+		Name: c.name,
+	}
+
+	resp, err := c.client.GetPosition(ctx, req)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	pose := spatialmath.NewPoseFromProtobuf(resp.GetPose())
+	confidence, ok := resp.GetExtra().AsMap()[ConfidenceKey].(float64)
+	if !ok {
+		return pose, resp.GetComponentReference(), 0, errors.Wrapf(ErrNoConfidence, "slam service %v", c.Name())
+	}
+
+	return pose, resp.GetComponentReference(), confidence, nil
+}
+
 // GetPointCloudMap creates a request, calls the slam service GetPointCloudMap and returns a callback
 // function which will return the next chunk of the current pointcloud map when called.
 func (c *client) GetPointCloudMap(ctx context.Context) (func() ([]byte, error), error) {
@@ -299,3 +299,85 @@ func TestFailingClient(t *testing.T) {
 		test.That(t, conn.Close(), test.ShouldBeNil)
 	})
 }
+
+// localizingSLAMService is a slam service that also reports a confidence in its pose.
+type localizingSLAMService struct {
+	*inject.SLAMService
+	confidenceErr error
+}
+
+func (svc *localizingSLAMService) GetPositionWithConfidence(ctx context.Context) (spatial.Pose, string, float64, error) {
+	pose, componentReference, err := svc.GetPosition(ctx)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if svc.confidenceErr != nil {
+		return pose, componentReference, 0, svc.confidenceErr
+	}
+	return pose, componentReference, 0.75, nil
+}
+
+func TestClientPositionConfidence(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.That(t, err, test.ShouldBeNil)
+	server, err := rpc.NewServer(logger, rpc.WithUnauthenticated())
+	test.That(t, err, test.ShouldBeNil)
+
+	pose := spatial.NewPoseFromPoint(r3.Vector{X: 1, Y: 2})
+	localizingSvc := &localizingSLAMService{SLAMService: &inject.SLAMService{}}
+	var positionCalls int
+	localizingSvc.GetPositionFunc = func(ctx context.Context) (spatial.Pose, string, error) {
+		positionCalls++
+		return pose, "lidar", nil
+	}
+	mappingSvc := &inject.SLAMService{}
+	mappingSvc.GetPositionFunc = localizingSvc.GetPositionFunc
+
+	svcs, err := resource.NewSubtypeCollection(slam.Subtype, map[resource.Name]slam.Service{
+		slam.Named(nameSucc): localizingSvc,
+		slam.Named(nameFail): mappingSvc,
+	})
+	test.That(t, err, test.ShouldBeNil)
+	resourceSubtype, ok, err := resource.LookupSubtypeRegistration[slam.Service](slam.Subtype)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, ok, test.ShouldBeTrue)
+	resourceSubtype.RegisterRPCService(context.Background(), server, svcs)
+
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := viamgrpc.Dial(context.Background(), listener.Addr().String(), logger)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, conn.Close(), test.ShouldBeNil)
+	}()
+
+	localizingClient := slam.NewClientFromConn(context.Background(), conn, slam.Named(nameSucc), logger)
+	p, componentReference, confidence, err := slam.GetPositionWithConfidence(context.Background(), localizingClient)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatial.PoseAlmostEqual(p, pose), test.ShouldBeTrue)
+	test.That(t, componentReference, test.ShouldEqual, "lidar")
+	test.That(t, confidence, test.ShouldEqual, 0.75)
+
+	// A service without a confidence for its pose still answers GetPosition, asking the service only once.
+	localizingSvc.confidenceErr = slam.ErrNoConfidence
+	positionCalls = 0
+	p, _, err = localizingClient.GetPosition(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatial.PoseAlmostEqual(p, pose), test.ShouldBeTrue)
+	test.That(t, positionCalls, test.ShouldEqual, 1)
+	p, componentReference, _, err = slam.GetPositionWithConfidence(context.Background(), localizingClient)
+	test.That(t, errors.Is(err, slam.ErrNoConfidence), test.ShouldBeTrue)
+	test.That(t, spatial.PoseAlmostEqual(p, pose), test.ShouldBeTrue)
+	test.That(t, componentReference, test.ShouldEqual, "lidar")
+
+	mappingClient := slam.NewClientFromConn(context.Background(), conn, slam.Named(nameFail), logger)
+	p, _, _, err = slam.GetPositionWithConfidence(context.Background(), mappingClient)
+	test.That(t, errors.Is(err, slam.ErrNoConfidence), test.ShouldBeTrue)
+	test.That(t, spatial.PoseAlmostEqual(p, pose), test.ShouldBeTrue)
+
+	p, _, _, err = slam.GetPositionWithConfidence(context.Background(), mappingSvc)
+	test.That(t, errors.Is(err, slam.ErrNoConfidence), test.ShouldBeTrue)
+	test.That(t, spatial.PoseAlmostEqual(p, pose), test.ShouldBeTrue)
+}
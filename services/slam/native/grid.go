@@ -0,0 +1,173 @@
+package native
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/pointcloud"
+)
+
+// cellKey indexes a cell of the occupancy grid.
+type cellKey struct {
+	X, Y int
+}
+
+// cellCounts tracks how often a cell has been observed as occupied (hit) or free (miss).
+type cellCounts struct {
+	hits   int
+	misses int
+}
+
+// probability returns the occupancy probability of the cell as an integer percentage.
+func (c *cellCounts) probability() int {
+	total := c.hits + c.misses
+	if total == 0 {
+		return 0
+	}
+	return int(math.Round(100 * float64(c.hits) / float64(total)))
+}
+
+// occupancyGrid is a 2D hit/miss occupancy grid with a fixed cell size in millimeters.
+type occupancyGrid struct {
+	resolution float64
+	cells      map[cellKey]*cellCounts
+}
+
+func newOccupancyGrid(resolution float64) *occupancyGrid {
+	return &occupancyGrid{resolution: resolution, cells: map[cellKey]*cellCounts{}}
+}
+
+func (g *occupancyGrid) keyFor(p r3.Vector) cellKey {
+	return cellKey{X: int(math.Floor(p.X / g.resolution)), Y: int(math.Floor(p.Y / g.resolution))}
+}
+
+func (g *occupancyGrid) center(k cellKey) r3.Vector {
+	return r3.Vector{X: (float64(k.X) + 0.5) * g.resolution, Y: (float64(k.Y) + 0.5) * g.resolution}
+}
+
+func (g *occupancyGrid) cell(k cellKey) *cellCounts {
+	c, ok := g.cells[k]
+	if !ok {
+		c = &cellCounts{}
+		g.cells[k] = c
+	}
+	return c
+}
+
+// insertScan ray traces every point of a scan, given in world coordinates, from the sensor origin. Cells
+// crossed by a ray are marked free and the cell containing the point is marked occupied.
+func (g *occupancyGrid) insertScan(origin r3.Vector, points []r3.Vector) {
+	start := g.keyFor(origin)
+	for _, p := range points {
+		end := g.keyFor(p)
+		traceRay(start, end, func(k cellKey) {
+			g.cell(k).misses++
+		})
+		g.cell(end).hits++
+	}
+}
+
+// occupied reports whether the cell containing p has been observed as occupied more often than free.
+func (g *occupancyGrid) occupied(p r3.Vector) bool {
+	c, ok := g.cells[g.keyFor(p)]
+	return ok && c.hits > c.misses
+}
+
+// toPointCloud returns every cell that has been hit at least once, placed at the cell center. As in the
+// maps produced by cartographer, the occupancy probability is stored both as the point value and in the
+// blue channel of its color.
+func (g *occupancyGrid) toPointCloud() (pointcloud.PointCloud, error) {
+	if len(g.cells) == 0 {
+		return pointcloud.New(), nil
+	}
+	minX, minY := math.MaxInt, math.MaxInt
+	maxX, maxY := math.MinInt, math.MinInt
+	for k, c := range g.cells {
+		if c.hits == 0 {
+			continue
+		}
+		if k.X < minX {
+			minX = k.X
+		}
+		if k.X > maxX {
+			maxX = k.X
+		}
+		if k.Y < minY {
+			minY = k.Y
+		}
+		if k.Y > maxY {
+			maxY = k.Y
+		}
+	}
+	if minX > maxX {
+		return pointcloud.New(), nil
+	}
+	lo := g.center(cellKey{minX, minY})
+	hi := g.center(cellKey{maxX, maxY})
+	side := math.Max(hi.X-lo.X, hi.Y-lo.Y) + 2*g.resolution
+	octree, err := pointcloud.NewBasicOctree(lo.Add(hi).Mul(0.5), side)
+	if err != nil {
+		return nil, err
+	}
+	for k, c := range g.cells {
+		if c.hits == 0 {
+			continue
+		}
+		prob := c.probability()
+		d := pointcloud.NewColoredData(color.NRGBA{B: uint8(prob), A: 255})
+		d.SetValue(prob)
+		if err := octree.Set(g.center(k), d); err != nil {
+			return nil, err
+		}
+	}
+	return octree, nil
+}
+
+// toPCD encodes the occupied cells of the grid as a binary PCD file.
+func (g *occupancyGrid) toPCD() ([]byte, error) {
+	pc, err := g.toPointCloud()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := pointcloud.ToPCD(pc, &buf, pointcloud.PCDBinary); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// traceRay calls fn for every cell on the line from start to end, excluding end, using Bresenham's algorithm.
+func traceRay(start, end cellKey, fn func(cellKey)) {
+	dx, dy := abs(end.X-start.X), -abs(end.Y-start.Y)
+	sx, sy := 1, 1
+	if start.X > end.X {
+		sx = -1
+	}
+	if start.Y > end.Y {
+		sy = -1
+	}
+	errTerm := dx + dy
+	x, y := start.X, start.Y
+	for x != end.X || y != end.Y {
+		fn(cellKey{x, y})
+		e2 := 2 * errTerm
+		if e2 >= dy {
+			errTerm += dy
+			x += sx
+		}
+		if e2 <= dx {
+			errTerm += dx
+			y += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
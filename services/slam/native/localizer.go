@@ -0,0 +1,289 @@
+package native
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/pointcloud"
+)
+
+const (
+	// relocalizationSamples is the number of scan points used to score candidate poses during a global search.
+	relocalizationSamples = 60
+	// relocalizationCandidates is the number of best scoring candidate poses refined with ICP.
+	relocalizationCandidates = 10
+)
+
+// localizerParams tunes localization against a fixed map.
+type localizerParams struct {
+	// minConfidence is the fraction of the points of a scan that must match the map for a pose to be accepted.
+	minConfidence float64
+	// searchStep and searchAngleStep are the spacing, in mm and radians, of the candidate poses tried when
+	// searching the whole map for the pose of a scan.
+	searchStep      float64
+	searchAngleStep float64
+	// initialPose seeds tracking when set, so that no global search is needed for the first scan.
+	initialPose *pose2D
+}
+
+func defaultLocalizerParams() localizerParams {
+	return localizerParams{
+		minConfidence:   0.5,
+		searchStep:      500,
+		searchAngleStep: 15 * math.Pi / 180,
+	}
+}
+
+// parseLocalizerParams overrides the default localizer parameters with any given in config_params.
+func parseLocalizerParams(configParams map[string]string) (localizerParams, error) {
+	params := defaultLocalizerParams()
+	values := map[string]float64{}
+	for _, key := range []string{
+		"min_confidence", "relocalization_step_mm", "relocalization_angle_deg",
+		"initial_x_mm", "initial_y_mm", "initial_theta_deg",
+	} {
+		if v, ok := configParams[key]; ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return params, errors.Wrapf(err, "error parsing config_params[%s]", key)
+			}
+			values[key] = f
+		}
+	}
+	if v, ok := values["min_confidence"]; ok {
+		params.minConfidence = v
+	}
+	if v, ok := values["relocalization_step_mm"]; ok {
+		params.searchStep = v
+	}
+	if v, ok := values["relocalization_angle_deg"]; ok {
+		params.searchAngleStep = v * math.Pi / 180
+	}
+	x, hasX := values["initial_x_mm"]
+	y, hasY := values["initial_y_mm"]
+	if hasX != hasY {
+		return params, errors.New("config_params[initial_x_mm] and config_params[initial_y_mm] must be given together")
+	}
+	if hasX {
+		params.initialPose = &pose2D{X: x, Y: y, Theta: normalizeAngle(values["initial_theta_deg"] * math.Pi / 180)}
+	}
+	if params.minConfidence < 0 || params.minConfidence > 1 {
+		return params, errors.New("config_params[min_confidence] must be between 0 and 1")
+	}
+	if params.searchStep <= 0 || params.searchAngleStep <= 0 {
+		return params, errors.New("config_params[relocalization_step_mm] and config_params[relocalization_angle_deg] " +
+			"must be greater than zero")
+	}
+	return params, nil
+}
+
+// localizer tracks the pose of the sensor in a fixed map without extending it. Each scan is matched with ICP
+// against the whole map, seeded with the previous pose and motion. When a scan no longer matches well, the
+// localizer considers itself lost and searches the whole map for the pose of the following scans.
+type localizer struct {
+	params  mapperParams
+	lparams localizerParams
+	logger  golog.Logger
+
+	// mapFile holds the unmodified contents of the loaded map, and mapPCD the map as a PCD. Neither they nor the
+	// map and its bounds change once loaded, so scans are matched against them without holding mu.
+	mapFile  []byte
+	mapPCD   []byte
+	target   *pointcloud.KDTree
+	min, max r3.Vector
+
+	// scanMu makes scans be localized one at a time, while mu guards the pose estimate, so that it can be read
+	// while a scan is being matched.
+	scanMu     sync.Mutex
+	mu         sync.Mutex
+	pose       pose2D
+	lastDelta  pose2D
+	confidence float64
+	lost       bool
+}
+
+// newLocalizer loads a map saved by the native slam service, either as its internal state (.json) or as a
+// point cloud (.pcd), such as the maps returned by GetPointCloudMap.
+func newLocalizer(mapFile string, params mapperParams, lparams localizerParams, logger golog.Logger) (*localizer, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(mapFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading map")
+	}
+
+	l := &localizer{params: params, lparams: lparams, logger: logger, mapFile: data, lost: true}
+	var pc pointcloud.PointCloud
+	switch ext := filepath.Ext(mapFile); ext {
+	case mapFileType:
+		m := newMapper(params, logger)
+		if err := m.unmarshalState(data); err != nil {
+			return nil, err
+		}
+		l.params.resolution = m.params.resolution
+		if pc, err = m.occupancyGrid().toPointCloud(); err != nil {
+			return nil, err
+		}
+		if l.mapPCD, err = m.pointCloudMap(); err != nil {
+			return nil, err
+		}
+	case ".pcd":
+		if pc, err = pointcloud.ReadPCD(bytes.NewReader(data)); err != nil {
+			return nil, errors.Wrap(err, "error parsing map")
+		}
+		l.mapPCD = data
+	default:
+		return nil, errors.Errorf("unsupported map file type %q, must be %q or %q", ext, mapFileType, ".pcd")
+	}
+
+	points := make([]r3.Vector, 0, pc.Size())
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		p.Z = 0
+		points = append(points, p)
+		return true
+	})
+	points = downsample(points, l.params.resolution)
+	if len(points) == 0 {
+		return nil, errors.Errorf("map %v has no points", mapFile)
+	}
+	l.target = pointcloud.NewKDTree()
+	l.min = r3.Vector{X: math.Inf(1), Y: math.Inf(1)}
+	l.max = r3.Vector{X: math.Inf(-1), Y: math.Inf(-1)}
+	for _, p := range points {
+		if err := l.target.Set(p, pointcloud.NewBasicData()); err != nil {
+			return nil, err
+		}
+		l.min = r3.Vector{X: math.Min(l.min.X, p.X), Y: math.Min(l.min.Y, p.Y)}
+		l.max = r3.Vector{X: math.Max(l.max.X, p.X), Y: math.Max(l.max.Y, p.Y)}
+	}
+
+	if lparams.initialPose != nil {
+		l.pose = *lparams.initialPose
+		l.lost = false
+	}
+	return l, nil
+}
+
+// positionWithConfidence returns the current estimated pose of the sensor along with the fraction of the
+// points of the last scan that matched the map, which is zero while the localizer is lost.
+func (l *localizer) positionWithConfidence() (pose2D, float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.pose, l.confidence
+}
+
+// addScan localizes a point cloud, in the sensor frame, in the map and updates the pose estimate.
+func (l *localizer) addScan(pc pointcloud.PointCloud) (pose2D, error) {
+	scan := preprocessScan(pc, l.params)
+	if len(scan) == 0 {
+		pose, _ := l.positionWithConfidence()
+		return pose, errors.New("scan has no points within range")
+	}
+
+	l.scanMu.Lock()
+	defer l.scanMu.Unlock()
+
+	l.mu.Lock()
+	lost, current, lastDelta := l.lost, l.pose, l.lastDelta
+	l.mu.Unlock()
+
+	if !lost {
+		if pose, confidence, ok := l.refine(scan, current.compose(lastDelta)); ok {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.lastDelta = current.between(pose)
+			l.pose = pose
+			l.confidence = confidence
+			return l.pose, nil
+		}
+		l.logger.Info("lost track of pose in map, searching the whole map")
+		l.mu.Lock()
+		l.lost = true
+		l.mu.Unlock()
+	}
+
+	pose, confidence, ok := l.relocalize(scan)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !ok {
+		l.confidence = 0
+		return l.pose, errors.New("could not localize scan in map")
+	}
+	l.logger.Infow("localized in map", "pose", pose, "confidence", confidence)
+	l.pose = pose
+	l.lastDelta = pose2D{}
+	l.confidence = confidence
+	l.lost = false
+	return l.pose, nil
+}
+
+// refine aligns a scan to the map starting from guess, and reports whether the result is good enough to accept.
+func (l *localizer) refine(scan []r3.Vector, guess pose2D) (pose2D, float64, bool) {
+	threshold := 2 * l.params.resolution
+	pose, cost, err := matchScan(scan, l.target, guess, l.params.maxCorrespondence, threshold)
+	if err != nil || cost > l.params.maxMatchError {
+		return guess, 0, false
+	}
+	confidence := inlierRatio(scan, l.target, pose, threshold)
+	return pose, confidence, confidence >= l.lparams.minConfidence
+}
+
+// relocalize searches the whole map for the pose of a scan. Candidate poses on a grid spanning the map are
+// scored by how many of a sample of the scan's points land near the map, and the best of them are refined
+// with ICP.
+func (l *localizer) relocalize(scan []r3.Vector) (pose2D, float64, bool) {
+	sample := scan
+	if len(scan) > relocalizationSamples {
+		sample = make([]r3.Vector, 0, relocalizationSamples)
+		for i := 0; i < relocalizationSamples; i++ {
+			sample = append(sample, scan[i*len(scan)/relocalizationSamples])
+		}
+	}
+
+	type candidate struct {
+		pose  pose2D
+		score float64
+	}
+	candidates := []candidate{}
+	for x := l.min.X; x <= l.max.X; x += l.lparams.searchStep {
+		for y := l.min.Y; y <= l.max.Y; y += l.lparams.searchStep {
+			for theta := -math.Pi; theta < math.Pi; theta += l.lparams.searchAngleStep {
+				pose := pose2D{X: x, Y: y, Theta: theta}
+				candidates = append(candidates, candidate{pose, inlierRatio(sample, l.target, pose, l.lparams.searchStep)})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if len(candidates) > relocalizationCandidates {
+		candidates = candidates[:relocalizationCandidates]
+	}
+
+	var best pose2D
+	var bestConfidence float64
+	found := false
+	for _, c := range candidates {
+		if pose, confidence, ok := l.refine(scan, c.pose); ok && confidence > bestConfidence {
+			best, bestConfidence, found = pose, confidence, true
+		}
+	}
+	return best, bestConfidence, found
+}
+
+// pointCloudMap returns the map as a PCD.
+func (l *localizer) pointCloudMap() ([]byte, error) {
+	return l.mapPCD, nil
+}
+
+// marshalState returns the map file the localizer was loaded from, unchanged, since localization does not
+// modify the map.
+func (l *localizer) marshalState() ([]byte, error) {
+	return l.mapFile, nil
+}
@@ -0,0 +1,136 @@
+package native
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+)
+
+// saveTestMap maps the test room while driving forward and writes the map to a file of the given type.
+func saveTestMap(t *testing.T, fileType string) string {
+	t.Helper()
+	m := newMapper(defaultMapperParams(), golog.NewTestLogger(t))
+	for i := 0; i < 8; i++ {
+		_, err := m.addScan(simulateScan(t, pose2D{X: 100 * float64(i)}))
+		test.That(t, err, test.ShouldBeNil)
+	}
+	var data []byte
+	var err error
+	if fileType == ".pcd" {
+		data, err = m.pointCloudMap()
+	} else {
+		data, err = m.marshalState()
+	}
+	test.That(t, err, test.ShouldBeNil)
+	filename := filepath.Join(t.TempDir(), "map"+fileType)
+	test.That(t, os.WriteFile(filename, data, 0o600), test.ShouldBeNil)
+	return filename
+}
+
+func TestParseLocalizerParams(t *testing.T) {
+	params, err := parseLocalizerParams(map[string]string{"mode": "2d"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, params, test.ShouldResemble, defaultLocalizerParams())
+
+	params, err = parseLocalizerParams(map[string]string{
+		"min_confidence":    "0.7",
+		"initial_x_mm":      "100",
+		"initial_y_mm":      "-200",
+		"initial_theta_deg": "90",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, params.minConfidence, test.ShouldEqual, 0.7)
+	test.That(t, *params.initialPose, test.ShouldResemble, pose2D{X: 100, Y: -200, Theta: math.Pi / 2})
+
+	_, err = parseLocalizerParams(map[string]string{"initial_x_mm": "100"})
+	test.That(t, err, test.ShouldBeError,
+		"config_params[initial_x_mm] and config_params[initial_y_mm] must be given together")
+
+	_, err = parseLocalizerParams(map[string]string{"min_confidence": "2"})
+	test.That(t, err, test.ShouldBeError, "config_params[min_confidence] must be between 0 and 1")
+}
+
+func TestLocalizerRelocalizes(t *testing.T) {
+	for _, fileType := range []string{mapFileType, ".pcd"} {
+		t.Run(fileType, func(t *testing.T) {
+			l, err := newLocalizer(saveTestMap(t, fileType), defaultMapperParams(), defaultLocalizerParams(),
+				golog.NewTestLogger(t))
+			test.That(t, err, test.ShouldBeNil)
+			_, confidence := l.positionWithConfidence()
+			test.That(t, confidence, test.ShouldEqual, 0)
+
+			// The first scan is found by searching the whole map.
+			truth := pose2D{X: -1200, Y: 800, Theta: 2}
+			pose, err := l.addScan(simulateScan(t, truth))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, pose.distance(truth), test.ShouldBeLessThan, 30)
+			test.That(t, pose.Theta, test.ShouldAlmostEqual, truth.Theta, 0.02)
+
+			// Following scans are tracked from the previous pose.
+			for i := 0; i < 5; i++ {
+				truth = truth.compose(pose2D{X: 80, Theta: -0.05})
+				_, err := l.addScan(simulateScan(t, truth))
+				test.That(t, err, test.ShouldBeNil)
+			}
+			pose, confidence = l.positionWithConfidence()
+			test.That(t, pose.distance(truth), test.ShouldBeLessThan, 30)
+			test.That(t, confidence, test.ShouldBeGreaterThan, 0.9)
+
+			// A robot moved somewhere else entirely is found again.
+			truth = pose2D{X: 2000, Y: -1200, Theta: -2.5}
+			pose, err = l.addScan(simulateScan(t, truth))
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, pose.distance(truth), test.ShouldBeLessThan, 30)
+		})
+	}
+}
+
+func TestLocalizerInitialPose(t *testing.T) {
+	lparams := defaultLocalizerParams()
+	lparams.initialPose = &pose2D{X: 500, Y: 0}
+	// A search step larger than the map means that only tracking from the initial pose can succeed.
+	lparams.searchStep = 100000
+	l, err := newLocalizer(saveTestMap(t, mapFileType), defaultMapperParams(), lparams, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	truth := pose2D{X: 550, Y: 30, Theta: 0.05}
+	pose, err := l.addScan(simulateScan(t, truth))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pose.distance(truth), test.ShouldBeLessThan, 30)
+}
+
+func TestLocalizerPositionWhileMatching(t *testing.T) {
+	l, err := newLocalizer(saveTestMap(t, mapFileType), defaultMapperParams(), defaultLocalizerParams(),
+		golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+
+	// A scan being matched, which can take a while when searching the whole map, does not hold up reading the pose.
+	l.scanMu.Lock()
+	defer l.scanMu.Unlock()
+	read := make(chan struct{})
+	go func() {
+		l.positionWithConfidence()
+		close(read)
+	}()
+	select {
+	case <-read:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reading the pose blocked while a scan was being matched")
+	}
+}
+
+func TestNewLocalizerErrors(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	_, err := newLocalizer(filepath.Join(t.TempDir(), "missing.json"), defaultMapperParams(), defaultLocalizerParams(), logger)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error reading map")
+
+	filename := filepath.Join(t.TempDir(), "map.pbstream")
+	test.That(t, os.WriteFile(filename, []byte{}, 0o600), test.ShouldBeNil)
+	_, err = newLocalizer(filename, defaultMapperParams(), defaultLocalizerParams(), logger)
+	test.That(t, err, test.ShouldBeError, `unsupported map file type ".pbstream", must be ".json" or ".pcd"`)
+}
@@ -0,0 +1,429 @@
+package native
+
+import (
+	"math"
+	"sync"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/pointcloud"
+)
+
+const (
+	icpMaxIterations        = 50
+	icpMinInlierRatio       = 0.5
+	icpTranslationTolerance = 0.01
+	icpRotationTolerance    = 1e-5
+	icpThresholdDecay       = 0.8
+	normalNeighbors         = 5
+)
+
+// mapperParams tunes the scan matching, map building and loop closure of a mapper.
+type mapperParams struct {
+	// resolution is the side length of an occupancy grid cell and of the voxels scans are downsampled to, in mm.
+	resolution float64
+	// minRange and maxRange bound the planar distance from the sensor of the points kept from a scan, in mm.
+	minRange float64
+	maxRange float64
+	// minHeight and maxHeight bound the z coordinate of the points kept from a scan, in mm.
+	minHeight float64
+	maxHeight float64
+	// keyframeDistance and keyframeAngle are the motion, in mm and radians, after which a new keyframe is
+	// added to the map.
+	keyframeDistance float64
+	keyframeAngle    float64
+	// submapSize is the number of most recent keyframes incoming scans are matched against.
+	submapSize int
+	// maxCorrespondence is the distance, in mm, beyond which a scan point is not paired with a map point
+	// during scan matching.
+	maxCorrespondence float64
+	// maxMatchError is the mean point distance, in mm, above which a scan match is rejected.
+	maxMatchError float64
+	// loopClosureRadius is the distance, in mm, within which an older keyframe is considered for loop closure.
+	loopClosureRadius float64
+	// loopClosureMinGap is the minimum number of keyframes between the two ends of a loop.
+	loopClosureMinGap int
+	// loopClosureMaxError is the mean point distance, in mm, below which a loop closure is accepted.
+	loopClosureMaxError float64
+}
+
+func defaultMapperParams() mapperParams {
+	return mapperParams{
+		resolution:          50,
+		minRange:            100,
+		maxRange:            25000,
+		minHeight:           math.Inf(-1),
+		maxHeight:           math.Inf(1),
+		keyframeDistance:    200,
+		keyframeAngle:       10 * math.Pi / 180,
+		submapSize:          10,
+		maxCorrespondence:   500,
+		maxMatchError:       100,
+		loopClosureRadius:   1000,
+		loopClosureMinGap:   20,
+		loopClosureMaxError: 50,
+	}
+}
+
+// keyframe is a downsampled scan, in the sensor frame, along with the pose it was taken at.
+type keyframe struct {
+	Pose   pose2D      `json:"pose"`
+	Points []r3.Vector `json:"points"`
+}
+
+func (kf *keyframe) worldPoints() []r3.Vector {
+	pts := make([]r3.Vector, 0, len(kf.Points))
+	for _, p := range kf.Points {
+		pts = append(pts, kf.Pose.transform(p))
+	}
+	return pts
+}
+
+// mapper is a 2D lidar SLAM front and back end. Scans are matched against a submap of recent keyframes
+// with ICP, keyframes are inserted into an occupancy grid, and loops are closed by matching against older
+// keyframes near the current pose and spreading the correction over the keyframes in the loop.
+type mapper struct {
+	mu     sync.Mutex
+	params mapperParams
+	logger golog.Logger
+
+	pose      pose2D
+	lastDelta pose2D
+	keyframes []*keyframe
+	grid      *occupancyGrid
+	gridDirty bool
+	submap    *pointcloud.KDTree
+
+	// confidence is the fraction of the points of the last scan that matched the map.
+	confidence   float64
+	loopClosures int
+}
+
+func newMapper(params mapperParams, logger golog.Logger) *mapper {
+	return &mapper{
+		params: params,
+		logger: logger,
+		grid:   newOccupancyGrid(params.resolution),
+	}
+}
+
+// position returns the current estimated pose of the sensor.
+func (m *mapper) position() pose2D {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pose
+}
+
+// positionWithConfidence returns the current estimated pose of the sensor along with the fraction of the
+// points of the last scan that matched the map.
+func (m *mapper) positionWithConfidence() (pose2D, float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pose, m.confidence
+}
+
+// addScan matches a point cloud, in the sensor frame, against the map and updates the pose estimate and map.
+func (m *mapper) addScan(pc pointcloud.PointCloud) (pose2D, error) {
+	scan := preprocessScan(pc, m.params)
+	if len(scan) == 0 {
+		return m.position(), errors.New("scan has no points within range")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.keyframes) == 0 {
+		m.addKeyframe(&keyframe{Pose: m.pose, Points: scan})
+		m.confidence = 1
+		return m.pose, nil
+	}
+
+	guess := m.pose.compose(m.lastDelta)
+	matched, cost, err := matchScan(scan, m.submap, guess, m.params.maxCorrespondence, 2*m.params.resolution)
+	if err != nil {
+		m.confidence = 0
+		return m.pose, err
+	}
+	if cost > m.params.maxMatchError {
+		m.lastDelta = pose2D{}
+		m.confidence = 0
+		return m.pose, errors.Errorf("scan match error %.2fmm exceeds maximum of %.2fmm", cost, m.params.maxMatchError)
+	}
+	m.lastDelta = m.pose.between(matched)
+	m.pose = matched
+	m.confidence = inlierRatio(scan, m.submap, matched, 2*m.params.resolution)
+
+	last := m.keyframes[len(m.keyframes)-1].Pose
+	if last.distance(matched) >= m.params.keyframeDistance ||
+		math.Abs(normalizeAngle(matched.Theta-last.Theta)) >= m.params.keyframeAngle {
+		m.addKeyframe(&keyframe{Pose: matched, Points: scan})
+		m.closeLoop()
+	}
+	return m.pose, nil
+}
+
+// preprocessScan flattens a point cloud onto the XY plane, drops points out of range and downsamples it to
+// the map resolution by averaging the points falling in each cell.
+func preprocessScan(pc pointcloud.PointCloud, params mapperParams) []r3.Vector {
+	points := []r3.Vector{}
+	pc.Iterate(0, 0, func(p r3.Vector, d pointcloud.Data) bool {
+		if p.Z < params.minHeight || p.Z > params.maxHeight {
+			return true
+		}
+		p.Z = 0
+		if r := p.Norm(); r < params.minRange || r > params.maxRange {
+			return true
+		}
+		points = append(points, p)
+		return true
+	})
+	return downsample(points, params.resolution)
+}
+
+// downsample replaces the points falling in each cell of a grid with the given resolution by their mean.
+func downsample(points []r3.Vector, resolution float64) []r3.Vector {
+	type accumulator struct {
+		sum r3.Vector
+		n   float64
+	}
+	grid := newOccupancyGrid(resolution)
+	voxels := map[cellKey]*accumulator{}
+	keys := []cellKey{}
+	for _, p := range points {
+		k := grid.keyFor(p)
+		acc, ok := voxels[k]
+		if !ok {
+			acc = &accumulator{}
+			voxels[k] = acc
+			keys = append(keys, k)
+		}
+		acc.sum = acc.sum.Add(p)
+		acc.n++
+	}
+	out := make([]r3.Vector, 0, len(keys))
+	for _, k := range keys {
+		acc := voxels[k]
+		out = append(out, acc.sum.Mul(1/acc.n))
+	}
+	return out
+}
+
+// addKeyframe appends a keyframe, inserts it into the occupancy grid and refreshes the submap.
+// It must be called with the mutex held.
+func (m *mapper) addKeyframe(kf *keyframe) {
+	m.keyframes = append(m.keyframes, kf)
+	if !m.gridDirty {
+		m.grid.insertScan(r3.Vector{X: kf.Pose.X, Y: kf.Pose.Y}, kf.worldPoints())
+	}
+	m.refreshSubmap()
+}
+
+// refreshSubmap rebuilds the k-d tree of the most recent keyframes in world coordinates.
+// It must be called with the mutex held.
+func (m *mapper) refreshSubmap() {
+	start := len(m.keyframes) - m.params.submapSize
+	if start < 0 {
+		start = 0
+	}
+	m.submap = keyframesToKDTree(m.keyframes[start:])
+}
+
+func keyframesToKDTree(kfs []*keyframe) *pointcloud.KDTree {
+	tree := pointcloud.NewKDTree()
+	for _, kf := range kfs {
+		for _, p := range kf.worldPoints() {
+			// Set only fails for coordinates beyond float64 integer precision, which a map never reaches.
+			//nolint:errcheck
+			tree.Set(p, pointcloud.NewBasicData())
+		}
+	}
+	return tree
+}
+
+// closeLoop looks for an older keyframe close to the newest one. If the newest scan matches it well, the
+// resulting pose correction is spread linearly over every keyframe since the older one and the map is
+// marked for rebuilding. It must be called with the mutex held.
+func (m *mapper) closeLoop() {
+	current := len(m.keyframes) - 1
+	newest := m.keyframes[current]
+	candidate := -1
+	best := m.params.loopClosureRadius
+	for i := 0; i < current-m.params.loopClosureMinGap; i++ {
+		if d := m.keyframes[i].Pose.distance(newest.Pose); d < best {
+			candidate, best = i, d
+		}
+	}
+	if candidate < 0 {
+		return
+	}
+
+	lo, hi := candidate-1, candidate+2
+	if lo < 0 {
+		lo = 0
+	}
+	target := keyframesToKDTree(m.keyframes[lo:hi])
+	matched, cost, err := matchScan(newest.Points, target, newest.Pose, m.params.maxCorrespondence, 2*m.params.resolution)
+	if err != nil {
+		m.logger.Debugw("loop closure scan match failed", "error", err)
+		return
+	}
+	if cost > m.params.loopClosureMaxError {
+		m.logger.Debugw("rejected loop closure", "from", candidate, "to", current, "error", cost)
+		return
+	}
+
+	correction := pose2D{
+		X:     matched.X - newest.Pose.X,
+		Y:     matched.Y - newest.Pose.Y,
+		Theta: normalizeAngle(matched.Theta - newest.Pose.Theta),
+	}
+	span := float64(current - candidate)
+	for i := candidate + 1; i <= current; i++ {
+		frac := float64(i-candidate) / span
+		kf := m.keyframes[i]
+		kf.Pose = pose2D{
+			X:     kf.Pose.X + frac*correction.X,
+			Y:     kf.Pose.Y + frac*correction.Y,
+			Theta: normalizeAngle(kf.Pose.Theta + frac*correction.Theta),
+		}
+	}
+	m.pose = newest.Pose
+	m.loopClosures++
+	m.gridDirty = true
+	m.refreshSubmap()
+	m.logger.Debugw("closed loop", "from", candidate, "to", current, "correction", correction)
+}
+
+// occupancyGrid returns the occupancy grid, rebuilding it from the keyframes if a loop closure moved them.
+// It must be called with the mutex held.
+func (m *mapper) occupancyGrid() *occupancyGrid {
+	if m.gridDirty {
+		m.grid = newOccupancyGrid(m.params.resolution)
+		for _, kf := range m.keyframes {
+			m.grid.insertScan(r3.Vector{X: kf.Pose.X, Y: kf.Pose.Y}, kf.worldPoints())
+		}
+		m.gridDirty = false
+	}
+	return m.grid
+}
+
+// pointCloudMap returns the occupancy grid encoded as a binary PCD.
+func (m *mapper) pointCloudMap() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.occupancyGrid().toPCD()
+}
+
+// matchScan aligns a scan, given in the sensor frame, to a target with planar point-to-line ICP seeded
+// with guess. Each iteration pairs every scan point with its nearest target point within a distance
+// threshold and solves the linearized least squares problem for the rigid 2D transform minimizing the
+// distances from the scan points to the lines through their pairs. The threshold starts at
+// maxCorrespondence and shrinks to minCorrespondence as the alignment improves, so that wrong pairs stop
+// pulling the scan once it is roughly in place. It returns the resulting pose along with the mean
+// distance between paired points.
+//
+// pointcloud.RegisterPointCloudICP is not used here: its finite difference optimization over all six
+// degrees of freedom does not reliably converge in translation on planar scans.
+func matchScan(
+	scan []r3.Vector, target *pointcloud.KDTree, guess pose2D, maxCorrespondence, minCorrespondence float64,
+) (pose2D, float64, error) {
+	if target == nil || target.Size() == 0 {
+		return guess, 0, errors.New("cannot match scan against an empty map")
+	}
+	normals := map[r3.Vector]r3.Vector{}
+	pose := guess
+	threshold := maxCorrespondence
+	var cost float64
+	for i := 0; i < icpMaxIterations; i++ {
+		var jtj [3][3]float64
+		var jtr [3]float64
+		pairs := 0
+		cost = 0
+		for _, p := range scan {
+			world := pose.transform(p)
+			nearest, _, dist, ok := target.NearestNeighbor(world)
+			if !ok || dist > threshold {
+				continue
+			}
+			n, ok := normals[nearest]
+			if !ok {
+				n = estimateNormal(target, nearest)
+				normals[nearest] = n
+			}
+			// The residual is the signed distance from the point to the line, and its jacobian is taken
+			// with respect to a translation and a rotation about the origin of the world frame.
+			residual := world.Sub(nearest).Dot(n)
+			jac := [3]float64{n.X, n.Y, -world.Y*n.X + world.X*n.Y}
+			for r := 0; r < 3; r++ {
+				for c := 0; c < 3; c++ {
+					jtj[r][c] += jac[r] * jac[c]
+				}
+				jtr[r] -= jac[r] * residual
+			}
+			pairs++
+			cost += dist
+		}
+		if float64(pairs) < icpMinInlierRatio*float64(len(scan)) {
+			return guess, 0, errors.Errorf("only %d of %d scan points matched the map", pairs, len(scan))
+		}
+		cost /= float64(pairs)
+
+		var x mat.VecDense
+		a := mat.NewDense(3, 3, []float64{
+			jtj[0][0], jtj[0][1], jtj[0][2],
+			jtj[1][0], jtj[1][1], jtj[1][2],
+			jtj[2][0], jtj[2][1], jtj[2][2],
+		})
+		if err := x.SolveVec(a, mat.NewVecDense(3, jtr[:])); err != nil {
+			return guess, 0, errors.Wrap(err, "scan matching is degenerate")
+		}
+		// The update is expressed in the world frame, so it is applied on the left of the current pose.
+		delta := pose2D{X: x.AtVec(0), Y: x.AtVec(1), Theta: x.AtVec(2)}
+		pose = delta.compose(pose)
+
+		converged := math.Hypot(delta.X, delta.Y) < icpTranslationTolerance && math.Abs(delta.Theta) < icpRotationTolerance
+		if converged && threshold == minCorrespondence {
+			break
+		}
+		threshold = math.Max(minCorrespondence, threshold*icpThresholdDecay)
+	}
+	return pose, cost, nil
+}
+
+// inlierRatio returns the fraction of the points of a scan, placed at pose, that lie within threshold of a
+// target point.
+func inlierRatio(scan []r3.Vector, target *pointcloud.KDTree, pose pose2D, threshold float64) float64 {
+	if len(scan) == 0 || target == nil || target.Size() == 0 {
+		return 0
+	}
+	inliers := 0
+	for _, p := range scan {
+		if _, _, dist, ok := target.NearestNeighbor(pose.transform(p)); ok && dist <= threshold {
+			inliers++
+		}
+	}
+	return float64(inliers) / float64(len(scan))
+}
+
+// estimateNormal returns the unit normal of the line best fitting the neighborhood of a target point.
+func estimateNormal(target *pointcloud.KDTree, p r3.Vector) r3.Vector {
+	neighbors := target.KNearestNeighbors(p, normalNeighbors, true)
+	var mean r3.Vector
+	for _, n := range neighbors {
+		mean = mean.Add(n.P)
+	}
+	mean = mean.Mul(1 / float64(len(neighbors)))
+	var sxx, sxy, syy float64
+	for _, n := range neighbors {
+		d := n.P.Sub(mean)
+		sxx += d.X * d.X
+		sxy += d.X * d.Y
+		syy += d.Y * d.Y
+	}
+	// The line direction is the principal axis of the neighborhood's covariance.
+	phi := 0.5 * math.Atan2(2*sxy, sxx-syy)
+	return r3.Vector{X: -math.Sin(phi), Y: math.Cos(phi)}
+}
@@ -0,0 +1,232 @@
+package native
+
+import (
+	"math"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/pointcloud"
+)
+
+// segment is a wall of the simulated environment.
+type segment struct {
+	a, b r3.Vector
+}
+
+// testRoom is a room of about 6m x 4m with a pillar, so that scans are not symmetric. Walls are kept off
+// of cell boundaries so that occupancy checks are not sensitive to rounding.
+var testRoom = []segment{
+	{r3.Vector{X: -3020, Y: -2020}, r3.Vector{X: 3020, Y: -2020}},
+	{r3.Vector{X: 3020, Y: -2020}, r3.Vector{X: 3020, Y: 2020}},
+	{r3.Vector{X: 3020, Y: 2020}, r3.Vector{X: -3020, Y: 2020}},
+	{r3.Vector{X: -3020, Y: 2020}, r3.Vector{X: -3020, Y: -2020}},
+	{r3.Vector{X: 1020, Y: 520}, r3.Vector{X: 1520, Y: 520}},
+	{r3.Vector{X: 1520, Y: 520}, r3.Vector{X: 1520, Y: 1020}},
+	{r3.Vector{X: 1520, Y: 1020}, r3.Vector{X: 1020, Y: 1020}},
+	{r3.Vector{X: 1020, Y: 1020}, r3.Vector{X: 1020, Y: 520}},
+}
+
+// simulateScan casts one ray per degree from the given pose and returns the closest wall hits in the
+// sensor frame.
+func simulateScan(t *testing.T, pose pose2D) pointcloud.PointCloud {
+	t.Helper()
+	pc := pointcloud.New()
+	for deg := 0; deg < 360; deg++ {
+		angle := float64(deg) * math.Pi / 180
+		dir := r3.Vector{X: math.Cos(pose.Theta + angle), Y: math.Sin(pose.Theta + angle)}
+		best := math.Inf(1)
+		for _, s := range testRoom {
+			if d, ok := raySegment(r3.Vector{X: pose.X, Y: pose.Y}, dir, s); ok && d < best {
+				best = d
+			}
+		}
+		if math.IsInf(best, 1) {
+			continue
+		}
+		p := r3.Vector{X: best * math.Cos(angle), Y: best * math.Sin(angle)}
+		test.That(t, pc.Set(p, pointcloud.NewBasicData()), test.ShouldBeNil)
+	}
+	return pc
+}
+
+func raySegment(origin, dir r3.Vector, s segment) (float64, bool) {
+	e := s.b.Sub(s.a)
+	denom := dir.X*e.Y - dir.Y*e.X
+	if math.Abs(denom) < 1e-9 {
+		return 0, false
+	}
+	w := s.a.Sub(origin)
+	dist := (w.X*e.Y - w.Y*e.X) / denom
+	u := (w.X*dir.Y - w.Y*dir.X) / denom
+	if dist <= 0 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return dist, true
+}
+
+func TestPose2D(t *testing.T) {
+	a := pose2D{X: 100, Y: -50, Theta: math.Pi / 2}
+	delta := pose2D{X: 10, Y: 0, Theta: math.Pi}
+	b := a.compose(delta)
+	test.That(t, b.X, test.ShouldAlmostEqual, 100)
+	test.That(t, b.Y, test.ShouldAlmostEqual, -40)
+	test.That(t, b.Theta, test.ShouldAlmostEqual, -math.Pi/2)
+
+	back := a.between(b)
+	test.That(t, back.X, test.ShouldAlmostEqual, delta.X)
+	test.That(t, back.Y, test.ShouldAlmostEqual, delta.Y)
+	test.That(t, math.Abs(back.Theta), test.ShouldAlmostEqual, math.Pi)
+
+	test.That(t, normalizeAngle(3*math.Pi), test.ShouldAlmostEqual, math.Pi)
+	test.That(t, normalizeAngle(-3*math.Pi/2), test.ShouldAlmostEqual, math.Pi/2)
+
+	pose := a.toPose()
+	test.That(t, pose.Point().X, test.ShouldAlmostEqual, 100)
+	test.That(t, pose.Orientation().EulerAngles().Yaw, test.ShouldAlmostEqual, math.Pi/2)
+}
+
+func TestOccupancyGrid(t *testing.T) {
+	grid := newOccupancyGrid(100)
+	wall := []r3.Vector{{X: 1050, Y: 50}, {X: 1050, Y: 150}}
+	grid.insertScan(r3.Vector{X: 50, Y: 50}, wall)
+	grid.insertScan(r3.Vector{X: 50, Y: 50}, wall)
+
+	test.That(t, grid.occupied(r3.Vector{X: 1010, Y: 10}), test.ShouldBeTrue)
+	test.That(t, grid.occupied(r3.Vector{X: 510, Y: 10}), test.ShouldBeFalse)
+	test.That(t, grid.cells[cellKey{5, 0}].misses, test.ShouldEqual, 2)
+
+	pc, err := grid.toPointCloud()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldEqual, 2)
+	d, ok := pc.At(1050, 50, 0)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, d.Value(), test.ShouldEqual, 100)
+	_, _, blue := d.RGB255()
+	test.That(t, blue, test.ShouldEqual, 100)
+
+	pcd, err := grid.toPCD()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(pcd), test.ShouldBeGreaterThan, 0)
+}
+
+func TestTraceRay(t *testing.T) {
+	var cells []cellKey
+	traceRay(cellKey{0, 0}, cellKey{3, -1}, func(k cellKey) { cells = append(cells, k) })
+	test.That(t, cells, test.ShouldResemble, []cellKey{{0, 0}, {1, 0}, {2, -1}})
+
+	cells = nil
+	traceRay(cellKey{2, 2}, cellKey{2, 2}, func(k cellKey) { cells = append(cells, k) })
+	test.That(t, cells, test.ShouldBeEmpty)
+}
+
+func TestMapperTracksMotion(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	m := newMapper(defaultMapperParams(), logger)
+
+	truth := pose2D{}
+	_, err := m.addScan(simulateScan(t, truth))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(m.keyframes), test.ShouldEqual, 1)
+
+	for i := 0; i < 15; i++ {
+		truth = truth.compose(pose2D{X: 60, Theta: 0.02})
+		_, err := m.addScan(simulateScan(t, truth))
+		test.That(t, err, test.ShouldBeNil)
+	}
+
+	estimate := m.position()
+	test.That(t, estimate.distance(truth), test.ShouldBeLessThan, 50)
+	test.That(t, estimate.Theta, test.ShouldAlmostEqual, truth.Theta, 0.03)
+	test.That(t, len(m.keyframes), test.ShouldBeGreaterThan, 1)
+
+	// The walls of the room should be in the map and its interior should be free.
+	m.mu.Lock()
+	grid := m.occupancyGrid()
+	m.mu.Unlock()
+	test.That(t, grid.occupied(r3.Vector{X: 3020, Y: 0}), test.ShouldBeTrue)
+	test.That(t, grid.occupied(r3.Vector{X: 0, Y: 2020}), test.ShouldBeTrue)
+	test.That(t, grid.occupied(r3.Vector{X: -1000, Y: 0}), test.ShouldBeFalse)
+}
+
+func TestMapperRejectsEmptyScan(t *testing.T) {
+	m := newMapper(defaultMapperParams(), golog.NewTestLogger(t))
+	pc := pointcloud.New()
+	test.That(t, pc.Set(r3.Vector{X: 1}, pointcloud.NewBasicData()), test.ShouldBeNil)
+	_, err := m.addScan(pc)
+	test.That(t, err, test.ShouldBeError, "scan has no points within range")
+}
+
+func TestMapperLoopClosure(t *testing.T) {
+	params := defaultMapperParams()
+	params.loopClosureMinGap = 5
+	params.loopClosureRadius = 400
+	m := newMapper(params, golog.NewTestLogger(t))
+
+	_, err := m.addScan(simulateScan(t, pose2D{}))
+	test.That(t, err, test.ShouldBeNil)
+	// Simulate odometry drift that grows along a loop returning to the start.
+	for i, p := range []pose2D{
+		{X: 250}, {X: 500}, {X: 750, Y: 250}, {X: 750, Y: 500}, {X: 500, Y: 750},
+		{X: 250, Y: 750}, {X: 0, Y: 500}, {X: 0, Y: 250}, {X: 0, Y: 50},
+	} {
+		drifted := pose2D{X: p.X + 5*float64(i+1), Y: p.Y}
+		m.mu.Lock()
+		m.pose = drifted
+		m.addKeyframe(&keyframe{Pose: drifted, Points: preprocessScan(simulateScan(t, p), m.params)})
+		m.closeLoop()
+		m.mu.Unlock()
+	}
+
+	test.That(t, m.loopClosures, test.ShouldBeGreaterThanOrEqualTo, 1)
+	last := m.keyframes[len(m.keyframes)-1].Pose
+	test.That(t, last.distance(pose2D{X: 0, Y: 50}), test.ShouldBeLessThan, 15)
+	test.That(t, m.gridDirty, test.ShouldBeTrue)
+
+	m.mu.Lock()
+	grid := m.occupancyGrid()
+	m.mu.Unlock()
+	test.That(t, m.gridDirty, test.ShouldBeFalse)
+	test.That(t, grid.occupied(r3.Vector{X: -3020, Y: 0}), test.ShouldBeTrue)
+}
+
+func TestInternalStateRoundTrip(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	m := newMapper(defaultMapperParams(), logger)
+	_, err := m.addScan(simulateScan(t, pose2D{}))
+	test.That(t, err, test.ShouldBeNil)
+	_, err = m.addScan(simulateScan(t, pose2D{X: 250}))
+	test.That(t, err, test.ShouldBeNil)
+
+	state, err := m.marshalState()
+	test.That(t, err, test.ShouldBeNil)
+
+	restored := newMapper(defaultMapperParams(), logger)
+	test.That(t, restored.unmarshalState(state), test.ShouldBeNil)
+	test.That(t, restored.position(), test.ShouldResemble, m.position())
+	test.That(t, len(restored.keyframes), test.ShouldEqual, len(m.keyframes))
+
+	original, err := m.pointCloudMap()
+	test.That(t, err, test.ShouldBeNil)
+	rebuilt, err := restored.pointCloudMap()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(rebuilt), test.ShouldEqual, len(original))
+
+	test.That(t, restored.unmarshalState([]byte(`{"version": 99}`)), test.ShouldBeError,
+		"unsupported slam internal state version 99")
+}
+
+func TestChunkedReader(t *testing.T) {
+	data := make([]byte, chunkSizeBytes+10)
+	f := chunkedReader(data)
+	chunk, err := f()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(chunk), test.ShouldEqual, chunkSizeBytes)
+	chunk, err = f()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(chunk), test.ShouldEqual, 10)
+	_, err = f()
+	test.That(t, err, test.ShouldNotBeNil)
+}
@@ -0,0 +1,372 @@
+// Package native implements a pure Go 2D lidar slam service that requires no external slam binaries.
+// This is an Experimental package.
+package native
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/slam"
+	slamConfig "go.viam.com/rdk/services/slam/slam_copy/config"
+	"go.viam.com/rdk/services/slam/slam_copy/dataprocess"
+	"go.viam.com/rdk/spatialmath"
+)
+
+var model = resource.NewDefaultModel("native")
+
+const (
+	defaultDataRateMsec = 200
+	defaultMapRateSec   = 60
+	mapFilePrefix       = "map"
+	mapFileType         = ".json"
+)
+
+func init() {
+	resource.RegisterService(slam.Subtype, model, resource.Registration[slam.Service, *slamConfig.Config]{
+		Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			c resource.Config,
+			logger golog.Logger,
+		) (slam.Service, error) {
+			return NewNative(ctx, deps, c, logger)
+		},
+	})
+}
+
+// algorithm is the scan processing behind a native slam service: either a mapper building a new map, or a
+// localizer tracking the pose of the sensor in a saved one.
+type algorithm interface {
+	addScan(pc pointcloud.PointCloud) (pose2D, error)
+	positionWithConfidence() (pose2D, float64)
+	pointCloudMap() ([]byte, error)
+	marshalState() ([]byte, error)
+}
+
+// nativeSLAM is a slam service that matches and maps point clouds in process.
+type nativeSLAM struct {
+	resource.Named
+	resource.AlwaysRebuild
+	primarySensorName string
+	cam               camera.Camera
+	algo              algorithm
+
+	dataDirectory string
+	useLiveData   bool
+	dataRateMs    int
+	mapRateSec    int
+
+	cancelFunc              func()
+	logger                  golog.Logger
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// NewNative returns a new native slam service. In live mode it polls the first configured sensor for
+// point clouds; otherwise it processes the PCD files found in the data subdirectory of data_dir in
+// filename order.
+//
+// If map_file is set, or map_rate_sec is 0 and a map was previously saved to data_dir, the service only
+// localizes against that map and never modifies it.
+func NewNative(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger golog.Logger) (slam.Service, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::native::New")
+	defer span.End()
+
+	svcConfig, err := resource.NativeConfig[*slamConfig.Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	if mode := slam.Mode(svcConfig.ConfigParams["mode"]); mode != slam.Dim2d {
+		return nil, errors.Errorf("native slam does not support mode %q, only %q", mode, slam.Dim2d)
+	}
+	params, err := parseMapperParams(svcConfig.ConfigParams)
+	if err != nil {
+		return nil, err
+	}
+
+	_, dataRateMsec, mapRateSec, useLiveData, _, err :=
+		slamConfig.GetOptionalParameters(svcConfig, "", defaultDataRateMsec, defaultMapRateSec, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := slamConfig.SetupDirectories(svcConfig.DataDirectory, logger); err != nil {
+		return nil, err
+	}
+
+	var algo algorithm = newMapper(params, logger)
+	mapFile := svcConfig.MapFile
+	if mapFile == "" && mapRateSec == 0 {
+		if mapFile, err = latestMap(svcConfig.DataDirectory); err != nil {
+			return nil, err
+		}
+	}
+	if mapFile != "" {
+		lparams, err := parseLocalizerParams(svcConfig.ConfigParams)
+		if err != nil {
+			return nil, err
+		}
+		if algo, err = newLocalizer(mapFile, params, lparams, logger); err != nil {
+			return nil, errors.Wrapf(err, "error loading map %v", mapFile)
+		}
+		if mapRateSec > 0 {
+			logger.Warn("native slam does not update a map it localizes in, ignoring map_rate_sec")
+			mapRateSec = 0
+		}
+		logger.Infof("localizing against map %v", mapFile)
+	}
+
+	slamSvc := &nativeSLAM{
+		Named:         conf.ResourceName().AsNamed(),
+		algo:          algo,
+		dataDirectory: svcConfig.DataDirectory,
+		useLiveData:   useLiveData,
+		dataRateMs:    dataRateMsec,
+		mapRateSec:    mapRateSec,
+		logger:        logger,
+	}
+	if useLiveData {
+		slamSvc.primarySensorName = svcConfig.Sensors[0]
+		slamSvc.cam, err = camera.FromDependencies(deps, slamSvc.primarySensorName)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting camera %v for slam service", slamSvc.primarySensorName)
+		}
+	}
+
+	cancelCtx, cancelFunc := context.WithCancel(context.Background())
+	slamSvc.cancelFunc = cancelFunc
+	if useLiveData {
+		slamSvc.startLiveProcess(cancelCtx)
+	} else {
+		slamSvc.startOfflineProcess(cancelCtx)
+	}
+	slamSvc.startMapSaveProcess(cancelCtx)
+
+	return slamSvc, nil
+}
+
+// parseMapperParams overrides the default mapper parameters with any given in config_params.
+func parseMapperParams(configParams map[string]string) (mapperParams, error) {
+	params := defaultMapperParams()
+	floatParams := map[string]*float64{
+		"resolution_mm":             &params.resolution,
+		"min_range_mm":              &params.minRange,
+		"max_range_mm":              &params.maxRange,
+		"min_height_mm":             &params.minHeight,
+		"max_height_mm":             &params.maxHeight,
+		"keyframe_distance_mm":      &params.keyframeDistance,
+		"max_correspondence_mm":     &params.maxCorrespondence,
+		"max_match_error_mm":        &params.maxMatchError,
+		"loop_closure_radius_mm":    &params.loopClosureRadius,
+		"loop_closure_max_error_mm": &params.loopClosureMaxError,
+	}
+	for key, dst := range floatParams {
+		if v, ok := configParams[key]; ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return params, errors.Wrapf(err, "error parsing config_params[%s]", key)
+			}
+			*dst = f
+		}
+	}
+	if v, ok := configParams["keyframe_angle_deg"]; ok {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return params, errors.Wrap(err, "error parsing config_params[keyframe_angle_deg]")
+		}
+		params.keyframeAngle = f * math.Pi / 180
+	}
+	intParams := map[string]*int{
+		"submap_size":          &params.submapSize,
+		"loop_closure_min_gap": &params.loopClosureMinGap,
+	}
+	for key, dst := range intParams {
+		if v, ok := configParams[key]; ok {
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return params, errors.Wrapf(err, "error parsing config_params[%s]", key)
+			}
+			*dst = i
+		}
+	}
+	if params.resolution <= 0 {
+		return params, errors.New("config_params[resolution_mm] must be greater than zero")
+	}
+	if params.submapSize <= 0 {
+		return params, errors.New("config_params[submap_size] must be greater than zero")
+	}
+	return params, nil
+}
+
+// GetPosition returns the current pose of the sensor in the map frame, and the name of the sensor.
+func (slamSvc *nativeSLAM) GetPosition(ctx context.Context) (spatialmath.Pose, string, error) {
+	_, span := trace.StartSpan(ctx, "slam::native::GetPosition")
+	defer span.End()
+	pose, _ := slamSvc.algo.positionWithConfidence()
+	return pose.toPose(), slamSvc.primarySensorName, nil
+}
+
+// GetPositionWithConfidence returns the same as GetPosition along with the fraction of the points of the
+// last scan that matched the map.
+func (slamSvc *nativeSLAM) GetPositionWithConfidence(ctx context.Context) (spatialmath.Pose, string, float64, error) {
+	_, span := trace.StartSpan(ctx, "slam::native::GetPositionWithConfidence")
+	defer span.End()
+	pose, confidence := slamSvc.algo.positionWithConfidence()
+	return pose.toPose(), slamSvc.primarySensorName, confidence, nil
+}
+
+// GetPointCloudMap returns a callback function which will return the next chunk of the current occupancy
+// grid encoded as a PCD.
+func (slamSvc *nativeSLAM) GetPointCloudMap(ctx context.Context) (func() ([]byte, error), error) {
+	_, span := trace.StartSpan(ctx, "slam::native::GetPointCloudMap")
+	defer span.End()
+	pcd, err := slamSvc.algo.pointCloudMap()
+	if err != nil {
+		return nil, err
+	}
+	return chunkedReader(pcd), nil
+}
+
+// GetInternalState returns a callback function which will return the next chunk of the serialized
+// keyframes of the map. When localizing, it returns the map file that was loaded.
+func (slamSvc *nativeSLAM) GetInternalState(ctx context.Context) (func() ([]byte, error), error) {
+	_, span := trace.StartSpan(ctx, "slam::native::GetInternalState")
+	defer span.End()
+	state, err := slamSvc.algo.marshalState()
+	if err != nil {
+		return nil, err
+	}
+	return chunkedReader(state), nil
+}
+
+// Close stops all background processing and saves the map if map saving is enabled.
+func (slamSvc *nativeSLAM) Close(ctx context.Context) error {
+	slamSvc.cancelFunc()
+	slamSvc.activeBackgroundWorkers.Wait()
+	if slamSvc.mapRateSec > 0 {
+		return slamSvc.saveMap()
+	}
+	return nil
+}
+
+// startLiveProcess polls the camera for point clouds at the configured data rate and adds them to the map.
+func (slamSvc *nativeSLAM) startLiveProcess(cancelCtx context.Context) {
+	slamSvc.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer slamSvc.activeBackgroundWorkers.Done()
+		ticker := time.NewTicker(time.Millisecond * time.Duration(slamSvc.dataRateMs))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-cancelCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			pc, err := slamSvc.cam.NextPointCloud(cancelCtx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					slamSvc.logger.Warnw("error getting point cloud", "error", err)
+				}
+				continue
+			}
+			if _, err := slamSvc.algo.addScan(pc); err != nil {
+				slamSvc.logger.Debugw("skipping scan", "error", err)
+			}
+		}
+	})
+}
+
+// startOfflineProcess adds every PCD file in the data directory to the map, in filename order. Filenames
+// written by the slam services include a timestamp, so this is also capture order.
+func (slamSvc *nativeSLAM) startOfflineProcess(cancelCtx context.Context) {
+	slamSvc.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer slamSvc.activeBackgroundWorkers.Done()
+		files, err := filepath.Glob(filepath.Join(slamSvc.dataDirectory, "data", "*.pcd"))
+		if err != nil {
+			slamSvc.logger.Errorw("error listing slam data", "error", err)
+			return
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			if cancelCtx.Err() != nil {
+				return
+			}
+			pc, err := readPCDFile(file)
+			if err != nil {
+				slamSvc.logger.Warnw("error reading point cloud", "file", file, "error", err)
+				continue
+			}
+			if _, err := slamSvc.algo.addScan(pc); err != nil {
+				slamSvc.logger.Debugw("skipping scan", "file", file, "error", err)
+			}
+		}
+		slamSvc.logger.Infof("finished processing %d offline scans", len(files))
+	})
+}
+
+// startMapSaveProcess writes the internal state to the map directory every map_rate_sec seconds.
+func (slamSvc *nativeSLAM) startMapSaveProcess(cancelCtx context.Context) {
+	if slamSvc.mapRateSec <= 0 {
+		return
+	}
+	slamSvc.activeBackgroundWorkers.Add(1)
+	goutils.PanicCapturingGo(func() {
+		defer slamSvc.activeBackgroundWorkers.Done()
+		for goutils.SelectContextOrWait(cancelCtx, time.Duration(slamSvc.mapRateSec)*time.Second) {
+			if err := slamSvc.saveMap(); err != nil {
+				slamSvc.logger.Warnw("error saving map", "error", err)
+			}
+		}
+	})
+}
+
+// saveMap writes the internal state to a timestamped file in the map directory.
+func (slamSvc *nativeSLAM) saveMap() error {
+	state, err := slamSvc.algo.marshalState()
+	if err != nil {
+		return err
+	}
+	filename := dataprocess.CreateTimestampFilename(
+		filepath.Join(slamSvc.dataDirectory, "map"), mapFilePrefix, mapFileType, time.Now())
+	if err := os.WriteFile(filename, state, 0o600); err != nil {
+		return errors.Wrap(err, "error writing map")
+	}
+	return nil
+}
+
+// latestMap returns the newest map saved to the map directory, or an empty string if there is none.
+func latestMap(dataDirectory string) (string, error) {
+	maps, err := filepath.Glob(filepath.Join(dataDirectory, "map", mapFilePrefix+"_data_*"+mapFileType))
+	if err != nil {
+		return "", err
+	}
+	if len(maps) == 0 {
+		return "", nil
+	}
+	sort.Strings(maps)
+	return maps[len(maps)-1], nil
+}
+
+func readPCDFile(filename string) (pointcloud.PointCloud, error) {
+	//nolint:gosec
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer goutils.UncheckedErrorFunc(f.Close)
+	return pointcloud.ReadPCD(f)
+}
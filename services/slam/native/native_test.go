@@ -0,0 +1,232 @@
+package native
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/edaniels/golog"
+	"go.viam.com/test"
+	"go.viam.com/utils/testutils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/pointcloud"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/slam"
+	slamConfig "go.viam.com/rdk/services/slam/slam_copy/config"
+	"go.viam.com/rdk/services/slam/slam_copy/dataprocess"
+)
+
+// scanCamera is a camera that returns simulated scans along a straight path.
+type scanCamera struct {
+	camera.Camera
+	t     *testing.T
+	mu    sync.Mutex
+	truth pose2D
+}
+
+func (c *scanCamera) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.truth.X < 600 {
+		c.truth.X += 20
+	}
+	return simulateScan(c.t, c.truth), nil
+}
+
+func createNativeService(t *testing.T, conf *slamConfig.Config, deps resource.Dependencies) (slam.Service, error) {
+	t.Helper()
+	_, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	cfgService := resource.Config{Name: "test", API: slam.Subtype, Model: model}
+	cfgService.ConvertedAttributes = conf
+	return NewNative(context.Background(), deps, cfgService, golog.NewTestLogger(t))
+}
+
+func TestNewNativeValidation(t *testing.T) {
+	useLiveData := false
+	conf := &slamConfig.Config{
+		ConfigParams:  map[string]string{"mode": "rgbd"},
+		DataDirectory: t.TempDir(),
+		UseLiveData:   &useLiveData,
+	}
+	_, err := createNativeService(t, conf, resource.Dependencies{})
+	test.That(t, err, test.ShouldBeError, `native slam does not support mode "rgbd", only "2d"`)
+
+	conf.ConfigParams = map[string]string{"mode": "2d", "resolution_mm": "abc"}
+	_, err = createNativeService(t, conf, resource.Dependencies{})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error parsing config_params[resolution_mm]")
+
+	conf.ConfigParams = map[string]string{"mode": "2d", "submap_size": "0"}
+	_, err = createNativeService(t, conf, resource.Dependencies{})
+	test.That(t, err, test.ShouldBeError, "config_params[submap_size] must be greater than zero")
+
+	useLiveData = true
+	conf.ConfigParams = map[string]string{"mode": "2d"}
+	conf.Sensors = []string{"lidar"}
+	_, err = createNativeService(t, conf, resource.Dependencies{})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error getting camera lidar for slam service")
+}
+
+func TestParseMapperParams(t *testing.T) {
+	params, err := parseMapperParams(map[string]string{
+		"mode":                 "2d",
+		"resolution_mm":        "25",
+		"keyframe_angle_deg":   "90",
+		"loop_closure_min_gap": "7",
+	})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, params.resolution, test.ShouldEqual, 25)
+	test.That(t, params.keyframeAngle, test.ShouldAlmostEqual, math.Pi/2)
+	test.That(t, params.loopClosureMinGap, test.ShouldEqual, 7)
+	test.That(t, params.submapSize, test.ShouldEqual, defaultMapperParams().submapSize)
+}
+
+func TestNativeOffline(t *testing.T) {
+	dataDir := t.TempDir()
+	test.That(t, slamConfig.SetupDirectories(dataDir, golog.NewTestLogger(t)), test.ShouldBeNil)
+
+	// Write a recorded dataset of scans taken while driving forward.
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		filename := dataprocess.CreateTimestampFilename(
+			filepath.Join(dataDir, "data"), "lidar", ".pcd", start.Add(time.Duration(i)*time.Second))
+		test.That(t, dataprocess.WritePCDToFile(simulateScan(t, pose2D{X: 50 * float64(i)}), filename), test.ShouldBeNil)
+	}
+
+	useLiveData := false
+	mapRateSec := 100
+	conf := &slamConfig.Config{
+		ConfigParams:  map[string]string{"mode": "2d"},
+		DataDirectory: dataDir,
+		UseLiveData:   &useLiveData,
+		MapRateSec:    &mapRateSec,
+	}
+	svc, err := createNativeService(t, conf, resource.Dependencies{})
+	test.That(t, err, test.ShouldBeNil)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		pose, componentReference, err := svc.GetPosition(context.Background())
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, componentReference, test.ShouldEqual, "")
+		test.That(tb, pose.Point().X, test.ShouldAlmostEqual, 450, 20)
+		test.That(tb, pose.Point().Y, test.ShouldAlmostEqual, 0, 20)
+	})
+
+	pcd, err := slam.GetPointCloudMapFull(context.Background(), svc)
+	test.That(t, err, test.ShouldBeNil)
+	pc, err := pointcloud.ReadPCD(bytes.NewReader(pcd))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, pc.Size(), test.ShouldBeGreaterThan, 100)
+
+	state, err := slam.GetInternalStateFull(context.Background(), svc)
+	test.That(t, err, test.ShouldBeNil)
+	var parsed internalState
+	test.That(t, json.Unmarshal(state, &parsed), test.ShouldBeNil)
+	test.That(t, parsed.Version, test.ShouldEqual, internalStateVersion)
+	test.That(t, len(parsed.Keyframes), test.ShouldBeGreaterThan, 1)
+
+	test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	maps, err := filepath.Glob(filepath.Join(dataDir, "map", "map_data_*.json"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(maps), test.ShouldEqual, 1)
+}
+
+func TestNativeLive(t *testing.T) {
+	cam := &scanCamera{t: t}
+	deps := resource.Dependencies{camera.Named("lidar"): cam}
+
+	useLiveData := true
+	mapRateSec := 0
+	conf := &slamConfig.Config{
+		Sensors:       []string{"lidar"},
+		ConfigParams:  map[string]string{"mode": "2d"},
+		DataDirectory: t.TempDir(),
+		UseLiveData:   &useLiveData,
+		DataRateMsec:  10,
+		MapRateSec:    &mapRateSec,
+	}
+	svc, err := createNativeService(t, conf, deps)
+	test.That(t, err, test.ShouldBeNil)
+	defer func() {
+		test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	}()
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		pose, componentReference, err := svc.GetPosition(context.Background())
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, componentReference, test.ShouldEqual, "lidar")
+		test.That(tb, pose.Point().X, test.ShouldAlmostEqual, 600, 20)
+	})
+}
+
+func TestNativeLocalization(t *testing.T) {
+	mapFile := saveTestMap(t, mapFileType)
+	mapData, err := os.ReadFile(mapFile)
+	test.That(t, err, test.ShouldBeNil)
+
+	dataDir := t.TempDir()
+	test.That(t, slamConfig.SetupDirectories(dataDir, golog.NewTestLogger(t)), test.ShouldBeNil)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		filename := dataprocess.CreateTimestampFilename(
+			filepath.Join(dataDir, "data"), "lidar", ".pcd", start.Add(time.Duration(i)*time.Second))
+		scan := simulateScan(t, pose2D{X: -1000, Y: 50 * float64(i), Theta: math.Pi / 2})
+		test.That(t, dataprocess.WritePCDToFile(scan, filename), test.ShouldBeNil)
+	}
+
+	useLiveData := false
+	conf := &slamConfig.Config{
+		ConfigParams:  map[string]string{"mode": "2d"},
+		DataDirectory: dataDir,
+		UseLiveData:   &useLiveData,
+		MapFile:       mapFile,
+	}
+	svc, err := createNativeService(t, conf, resource.Dependencies{})
+	test.That(t, err, test.ShouldBeNil)
+
+	testutils.WaitForAssertion(t, func(tb testing.TB) {
+		tb.Helper()
+		pose, _, confidence, err := slam.GetPositionWithConfidence(context.Background(), svc)
+		test.That(tb, err, test.ShouldBeNil)
+		test.That(tb, pose.Point().X, test.ShouldAlmostEqual, -1000, 30)
+		test.That(tb, pose.Point().Y, test.ShouldAlmostEqual, 200, 30)
+		test.That(tb, confidence, test.ShouldBeGreaterThan, 0.9)
+	})
+
+	// The map is never modified or saved while localizing.
+	state, err := slam.GetInternalStateFull(context.Background(), svc)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, state, test.ShouldResemble, mapData)
+	test.That(t, svc.Close(context.Background()), test.ShouldBeNil)
+	maps, err := filepath.Glob(filepath.Join(dataDir, "map", "*"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, maps, test.ShouldBeEmpty)
+}
+
+func TestLatestMap(t *testing.T) {
+	dataDir := t.TempDir()
+	test.That(t, slamConfig.SetupDirectories(dataDir, golog.NewTestLogger(t)), test.ShouldBeNil)
+	mapFile, err := latestMap(dataDir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mapFile, test.ShouldBeEmpty)
+
+	start := time.Now()
+	var newest string
+	for i := 0; i < 3; i++ {
+		newest = dataprocess.CreateTimestampFilename(
+			filepath.Join(dataDir, "map"), mapFilePrefix, mapFileType, start.Add(time.Duration(i)*time.Minute))
+		test.That(t, os.WriteFile(newest, []byte("{}"), 0o600), test.ShouldBeNil)
+	}
+	mapFile, err = latestMap(dataDir)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, mapFile, test.ShouldEqual, newest)
+}
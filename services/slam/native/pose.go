@@ -0,0 +1,63 @@
+package native
+
+import (
+	"math"
+
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// pose2D is a planar pose with a position in millimeters and a heading in radians.
+type pose2D struct {
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Theta float64 `json:"theta"`
+}
+
+// toPose converts the planar pose into a spatialmath.Pose lying in the XY plane.
+func (p pose2D) toPose() spatialmath.Pose {
+	return spatialmath.NewPose(r3.Vector{X: p.X, Y: p.Y}, &spatialmath.EulerAngles{Yaw: p.Theta})
+}
+
+// transform applies the pose to a point expressed in the pose's frame.
+func (p pose2D) transform(pt r3.Vector) r3.Vector {
+	sin, cos := math.Sincos(p.Theta)
+	return r3.Vector{
+		X: p.X + cos*pt.X - sin*pt.Y,
+		Y: p.Y + sin*pt.X + cos*pt.Y,
+	}
+}
+
+// compose returns the pose reached by applying delta in the frame of p.
+func (p pose2D) compose(delta pose2D) pose2D {
+	pt := p.transform(r3.Vector{X: delta.X, Y: delta.Y})
+	return pose2D{X: pt.X, Y: pt.Y, Theta: normalizeAngle(p.Theta + delta.Theta)}
+}
+
+// between returns the delta such that p.compose(delta) == other.
+func (p pose2D) between(other pose2D) pose2D {
+	sin, cos := math.Sincos(p.Theta)
+	dx, dy := other.X-p.X, other.Y-p.Y
+	return pose2D{
+		X:     cos*dx + sin*dy,
+		Y:     -sin*dx + cos*dy,
+		Theta: normalizeAngle(other.Theta - p.Theta),
+	}
+}
+
+// distance returns the euclidean distance between the positions of two poses.
+func (p pose2D) distance(other pose2D) float64 {
+	return math.Hypot(other.X-p.X, other.Y-p.Y)
+}
+
+// normalizeAngle wraps an angle in radians to the range (-pi, pi].
+func normalizeAngle(theta float64) float64 {
+	theta = math.Mod(theta, 2*math.Pi)
+	if theta > math.Pi {
+		theta -= 2 * math.Pi
+	} else if theta <= -math.Pi {
+		theta += 2 * math.Pi
+	}
+	return theta
+}
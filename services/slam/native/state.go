@@ -0,0 +1,73 @@
+package native
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	internalStateVersion = 1
+	chunkSizeBytes       = 1 * 1024 * 1024
+)
+
+// internalState is the serialized form of a mapper returned by GetInternalState and written to the map
+// directory. It holds every keyframe, which is enough to rebuild the occupancy grid and resume mapping.
+type internalState struct {
+	Version      int         `json:"version"`
+	ResolutionMM float64     `json:"resolution_mm"`
+	Pose         pose2D      `json:"pose"`
+	Keyframes    []*keyframe `json:"keyframes"`
+}
+
+// marshalState serializes the keyframes and current pose of the mapper.
+func (m *mapper) marshalState() ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return json.Marshal(internalState{
+		Version:      internalStateVersion,
+		ResolutionMM: m.params.resolution,
+		Pose:         m.pose,
+		Keyframes:    m.keyframes,
+	})
+}
+
+// unmarshalState replaces the keyframes and pose of the mapper with a state produced by marshalState.
+func (m *mapper) unmarshalState(data []byte) error {
+	var state internalState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return errors.Wrap(err, "error parsing slam internal state")
+	}
+	if state.Version != internalStateVersion {
+		return errors.Errorf("unsupported slam internal state version %d", state.Version)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.params.resolution = state.ResolutionMM
+	m.pose = state.Pose
+	m.lastDelta = pose2D{}
+	m.keyframes = state.Keyframes
+	m.grid = newOccupancyGrid(state.ResolutionMM)
+	m.gridDirty = true
+	m.refreshSubmap()
+	return nil
+}
+
+// chunkedReader returns a callback that yields data in chunks of at most chunkSizeBytes, followed by io.EOF,
+// matching the callbacks returned by the streaming slam.Service methods.
+func chunkedReader(data []byte) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		if len(data) == 0 {
+			return nil, io.EOF
+		}
+		n := chunkSizeBytes
+		if n > len(data) {
+			n = len(data)
+		}
+		chunk := data[:n]
+		data = data[n:]
+		return chunk, nil
+	}
+}
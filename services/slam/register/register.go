@@ -5,4 +5,5 @@ import (
 	// for slam models.
 	_ "go.viam.com/rdk/services/slam/builtin"
 	_ "go.viam.com/rdk/services/slam/fake"
+	_ "go.viam.com/rdk/services/slam/native"
 )
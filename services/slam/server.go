@@ -8,6 +8,7 @@ import (
 	"go.opencensus.io/trace"
 	commonpb "go.viam.com/api/common/v1"
 	pb "go.viam.com/api/service/slam/v1"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"go.viam.com/rdk/protoutils"
 	"go.viam.com/rdk/resource"
@@ -38,15 +39,21 @@ func (server *subtypeServer) GetPosition(ctx context.Context, req *pb.GetPositio
 		return nil, err
 	}
 
-	p, componentReference, err := svc.GetPosition(ctx)
-	if err != nil {
+	// Services that report a confidence in their pose send it back in the extra field.
+	p, componentReference, confidence, err := GetPositionWithConfidence(ctx, svc)
+	if err != nil && !errors.Is(err, ErrNoConfidence) {
 		return nil, err
 	}
-
-	return &pb.GetPositionResponse{
+	resp := &pb.GetPositionResponse{
 		Pose:               spatialmath.PoseToProtobuf(p),
 		ComponentReference: componentReference,
-	}, nil
+	}
+	if err == nil {
+		if resp.Extra, err = structpb.NewStruct(map[string]interface{}{ConfidenceKey: confidence}); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
 }
 
 // GetPointCloudMap returns the slam service's slam algo's current map state in PCD format as
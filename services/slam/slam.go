@@ -57,6 +57,40 @@ type Service interface {
 	GetInternalState(ctx context.Context) (func() ([]byte, error), error)
 }
 
+// ConfidenceKey is the key under which a pose confidence is reported in the extra field of a GetPosition
+// response.
+const ConfidenceKey = "confidence"
+
+// ErrNoConfidence is returned by GetPositionWithConfidence, along with the pose and component reference,
+// when a slam service has no confidence to report for its pose.
+var ErrNoConfidence = errors.New("slam service does not report pose confidence")
+
+// Localizer is implemented by slam services that can report how confident they are in the pose returned
+// by GetPosition, such as services running in localization only mode against a saved map.
+type Localizer interface {
+	// GetPositionWithConfidence returns the same pose and component reference as GetPosition along with
+	// a confidence in that pose, from 0 (lost) to 1 (certain). If no confidence is available, it still
+	// returns the pose and component reference, with an error wrapping ErrNoConfidence.
+	GetPositionWithConfidence(ctx context.Context) (spatialmath.Pose, string, float64, error)
+}
+
+// GetPositionWithConfidence returns the pose of the given slam service along with its confidence in that
+// pose. If the service does not report a confidence, it returns the pose and component reference with an
+// error wrapping ErrNoConfidence.
+func GetPositionWithConfidence(ctx context.Context, slamSvc Service) (spatialmath.Pose, string, float64, error) {
+	ctx, span := trace.StartSpan(ctx, "slam::GetPositionWithConfidence")
+	defer span.End()
+	localizer, ok := slamSvc.(Localizer)
+	if !ok {
+		pose, componentReference, err := slamSvc.GetPosition(ctx)
+		if err != nil {
+			return nil, "", 0, err
+		}
+		return pose, componentReference, 0, errors.Wrapf(ErrNoConfidence, "slam service %v", slamSvc.Name())
+	}
+	return localizer.GetPositionWithConfidence(ctx)
+}
+
 // HelperConcatenateChunksToFull concatenates the chunks from a streamed grpc endpoint.
 func HelperConcatenateChunksToFull(f func() ([]byte, error)) ([]byte, error) {
 	var fullBytes []byte
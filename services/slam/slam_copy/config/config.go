@@ -53,6 +53,9 @@ type Config struct {
 	MapRateSec          *int              `json:"map_rate_sec"`
 	Port                string            `json:"port"`
 	DeleteProcessedData *bool             `json:"delete_processed_data"`
+	// MapFile is a previously saved map to load on startup. Unless map_rate_sec is also given, the
+	// slam system only localizes against this map instead of extending it.
+	MapFile string `json:"map_file"`
 }
 
 // NewConfig creates a SLAM config from a service config.
@@ -114,7 +117,9 @@ func GetOptionalParameters(config *Config, defaultPort string,
 	}
 
 	mapRateSec := 0
-	if config.MapRateSec == nil {
+	if config.MapRateSec == nil && config.MapFile != "" {
+		logger.Debug("no map_rate_sec given with map_file, localizing without updating the map")
+	} else if config.MapRateSec == nil {
 		logger.Debugf("no map_rate_sec given, setting to default value of %d", defaultMapRateSec)
 		mapRateSec = defaultMapRateSec
 	} else {
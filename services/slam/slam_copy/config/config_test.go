@@ -195,4 +195,22 @@ func TestGetOptionalParameters(t *testing.T) {
 		_, _, _, _, _, err = GetOptionalParameters(cfg, "localhost", 1001, 1002, logger)
 		test.That(t, err, test.ShouldBeError, newError("sensors field cannot be empty when use_live_data is set to true"))
 	})
+
+	t.Run("Map file without map_rate_sec", func(t *testing.T) {
+		cfgService := makeCfgService()
+		cfgService.Attributes["use_live_data"] = false
+		cfgService.Attributes["map_file"] = "/maps/warehouse.pbstream"
+		cfg, err := NewConfig(cfgService)
+		test.That(t, err, test.ShouldBeNil)
+		_, _, mapRateSec, _, _, err := GetOptionalParameters(cfg, "localhost", 1001, 1002, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mapRateSec, test.ShouldEqual, 0)
+
+		cfgService.Attributes["map_rate_sec"] = 5
+		cfg, err = NewConfig(cfgService)
+		test.That(t, err, test.ShouldBeNil)
+		_, _, mapRateSec, _, _, err = GetOptionalParameters(cfg, "localhost", 1001, 1002, logger)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, mapRateSec, test.ShouldEqual, 5)
+	})
 }
@@ -12,6 +12,8 @@ import (
 	pb "go.viam.com/api/service/slam/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+
+	"go.viam.com/rdk/services/slam/slam_copy/dataprocess"
 )
 
 // This increases the message size from 4MB to 32MB to match the RDK.
@@ -34,6 +36,24 @@ func SetupDirectories(dataDirectory string, logger golog.Logger) error {
 	return nil
 }
 
+// InstallMapFile copies a previously saved map into the map subdirectory of the data directory, named
+// so that it is the newest map there and the one the slam library loads on startup.
+func InstallMapFile(dataDirectory, mapFile, mapFileType string) error {
+	if ext := filepath.Ext(mapFile); ext != mapFileType {
+		return errors.Errorf("map_file %v must have extension %v", mapFile, mapFileType)
+	}
+	//nolint:gosec
+	data, err := os.ReadFile(mapFile)
+	if err != nil {
+		return errors.Wrap(err, "error reading map_file")
+	}
+	filename := dataprocess.CreateTimestampFilename(filepath.Join(dataDirectory, "map"), "map", mapFileType, time.Now())
+	if err := os.WriteFile(filename, data, 0o600); err != nil {
+		return errors.Wrap(err, "error installing map_file")
+	}
+	return nil
+}
+
 // SetupGRPCConnection uses the defined port to create a GRPC client for communicating with the SLAM algorithms.
 func SetupGRPCConnection(
 	ctx context.Context,
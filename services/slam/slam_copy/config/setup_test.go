@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/edaniels/golog"
@@ -72,3 +73,23 @@ func TestSetupDirectories(t *testing.T) {
 		test.That(t, fmt.Sprint(err), test.ShouldContainSubstring, "issue creating directory at")
 	})
 }
+
+func TestInstallMapFile(t *testing.T) {
+	logger := golog.NewTestLogger(t)
+	dataDir := t.TempDir()
+	test.That(t, SetupDirectories(dataDir, logger), test.ShouldBeNil)
+
+	mapFile := filepath.Join(t.TempDir(), "warehouse.pbstream")
+	test.That(t, os.WriteFile(mapFile, []byte("map"), 0o600), test.ShouldBeNil)
+
+	err := InstallMapFile(dataDir, mapFile, ".osa")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "must have extension .osa")
+
+	test.That(t, InstallMapFile(dataDir, mapFile, ".pbstream"), test.ShouldBeNil)
+	installed, err := filepath.Glob(filepath.Join(dataDir, "map", "map_data_*.pbstream"))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, len(installed), test.ShouldEqual, 1)
+	data, err := os.ReadFile(installed[0])
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, data, test.ShouldResemble, []byte("map"))
+}
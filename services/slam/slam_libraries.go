@@ -36,6 +36,7 @@ var cartographerMetadata = LibraryMetadata{
 	AlgoType:       Dense,
 	SlamMode:       map[string]Mode{"2d": Dim2d},
 	BinaryLocation: "carto_grpc_server",
+	MapFileType:    ".pbstream",
 }
 
 var orbslamv3Metadata = LibraryMetadata{
@@ -43,6 +44,7 @@ var orbslamv3Metadata = LibraryMetadata{
 	AlgoType:       Sparse,
 	SlamMode:       map[string]Mode{"mono": Mono, "rgbd": Rgbd},
 	BinaryLocation: "orb_grpc_server",
+	MapFileType:    ".osa",
 }
 
 // LibraryMetadata contains all pertinent information for defining a SLAM library/algorithm including the
@@ -52,4 +54,6 @@ type LibraryMetadata struct {
 	AlgoType       Library
 	SlamMode       map[string]Mode
 	BinaryLocation string
+	// MapFileType is the extension of the maps the library saves and loads.
+	MapFileType string
 }
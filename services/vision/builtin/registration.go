@@ -11,6 +11,7 @@ import (
 
 	"go.viam.com/rdk/resource"
 	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/vision/classification"
 	objdet "go.viam.com/rdk/vision/objectdetection"
 	"go.viam.com/rdk/vision/segmentation"
 )
@@ -50,6 +51,30 @@ func registerTfliteClassifier(ctx context.Context, mm modelMap, conf *vision.Vis
 	return mm.RegisterVisModel(conf.Name, &regModel, logger)
 }
 
+// TrainedClassifierConfig specifies the fields necessary for loading a classifier trained on the robot, such
+// as by the classifier_trainer vision service.
+type TrainedClassifierConfig struct {
+	ModelPath string `json:"model_path"`
+}
+
+func registerTrainedClassifier(ctx context.Context, mm modelMap, conf *vision.VisModelConfig, logger golog.Logger) error {
+	_, span := trace.StartSpan(ctx, "service::vision::registerTrainedClassifier")
+	defer span.End()
+	if conf == nil {
+		return errors.New("config for trained classifier cannot be nil")
+	}
+	trainedConf, err := resource.TransformAttributeMap[*TrainedClassifierConfig](conf.Parameters)
+	if err != nil {
+		return errors.Wrapf(err, "register trained classifier %s", conf.Name)
+	}
+	classifier, err := classification.LoadTrainedClassifier(trainedConf.ModelPath)
+	if err != nil {
+		return errors.Wrapf(err, "could not register trained classifier %s", conf.Name)
+	}
+	regModel := registeredModel{Model: classification.Classifier(classifier.Classify), ModelType: TrainedClassifier, Closer: nil}
+	return mm.RegisterVisModel(conf.Name, &regModel, logger)
+}
+
 func registerTfliteDetector(ctx context.Context, mm modelMap, conf *vision.VisModelConfig, logger golog.Logger) error {
 	ctx, span := trace.StartSpan(ctx, "service::vision::registerTfliteDetector")
 	defer span.End()
@@ -28,6 +28,7 @@ const (
 	ColorDetector     = vision.VisModelType("color_detector")
 	TFLiteClassifier  = vision.VisModelType("tflite_classifier")
 	TFClassifier      = vision.VisModelType("tf_classifier")
+	TrainedClassifier = vision.VisModelType("trained_classifier")
 	RCSegmenter       = vision.VisModelType("radius_clustering_segmenter")
 	DetectorSegmenter = vision.VisModelType("detector_segmenter")
 )
@@ -37,6 +38,7 @@ var registeredModelParameterSchemas = map[vision.VisModelType]*jsonschema.Schema
 	TFLiteDetector:    jsonschema.Reflect(&TFLiteDetectorConfig{}),
 	ColorDetector:     jsonschema.Reflect(&objectdetection.ColorDetectorConfig{}),
 	TFLiteClassifier:  jsonschema.Reflect(&TFLiteClassifierConfig{}),
+	TrainedClassifier: jsonschema.Reflect(&TrainedClassifierConfig{}),
 	RCSegmenter:       jsonschema.Reflect(&segmentation.RadiusClusteringConfig{}),
 	DetectorSegmenter: jsonschema.Reflect(&segmentation.DetectionSegmenterConfig{}),
 }
@@ -55,6 +57,7 @@ var visModelToOpMap = map[vision.VisModelType]VisOperation{
 	ColorDetector:     VisDetection,
 	TFLiteClassifier:  VisClassification,
 	TFClassifier:      VisClassification,
+	TrainedClassifier: VisClassification,
 	RCSegmenter:       VisSegmentation,
 	DetectorSegmenter: VisSegmentation,
 }
@@ -223,6 +226,8 @@ func registerNewVisModels(ctx context.Context, mm modelMap, conf *vision.Config,
 			multierr.AppendInto(&err, newVisModelTypeNotImplemented(modelConf.Type))
 		case TFClassifier:
 			multierr.AppendInto(&err, newVisModelTypeNotImplemented(modelConf.Type))
+		case TrainedClassifier:
+			multierr.AppendInto(&err, registerTrainedClassifier(ctx, mm, &modelConf, logger))
 		case ColorDetector:
 			multierr.AppendInto(&err, registerColorDetector(ctx, mm, &modelConf, logger))
 		case RCSegmenter:
@@ -3,6 +3,8 @@ package builtin
 import (
 	"context"
 	"image"
+	"image/color"
+	"path/filepath"
 	"testing"
 
 	"github.com/edaniels/golog"
@@ -77,6 +79,46 @@ func TestCloser(t *testing.T) {
 	test.That(t, got, test.ShouldNotBeNil)
 }
 
+func TestTrainedClassifierRegistration(t *testing.T) {
+	white := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	black := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			white.Set(x, y, color.White)
+			black.Set(x, y, color.Black)
+		}
+	}
+	tc, err := classification.TrainClassifier([]classification.Example{
+		classification.NewExample("white", white),
+		classification.NewExample("black", black),
+	}, 1)
+	test.That(t, err, test.ShouldBeNil)
+	modelPath := filepath.Join(t.TempDir(), "classifier.json")
+	test.That(t, tc.Save(modelPath), test.ShouldBeNil)
+
+	reg := make(modelMap)
+	conf := &vision.Config{ModelRegistry: []vision.VisModelConfig{{
+		Name:       "trained",
+		Type:       string(TrainedClassifier),
+		Parameters: utils.AttributeMap{"model_path": modelPath},
+	}}}
+	err = registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, reg.ClassifierNames(), test.ShouldResemble, []string{"trained"})
+
+	m, err := reg.modelLookup("trained")
+	test.That(t, err, test.ShouldBeNil)
+	classifier, err := m.toClassifier()
+	test.That(t, err, test.ShouldBeNil)
+	classifications, err := classifier(context.Background(), black)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, classifications[0].Label(), test.ShouldEqual, "black")
+
+	conf.ModelRegistry[0].Parameters = utils.AttributeMap{"model_path": filepath.Join(t.TempDir(), "missing.json")}
+	err = registerNewVisModels(context.Background(), reg, conf, golog.NewTestLogger(t))
+	test.That(t, err.Error(), test.ShouldContainSubstring, "could not register trained classifier trained")
+}
+
 func TestDetectorRemoval(t *testing.T) {
 	fakeDetectFn := func(context.Context, image.Image) ([]objdet.Detection, error) {
 		return []objdet.Detection{objdet.NewDetection(image.Rectangle{}, 0.0, "")}, nil
@@ -0,0 +1,581 @@
+// Package classifiertrainer implements a vision service that learns to classify the images of a camera on the
+// robot itself. Operators label example images through DoCommand or by tagging data captured by the data
+// manager, and the service trains a lightweight classifier on embedded image features within seconds, without
+// a round trip to the cloud. Frames that the classifier is unsure about are flagged for labeling, closing the
+// active learning loop.
+package classifiertrainer
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/edaniels/golog"
+	"github.com/invopop/jsonschema"
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/services/vision"
+	viz "go.viam.com/rdk/vision"
+	"go.viam.com/rdk/vision/classification"
+	objdet "go.viam.com/rdk/vision/objectdetection"
+)
+
+var model = resource.NewDefaultModel("classifier_trainer")
+
+// trainedClassifierType is the vision model type of the builtin vision service that loads the classifiers
+// saved by this service.
+const trainedClassifierType = "trained_classifier"
+
+const (
+	defaultK                   = 3
+	defaultConfidenceThreshold = 0.6
+	defaultMaxFlaggedFrames    = 100
+	defaultLabelTagPrefix      = "label:"
+)
+
+// DoCommand commands and their arguments.
+const (
+	Command              = "command"
+	CommandAddExample    = "add_example"
+	CommandTrain         = "train"
+	CommandStatus        = "status"
+	CommandListFlagged   = "list_flagged"
+	CommandLabelFlagged  = "label_flagged"
+	CommandDiscardFlag   = "discard_flagged"
+	CommandRemoveLabel   = "remove_label"
+	CommandImportCapture = "import_captures"
+	ArgLabel             = "label"
+	ArgID                = "id"
+	ArgCaptureDir        = "capture_dir"
+)
+
+func init() {
+	resource.RegisterService(vision.Subtype, model, resource.Registration[vision.Service, *Config]{
+		Constructor: func(
+			ctx context.Context,
+			deps resource.Dependencies,
+			conf resource.Config,
+			logger golog.Logger,
+		) (vision.Service, error) {
+			return New(ctx, deps, conf, logger)
+		},
+	})
+}
+
+// Config describes how to configure the service.
+type Config struct {
+	Camera string `json:"camera"`
+	// ClassifierName is the name of the trained classifier, which defaults to the name of the service.
+	ClassifierName string `json:"classifier_name,omitempty"`
+	// DataDirectory holds the example images, flagged frames and trained classifier. It defaults to a
+	// directory named after the service in ~/.viam/classifier_trainer.
+	DataDirectory string `json:"data_dir,omitempty"`
+	// K is the number of nearest examples that vote on the class of an image.
+	K int `json:"k,omitempty"`
+	// ConfidenceThreshold is the score under which a classification is considered unsure, and the frame is
+	// flagged for labeling.
+	ConfidenceThreshold float64 `json:"confidence_threshold,omitempty"`
+	// MonitorFrequencyHz is how often to classify the camera's frames in the background in order to flag
+	// unsure ones. When zero, only the frames classified through the service are checked.
+	MonitorFrequencyHz float64 `json:"monitor_frequency_hz,omitempty"`
+	MaxFlaggedFrames   int     `json:"max_flagged_frames,omitempty"`
+	// CaptureDirectory and LabelTagPrefix configure importing images captured by the data manager: images
+	// tagged with "<prefix><label>" become examples of that label.
+	CaptureDirectory string `json:"capture_dir,omitempty"`
+	LabelTagPrefix   string `json:"label_tag_prefix,omitempty"`
+	// VisionService, if set, is a builtin vision service that the trained classifier is registered with
+	// every time it is trained.
+	VisionService string `json:"vision_service,omitempty"`
+}
+
+// Validate ensures all parts of the config are valid and returns the implicit dependencies.
+func (conf *Config) Validate(path string) ([]string, error) {
+	if conf.Camera == "" {
+		return nil, goutils.NewConfigValidationFieldRequiredError(path, "camera")
+	}
+	if conf.K < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("k cannot be negative"))
+	}
+	if conf.ConfidenceThreshold < 0 || conf.ConfidenceThreshold > 1 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("confidence_threshold must be between 0 and 1"))
+	}
+	if conf.MonitorFrequencyHz < 0 {
+		return nil, goutils.NewConfigValidationError(path, errors.New("monitor_frequency_hz cannot be negative"))
+	}
+	deps := []string{conf.Camera}
+	if conf.VisionService != "" {
+		deps = append(deps, vision.Named(conf.VisionService).String())
+	}
+	return deps, nil
+}
+
+type trainer struct {
+	resource.Named
+	resource.AlwaysRebuild
+	logger golog.Logger
+
+	cam            camera.Camera
+	cameraName     string
+	classifierName string
+	visionSvc      vision.Service
+	k              int
+	threshold      float64
+	maxFlagged     int
+	captureDir     string
+	tagPrefix      string
+
+	mu         sync.Mutex
+	data       *dataset
+	classifier *classification.TrainedClassifier
+
+	cancel                  func()
+	activeBackgroundWorkers sync.WaitGroup
+}
+
+// New returns a classifier trainer, loading the examples saved in its data directory and training on them.
+func New(ctx context.Context, deps resource.Dependencies, conf resource.Config, logger golog.Logger) (vision.Service, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::classifier_trainer::New")
+	defer span.End()
+
+	svcConf, err := resource.NativeConfig[*Config](conf)
+	if err != nil {
+		return nil, err
+	}
+	cam, err := camera.FromDependencies(deps, svcConf.Camera)
+	if err != nil {
+		return nil, err
+	}
+	t := &trainer{
+		Named:          conf.ResourceName().AsNamed(),
+		logger:         logger,
+		cam:            cam,
+		cameraName:     svcConf.Camera,
+		classifierName: svcConf.ClassifierName,
+		k:              svcConf.K,
+		threshold:      svcConf.ConfidenceThreshold,
+		maxFlagged:     svcConf.MaxFlaggedFrames,
+		captureDir:     svcConf.CaptureDirectory,
+		tagPrefix:      svcConf.LabelTagPrefix,
+	}
+	if t.classifierName == "" {
+		t.classifierName = conf.Name
+	}
+	if t.k == 0 {
+		t.k = defaultK
+	}
+	if t.threshold == 0 {
+		t.threshold = defaultConfidenceThreshold
+	}
+	if t.maxFlagged == 0 {
+		t.maxFlagged = defaultMaxFlaggedFrames
+	}
+	if t.captureDir == "" {
+		t.captureDir = filepath.Join(os.Getenv("HOME"), ".viam", "capture")
+	}
+	if t.tagPrefix == "" {
+		t.tagPrefix = defaultLabelTagPrefix
+	}
+	if svcConf.VisionService != "" {
+		if t.visionSvc, err = vision.FromDependencies(deps, svcConf.VisionService); err != nil {
+			return nil, err
+		}
+	}
+
+	dataDir := svcConf.DataDirectory
+	if dataDir == "" {
+		dataDir = filepath.Join(os.Getenv("HOME"), ".viam", "classifier_trainer", conf.Name)
+	}
+	if t.data, err = loadDataset(ctx, dataDir); err != nil {
+		return nil, errors.Wrapf(err, "error loading examples from %s", dataDir)
+	}
+	if len(t.data.examples) > 0 {
+		if err := t.train(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if svcConf.MonitorFrequencyHz > 0 {
+		cancelCtx, cancel := context.WithCancel(context.Background())
+		t.cancel = cancel
+		t.activeBackgroundWorkers.Add(1)
+		goutils.PanicCapturingGo(func() {
+			defer t.activeBackgroundWorkers.Done()
+			t.monitor(cancelCtx, time.Duration(float64(time.Second)/svcConf.MonitorFrequencyHz))
+		})
+	}
+	return t, nil
+}
+
+// train retrains the classifier on all examples, saves it and registers it with the configured vision
+// service. It must be called with the mutex held.
+func (t *trainer) train(ctx context.Context) error {
+	examples := t.data.trainingExamples()
+	if len(examples) == 0 {
+		t.classifier = nil
+		if t.visionSvc != nil {
+			return t.visionSvc.RemoveClassifier(ctx, t.classifierName, nil)
+		}
+		return nil
+	}
+	classifier, err := classification.TrainClassifier(examples, t.k)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(t.data.dir, classifierFile)
+	if err := classifier.Save(path); err != nil {
+		return errors.Wrap(err, "error saving trained classifier")
+	}
+	t.classifier = classifier
+	t.logger.Infow("trained classifier", "name", t.classifierName, "labels", classifier.Labels, "examples", len(examples))
+
+	if t.visionSvc == nil {
+		return nil
+	}
+	// The builtin vision service overwrites classifiers of the same name, but releases them on removal.
+	if err := t.visionSvc.RemoveClassifier(ctx, t.classifierName, nil); err != nil {
+		t.logger.Debugw("error removing previous classifier", "error", err)
+	}
+	return t.visionSvc.AddClassifier(ctx, vision.VisModelConfig{
+		Name:       t.classifierName,
+		Type:       trainedClassifierType,
+		Parameters: map[string]interface{}{"model_path": path},
+	}, nil)
+}
+
+// monitor classifies the camera's frames periodically to flag unsure ones.
+func (t *trainer) monitor(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		img, release, err := camera.ReadImage(ctx, t.cam)
+		if err != nil {
+			if ctx.Err() == nil {
+				t.logger.Debugw("error reading image to monitor", "error", err)
+			}
+			continue
+		}
+		if _, err := t.classify(ctx, img); err != nil {
+			t.logger.Debugw("error classifying image to monitor", "error", err)
+		}
+		release()
+	}
+}
+
+// classify classifies an image and flags it for labeling if the classifier is unsure.
+func (t *trainer) classify(ctx context.Context, img image.Image) (classification.Classifications, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.classifier == nil {
+		return nil, errors.Errorf("classifier %q has not been trained yet, add examples first", t.classifierName)
+	}
+	// the embedding both classifies the image and is saved with it if it is flagged
+	features := classification.EmbedImage(img)
+	classifications, err := t.classifier.ClassifyFeatures(features)
+	if err != nil {
+		return nil, err
+	}
+	top := classifications[0]
+	if top.Score() < t.threshold {
+		frame, err := t.data.flag(ctx, img, features, top, t.maxFlagged)
+		if err != nil {
+			t.logger.Errorw("error flagging frame", "error", err)
+		} else if frame != nil {
+			t.logger.Debugw("flagged unsure frame for labeling", "id", frame.ID, "label", frame.Label,
+				"confidence", frame.Confidence)
+		}
+	}
+	return classifications, nil
+}
+
+func (t *trainer) checkClassifierName(classifierName string) error {
+	if classifierName != t.classifierName {
+		return errors.Errorf("no such classifier %q, only %q is available", classifierName, t.classifierName)
+	}
+	return nil
+}
+
+func (t *trainer) ClassifierNames(ctx context.Context, extra map[string]interface{}) ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.classifier == nil {
+		return []string{}, nil
+	}
+	return []string{t.classifierName}, nil
+}
+
+func (t *trainer) ClassificationsFromCamera(
+	ctx context.Context,
+	cameraName, classifierName string,
+	n int,
+	extra map[string]interface{},
+) (classification.Classifications, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::classifier_trainer::ClassificationsFromCamera")
+	defer span.End()
+	if cameraName != t.cameraName {
+		return nil, errors.Errorf("classifier trainer only uses camera %q, not %q", t.cameraName, cameraName)
+	}
+	if err := t.checkClassifierName(classifierName); err != nil {
+		return nil, err
+	}
+	img, release, err := camera.ReadImage(ctx, t.cam)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get image from %s", cameraName)
+	}
+	defer release()
+	return t.topN(ctx, img, n)
+}
+
+func (t *trainer) Classifications(
+	ctx context.Context,
+	img image.Image,
+	classifierName string,
+	n int,
+	extra map[string]interface{},
+) (classification.Classifications, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::classifier_trainer::Classifications")
+	defer span.End()
+	if err := t.checkClassifierName(classifierName); err != nil {
+		return nil, err
+	}
+	return t.topN(ctx, img, n)
+}
+
+func (t *trainer) topN(ctx context.Context, img image.Image, n int) (classification.Classifications, error) {
+	classifications, err := t.classify(ctx, img)
+	if err != nil {
+		return nil, err
+	}
+	if n > len(classifications) {
+		n = len(classifications)
+	}
+	return classifications.TopN(n)
+}
+
+// DoCommand manages the examples and flagged frames, and retrains the classifier whenever the examples change.
+func (t *trainer) DoCommand(ctx context.Context, cmd map[string]interface{}) (map[string]interface{}, error) {
+	ctx, span := trace.StartSpan(ctx, "service::vision::classifier_trainer::DoCommand")
+	defer span.End()
+
+	name, ok := cmd[Command]
+	if !ok {
+		return nil, errors.Errorf("missing %s value", Command)
+	}
+	stringArg := func(key string) (string, error) {
+		v, ok := cmd[key].(string)
+		if !ok || v == "" {
+			return "", errors.Errorf("%s needs a %s string", name, key)
+		}
+		return v, nil
+	}
+
+	switch name {
+	case CommandAddExample:
+		label, err := stringArg(ArgLabel)
+		if err != nil {
+			return nil, err
+		}
+		img, release, err := camera.ReadImage(ctx, t.cam)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get image from %s", t.cameraName)
+		}
+		defer release()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, err := t.data.addExample(ctx, label, "", img); err != nil {
+			return nil, err
+		}
+		return t.retrain(ctx)
+	case CommandTrain:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.retrain(ctx)
+	case CommandStatus:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.status(), nil
+	case CommandListFlagged:
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		frames := make([]interface{}, 0, len(t.data.flagged))
+		for _, f := range t.data.flagged {
+			frames = append(frames, map[string]interface{}{
+				"id":         f.ID,
+				"path":       f.Path,
+				"label":      f.Label,
+				"confidence": f.Confidence,
+				"time":       f.Time.Format(time.RFC3339Nano),
+			})
+		}
+		return map[string]interface{}{"flagged": frames}, nil
+	case CommandLabelFlagged:
+		id, err := stringArg(ArgID)
+		if err != nil {
+			return nil, err
+		}
+		label, err := stringArg(ArgLabel)
+		if err != nil {
+			return nil, err
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if err := t.data.labelFlagged(id, label); err != nil {
+			return nil, err
+		}
+		return t.retrain(ctx)
+	case CommandDiscardFlag:
+		id, err := stringArg(ArgID)
+		if err != nil {
+			return nil, err
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if err := t.data.discardFlagged(id); err != nil {
+			return nil, err
+		}
+		return t.status(), nil
+	case CommandRemoveLabel:
+		label, err := stringArg(ArgLabel)
+		if err != nil {
+			return nil, err
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, err := t.data.removeLabel(label); err != nil {
+			return nil, err
+		}
+		return t.retrain(ctx)
+	case CommandImportCapture:
+		captureDir := t.captureDir
+		if dir, ok := cmd[ArgCaptureDir].(string); ok && dir != "" {
+			captureDir = dir
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		imported, importErr := t.data.importCaptures(ctx, captureDir, t.tagPrefix)
+		if imported > 0 {
+			if err := t.train(ctx); err != nil {
+				return nil, err
+			}
+		}
+		status := t.status()
+		status["imported"] = imported
+		return status, importErr
+	default:
+		return nil, errors.Errorf("no such command: %s", name)
+	}
+}
+
+// retrain trains the classifier and returns the resulting status. It must be called with the mutex held.
+func (t *trainer) retrain(ctx context.Context) (map[string]interface{}, error) {
+	if err := t.train(ctx); err != nil {
+		return nil, err
+	}
+	return t.status(), nil
+}
+
+// status describes the examples, flagged frames and classifier. It must be called with the mutex held.
+func (t *trainer) status() map[string]interface{} {
+	labels := []interface{}{}
+	if t.classifier != nil {
+		sorted := append([]string{}, t.classifier.Labels...)
+		sort.Strings(sorted)
+		for _, l := range sorted {
+			labels = append(labels, l)
+		}
+	}
+	return map[string]interface{}{
+		"classifier": t.classifierName,
+		"trained":    t.classifier != nil,
+		"labels":     labels,
+		"examples":   t.data.counts(),
+		"flagged":    len(t.data.flagged),
+	}
+}
+
+func (t *trainer) Close(ctx context.Context) error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.activeBackgroundWorkers.Wait()
+	return nil
+}
+
+var errUnsupported = errors.New("the classifier trainer only supports classification")
+
+func (t *trainer) GetModelParameterSchema(
+	ctx context.Context,
+	modelType vision.VisModelType,
+	extra map[string]interface{},
+) (*jsonschema.Schema, error) {
+	return nil, errUnsupported
+}
+
+func (t *trainer) DetectorNames(ctx context.Context, extra map[string]interface{}) ([]string, error) {
+	return []string{}, nil
+}
+
+func (t *trainer) AddDetector(ctx context.Context, cfg vision.VisModelConfig, extra map[string]interface{}) error {
+	return errUnsupported
+}
+
+func (t *trainer) RemoveDetector(ctx context.Context, detectorName string, extra map[string]interface{}) error {
+	return errUnsupported
+}
+
+func (t *trainer) DetectionsFromCamera(
+	ctx context.Context,
+	cameraName, detectorName string,
+	extra map[string]interface{},
+) ([]objdet.Detection, error) {
+	return nil, errUnsupported
+}
+
+func (t *trainer) Detections(
+	ctx context.Context,
+	img image.Image,
+	detectorName string,
+	extra map[string]interface{},
+) ([]objdet.Detection, error) {
+	return nil, errUnsupported
+}
+
+func (t *trainer) AddClassifier(ctx context.Context, cfg vision.VisModelConfig, extra map[string]interface{}) error {
+	return errors.New("the classifier trainer trains its own classifier, add examples to it instead")
+}
+
+func (t *trainer) RemoveClassifier(ctx context.Context, classifierName string, extra map[string]interface{}) error {
+	return errors.New("the classifier trainer trains its own classifier, remove labels from it instead")
+}
+
+func (t *trainer) SegmenterNames(ctx context.Context, extra map[string]interface{}) ([]string, error) {
+	return []string{}, nil
+}
+
+func (t *trainer) AddSegmenter(ctx context.Context, cfg vision.VisModelConfig, extra map[string]interface{}) error {
+	return errUnsupported
+}
+
+func (t *trainer) RemoveSegmenter(ctx context.Context, segmenterName string, extra map[string]interface{}) error {
+	return errUnsupported
+}
+
+func (t *trainer) GetObjectPointClouds(
+	ctx context.Context,
+	cameraName, segmenterName string,
+	extra map[string]interface{},
+) ([]*viz.Object, error) {
+	return nil, errUnsupported
+}
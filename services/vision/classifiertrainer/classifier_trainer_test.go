@@ -0,0 +1,255 @@
+package classifiertrainer
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/edaniels/gostream"
+	v1 "go.viam.com/api/app/datasync/v1"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/resource"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/services/vision"
+	"go.viam.com/rdk/testutils/inject"
+	"go.viam.com/rdk/utils"
+)
+
+// partImage draws a part of the given color on a white background, offset by the given amount.
+func partImage(c color.Color, offset int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			dx, dy := x-32-offset, y-24
+			if dx*dx+dy*dy < 14*14 {
+				img.Set(x, y, c)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	return img
+}
+
+var (
+	red  = color.RGBA{200, 20, 20, 255}
+	blue = color.RGBA{20, 40, 200, 255}
+)
+
+// fakeCamera is a camera whose next image can be changed.
+type fakeCamera struct {
+	*inject.Camera
+	mu  sync.Mutex
+	img image.Image
+}
+
+func newFakeCamera() *fakeCamera {
+	cam := &fakeCamera{Camera: inject.NewCamera("cam")}
+	cam.StreamFunc = func(ctx context.Context, errHandlers ...gostream.ErrorHandler) (gostream.VideoStream, error) {
+		return gostream.NewEmbeddedVideoStreamFromReader(gostream.VideoReaderFunc(func(ctx context.Context) (image.Image, func(), error) {
+			cam.mu.Lock()
+			defer cam.mu.Unlock()
+			return cam.img, func() {}, nil
+		})), nil
+	}
+	return cam
+}
+
+func (c *fakeCamera) show(img image.Image) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.img = img
+}
+
+func newTrainer(t *testing.T, cam camera.Camera, attrs *Config, deps resource.Dependencies) *trainer {
+	t.Helper()
+	if deps == nil {
+		deps = resource.Dependencies{}
+	}
+	deps[camera.Named("cam")] = cam
+	conf := resource.Config{Name: "trainer", API: vision.Subtype, Model: model, ConvertedAttributes: attrs}
+	svc, err := New(context.Background(), deps, conf, golog.NewTestLogger(t))
+	test.That(t, err, test.ShouldBeNil)
+	return svc.(*trainer)
+}
+
+func TestConfigValidate(t *testing.T) {
+	conf := &Config{}
+	_, err := conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "camera")
+
+	conf = &Config{Camera: "cam", ConfidenceThreshold: 2}
+	_, err = conf.Validate("path")
+	test.That(t, err.Error(), test.ShouldContainSubstring, "confidence_threshold")
+
+	conf = &Config{Camera: "cam", VisionService: "vis"}
+	deps, err := conf.Validate("path")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, deps, test.ShouldResemble, []string{"cam", vision.Named("vis").String()})
+}
+
+func TestTrainAndActiveLearning(t *testing.T) {
+	ctx := context.Background()
+	cam := newFakeCamera()
+	dataDir := t.TempDir()
+
+	var added []vision.VisModelConfig
+	vis := inject.NewVisionService("vis")
+	vis.AddClassifierFunc = func(ctx context.Context, cfg vision.VisModelConfig, extra map[string]interface{}) error {
+		added = append(added, cfg)
+		return nil
+	}
+	vis.RemoveClassifierFunc = func(ctx context.Context, classifierName string, extra map[string]interface{}) error {
+		return nil
+	}
+	deps := resource.Dependencies{vision.Named("vis"): vis}
+	svc := newTrainer(t, cam, &Config{Camera: "cam", DataDirectory: dataDir, K: 1, VisionService: "vis"}, deps)
+
+	names, err := svc.ClassifierNames(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, names, test.ShouldBeEmpty)
+	_, err = svc.Classifications(ctx, partImage(red, 0), "trainer", 1, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "has not been trained yet")
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandAddExample})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "needs a label")
+	_, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandAddExample, ArgLabel: "../x"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "invalid label")
+
+	for _, offset := range []int{-4, 0, 4} {
+		cam.show(partImage(red, offset))
+		_, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandAddExample, ArgLabel: "red"})
+		test.That(t, err, test.ShouldBeNil)
+		cam.show(partImage(blue, offset))
+		_, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandAddExample, ArgLabel: "blue"})
+		test.That(t, err, test.ShouldBeNil)
+	}
+	status, err := svc.DoCommand(ctx, map[string]interface{}{Command: CommandStatus})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["trained"], test.ShouldBeTrue)
+	test.That(t, status["labels"], test.ShouldResemble, []interface{}{"blue", "red"})
+	test.That(t, status["examples"], test.ShouldResemble, map[string]interface{}{"red": 3, "blue": 3})
+	test.That(t, added[len(added)-1], test.ShouldResemble, vision.VisModelConfig{
+		Name:       "trainer",
+		Type:       trainedClassifierType,
+		Parameters: map[string]interface{}{"model_path": filepath.Join(dataDir, classifierFile)},
+	})
+
+	names, err = svc.ClassifierNames(ctx, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, names, test.ShouldResemble, []string{"trainer"})
+	cam.show(partImage(blue, 2))
+	classifications, err := svc.ClassificationsFromCamera(ctx, "cam", "trainer", 1, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, classifications, test.ShouldHaveLength, 1)
+	test.That(t, classifications[0].Label(), test.ShouldEqual, "blue")
+	_, err = svc.ClassificationsFromCamera(ctx, "other", "trainer", 1, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = svc.Classifications(ctx, partImage(blue, 0), "other", 1, nil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no such classifier")
+
+	// With a single neighbor voting, the classifier is always sure, so any threshold flags every frame.
+	svc.threshold = 1.01
+	green := color.RGBA{20, 200, 20, 255}
+	classifications, err = svc.Classifications(ctx, partImage(green, 0), "trainer", 1, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, classifications, test.ShouldHaveLength, 1)
+	// the same frame again is not flagged twice
+	_, err = svc.Classifications(ctx, partImage(green, 0), "trainer", 1, nil)
+	test.That(t, err, test.ShouldBeNil)
+
+	resp, err := svc.DoCommand(ctx, map[string]interface{}{Command: CommandListFlagged})
+	test.That(t, err, test.ShouldBeNil)
+	flagged := resp["flagged"].([]interface{})
+	test.That(t, flagged, test.ShouldHaveLength, 1)
+	id := flagged[0].(map[string]interface{})["id"].(string)
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandLabelFlagged, ArgID: "missing", ArgLabel: "green"})
+	test.That(t, err.Error(), test.ShouldContainSubstring, "no flagged frame")
+	status, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandLabelFlagged, ArgID: id, ArgLabel: "green"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["flagged"], test.ShouldEqual, 0)
+	test.That(t, status["labels"], test.ShouldResemble, []interface{}{"blue", "green", "red"})
+
+	svc.threshold = defaultConfidenceThreshold
+	classifications, err = svc.Classifications(ctx, partImage(green, 3), "trainer", 3, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, classifications[0].Label(), test.ShouldEqual, "green")
+
+	// a flagged frame can also be discarded
+	svc.threshold = 1.01
+	_, err = svc.Classifications(ctx, partImage(color.Black, 0), "trainer", 1, nil)
+	test.That(t, err, test.ShouldBeNil)
+	resp, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandListFlagged})
+	test.That(t, err, test.ShouldBeNil)
+	flagged = resp["flagged"].([]interface{})
+	test.That(t, flagged, test.ShouldHaveLength, 1)
+	id = flagged[0].(map[string]interface{})["id"].(string)
+	status, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandDiscardFlag, ArgID: id})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["flagged"], test.ShouldEqual, 0)
+
+	status, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandRemoveLabel, ArgLabel: "red"})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["labels"], test.ShouldResemble, []interface{}{"blue", "green"})
+
+	_, err = svc.DoCommand(ctx, map[string]interface{}{Command: "nope"})
+	test.That(t, err, test.ShouldBeError, "no such command: nope")
+	test.That(t, svc.Close(ctx), test.ShouldBeNil)
+
+	// the examples and the classifier survive a restart
+	svc = newTrainer(t, cam, &Config{Camera: "cam", DataDirectory: dataDir, K: 1}, nil)
+	defer svc.Close(ctx)
+	status, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandStatus})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["examples"], test.ShouldResemble, map[string]interface{}{"blue": 3, "green": 1})
+	classifications, err = svc.Classifications(ctx, partImage(green, 3), "trainer", 1, nil)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, classifications[0].Label(), test.ShouldEqual, "green")
+}
+
+func writeCapture(t *testing.T, dir string, tags []string, imgs ...image.Image) {
+	t.Helper()
+	md, err := datacapture.BuildCaptureMetadata(camera.Subtype, "cam", "ReadImage",
+		map[string]string{"mime_type": utils.MimeTypeJPEG}, tags)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, os.MkdirAll(dir, 0o700), test.ShouldBeNil)
+	f, err := datacapture.NewFile(dir, md)
+	test.That(t, err, test.ShouldBeNil)
+	for _, img := range imgs {
+		data, err := rimage.EncodeImage(context.Background(), img, utils.MimeTypeJPEG)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, f.WriteNext(&v1.SensorData{Data: &v1.SensorData_Binary{Binary: data}}), test.ShouldBeNil)
+	}
+	test.That(t, f.Close(), test.ShouldBeNil)
+}
+
+func TestImportCaptures(t *testing.T) {
+	ctx := context.Background()
+	captureDir := t.TempDir()
+	writeCapture(t, filepath.Join(captureDir, "red"), []string{"site:a", "label:red"}, partImage(red, 0), partImage(red, 4))
+	writeCapture(t, filepath.Join(captureDir, "blue"), []string{"label:blue"}, partImage(blue, 0))
+	writeCapture(t, filepath.Join(captureDir, "untagged"), nil, partImage(blue, 4))
+
+	svc := newTrainer(t, newFakeCamera(), &Config{Camera: "cam", DataDirectory: t.TempDir(), CaptureDirectory: captureDir}, nil)
+	defer svc.Close(ctx)
+	status, err := svc.DoCommand(ctx, map[string]interface{}{Command: CommandImportCapture})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["imported"], test.ShouldEqual, 3)
+	test.That(t, status["examples"], test.ShouldResemble, map[string]interface{}{"red": 2, "blue": 1})
+	test.That(t, status["trained"], test.ShouldBeTrue)
+
+	// importing again does not duplicate examples
+	status, err = svc.DoCommand(ctx, map[string]interface{}{Command: CommandImportCapture, ArgCaptureDir: captureDir})
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, status["imported"], test.ShouldEqual, 0)
+	test.That(t, status["examples"], test.ShouldResemble, map[string]interface{}{"red": 2, "blue": 1})
+}
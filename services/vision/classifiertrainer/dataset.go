@@ -0,0 +1,379 @@
+package classifiertrainer
+
+import (
+	"context"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+	goutils "go.viam.com/utils"
+
+	"go.viam.com/rdk/components/camera"
+	"go.viam.com/rdk/rimage"
+	"go.viam.com/rdk/services/datamanager/datacapture"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/classification"
+)
+
+const (
+	examplesDirectory = "examples"
+	flaggedDirectory  = "flagged"
+	classifierFile    = "classifier.json"
+	imageExt          = ".jpg"
+	// duplicateDistance is the embedding distance under which a low confidence frame is considered the same
+	// as one already flagged, so that a robot staring at the same scene does not flag it over and over.
+	duplicateDistance = 0.1
+)
+
+// example is a labeled image saved in the data directory.
+type example struct {
+	path     string
+	features []float64
+}
+
+// flaggedFrame is an image that was classified with low confidence, saved for an operator to label.
+type flaggedFrame struct {
+	ID         string
+	Path       string
+	Label      string
+	Confidence float64
+	Time       time.Time
+	features   []float64
+}
+
+// dataset holds the labeled examples and the flagged frames of the trainer, which are stored as images in
+// its data directory so that they survive restarts and can be reviewed or copied elsewhere.
+type dataset struct {
+	dir      string
+	examples map[string][]example
+	flagged  []*flaggedFrame
+}
+
+// validateLabel checks that a label can be used as the name of a directory.
+func validateLabel(label string) error {
+	if label == "" || label == "." || label == ".." || strings.ContainsAny(label, `/\`) {
+		return errors.Errorf("invalid label %q", label)
+	}
+	return nil
+}
+
+// loadDataset loads the examples and flagged frames found in a data directory, creating it if needed.
+func loadDataset(ctx context.Context, dir string) (*dataset, error) {
+	d := &dataset{dir: dir, examples: map[string][]example{}}
+	for _, sub := range []string{examplesDirectory, flaggedDirectory} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return nil, err
+		}
+	}
+
+	labelDirs, err := os.ReadDir(filepath.Join(dir, examplesDirectory))
+	if err != nil {
+		return nil, err
+	}
+	for _, labelDir := range labelDirs {
+		if !labelDir.IsDir() {
+			continue
+		}
+		label := labelDir.Name()
+		paths, err := filepath.Glob(filepath.Join(dir, examplesDirectory, label, "*"+imageExt))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			img, err := readImageFile(ctx, path)
+			if err != nil {
+				return nil, err
+			}
+			d.examples[label] = append(d.examples[label], example{path, classification.EmbedImage(img)})
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, flaggedDirectory, "*"+imageExt))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		img, err := readImageFile(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		d.flagged = append(d.flagged, &flaggedFrame{
+			ID:       strings.TrimSuffix(filepath.Base(path), imageExt),
+			Path:     path,
+			Time:     info.ModTime(),
+			features: classification.EmbedImage(img),
+		})
+	}
+	return d, nil
+}
+
+func readImageFile(ctx context.Context, path string) (image.Image, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := rimage.DecodeImage(ctx, data, utils.MimeTypeJPEG)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decoding %s", path)
+	}
+	return img, nil
+}
+
+func writeImageFile(ctx context.Context, path string, img image.Image) error {
+	data, err := rimage.EncodeImage(ctx, img, utils.MimeTypeJPEG)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// fileName returns a file name, unique within the dataset, for an image taken at the given time.
+func fileName(t time.Time) string {
+	return strings.ReplaceAll(t.UTC().Format("20060102T150405.000000000"), ".", "_") + imageExt
+}
+
+// addExample saves a labeled image as an example. If name is empty, a name is derived from the current time;
+// otherwise an example of that name that already exists is left as it is.
+func (d *dataset) addExample(ctx context.Context, label, name string, img image.Image) (bool, error) {
+	if err := validateLabel(label); err != nil {
+		return false, err
+	}
+	if name == "" {
+		name = fileName(time.Now())
+	}
+	labelDir := filepath.Join(d.dir, examplesDirectory, label)
+	if err := os.MkdirAll(labelDir, 0o700); err != nil {
+		return false, err
+	}
+	path := filepath.Join(labelDir, name)
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+	if err := writeImageFile(ctx, path, img); err != nil {
+		return false, err
+	}
+	// Embed the image as saved, so that the example is the same after a restart.
+	saved, err := readImageFile(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	d.examples[label] = append(d.examples[label], example{path, classification.EmbedImage(saved)})
+	return true, nil
+}
+
+// removeLabel deletes all examples of a label.
+func (d *dataset) removeLabel(label string) (int, error) {
+	if err := validateLabel(label); err != nil {
+		return 0, err
+	}
+	n := len(d.examples[label])
+	if n == 0 {
+		return 0, errors.Errorf("no examples labeled %q", label)
+	}
+	delete(d.examples, label)
+	return n, os.RemoveAll(filepath.Join(d.dir, examplesDirectory, label))
+}
+
+// trainingExamples returns all examples, in a deterministic order.
+func (d *dataset) trainingExamples() []classification.Example {
+	labels := make([]string, 0, len(d.examples))
+	for label := range d.examples {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	var out []classification.Example
+	for _, label := range labels {
+		for _, e := range d.examples[label] {
+			out = append(out, classification.Example{Label: label, Features: e.features})
+		}
+	}
+	return out
+}
+
+// counts returns the number of examples of every label.
+func (d *dataset) counts() map[string]interface{} {
+	out := make(map[string]interface{}, len(d.examples))
+	for label, examples := range d.examples {
+		out[label] = len(examples)
+	}
+	return out
+}
+
+// flag saves a low confidence frame for labeling, unless it looks like a frame already flagged or too many
+// frames are flagged already. It returns the flagged frame, or nil.
+func (d *dataset) flag(
+	ctx context.Context,
+	img image.Image,
+	features []float64,
+	top classification.Classification,
+	maxFlagged int,
+) (*flaggedFrame, error) {
+	if len(d.flagged) >= maxFlagged {
+		return nil, nil
+	}
+	for _, f := range d.flagged {
+		if distance(f.features, features) < duplicateDistance {
+			return nil, nil
+		}
+	}
+	now := time.Now()
+	name := fileName(now)
+	frame := &flaggedFrame{
+		ID:       strings.TrimSuffix(name, imageExt),
+		Path:     filepath.Join(d.dir, flaggedDirectory, name),
+		Time:     now,
+		features: features,
+	}
+	if top != nil {
+		frame.Label = top.Label()
+		frame.Confidence = top.Score()
+	}
+	if err := writeImageFile(ctx, frame.Path, img); err != nil {
+		return nil, err
+	}
+	d.flagged = append(d.flagged, frame)
+	return frame, nil
+}
+
+func (d *dataset) findFlagged(id string) (int, error) {
+	for i, f := range d.flagged {
+		if f.ID == id {
+			return i, nil
+		}
+	}
+	return 0, errors.Errorf("no flagged frame with id %q", id)
+}
+
+// labelFlagged turns a flagged frame into an example with the given label.
+func (d *dataset) labelFlagged(id, label string) error {
+	if err := validateLabel(label); err != nil {
+		return err
+	}
+	i, err := d.findFlagged(id)
+	if err != nil {
+		return err
+	}
+	f := d.flagged[i]
+	labelDir := filepath.Join(d.dir, examplesDirectory, label)
+	if err := os.MkdirAll(labelDir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(labelDir, filepath.Base(f.Path))
+	if err := os.Rename(f.Path, path); err != nil {
+		return err
+	}
+	d.examples[label] = append(d.examples[label], example{path, f.features})
+	d.flagged = append(d.flagged[:i], d.flagged[i+1:]...)
+	return nil
+}
+
+// discardFlagged deletes a flagged frame.
+func (d *dataset) discardFlagged(id string) error {
+	i, err := d.findFlagged(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(d.flagged[i].Path); err != nil {
+		return err
+	}
+	d.flagged = append(d.flagged[:i], d.flagged[i+1:]...)
+	return nil
+}
+
+// importCaptures adds the images of data capture files under captureDir that have a tag starting with
+// tagPrefix as examples, labeled with the rest of the tag. Files that were imported before are skipped.
+func (d *dataset) importCaptures(ctx context.Context, captureDir, tagPrefix string) (int, error) {
+	imported := 0
+	var errs error
+	err := filepath.WalkDir(captureDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != datacapture.FileExt {
+			return err
+		}
+		n, err := d.importCaptureFile(ctx, path, tagPrefix)
+		imported += n
+		multierr.AppendInto(&errs, errors.Wrapf(err, "error importing %s", path))
+		return nil
+	})
+	return imported, multierr.Combine(err, errs)
+}
+
+func (d *dataset) importCaptureFile(ctx context.Context, path, tagPrefix string) (int, error) {
+	//nolint:gosec
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	captureFile, err := datacapture.ReadFile(f)
+	if err != nil {
+		return 0, multierr.Combine(err, f.Close())
+	}
+	defer func() {
+		goutils.UncheckedError(captureFile.Close())
+	}()
+
+	md := captureFile.ReadMetadata()
+	if md.GetComponentType() != camera.Subtype.String() {
+		return 0, nil
+	}
+	label := ""
+	for _, tag := range md.GetTags() {
+		if strings.HasPrefix(tag, tagPrefix) {
+			label = strings.TrimPrefix(tag, tagPrefix)
+			break
+		}
+	}
+	if label == "" {
+		return 0, nil
+	}
+	mimeType := utils.MimeTypeJPEG
+	if md.GetFileExtension() == ".png" {
+		mimeType = utils.MimeTypePNG
+	}
+
+	readings, err := datacapture.SensorDataFromFile(captureFile)
+	if err != nil {
+		return 0, err
+	}
+	imported := 0
+	base := strings.TrimSuffix(filepath.Base(path), datacapture.FileExt)
+	for i, reading := range readings {
+		if reading.GetBinary() == nil {
+			continue
+		}
+		img, err := rimage.DecodeImage(ctx, reading.GetBinary(), mimeType)
+		if err != nil {
+			return imported, err
+		}
+		name := "capture_" + strings.NewReplacer(":", "_", ".", "_").Replace(base) + "_" + strconv.Itoa(i) + imageExt
+		added, err := d.addExample(ctx, label, name, img)
+		if err != nil {
+			return imported, err
+		}
+		if added {
+			imported++
+		}
+	}
+	return imported, nil
+}
+
+func distance(a, b []float64) float64 {
+	sum := 0.
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
@@ -4,4 +4,5 @@ package register
 import (
 	// for vision models.
 	_ "go.viam.com/rdk/services/vision/builtin"
+	_ "go.viam.com/rdk/services/vision/classifiertrainer"
 )
@@ -84,6 +84,12 @@ func FromRobot(r robot.Robot, name string) (Service, error) {
 	return robot.ResourceFromRobot[Service](r, Named(name))
 }
 
+// FromDependencies is a helper for getting the named vision service from a collection of
+// dependencies.
+func FromDependencies(deps resource.Dependencies, name string) (Service, error) {
+	return resource.FromDependencies[Service](deps, Named(name))
+}
+
 // FindFirstName returns name of first vision service found.
 func FindFirstName(r robot.Robot) string {
 	for _, val := range robot.NamesBySubtype(r, Subtype) {
@@ -3,6 +3,7 @@ package spatialmath
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/golang/geo/r3"
 	commonpb "go.viam.com/api/common/v1"
@@ -27,6 +28,40 @@ type Geometry interface {
 	json.Marshaler
 }
 
+// BoundingBox returns the minimum and maximum corners of the smallest box aligned with the world axes that
+// contains the geometry. Geometries whose bounding boxes do not overlap cannot be in collision, which makes them a
+// cheap first pass before checking collisions exactly.
+func BoundingBox(g Geometry) (r3.Vector, r3.Vector, error) {
+	switch geom := g.(type) {
+	case *box:
+		rm := geom.rotationMatrix()
+		center := geom.pose.Point()
+		// the rows of the rotation matrix are the axes of the box, and the extent of the box along a world axis is
+		// the sum of the projections of its half sizes on that axis
+		extent := func(axis int) float64 {
+			return math.Abs(rm.At(0, axis))*geom.halfSize[0] +
+				math.Abs(rm.At(1, axis))*geom.halfSize[1] +
+				math.Abs(rm.At(2, axis))*geom.halfSize[2]
+		}
+		halfExtents := r3.Vector{X: extent(0), Y: extent(1), Z: extent(2)}
+		return center.Sub(halfExtents), center.Add(halfExtents), nil
+	case *sphere:
+		r := r3.Vector{X: geom.radius, Y: geom.radius, Z: geom.radius}
+		center := geom.pose.Point()
+		return center.Sub(r), center.Add(r), nil
+	case *capsule:
+		r := r3.Vector{X: geom.radius, Y: geom.radius, Z: geom.radius}
+		a, b := geom.segA, geom.segB
+		lo := r3.Vector{X: math.Min(a.X, b.X), Y: math.Min(a.Y, b.Y), Z: math.Min(a.Z, b.Z)}
+		hi := r3.Vector{X: math.Max(a.X, b.X), Y: math.Max(a.Y, b.Y), Z: math.Max(a.Z, b.Z)}
+		return lo.Sub(r), hi.Add(r), nil
+	case *point:
+		return geom.position, geom.position, nil
+	default:
+		return r3.Vector{}, r3.Vector{}, fmt.Errorf("%w %s", ErrGeometryTypeUnsupported, fmt.Sprintf("%T", g))
+	}
+}
+
 // GeometryType defines what geometry creator representations are known.
 type GeometryType string
 
@@ -800,3 +800,59 @@ func TestCapsuleVsPointEncompassed(t *testing.T) {
 	}
 	testGeometryEncompassed(t, cases)
 }
+
+func TestBoundingBox(t *testing.T) {
+	inBounds := func(pts []r3.Vector, lo, hi r3.Vector) {
+		for _, pt := range pts {
+			test.That(t, pt.X, test.ShouldBeBetweenOrEqual, lo.X-1e-6, hi.X+1e-6)
+			test.That(t, pt.Y, test.ShouldBeBetweenOrEqual, lo.Y-1e-6, hi.Y+1e-6)
+			test.That(t, pt.Z, test.ShouldBeBetweenOrEqual, lo.Z-1e-6, hi.Z+1e-6)
+		}
+	}
+
+	b, err := NewBox(NewPoseFromPoint(r3.Vector{1, 2, 3}), r3.Vector{2, 4, 6}, "")
+	test.That(t, err, test.ShouldBeNil)
+	lo, hi, err := BoundingBox(b)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, R3VectorAlmostEqual(lo, r3.Vector{0, 0, 0}, 1e-9), test.ShouldBeTrue)
+	test.That(t, R3VectorAlmostEqual(hi, r3.Vector{2, 4, 6}, 1e-9), test.ShouldBeTrue)
+
+	// the vertices of a rotated box are in its bounding box, and some touch each side
+	pose := NewPose(r3.Vector{10, -5, 2}, &OrientationVectorDegrees{OX: 1, OY: 2, OZ: 3, Theta: 40})
+	b, err = NewBox(pose, r3.Vector{2, 4, 6}, "")
+	test.That(t, err, test.ShouldBeNil)
+	lo, hi, err = BoundingBox(b)
+	test.That(t, err, test.ShouldBeNil)
+	verts := b.(*box).vertices()
+	inBounds(verts, lo, hi)
+	tight := r3.Vector{math.Inf(1), math.Inf(1), math.Inf(1)}
+	for _, v := range verts {
+		tight.X = math.Min(tight.X, v.X)
+		tight.Y = math.Min(tight.Y, v.Y)
+		tight.Z = math.Min(tight.Z, v.Z)
+	}
+	test.That(t, R3VectorAlmostEqual(lo, tight, 1e-6), test.ShouldBeTrue)
+
+	s, err := NewSphere(NewPoseFromPoint(r3.Vector{1, 1, 1}), 2, "")
+	test.That(t, err, test.ShouldBeNil)
+	lo, hi, err = BoundingBox(s)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lo, test.ShouldResemble, r3.Vector{-1, -1, -1})
+	test.That(t, hi, test.ShouldResemble, r3.Vector{3, 3, 3})
+
+	c, err := NewCapsule(pose, 1, 8, "")
+	test.That(t, err, test.ShouldBeNil)
+	lo, hi, err = BoundingBox(c)
+	test.That(t, err, test.ShouldBeNil)
+	tips := []r3.Vector{
+		Compose(pose, NewPoseFromPoint(r3.Vector{0, 0, 4})).Point(),
+		Compose(pose, NewPoseFromPoint(r3.Vector{0, 0, -4})).Point(),
+	}
+	inBounds(tips, lo, hi)
+	test.That(t, hi.Sub(lo).Norm(), test.ShouldBeLessThan, 8+2*math.Sqrt(3))
+
+	lo, hi, err = BoundingBox(NewPoint(r3.Vector{4, 5, 6}, ""))
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, lo, test.ShouldResemble, r3.Vector{4, 5, 6})
+	test.That(t, hi, test.ShouldResemble, r3.Vector{4, 5, 6})
+}
@@ -22,6 +22,7 @@ type Camera struct {
 		errHandlers ...gostream.ErrorHandler,
 	) (gostream.VideoStream, error)
 	NextPointCloudFunc func(ctx context.Context) (pointcloud.PointCloud, error)
+	ImagesFunc         func(ctx context.Context) ([]camera.NamedImage, error)
 	ProjectorFunc      func(ctx context.Context) (transform.Projector, error)
 	PropertiesFunc     func(ctx context.Context) (camera.Properties, error)
 	CloseFunc          func(ctx context.Context) error
@@ -45,6 +46,17 @@ func (c *Camera) NextPointCloud(ctx context.Context) (pointcloud.PointCloud, err
 	return c.NextPointCloudFunc(ctx)
 }
 
+// Images calls the injected Images or the real version.
+func (c *Camera) Images(ctx context.Context) ([]camera.NamedImage, error) {
+	if c.ImagesFunc != nil {
+		return c.ImagesFunc(ctx)
+	}
+	if c.Camera != nil {
+		return camera.Images(ctx, c.Camera)
+	}
+	return nil, errors.New("no images function available")
+}
+
 // Stream calls the injected Stream or the real version.
 func (c *Camera) Stream(
 	ctx context.Context,
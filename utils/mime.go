@@ -28,6 +28,13 @@ const (
 	// MimeTypePCD is for .pcd pountcloud files.
 	MimeTypePCD = "pointcloud/pcd"
 
+	// MimeTypePLY is for .ply pointcloud files.
+	MimeTypePLY = "pointcloud/ply"
+
+	// MimeTypeCompactPointCloud is for the compact encoding of point clouds sent over the network, as
+	// explained in the comments for pointcloud.ToCompact. A resolution_mm parameter quantizes the points.
+	MimeTypeCompactPointCloud = "pointcloud/vnd.viam.pc"
+
 	// MimeTypeQOI is for .qoi "Quite OK Image" for lossless, fast encoding/decoding.
 	MimeTypeQOI = "image/qoi"
 
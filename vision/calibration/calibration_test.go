@@ -0,0 +1,163 @@
+package calibration
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+var testBoard = Checkerboard{Columns: 8, Rows: 5, SquareMM: 25}
+
+// boardImage returns an image of the test board, with a white margin of one square, centered in a square image so
+// that it can be drawn with fiducial.DrawMarker, and the pose of the board in the frame of that image.
+func boardImage() (image.Image, float64, spatialmath.Pose) {
+	const squarePx = 20
+	side := testBoard.Columns + 3
+	img := image.NewGray(image.Rect(0, 0, side*squarePx, side*squarePx))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 250}), image.Point{}, draw.Src)
+	top := float64(side-testBoard.Rows-3) / 2
+	for row := 0; row <= testBoard.Rows; row++ {
+		for col := 0; col <= testBoard.Columns; col++ {
+			if (row+col)%2 == 1 {
+				continue
+			}
+			x0, y0 := (col+1)*squarePx, int((top+1+float64(row))*squarePx)
+			draw.Draw(img, image.Rect(x0, y0, x0+squarePx, y0+squarePx), image.NewUniform(color.Gray{Y: 10}), image.Point{}, draw.Src)
+		}
+	}
+	sizeMm := float64(side) * testBoard.SquareMM
+	// the first inner corner is two squares from the left and top+2 squares from the top of the image, and the
+	// board frame is turned around the X axis of the image frame
+	firstCorner := r3.Vector{X: -sizeMm/2 + 2*testBoard.SquareMM, Y: sizeMm/2 - (top+2)*testBoard.SquareMM}
+	return img, sizeMm, spatialmath.NewPose(firstCorner, &spatialmath.R4AA{Theta: math.Pi, RX: 1})
+}
+
+// renderBoard returns the image the camera sees of the board at the given pose of the center of its image, and the
+// pixels of its corners.
+func renderBoard(camera *fiducial.Camera, pose spatialmath.Pose) (image.Image, []r2.Point) {
+	img := image.NewGray(image.Rect(0, 0, camera.Intrinsics.Width, camera.Intrinsics.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 128}), image.Point{}, draw.Src)
+	board, sizeMm, boardPose := boardImage()
+	fiducial.DrawMarker(img, board, sizeMm, pose, camera)
+
+	var corners []r2.Point
+	for _, p := range testBoard.Points() {
+		corners = append(corners, camera.Project(spatialmath.Compose(spatialmath.Compose(pose, boardPose), spatialmath.NewPoseFromPoint(p)).Point()))
+	}
+	return img, corners
+}
+
+// testPoses are poses of the center of the board image, facing the camera from different angles.
+func testPoses() []spatialmath.Pose {
+	facing := spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: math.Pi, RX: 1})
+	var poses []spatialmath.Pose
+	for _, p := range []struct {
+		x, y, z   float64
+		rx, ry    float64
+		inPlaneRZ float64
+	}{
+		{0, 0, 650, 0, 0, 0},
+		{-150, -80, 750, 0.3, -0.3, 0.1},
+		{150, 90, 700, -0.3, 0.3, -0.2},
+		{140, -100, 800, 0.4, 0.2, 0.3},
+		{-140, 100, 700, -0.2, -0.4, -0.1},
+		{0, 0, 600, 0.5, 0, math.Pi},
+		{30, -20, 650, 0, 0.5, 0.5},
+	} {
+		o := &spatialmath.R4AA{Theta: math.Hypot(p.rx, p.ry), RX: p.rx, RY: p.ry}
+		if o.Theta == 0 {
+			o = &spatialmath.R4AA{Theta: 0, RZ: 1}
+		}
+		poses = append(poses, spatialmath.Compose(
+			spatialmath.Compose(spatialmath.NewPose(r3.Vector{X: p.x, Y: p.y, Z: p.z}, o), facing),
+			spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: p.inPlaneRZ, RZ: 1}),
+		))
+	}
+	return poses
+}
+
+func testCamera() *fiducial.Camera {
+	return &fiducial.Camera{
+		Intrinsics: &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 520, Fy: 515, Ppx: 325, Ppy: 236},
+		Distortion: &transform.BrownConrady{RadialK1: -0.15, RadialK2: 0.03, TangentialP1: 0.001, TangentialP2: -0.0005},
+	}
+}
+
+func TestFindCorners(t *testing.T) {
+	camera := testCamera()
+	for _, pose := range testPoses() {
+		img, expected := renderBoard(camera, pose)
+		corners, err := FindCorners(img, testBoard)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(corners), test.ShouldEqual, len(expected))
+		for i := range corners {
+			test.That(t, corners[i].Sub(expected[i]).Norm(), test.ShouldBeLessThan, 0.3)
+		}
+	}
+
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	_, err := FindCorners(img, testBoard)
+	test.That(t, err, test.ShouldBeError, ErrBoardNotFound)
+
+	_, err = FindCorners(img, Checkerboard{Columns: 1, Rows: 5, SquareMM: 10})
+	test.That(t, err, test.ShouldNotBeNil)
+}
+
+func TestCalibrate(t *testing.T) {
+	camera := testCamera()
+	calibrator, err := NewCalibrator(testBoard)
+	test.That(t, err, test.ShouldBeNil)
+
+	_, err = calibrator.Calibrate()
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 views")
+
+	poses := testPoses()
+	for _, pose := range poses {
+		img, _ := renderBoard(camera, pose)
+		test.That(t, calibrator.AddImage(img), test.ShouldBeNil)
+	}
+	test.That(t, calibrator.Views(), test.ShouldEqual, len(poses))
+	test.That(t, calibrator.AddImage(image.NewGray(image.Rect(0, 0, 640, 480))), test.ShouldBeError, ErrBoardNotFound)
+	test.That(t, calibrator.AddImage(image.NewGray(image.Rect(0, 0, 320, 240))), test.ShouldNotBeNil)
+
+	cal, err := calibrator.Calibrate()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, cal.RMSErrorPx, test.ShouldBeLessThan, 0.2)
+	test.That(t, len(cal.ViewErrorsPx), test.ShouldEqual, len(poses))
+	test.That(t, cal.Intrinsics.Width, test.ShouldEqual, 640)
+	test.That(t, cal.Intrinsics.Height, test.ShouldEqual, 480)
+	test.That(t, cal.Intrinsics.Fx, test.ShouldAlmostEqual, 520, 3)
+	test.That(t, cal.Intrinsics.Fy, test.ShouldAlmostEqual, 515, 3)
+	test.That(t, cal.Intrinsics.Ppx, test.ShouldAlmostEqual, 325, 3)
+	test.That(t, cal.Intrinsics.Ppy, test.ShouldAlmostEqual, 236, 3)
+	test.That(t, cal.Distortion.RadialK1, test.ShouldAlmostEqual, -0.15, 0.02)
+
+	// the estimated distortion matches the actual one over the image
+	for _, p := range []r2.Point{{X: 0.5, Y: 0.4}, {X: -0.5, Y: 0.3}, {X: 0.2, Y: -0.45}} {
+		x, y := cal.Distortion.Transform(p.X, p.Y)
+		ex, ey := camera.Distortion.Transform(p.X, p.Y)
+		test.That(t, math.Hypot(x-ex, y-ey)*520, test.ShouldBeLessThan, 1)
+	}
+
+	_, _, boardPose := boardImage()
+	for i, pose := range cal.Poses {
+		expected := spatialmath.Compose(poses[i], boardPose)
+		test.That(t, pose.Point().Sub(expected.Point()).Norm(), test.ShouldBeLessThan, 10)
+		test.That(t, spatialmath.PoseBetween(expected, pose).Orientation().AxisAngles().Theta, test.ShouldBeLessThan, 0.02)
+	}
+
+	attrs := cal.CameraAttributes()
+	test.That(t, attrs.IntrinsicParams, test.ShouldEqual, cal.Intrinsics)
+	test.That(t, attrs.DistortionParams, test.ShouldEqual, cal.Distortion)
+}
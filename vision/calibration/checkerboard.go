@@ -0,0 +1,401 @@
+// Package calibration estimates the intrinsic parameters and lens distortion of cameras from images of
+// checkerboards.
+package calibration
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ringRadius is the radius in pixels of the ring of samples used to find the corners of checkerboards.
+	ringRadius = 5
+	// minResponseRatio is the smallest corner response, relative to the strongest one, of candidate corners.
+	minResponseRatio = 0.1
+	// minResponse is the smallest corner response of candidate corners, so that flat images have none.
+	minResponse = 200.
+	// refineRadius is the half size of the window used to refine corners to subpixel accuracy.
+	refineRadius = 4
+	// neighborTolerance is how far, relative to the distance between corners, a corner can be from where it is
+	// expected in the grid.
+	neighborTolerance = 0.3
+)
+
+// ErrBoardNotFound is returned when there is no checkerboard in an image.
+var ErrBoardNotFound = errors.New("checkerboard not found")
+
+// A Checkerboard is a calibration target of black and white squares. It is described by the number of corners where
+// four squares meet along its rows and columns, so a board of 10 by 7 squares has 9 by 6 inner corners. Boards with an
+// even number of inner corners along one side and an odd number along the other are recommended, as their corners can
+// be numbered the same way whichever way up they are seen.
+type Checkerboard struct {
+	Columns  int     `json:"columns"`
+	Rows     int     `json:"rows"`
+	SquareMM float64 `json:"square_mm"`
+}
+
+// Validate ensures the board can be used for calibration.
+func (b *Checkerboard) Validate() error {
+	if b.Columns < 2 || b.Rows < 2 {
+		return errors.Errorf("a checkerboard needs at least 2 by 2 inner corners, not %d by %d", b.Columns, b.Rows)
+	}
+	if b.SquareMM <= 0 {
+		return errors.New("the squares of a checkerboard must have a positive size")
+	}
+	return nil
+}
+
+// Points returns the inner corners of the board in its frame, row by row. The origin of the frame of a board is its
+// first inner corner, its +X axis points along its rows, its +Y axis along its columns and its +Z axis into it, away
+// from the side with the squares.
+func (b *Checkerboard) Points() []r3.Vector {
+	points := make([]r3.Vector, 0, b.Columns*b.Rows)
+	for row := 0; row < b.Rows; row++ {
+		for col := 0; col < b.Columns; col++ {
+			points = append(points, r3.Vector{X: float64(col) * b.SquareMM, Y: float64(row) * b.SquareMM})
+		}
+	}
+	return points
+}
+
+// FindCorners returns the pixels of the inner corners of the board in the image, row by row, in the order of Points.
+// The first corner is the one next to a black corner square when that tells the sides of the board apart, and the
+// closest to the top left of the image otherwise.
+func FindCorners(img image.Image, board Checkerboard) ([]r2.Point, error) {
+	if err := board.Validate(); err != nil {
+		return nil, err
+	}
+	gray := newGrayImage(img)
+	candidates := findCandidates(gray)
+	if len(candidates) < board.Columns*board.Rows {
+		return nil, ErrBoardNotFound
+	}
+	for i := range candidates {
+		candidates[i] = gray.refineCorner(candidates[i])
+	}
+
+	// start growing the grid from the strongest corners, which are the most likely to be on the board
+	for _, start := range candidates[:minInt(len(candidates), 10)] {
+		grid, ok := growGrid(candidates, start)
+		if !ok {
+			continue
+		}
+		if corners, ok := orderGrid(gray, grid, board); ok {
+			return corners, nil
+		}
+	}
+	return nil, ErrBoardNotFound
+}
+
+// findCandidates returns the local maxima of the ChESS corner response, which is high where four squares meet, from
+// the strongest one.
+func findCandidates(gray *grayImage) []r2.Point {
+	const samples = 16
+	var ring [samples]image.Point
+	for i := range ring {
+		angle := 2 * math.Pi * float64(i) / samples
+		ring[i] = image.Point{X: int(math.Round(ringRadius * math.Cos(angle))), Y: int(math.Round(ringRadius * math.Sin(angle)))}
+	}
+
+	w, h := gray.w, gray.h
+	response := make([]float64, w*h)
+	var maxResponse float64
+	for y := ringRadius; y < h-ringRadius; y++ {
+		for x := ringRadius; x < w-ringRadius; x++ {
+			var values [samples]float64
+			var ringMean float64
+			for i, p := range ring {
+				values[i] = gray.at(x+p.X, y+p.Y)
+				ringMean += values[i]
+			}
+			ringMean /= samples
+			// opposite samples are alike and samples a quarter turn apart are different around corners, but not
+			// along edges
+			var sum, diff float64
+			for i := 0; i < samples/4; i++ {
+				sum += math.Abs(values[i] + values[i+8] - values[i+4] - values[i+12])
+			}
+			for i := 0; i < samples/2; i++ {
+				diff += math.Abs(values[i] - values[i+8])
+			}
+			localMean := (gray.at(x, y) + gray.at(x-1, y) + gray.at(x+1, y) + gray.at(x, y-1) + gray.at(x, y+1)) / 5
+			r := sum - diff - 16*math.Abs(ringMean-localMean)
+			response[y*w+x] = r
+			maxResponse = math.Max(maxResponse, r)
+		}
+	}
+
+	threshold := math.Max(minResponse, minResponseRatio*maxResponse)
+	type candidate struct {
+		p r2.Point
+		r float64
+	}
+	var found []candidate
+	const suppression = 3
+	for y := ringRadius; y < h-ringRadius; y++ {
+		for x := ringRadius; x < w-ringRadius; x++ {
+			r := response[y*w+x]
+			if r < threshold {
+				continue
+			}
+			isMax := true
+			for dy := -suppression; dy <= suppression && isMax; dy++ {
+				for dx := -suppression; dx <= suppression; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h || (dx == 0 && dy == 0) {
+						continue
+					}
+					// ties go to the first pixel, so that plateaus give a single corner
+					if n := response[ny*w+nx]; n > r || (n == r && (dy < 0 || (dy == 0 && dx < 0))) {
+						isMax = false
+						break
+					}
+				}
+			}
+			if isMax {
+				found = append(found, candidate{r2.Point{X: float64(x), Y: float64(y)}, r})
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].r > found[j].r })
+	points := make([]r2.Point, len(found))
+	for i, c := range found {
+		points[i] = c.p
+	}
+	return points
+}
+
+// refineCorner moves the corner to the point where the lines along the gradients in the window around it meet best.
+func (g *grayImage) refineCorner(p r2.Point) r2.Point {
+	for iteration := 0; iteration < 5; iteration++ {
+		cx, cy := int(math.Round(p.X)), int(math.Round(p.Y))
+		var a00, a01, a11, b0, b1 float64
+		for dy := -refineRadius; dy <= refineRadius; dy++ {
+			for dx := -refineRadius; dx <= refineRadius; dx++ {
+				x, y := cx+dx, cy+dy
+				if x < 1 || y < 1 || x >= g.w-1 || y >= g.h-1 {
+					continue
+				}
+				gx := (g.at(x+1, y) - g.at(x-1, y)) / 2
+				gy := (g.at(x, y+1) - g.at(x, y-1)) / 2
+				weight := math.Exp(-float64(dx*dx+dy*dy) / (refineRadius * refineRadius))
+				gxx, gxy, gyy := weight*gx*gx, weight*gx*gy, weight*gy*gy
+				a00 += gxx
+				a01 += gxy
+				a11 += gyy
+				b0 += gxx*float64(x) + gxy*float64(y)
+				b1 += gxy*float64(x) + gyy*float64(y)
+			}
+		}
+		det := a00*a11 - a01*a01
+		if math.Abs(det) < 1e-9 {
+			return p
+		}
+		next := r2.Point{X: (a11*b0 - a01*b1) / det, Y: (a00*b1 - a01*b0) / det}
+		if next.Sub(p).Norm() > refineRadius {
+			return p
+		}
+		moved := next.Sub(p).Norm()
+		p = next
+		if moved < 0.01 {
+			break
+		}
+	}
+	return p
+}
+
+type gridIndex struct{ col, row int }
+
+type gridNode struct {
+	p    r2.Point
+	u, v r2.Point
+}
+
+// growGrid finds the corners connected to the start one in a grid, stepping from corner to corner along the lines
+// of the grid.
+func growGrid(candidates []r2.Point, start r2.Point) (map[gridIndex]*gridNode, bool) {
+	nearest := func(p r2.Point, maxDist float64, exclude func(r2.Point) bool) (r2.Point, bool) {
+		best, bestDist := r2.Point{}, maxDist
+		for _, c := range candidates {
+			if d := c.Sub(p).Norm(); d < bestDist && !exclude(c) {
+				best, bestDist = c, d
+			}
+		}
+		return best, bestDist < maxDist
+	}
+
+	self := func(c r2.Point) bool { return c == start }
+	first, ok := nearest(start, math.Inf(1), self)
+	if !ok {
+		return nil, false
+	}
+	u := first.Sub(start)
+	second, ok := nearest(start, 3*u.Norm(), func(c r2.Point) bool {
+		d := c.Sub(start)
+		return self(c) || math.Abs(d.Dot(u))/(d.Norm()*u.Norm()) > 0.5
+	})
+	if !ok {
+		return nil, false
+	}
+	v := second.Sub(start)
+
+	grid := map[gridIndex]*gridNode{{0, 0}: {p: start, u: u, v: v}}
+	used := map[r2.Point]bool{start: true}
+	queue := []gridIndex{{0, 0}}
+	for len(queue) > 0 {
+		idx := queue[0]
+		queue = queue[1:]
+		node := grid[idx]
+		for _, step := range []struct {
+			next   gridIndex
+			offset r2.Point
+			alongU bool
+		}{
+			{gridIndex{idx.col + 1, idx.row}, node.u, true},
+			{gridIndex{idx.col - 1, idx.row}, node.u.Mul(-1), true},
+			{gridIndex{idx.col, idx.row + 1}, node.v, false},
+			{gridIndex{idx.col, idx.row - 1}, node.v.Mul(-1), false},
+		} {
+			if _, ok := grid[step.next]; ok {
+				continue
+			}
+			found, ok := nearest(node.p.Add(step.offset), neighborTolerance*step.offset.Norm(), func(c r2.Point) bool { return used[c] })
+			if !ok {
+				continue
+			}
+			next := &gridNode{p: found, u: node.u, v: node.v}
+			// the steps between corners change across the image with perspective
+			if step.alongU {
+				next.u = found.Sub(node.p).Mul(float64(step.next.col - idx.col))
+			} else {
+				next.v = found.Sub(node.p).Mul(float64(step.next.row - idx.row))
+			}
+			grid[step.next] = next
+			used[found] = true
+			queue = append(queue, step.next)
+		}
+	}
+	return grid, true
+}
+
+// orderGrid returns the corners of the grid in the order of the points of the board, if the grid is the board.
+func orderGrid(gray *grayImage, grid map[gridIndex]*gridNode, board Checkerboard) ([]r2.Point, bool) {
+	if len(grid) != board.Columns*board.Rows {
+		return nil, false
+	}
+	minIdx, maxIdx := gridIndex{math.MaxInt, math.MaxInt}, gridIndex{math.MinInt, math.MinInt}
+	for idx := range grid {
+		minIdx = gridIndex{minInt(minIdx.col, idx.col), minInt(minIdx.row, idx.row)}
+		maxIdx = gridIndex{maxInt(maxIdx.col, idx.col), maxInt(maxIdx.row, idx.row)}
+	}
+	cols, rows := maxIdx.col-minIdx.col+1, maxIdx.row-minIdx.row+1
+	at := func(col, row int) r2.Point { return grid[gridIndex{minIdx.col + col, minIdx.row + row}].p }
+
+	var corners []r2.Point
+	switch {
+	case cols == board.Columns && rows == board.Rows:
+		for row := 0; row < rows; row++ {
+			for col := 0; col < cols; col++ {
+				corners = append(corners, at(col, row))
+			}
+		}
+	case cols == board.Rows && rows == board.Columns:
+		for col := 0; col < cols; col++ {
+			for row := 0; row < rows; row++ {
+				corners = append(corners, at(col, row))
+			}
+		}
+	default:
+		return nil, false
+	}
+
+	c := board.Columns
+	index := func(col, row int) int { return row*c + col }
+	// the board is seen from the side with the squares, so its rows go clockwise from its columns in the image
+	u := corners[index(1, 0)].Sub(corners[0])
+	v := corners[index(0, 1)].Sub(corners[0])
+	if u.Cross(v) < 0 {
+		for row := 0; row < board.Rows/2; row++ {
+			for col := 0; col < c; col++ {
+				i, j := index(col, row), index(col, board.Rows-1-row)
+				corners[i], corners[j] = corners[j], corners[i]
+			}
+		}
+	}
+
+	// turning the board around gives the other numbering with the same handedness
+	outside := func(corner, next1, next2 r2.Point) float64 {
+		// the center of the square diagonally outside of the corner
+		p := corner.Sub(next1.Sub(corner).Add(next2.Sub(corner)).Mul(0.5))
+		return gray.bilinear(p.X, p.Y)
+	}
+	last := len(corners) - 1
+	firstSquare := outside(corners[0], corners[index(1, 0)], corners[index(0, 1)])
+	lastSquare := outside(corners[last], corners[index(c-2, board.Rows-1)], corners[index(c-1, board.Rows-2)])
+	var reverse bool
+	if (board.Columns+board.Rows)%2 == 1 {
+		reverse = firstSquare > lastSquare
+	} else {
+		reverse = corners[last].X+corners[last].Y < corners[0].X+corners[0].Y
+	}
+	if reverse {
+		for i, j := 0, last; i < j; i, j = i+1, j-1 {
+			corners[i], corners[j] = corners[j], corners[i]
+		}
+	}
+	return corners, true
+}
+
+// grayImage holds the intensities of the pixels of an image, whose centers are at integer coordinates.
+type grayImage struct {
+	w, h int
+	pix  []float64
+}
+
+func newGrayImage(img image.Image) *grayImage {
+	bounds := img.Bounds()
+	g := &grayImage{w: bounds.Dx(), h: bounds.Dy(), pix: make([]float64, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			c, _ := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			g.pix[y*g.w+x] = float64(c.Y)
+		}
+	}
+	return g
+}
+
+func (g *grayImage) at(x, y int) float64 {
+	return g.pix[y*g.w+x]
+}
+
+// bilinear returns the intensity at the given point, interpolated between the four pixels around it.
+func (g *grayImage) bilinear(x, y float64) float64 {
+	x = math.Max(0, math.Min(float64(g.w-1), x))
+	y = math.Max(0, math.Min(float64(g.h-1), y))
+	x0, y0 := int(x), int(y)
+	x1, y1 := minInt(x0+1, g.w-1), minInt(y0+1, g.h-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+	top := g.at(x0, y0)*(1-fx) + g.at(x1, y0)*fx
+	bottom := g.at(x0, y1)*(1-fx) + g.at(x1, y1)*fx
+	return top*(1-fy) + bottom*fy
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,292 @@
+package calibration
+
+import (
+	"context"
+	"image"
+	"math"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/referenceframe"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/utils"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+// minHandEyePoses is the number of poses needed for hand-eye calibration, which gives two motions of the arm.
+const minHandEyePoses = 3
+
+// ErrTargetNotFound is returned when the calibration target is not in view.
+var ErrTargetNotFound = errors.New("calibration target not found")
+
+// HandEyeSetup is where the camera is in a hand-eye calibration.
+type HandEyeSetup string
+
+const (
+	// EyeInHand is for cameras mounted on the arm, looking at a target fixed in the world. The calibration gives the
+	// pose of the camera in the frame of the end of the arm.
+	EyeInHand = HandEyeSetup("eye_in_hand")
+	// EyeToHand is for cameras fixed in the world, looking at a target held by the arm. The calibration gives the pose
+	// of the camera in the frame the arm gives its end positions in, which is usually its base.
+	EyeToHand = HandEyeSetup("eye_to_hand")
+)
+
+// HandEyeCalibration is the result of a hand-eye calibration.
+type HandEyeCalibration struct {
+	// Pose of the camera in the frame of the end of the arm for EyeInHand setups, or in the frame of the base of the
+	// arm for EyeToHand setups.
+	Pose spatialmath.Pose
+	// RotationErrorDeg and TranslationErrorMM are the root mean square differences between the motions of the arm and
+	// the motions of the target seen by the camera, moved to the frame of the arm. They are large when the poses of
+	// the target or of the arm are inaccurate.
+	RotationErrorDeg   float64
+	TranslationErrorMM float64
+}
+
+// LinkConfig returns the config of the frame of the camera, with the given name and parent frame, which is the end
+// of the arm for EyeInHand setups or the frame of the base of the arm for EyeToHand setups.
+func (c *HandEyeCalibration) LinkConfig(id, parent string) (*referenceframe.LinkConfig, error) {
+	orientation, err := spatialmath.NewOrientationConfig(c.Pose.Orientation())
+	if err != nil {
+		return nil, err
+	}
+	return &referenceframe.LinkConfig{ID: id, Translation: c.Pose.Point(), Orientation: orientation, Parent: parent}, nil
+}
+
+// SolveHandEye returns the pose of the camera, in the frame of the end of the arm or of its base depending on the
+// setup, from poses of the end of the arm and the poses of the target in the frame of the camera at the same times.
+//
+// Each pair of poses gives a motion A of the arm and the corresponding motion B of the target seen by the camera,
+// and the pose X of the camera solves AX = XB for all of them. The rotation of X is found first with the method of
+// Park and Martin, then its translation with least squares. The arm must rotate around at least two different axes
+// across the poses.
+func SolveHandEye(setup HandEyeSetup, armPoses, targetPoses []spatialmath.Pose) (*HandEyeCalibration, error) {
+	if setup != EyeInHand && setup != EyeToHand {
+		return nil, errors.Errorf("unknown hand-eye setup %q, must be %s or %s", setup, EyeInHand, EyeToHand)
+	}
+	if len(armPoses) != len(targetPoses) {
+		return nil, errors.Errorf("have %d arm poses but %d target poses", len(armPoses), len(targetPoses))
+	}
+	if len(armPoses) < minHandEyePoses {
+		return nil, errors.Errorf("need at least %d poses, have %d", minHandEyePoses, len(armPoses))
+	}
+
+	// motions between all the pairs of poses
+	var as, bs []spatialmath.Pose
+	for i := range armPoses {
+		for j := i + 1; j < len(armPoses); j++ {
+			b := spatialmath.Compose(targetPoses[j], spatialmath.PoseInverse(targetPoses[i]))
+			if setup == EyeInHand {
+				as = append(as, spatialmath.Compose(spatialmath.PoseInverse(armPoses[j]), armPoses[i]))
+			} else {
+				as = append(as, spatialmath.Compose(armPoses[j], spatialmath.PoseInverse(armPoses[i])))
+			}
+			bs = append(bs, b)
+		}
+	}
+
+	// the rotation vectors of the motions are rotated by X: alpha = R_X beta, which is solved as an orthogonal
+	// Procrustes problem
+	h := mat.NewDense(3, 3, nil)
+	for k := range as {
+		alpha := as[k].Orientation().AxisAngles().ToR3()
+		beta := bs[k].Orientation().AxisAngles().ToR3()
+		var outer mat.Dense
+		outer.Outer(1, mat.NewVecDense(3, []float64{beta.X, beta.Y, beta.Z}), mat.NewVecDense(3, []float64{alpha.X, alpha.Y, alpha.Z}))
+		h.Add(h, &outer)
+	}
+	var svd mat.SVD
+	if !svd.Factorize(h, mat.SVDFull) {
+		return nil, errors.New("could not solve for the rotation of the camera")
+	}
+	if values := svd.Values(nil); values[1] < 1e-6*values[0] {
+		return nil, errors.New("the arm must rotate around at least two different axes")
+	}
+	var u, v, rotation mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	rotation.Mul(&v, u.T())
+	if mat.Det(&rotation) < 0 {
+		// the closest proper rotation flips the axis of the smallest singular value
+		fix := mat.NewDiagDense(3, []float64{1, 1, -1})
+		var vFixed mat.Dense
+		vFixed.Mul(&v, fix)
+		rotation.Mul(&vFixed, u.T())
+	}
+	orientation := orientationFromMatrix(&rotation)
+
+	// with the rotation known, the translation solves (R_A - I) t_X = R_X t_B - t_A
+	rows := make([]float64, 0, 9*len(as))
+	rhs := make([]float64, 0, 3*len(as))
+	for k := range as {
+		o := as[k].Orientation()
+		colX, colY, colZ := rotate(o, r3.Vector{X: 1}), rotate(o, r3.Vector{Y: 1}), rotate(o, r3.Vector{Z: 1})
+		rows = append(rows,
+			colX.X-1, colY.X, colZ.X,
+			colX.Y, colY.Y-1, colZ.Y,
+			colX.Z, colY.Z, colZ.Z-1,
+		)
+		r := rotate(orientation, bs[k].Point()).Sub(as[k].Point())
+		rhs = append(rhs, r.X, r.Y, r.Z)
+	}
+	var t mat.VecDense
+	if err := t.SolveVec(mat.NewDense(len(rhs), 3, rows), mat.NewVecDense(len(rhs), rhs)); err != nil {
+		return nil, errors.Wrap(err, "could not solve for the translation of the camera")
+	}
+	x := spatialmath.NewPose(r3.Vector{X: t.AtVec(0), Y: t.AtVec(1), Z: t.AtVec(2)}, orientation)
+
+	result := &HandEyeCalibration{Pose: x}
+	for k := range as {
+		ax := spatialmath.Compose(as[k], x)
+		xb := spatialmath.Compose(x, bs[k])
+		angle := spatialmath.PoseBetween(xb, ax).Orientation().AxisAngles().Theta
+		result.RotationErrorDeg += angle * angle
+		d := ax.Point().Sub(xb.Point()).Norm()
+		result.TranslationErrorMM += d * d
+	}
+	result.RotationErrorDeg = utils.RadToDeg(math.Sqrt(result.RotationErrorDeg / float64(len(as))))
+	result.TranslationErrorMM = math.Sqrt(result.TranslationErrorMM / float64(len(as)))
+	return result, nil
+}
+
+// rotate returns the vector rotated by the orientation.
+func rotate(o spatialmath.Orientation, v r3.Vector) r3.Vector {
+	return spatialmath.Compose(spatialmath.NewPoseFromOrientation(o), spatialmath.NewPoseFromPoint(v)).Point()
+}
+
+// orientationFromMatrix returns the orientation rotating vectors by the matrix.
+func orientationFromMatrix(m mat.Matrix) spatialmath.Orientation {
+	// the rows of rotation matrices of the spatialmath package are the images of the axes, which are the columns
+	// of m
+	rm, err := spatialmath.NewRotationMatrix([]float64{
+		m.At(0, 0), m.At(1, 0), m.At(2, 0),
+		m.At(0, 1), m.At(1, 1), m.At(2, 1),
+		m.At(0, 2), m.At(1, 2), m.At(2, 2),
+	})
+	if err != nil {
+		return spatialmath.NewZeroOrientation()
+	}
+	return rm
+}
+
+// GenerateHandEyePoses returns poses for an arm to go through for hand-eye calibration. They are the start pose rotated
+// by maxAngleDeg, or half of it, around axes going different ways through a pivot pivotDistanceMM along the +Z axis of
+// the start pose. The start pose should show the target to the camera and the pivot should be on the target, so that
+// it stays in view.
+func GenerateHandEyePoses(start spatialmath.Pose, pivotDistanceMM, maxAngleDeg float64, count int) []spatialmath.Pose {
+	toPivot := spatialmath.NewPoseFromPoint(r3.Vector{Z: pivotDistanceMM})
+	fromPivot := spatialmath.NewPoseFromPoint(r3.Vector{Z: -pivotDistanceMM})
+	// the golden angle spreads the axes evenly around the pivot whatever their number
+	goldenAngle := math.Pi * (3 - math.Sqrt(5))
+	poses := make([]spatialmath.Pose, 0, count)
+	for i := 0; i < count; i++ {
+		azimuth := goldenAngle * float64(i)
+		axis := r3.Vector{X: math.Cos(azimuth), Y: math.Sin(azimuth), Z: 0.3 * math.Cos(3*azimuth)}
+		angle := utils.DegToRad(maxAngleDeg)
+		if i%2 == 1 {
+			angle /= 2
+		}
+		rotation := spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: angle, RX: axis.X, RY: axis.Y, RZ: axis.Z})
+		poses = append(poses, spatialmath.Compose(spatialmath.Compose(spatialmath.Compose(start, toPivot), rotation), fromPivot))
+	}
+	return poses
+}
+
+// An Arm is the part of the arm API used for hand-eye calibration.
+type Arm interface {
+	EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error)
+	MoveToPosition(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error
+}
+
+// A TargetLocator returns the pose of the calibration target in the frame of the camera. It returns an error wrapping
+// ErrTargetNotFound when the target is not in view.
+type TargetLocator func(ctx context.Context) (spatialmath.Pose, error)
+
+// An ImageSource returns the current image of a camera, like camera.ReadImage does.
+type ImageSource func(ctx context.Context) (image.Image, func(), error)
+
+// CheckerboardLocator returns a TargetLocator finding the board in the images of the camera.
+func CheckerboardLocator(board Checkerboard, camera *fiducial.Camera, images ImageSource) TargetLocator {
+	points := board.Points()
+	planar := make([]r2.Point, len(points))
+	for i, p := range points {
+		planar[i] = r2.Point{X: p.X, Y: p.Y}
+	}
+	return func(ctx context.Context) (spatialmath.Pose, error) {
+		img, release, err := images(ctx)
+		if err != nil {
+			return nil, err
+		}
+		corners, err := FindCorners(img, board)
+		release()
+		if errors.Is(err, ErrBoardNotFound) {
+			return nil, ErrTargetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		pose, _, err := fiducial.EstimatePlanarPose(planar, corners, camera)
+		return pose, err
+	}
+}
+
+// MarkerLocator returns a TargetLocator finding the marker with the given ID and size in the images of the camera.
+func MarkerLocator(detector *fiducial.Detector, id int, sizeMM float64, camera *fiducial.Camera, images ImageSource) TargetLocator {
+	return func(ctx context.Context) (spatialmath.Pose, error) {
+		img, release, err := images(ctx)
+		if err != nil {
+			return nil, err
+		}
+		detections := detector.Detect(img)
+		release()
+		for _, det := range detections {
+			if det.ID == id {
+				pose, _, err := fiducial.EstimatePose(det.Corners, sizeMM, camera)
+				return pose, err
+			}
+		}
+		return nil, errors.Wrapf(ErrTargetNotFound, "marker %d", id)
+	}
+}
+
+// CalibrateHandEye moves the arm through the poses, locates the target at each of them, and solves for the pose of
+// the camera. Poses where the arm cannot go or the target is not found are skipped.
+func CalibrateHandEye(
+	ctx context.Context,
+	setup HandEyeSetup,
+	arm Arm,
+	locate TargetLocator,
+	poses []spatialmath.Pose,
+	logger golog.Logger,
+) (*HandEyeCalibration, error) {
+	var armPoses, targetPoses []spatialmath.Pose
+	for i, pose := range poses {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := arm.MoveToPosition(ctx, pose, nil); err != nil {
+			logger.Warnw("skipping pose the arm could not move to", "pose", i, "error", err)
+			continue
+		}
+		// the arm may not have reached the pose exactly, so use where it actually is
+		armPose, err := arm.EndPosition(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		targetPose, err := locate(ctx)
+		if err != nil {
+			if errors.Is(err, ErrTargetNotFound) {
+				logger.Warnw("skipping pose the target cannot be seen from", "pose", i)
+				continue
+			}
+			return nil, err
+		}
+		armPoses = append(armPoses, armPose)
+		targetPoses = append(targetPoses, targetPose)
+	}
+	return SolveHandEye(setup, armPoses, targetPoses)
+}
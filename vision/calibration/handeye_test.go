@@ -0,0 +1,177 @@
+package calibration
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/edaniels/golog"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+func TestSolveHandEye(t *testing.T) {
+	x := spatialmath.NewPose(r3.Vector{X: 40, Y: -25, Z: 60}, &spatialmath.R4AA{Theta: 0.3, RX: 0.2, RY: -0.5, RZ: 1})
+	target := spatialmath.NewPose(r3.Vector{X: 500, Y: 100, Z: -50}, &spatialmath.R4AA{Theta: 2.5, RX: 1, RY: 0.3})
+	start := spatialmath.NewPose(r3.Vector{X: 300, Y: 50, Z: 400}, &spatialmath.R4AA{Theta: math.Pi, RX: 1})
+	armPoses := GenerateHandEyePoses(start, 400, 20, 8)
+	test.That(t, len(armPoses), test.ShouldEqual, 8)
+
+	rnd := rand.New(rand.NewSource(1))
+	noisy := func(p spatialmath.Pose, mm, rad float64) spatialmath.Pose {
+		return spatialmath.Compose(p, spatialmath.NewPose(
+			r3.Vector{X: rnd.NormFloat64() * mm, Y: rnd.NormFloat64() * mm, Z: rnd.NormFloat64() * mm},
+			&spatialmath.R4AA{Theta: rnd.NormFloat64() * rad, RX: rnd.Float64() - 0.5, RY: rnd.Float64() - 0.5, RZ: rnd.Float64() - 0.5},
+		))
+	}
+
+	for _, setup := range []HandEyeSetup{EyeInHand, EyeToHand} {
+		t.Run(string(setup), func(t *testing.T) {
+			targetPoses := make([]spatialmath.Pose, len(armPoses))
+			for i, g := range armPoses {
+				if setup == EyeInHand {
+					// the target is fixed in the world and the camera is at g x
+					targetPoses[i] = spatialmath.Compose(spatialmath.PoseInverse(spatialmath.Compose(g, x)), target)
+				} else {
+					// the target is fixed to the end of the arm and the camera is at x in the world
+					targetPoses[i] = spatialmath.Compose(spatialmath.PoseInverse(x), spatialmath.Compose(g, target))
+				}
+			}
+			result, err := SolveHandEye(setup, armPoses, targetPoses)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, spatialmath.PoseAlmostEqualEps(result.Pose, x, 1e-6), test.ShouldBeTrue)
+			test.That(t, result.RotationErrorDeg, test.ShouldBeLessThan, 1e-6)
+			test.That(t, result.TranslationErrorMM, test.ShouldBeLessThan, 1e-6)
+
+			for i := range targetPoses {
+				targetPoses[i] = noisy(targetPoses[i], 0.5, 0.002)
+			}
+			result, err = SolveHandEye(setup, armPoses, targetPoses)
+			test.That(t, err, test.ShouldBeNil)
+			test.That(t, result.Pose.Point().Sub(x.Point()).Norm(), test.ShouldBeLessThan, 5)
+			test.That(t, spatialmath.PoseBetween(x, result.Pose).Orientation().AxisAngles().Theta, test.ShouldBeLessThan, 0.01)
+			test.That(t, result.RotationErrorDeg, test.ShouldBeGreaterThan, 0)
+			test.That(t, result.TranslationErrorMM, test.ShouldBeGreaterThan, 0)
+		})
+	}
+
+	_, err := SolveHandEye(EyeInHand, armPoses[:2], armPoses[:2])
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = SolveHandEye(EyeInHand, armPoses, armPoses[:3])
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = SolveHandEye("eye_on_hand", armPoses, armPoses)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	// rotations around a single axis leave the calibration undetermined
+	var spins []spatialmath.Pose
+	for i := 0; i < 4; i++ {
+		spins = append(spins, spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: 0.3 * float64(i), RZ: 1}))
+	}
+	_, err = SolveHandEye(EyeInHand, spins, spins)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	result := &HandEyeCalibration{Pose: x}
+	link, err := result.LinkConfig("cam", "arm")
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, link.ID, test.ShouldEqual, "cam")
+	test.That(t, link.Parent, test.ShouldEqual, "arm")
+	pose, err := link.Pose()
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, spatialmath.PoseAlmostEqual(pose, x), test.ShouldBeTrue)
+}
+
+// fakeArm goes to the poses it is asked to, except for unreachable ones.
+type fakeArm struct {
+	mu          sync.Mutex
+	pose        spatialmath.Pose
+	unreachable spatialmath.Pose
+}
+
+func (a *fakeArm) EndPosition(ctx context.Context, extra map[string]interface{}) (spatialmath.Pose, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.pose, nil
+}
+
+func (a *fakeArm) MoveToPosition(ctx context.Context, pose spatialmath.Pose, extra map[string]interface{}) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if pose == a.unreachable {
+		return errors.New("out of reach")
+	}
+	a.pose = pose
+	return nil
+}
+
+func TestCalibrateHandEye(t *testing.T) {
+	camera := &fiducial.Camera{
+		Intrinsics: &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240},
+	}
+	// the camera is on the end of the arm, looking at a board fixed in the world
+	x := spatialmath.NewPose(r3.Vector{X: 30, Y: -20, Z: 50}, &spatialmath.R4AA{Theta: 0.2, RZ: 1})
+	board := spatialmath.NewPose(r3.Vector{X: 400, Y: 100}, &spatialmath.R4AA{Theta: 0.4, RZ: 1})
+	inView := spatialmath.NewPose(r3.Vector{Z: 650}, &spatialmath.R4AA{Theta: math.Pi, RX: 1})
+	start := spatialmath.Compose(spatialmath.Compose(board, spatialmath.PoseInverse(inView)), spatialmath.PoseInverse(x))
+	poses := GenerateHandEyePoses(start, 700, 20, 8)
+
+	arm := &fakeArm{unreachable: poses[2]}
+	images := func(ctx context.Context) (image.Image, func(), error) {
+		g, err := arm.EndPosition(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		img, _ := renderBoard(camera, spatialmath.Compose(spatialmath.PoseInverse(spatialmath.Compose(g, x)), board))
+		return img, func() {}, nil
+	}
+
+	result, err := CalibrateHandEye(
+		context.Background(), EyeInHand, arm, CheckerboardLocator(testBoard, camera, images), poses, golog.NewTestLogger(t),
+	)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, result.Pose.Point().Sub(x.Point()).Norm(), test.ShouldBeLessThan, 3)
+	test.That(t, spatialmath.PoseBetween(x, result.Pose).Orientation().AxisAngles().Theta, test.ShouldBeLessThan, 0.01)
+
+	// without the board in view, there is nothing to calibrate with
+	blank := func(ctx context.Context) (image.Image, func(), error) {
+		return image.NewGray(image.Rect(0, 0, 640, 480)), func() {}, nil
+	}
+	_, err = CalibrateHandEye(
+		context.Background(), EyeInHand, arm, CheckerboardLocator(testBoard, camera, blank), poses, golog.NewTestLogger(t),
+	)
+	test.That(t, err, test.ShouldNotBeNil)
+	test.That(t, err.Error(), test.ShouldContainSubstring, "at least 3 poses")
+}
+
+func TestMarkerLocator(t *testing.T) {
+	camera := &fiducial.Camera{
+		Intrinsics: &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240},
+	}
+	dictionary := fiducial.ArucoOriginal()
+	marker, err := dictionary.MarkerImage(7, 10)
+	test.That(t, err, test.ShouldBeNil)
+	pose := spatialmath.NewPose(r3.Vector{X: 30, Y: -20, Z: 500}, &spatialmath.R4AA{Theta: math.Pi - 0.3, RX: 1, RY: 0.2})
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 230}), image.Point{}, draw.Src)
+	fiducial.DrawMarker(img, marker, 80, pose, camera)
+	images := func(ctx context.Context) (image.Image, func(), error) {
+		return img, func() {}, nil
+	}
+
+	located, err := MarkerLocator(fiducial.NewDetector(dictionary), 7, 80, camera, images)(context.Background())
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, located.Point().Sub(pose.Point()).Norm(), test.ShouldBeLessThan, 5)
+	test.That(t, spatialmath.PoseBetween(pose, located).Orientation().AxisAngles().Theta, test.ShouldBeLessThan, 0.02)
+
+	_, err = MarkerLocator(fiducial.NewDetector(dictionary), 8, 80, camera, images)(context.Background())
+	test.That(t, errors.Is(err, ErrTargetNotFound), test.ShouldBeTrue)
+}
@@ -0,0 +1,448 @@
+package calibration
+
+import (
+	"image"
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+	"go.viam.com/rdk/vision/fiducial"
+)
+
+const (
+	// minViews is the number of views of a checkerboard needed to estimate the intrinsic parameters of a camera.
+	minViews = 3
+	// numIntrinsics is the number of intrinsic parameters: the focal lengths, the principal point and the five
+	// parameters of the Brown-Conrady distortion model.
+	numIntrinsics = 9
+	// k3Index is the index of the third radial distortion coefficient in the parameters, which is kept at zero as
+	// it only matters for wide angle lenses, and fitting it to a few views of a board makes the other parameters
+	// worse.
+	k3Index       = 6
+	maxIterations = 100
+)
+
+// A Calibrator collects views of a checkerboard taken by a camera to estimate its intrinsic parameters.
+type Calibrator struct {
+	board  Checkerboard
+	bounds image.Rectangle
+	views  [][]r2.Point
+}
+
+// NewCalibrator returns a calibrator for views of the given board.
+func NewCalibrator(board Checkerboard) (*Calibrator, error) {
+	if err := board.Validate(); err != nil {
+		return nil, err
+	}
+	return &Calibrator{board: board}, nil
+}
+
+// AddImage finds the board in the image and adds its corners as a view. All the images must have the same size.
+// It returns ErrBoardNotFound when the whole board is not in the image.
+func (c *Calibrator) AddImage(img image.Image) error {
+	if len(c.views) > 0 && img.Bounds().Size() != c.bounds.Size() {
+		return errors.Errorf("image is %v but the previous ones were %v", img.Bounds().Size(), c.bounds.Size())
+	}
+	corners, err := FindCorners(img, c.board)
+	if err != nil {
+		return err
+	}
+	c.bounds = img.Bounds()
+	c.views = append(c.views, corners)
+	return nil
+}
+
+// Views returns the number of views of the board collected so far.
+func (c *Calibrator) Views() int {
+	return len(c.views)
+}
+
+// Calibrate estimates the intrinsic parameters of the camera from the views collected so far.
+func (c *Calibrator) Calibrate() (*Calibration, error) {
+	return CalibrateIntrinsics(c.board, c.bounds.Dx(), c.bounds.Dy(), c.views)
+}
+
+// Calibration is the result of the calibration of a camera.
+type Calibration struct {
+	Intrinsics *transform.PinholeCameraIntrinsics
+	Distortion *transform.BrownConrady
+	// RMSErrorPx is the root mean square distance in pixels between the corners of the boards in the views and
+	// their projections with the estimated parameters.
+	RMSErrorPx float64
+	// ViewErrorsPx are the root mean square reprojection errors of each view, which can point out bad views.
+	ViewErrorsPx []float64
+	// Poses are the poses of the board in the frame of the camera in each view.
+	Poses []spatialmath.Pose
+}
+
+// CameraAttributes are the attributes of camera configs holding the parameters of a camera.
+type CameraAttributes struct {
+	IntrinsicParams  *transform.PinholeCameraIntrinsics `json:"intrinsic_parameters"`
+	DistortionParams *transform.BrownConrady            `json:"distortion_parameters"`
+}
+
+// CameraAttributes returns the attributes to add to the config of the camera for it to use the calibration.
+func (c *Calibration) CameraAttributes() CameraAttributes {
+	return CameraAttributes{IntrinsicParams: c.Intrinsics, DistortionParams: c.Distortion}
+}
+
+// CalibrateIntrinsics estimates the intrinsic parameters and the Brown-Conrady distortion, without the third radial
+// coefficient, of a camera taking images of the given size from at least three views of a checkerboard, which are the
+// pixels of the corners of the board in the order of its Points. The board should be seen at different angles and
+// cover the whole image across the views.
+//
+// The parameters are first estimated without distortion with Zhang's closed form solution, then refined with the
+// poses of the board in all the views to minimize the distances between the corners and their projections.
+func CalibrateIntrinsics(board Checkerboard, width, height int, views [][]r2.Point) (*Calibration, error) {
+	if err := board.Validate(); err != nil {
+		return nil, err
+	}
+	if len(views) < minViews {
+		return nil, errors.Errorf("need at least %d views of the board, have %d", minViews, len(views))
+	}
+	points := board.Points()
+	planar := make([]r2.Point, len(points))
+	for i, p := range points {
+		planar[i] = r2.Point{X: p.X, Y: p.Y}
+	}
+	for i, view := range views {
+		if len(view) != len(points) {
+			return nil, errors.Errorf("view %d has %d corners but the board has %d", i, len(view), len(points))
+		}
+	}
+
+	homographies := make([]*mat.Dense, len(views))
+	for i, view := range views {
+		h, err := findHomography(planar, view)
+		if err != nil {
+			return nil, errors.Wrapf(err, "view %d", i)
+		}
+		homographies[i] = h
+	}
+	intrinsics, err := closedFormIntrinsics(homographies)
+	if err != nil {
+		return nil, err
+	}
+	intrinsics.Width, intrinsics.Height = width, height
+
+	camera := &fiducial.Camera{Intrinsics: intrinsics}
+	params := make([]float64, numIntrinsics+6*len(views))
+	params[0], params[1], params[2], params[3] = intrinsics.Fx, intrinsics.Fy, intrinsics.Ppx, intrinsics.Ppy
+	for i, view := range views {
+		pose, _, err := fiducial.EstimatePlanarPose(planar, view, camera)
+		if err != nil {
+			return nil, errors.Wrapf(err, "view %d", i)
+		}
+		rotation := pose.Orientation().AxisAngles().ToR3()
+		copy(params[numIntrinsics+6*i:], []float64{
+			rotation.X, rotation.Y, rotation.Z, pose.Point().X, pose.Point().Y, pose.Point().Z,
+		})
+	}
+
+	params = refine(params, points, views)
+	return newCalibration(params, points, views, width, height), nil
+}
+
+// newCalibration returns the calibration with the given parameters.
+func newCalibration(params []float64, points []r3.Vector, views [][]r2.Point, width, height int) *Calibration {
+	camera := cameraFromParams(params, width, height)
+	cal := &Calibration{
+		Intrinsics: camera.Intrinsics,
+		Distortion: camera.Distortion.(*transform.BrownConrady),
+	}
+	var total float64
+	for i, view := range views {
+		res := viewResiduals(camera, params[numIntrinsics+6*i:numIntrinsics+6*i+6], points, view)
+		var sum float64
+		for _, r := range res {
+			sum += r * r
+		}
+		total += sum
+		cal.ViewErrorsPx = append(cal.ViewErrorsPx, math.Sqrt(sum/float64(len(points))))
+		cal.Poses = append(cal.Poses, poseFromParams(params[numIntrinsics+6*i:]))
+	}
+	cal.RMSErrorPx = math.Sqrt(total / float64(len(points)*len(views)))
+	return cal
+}
+
+func cameraFromParams(params []float64, width, height int) *fiducial.Camera {
+	return &fiducial.Camera{
+		Intrinsics: &transform.PinholeCameraIntrinsics{
+			Width: width, Height: height, Fx: params[0], Fy: params[1], Ppx: params[2], Ppy: params[3],
+		},
+		Distortion: &transform.BrownConrady{
+			RadialK1: params[4], RadialK2: params[5], RadialK3: params[6], TangentialP1: params[7], TangentialP2: params[8],
+		},
+	}
+}
+
+// poseFromParams returns the pose with the rotation vector and translation at the start of the parameters.
+func poseFromParams(params []float64) spatialmath.Pose {
+	return spatialmath.NewPose(
+		r3.Vector{X: params[3], Y: params[4], Z: params[5]},
+		spatialmath.R3ToR4(r3.Vector{X: params[0], Y: params[1], Z: params[2]}),
+	)
+}
+
+// viewResiduals returns the differences between the projections of the points of the board at the pose given by
+// the parameters and the corners seen in the view.
+func viewResiduals(camera *fiducial.Camera, poseParams []float64, points []r3.Vector, view []r2.Point) []float64 {
+	// the rows of the rotation matrices of the spatialmath package are the images of the axes
+	rm := spatialmath.R3ToR4(r3.Vector{X: poseParams[0], Y: poseParams[1], Z: poseParams[2]}).RotationMatrix()
+	t := r3.Vector{X: poseParams[3], Y: poseParams[4], Z: poseParams[5]}
+	res := make([]float64, 0, 2*len(points))
+	for i, p := range points {
+		inCamera := rm.Row(0).Mul(p.X).Add(rm.Row(1).Mul(p.Y)).Add(rm.Row(2).Mul(p.Z)).Add(t)
+		proj := camera.Project(inCamera)
+		res = append(res, proj.X-view[i].X, proj.Y-view[i].Y)
+	}
+	return res
+}
+
+// refine minimizes the reprojection errors of all the views with the Levenberg-Marquardt algorithm. The parameters
+// are the intrinsic parameters followed by the rotation vector and translation of the board in each view.
+func refine(params []float64, points []r3.Vector, views [][]r2.Point) []float64 {
+	n := len(params)
+	rowsPerView := 2 * len(points)
+	residuals := func(params []float64) [][]float64 {
+		camera := cameraFromParams(params, 0, 0)
+		res := make([][]float64, len(views))
+		for i, view := range views {
+			res[i] = viewResiduals(camera, params[numIntrinsics+6*i:numIntrinsics+6*i+6], points, view)
+		}
+		return res
+	}
+	cost := func(res [][]float64) float64 {
+		var sum float64
+		for _, view := range res {
+			for _, r := range view {
+				sum += r * r
+			}
+		}
+		return sum
+	}
+	step := func(k int, value float64) float64 {
+		if k < 4 || k >= numIntrinsics+3 && (k-numIntrinsics)%6 >= 3 {
+			// focal lengths, principal points and translations are in pixels and millimeters
+			return 1e-6 * math.Max(1, math.Abs(value))
+		}
+		return 1e-7
+	}
+
+	res := residuals(params)
+	current := cost(res)
+	damping := 1e-3
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		// the residuals of each view only depend on the intrinsic parameters and the pose of the board in that view
+		intrinsicJacobian := make([][][]float64, numIntrinsics)
+		for k := 0; k < numIntrinsics; k++ {
+			intrinsicJacobian[k] = make([][]float64, len(views))
+			for v := range views {
+				intrinsicJacobian[k][v] = make([]float64, rowsPerView)
+			}
+			if k == k3Index {
+				continue
+			}
+			shifted := append([]float64{}, params...)
+			h := step(k, params[k])
+			shifted[k] += h
+			shiftedRes := residuals(shifted)
+			for v := range views {
+				for r := range res[v] {
+					intrinsicJacobian[k][v][r] = (shiftedRes[v][r] - res[v][r]) / h
+				}
+			}
+		}
+		camera := cameraFromParams(params, 0, 0)
+		poseJacobian := make([][][]float64, len(views))
+		for v, view := range views {
+			poseJacobian[v] = make([][]float64, 6)
+			for k := 0; k < 6; k++ {
+				poseParams := append([]float64{}, params[numIntrinsics+6*v:numIntrinsics+6*v+6]...)
+				h := step(numIntrinsics+6*v+k, poseParams[k])
+				poseParams[k] += h
+				shiftedRes := viewResiduals(camera, poseParams, points, view)
+				poseJacobian[v][k] = make([]float64, rowsPerView)
+				for r := range shiftedRes {
+					poseJacobian[v][k][r] = (shiftedRes[r] - res[v][r]) / h
+				}
+			}
+		}
+
+		jtj := mat.NewDense(n, n, nil)
+		jtr := make([]float64, n)
+		for v := range views {
+			cols := make([]int, 0, numIntrinsics+6)
+			columns := make([][]float64, 0, numIntrinsics+6)
+			for k := 0; k < numIntrinsics; k++ {
+				cols = append(cols, k)
+				columns = append(columns, intrinsicJacobian[k][v])
+			}
+			for k := 0; k < 6; k++ {
+				cols = append(cols, numIntrinsics+6*v+k)
+				columns = append(columns, poseJacobian[v][k])
+			}
+			for a, ca := range cols {
+				for b, cb := range cols {
+					var sum float64
+					for r := 0; r < rowsPerView; r++ {
+						sum += columns[a][r] * columns[b][r]
+					}
+					jtj.Set(ca, cb, jtj.At(ca, cb)+sum)
+				}
+				for r := 0; r < rowsPerView; r++ {
+					jtr[ca] -= columns[a][r] * res[v][r]
+				}
+			}
+		}
+
+		improved, converged := false, false
+		for attempt := 0; attempt < 10 && !improved; attempt++ {
+			a := mat.DenseCopyOf(jtj)
+			for k := 0; k < n; k++ {
+				a.Set(k, k, a.At(k, k)*(1+damping))
+			}
+			// the fixed parameter does not move
+			a.Set(k3Index, k3Index, 1)
+			var delta mat.VecDense
+			if err := delta.SolveVec(a, mat.NewVecDense(n, append([]float64{}, jtr...))); err != nil {
+				damping *= 10
+				continue
+			}
+			candidate := append([]float64{}, params...)
+			for k := range candidate {
+				candidate[k] += delta.AtVec(k)
+			}
+			candidateRes := residuals(candidate)
+			if candidateCost := cost(candidateRes); candidateCost < current {
+				converged = current-candidateCost < 1e-10*current
+				params, res, current = candidate, candidateRes, candidateCost
+				damping = math.Max(damping/10, 1e-9)
+				improved = true
+			} else {
+				damping *= 10
+			}
+		}
+		if !improved || converged {
+			break
+		}
+	}
+	return params
+}
+
+// closedFormIntrinsics estimates the focal lengths and principal point of a camera without distortion from the
+// homographies taking a plane to its images, as described in "A Flexible New Technique for Camera Calibration" by
+// Zhengyou Zhang. The camera is assumed to have no skew.
+func closedFormIntrinsics(homographies []*mat.Dense) (*transform.PinholeCameraIntrinsics, error) {
+	// each homography constrains the image of the absolute conic B = K^-T K^-1, whose six distinct entries are
+	// b = (B11, B12, B22, B13, B23, B33)
+	v := func(h *mat.Dense, i, j int) []float64 {
+		return []float64{
+			h.At(0, i) * h.At(0, j),
+			h.At(0, i)*h.At(1, j) + h.At(1, i)*h.At(0, j),
+			h.At(1, i) * h.At(1, j),
+			h.At(2, i)*h.At(0, j) + h.At(0, i)*h.At(2, j),
+			h.At(2, i)*h.At(1, j) + h.At(1, i)*h.At(2, j),
+			h.At(2, i) * h.At(2, j),
+		}
+	}
+	var rows []float64
+	for _, h := range homographies {
+		// the homographies are scaled so that the constraints of all views weigh the same
+		scaled := mat.DenseCopyOf(h)
+		scaled.Scale(1/mat.Norm(h, 2), scaled)
+		v11, v12, v22 := v(scaled, 0, 0), v(scaled, 0, 1), v(scaled, 1, 1)
+		rows = append(rows, v12...)
+		for k := range v11 {
+			rows = append(rows, v11[k]-v22[k])
+		}
+	}
+	// no skew
+	rows = append(rows, 0, 1, 0, 0, 0, 0)
+	system := mat.NewDense(len(rows)/6, 6, rows)
+
+	var svd mat.SVD
+	if !svd.Factorize(system, mat.SVDFull) {
+		return nil, errors.New("could not estimate intrinsic parameters")
+	}
+	var vecs mat.Dense
+	svd.VTo(&vecs)
+	b := mat.Col(nil, 5, &vecs)
+	b11, b12, b22, b13, b23, b33 := b[0], b[1], b[2], b[3], b[4], b[5]
+
+	denom := b11*b22 - b12*b12
+	if denom == 0 || b11 == 0 {
+		return nil, errors.New("views of the board are degenerate, take them at more varied angles")
+	}
+	v0 := (b12*b13 - b11*b23) / denom
+	lambda := b33 - (b13*b13+v0*(b12*b13-b11*b23))/b11
+	alpha2, beta2 := lambda/b11, lambda*b11/denom
+	if alpha2 <= 0 || beta2 <= 0 {
+		return nil, errors.New("views of the board are degenerate, take them at more varied angles")
+	}
+	alpha := math.Sqrt(alpha2)
+	u0 := -b13 * alpha2 / lambda
+	return &transform.PinholeCameraIntrinsics{Fx: alpha, Fy: math.Sqrt(beta2), Ppx: u0, Ppy: v0}, nil
+}
+
+// findHomography returns the homography taking the points from to the points to with the normalized direct linear
+// transformation.
+func findHomography(from, to []r2.Point) (*mat.Dense, error) {
+	fromNorm, fromT := normalization(from)
+	toNorm, toT := normalization(to)
+	rows := make([]float64, 0, 18*len(from))
+	for i := range fromNorm {
+		x, y, u, w := fromNorm[i].X, fromNorm[i].Y, toNorm[i].X, toNorm[i].Y
+		rows = append(rows,
+			x, y, 1, 0, 0, 0, -u*x, -u*y, -u,
+			0, 0, 0, x, y, 1, -w*x, -w*y, -w,
+		)
+	}
+	var svd mat.SVD
+	if !svd.Factorize(mat.NewDense(2*len(from), 9, rows), mat.SVDFull) {
+		return nil, errors.New("could not estimate homography")
+	}
+	var vecs mat.Dense
+	svd.VTo(&vecs)
+	h := mat.NewDense(3, 3, mat.Col(nil, 8, &vecs))
+
+	var toInv mat.Dense
+	if err := toInv.Inverse(toT); err != nil {
+		return nil, err
+	}
+	var result mat.Dense
+	result.Product(&toInv, h, fromT)
+	if result.At(2, 2) == 0 {
+		return nil, errors.New("could not estimate homography")
+	}
+	result.Scale(1/result.At(2, 2), &result)
+	return &result, nil
+}
+
+// normalization returns the points moved to have their mean at the origin and scaled to have a mean distance to
+// it of the square root of two, and the transformation that does it.
+func normalization(points []r2.Point) ([]r2.Point, *mat.Dense) {
+	var mean r2.Point
+	for _, p := range points {
+		mean = mean.Add(p)
+	}
+	mean = mean.Mul(1 / float64(len(points)))
+	var dist float64
+	for _, p := range points {
+		dist += p.Sub(mean).Norm()
+	}
+	scale := 1.
+	if dist > 0 {
+		scale = math.Sqrt2 * float64(len(points)) / dist
+	}
+	normalized := make([]r2.Point, len(points))
+	for i, p := range points {
+		normalized[i] = p.Sub(mean).Mul(scale)
+	}
+	return normalized, mat.NewDense(3, 3, []float64{scale, 0, -scale * mean.X, 0, scale, -scale * mean.Y, 0, 0, 1})
+}
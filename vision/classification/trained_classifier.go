@@ -0,0 +1,188 @@
+package classification
+
+import (
+	"context"
+	"encoding/json"
+	"image"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/nfnt/resize"
+	"github.com/pkg/errors"
+
+	"go.viam.com/rdk/ml"
+)
+
+const (
+	// embeddingThumbnailSize is the width and height of the grayscale thumbnail describing the shape of an image.
+	embeddingThumbnailSize = 12
+	// embeddingHueBins is the number of bins of the histogram describing the color of an image. One more bin
+	// counts the unsaturated pixels, whose hue is meaningless, so that a colored part on a gray background is
+	// described by its color only.
+	embeddingHueBins = 12
+	// embeddingMinSaturation is the saturation under which a pixel counts as unsaturated.
+	embeddingMinSaturation = 0.15
+	// embeddingMinContrast is the RMS contrast below which the thumbnail is not scaled up further, so that the
+	// noise of uniform images is not amplified into shapes.
+	embeddingMinContrast = 0.05
+)
+
+// EmbedImage returns a feature vector describing the appearance of an image, in which similar looking images
+// are close. It concatenates a coarse grayscale thumbnail normalized for brightness and contrast, describing
+// shape, a hue histogram of the saturated pixels, describing color, the share of unsaturated pixels and the
+// mean brightness. The first two parts have unit length, so they weigh the same, unless the image is nearly
+// uniform or colorless.
+func EmbedImage(img image.Image) []float64 {
+	small := resize.Resize(embeddingThumbnailSize, embeddingThumbnailSize, img, resize.Bilinear)
+	gray := make([]float64, 0, embeddingThumbnailSize*embeddingThumbnailSize)
+	hues := make([]float64, embeddingHueBins+1)
+	bounds := small.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c, ok := colorful.MakeColor(small.At(x, y))
+			if !ok {
+				// fully transparent
+				gray = append(gray, 0)
+				continue
+			}
+			h, s, v := c.Hsv()
+			gray = append(gray, 0.299*c.R+0.587*c.G+0.114*c.B)
+			if s*v < embeddingMinSaturation {
+				hues[embeddingHueBins]++
+			} else {
+				// split every pixel between the two nearest bins, so that slightly different hues, like those
+				// of a compressed image, do not fall in different bins
+				pos := h/360*embeddingHueBins - 0.5
+				lo := math.Floor(pos)
+				frac := pos - lo
+				hues[(int(lo)+embeddingHueBins)%embeddingHueBins] += 1 - frac
+				hues[(int(lo)+1)%embeddingHueBins] += frac
+			}
+		}
+	}
+
+	mean := 0.
+	for _, v := range gray {
+		mean += v
+	}
+	mean /= float64(len(gray))
+	for i := range gray {
+		gray[i] -= mean
+	}
+	unsaturated := hues[embeddingHueBins] / float64(len(gray))
+	features := normalize(gray, embeddingMinContrast*math.Sqrt(float64(len(gray))))
+	features = append(features, normalize(hues[:embeddingHueBins], 0)...)
+	return append(features, unsaturated, mean)
+}
+
+// normalize scales a vector to unit length, or divides it by minNorm if its length is less.
+func normalize(v []float64, minNorm float64) []float64 {
+	norm := 0.
+	for _, x := range v {
+		norm += x * x
+	}
+	norm = math.Max(math.Sqrt(norm), minNorm)
+	if norm == 0 {
+		return v
+	}
+	for i := range v {
+		v[i] /= norm
+	}
+	return v
+}
+
+// Example is a labeled image, described by its embedding.
+type Example struct {
+	Label    string    `json:"label"`
+	Features []float64 `json:"features"`
+}
+
+// NewExample returns an example from a labeled image.
+func NewExample(label string, img image.Image) Example {
+	return Example{Label: label, Features: EmbedImage(img)}
+}
+
+// TrainedClassifier classifies images by comparing their embeddings with those of labeled examples, using a
+// k nearest neighbors classifier. It can be saved to and loaded from a JSON file.
+type TrainedClassifier struct {
+	Labels []string          `json:"labels"`
+	Model  *ml.KNNClassifier `json:"model"`
+}
+
+// TrainClassifier trains a classifier on examples, considering the k nearest ones when classifying.
+func TrainClassifier(examples []Example, k int) (*TrainedClassifier, error) {
+	tc := &TrainedClassifier{Model: ml.NewKNNClassifier(k)}
+	labelIndices := map[string]int{}
+	data := make([][]float64, 0, len(examples))
+	correct := make([]int, 0, len(examples))
+	for _, e := range examples {
+		if e.Label == "" {
+			return nil, errors.New("examples must have a label")
+		}
+		i, ok := labelIndices[e.Label]
+		if !ok {
+			i = len(tc.Labels)
+			labelIndices[e.Label] = i
+			tc.Labels = append(tc.Labels, e.Label)
+		}
+		data = append(data, e.Features)
+		correct = append(correct, i)
+	}
+	if err := tc.Model.Train(data, correct); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// LoadTrainedClassifier loads a classifier saved with Save.
+func LoadTrainedClassifier(path string) (*TrainedClassifier, error) {
+	//nolint:gosec
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading trained classifier")
+	}
+	tc := &TrainedClassifier{}
+	if err := json.Unmarshal(data, tc); err != nil {
+		return nil, errors.Wrap(err, "error parsing trained classifier")
+	}
+	if tc.Model == nil || len(tc.Model.Data) == 0 {
+		return nil, errors.Errorf("trained classifier %s has no examples", path)
+	}
+	for _, c := range tc.Model.Correct {
+		if c < 0 || c >= len(tc.Labels) {
+			return nil, errors.Errorf("trained classifier %s has an example of unknown class %d", path, c)
+		}
+	}
+	return tc, nil
+}
+
+// Save writes the classifier to a JSON file.
+func (tc *TrainedClassifier) Save(path string) error {
+	data, err := json.Marshal(tc)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Classify returns a classification for every label, with the share of votes it got as score, sorted from
+// the highest score. It has the signature of a Classifier.
+func (tc *TrainedClassifier) Classify(ctx context.Context, img image.Image) (Classifications, error) {
+	return tc.ClassifyFeatures(EmbedImage(img))
+}
+
+// ClassifyFeatures is Classify for an image already embedded with EmbedImage.
+func (tc *TrainedClassifier) ClassifyFeatures(features []float64) (Classifications, error) {
+	scores, err := tc.Model.Scores(features)
+	if err != nil {
+		return nil, err
+	}
+	out := make(Classifications, 0, len(tc.Labels))
+	for i, label := range tc.Labels {
+		out = append(out, NewClassification(scores[i], label))
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score() > out[j].Score() })
+	return out, nil
+}
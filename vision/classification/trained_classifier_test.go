@@ -0,0 +1,78 @@
+package classification
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"go.viam.com/test"
+)
+
+// partImage draws a part of the given color and shape on a white background, offset by the given amount.
+func partImage(c color.Color, round bool, offset int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			dx, dy := x-32-offset, y-24+offset/2
+			inside := dx*dx+dy*dy < 14*14
+			if !round {
+				inside = dx > -12 && dx < 12 && dy > -12 && dy < 12
+			}
+			if inside {
+				img.Set(x, y, c)
+			} else {
+				img.Set(x, y, color.White)
+			}
+		}
+	}
+	return img
+}
+
+func TestTrainedClassifier(t *testing.T) {
+	red := color.RGBA{200, 20, 20, 255}
+	blue := color.RGBA{20, 40, 200, 255}
+	var examples []Example
+	for _, offset := range []int{-4, 0, 4} {
+		examples = append(examples,
+			NewExample("red_disc", partImage(red, true, offset)),
+			NewExample("blue_square", partImage(blue, false, offset)),
+			NewExample("red_square", partImage(red, false, offset)),
+		)
+	}
+	tc, err := TrainClassifier(examples, 3)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, tc.Labels, test.ShouldResemble, []string{"red_disc", "blue_square", "red_square"})
+
+	for _, tt := range []struct {
+		img   image.Image
+		label string
+	}{
+		{partImage(red, true, 2), "red_disc"},
+		{partImage(blue, false, -2), "blue_square"},
+		{partImage(red, false, 2), "red_square"},
+	} {
+		classifications, err := tc.Classify(context.Background(), tt.img)
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, len(classifications), test.ShouldEqual, 3)
+		test.That(t, classifications[0].Label(), test.ShouldEqual, tt.label)
+		test.That(t, classifications[0].Score(), test.ShouldBeGreaterThan, classifications[1].Score())
+		fromFeatures, err := tc.ClassifyFeatures(EmbedImage(tt.img))
+		test.That(t, err, test.ShouldBeNil)
+		test.That(t, fromFeatures, test.ShouldResemble, classifications)
+	}
+
+	path := filepath.Join(t.TempDir(), "classifier.json")
+	test.That(t, tc.Save(path), test.ShouldBeNil)
+	loaded, err := LoadTrainedClassifier(path)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, loaded, test.ShouldResemble, tc)
+
+	_, err = TrainClassifier(nil, 3)
+	test.That(t, err, test.ShouldBeError, "no data")
+	_, err = TrainClassifier([]Example{{Features: []float64{1}}}, 3)
+	test.That(t, err, test.ShouldBeError, "examples must have a label")
+	_, err = LoadTrainedClassifier(filepath.Join(t.TempDir(), "missing.json"))
+	test.That(t, err.Error(), test.ShouldContainSubstring, "error reading trained classifier")
+}
@@ -0,0 +1,426 @@
+package fiducial
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/golang/geo/r2"
+)
+
+const (
+	defaultMinSidePx = 10.
+	// thresholdOffset is how much darker than its neighborhood a pixel must be to be part of a marker's border.
+	thresholdOffset = 7.
+	// minContrast is the smallest difference between the black and white cells of a marker.
+	minContrast = 20.
+	// minQuadFill is the smallest fraction of the convex hull of a dark region its quadrilateral must cover.
+	minQuadFill = 0.85
+)
+
+// thresholdRadii are the half sizes of the neighborhoods pixels are compared with to find dark regions. Several
+// are used so that the borders of markers of every size are found whole.
+var thresholdRadii = []int{4, 12, 36, 108}
+
+// Detection is a marker found in an image.
+type Detection struct {
+	ID int
+	// Corners are the top left, top right, bottom right and bottom left corners of the marker, in pixels whose
+	// centers are at integer coordinates. They go clockwise in the image when the marker faces the camera.
+	Corners [4]r2.Point
+	// BitErrors is the number of data cells that were misread, and corrected to identify the marker.
+	BitErrors int
+}
+
+// Center returns the center of the marker in the image.
+func (det *Detection) Center() r2.Point {
+	p, ok := lineIntersection(det.Corners[0], det.Corners[2], det.Corners[1], det.Corners[3])
+	if !ok {
+		return det.Corners[0].Add(det.Corners[2]).Mul(0.5)
+	}
+	return p
+}
+
+// A Detector finds the markers of a dictionary in images.
+type Detector struct {
+	Dictionary *Dictionary
+	// MaxBitErrors is the number of data cells of a marker that may be misread for it to still be identified.
+	MaxBitErrors int
+	// MinSidePx is the length of the shortest side of detected markers, 10 pixels if zero.
+	MinSidePx float64
+}
+
+// NewDetector returns a detector of the markers of the given dictionary.
+func NewDetector(dictionary *Dictionary) *Detector {
+	return &Detector{Dictionary: dictionary}
+}
+
+// Detect finds the markers in the image, ordered by ID.
+func (d *Detector) Detect(img image.Image) []Detection {
+	gray := newGrayImage(img)
+	minSide := d.MinSidePx
+	if minSide <= 0 {
+		minSide = defaultMinSidePx
+	}
+
+	var detections []Detection
+	for _, radius := range thresholdRadii {
+		if radius > 2*maxInt(gray.w, gray.h) {
+			break
+		}
+		for _, quad := range findQuads(gray, radius, minSide) {
+			det, ok := d.decode(gray, d.refineQuad(gray, quad))
+			if !ok || isDuplicate(detections, det) {
+				continue
+			}
+			detections = append(detections, det)
+		}
+	}
+	sort.SliceStable(detections, func(i, j int) bool { return detections[i].ID < detections[j].ID })
+	return detections
+}
+
+// isDuplicate returns whether the marker was already found in the image, at another threshold.
+func isDuplicate(detections []Detection, det Detection) bool {
+	center := det.Center()
+	for i := range detections {
+		if detections[i].ID != det.ID {
+			continue
+		}
+		if detections[i].Center().Sub(center).Norm() < det.Corners[0].Sub(det.Corners[1]).Norm()/2 {
+			return true
+		}
+	}
+	return false
+}
+
+// decode reads the cells of the marker inside the quadrilateral, and identifies it.
+func (d *Detector) decode(gray *grayImage, quad [4]r2.Point) (Detection, bool) {
+	n := d.Dictionary.Bits + 2
+	h, ok := unitSquareHomography(quad)
+	if !ok {
+		return Detection{}, false
+	}
+
+	cells := make([]float64, n*n)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var sum float64
+			for _, offset := range [][2]float64{{0, 0}, {-0.25, -0.25}, {0.25, -0.25}, {0.25, 0.25}, {-0.25, 0.25}} {
+				p := h.apply((float64(x)+0.5+offset[0])/float64(n), (float64(y)+0.5+offset[1])/float64(n))
+				sum += gray.bilinear(p.X, p.Y)
+			}
+			cells[y*n+x] = sum / 5
+		}
+	}
+
+	isBorder := func(x, y int) bool { return x == 0 || y == 0 || x == n-1 || y == n-1 }
+	var black, white float64
+	var borderCells int
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			if isBorder(x, y) {
+				black += cells[y*n+x]
+				borderCells++
+			} else {
+				white = math.Max(white, cells[y*n+x])
+			}
+		}
+	}
+	black /= float64(borderCells)
+	if white-black < minContrast {
+		return Detection{}, false
+	}
+	threshold := (black + white) / 2
+
+	var code uint64
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			dark := cells[y*n+x] < threshold
+			if isBorder(x, y) {
+				if !dark {
+					return Detection{}, false
+				}
+				continue
+			}
+			code <<= 1
+			if !dark {
+				code |= 1
+			}
+		}
+	}
+
+	id, rotations, errs, ok := d.Dictionary.match(code, d.MaxBitErrors)
+	if !ok {
+		return Detection{}, false
+	}
+	det := Detection{ID: id, BitErrors: errs}
+	// rotating the cells clockwise moves the corner that was at the bottom left to the top left
+	for i := range det.Corners {
+		det.Corners[i] = quad[(i-rotations+4)%4]
+	}
+	return det, true
+}
+
+// refineQuad moves the sides of the quadrilateral to the edges between the black border of the marker and the
+// white margin around it, found with subpixel accuracy, and returns the intersections of the sides.
+func (d *Detector) refineQuad(gray *grayImage, quad [4]r2.Point) [4]r2.Point {
+	n := float64(d.Dictionary.Bits + 2)
+	for iteration := 0; iteration < 2; iteration++ {
+		var lines [4][2]r2.Point
+		for k := 0; k < 4; k++ {
+			a, b := quad[k], quad[(k+1)%4]
+			length := b.Sub(a).Norm()
+			dir := b.Sub(a).Mul(1 / length)
+			// the corners go clockwise in the image, whose y axis points down, so the outside is on the left
+			normal := r2.Point{X: dir.Y, Y: -dir.X}
+			searchPx := math.Max(1.5, math.Min(4, 0.45*length/n))
+
+			var points []r2.Point
+			samples := int(math.Max(8, length/2))
+			for i := 0; i <= samples; i++ {
+				p := a.Add(b.Sub(a).Mul(0.15 + 0.7*float64(i)/float64(samples)))
+				if s, ok := edgeOffset(gray, p, normal, searchPx); ok {
+					points = append(points, p.Add(normal.Mul(s)))
+				}
+			}
+			lines[k] = [2]r2.Point{a, b}
+			if len(points) >= 3 {
+				lines[k] = fitLine(points)
+			}
+		}
+
+		refined := quad
+		for k := 0; k < 4; k++ {
+			prev := lines[(k+3)%4]
+			p, ok := lineIntersection(prev[0], prev[1], lines[k][0], lines[k][1])
+			if ok && p.Sub(quad[k]).Norm() < 4 {
+				refined[k] = p
+			}
+		}
+		quad = refined
+	}
+	return quad
+}
+
+// edgeOffset finds where the intensity crosses halfway between the dark inside and the bright outside along the
+// normal through p, within searchPx of p.
+func edgeOffset(gray *grayImage, p, normal r2.Point, searchPx float64) (float64, bool) {
+	const step = 0.25
+	at := func(s float64) float64 {
+		q := p.Add(normal.Mul(s))
+		return gray.bilinear(q.X, q.Y)
+	}
+	dark, bright := at(-searchPx), at(searchPx)
+	if bright-dark < minContrast {
+		return 0, false
+	}
+	mid := (dark + bright) / 2
+	prev := dark
+	for s := -searchPx + step; s <= searchPx+1e-9; s += step {
+		v := at(s)
+		if v >= mid {
+			return s - step + step*(mid-prev)/(v-prev), true
+		}
+		prev = v
+	}
+	return 0, false
+}
+
+// fitLine returns two points of the line closest to the points, in the total least squares sense.
+func fitLine(points []r2.Point) [2]r2.Point {
+	var mean r2.Point
+	for _, p := range points {
+		mean = mean.Add(p)
+	}
+	mean = mean.Mul(1 / float64(len(points)))
+	var sxx, sxy, syy float64
+	for _, p := range points {
+		d := p.Sub(mean)
+		sxx += d.X * d.X
+		sxy += d.X * d.Y
+		syy += d.Y * d.Y
+	}
+	// the direction of the line is the eigenvector of the scatter matrix with the largest eigenvalue
+	angle := 0.5 * math.Atan2(2*sxy, sxx-syy)
+	return [2]r2.Point{mean, mean.Add(r2.Point{X: math.Cos(angle), Y: math.Sin(angle)})}
+}
+
+// lineIntersection returns the intersection of the line through a0 and a1 with the line through b0 and b1.
+func lineIntersection(a0, a1, b0, b1 r2.Point) (r2.Point, bool) {
+	da, db := a1.Sub(a0), b1.Sub(b0)
+	denom := da.Cross(db)
+	if math.Abs(denom) < 1e-12 {
+		return r2.Point{}, false
+	}
+	t := b0.Sub(a0).Cross(db) / denom
+	return a0.Add(da.Mul(t)), true
+}
+
+// findQuads finds dark regions darker than their neighborhoods of the given radius, and returns the quadrilaterals
+// that fit them, with their corners clockwise in the image.
+func findQuads(gray *grayImage, radius int, minSide float64) [][4]r2.Point {
+	w, h := gray.w, gray.h
+	integral := gray.integral()
+	dark := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		y0, y1 := maxInt(0, y-radius), minInt(h-1, y+radius)
+		for x := 0; x < w; x++ {
+			x0, x1 := maxInt(0, x-radius), minInt(w-1, x+radius)
+			sum := integral[(y1+1)*(w+1)+x1+1] - integral[y0*(w+1)+x1+1] - integral[(y1+1)*(w+1)+x0] + integral[y0*(w+1)+x0]
+			mean := sum / float64((x1-x0+1)*(y1-y0+1))
+			dark[y*w+x] = gray.pix[y*w+x] < mean-thresholdOffset
+		}
+	}
+
+	var quads [][4]r2.Point
+	labels := make([]int32, w*h)
+	var label int32
+	var stack []int
+	for start := range dark {
+		if !dark[start] || labels[start] != 0 {
+			continue
+		}
+		label++
+		labels[start] = label
+		stack = append(stack[:0], start)
+		var pixels []int
+		touchesEdge := false
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			pixels = append(pixels, i)
+			x, y := i%w, i/w
+			if x == 0 || y == 0 || x == w-1 || y == h-1 {
+				touchesEdge = true
+			}
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := x+dx, y+dy
+					if nx < 0 || ny < 0 || nx >= w || ny >= h {
+						continue
+					}
+					if j := ny*w + nx; dark[j] && labels[j] == 0 {
+						labels[j] = label
+						stack = append(stack, j)
+					}
+				}
+			}
+		}
+		// markers need a white margin, so regions touching the edges of the image are not markers
+		if touchesEdge || float64(len(pixels)) < 2*minSide {
+			continue
+		}
+
+		var boundary []r2.Point
+		for _, i := range pixels {
+			x, y := i%w, i/w
+			if labels[i-1] != label || labels[i+1] != label || labels[i-w] != label || labels[i+w] != label {
+				boundary = append(boundary, r2.Point{X: float64(x), Y: float64(y)})
+			}
+		}
+		if quad, ok := fitQuad(convexHull(boundary), minSide); ok {
+			quads = append(quads, quad)
+		}
+	}
+	return quads
+}
+
+// fitQuad returns the quadrilateral with corners on the convex hull that covers most of it.
+func fitQuad(hull []r2.Point, minSide float64) ([4]r2.Point, bool) {
+	var quad [4]r2.Point
+	if len(hull) < 4 {
+		return quad, false
+	}
+	var centroid r2.Point
+	for _, p := range hull {
+		centroid = centroid.Add(p)
+	}
+	centroid = centroid.Mul(1 / float64(len(hull)))
+
+	farthest := func(from func(p r2.Point) float64) int {
+		best := 0
+		for i, p := range hull {
+			if from(p) > from(hull[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	i0 := farthest(func(p r2.Point) float64 { return p.Sub(centroid).Norm() })
+	i2 := farthest(func(p r2.Point) float64 { return p.Sub(hull[i0]).Norm() })
+	diagonal := hull[i2].Sub(hull[i0])
+	side := func(p r2.Point) float64 { return diagonal.Cross(p.Sub(hull[i0])) }
+	i1 := farthest(side)
+	i3 := farthest(func(p r2.Point) float64 { return -side(p) })
+	quad = [4]r2.Point{hull[i0], hull[i1], hull[i2], hull[i3]}
+
+	for k := 0; k < 4; k++ {
+		if quad[k].Sub(quad[(k+1)%4]).Norm() < minSide {
+			return quad, false
+		}
+	}
+	area := polygonArea(quad[:])
+	if area < 0 {
+		quad[1], quad[3] = quad[3], quad[1]
+		area = -area
+	}
+	if area < minQuadFill*math.Abs(polygonArea(hull)) {
+		return quad, false
+	}
+	return quad, true
+}
+
+// polygonArea returns the area of the polygon, positive when its vertices go clockwise in an image, whose y axis
+// points down.
+func polygonArea(polygon []r2.Point) float64 {
+	var area float64
+	for i, p := range polygon {
+		area += p.Cross(polygon[(i+1)%len(polygon)])
+	}
+	return area / 2
+}
+
+// convexHull returns the convex hull of the points, with Andrew's monotone chain algorithm.
+func convexHull(points []r2.Point) []r2.Point {
+	if len(points) < 3 {
+		return points
+	}
+	sorted := append([]r2.Point{}, points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+	hull := make([]r2.Point, 0, 2*len(sorted))
+	for pass := 0; pass < 2; pass++ {
+		start := len(hull)
+		for _, p := range sorted {
+			for len(hull) >= start+2 && hull[len(hull)-1].Sub(hull[len(hull)-2]).Cross(p.Sub(hull[len(hull)-2])) <= 0 {
+				hull = hull[:len(hull)-1]
+			}
+			hull = append(hull, p)
+		}
+		// the last point of each chain is the first of the other one
+		hull = hull[:len(hull)-1]
+		for i, j := 0, len(sorted)-1; i < j; i, j = i+1, j-1 {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		}
+	}
+	return hull
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
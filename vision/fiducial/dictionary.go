@@ -0,0 +1,134 @@
+// Package fiducial detects ArUco-style square fiducial markers, whose data cells are surrounded by a black border
+// one cell wide, in images and estimates their poses relative to the camera that took them.
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// maxMarkerBits is the largest number of data cells along the side of a marker whose codes fit in a uint64.
+const maxMarkerBits = 8
+
+// A Dictionary is a family of markers. Every marker is a square grid of data cells surrounded by a black border one
+// cell wide, which must itself be surrounded by a white margin for the marker to be detected.
+type Dictionary struct {
+	Name string
+	// Bits is the number of data cells along each side of a marker.
+	Bits int
+	// Codes holds the data cells of each marker, row by row from the top left one, which is the most significant
+	// bit, with white cells as ones. The index of a code is the ID of its marker.
+	Codes []uint64
+
+	ids map[uint64]int
+}
+
+// NewDictionary returns a dictionary of markers with the given number of data cells along their sides and codes.
+func NewDictionary(name string, markerBits int, codes []uint64) (*Dictionary, error) {
+	if markerBits < 2 || markerBits > maxMarkerBits {
+		return nil, errors.Errorf("markers must have between 2 and %d bits along their sides, not %d", maxMarkerBits, markerBits)
+	}
+	if len(codes) == 0 {
+		return nil, errors.New("a dictionary needs at least one code")
+	}
+	d := &Dictionary{Name: name, Bits: markerBits, Codes: codes, ids: make(map[uint64]int, len(codes))}
+	for id, code := range codes {
+		if code>>(markerBits*markerBits) != 0 {
+			return nil, errors.Errorf("code %#x of marker %d has more than %d bits", code, id, markerBits*markerBits)
+		}
+		if other, ok := d.ids[code]; ok {
+			return nil, errors.Errorf("markers %d and %d have the same code %#x", other, id, code)
+		}
+		d.ids[code] = id
+	}
+	return d, nil
+}
+
+// arucoOriginalWords are the rows of the markers of the original ArUco dictionary, each encoding two bits of an ID
+// in its second and fourth cells.
+var arucoOriginalWords = [4]uint64{0b10000, 0b10111, 0b01001, 0b01110}
+
+// ArucoOriginal returns the dictionary of the 1024 markers of the original ArUco library, which have 5 by 5 data
+// cells.
+func ArucoOriginal() *Dictionary {
+	codes := make([]uint64, 1024)
+	for id := range codes {
+		var code uint64
+		for row := 0; row < 5; row++ {
+			code = code<<5 | arucoOriginalWords[(id>>(2*(4-row)))&3]
+		}
+		codes[id] = code
+	}
+	d, err := NewDictionary("aruco_original", 5, codes)
+	if err != nil {
+		// the dictionary is generated, so this cannot happen
+		panic(err)
+	}
+	return d
+}
+
+// rotateCode rotates the cells of a code a quarter turn clockwise.
+func rotateCode(code uint64, n int) uint64 {
+	var rotated uint64
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			// the new cell at (x, y) is the old one at (y, n-1-x)
+			bit := (code >> (n*n - 1 - ((n-1-x)*n + y))) & 1
+			rotated |= bit << (n*n - 1 - (y*n + x))
+		}
+	}
+	return rotated
+}
+
+// match finds the marker whose code is closest to the given one, under any rotation, with at most maxErrors cells
+// differing. It returns the number of quarter turns clockwise the code had to be rotated by to match.
+func (d *Dictionary) match(code uint64, maxErrors int) (id, rotations, errs int, ok bool) {
+	rotated := [4]uint64{code}
+	for r := 1; r < 4; r++ {
+		rotated[r] = rotateCode(rotated[r-1], d.Bits)
+	}
+	for r, c := range rotated {
+		if id, ok := d.ids[c]; ok {
+			return id, r, 0, true
+		}
+	}
+	if maxErrors == 0 {
+		return 0, 0, 0, false
+	}
+
+	errs = maxErrors + 1
+	for candidate, c := range d.Codes {
+		for r, rc := range rotated {
+			if e := bits.OnesCount64(c ^ rc); e < errs {
+				id, rotations, errs = candidate, r, e
+			}
+		}
+	}
+	return id, rotations, errs, errs <= maxErrors
+}
+
+// MarkerImage returns an image of the marker with the given ID, with cells of the given size in pixels and without
+// the white margin it needs around it to be detected.
+func (d *Dictionary) MarkerImage(id, cellPx int) (*image.Gray, error) {
+	if id < 0 || id >= len(d.Codes) {
+		return nil, errors.Errorf("no marker %d in dictionary %q of %d markers", id, d.Name, len(d.Codes))
+	}
+	n := d.Bits + 2
+	img := image.NewGray(image.Rect(0, 0, n*cellPx, n*cellPx))
+	code := d.Codes[id]
+	for y := 0; y < n*cellPx; y++ {
+		for x := 0; x < n*cellPx; x++ {
+			cellX, cellY := x/cellPx-1, y/cellPx-1
+			if cellX < 0 || cellY < 0 || cellX >= d.Bits || cellY >= d.Bits {
+				continue
+			}
+			if (code>>(d.Bits*d.Bits-1-(cellY*d.Bits+cellX)))&1 == 1 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,179 @@
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"go.viam.com/test"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+func testCamera(distortion transform.Distorter) *Camera {
+	return &Camera{
+		Intrinsics: &transform.PinholeCameraIntrinsics{Width: 640, Height: 480, Fx: 600, Fy: 600, Ppx: 320, Ppy: 240},
+		Distortion: distortion,
+	}
+}
+
+type testMarker struct {
+	id   int
+	pose spatialmath.Pose
+}
+
+// renderMarkers returns the image the camera sees of the markers, which are 100mm wide, in front of a white wall.
+func renderMarkers(t *testing.T, d *Dictionary, camera *Camera, markers []testMarker) *image.Gray {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, camera.Intrinsics.Width, camera.Intrinsics.Height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.Gray{Y: 230}), image.Point{}, draw.Src)
+	for _, m := range markers {
+		marker, err := d.MarkerImage(m.id, 10)
+		test.That(t, err, test.ShouldBeNil)
+		DrawMarker(img, marker, 100, m.pose, camera)
+	}
+	return img
+}
+
+func TestArucoOriginal(t *testing.T) {
+	d := ArucoOriginal()
+	test.That(t, d.Bits, test.ShouldEqual, 5)
+	test.That(t, len(d.Codes), test.ShouldEqual, 1024)
+	// the first row of marker 0 is a white cell followed by four black ones
+	test.That(t, d.Codes[0]>>20, test.ShouldEqual, 0b10000)
+	test.That(t, d.Codes[1023], test.ShouldEqual, uint64(0b01110_01110_01110_01110_01110))
+
+	code := d.Codes[123]
+	rotated := code
+	for i := 0; i < 4; i++ {
+		rotated = rotateCode(rotated, d.Bits)
+	}
+	test.That(t, rotated, test.ShouldEqual, code)
+
+	id, rotations, errs, ok := d.match(rotateCode(code, d.Bits), 0)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, id, test.ShouldEqual, 123)
+	test.That(t, rotations, test.ShouldEqual, 3)
+	test.That(t, errs, test.ShouldEqual, 0)
+
+	flipped := code ^ 1<<7
+	_, _, _, ok = d.match(flipped, 0)
+	test.That(t, ok, test.ShouldBeFalse)
+	id, _, errs, ok = d.match(flipped, 1)
+	test.That(t, ok, test.ShouldBeTrue)
+	test.That(t, id, test.ShouldEqual, 123)
+	test.That(t, errs, test.ShouldEqual, 1)
+
+	_, err := d.MarkerImage(1024, 10)
+	test.That(t, err, test.ShouldNotBeNil)
+	img, err := d.MarkerImage(0, 10)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, img.Bounds(), test.ShouldResemble, image.Rect(0, 0, 70, 70))
+	test.That(t, img.GrayAt(5, 5).Y, test.ShouldEqual, 0)
+	test.That(t, img.GrayAt(15, 15).Y, test.ShouldEqual, 255)
+	test.That(t, img.GrayAt(25, 15).Y, test.ShouldEqual, 0)
+}
+
+func TestNewDictionary(t *testing.T) {
+	_, err := NewDictionary("tiny", 1, []uint64{1})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewDictionary("wide", 4, []uint64{1 << 16})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewDictionary("same", 4, []uint64{0x1234, 0x1234})
+	test.That(t, err, test.ShouldNotBeNil)
+	_, err = NewDictionary("empty", 4, nil)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	d, err := NewDictionary("custom", 4, []uint64{0x231b, 0x2ea5})
+	test.That(t, err, test.ShouldBeNil)
+	camera := testCamera(nil)
+	pose := spatialmath.NewPose(r3.Vector{Y: -20, Z: 600}, &spatialmath.R4AA{Theta: math.Pi, RX: 1})
+	detections := NewDetector(d).Detect(renderMarkers(t, d, camera, []testMarker{{1, pose}}))
+	test.That(t, len(detections), test.ShouldEqual, 1)
+	test.That(t, detections[0].ID, test.ShouldEqual, 1)
+}
+
+func TestDetectAndEstimatePose(t *testing.T) {
+	d := ArucoOriginal()
+	for _, tc := range []struct {
+		name       string
+		distortion transform.Distorter
+	}{
+		{"without distortion", nil},
+		{"with distortion", &transform.BrownConrady{RadialK1: -0.2, RadialK2: 0.05, TangentialP1: 0.001}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			camera := testCamera(tc.distortion)
+			// markers facing the camera have their +Z axis pointing back at it, so they are turned around the X axis
+			facing := &spatialmath.R4AA{Theta: math.Pi, RX: 1}
+			markers := []testMarker{
+				{7, spatialmath.NewPose(r3.Vector{X: -150, Y: -50, Z: 700}, facing)},
+				{300, spatialmath.Compose(
+					spatialmath.NewPose(r3.Vector{X: 120, Y: 60, Z: 600}, facing),
+					spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: 0.5, RX: 0.3, RY: 1, RZ: 0.2}),
+				)},
+				{1000, spatialmath.Compose(
+					spatialmath.NewPose(r3.Vector{X: 0, Y: 150, Z: 900}, facing),
+					spatialmath.NewPoseFromOrientation(&spatialmath.R4AA{Theta: 2, RZ: 1}),
+				)},
+			}
+			img := renderMarkers(t, d, camera, markers)
+
+			detections := NewDetector(d).Detect(img)
+			test.That(t, len(detections), test.ShouldEqual, len(markers))
+			for i, det := range detections {
+				m := markers[i]
+				test.That(t, det.ID, test.ShouldEqual, m.id)
+				test.That(t, det.BitErrors, test.ShouldEqual, 0)
+				for k, corner := range markerCorners(100) {
+					expected := camera.Project(spatialmath.Compose(m.pose, spatialmath.NewPoseFromPoint(r3.Vector{X: corner.X, Y: corner.Y})).Point())
+					test.That(t, det.Corners[k].Sub(expected).Norm(), test.ShouldBeLessThan, 0.5)
+				}
+
+				pose, rms, err := EstimatePose(det.Corners, 100, camera)
+				test.That(t, err, test.ShouldBeNil)
+				test.That(t, rms, test.ShouldBeLessThan, 0.5)
+				test.That(t, pose.Point().Sub(m.pose.Point()).Norm(), test.ShouldBeLessThan, 5)
+				delta := spatialmath.PoseBetween(m.pose, pose).Orientation().AxisAngles()
+				test.That(t, delta.Theta, test.ShouldBeLessThan, 0.05)
+			}
+		})
+	}
+}
+
+func TestDetectNothing(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 100, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 100; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8((x * y) % 256)})
+		}
+	}
+	test.That(t, NewDetector(ArucoOriginal()).Detect(img), test.ShouldBeEmpty)
+}
+
+func TestEstimatePlanarPose(t *testing.T) {
+	camera := testCamera(nil)
+	_, _, err := EstimatePose([4]r2.Point{}, 0, camera)
+	test.That(t, err, test.ShouldNotBeNil)
+	_, _, err = EstimatePlanarPose(make([]r2.Point, 3), make([]r2.Point, 3), camera)
+	test.That(t, err, test.ShouldNotBeNil)
+
+	pose := spatialmath.NewPose(r3.Vector{X: 30, Y: -20, Z: 800}, &spatialmath.R4AA{Theta: 2.8, RX: 1, RY: 0.1})
+	var points, pixels []r2.Point
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 5; x++ {
+			p := r2.Point{X: float64(x) * 30, Y: float64(y) * 30}
+			points = append(points, p)
+			pixels = append(pixels, camera.Project(spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(r3.Vector{X: p.X, Y: p.Y})).Point()))
+		}
+	}
+	estimated, rms, err := EstimatePlanarPose(points, pixels, camera)
+	test.That(t, err, test.ShouldBeNil)
+	test.That(t, rms, test.ShouldBeLessThan, 1e-6)
+	test.That(t, spatialmath.PoseAlmostEqualEps(estimated, pose, 1e-3), test.ShouldBeTrue)
+}
@@ -0,0 +1,201 @@
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/golang/geo/r2"
+)
+
+// grayImage holds the intensities of the pixels of an image, whose centers are at integer coordinates.
+type grayImage struct {
+	w, h int
+	pix  []float64
+}
+
+func newGrayImage(img image.Image) *grayImage {
+	bounds := img.Bounds()
+	g := &grayImage{w: bounds.Dx(), h: bounds.Dy(), pix: make([]float64, bounds.Dx()*bounds.Dy())}
+	if gray, ok := img.(*image.Gray); ok {
+		for y := 0; y < g.h; y++ {
+			row := gray.Pix[y*gray.Stride : y*gray.Stride+g.w]
+			for x, v := range row {
+				g.pix[y*g.w+x] = float64(v)
+			}
+		}
+		return g
+	}
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			c, _ := color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+			g.pix[y*g.w+x] = float64(c.Y)
+		}
+	}
+	return g
+}
+
+// bilinear returns the intensity at the given point, interpolated between the four pixels around it.
+func (g *grayImage) bilinear(x, y float64) float64 {
+	x = math.Max(0, math.Min(float64(g.w-1), x))
+	y = math.Max(0, math.Min(float64(g.h-1), y))
+	x0, y0 := int(x), int(y)
+	x1, y1 := minInt(x0+1, g.w-1), minInt(y0+1, g.h-1)
+	fx, fy := x-float64(x0), y-float64(y0)
+	top := g.pix[y0*g.w+x0]*(1-fx) + g.pix[y0*g.w+x1]*fx
+	bottom := g.pix[y1*g.w+x0]*(1-fx) + g.pix[y1*g.w+x1]*fx
+	return top*(1-fy) + bottom*fy
+}
+
+// integral returns the sums of the intensities of the pixels above and to the left of each pixel, with an extra
+// row and column of zeros first.
+func (g *grayImage) integral() []float64 {
+	sums := make([]float64, (g.w+1)*(g.h+1))
+	for y := 0; y < g.h; y++ {
+		var row float64
+		for x := 0; x < g.w; x++ {
+			row += g.pix[y*g.w+x]
+			sums[(y+1)*(g.w+1)+x+1] = sums[y*(g.w+1)+x+1] + row
+		}
+	}
+	return sums
+}
+
+// homography is a 3 by 3 projective transformation of the plane, row by row.
+type homography [9]float64
+
+func (h *homography) apply(x, y float64) r2.Point {
+	w := h[6]*x + h[7]*y + h[8]
+	return r2.Point{X: (h[0]*x + h[1]*y + h[2]) / w, Y: (h[3]*x + h[4]*y + h[5]) / w}
+}
+
+// unitSquareHomography returns the homography taking the corners (0, 0), (1, 0), (1, 1) and (0, 1) of the unit
+// square to the corners of the quadrilateral.
+func unitSquareHomography(quad [4]r2.Point) (*homography, bool) {
+	return findHomography(
+		[]r2.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 1}},
+		quad[:],
+	)
+}
+
+// findHomography returns the homography that best takes the points from to the points to, which must be at least
+// four, with the direct linear transformation. The points are normalized first for the solution to be stable.
+func findHomography(from, to []r2.Point) (*homography, bool) {
+	fromNorm, fromScale, fromMean := normalizePoints(from)
+	toNorm, toScale, toMean := normalizePoints(to)
+	h, ok := solveHomography(fromNorm, toNorm)
+	if !ok {
+		return nil, false
+	}
+	// undo the normalizations, taking from to its normalized points before h and normalized points back to to
+	// after it
+	var denorm homography
+	for c := 0; c < 3; c++ {
+		denorm[c] = h[c]/toScale + toMean.X*h[6+c]
+		denorm[3+c] = h[3+c]/toScale + toMean.Y*h[6+c]
+		denorm[6+c] = h[6+c]
+	}
+	var result homography
+	for r := 0; r < 3; r++ {
+		result[r*3] = denorm[r*3] * fromScale
+		result[r*3+1] = denorm[r*3+1] * fromScale
+		result[r*3+2] = denorm[r*3+2] - fromScale*(denorm[r*3]*fromMean.X+denorm[r*3+1]*fromMean.Y)
+	}
+	if math.Abs(result[8]) < 1e-15 {
+		return nil, false
+	}
+	for i := range result {
+		result[i] /= result[8]
+	}
+	return &result, true
+}
+
+// normalizePoints moves the points so that their mean is at the origin and scales them so that their mean distance
+// to it is the square root of two.
+func normalizePoints(points []r2.Point) ([]r2.Point, float64, r2.Point) {
+	var mean r2.Point
+	for _, p := range points {
+		mean = mean.Add(p)
+	}
+	mean = mean.Mul(1 / float64(len(points)))
+	var dist float64
+	for _, p := range points {
+		dist += p.Sub(mean).Norm()
+	}
+	scale := 1.
+	if dist > 0 {
+		scale = math.Sqrt2 * float64(len(points)) / dist
+	}
+	normalized := make([]r2.Point, len(points))
+	for i, p := range points {
+		normalized[i] = p.Sub(mean).Mul(scale)
+	}
+	return normalized, scale, mean
+}
+
+func solveHomography(from, to []r2.Point) (*homography, bool) {
+	// with h[8] fixed to 1, each correspondence gives two linear equations in the other eight entries, solved in
+	// the least squares sense with the normal equations
+	var ata [8][8]float64
+	var atb [8]float64
+	addRow := func(row [8]float64, b float64) {
+		for i := 0; i < 8; i++ {
+			for j := 0; j < 8; j++ {
+				ata[i][j] += row[i] * row[j]
+			}
+			atb[i] += row[i] * b
+		}
+	}
+	for i := range from {
+		x, y, u, v := from[i].X, from[i].Y, to[i].X, to[i].Y
+		addRow([8]float64{x, y, 1, 0, 0, 0, -u * x, -u * y}, u)
+		addRow([8]float64{0, 0, 0, x, y, 1, -v * x, -v * y}, v)
+	}
+	a := make([][]float64, 8)
+	for i := range a {
+		a[i] = ata[i][:]
+	}
+	solution, ok := solveLinear(a, atb[:])
+	if !ok {
+		return nil, false
+	}
+	var h homography
+	copy(h[:], solution)
+	h[8] = 1
+	return &h, true
+}
+
+// solveLinear solves the square linear system a x = b with Gaussian elimination and partial pivoting, modifying a
+// and b.
+func solveLinear(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+			b[row] -= f * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; row >= 0; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, true
+}
@@ -0,0 +1,234 @@
+package fiducial
+
+import (
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+	"github.com/pkg/errors"
+	"gonum.org/v1/gonum/mat"
+
+	"go.viam.com/rdk/rimage/transform"
+	"go.viam.com/rdk/spatialmath"
+)
+
+const (
+	poseIterations = 50
+	// undistortIterations is the number of fixed point iterations used to invert the distortion of a camera.
+	undistortIterations = 20
+)
+
+// A Camera projects points in its frame, whose +X axis points to the right of its images, +Y axis to their
+// bottom and +Z axis forward, to the pixels of its images.
+type Camera struct {
+	Intrinsics *transform.PinholeCameraIntrinsics
+	// Distortion of the lens of the camera, or nil if there is none.
+	Distortion transform.Distorter
+}
+
+// Project returns the pixel the point is seen at.
+func (c *Camera) Project(p r3.Vector) r2.Point {
+	x, y := p.X/p.Z, p.Y/p.Z
+	if c.Distortion != nil {
+		x, y = c.Distortion.Transform(x, y)
+	}
+	return r2.Point{X: c.Intrinsics.Fx*x + c.Intrinsics.Ppx, Y: c.Intrinsics.Fy*y + c.Intrinsics.Ppy}
+}
+
+// Unproject returns the point at unit depth that is seen at the pixel.
+func (c *Camera) Unproject(px r2.Point) r2.Point {
+	distorted := r2.Point{X: (px.X - c.Intrinsics.Ppx) / c.Intrinsics.Fx, Y: (px.Y - c.Intrinsics.Ppy) / c.Intrinsics.Fy}
+	if c.Distortion == nil {
+		return distorted
+	}
+	p := distorted
+	for i := 0; i < undistortIterations; i++ {
+		x, y := c.Distortion.Transform(p.X, p.Y)
+		p = p.Sub(r2.Point{X: x, Y: y}.Sub(distorted))
+	}
+	return p
+}
+
+// markerCorners returns the top left, top right, bottom right and bottom left corners of a marker of the given size
+// in its frame, whose origin is its center, +X axis points to its right, +Y axis to its top and +Z axis out of it.
+func markerCorners(sizeMm float64) []r2.Point {
+	s := sizeMm / 2
+	return []r2.Point{{X: -s, Y: s}, {X: s, Y: s}, {X: s, Y: -s}, {X: -s, Y: -s}}
+}
+
+// EstimatePose returns the pose of the marker with the given corners and size in the frame of the camera, and the
+// root mean square distance in pixels between the corners and the projections of the corners of the marker at that
+// pose. The origin of the frame of the marker is its center, its +X axis points to its right, its +Y axis to its top
+// and its +Z axis out of it, towards the camera.
+func EstimatePose(corners [4]r2.Point, sizeMm float64, camera *Camera) (spatialmath.Pose, float64, error) {
+	if sizeMm <= 0 {
+		return nil, 0, errors.Errorf("marker size must be positive, not %v", sizeMm)
+	}
+	return EstimatePlanarPose(markerCorners(sizeMm), corners[:], camera)
+}
+
+// EstimatePlanarPose returns the pose of a plane in the frame of the camera from at least four points on it, given in
+// millimeters along its X and Y axes, and the pixels they are seen at. It also returns the root mean square distance
+// in pixels between those pixels and the projections of the points at that pose.
+func EstimatePlanarPose(points, pixels []r2.Point, camera *Camera) (spatialmath.Pose, float64, error) {
+	if len(points) < 4 || len(points) != len(pixels) {
+		return nil, 0, errors.Errorf("need at least four points and as many pixels, got %d points and %d pixels", len(points), len(pixels))
+	}
+	if camera == nil || camera.Intrinsics == nil {
+		return nil, 0, errors.New("camera intrinsics are needed to estimate poses")
+	}
+	normalized := make([]r2.Point, len(pixels))
+	for i, px := range pixels {
+		normalized[i] = camera.Unproject(px)
+	}
+	h, ok := findHomography(points, normalized)
+	if !ok {
+		return nil, 0, errors.New("points are degenerate")
+	}
+	rotation, translation, err := poseFromHomography(h)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	objectPoints := make([]r3.Vector, len(points))
+	for i, p := range points {
+		objectPoints[i] = r3.Vector{X: p.X, Y: p.Y}
+	}
+	pose, rms := refinePose(objectPoints, pixels, camera, rotation, translation)
+	return pose, rms, nil
+}
+
+// poseFromHomography decomposes the homography taking a plane to the normalized image of a camera into the rotation
+// vector and translation of the plane.
+func poseFromHomography(h *homography) (r3.Vector, r3.Vector, error) {
+	h1 := r3.Vector{X: h[0], Y: h[3], Z: h[6]}
+	h2 := r3.Vector{X: h[1], Y: h[4], Z: h[7]}
+	t := r3.Vector{X: h[2], Y: h[5], Z: h[8]}
+	scale := 2 / (h1.Norm() + h2.Norm())
+	if t.Z < 0 {
+		// the plane must be in front of the camera
+		scale = -scale
+	}
+	r1, r2 := h1.Mul(scale), h2.Mul(scale)
+	t = t.Mul(scale)
+	r3v := r1.Cross(r2)
+
+	// the columns are only approximately orthonormal, so take the closest rotation
+	m := mat.NewDense(3, 3, []float64{r1.X, r2.X, r3v.X, r1.Y, r2.Y, r3v.Y, r1.Z, r2.Z, r3v.Z})
+	var svd mat.SVD
+	if !svd.Factorize(m, mat.SVDFull) {
+		return r3.Vector{}, r3.Vector{}, errors.New("could not decompose homography")
+	}
+	var u, v, rot mat.Dense
+	svd.UTo(&u)
+	svd.VTo(&v)
+	rot.Mul(&u, v.T())
+	if mat.Det(&rot) < 0 {
+		return r3.Vector{}, r3.Vector{}, errors.New("could not decompose homography")
+	}
+	return rotationVector(&rot), t, nil
+}
+
+// rotationVector returns the axis of the rotation scaled by its angle.
+func rotationVector(m mat.Matrix) r3.Vector {
+	// the rows of rotation matrices of the spatialmath package are the images of the axes, which are the columns
+	// of m
+	rm, err := spatialmath.NewRotationMatrix([]float64{
+		m.At(0, 0), m.At(1, 0), m.At(2, 0),
+		m.At(0, 1), m.At(1, 1), m.At(2, 1),
+		m.At(0, 2), m.At(1, 2), m.At(2, 2),
+	})
+	if err != nil {
+		return r3.Vector{}
+	}
+	return rm.AxisAngles().ToR3()
+}
+
+// newPose returns the pose with the given rotation vector and translation.
+func newPose(rotation, translation r3.Vector) spatialmath.Pose {
+	return spatialmath.NewPose(translation, spatialmath.R3ToR4(rotation))
+}
+
+// refinePose minimizes the distances between the pixels and the projections of the points at the pose with the
+// Levenberg-Marquardt algorithm, starting from the given rotation vector and translation, and returns the pose and
+// the root mean square of the distances.
+func refinePose(points []r3.Vector, pixels []r2.Point, camera *Camera, rotation, translation r3.Vector) (spatialmath.Pose, float64) {
+	params := [6]float64{rotation.X, rotation.Y, rotation.Z, translation.X, translation.Y, translation.Z}
+	residuals := func(params [6]float64) []float64 {
+		pose := newPose(r3.Vector{X: params[0], Y: params[1], Z: params[2]}, r3.Vector{X: params[3], Y: params[4], Z: params[5]})
+		res := make([]float64, 0, 2*len(points))
+		for i, p := range points {
+			proj := camera.Project(spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(p)).Point())
+			res = append(res, proj.X-pixels[i].X, proj.Y-pixels[i].Y)
+		}
+		return res
+	}
+	sumSquares := func(res []float64) float64 {
+		var sum float64
+		for _, r := range res {
+			sum += r * r
+		}
+		return sum
+	}
+
+	res := residuals(params)
+	cost := sumSquares(res)
+	damping := 1e-3
+	for iteration := 0; iteration < poseIterations; iteration++ {
+		var jacobian [6][]float64
+		for k := range params {
+			step := 1e-6
+			if k >= 3 {
+				step = 1e-6 * math.Max(1, math.Abs(params[k]))
+			}
+			shifted := params
+			shifted[k] += step
+			shiftedRes := residuals(shifted)
+			jacobian[k] = make([]float64, len(res))
+			for i := range res {
+				jacobian[k][i] = (shiftedRes[i] - res[i]) / step
+			}
+		}
+
+		improved, converged := false, false
+		for attempt := 0; attempt < 10 && !improved; attempt++ {
+			a := make([][]float64, 6)
+			b := make([]float64, 6)
+			for i := 0; i < 6; i++ {
+				a[i] = make([]float64, 6)
+				for j := 0; j < 6; j++ {
+					for r := range res {
+						a[i][j] += jacobian[i][r] * jacobian[j][r]
+					}
+				}
+				a[i][i] *= 1 + damping
+				for r := range res {
+					b[i] -= jacobian[i][r] * res[r]
+				}
+			}
+			delta, ok := solveLinear(a, b)
+			if !ok {
+				damping *= 10
+				continue
+			}
+			candidate := params
+			for k := range candidate {
+				candidate[k] += delta[k]
+			}
+			candidateRes := residuals(candidate)
+			if candidateCost := sumSquares(candidateRes); candidateCost < cost {
+				converged = cost-candidateCost < 1e-12*cost
+				params, res, cost = candidate, candidateRes, candidateCost
+				damping = math.Max(damping/10, 1e-9)
+				improved = true
+			} else {
+				damping *= 10
+			}
+		}
+		if !improved || converged {
+			break
+		}
+	}
+	pose := newPose(r3.Vector{X: params[0], Y: params[1], Z: params[2]}, r3.Vector{X: params[3], Y: params[4], Z: params[5]})
+	return pose, math.Sqrt(cost / float64(len(points)))
+}
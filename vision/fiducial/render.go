@@ -0,0 +1,84 @@
+package fiducial
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/golang/geo/r2"
+	"github.com/golang/geo/r3"
+
+	"go.viam.com/rdk/spatialmath"
+)
+
+// DrawMarker draws the marker image, of the given size, as the camera would see it at the given pose, which is the
+// pose of its center as returned by EstimatePose. It is useful to simulate cameras looking at markers and to check
+// estimated poses by drawing markers over the images they were detected in.
+func DrawMarker(dst draw.Image, marker image.Image, sizeMm float64, pose spatialmath.Pose, camera *Camera) {
+	const subpixels = 4
+	// the rows of the rotation matrices of poses are the axes of their frames
+	rotation := pose.Orientation().RotationMatrix()
+	origin := pose.Point()
+	normal := rotation.Row(2)
+	markerBounds := marker.Bounds()
+
+	// only the pixels around the projections of the corners can see the marker
+	var minPx, maxPx r2.Point
+	for i, corner := range markerCorners(sizeMm) {
+		p := camera.Project(spatialmath.Compose(pose, spatialmath.NewPoseFromPoint(r3.Vector{X: corner.X, Y: corner.Y})).Point())
+		if i == 0 {
+			minPx, maxPx = p, p
+		}
+		minPx = r2.Point{X: math.Min(minPx.X, p.X), Y: math.Min(minPx.Y, p.Y)}
+		maxPx = r2.Point{X: math.Max(maxPx.X, p.X), Y: math.Max(maxPx.Y, p.Y)}
+	}
+	padding := 0.1*math.Max(maxPx.X-minPx.X, maxPx.Y-minPx.Y) + 2
+	area := image.Rect(
+		int(math.Floor(minPx.X-padding)), int(math.Floor(minPx.Y-padding)),
+		int(math.Ceil(maxPx.X+padding)), int(math.Ceil(maxPx.Y+padding)),
+	).Intersect(dst.Bounds())
+
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			var sum float64
+			var covered int
+			for sy := 0; sy < subpixels; sy++ {
+				for sx := 0; sx < subpixels; sx++ {
+					px := r2.Point{
+						X: float64(x) + (float64(sx)+0.5)/subpixels - 0.5,
+						Y: float64(y) + (float64(sy)+0.5)/subpixels - 0.5,
+					}
+					ray := camera.Unproject(px)
+					dir := r3.Vector{X: ray.X, Y: ray.Y, Z: 1}
+					denom := normal.Dot(dir)
+					if math.Abs(denom) < 1e-12 {
+						continue
+					}
+					hit := dir.Mul(normal.Dot(origin) / denom)
+					if hit.Z <= 0 {
+						continue
+					}
+					// the point the ray hits, in the frame of the marker
+					local := hit.Sub(origin)
+					mx := rotation.Row(0).Dot(local)
+					my := rotation.Row(1).Dot(local)
+					u := (mx/sizeMm + 0.5) * float64(markerBounds.Dx())
+					v := (0.5 - my/sizeMm) * float64(markerBounds.Dy())
+					if u < 0 || v < 0 || u >= float64(markerBounds.Dx()) || v >= float64(markerBounds.Dy()) {
+						continue
+					}
+					c, _ := color.GrayModel.Convert(marker.At(markerBounds.Min.X+int(u), markerBounds.Min.Y+int(v))).(color.Gray)
+					sum += float64(c.Y)
+					covered++
+				}
+			}
+			if covered == 0 {
+				continue
+			}
+			background, _ := color.GrayModel.Convert(dst.At(x, y)).(color.Gray)
+			sum += float64(background.Y) * float64(subpixels*subpixels-covered)
+			dst.Set(x, y, color.Gray{Y: uint8(math.Round(sum / subpixels / subpixels))})
+		}
+	}
+}
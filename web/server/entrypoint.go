@@ -14,11 +14,13 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.viam.com/utils"
 	"go.viam.com/utils/perf"
 	"go.viam.com/utils/rpc"
 
 	"go.viam.com/rdk/config"
+	"go.viam.com/rdk/logstore"
 	robotimpl "go.viam.com/rdk/robot/impl"
 	"go.viam.com/rdk/robot/web"
 	weboptions "go.viam.com/rdk/robot/web/options"
@@ -36,16 +38,19 @@ type Arguments struct {
 	SharedDir                  string `flag:"shareddir,usage=web resource directory"`
 	Version                    bool   `flag:"version,usage=print version"`
 	WebProfile                 bool   `flag:"webprofile,usage=include profiler in http server"`
+	WebMetrics                 bool   `flag:"webmetrics,usage=include unauthenticated prometheus metrics at /metrics in http server"`
 	WebRTC                     bool   `flag:"webrtc,default=true,usage=force webrtc connections instead of direct"`
 	RevealSensitiveConfigDiffs bool   `flag:"reveal-sensitive-config-diffs,usage=show config diffs"`
 	UntrustedEnv               bool   `flag:"untrusted-env,usage=disable processes and shell from running in a untrusted environment"`
 	OutputTelemetry            bool   `flag:"output-telemetry,usage=print out telemetry data (metrics and spans)"`
+	LogStoreDir                string `flag:"log-store-dir,usage=directory to keep logs in for querying (default ~/.viam/logs)"`
 }
 
 type robotServer struct {
 	args      Arguments
 	logConfig zap.Config
 	logger    *zap.SugaredLogger
+	logStore  *logstore.Store
 }
 
 // RunServer is an entry point to starting the web server that can be called by main in a code
@@ -70,6 +75,25 @@ func RunServer(ctx context.Context, args []string, _ golog.Logger) (err error) {
 	}
 	rdkLogLevel := logConfig.Level
 	logger := zap.Must(logConfig.Build()).Sugar().Named("robot_server")
+
+	// Keep logs on disk so they can be queried even when the robot is offline.
+	logStoreDir := argsParsed.LogStoreDir
+	if logStoreDir == "" {
+		logStoreDir = filepath.Join(viamDotDir, "logs")
+	}
+	logStore, logStoreErr := logstore.NewStore(logStoreDir, logstore.DefaultMaxFileSize, logstore.DefaultMaxFiles)
+	if logStoreErr != nil {
+		logger.Warnw("failed to open log store; logs will not be kept on disk", "error", logStoreErr)
+	} else {
+		defer func() {
+			if err := logStore.Close(); err != nil {
+				logger.Errorw("error closing log store", "error", err)
+			}
+		}()
+		logger = logger.Desugar().WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c, logStore.Core(rdkLogLevel))
+		})).Sugar()
+	}
 	golog.ReplaceGloabl(logger)
 
 	// Always log the version, return early if the '-version' flag was provided
@@ -140,6 +164,7 @@ func RunServer(ctx context.Context, args []string, _ golog.Logger) (err error) {
 	server := robotServer{
 		logConfig: logConfig,
 		logger:    logger,
+		logStore:  logStore,
 		args:      argsParsed,
 	}
 
@@ -182,6 +207,8 @@ func (s *robotServer) createWebOptions(cfg *config.Config) (weboptions.Options,
 		return weboptions.Options{}, err
 	}
 	options.Pprof = s.args.WebProfile
+	options.Metrics = s.args.WebMetrics
+	options.LogStore = s.logStore
 	options.SharedDir = s.args.SharedDir
 	options.Debug = s.args.Debug || cfg.Debug
 	options.WebRTC = s.args.WebRTC